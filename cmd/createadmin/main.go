@@ -14,7 +14,9 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/hash"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
 )
 
@@ -103,8 +105,13 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	hasher, err := hash.New(cfg.Security.PasswordHash)
+	if err != nil {
+		log.Fatalf("Failed to create password hasher: %v", err)
+	}
+
 	repo := user.NewRepository(db)
-	service := user.NewService(repo)
+	service := user.NewService(repo, user.WithMaxUsers(cfg.License.MaxUsers), user.WithPasswordHasher(hasher))
 
 	ctx := context.Background()
 
@@ -194,31 +201,8 @@ func readPassword(prompt string) string {
 	return strings.TrimSpace(string(bytePassword))
 }
 
+// validatePassword enforces the strong password policy for admin accounts,
+// regardless of the policy configured for regular users
 func validatePassword(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
-	}
-
-	// Enforce strong password policy for admin accounts
-	var (
-		hasUpper   = regexp.MustCompile(`[A-Z]`).MatchString(password)
-		hasLower   = regexp.MustCompile(`[a-z]`).MatchString(password)
-		hasDigit   = regexp.MustCompile(`[0-9]`).MatchString(password)
-		hasSpecial = regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>\/?]`).MatchString(password)
-	)
-
-	if !hasUpper {
-		return fmt.Errorf("password must contain at least one uppercase letter")
-	}
-	if !hasLower {
-		return fmt.Errorf("password must contain at least one lowercase letter")
-	}
-	if !hasDigit {
-		return fmt.Errorf("password must contain at least one digit")
-	}
-	if !hasSpecial {
-		return fmt.Errorf("password must contain at least one special character")
-	}
-
-	return nil
+	return auth.StrongPasswordPolicy().Validate(password)
 }
@@ -39,6 +39,14 @@ func (m *MockService) GetUserByID(ctx context.Context, id uint) (*user.User, err
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *MockService) GetUserByIDIncludingDeleted(ctx context.Context, id uint) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
 func (m *MockService) UpdateUser(ctx context.Context, id uint, req user.UpdateUserRequest) (*user.User, error) {
 	args := m.Called(ctx, id, req)
 	if args.Get(0) == nil {
@@ -47,6 +55,22 @@ func (m *MockService) UpdateUser(ctx context.Context, id uint, req user.UpdateUs
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *MockService) UpdateUserPartial(ctx context.Context, id uint, patch user.PatchUserRequest) (*user.User, error) {
+	args := m.Called(ctx, id, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) UpdateUserAsAdmin(ctx context.Context, id uint, req user.AdminUpdateUserRequest) (*user.User, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
 func (m *MockService) DeleteUser(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -60,11 +84,97 @@ func (m *MockService) ListUsers(ctx context.Context, filters user.UserFilterPara
 	return args.Get(0).([]user.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockService) CountUsersByRole(ctx context.Context, filters user.UserFilterParams) (map[string]int64, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockService) RestoreUser(ctx context.Context, id uint) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) SetUserActive(ctx context.Context, userID uint, active bool) (*user.User, error) {
+	args := m.Called(ctx, userID, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) RecordLogin(ctx context.Context, userID uint) {
+	m.Called(ctx, userID)
+}
+
+func (m *MockService) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockService) PromoteToAdmin(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockService) BootstrapAdmin(ctx context.Context, req user.RegisterRequest) (*user.User, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) BulkAssignRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]user.RoleChangeResult, error) {
+	args := m.Called(ctx, roleName, userIDs, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]user.RoleChangeResult), args.Error(1)
+}
+
+func (m *MockService) BulkRemoveRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]user.RoleChangeResult, error) {
+	args := m.Called(ctx, roleName, userIDs, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]user.RoleChangeResult), args.Error(1)
+}
+
+func (m *MockService) FindOrCreateOAuthUser(ctx context.Context, email, name string) (*user.User, error) {
+	args := m.Called(ctx, email, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) ConfirmEmailChange(ctx context.Context, userID uint, token string) (*user.User, error) {
+	args := m.Called(ctx, userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) CancelEmailChange(ctx context.Context, userID uint) (*user.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockService) SeatUsage(ctx context.Context) (user.SeatUsage, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(user.SeatUsage), args.Error(1)
+}
+
 func TestValidatePassword(t *testing.T) {
 	tests := []struct {
 		name        string
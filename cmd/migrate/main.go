@@ -12,14 +12,21 @@ import (
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/migrate"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
 func main() {
 	timeoutFlag := flag.String("timeout", "", "Migration timeout (e.g., 5m, 30s, 1h)")
 	lockTimeoutFlag := flag.String("lock-timeout", "", "Lock acquisition timeout (e.g., 30s, 1m)")
 	forceFlag := flag.Bool("force", false, "Skip confirmations for destructive operations")
+	versionFlag := flag.Bool("version", false, "Print version information and exit")
 	flag.Parse()
 
+	if *versionFlag {
+		fmt.Println(version.Get())
+		return
+	}
+
 	args := flag.Args()
 	if len(args) == 0 {
 		printUsage()
@@ -74,6 +81,8 @@ func main() {
 
 	migrator, err := migrate.New(sqlDB, migrate.Config{
 		MigrationsDir: cfg.Migrations.Directory,
+		UseEmbedded:   cfg.Migrations.UseEmbedded,
+		TablePrefix:   cfg.Database.TablePrefix,
 		Timeout:       timeout,
 		LockTimeout:   lockTimeout,
 	})
@@ -313,6 +322,7 @@ func printUsage() {
 	fmt.Println("  --timeout DURATION        Override migration timeout (e.g., 5m, 30s, 1h)")
 	fmt.Println("  --lock-timeout DURATION   Override lock timeout (e.g., 30s, 1m)")
 	fmt.Println("  --force                   Skip confirmations (for drop command)")
+	fmt.Println("  --version                 Print version information and exit")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  migrate up")
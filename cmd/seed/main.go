@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/seed"
+)
+
+func main() {
+	defaults := seed.DefaultOptions()
+	usersFlag := flag.Int("users", defaults.Users, "Number of users to ensure exist (user001@example.dev, ...)")
+	adminsFlag := flag.Int("admins", defaults.Admins, "How many of the first users get the admin role")
+	sessionsFlag := flag.Int("sessions", defaults.SessionsPerUser, "Refresh-token sessions per user")
+	seedFlag := flag.Int64("seed", defaults.Seed, "Random seed for deterministic names")
+	passwordFlag := flag.String("password", defaults.Password, "Password shared by all seeded users")
+	forceFlag := flag.Bool("force", false, "Allow seeding when app.environment is production")
+	flag.Parse()
+
+	opts := seed.Options{
+		Users:           *usersFlag,
+		Admins:          *adminsFlag,
+		SessionsPerUser: *sessionsFlag,
+		Seed:            *seedFlag,
+		Password:        *passwordFlag,
+	}
+	if err := run(opts, *forceFlag); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run seeds the database and reports failures itself. It returns instead of
+// exiting so the deferred close of the database runs.
+func run(opts seed.Options, force bool) error {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		slog.Error("Failed to load configuration", "err", err)
+		return err
+	}
+
+	if err := seed.CheckEnvironment(cfg.App.Environment, force); err != nil {
+		if errors.Is(err, seed.ErrProductionEnvironment) {
+			slog.Error("Seeding aborted", "environment", cfg.App.Environment, "err", err)
+		}
+		return err
+	}
+
+	database, err := db.NewPostgresDBFromDatabaseConfig(cfg.Database)
+	if err != nil {
+		slog.Error("Failed to connect to database", "err", err)
+		return err
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		slog.Error("Failed to get database instance", "err", err)
+		return err
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("Failed to close database connection", "err", err)
+		}
+	}()
+
+	summary, err := seed.Run(context.Background(), database, opts)
+	if err != nil {
+		slog.Error("Seeding failed", "err", err)
+		return err
+	}
+
+	fmt.Println("✅ Seeding complete")
+	fmt.Printf("   %s\n", summary)
+	fmt.Printf("   Log in as %s / %s\n", seed.Email(1), opts.Password)
+	return nil
+}
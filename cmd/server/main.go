@@ -2,23 +2,49 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 
 	_ "github.com/vahiiiid/go-rest-api-boilerplate/api/docs"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth/oauth"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/featureflags"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/graphql"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/grpcserver"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/hash"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/health"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/httpclient"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/lifecycle"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/logging"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/maintenance"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/migrate"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/notification"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/outbox"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/realtime"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/scheduler"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tracing"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/worker"
 )
 
 // @title Go REST API Boilerplate
@@ -42,27 +68,102 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	validateOnly := flags.Bool("validate-only", false, "Load and validate configuration, print a report and exit")
+	showVersion := flags.Bool("version", false, "Print version information and exit")
+	configPath := flags.String("config", "", "Path to a config file (default: configs/config.yaml merged with configs/config.<env>.yaml)")
+	_ = flags.Parse(os.Args[1:])
+
+	if *showVersion {
+		fmt.Println(version.Get())
+		return
+	}
+
+	if *validateOnly {
+		os.Exit(validateConfig(*configPath, os.Stdout))
+	}
+
 	if err := run(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// validateConfig loads the configuration, prints the production hardening
+// report to out and returns the process exit code (0 valid, 1 invalid).
+func validateConfig(configPath string, out io.Writer) int {
+	cfg, err := config.ReadConfig(configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "FAIL  load configuration: %v\n", err)
+		return 1
+	}
+
+	production := cfg.App.Environment == "production"
+	_, _ = fmt.Fprintf(out, "Environment: %s\n", cfg.App.Environment)
+	if !production {
+		_, _ = fmt.Fprintln(out, "Production checks are reported but only enforced when app.environment is production")
+	}
+
+	for _, check := range cfg.ProductionChecks() {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+			if !production {
+				status = "WARN"
+			}
+		}
+		_, _ = fmt.Fprintf(out, "%s  %s", status, check.Name)
+		if !check.Passed {
+			_, _ = fmt.Fprintf(out, ": %s", check.Message)
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
+	for _, key := range cfg.UnknownKeys {
+		_, _ = fmt.Fprintf(out, "WARN  unknown configuration key: %s\n", key)
+	}
+	for _, name := range cfg.UnknownEnv {
+		_, _ = fmt.Fprintf(out, "WARN  environment variable matches no configuration key: %s\n", name)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		_, _ = fmt.Fprintf(out, "Configuration invalid: %v\n", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintln(out, "Configuration valid")
+	return 0
+}
+
 func run() error {
-	logger := slog.Default()
-	logger.Info("Starting Go REST API Boilerplate...")
+	// WHY: The configured logger needs the configuration, so failures loading it use the default
+	bootLogger := slog.Default().With(version.LogAttr())
 
 	cfg, err := config.LoadConfig("")
 	if err != nil {
-		logger.Error("Failed to load configuration", "error", err)
+		bootLogger.Error("Failed to load configuration", "error", err)
 		return err
 	}
 
 	if err := cfg.Validate(); err != nil {
-		logger.Error("Configuration validation failed", "error", err)
+		bootLogger.Error("Configuration validation failed", "error", err)
 		return err
 	}
 
+	logger, closeLogger, err := logging.New(cfg.Logging, cfg.App.Name)
+	if err != nil {
+		bootLogger.Error("Failed to set up logging", "error", err)
+		return err
+	}
+	defer func() {
+		_ = closeLogger()
+	}()
+	// WHY: The request logger and packages logging through slog pick up the default
+	slog.SetDefault(logger)
+	logger.Info("Starting Go REST API Boilerplate...", "commit", version.Commit, "build_date", version.BuildDate)
+
+	cfg.App.Version = version.Version
 	cfg.LogSafeConfig(logger)
+	cfg.LogUnknownKeys(logger)
 
 	database, err := db.NewPostgresDBFromDatabaseConfig(cfg.Database)
 	if err != nil {
@@ -70,6 +171,20 @@ func run() error {
 		return err
 	}
 
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled {
+		tracerProvider, err = tracing.NewProvider(context.Background(), cfg.Tracing, version.Version)
+		if err != nil {
+			logger.Error("Failed to set up tracing", "error", err)
+			return err
+		}
+		tracing.SetGlobal(tracerProvider)
+		if err := tracing.InstrumentGORM(database, tracerProvider); err != nil {
+			logger.Error("Failed to instrument database for tracing", "error", err)
+			return err
+		}
+	}
+
 	if os.Getenv("SKIP_MIGRATION_CHECK") == "" {
 		if err := checkMigrationStatus(database, &cfg.Migrations); err != nil {
 			logger.Warn("Migration check", "status", "⚠️", "error", err)
@@ -78,12 +193,180 @@ func run() error {
 		}
 	}
 
-	authService := auth.NewServiceWithRepo(&cfg.JWT, database)
+	authService, err := auth.NewServiceFromConfig(&cfg.JWT, database)
+	if err != nil {
+		logger.Error("Failed to create auth service", "error", err)
+		return err
+	}
+	passwordHasher, err := hash.New(cfg.Security.PasswordHash)
+	if err != nil {
+		logger.Error("Failed to create password hasher", "error", err)
+		return err
+	}
 	userRepo := user.NewRepository(database)
-	userService := user.NewService(userRepo)
-	userHandler := user.NewHandler(userService, authService)
+	var outboxRepo outbox.Repository
+	if cfg.Outbox.Enabled {
+		outboxRepo = outbox.NewRepository(database)
+	}
+	unsubscribeLinks := notification.NewUnsubscribeLinks(auth.NewURLSigner(cfg.JWT.Secret, notification.LinkTTL(cfg.Email.UnsubscribeTTL)), cfg.Email.PublicBaseURL, cfg.Email.UnsubscribeTTL)
+	emailPool := worker.New(worker.Config{
+		Name:      "email",
+		Workers:   cfg.Worker.Concurrency,
+		QueueSize: cfg.Worker.QueueSize,
+		Logger:    logger,
+		Namespace: cfg.Metrics.Namespace,
+	})
+	mailer := email.WithWorkerPool(email.WithPreferences(
+		email.WithTimeout(email.NewConsoleEmailService(cfg.Email.From, email.NewResetLinks(cfg.Security.ResetLinkTemplate, cfg.Email.PublicBaseURL), logger), cfg.Email.SendTimeout),
+		notification.NewService(notification.NewRepository(database)),
+		unsubscribeLinks.URL,
+	), emailPool, logger)
+	shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout) * time.Second
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+
+	workers := lifecycle.New(logger)
+	// WHY: Stops the workers already started when run returns early; after a graceful shutdown none are left
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = workers.Shutdown(ctx)
+	}()
+	if err := workers.Add("email", lifecycle.StopFunc(emailPool.Stop)); err != nil {
+		return err
+	}
+	userService := user.NewService(userRepo,
+		user.WithEmailService(mailer),
+		user.WithEmailChangeTTL(cfg.Email.ChangeTokenTTL),
+		user.WithPasswordPolicy(auth.NewPasswordPolicy(cfg.Password)),
+		user.WithPasswordHasher(passwordHasher),
+		user.WithRehashOnLogin(cfg.Security.PasswordHash.RehashOnLogin),
+		user.WithHashLimiter(hash.NewLimiter(hash.LimiterConfig{
+			MaxConcurrency: cfg.Security.PasswordHash.MaxConcurrency,
+			MaxWait:        cfg.Security.PasswordHash.MaxWait,
+			Namespace:      cfg.Metrics.Namespace,
+		})),
+		user.WithMaxUsers(cfg.License.MaxUsers),
+		user.WithOutbox(outboxRepo),
+	)
+	if cfg.Admin.BootstrapEnabled {
+		if err := bootstrapAdmin(userService, cfg.Admin, logger); err != nil {
+			logger.Error("Failed to bootstrap admin", "email", cfg.Admin.Email, "error", err)
+			return err
+		}
+	}
+	auditLogger := audit.NewDBLogger(audit.NewRepository(database))
+	featureFlags := featureflags.Load(cfg)
+	eventBus := events.NewBus(events.DefaultBufferSize)
+	httpclient.SetDefaultMetrics(httpclient.NewMetrics(cfg.Metrics.Namespace, nil))
+	authMetrics := middleware.NewAuthMetrics(middleware.MetricsConfig{Namespace: cfg.Metrics.Namespace})
+	userHandler := user.NewHandler(userService, authService,
+		user.WithLegacyAuthResponse(cfg.JWT.LegacyAuthResponse),
+		user.WithAutoLoginOnRegister(cfg.Security.AutoLoginOnRegister),
+		user.WithTokenDelivery(cfg.Security.TokenDelivery, cfg.JWT.RefreshTokenTTL),
+		user.WithSecureCookies(cfg.Security.CookieSecure),
+		user.WithURLSigner(auth.NewURLSigner(cfg.JWT.Secret, user.DownloadURLTTL)),
+		user.WithAuditLogger(auditLogger),
+		user.WithAuthMetrics(authMetrics),
+		user.WithEventBus(eventBus),
+		user.WithRefreshUpdatesLastLogin(cfg.JWT.RefreshUpdatesLastLogin),
+		user.WithBulkMaxUsers(cfg.Admin.BulkMaxUsers),
+		user.WithStrictHTTPSemantics(cfg.Server.StrictHTTPSemantics),
+		user.WithEmailEnumerationProtection(cfg.Auth.EmailEnumerationProtection),
+	)
+
+	var extraCheckers []health.Checker
+	if cfg.Health.MigrationCheckEnabled {
+		// WHY: Like the startup migration check, a broken migrations setup is reported rather than fatal
+		status, err := newMigrationStatus(database, &cfg.Migrations)
+		if err != nil {
+			logger.Warn("Migration health check disabled", "status", "⚠️", "error", err)
+		} else {
+			defer func() { _ = status.Close() }()
+			extraCheckers = append(extraCheckers, health.NewMigrationChecker(status))
+		}
+	}
+	var jobScheduler *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		var outboxRelay *outbox.Relay
+		if outboxRepo != nil {
+			outboxRelay = outbox.NewRelay(outboxRepo, outbox.PublishTo(eventBus), outbox.RelayConfig{BatchSize: cfg.Outbox.BatchSize, MaxAttempts: cfg.Outbox.MaxAttempts, Logger: logger})
+		}
+		jobScheduler, err = setupScheduler(database, cfg, outboxRelay, logger)
+		if err != nil {
+			logger.Error("Failed to set up scheduler", "error", err)
+			return err
+		}
+		if err := workers.Add("scheduler", jobScheduler); err != nil {
+			return err
+		}
+		extraCheckers = append(extraCheckers, scheduler.NewChecker(jobScheduler))
+	}
+
+	maintenanceMode := maintenance.NewMode(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfter)
+	router := server.SetupRouter(userHandler, authService, cfg, database,
+		server.WithHealthCheckers(extraCheckers...),
+		server.WithMaintenanceMode(maintenanceMode),
+	)
+	server.RegisterFeatureFlagRoutes(router, authService, featureflags.NewHandler(featureFlags))
+	// WHY: A reload can switch a mounted feature off and on again, but a feature off at startup stays unmounted until restart
+	if featureFlags.IsEnabled(featureflags.OAuth) {
+		identityRepo := oauth.NewIdentityRepository(database)
+		googleProvider := oauth.NewGoogleProvider(cfg.OAuth.Google)
+		server.RegisterOAuthRoutes(router, featureFlags, googleProvider.Name(), oauth.NewHandler(googleProvider, userService, authService, identityRepo))
+		server.RegisterIdentityRoutes(router, featureFlags, authService, oauth.NewIdentityHandler(identityRepo, userService, googleProvider))
+	}
+	if featureFlags.IsEnabled(featureflags.GraphQL) {
+		graphqlHandler, err := graphql.NewHandler(userService)
+		if err != nil {
+			logger.Error("Failed to build GraphQL schema", "error", err)
+			return err
+		}
+		server.RegisterGraphQLRoutes(router, featureFlags, authService, graphqlHandler)
+	}
+	server.RegisterAdminEventRoutes(router, authService, realtime.NewStreamHandler(eventBus,
+		realtime.WithMaxStreams(cfg.Admin.EventStreamMaxConnections),
+		realtime.WithUserStatusCheck(authService),
+	))
+	if featureFlags.IsEnabled(featureflags.WebSocket) {
+		server.RegisterWebSocketRoutes(router, featureFlags, realtime.NewHandler(authService, eventBus,
+			realtime.WithPingInterval(cfg.WebSocket.PingInterval),
+			realtime.WithAllowedOrigins(cfg.CORS.AllowOrigins),
+		))
+	}
+
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.Port))
+		if err != nil {
+			logger.Error("Failed to listen for gRPC", "error", err)
+			return err
+		}
+		grpcServer = grpcserver.New(grpcserver.NewServer(userService, authService,
+			grpcserver.WithAutoLoginOnRegister(cfg.Security.AutoLoginOnRegister),
+			grpcserver.WithAuthMetrics(authMetrics),
+			grpcserver.WithTenantMetadata(cfg.Tenant.Enabled),
+		))
+		go func() {
+			logger.Info("gRPC server starting", "address", listener.Addr().String())
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error("gRPC server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+		if err := workers.Add("grpc", lifecycle.StopFunc(func(ctx context.Context) error {
+			stopGRPC(ctx, grpcServer)
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
 
-	router := server.SetupRouter(userHandler, authService, cfg, database)
+	if err := workers.Start(context.Background()); err != nil {
+		logger.Error("Failed to start background workers", "error", err)
+		return err
+	}
 
 	port := cfg.Server.Port
 	if port == "" {
@@ -106,7 +389,9 @@ func run() error {
 
 	go func() {
 		logger.Info("Server starting", "address", srv.Addr)
-		logger.Info("Swagger UI available", "url", fmt.Sprintf("http://localhost:%s/swagger/index.html", port))
+		if cfg.Server.SwaggerEnabled {
+			logger.Info("Swagger UI available", "url", fmt.Sprintf("http://localhost:%s/swagger/index.html", port))
+		}
 		logger.Info("Health check available", "url", fmt.Sprintf("http://localhost:%s/health", port))
 		logger.Info("Liveness probe available", "url", fmt.Sprintf("http://localhost:%s/health/live", port))
 		logger.Info("Readiness probe available", "url", fmt.Sprintf("http://localhost:%s/health/ready", port))
@@ -117,6 +402,8 @@ func run() error {
 		}
 	}()
 
+	go reloadOnHangup(featureFlags, maintenanceMode, logger)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-quit
@@ -124,6 +411,30 @@ func run() error {
 	logger.Info("Received shutdown signal", "signal", sig)
 	logger.Info("Shutting down server gracefully...")
 
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if tracerProvider != nil {
+		// WHY: Deferred, so spans of requests finishing during srv.Shutdown are exported too
+		defer func() {
+			logger.Info("Flushing traces...")
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				logger.Error("Traces were not exported in time", "error", err)
+			}
+		}()
+	}
+
+	// WHY: In-flight requests still enqueue work and query the database, so the
+	// server stops first, then the workers, and the database goes last
+	shutdownErr := srv.Shutdown(ctx)
+	if shutdownErr != nil {
+		logger.Error("Server forced to shutdown", "error", shutdownErr)
+	}
+
+	logger.Info("Stopping background workers...")
+	// WHY: Errors are logged per worker; a slow one must not keep the server from shutting down
+	_ = workers.Shutdown(ctx)
+
 	sqlDB, err := database.DB()
 	if err == nil {
 		logger.Info("Closing database connections...")
@@ -132,41 +443,134 @@ func run() error {
 		}
 	}
 
-	shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout) * time.Second
-	if shutdownTimeout == 0 {
-		shutdownTimeout = 30 * time.Second
+	if shutdownErr != nil {
+		return shutdownErr
 	}
+	logger.Info("Server exited gracefully")
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
+// reloadOnHangup reloads the feature flags and maintenance mode from the
+// configuration each time the process receives SIGHUP. An invalid
+// configuration keeps the current values.
+func reloadOnHangup(flags *featureflags.Flags, maintenanceMode *maintenance.Mode, logger *slog.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for range hangup {
+		cfg, err := config.LoadConfig("")
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err != nil {
+			logger.Error("Failed to reload configuration", "error", err)
+			continue
+		}
+		flags.Reload(cfg)
+		maintenanceMode.Reload(cfg.Maintenance)
+		logger.Info("Configuration reloaded", "flags", flags.All(), "maintenance", maintenanceMode.Enabled())
+	}
+}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown", "error", err)
+// bootstrapAdmin creates the configured admin when no admin exists yet
+func bootstrapAdmin(userService user.Service, cfg config.AdminConfig, logger *slog.Logger) error {
+	admin, err := userService.BootstrapAdmin(context.Background(), user.RegisterRequest{
+		Name:     cfg.Name,
+		Email:    cfg.Email,
+		Password: cfg.Password,
+	})
+	if err != nil {
 		return err
 	}
-
-	logger.Info("Server exited gracefully")
+	if admin == nil {
+		logger.Debug("Admin bootstrap skipped, an admin already exists")
+		return nil
+	}
+	logger.Info("Bootstrapped admin", "user_id", admin.ID, "email", admin.Email)
 	return nil
 }
 
-func checkMigrationStatus(database *gorm.DB, cfg *config.MigrationsConfig) error {
+// stopGRPC lets in-flight RPCs finish, forcing the server to stop once ctx expires
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+// setupScheduler registers the periodic background jobs; a nil relay leaves
+// the outbox relay out
+func setupScheduler(database *gorm.DB, cfg *config.Config, relay *outbox.Relay, logger *slog.Logger) (*scheduler.Scheduler, error) {
+	s := scheduler.New(scheduler.Config{Logger: logger})
+
+	cleanupInterval := cfg.Scheduler.TokenCleanupInterval
+	if cleanupInterval == 0 {
+		cleanupInterval = time.Hour
+	}
+
+	refreshTokenRepo := auth.NewRefreshTokenRepository(database)
+	if err := s.Add(scheduler.Job{
+		Name:     "refresh_token_cleanup",
+		Interval: cleanupInterval,
+		Jitter:   cleanupInterval / 10,
+		Run:      refreshTokenRepo.DeleteExpired,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to register refresh token cleanup job: %w", err)
+	}
+
+	if relay != nil {
+		relayInterval := cfg.Outbox.RelayInterval
+		if relayInterval == 0 {
+			relayInterval = time.Second
+		}
+		if err := s.Add(scheduler.Job{
+			Name:     "outbox_relay",
+			Interval: relayInterval,
+			Run:      relay.Run,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to register outbox relay job: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// newMigrationStatus reads migration versions without holding a connection, so
+// it can back a health check for the lifetime of the process
+func newMigrationStatus(database *gorm.DB, cfg *config.MigrationsConfig) (*migrate.Status, error) {
 	sqlDB, err := database.DB()
 	if err != nil {
-		return fmt.Errorf("failed to get sql.DB: %w", err)
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 
-	migrator, err := migrate.New(sqlDB, migrate.Config{
+	status, err := migrate.NewStatus(sqlDB, migrate.Config{
 		MigrationsDir: cfg.Directory,
-		Timeout:       time.Duration(cfg.Timeout) * time.Second,
-		LockTimeout:   time.Duration(cfg.LockTimeout) * time.Second,
+		UseEmbedded:   cfg.UseEmbedded,
+		TablePrefix:   db.TablePrefix(database),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
 	}
+	return status, nil
+}
 
-	version, dirty, err := migrator.Version()
+func checkMigrationStatus(database *gorm.DB, cfg *config.MigrationsConfig) error {
+	status, err := newMigrationStatus(database, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to get migration version: %w", err)
+		return err
+	}
+	defer func() { _ = status.Close() }()
+
+	version, dirty, err := status.Version(context.Background())
+	if err != nil {
+		return err
 	}
 
 	if dirty {
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
 func TestRun_ConfigLoadError(t *testing.T) {
@@ -408,3 +415,168 @@ func TestHTTPServer_MaxHeaderBytes(t *testing.T) {
 		t.Error("server shutdown timed out")
 	}
 }
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+const secureProductionConfig = `
+app:
+  environment: "production"
+database:
+  host: "db.internal"
+  password: "prod-password"
+  sslmode: "require"
+jwt:
+  secret: "qrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyzAB"
+ratelimit:
+  enabled: true
+cors:
+  allow_origins: ["https://app.example.com"]
+`
+
+const insecureProductionConfig = `
+app:
+  environment: "production"
+  debug: true
+database:
+  host: "db.internal"
+  password: "prod-password"
+  sslmode: "disable"
+jwt:
+  secret: "qrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyzAB"
+server:
+  swagger_enabled: true
+`
+
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"APP_ENVIRONMENT", "APP_DEBUG", "JWT_SECRET", "DATABASE_PASSWORD", "DATABASE_SSLMODE", "RATELIMIT_ENABLED", "SERVER_SWAGGER_ENABLED", "CORS_ALLOW_ORIGINS"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	clearConfigEnv(t)
+
+	tests := []struct {
+		name         string
+		path         string
+		wantCode     int
+		wantInOutput []string
+	}{
+		{
+			name:         "secure production config",
+			path:         writeTestConfig(t, secureProductionConfig),
+			wantCode:     0,
+			wantInOutput: []string{"Environment: production", "PASS  app.debug", "Configuration valid"},
+		},
+		{
+			name:     "insecure production config",
+			path:     writeTestConfig(t, insecureProductionConfig),
+			wantCode: 1,
+			wantInOutput: []string{
+				"FAIL  app.debug",
+				"FAIL  database.sslmode",
+				"FAIL  ratelimit.enabled",
+				"FAIL  server.swagger_enabled",
+				"FAIL  cors.allow_origins",
+				"Configuration invalid",
+			},
+		},
+		{
+			name:         "insecure settings only warn outside production",
+			path:         writeTestConfig(t, strings.Replace(insecureProductionConfig, `"production"`, `"development"`, 1)),
+			wantCode:     0,
+			wantInOutput: []string{"WARN  app.debug", "Configuration valid"},
+		},
+		{
+			name:         "misspelled key warns",
+			path:         writeTestConfig(t, secureProductionConfig+"\nratelimt:\n  enabled: true\n"),
+			wantCode:     0,
+			wantInOutput: []string{"WARN  unknown configuration key: ratelimt.enabled", "Configuration valid"},
+		},
+		{
+			name:         "missing config file",
+			path:         filepath.Join(t.TempDir(), "missing.yaml"),
+			wantCode:     1,
+			wantInOutput: []string{"FAIL  load configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			code := validateConfig(tt.path, &out)
+			if code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d\n%s", tt.wantCode, code, out.String())
+			}
+			for _, want := range tt.wantInOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMain_ValidateOnlyFlag(t *testing.T) {
+	if path := os.Getenv("VALIDATE_ONLY_CONFIG"); path != "" {
+		os.Args = []string{"server", "--validate-only", "--config", path}
+		main()
+		return
+	}
+
+	clearConfigEnv(t)
+
+	tests := []struct {
+		name     string
+		config   string
+		wantCode int
+	}{
+		{"valid config exits 0", secureProductionConfig, 0},
+		{"insecure config exits 1", insecureProductionConfig, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestMain_ValidateOnlyFlag$")
+			cmd.Env = append(os.Environ(), "VALIDATE_ONLY_CONFIG="+writeTestConfig(t, tt.config))
+			output, err := cmd.CombinedOutput()
+
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("failed to run subprocess: %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d\n%s", tt.wantCode, code, output)
+			}
+		})
+	}
+}
+
+func TestMain_VersionFlag(t *testing.T) {
+	if os.Getenv("PRINT_VERSION") == "1" {
+		os.Args = []string{"server", "--version"}
+		main()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMain_VersionFlag$")
+	cmd.Env = append(os.Environ(), "PRINT_VERSION=1")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected exit code 0, got %v\n%s", err, output)
+	}
+	if !strings.Contains(string(output), version.Get().String()) {
+		t.Errorf("expected version output %q, got:\n%s", version.Get().String(), output)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func main() {
+	revokeID := flag.Uint("revoke-tokens", 0, "Revoke every refresh token of the user ID, forcing them to log in again")
+	flag.Parse()
+
+	if *revokeID == 0 {
+		fmt.Fprintln(os.Stderr, "usage: usertool -revoke-tokens <userID>")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	if err := run(*revokeID); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run revokes the tokens of userID and reports failures itself. It returns
+// instead of exiting so the deferred close of the database runs.
+func run(userID uint) error {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		slog.Error("Failed to load configuration", "err", err)
+		return err
+	}
+
+	database, err := db.NewPostgresDBFromDatabaseConfig(cfg.Database)
+	if err != nil {
+		slog.Error("Failed to connect to database", "err", err)
+		return err
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		slog.Error("Failed to get database instance", "err", err)
+		return err
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("Failed to close database connection", "err", err)
+		}
+	}()
+
+	authService, err := auth.NewServiceFromConfig(&cfg.JWT, database)
+	if err != nil {
+		slog.Error("Failed to create auth service", "err", err)
+		return err
+	}
+	userService := user.NewService(user.NewRepository(database))
+
+	if err := revokeTokens(context.Background(), userService, authService, userID, os.Stdout); err != nil {
+		slog.Error("Failed to revoke tokens", "user_id", userID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// revokeTokens revokes all refresh tokens of an existing user and reports how many were revoked
+func revokeTokens(ctx context.Context, users user.Service, authService auth.Service, userID uint, out io.Writer) error {
+	u, err := users.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	revoked, err := authService.RevokeAllUserTokens(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Revoked %d refresh token(s) for %s (%s)\n", revoked, u.Name, u.Email)
+	_, _ = fmt.Fprintln(out, "Issued access tokens stay valid until they expire; the user must log in again after that")
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func TestRevokeTokens(t *testing.T) {
+	ctx := context.Background()
+	database := testutil.NewSQLiteDB(t)
+
+	authService, err := auth.NewServiceFromConfig(&config.JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"}, database)
+	require.NoError(t, err)
+	userService := user.NewService(user.NewRepository(database))
+
+	u, err := userService.RegisterUser(ctx, user.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	first, err := authService.GenerateTokenPair(ctx, u.ID, u.Email, u.Name)
+	require.NoError(t, err)
+	_, err = authService.GenerateTokenPair(ctx, u.ID, u.Email, u.Name)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, revokeTokens(ctx, userService, authService, u.ID, &out))
+	assert.Contains(t, out.String(), "Revoked 2 refresh token(s) for John Doe (john@example.com)")
+
+	_, err = authService.RefreshAccessToken(ctx, first.RefreshToken)
+	assert.Error(t, err, "revoked refresh tokens cannot be used")
+
+	t.Run("nothing left to revoke", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, revokeTokens(ctx, userService, authService, u.ID, &out))
+		assert.Contains(t, out.String(), "Revoked 0 refresh token(s)")
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		out.Reset()
+		err := revokeTokens(ctx, userService, authService, 999, &out)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+		assert.Empty(t, out.String())
+	})
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+)
+
+// ErrInvalidFilter is returned for malformed audit log query parameters
+var ErrInvalidFilter = errors.New("invalid audit filter")
+
+// Query parameters filtering the audit log
+const (
+	ActionParam   = "action"
+	ActorIDParam  = "actor_id"
+	TargetIDParam = "target_id"
+	FromParam     = "from"
+	ToParam       = "to"
+)
+
+// Filter narrows the audit log; zero fields leave that dimension open and set
+// fields are combined with AND
+type Filter struct {
+	Action   string
+	ActorID  *uint
+	TargetID *uint
+	// From and To bound the entry time, inclusive
+	From *time.Time
+	To   *time.Time
+}
+
+// ParseFilter reads the audit log filters from the query string. Times are
+// RFC3339 and IDs positive integers; anything else returns ErrInvalidFilter.
+func ParseFilter(c *gin.Context) (Filter, error) {
+	var filter Filter
+	var err error
+
+	filter.Action = strings.TrimSpace(c.Query(ActionParam))
+	if len(filter.Action) > 100 {
+		return Filter{}, fmt.Errorf("%w: %s must be at most 100 characters", ErrInvalidFilter, ActionParam)
+	}
+	if filter.ActorID, err = parseIDParam(c, ActorIDParam); err != nil {
+		return Filter{}, err
+	}
+	if filter.TargetID, err = parseIDParam(c, TargetIDParam); err != nil {
+		return Filter{}, err
+	}
+	if filter.From, err = parseTimeParam(c, FromParam); err != nil {
+		return Filter{}, err
+	}
+	if filter.To, err = parseTimeParam(c, ToParam); err != nil {
+		return Filter{}, err
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return Filter{}, fmt.Errorf("%w: %s must not be after %s", ErrInvalidFilter, FromParam, ToParam)
+	}
+
+	return filter, nil
+}
+
+func parseIDParam(c *gin.Context, name string) (*uint, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := db.ParseID(raw)
+	if errors.Is(err, db.ErrIDOutOfRange) {
+		return nil, fmt.Errorf("%w: %s must be at most %d", ErrInvalidFilter, name, db.MaxID)
+	}
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidFilter, name)
+	}
+	return &id, nil
+}
+
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	t, err := middleware.ParseTimeParam(c, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return t, nil
+}
+
+// apply adds the filter conditions to query
+func (f Filter) apply(query *gorm.DB) *gorm.DB {
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.ActorID != nil {
+		query = query.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.TargetID != nil {
+		query = query.Where("target_id = ?", *f.TargetID)
+	}
+	if f.From != nil {
+		query = query.Where("created_at >= ?", f.From.UTC())
+	}
+	if f.To != nil {
+		query = query.Where("created_at <= ?", f.To.UTC())
+	}
+	return query
+}
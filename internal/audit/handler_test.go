@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+func TestHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	logger := NewDBLogger(repo)
+	for i := uint(1); i <= 3; i++ {
+		require.NoError(t, logger.Record(context.Background(), Event{ActorID: 1, Action: ActionUserDelete, TargetID: uintPtr(i)}))
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/admin/audit", NewHandler(repo).List)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?page=1&per_page=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool              `json:"success"`
+		Data    EntryListResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, int64(3), response.Data.Total)
+	assert.Equal(t, 2, response.Data.TotalPages)
+	require.Len(t, response.Data.Entries, 2)
+	assert.Equal(t, ActionUserDelete, response.Data.Entries[0].Action)
+}
+
+func TestHandler_ListFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	logger := NewDBLogger(repo)
+	ctx := context.Background()
+	require.NoError(t, logger.Record(ctx, Event{ActorID: 1, Action: ActionUserPromote, TargetID: uintPtr(5)}))
+	require.NoError(t, logger.Record(ctx, Event{ActorID: 2, Action: ActionUserRoleRemove, TargetID: uintPtr(5)}))
+	require.NoError(t, logger.Record(ctx, Event{ActorID: 2, Action: ActionUserRoleRemove, TargetID: uintPtr(6)}))
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.GET("/api/v1/admin/audit", NewHandler(repo).List)
+
+	t.Run("filters combine", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?action=user.role_remove&actor_id=2&target_id=5&from=2000-01-01T00:00:00Z", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data EntryListResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(1), response.Data.Total)
+		assert.Equal(t, 1, response.Data.TotalPages)
+		require.Len(t, response.Data.Entries, 1)
+		assert.Equal(t, uint(2), response.Data.Entries[0].ActorID)
+		assert.Equal(t, uint(5), *response.Data.Entries[0].TargetID)
+	})
+
+	for query, message := range map[string]string{
+		"actor_id=abc":   "Invalid filter: actor_id must be a positive integer",
+		"target_id=0":    "Invalid filter: target_id must be a positive integer",
+		"from=yesterday": "Invalid filter: from must be an RFC3339 timestamp",
+		"from=2025-12-02T00:00:00Z&to=2025-12-01T00:00:00Z": "Invalid filter: from must not be after to",
+	} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), message)
+		})
+	}
+}
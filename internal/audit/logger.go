@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+// AuditLogger records sensitive administrative actions
+type AuditLogger interface {
+	Record(ctx context.Context, event Event) error
+}
+
+type dbLogger struct {
+	repo Repository
+}
+
+// NewDBLogger creates an AuditLogger that persists entries to the audit_logs table
+func NewDBLogger(repo Repository) AuditLogger {
+	return &dbLogger{repo: repo}
+}
+
+func (l *dbLogger) Record(ctx context.Context, event Event) error {
+	entry := &Entry{
+		ActorID:   event.ActorID,
+		Action:    event.Action,
+		TargetID:  event.TargetID,
+		Metadata:  event.Metadata,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := l.repo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates an AuditLogger that emits entries through the structured logger
+func NewSlogLogger(logger *slog.Logger) AuditLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Record(ctx context.Context, event Event) error {
+	attrs := []any{"actor_id", event.ActorID, "action", event.Action}
+	if id := tenant.FromContext(ctx); id != tenant.Default {
+		attrs = append(attrs, "tenant_id", id)
+	}
+	if event.TargetID != nil {
+		attrs = append(attrs, "target_id", *event.TargetID)
+	}
+	if len(event.Metadata) > 0 {
+		attrs = append(attrs, "metadata", event.Metadata)
+	}
+	l.logger.InfoContext(ctx, "audit", attrs...)
+	return nil
+}
@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&Entry{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestDBLogger_Record(t *testing.T) {
+	db := setupTestDB(t)
+	logger := NewDBLogger(NewRepository(db))
+
+	err := logger.Record(context.Background(), Event{
+		ActorID:  1,
+		Action:   ActionUserPromote,
+		TargetID: uintPtr(2),
+		Metadata: map[string]any{"role": "admin"},
+	})
+	require.NoError(t, err)
+
+	var entries []Entry
+	require.NoError(t, db.Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(1), entries[0].ActorID)
+	assert.Equal(t, ActionUserPromote, entries[0].Action)
+	require.NotNil(t, entries[0].TargetID)
+	assert.Equal(t, uint(2), *entries[0].TargetID)
+	assert.Equal(t, "admin", entries[0].Metadata["role"])
+	assert.False(t, entries[0].CreatedAt.IsZero())
+}
+
+func TestRepository_List(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	logger := NewDBLogger(repo)
+	ctx := context.Background()
+
+	for i := uint(1); i <= 3; i++ {
+		require.NoError(t, logger.Record(ctx, Event{ActorID: 1, Action: ActionUserUpdate, TargetID: uintPtr(i)}))
+	}
+
+	entries, total, err := repo.List(ctx, Filter{}, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint(3), *entries[0].TargetID, "newest entry first")
+
+	entries, _, err = repo.List(ctx, Filter{}, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(1), *entries[0].TargetID)
+}
+
+func TestRepository_ListTenants(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	logger := NewDBLogger(repo)
+	acme := tenant.NewContext(context.Background(), "acme")
+
+	require.NoError(t, logger.Record(context.Background(), Event{ActorID: 1, Action: ActionUserUpdate}))
+	require.NoError(t, logger.Record(acme, Event{ActorID: 2, Action: ActionUserDelete}))
+
+	entries, total, err := repo.List(acme, Filter{}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "acme", entries[0].TenantID)
+	assert.Equal(t, ActionUserDelete, entries[0].Action)
+
+	entries, total, err = repo.List(context.Background(), Filter{}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, tenant.Default, entries[0].TenantID)
+}
+
+func TestRepository_ListFilters(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 12, 0, 0, 0, time.UTC) }
+	seed := []Entry{
+		{ActorID: 1, Action: ActionUserPromote, TargetID: uintPtr(10), CreatedAt: day(1)},
+		{ActorID: 1, Action: ActionUserRoleRemove, TargetID: uintPtr(10), CreatedAt: day(2)},
+		{ActorID: 2, Action: ActionUserRoleRemove, TargetID: uintPtr(10), CreatedAt: day(3)},
+		{ActorID: 2, Action: ActionUserRoleRemove, TargetID: uintPtr(11), CreatedAt: day(4)},
+		{ActorID: 2, Action: ActionUserDelete, TargetID: uintPtr(11), CreatedAt: day(5)},
+	}
+	for i := range seed {
+		require.NoError(t, repo.Create(ctx, &seed[i]))
+	}
+	from, to := day(2), day(4)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []time.Time
+	}{
+		{"no filter", Filter{}, []time.Time{day(5), day(4), day(3), day(2), day(1)}},
+		{"action", Filter{Action: ActionUserRoleRemove}, []time.Time{day(4), day(3), day(2)}},
+		{"actor", Filter{ActorID: uintPtr(1)}, []time.Time{day(2), day(1)}},
+		{"target", Filter{TargetID: uintPtr(11)}, []time.Time{day(5), day(4)}},
+		{"date range is inclusive", Filter{From: &from, To: &to}, []time.Time{day(4), day(3), day(2)}},
+		{"from only", Filter{From: &to}, []time.Time{day(5), day(4)}},
+		{"action and actor", Filter{Action: ActionUserRoleRemove, ActorID: uintPtr(2)}, []time.Time{day(4), day(3)}},
+		{"action, target and date range", Filter{Action: ActionUserRoleRemove, TargetID: uintPtr(10), From: &from, To: &to}, []time.Time{day(3), day(2)}},
+		{"no match", Filter{Action: ActionUserDelete, ActorID: uintPtr(1)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := repo.List(ctx, tt.filter, 1, 20)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), total)
+
+			var got []time.Time
+			for _, entry := range entries {
+				got = append(got, entry.CreatedAt.UTC())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("total counts every match across pages", func(t *testing.T) {
+		entries, total, err := repo.List(ctx, Filter{ActorID: uintPtr(2)}, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, entries, 1)
+		assert.Equal(t, day(3), entries[0].CreatedAt.UTC())
+	})
+}
+
+func TestSlogLogger_Record(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	err := logger.Record(context.Background(), Event{
+		ActorID:  1,
+		Action:   ActionUserRevokeSessions,
+		TargetID: uintPtr(5),
+		Metadata: map[string]any{"reason": "compromised"},
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `"action":"user.revoke_sessions"`)
+	assert.Contains(t, out, `"actor_id":1`)
+	assert.Contains(t, out, `"target_id":5`)
+	assert.Contains(t, out, `"reason":"compromised"`)
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Actions recorded by the admin handlers
+const (
+	ActionUserPromote        = "user.promote"
+	ActionUserUpdate         = "user.update"
+	ActionUserDelete         = "user.delete"
+	ActionUserRestore        = "user.restore"
+	ActionUserRevokeSessions = "user.revoke_sessions"
+	ActionUserDeactivate     = "user.deactivate"
+	ActionUserReactivate     = "user.reactivate"
+	ActionUserRoleAssign     = "user.role_assign"
+	ActionUserRoleRemove     = "user.role_remove"
+)
+
+// Entry is a persisted audit record. TenantID is the tenant the action was
+// taken in.
+type Entry struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  string         `gorm:"index;not null;default:''" json:"-"`
+	ActorID   uint           `gorm:"not null;index:idx_audit_logs_actor_id_created_at,priority:1" json:"actor_id"`
+	Action    string         `gorm:"type:varchar(100);not null;index:idx_audit_logs_action_created_at,priority:1" json:"action"`
+	TargetID  *uint          `gorm:"index:idx_audit_logs_target_id_created_at,priority:1" json:"target_id,omitempty"`
+	Metadata  map[string]any `gorm:"serializer:json;type:text" json:"metadata,omitempty"`
+	CreatedAt time.Time      `gorm:"not null;index;index:idx_audit_logs_actor_id_created_at,priority:2;index:idx_audit_logs_action_created_at,priority:2;index:idx_audit_logs_target_id_created_at,priority:2" json:"created_at"`
+}
+
+// TableName specifies the table name for Entry, audit_logs with
+// the configured table prefix
+func (Entry) TableName(namer schema.Namer) string {
+	return namer.TableName("audit_log")
+}
+
+// BeforeCreate is a GORM hook that stamps entries in UTC, so date range
+// filters compare correctly on SQLite, which compares DATETIME as text
+func (e *Entry) BeforeCreate(tx *gorm.DB) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	} else {
+		e.CreatedAt = e.CreatedAt.UTC()
+	}
+	return nil
+}
+
+// Event describes an action to be audited
+type Event struct {
+	ActorID  uint           `json:"actor_id"`
+	Action   string         `json:"action"`
+	TargetID *uint          `json:"target_id,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// EntryListResponse represents a paginated list of audit entries
+type EntryListResponse struct {
+	Entries    []Entry `json:"entries"`
+	Total      int64   `json:"total"`
+	Page       int     `json:"page"`
+	PerPage    int     `json:"per_page"`
+	TotalPages int     `json:"total_pages"`
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+// Repository defines audit log persistence operations
+type Repository interface {
+	Create(ctx context.Context, entry *Entry) error
+	List(ctx context.Context, filter Filter, page, perPage int) ([]Entry, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit log repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// conn returns the transaction of the request in ctx, if any, or the repository's DB
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).WithContext(ctx)
+}
+
+// scoped returns the connection for ctx limited to the entries of the tenant in ctx
+func (r *repository) scoped(ctx context.Context) *gorm.DB {
+	return r.conn(ctx).Where("tenant_id = ?", tenant.FromContext(ctx))
+}
+
+// Create records entry in the tenant in ctx
+func (r *repository) Create(ctx context.Context, entry *Entry) error {
+	entry.TenantID = tenant.FromContext(ctx)
+	return r.conn(ctx).Create(entry).Error
+}
+
+// List returns the audit entries of the tenant in ctx matching filter, newest first
+func (r *repository) List(ctx context.Context, filter Filter, page, perPage int) ([]Entry, int64, error) {
+	var total int64
+	if err := filter.apply(r.scoped(ctx).Model(&Entry{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []Entry
+	err := filter.apply(r.scoped(ctx)).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
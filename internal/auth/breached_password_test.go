@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// pwnedHash returns the upper-case SHA-1 prefix and suffix the range API works with
+func pwnedHash(password string) (string, string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:5], hash[5:]
+}
+
+// newFakeRangeAPI serves the given range body for every prefix and records the requested paths
+func newFakeRangeAPI(t *testing.T, status int, body string) (*httptest.Server, *[]string) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		assert.NotEmpty(t, r.Header.Get("User-Agent"))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &paths
+}
+
+func TestPwnedPasswordsClient_IsBreached(t *testing.T) {
+	const password = "password123"
+	prefix, suffix := pwnedHash(password)
+
+	t.Run("matching suffix", func(t *testing.T) {
+		srv, paths := newFakeRangeAPI(t, http.StatusOK, fmt.Sprintf("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:251682\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n", suffix))
+
+		breached, err := NewPwnedPasswordsClient(srv.URL+"/range/", 0).IsBreached(context.Background(), password)
+		require.NoError(t, err)
+		assert.True(t, breached)
+		assert.Equal(t, []string{"/range/" + prefix}, *paths, "only the 5 character prefix is sent")
+	})
+
+	t.Run("base URL without trailing slash", func(t *testing.T) {
+		srv, paths := newFakeRangeAPI(t, http.StatusOK, suffix+":7\r\n")
+
+		breached, err := NewPwnedPasswordsClient(srv.URL+"/range", 0).IsBreached(context.Background(), password)
+		require.NoError(t, err)
+		assert.True(t, breached)
+		assert.Equal(t, []string{"/range/" + prefix}, *paths)
+	})
+
+	t.Run("lower-case suffix", func(t *testing.T) {
+		srv, _ := newFakeRangeAPI(t, http.StatusOK, strings.ToLower(suffix)+":3\n")
+
+		breached, err := NewPwnedPasswordsClient(srv.URL+"/range/", 0).IsBreached(context.Background(), password)
+		require.NoError(t, err)
+		assert.True(t, breached)
+	})
+
+	t.Run("no matching suffix", func(t *testing.T) {
+		srv, _ := newFakeRangeAPI(t, http.StatusOK, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n")
+
+		breached, err := NewPwnedPasswordsClient(srv.URL+"/range/", 0).IsBreached(context.Background(), password)
+		require.NoError(t, err)
+		assert.False(t, breached)
+	})
+
+	t.Run("padding entry", func(t *testing.T) {
+		srv, _ := newFakeRangeAPI(t, http.StatusOK, suffix+":0\r\n")
+
+		breached, err := NewPwnedPasswordsClient(srv.URL+"/range/", 0).IsBreached(context.Background(), password)
+		require.NoError(t, err)
+		assert.False(t, breached)
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		srv, _ := newFakeRangeAPI(t, http.StatusServiceUnavailable, "unavailable")
+
+		_, err := NewPwnedPasswordsClient(srv.URL+"/range/", 0).IsBreached(context.Background(), password)
+		assert.ErrorContains(t, err, "status 503")
+	})
+}
+
+type stubBreachChecker struct {
+	breached bool
+	err      error
+}
+
+func (s stubBreachChecker) IsBreached(context.Context, string) (bool, error) {
+	return s.breached, s.err
+}
+
+func TestPasswordPolicy_ValidateContext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("breached password is rejected", func(t *testing.T) {
+		policy := DefaultPasswordPolicy()
+		policy.BreachChecker = stubBreachChecker{breached: true}
+
+		var policyErr *PasswordPolicyError
+		require.ErrorAs(t, policy.ValidateContext(ctx, "password123"), &policyErr)
+		assert.Contains(t, policyErr.Reason, "data breach")
+	})
+
+	t.Run("lookup errors fail open", func(t *testing.T) {
+		policy := DefaultPasswordPolicy()
+		policy.BreachChecker = stubBreachChecker{err: errors.New("connection refused")}
+
+		assert.NoError(t, policy.ValidateContext(ctx, "password123"))
+	})
+
+	t.Run("rules are checked before the lookup", func(t *testing.T) {
+		policy := DefaultPasswordPolicy()
+		policy.BreachChecker = stubBreachChecker{err: errors.New("must not be called")}
+
+		assert.EqualError(t, policy.ValidateContext(ctx, "short"), "password must be at least 8 characters long")
+	})
+
+	t.Run("enabled from configuration", func(t *testing.T) {
+		_, suffix := pwnedHash("password123")
+		srv, _ := newFakeRangeAPI(t, http.StatusOK, suffix+":42\r\n")
+
+		policy := NewPasswordPolicy(config.PasswordConfig{BreachCheck: true, BreachCheckURL: srv.URL + "/range/"})
+		assert.Error(t, policy.ValidateContext(ctx, "password123"))
+		assert.NoError(t, policy.ValidateContext(ctx, "correct horse battery staple"))
+
+		assert.Nil(t, NewPasswordPolicy(config.PasswordConfig{}).BreachChecker, "the check is opt-in")
+	})
+
+	t.Run("unreachable API fails open", func(t *testing.T) {
+		srv, _ := newFakeRangeAPI(t, http.StatusOK, "")
+		srv.Close()
+
+		policy := NewPasswordPolicy(config.PasswordConfig{BreachCheck: true, BreachCheckURL: srv.URL + "/range/"})
+		assert.NoError(t, policy.ValidateContext(ctx, "password123"))
+	})
+}
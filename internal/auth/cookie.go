@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Token delivery modes of security.token_delivery
+const (
+	// TokenDeliveryBody returns the tokens in the response body
+	TokenDeliveryBody = "body"
+	// TokenDeliveryCookie sets the tokens as cookies that scripts cannot read
+	TokenDeliveryCookie = "cookie"
+	// TokenDeliveryBoth does both
+	TokenDeliveryBoth = "both"
+)
+
+const (
+	// AccessTokenCookie carries the access token when tokens are delivered as cookies
+	AccessTokenCookie = "access_token"
+	// RefreshTokenCookie carries the refresh token when tokens are delivered as cookies
+	RefreshTokenCookie = "refresh_token"
+
+	// refreshCookiePath keeps the refresh token from being sent to anything but the auth endpoints
+	refreshCookiePath = "/api/v1/auth"
+)
+
+// SetTokenCookies sets the tokens of pair as HttpOnly, SameSite=Strict cookies,
+// Secure unless secure is false. The refresh cookie expires after refreshTTL and
+// is only sent to the auth endpoints; a pair without a refresh token sets only
+// the access cookie.
+func SetTokenCookies(w http.ResponseWriter, pair *TokenPair, refreshTTL time.Duration, secure bool) {
+	http.SetCookie(w, tokenCookie(AccessTokenCookie, pair.AccessToken, "/", int(pair.ExpiresIn), secure))
+	if pair.RefreshToken != "" {
+		http.SetCookie(w, tokenCookie(RefreshTokenCookie, pair.RefreshToken, refreshCookiePath, int(refreshTTL.Seconds()), secure))
+	}
+}
+
+// ClearTokenCookies tells the browser to drop the token cookies. secure must
+// match the cookies being cleared.
+func ClearTokenCookies(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, tokenCookie(AccessTokenCookie, "", "/", -1, secure))
+	http.SetCookie(w, tokenCookie(RefreshTokenCookie, "", refreshCookiePath, -1, secure))
+}
+
+func tokenCookie(name, value, path string, maxAge int, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		// WHY: Cookies are sent automatically, so Strict keeps other sites from making authenticated requests
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// AccessTokenFromCookie returns the access token cookie of the request, if any
+func AccessTokenFromCookie(c *gin.Context) (string, bool) {
+	token, err := c.Cookie(AccessTokenCookie)
+	return token, err == nil && token != ""
+}
@@ -1,11 +1,50 @@
 package auth
 
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
 // Claims represents JWT token claims
 type Claims struct {
-	UserID uint     `json:"user_id"`
-	Email  string   `json:"email"`
-	Name   string   `json:"name"`
-	Roles  []string `json:"roles"`
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Roles     []string  `json:"roles"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TokenClaimsResponse is the non-sensitive view of an access token's claims.
+// Times are Unix seconds, as in the token itself.
+type TokenClaimsResponse struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+}
+
+// ToTokenClaimsResponse converts validated claims to their response form
+func ToTokenClaimsResponse(claims *Claims) TokenClaimsResponse {
+	roles := claims.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return TokenClaimsResponse{
+		Subject:   strconv.FormatUint(uint64(claims.UserID), 10),
+		Email:     claims.Email,
+		Name:      claims.Name,
+		Roles:     roles,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+	}
 }
 
 // TokenResponse represents token response (deprecated: use TokenPairResponse)
@@ -13,15 +52,25 @@ type TokenResponse struct {
 	Token string `json:"token"`
 }
 
-// TokenPairResponse represents access and refresh token pair response
-type TokenPairResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int64  `json:"expires_in"`
+// RefreshTokenSummary describes one refresh token without its hash
+type RefreshTokenSummary struct {
+	ID        uuid.UUID  `json:"id"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 }
 
-// RefreshTokenRequest represents refresh token request
-type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+// TokenFamilySummary groups the refresh tokens issued from one login, newest first.
+// Active reports whether the family still holds a token that can be redeemed.
+type TokenFamilySummary struct {
+	Family uuid.UUID             `json:"family"`
+	Active bool                  `json:"active"`
+	Tokens []RefreshTokenSummary `json:"tokens"`
 }
+
+// TokenPairResponse represents access and refresh token pair response
+type TokenPairResponse = api.TokenPairResponse
+
+// RefreshTokenRequest represents refresh token request
+type RefreshTokenRequest = api.RefreshTokenRequest
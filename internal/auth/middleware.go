@@ -1,10 +1,13 @@
 package auth
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
 )
 
 const (
@@ -16,34 +19,52 @@ const (
 	KeyUser = "user"
 )
 
-// AuthMiddleware creates a middleware that validates JWT tokens
+// AuthMiddleware creates a middleware that validates JWT tokens.
+// Expired tokens are reported with the TOKEN_EXPIRED code so clients know to
+// refresh; every other failure is UNAUTHORIZED and requires a new login.
+// Tokens of users that were deleted or deactivated since they were issued
+// are rejected too, as are tokens of another tenant than the request's.
+// Without an Authorization header, the access_token cookie is used.
 func AuthMiddleware(authService Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader(AuthorizationHeader)
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "authorization header required",
-			})
+		tokenString, ok := AccessTokenFromCookie(c)
+		if strings.TrimSpace(authHeader) != "" {
+			tokenString, ok = parseBearerToken(authHeader)
+			if !ok {
+				_ = c.Error(apiErrors.Unauthorized("Invalid authorization header format"))
+				c.Abort()
+				return
+			}
+		} else if !ok {
+			_ = c.Error(apiErrors.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid authorization header format",
-			})
+		claims, err := authService.ValidateToken(tokenString)
+		if err != nil {
+			if errors.Is(err, ErrExpiredToken) {
+				_ = c.Error(apiErrors.TokenExpired("Access token has expired"))
+			} else {
+				_ = c.Error(apiErrors.Unauthorized("Invalid access token"))
+			}
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
+		if claims.TenantID != tenant.FromContext(c.Request.Context()) {
+			_ = c.Error(apiErrors.Unauthorized("Access token belongs to another tenant"))
+			c.Abort()
+			return
+		}
 
-		claims, err := authService.ValidateToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid or expired token",
-			})
+		if err := authService.CheckUserStatus(c.Request.Context(), claims.UserID); err != nil {
+			if errors.Is(err, ErrUserInactive) {
+				_ = c.Error(apiErrors.Unauthorized("User account is no longer active"))
+			} else {
+				_ = c.Error(apiErrors.ServerError(err))
+			}
 			c.Abort()
 			return
 		}
@@ -53,6 +74,36 @@ func AuthMiddleware(authService Service) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware sets the JWT claims like AuthMiddleware when the request
+// carries a valid access token, but lets every request through. Routes that
+// require authentication still need AuthMiddleware.
+func OptionalAuthMiddleware(authService Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := parseBearerToken(c.GetHeader(AuthorizationHeader))
+		if !ok {
+			tokenString, ok = AccessTokenFromCookie(c)
+		}
+		if ok {
+			if claims, err := authService.ValidateToken(tokenString); err == nil &&
+				claims.TenantID == tenant.FromContext(c.Request.Context()) &&
+				authService.CheckUserStatus(c.Request.Context(), claims.UserID) == nil {
+				c.Set(KeyUser, claims)
+			}
+		}
+		c.Next()
+	}
+}
+
+// parseBearerToken extracts the token from an Authorization header value.
+// The scheme is matched case-insensitively and surrounding whitespace is ignored.
+func parseBearerToken(header string) (string, bool) {
+	fields := strings.Fields(header)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return "", false
+	}
+	return fields[1], true
+}
+
 // GetUserIDFromContext extracts user ID from gin context
 func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get(UserIDKey)
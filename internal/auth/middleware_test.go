@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
 )
 
 // MockAuthService is a mock implementation of Service interface
@@ -56,14 +60,32 @@ func (m *MockAuthService) RevokeUserRefreshToken(ctx context.Context, userID uin
 	return args.Error(0)
 }
 
-func (m *MockAuthService) RevokeAllUserTokens(ctx context.Context, userID uint) error {
+func (m *MockAuthService) RevokeAllUserTokens(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthService) ListUserTokens(ctx context.Context, userID uint) ([]TokenFamilySummary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]TokenFamilySummary), args.Error(1)
+}
+
+func (m *MockAuthService) CheckUserStatus(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockAuthService) CacheUserStatus(userID uint, active bool) {
+	m.Called(userID, active)
+}
+
 func setupTestRouter(authService Service) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
+	r.Use(apiErrors.ErrorHandler())
 
 	protected := r.Group("/api")
 	protected.Use(AuthMiddleware(authService))
@@ -80,7 +102,7 @@ func TestAuthMiddleware(t *testing.T) {
 		authHeader     string
 		setupMock      func(*MockAuthService)
 		expectedStatus int
-		expectedBody   string
+		expectedCode   string
 	}{
 		{
 			name:       "successful authentication",
@@ -92,37 +114,89 @@ func TestAuthMiddleware(t *testing.T) {
 					Name:   "Test User",
 				}
 				m.On("ValidateToken", "valid-token").Return(claims, nil)
+				m.On("CheckUserStatus", mock.Anything, uint(123)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "deleted or deactivated user",
+			authHeader: "Bearer valid-token",
+			setupMock: func(m *MockAuthService) {
+				m.On("ValidateToken", "valid-token").Return(&Claims{UserID: 123}, nil)
+				m.On("CheckUserStatus", mock.Anything, uint(123)).Return(ErrUserInactive)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   apiErrors.CodeUnauthorized,
+		},
+		{
+			name:       "user status lookup fails",
+			authHeader: "Bearer valid-token",
+			setupMock: func(m *MockAuthService) {
+				m.On("ValidateToken", "valid-token").Return(&Claims{UserID: 123}, nil)
+				m.On("CheckUserStatus", mock.Anything, uint(123)).Return(errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   apiErrors.CodeInternal,
+		},
+		{
+			name:       "lowercase scheme with extra spaces",
+			authHeader: "  bearer   valid-token ",
+			setupMock: func(m *MockAuthService) {
+				m.On("ValidateToken", "valid-token").Return(&Claims{UserID: 123}, nil)
+				m.On("CheckUserStatus", mock.Anything, uint(123)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "uppercase scheme",
+			authHeader: "BEARER valid-token",
+			setupMock: func(m *MockAuthService) {
+				m.On("ValidateToken", "valid-token").Return(&Claims{UserID: 123}, nil)
+				m.On("CheckUserStatus", mock.Anything, uint(123)).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"message":"success"}`,
 		},
 		{
 			name:           "missing authorization header",
 			authHeader:     "",
 			setupMock:      func(m *MockAuthService) {},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"authorization header required"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
+		},
+		{
+			name:           "blank authorization header",
+			authHeader:     "   ",
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 		{
 			name:           "invalid authorization header format - no Bearer",
 			authHeader:     "invalid-token",
 			setupMock:      func(m *MockAuthService) {},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid authorization header format"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 		{
 			name:           "invalid authorization header format - wrong scheme",
 			authHeader:     "Basic dGVzdDp0ZXN0",
 			setupMock:      func(m *MockAuthService) {},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid authorization header format"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 		{
 			name:           "invalid authorization header format - no token",
 			authHeader:     "Bearer",
 			setupMock:      func(m *MockAuthService) {},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid authorization header format"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
+		},
+		{
+			name:           "invalid authorization header format - too many parts",
+			authHeader:     "Bearer token extra",
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 		{
 			name:       "invalid token",
@@ -131,7 +205,7 @@ func TestAuthMiddleware(t *testing.T) {
 				m.On("ValidateToken", "invalid-token").Return(nil, ErrInvalidToken)
 			},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid or expired token"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 		{
 			name:       "expired token",
@@ -140,7 +214,7 @@ func TestAuthMiddleware(t *testing.T) {
 				m.On("ValidateToken", "expired-token").Return(nil, ErrExpiredToken)
 			},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid or expired token"}`,
+			expectedCode:   apiErrors.CodeTokenExpired,
 		},
 		{
 			name:       "service error",
@@ -149,7 +223,7 @@ func TestAuthMiddleware(t *testing.T) {
 				m.On("ValidateToken", "error-token").Return(nil, errors.New("service error"))
 			},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   `{"error":"invalid or expired token"}`,
+			expectedCode:   apiErrors.CodeUnauthorized,
 		},
 	}
 
@@ -169,13 +243,66 @@ func TestAuthMiddleware(t *testing.T) {
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if tt.expectedCode == "" {
+				assert.JSONEq(t, `{"message":"success"}`, w.Body.String())
+			} else {
+				var response apiErrors.Response
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.False(t, response.Success)
+				require.NotNil(t, response.Error)
+				assert.Equal(t, tt.expectedCode, response.Error.Code)
+			}
 
 			mockService.AssertExpectations(t)
 		})
 	}
 }
 
+func TestAuthMiddleware_Cookie(t *testing.T) {
+	mockService := &MockAuthService{}
+	mockService.On("ValidateToken", "cookie-token").Return(&Claims{UserID: 123}, nil)
+	mockService.On("ValidateToken", "header-token").Return(&Claims{UserID: 456}, nil)
+	mockService.On("CheckUserStatus", mock.Anything, mock.Anything).Return(nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.GET("/api/protected", AuthMiddleware(mockService), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.MustGet(KeyUser).(*Claims).UserID})
+	})
+
+	tests := []struct {
+		name       string
+		authHeader string
+		cookie     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "cookie without header", cookie: "cookie-token", wantStatus: http.StatusOK, wantBody: `{"user_id":123}`},
+		{name: "header wins over cookie", authHeader: "Bearer header-token", cookie: "cookie-token", wantStatus: http.StatusOK, wantBody: `{"user_id":456}`},
+		{name: "malformed header is not rescued by cookie", authHeader: "Token header-token", cookie: "cookie-token", wantStatus: http.StatusUnauthorized},
+		{name: "empty cookie", cookie: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/protected", nil)
+			if tt.authHeader != "" {
+				req.Header.Set(AuthorizationHeader, tt.authHeader)
+			}
+			req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: tt.cookie})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestAuthMiddleware_ContextSetting(t *testing.T) {
 	mockService := &MockAuthService{}
 	claims := &Claims{
@@ -184,6 +311,7 @@ func TestAuthMiddleware_ContextSetting(t *testing.T) {
 		Name:   "Test User",
 	}
 	mockService.On("ValidateToken", "valid-token").Return(claims, nil)
+	mockService.On("CheckUserStatus", mock.Anything, uint(123)).Return(nil)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -222,6 +350,52 @@ func TestAuthMiddleware_ContextSetting(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestOptionalAuthMiddleware(t *testing.T) {
+	mockService := &MockAuthService{}
+	mockService.On("ValidateToken", "valid-token").Return(&Claims{UserID: 123, Roles: []string{"admin"}}, nil)
+	mockService.On("ValidateToken", "invalid-token").Return(nil, ErrInvalidToken)
+	mockService.On("ValidateToken", "deleted-user-token").Return(&Claims{UserID: 456}, nil)
+	mockService.On("CheckUserStatus", mock.Anything, uint(123)).Return(nil)
+	mockService.On("CheckUserStatus", mock.Anything, uint(456)).Return(ErrUserInactive)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(OptionalAuthMiddleware(mockService))
+	r.GET("/test", func(c *gin.Context) {
+		var userID uint
+		if claims, ok := c.Get(KeyUser); ok {
+			userID = claims.(*Claims).UserID
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": userID})
+	})
+
+	tests := []struct {
+		name       string
+		authHeader string
+		wantUserID string
+	}{
+		{name: "valid token sets claims", authHeader: "Bearer valid-token", wantUserID: "123"},
+		{name: "invalid token passes through", authHeader: "Bearer invalid-token", wantUserID: "0"},
+		{name: "deleted user passes through", authHeader: "Bearer deleted-user-token", wantUserID: "0"},
+		{name: "malformed header passes through", authHeader: "Token valid-token", wantUserID: "0"},
+		{name: "no header passes through", wantUserID: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/test", nil)
+			if tt.authHeader != "" {
+				req.Header.Set(AuthorizationHeader, tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"user_id":`+tt.wantUserID+`}`, w.Body.String())
+		})
+	}
+}
+
 func TestGetUserIDFromContext(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
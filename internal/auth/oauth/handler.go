@@ -0,0 +1,193 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+const (
+	stateCookieName   = "oauth_state"
+	stateCookieMaxAge = 600
+	stateCookiePath   = "/api/v1/auth/oauth"
+)
+
+// errEmailNotVerified is returned when an unlinked identity's email is not provider-verified
+var errEmailNotVerified = errors.New("oauth email not verified")
+
+// UserProvisioner finds or creates the local account for a verified OAuth identity
+type UserProvisioner interface {
+	GetUserByID(ctx context.Context, id uint) (*user.User, error)
+	FindOrCreateOAuthUser(ctx context.Context, email, name string) (*user.User, error)
+	RecordLogin(ctx context.Context, userID uint)
+}
+
+// Handler handles the OAuth login redirect and callback
+type Handler struct {
+	provider    *Provider
+	users       UserProvisioner
+	authService auth.Service
+	identities  IdentityRepository
+}
+
+// NewHandler creates a new OAuth handler
+func NewHandler(provider *Provider, users UserProvisioner, authService auth.Service, identities IdentityRepository) *Handler {
+	return &Handler{
+		provider:    provider,
+		users:       users,
+		authService: authService,
+		identities:  identities,
+	}
+}
+
+// Login godoc
+// @Summary Start Google login
+// @Description Redirect to Google's consent page. A short-lived state cookie protects the callback against CSRF
+// @Tags auth
+// @Success 307 "Redirect to Google"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Failed to start OAuth flow"
+// @Router /api/v1/auth/oauth/google/login [get]
+func (h *Handler) Login(c *gin.Context) {
+	state, err := generateState()
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookieName, state, stateCookieMaxAge, stateCookiePath, "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusTemporaryRedirect, h.provider.AuthCodeURL(state))
+}
+
+// Callback godoc
+// @Summary Complete Google login
+// @Description Exchange the authorization code, provision the user on first login and issue a token pair
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} errors.Response{success=bool,data=user.AuthResponse} "Success response with user data and tokens"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Invalid state or missing code"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "OAuth authentication failed"
+// @Failure 403 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Account is disabled"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Internal server error"
+// @Router /api/v1/auth/oauth/google/callback [get]
+func (h *Handler) Callback(c *gin.Context) {
+	expectedState, err := c.Cookie(stateCookieName)
+	c.SetCookie(stateCookieName, "", -1, stateCookiePath, "", c.Request.TLS != nil, true)
+	if err != nil || expectedState == "" ||
+		subtle.ConstantTimeCompare([]byte(expectedState), []byte(c.Query("state"))) != 1 {
+		_ = c.Error(apiErrors.BadRequest("Invalid OAuth state"))
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		_ = c.Error(apiErrors.Unauthorized(fmt.Sprintf("OAuth authorization denied: %s", providerErr)))
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		_ = c.Error(apiErrors.BadRequest("Missing authorization code"))
+		return
+	}
+
+	profile, err := h.provider.Exchange(c.Request.Context(), code)
+	if err != nil {
+		_ = c.Error(apiErrors.Unauthorized("OAuth authentication failed"))
+		return
+	}
+
+	u, err := h.resolveUser(c.Request.Context(), profile)
+	if err != nil {
+		if errors.Is(err, errEmailNotVerified) {
+			_ = c.Error(apiErrors.Unauthorized("OAuth account email is not verified"))
+			return
+		}
+		if errors.Is(err, user.ErrLicenseLimitReached) {
+			_ = c.Error(apiErrors.LicenseLimitReached("The user limit of the license has been reached"))
+			return
+		}
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	if !u.Active {
+		_ = c.Error(apiErrors.AccountDisabled("Account is disabled"))
+		return
+	}
+
+	tokenPair, err := h.authService.GenerateTokenPair(c.Request.Context(), u.ID, u.Email, u.Name)
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	h.users.RecordLogin(c.Request.Context(), u.ID)
+
+	apiErrors.Respond(c, http.StatusOK, user.AuthResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    tokenPair.TokenType,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		User:         user.ToUserResponse(u),
+	})
+}
+
+// resolveUser logs in via a linked identity when one exists, otherwise matches or provisions
+// the account by verified email and links the identity for subsequent logins.
+func (h *Handler) resolveUser(ctx context.Context, profile *Profile) (*user.User, error) {
+	if profile.Subject != "" {
+		identity, err := h.identities.FindByProviderUserID(ctx, h.provider.Name(), profile.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up identity: %w", err)
+		}
+		if identity != nil {
+			return h.users.GetUserByID(ctx, identity.UserID)
+		}
+	}
+
+	// WHY: Matching by email is only safe when the provider has verified ownership of it
+	if !profile.EmailVerified {
+		return nil, errEmailNotVerified
+	}
+
+	u, err := h.users.FindOrCreateOAuthUser(ctx, profile.Email, profile.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Subject != "" {
+		identity := &Identity{
+			UserID:         u.ID,
+			Provider:       h.provider.Name(),
+			ProviderUserID: profile.Subject,
+			Email:          profile.Email,
+		}
+		if err := h.identities.Create(ctx, identity); err != nil {
+			// The user may already have a different account of this provider linked; login still succeeds
+			slog.WarnContext(ctx, "Failed to link OAuth identity", "provider", h.provider.Name(), "user_id", u.ID, "error", err)
+		}
+	}
+
+	return u, nil
+}
+
+func generateState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
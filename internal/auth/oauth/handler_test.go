@@ -0,0 +1,336 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+type mockProvisioner struct {
+	mock.Mock
+}
+
+func (m *mockProvisioner) GetUserByID(ctx context.Context, id uint) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockProvisioner) FindOrCreateOAuthUser(ctx context.Context, email, name string) (*user.User, error) {
+	args := m.Called(ctx, email, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockProvisioner) RecordLogin(ctx context.Context, userID uint) {
+	m.Called(ctx, userID)
+}
+
+type mockAuthService struct {
+	auth.Service
+	mock.Mock
+}
+
+func (m *mockAuthService) GenerateTokenPair(ctx context.Context, userID uint, email string, name string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, userID, email, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+// newFakeIdP serves the token and userinfo endpoints of an OpenID Connect provider.
+func newFakeIdP(t *testing.T, profile map[string]interface{}, userInfoStatus int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.Form.Get("code") != "valid-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"idp-access-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer idp-access-token", r.Header.Get("Authorization"))
+		if userInfoStatus != http.StatusOK {
+			w.WriteHeader(userInfoStatus)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(profile)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestProvider(idp *httptest.Server) *Provider {
+	return NewProvider("google", config.OAuthProviderConfig{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oauth/google/callback",
+	}, oauth2.Endpoint{
+		AuthURL:  idp.URL + "/auth",
+		TokenURL: idp.URL + "/token",
+	}, idp.URL+"/userinfo")
+}
+
+func TestHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp := newFakeIdP(t, nil, http.StatusOK)
+	handler := NewHandler(newTestProvider(idp), &mockProvisioner{}, &mockAuthService{}, NewIdentityRepository(setupTestDB(t)))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/google/login", nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/auth", location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "client-id", location.Query().Get("client_id"))
+	assert.Contains(t, location.Query().Get("scope"), "email")
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, stateCookieName, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, location.Query().Get("state"), cookies[0].Value)
+}
+
+func TestHandler_Callback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifiedProfile := map[string]interface{}{
+		"sub":            "google-123",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	}
+
+	tests := []struct {
+		name            string
+		profile         map[string]interface{}
+		userInfoStatus  int
+		cookieState     string
+		queryState      string
+		code            string
+		setupIdentities func(*testing.T, IdentityRepository)
+		setupMocks      func(*mockProvisioner, *mockAuthService)
+		expectedStatus  int
+		checkResponse   func(*testing.T, map[string]interface{})
+		checkIdentities func(*testing.T, IdentityRepository)
+	}{
+		{
+			name:           "new or existing user receives token pair",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupMocks: func(mp *mockProvisioner, ma *mockAuthService) {
+				mp.On("FindOrCreateOAuthUser", mock.Anything, "jane@example.com", "Jane Doe").
+					Return(&user.User{ID: 7, Name: "Jane Doe", Email: "jane@example.com", Active: true}, nil)
+				ma.On("GenerateTokenPair", mock.Anything, uint(7), "jane@example.com", "Jane Doe").
+					Return(&auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}, nil)
+				mp.On("RecordLogin", mock.Anything, uint(7)).Return()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "access", data["access_token"])
+				assert.Equal(t, "refresh", data["refresh_token"])
+				assert.Equal(t, "jane@example.com", data["user"].(map[string]interface{})["email"])
+			},
+			checkIdentities: func(t *testing.T, identities IdentityRepository) {
+				identity, err := identities.FindByProviderUserID(context.Background(), "google", "google-123")
+				require.NoError(t, err)
+				require.NotNil(t, identity, "first login links the identity")
+				assert.Equal(t, uint(7), identity.UserID)
+			},
+		},
+		{
+			name: "linked identity logs in its user regardless of email",
+			profile: map[string]interface{}{
+				"sub":            "google-123",
+				"email":          "other-address@example.com",
+				"email_verified": false,
+				"name":           "Jane Doe",
+			},
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupIdentities: func(t *testing.T, identities IdentityRepository) {
+				require.NoError(t, identities.Create(context.Background(), &Identity{UserID: 3, Provider: "google", ProviderUserID: "google-123"}))
+			},
+			setupMocks: func(mp *mockProvisioner, ma *mockAuthService) {
+				mp.On("GetUserByID", mock.Anything, uint(3)).
+					Return(&user.User{ID: 3, Name: "John Doe", Email: "john@example.com", Active: true}, nil)
+				ma.On("GenerateTokenPair", mock.Anything, uint(3), "john@example.com", "John Doe").
+					Return(&auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}, nil)
+				mp.On("RecordLogin", mock.Anything, uint(3)).Return()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, float64(3), data["user"].(map[string]interface{})["id"])
+			},
+		},
+		{
+			name:           "deactivated user is refused",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupMocks: func(mp *mockProvisioner, ma *mockAuthService) {
+				mp.On("FindOrCreateOAuthUser", mock.Anything, "jane@example.com", "Jane Doe").
+					Return(&user.User{ID: 7, Name: "Jane Doe", Email: "jane@example.com", Active: false}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, apiErrors.CodeAccountDisabled, errorInfo["code"])
+			},
+		},
+		{
+			name:           "state mismatch",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "forged",
+			code:           "valid-code",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing state cookie",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing code",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "code exchange fails",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "bad-code",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "userinfo endpoint fails",
+			profile:        verifiedProfile,
+			userInfoStatus: http.StatusInternalServerError,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unverified email is rejected",
+			profile: map[string]interface{}{
+				"sub":            "google-456",
+				"email":          "victim@example.com",
+				"email_verified": false,
+				"name":           "Mallory",
+			},
+			userInfoStatus: http.StatusOK,
+			cookieState:    "state-123",
+			queryState:     "state-123",
+			code:           "valid-code",
+			setupMocks:     func(mp *mockProvisioner, ma *mockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "OAuth account email is not verified", errorInfo["message"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newFakeIdP(t, tt.profile, tt.userInfoStatus)
+			provisioner := &mockProvisioner{}
+			authService := &mockAuthService{}
+			tt.setupMocks(provisioner, authService)
+
+			identities := NewIdentityRepository(setupTestDB(t))
+			if tt.setupIdentities != nil {
+				tt.setupIdentities(t, identities)
+			}
+
+			handler := NewHandler(newTestProvider(idp), provisioner, authService, identities)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			query := url.Values{}
+			query.Set("state", tt.queryState)
+			if tt.code != "" {
+				query.Set("code", tt.code)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/google/callback?"+query.Encode(), nil)
+			if tt.cookieState != "" {
+				req.AddCookie(&http.Cookie{Name: stateCookieName, Value: tt.cookieState})
+			}
+			c.Request = req
+
+			handler.Callback(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, response)
+			}
+
+			if tt.checkIdentities != nil {
+				tt.checkIdentities(t, identities)
+			}
+
+			provisioner.AssertExpectations(t)
+			authService.AssertExpectations(t)
+		})
+	}
+}
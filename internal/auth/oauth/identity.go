@@ -0,0 +1,87 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrIdentityNotFound is returned when no identity matches
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// Identity links an external provider account to a local user
+type Identity struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null;index;uniqueIndex:idx_oauth_identities_user_provider" json:"user_id"`
+	Provider       string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_oauth_identities_provider_subject;uniqueIndex:idx_oauth_identities_user_provider" json:"provider"`
+	ProviderUserID string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_oauth_identities_provider_subject" json:"provider_user_id"`
+	Email          string    `gorm:"type:varchar(255)" json:"email"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Identity, oauth_identities with
+// the configured table prefix
+func (Identity) TableName(namer schema.Namer) string {
+	return namer.TableName("oauth_identity")
+}
+
+// IdentityRepository defines persistence operations for linked identities
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *Identity) error
+	FindByProviderUserID(ctx context.Context, provider, providerUserID string) (*Identity, error)
+	ListByUserID(ctx context.Context, userID uint) ([]Identity, error)
+	Delete(ctx context.Context, userID uint, provider string) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// FindByProviderUserID returns nil, nil when the provider account is not linked
+func (r *identityRepository) FindByProviderUserID(ctx context.Context, provider, providerUserID string) (*Identity, error) {
+	var identity Identity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND provider_user_id = ?", provider, providerUserID).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) ListByUserID(ctx context.Context, userID uint) ([]Identity, error) {
+	var identities []Identity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error
+	return identities, err
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&Identity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
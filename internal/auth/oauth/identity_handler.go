@@ -0,0 +1,197 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+// LinkIdentityRequest represents a request to link a provider account to the current user
+type LinkIdentityRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// IdentityHandler manages the OAuth identities linked to the authenticated user
+type IdentityHandler struct {
+	providers  map[string]*Provider
+	identities IdentityRepository
+	users      UserProvisioner
+}
+
+// NewIdentityHandler creates a new identity handler for the given providers
+func NewIdentityHandler(identities IdentityRepository, users UserProvisioner, providers ...*Provider) *IdentityHandler {
+	byName := make(map[string]*Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &IdentityHandler{
+		providers:  byName,
+		identities: identities,
+		users:      users,
+	}
+}
+
+// List godoc
+// @Summary List linked identities
+// @Description List the OAuth provider accounts linked to the current user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} errors.Response{success=bool,data=[]Identity} "Linked identities"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Internal server error"
+// @Router /api/v1/users/me/identities [get]
+func (h *IdentityHandler) List(c *gin.Context) {
+	identities, err := h.identities.ListByUserID(c.Request.Context(), contextutil.GetUserID(c))
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	apiErrors.Respond(c, http.StatusOK, identities)
+}
+
+// Link godoc
+// @Summary Link an OAuth identity
+// @Description Exchange an authorization code obtained from the provider and link that account to the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LinkIdentityRequest true "Provider and authorization code"
+// @Success 201 {object} errors.Response{success=bool,data=Identity} "Identity linked"
+// @Success 200 {object} errors.Response{success=bool,data=Identity} "Identity was already linked"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unknown provider, validation error or failed code exchange"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Failure 409 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Identity linked to another user or provider already linked"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Internal server error"
+// @Router /api/v1/users/me/identities [post]
+func (h *IdentityHandler) Link(c *gin.Context) {
+	var req LinkIdentityRequest
+	if err := apiErrors.BindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	provider, ok := h.providers[req.Provider]
+	if !ok {
+		_ = c.Error(apiErrors.BadRequest("Unknown OAuth provider"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := contextutil.GetUserID(c)
+
+	profile, err := provider.Exchange(ctx, req.Code)
+	if err != nil || profile.Subject == "" {
+		_ = c.Error(apiErrors.BadRequest("OAuth authorization failed"))
+		return
+	}
+
+	existing, err := h.identities.FindByProviderUserID(ctx, provider.Name(), profile.Subject)
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			_ = c.Error(apiErrors.Conflict("This account is already linked to another user"))
+			return
+		}
+		apiErrors.Respond(c, http.StatusOK, existing)
+		return
+	}
+
+	linked, err := h.identities.ListByUserID(ctx, userID)
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+	if findProvider(linked, provider.Name()) != nil {
+		_ = c.Error(apiErrors.Conflict("A different account of this provider is already linked; unlink it first"))
+		return
+	}
+
+	identity := &Identity{
+		UserID:         userID,
+		Provider:       provider.Name(),
+		ProviderUserID: profile.Subject,
+		Email:          profile.Email,
+	}
+	if err := h.identities.Create(ctx, identity); err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	apiErrors.Respond(c, http.StatusCreated, identity)
+}
+
+// Unlink godoc
+// @Summary Unlink an OAuth identity
+// @Description Remove a linked provider account. Refused when it is the user's only way to sign in
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 204
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Failure 404 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Identity not found"
+// @Failure 409 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Cannot remove the last sign-in method"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Internal server error"
+// @Router /api/v1/users/me/identities/{provider} [delete]
+func (h *IdentityHandler) Unlink(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := contextutil.GetUserID(c)
+	providerName := c.Param("provider")
+
+	u, err := h.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			_ = c.Error(apiErrors.NotFound("User not found"))
+			return
+		}
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	linked, err := h.identities.ListByUserID(ctx, userID)
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+	if findProvider(linked, providerName) == nil {
+		_ = c.Error(apiErrors.NotFound("Identity not found"))
+		return
+	}
+
+	if !u.HasPassword() && len(linked) <= 1 {
+		_ = c.Error(apiErrors.Conflict("Cannot remove the last sign-in method; set a password or link another provider first"))
+		return
+	}
+
+	if err := h.identities.Delete(ctx, userID, providerName); err != nil {
+		if errors.Is(err, ErrIdentityNotFound) {
+			_ = c.Error(apiErrors.NotFound("Identity not found"))
+			return
+		}
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func findProvider(identities []Identity, provider string) *Identity {
+	for i := range identities {
+		if identities[i].Provider == provider {
+			return &identities[i]
+		}
+	}
+	return nil
+}
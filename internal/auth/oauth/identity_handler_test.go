@@ -0,0 +1,201 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func performIdentityRequest(handler gin.HandlerFunc, method, route, path, body string, userID uint) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: userID})
+		handler(c)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdentityHandler_Link(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	profile := map[string]interface{}{"sub": "google-123", "email": "john@gmail.com", "email_verified": true}
+
+	tests := []struct {
+		name            string
+		body            string
+		setupIdentities func(*testing.T, IdentityRepository)
+		expectedStatus  int
+	}{
+		{
+			name:           "links new identity",
+			body:           `{"provider":"google","code":"valid-code"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "already linked to same user is idempotent",
+			body: `{"provider":"google","code":"valid-code"}`,
+			setupIdentities: func(t *testing.T, r IdentityRepository) {
+				require.NoError(t, r.Create(context.Background(), &Identity{UserID: 1, Provider: "google", ProviderUserID: "google-123"}))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "linked to another user",
+			body: `{"provider":"google","code":"valid-code"}`,
+			setupIdentities: func(t *testing.T, r IdentityRepository) {
+				require.NoError(t, r.Create(context.Background(), &Identity{UserID: 2, Provider: "google", ProviderUserID: "google-123"}))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "different account of provider already linked",
+			body: `{"provider":"google","code":"valid-code"}`,
+			setupIdentities: func(t *testing.T, r IdentityRepository) {
+				require.NoError(t, r.Create(context.Background(), &Identity{UserID: 1, Provider: "google", ProviderUserID: "google-999"}))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown provider",
+			body:           `{"provider":"myspace","code":"valid-code"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid code",
+			body:           `{"provider":"google","code":"bad-code"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing fields",
+			body:           `{"provider":"google"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newFakeIdP(t, profile, http.StatusOK)
+			identities := NewIdentityRepository(setupTestDB(t))
+			if tt.setupIdentities != nil {
+				tt.setupIdentities(t, identities)
+			}
+			handler := NewIdentityHandler(identities, &mockProvisioner{}, newTestProvider(idp))
+
+			w := performIdentityRequest(handler.Link, http.MethodPost, "/identities", "/identities", tt.body, 1)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+
+			if tt.expectedStatus == http.StatusCreated {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "google", data["provider"])
+				assert.Equal(t, "google-123", data["provider_user_id"])
+				assert.Equal(t, float64(1), data["user_id"])
+			}
+		})
+	}
+}
+
+func TestIdentityHandler_Unlink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		user           *user.User
+		identities     []Identity
+		provider       string
+		expectedStatus int
+		remaining      int
+	}{
+		{
+			name:           "user with password can unlink only identity",
+			user:           &user.User{ID: 1, PasswordHash: "hash"},
+			identities:     []Identity{{UserID: 1, Provider: "google", ProviderUserID: "g-1"}},
+			provider:       "google",
+			expectedStatus: http.StatusNoContent,
+			remaining:      0,
+		},
+		{
+			name:           "oauth-only user cannot unlink last identity",
+			user:           &user.User{ID: 1},
+			identities:     []Identity{{UserID: 1, Provider: "google", ProviderUserID: "g-1"}},
+			provider:       "google",
+			expectedStatus: http.StatusConflict,
+			remaining:      1,
+		},
+		{
+			name: "oauth-only user can unlink one of two identities",
+			user: &user.User{ID: 1},
+			identities: []Identity{
+				{UserID: 1, Provider: "google", ProviderUserID: "g-1"},
+				{UserID: 1, Provider: "github", ProviderUserID: "gh-1"},
+			},
+			provider:       "github",
+			expectedStatus: http.StatusNoContent,
+			remaining:      1,
+		},
+		{
+			name:           "identity not linked",
+			user:           &user.User{ID: 1, PasswordHash: "hash"},
+			provider:       "google",
+			expectedStatus: http.StatusNotFound,
+			remaining:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities := NewIdentityRepository(setupTestDB(t))
+			for i := range tt.identities {
+				require.NoError(t, identities.Create(context.Background(), &tt.identities[i]))
+			}
+			provisioner := &mockProvisioner{}
+			provisioner.On("GetUserByID", mock.Anything, uint(1)).Return(tt.user, nil)
+
+			handler := NewIdentityHandler(identities, provisioner)
+
+			w := performIdentityRequest(handler.Unlink, http.MethodDelete, "/identities/:provider", "/identities/"+tt.provider, "", 1)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+
+			remaining, err := identities.ListByUserID(context.Background(), 1)
+			require.NoError(t, err)
+			assert.Len(t, remaining, tt.remaining)
+		})
+	}
+}
+
+func TestIdentityHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	identities := NewIdentityRepository(setupTestDB(t))
+	require.NoError(t, identities.Create(context.Background(), &Identity{UserID: 1, Provider: "google", ProviderUserID: "g-1"}))
+	require.NoError(t, identities.Create(context.Background(), &Identity{UserID: 2, Provider: "google", ProviderUserID: "g-2"}))
+
+	handler := NewIdentityHandler(identities, &mockProvisioner{})
+	w := performIdentityRequest(handler.List, http.MethodGet, "/identities", "/identities", "", 1)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].([]interface{})
+	require.Len(t, data, 1)
+	assert.Equal(t, "g-1", data[0].(map[string]interface{})["provider_user_id"])
+}
@@ -0,0 +1,76 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&Identity{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestIdentityRepository_CreateAndFind(t *testing.T) {
+	repo := NewIdentityRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	identity := &Identity{UserID: 1, Provider: "google", ProviderUserID: "sub-1", Email: "john@example.com"}
+	require.NoError(t, repo.Create(ctx, identity))
+	assert.NotZero(t, identity.ID)
+
+	found, err := repo.FindByProviderUserID(ctx, "google", "sub-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, uint(1), found.UserID)
+
+	missing, err := repo.FindByProviderUserID(ctx, "google", "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestIdentityRepository_UniqueConstraints(t *testing.T) {
+	repo := NewIdentityRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &Identity{UserID: 1, Provider: "google", ProviderUserID: "sub-1"}))
+
+	err := repo.Create(ctx, &Identity{UserID: 2, Provider: "google", ProviderUserID: "sub-1"})
+	assert.Error(t, err, "a provider account can only be linked once")
+
+	err = repo.Create(ctx, &Identity{UserID: 1, Provider: "google", ProviderUserID: "sub-2"})
+	assert.Error(t, err, "a user can link only one account per provider")
+
+	err = repo.Create(ctx, &Identity{UserID: 1, Provider: "github", ProviderUserID: "sub-1"})
+	assert.NoError(t, err)
+}
+
+func TestIdentityRepository_ListAndDelete(t *testing.T) {
+	repo := NewIdentityRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &Identity{UserID: 1, Provider: "google", ProviderUserID: "sub-1"}))
+	require.NoError(t, repo.Create(ctx, &Identity{UserID: 1, Provider: "github", ProviderUserID: "gh-1"}))
+	require.NoError(t, repo.Create(ctx, &Identity{UserID: 2, Provider: "google", ProviderUserID: "sub-2"}))
+
+	identities, err := repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, identities, 2)
+
+	require.NoError(t, repo.Delete(ctx, 1, "google"))
+	identities, err = repo.ListByUserID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "github", identities[0].Provider)
+
+	assert.ErrorIs(t, repo.Delete(ctx, 1, "google"), ErrIdentityNotFound)
+}
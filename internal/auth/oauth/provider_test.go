@@ -0,0 +1,32 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+)
+
+func TestProvider_ExchangeForwardsRequestID(t *testing.T) {
+	idp := newFakeIdP(t, map[string]interface{}{"sub": "google-123", "email": "jane@example.com"}, http.StatusOK)
+
+	// The token and userinfo requests are sequential, so the map needs no lock
+	received := map[string]string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received[r.URL.Path] = r.Header.Get(requestid.Header)
+		idp.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	profile, err := newTestProvider(proxy).Exchange(ctx, "valid-code")
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", profile.Email)
+
+	assert.Equal(t, map[string]string{"/token": "req-123", "/userinfo": "req-123"}, received)
+}
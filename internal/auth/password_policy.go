@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// DefaultMinPasswordLength is the minimum password length when none is configured
+const DefaultMinPasswordLength = 8
+
+// MaxPasswordBytes is the longest password accepted, in bytes; bcrypt fails on longer ones
+const MaxPasswordBytes = 72
+
+// passwordSpecialChars are the characters that satisfy RequireSpecial
+const passwordSpecialChars = `!@#$%^&*()_+-=[]{};':"\|,.<>/?`
+
+// PasswordPolicyError describes the rule a password failed
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+// PasswordPolicy holds the rules a new password must satisfy
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+	// BreachChecker, when set, makes ValidateContext reject passwords found in known breaches
+	BreachChecker BreachChecker
+}
+
+// DefaultPasswordPolicy only enforces DefaultMinPasswordLength
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: DefaultMinPasswordLength}
+}
+
+// StrongPasswordPolicy requires every character class; it is always used for admin accounts
+func StrongPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:      DefaultMinPasswordLength,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RequireSpecial: true,
+	}
+}
+
+// NewPasswordPolicy builds the policy from configuration, using DefaultMinPasswordLength
+// when no minimum length is set
+func NewPasswordPolicy(cfg config.PasswordConfig) PasswordPolicy {
+	policy := PasswordPolicy{
+		MinLength:      cfg.MinLength,
+		RequireUpper:   cfg.RequireUpper,
+		RequireLower:   cfg.RequireLower,
+		RequireDigit:   cfg.RequireDigit,
+		RequireSpecial: cfg.RequireSpecial,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = DefaultMinPasswordLength
+	}
+	if cfg.BreachCheck {
+		policy.BreachChecker = NewPwnedPasswordsClient(cfg.BreachCheckURL, cfg.BreachCheckTimeout)
+	}
+	return policy
+}
+
+// Validate returns a *PasswordPolicyError naming the first rule the password
+// fails. The minimum length counts characters; the maximum counts bytes.
+func (p PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("password must be at least %d characters long", p.MinLength)}
+	}
+	if len(password) > MaxPasswordBytes {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("password must be at most %d bytes long", MaxPasswordBytes)}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case strings.ContainsRune(passwordSpecialChars, r):
+			hasSpecial = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return &PasswordPolicyError{Reason: "password must contain at least one uppercase letter"}
+	}
+	if p.RequireLower && !hasLower {
+		return &PasswordPolicyError{Reason: "password must contain at least one lowercase letter"}
+	}
+	if p.RequireDigit && !hasDigit {
+		return &PasswordPolicyError{Reason: "password must contain at least one digit"}
+	}
+	if p.RequireSpecial && !hasSpecial {
+		return &PasswordPolicyError{Reason: "password must contain at least one special character"}
+	}
+
+	return nil
+}
+
+// ValidateContext runs Validate and then, when a BreachChecker is set, rejects
+// passwords known to be breached. The breach check fails open: lookup errors
+// are logged and the password is accepted so an outage never blocks sign-ups.
+func (p PasswordPolicy) ValidateContext(ctx context.Context, password string) error {
+	if err := p.Validate(password); err != nil {
+		return err
+	}
+	if p.BreachChecker == nil {
+		return nil
+	}
+
+	breached, err := p.BreachChecker.IsBreached(ctx, password)
+	if err != nil {
+		slog.WarnContext(ctx, "Breached password check failed, accepting password", "error", err)
+		return nil
+	}
+	if breached {
+		return &PasswordPolicyError{Reason: "password has appeared in a data breach, choose a different one"}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+func TestStrongPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		errorMsg string
+	}{
+		{name: "too short", password: "Pass1!", errorMsg: "password must be at least 8 characters long"},
+		{name: "missing uppercase", password: "password123!", errorMsg: "password must contain at least one uppercase letter"},
+		{name: "missing lowercase", password: "PASSWORD123!", errorMsg: "password must contain at least one lowercase letter"},
+		{name: "missing digit", password: "PasswordAbc!", errorMsg: "password must contain at least one digit"},
+		{name: "missing special character", password: "Password123", errorMsg: "password must contain at least one special character"},
+		{name: "only special chars", password: "!@#$%^&*", errorMsg: "password must contain at least one uppercase letter"},
+		{name: "non-ASCII letters are not a character class", password: "ÄÖÜäöü12!", errorMsg: "password must contain at least one uppercase letter"},
+		{name: "valid with all requirements", password: "Admin@2024Pass"},
+		{name: "exactly 8 characters valid", password: "Pass123!"},
+		{name: "length counts characters, not bytes", password: "Päss12!", errorMsg: "password must be at least 8 characters long"},
+		{name: "8 multibyte characters valid", password: "Päss123!"},
+		{name: "valid with brackets", password: "Pass[word]123"},
+		{name: "valid with backslash", password: `Pass\word123`},
+		{name: "valid with quotes", password: "Pass'word\"123"},
+		{name: "72 bytes valid", password: "Pass1!" + strings.Repeat("a", 66)},
+		{name: "over 72 bytes", password: "Pass1!" + strings.Repeat("a", 67), errorMsg: "password must be at most 72 bytes long"},
+		{name: "multibyte over 72 bytes", password: "Pass1!" + strings.Repeat("é", 34), errorMsg: "password must be at most 72 bytes long"},
+	}
+
+	policy := StrongPasswordPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.True(t, errors.As(err, &policyErr))
+			assert.Equal(t, tt.errorMsg, policyErr.Error())
+		})
+	}
+}
+
+func TestPasswordPolicy_ConfigurableRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PasswordConfig
+		password string
+		errorMsg string
+	}{
+		{name: "default length allows simple passwords", cfg: config.PasswordConfig{}, password: "password"},
+		{name: "default length", cfg: config.PasswordConfig{}, password: "pass123", errorMsg: "password must be at least 8 characters long"},
+		{name: "custom length", cfg: config.PasswordConfig{MinLength: 12}, password: "password123", errorMsg: "password must be at least 12 characters long"},
+		{name: "custom length satisfied", cfg: config.PasswordConfig{MinLength: 12}, password: "password1234"},
+		{name: "uppercase only", cfg: config.PasswordConfig{RequireUpper: true}, password: "password123", errorMsg: "password must contain at least one uppercase letter"},
+		{name: "lowercase only", cfg: config.PasswordConfig{RequireLower: true}, password: "PASSWORD123", errorMsg: "password must contain at least one lowercase letter"},
+		{name: "digit only", cfg: config.PasswordConfig{RequireDigit: true}, password: "password", errorMsg: "password must contain at least one digit"},
+		{name: "special only", cfg: config.PasswordConfig{RequireSpecial: true}, password: "password123", errorMsg: "password must contain at least one special character"},
+		{name: "disabled rules are skipped", cfg: config.PasswordConfig{RequireDigit: true}, password: "password1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewPasswordPolicy(tt.cfg).Validate(tt.password)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errorMsg)
+		})
+	}
+}
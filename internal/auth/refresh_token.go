@@ -9,10 +9,16 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
 )
 
 var (
 	ErrTokenDoesNotBelongToUser = errors.New("token does not belong to user")
+
+	// errTokenAlreadyUsed is returned by MarkAsUsed when another request marked the token first
+	errTokenAlreadyUsed = errors.New("token already used or not found")
 )
 
 // RefreshToken represents a refresh token in the database
@@ -27,20 +33,53 @@ type RefreshToken struct {
 	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
+// utcNow is the clock of the auth package. Token times are stored and compared
+// in UTC because SQLite keeps DATETIME values as text and compares them as
+// strings, so a time written with a non-UTC offset sorts wrongly against UTC.
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
 // BeforeCreate is a GORM hook that sets the ID and CreatedAt before creating the record
 func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
 	if rt.ID == uuid.Nil {
 		rt.ID = uuid.New()
 	}
 	if rt.CreatedAt.IsZero() {
-		rt.CreatedAt = time.Now()
+		rt.CreatedAt = utcNow()
 	}
 	return nil
 }
 
-// TableName specifies the table name for RefreshToken
-func (RefreshToken) TableName() string {
-	return "refresh_tokens"
+// BeforeSave is a GORM hook that stores every timestamp in UTC, whatever zone the caller used
+func (rt *RefreshToken) BeforeSave(tx *gorm.DB) error {
+	rt.toUTC()
+	return nil
+}
+
+// AfterFind is a GORM hook that returns timestamps in UTC on every database
+func (rt *RefreshToken) AfterFind(tx *gorm.DB) error {
+	rt.toUTC()
+	return nil
+}
+
+func (rt *RefreshToken) toUTC() {
+	rt.ExpiresAt = rt.ExpiresAt.UTC()
+	rt.CreatedAt = rt.CreatedAt.UTC()
+	if rt.UsedAt != nil {
+		usedAt := rt.UsedAt.UTC()
+		rt.UsedAt = &usedAt
+	}
+	if rt.RevokedAt != nil {
+		revokedAt := rt.RevokedAt.UTC()
+		rt.RevokedAt = &revokedAt
+	}
+}
+
+// TableName specifies the table name for RefreshToken, refresh_tokens with
+// the configured table prefix
+func (RefreshToken) TableName(namer schema.Namer) string {
+	return namer.TableName("refresh_token")
 }
 
 // RefreshTokenRepository defines the interface for refresh token operations
@@ -48,19 +87,21 @@ type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *RefreshToken) error
 	FindByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
 	FindByTokenFamily(ctx context.Context, tokenFamily uuid.UUID) ([]*RefreshToken, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*RefreshToken, error)
 	MarkAsUsed(ctx context.Context, id uuid.UUID) error
 	RevokeTokenFamily(ctx context.Context, tokenFamily uuid.UUID) error
-	RevokeByUserID(ctx context.Context, userID uint) error
+	RevokeByUserID(ctx context.Context, userID uint) (int64, error)
 	DeleteExpired(ctx context.Context) error
 }
 
 type refreshTokenRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	now func() time.Time
 }
 
 // NewRefreshTokenRepository creates a new refresh token repository
 func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
-	return &refreshTokenRepository{db: db}
+	return &refreshTokenRepository{db: db, now: utcNow}
 }
 
 // HashToken creates a SHA256 hash of the token
@@ -69,13 +110,18 @@ func HashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// conn returns the transaction of the request in ctx, if any, or the repository's DB
+func (r *refreshTokenRepository) conn(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).WithContext(ctx)
+}
+
 func (r *refreshTokenRepository) Create(ctx context.Context, token *RefreshToken) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	return r.conn(ctx).Create(token).Error
 }
 
 func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
 	var token RefreshToken
-	err := r.db.WithContext(ctx).
+	err := r.conn(ctx).
 		Where("token_hash = ?", tokenHash).
 		First(&token).Error
 	if err != nil {
@@ -86,7 +132,7 @@ func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash
 
 func (r *refreshTokenRepository) FindByTokenFamily(ctx context.Context, tokenFamily uuid.UUID) ([]*RefreshToken, error) {
 	var tokens []*RefreshToken
-	err := r.db.WithContext(ctx).
+	err := r.conn(ctx).
 		Where("token_family = ?", tokenFamily).
 		Order("created_at DESC").
 		Find(&tokens).Error
@@ -96,9 +142,21 @@ func (r *refreshTokenRepository) FindByTokenFamily(ctx context.Context, tokenFam
 	return tokens, nil
 }
 
+func (r *refreshTokenRepository) FindByUserID(ctx context.Context, userID uint) ([]*RefreshToken, error) {
+	var tokens []*RefreshToken
+	err := r.conn(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
 func (r *refreshTokenRepository) MarkAsUsed(ctx context.Context, id uuid.UUID) error {
-	now := time.Now()
-	result := r.db.WithContext(ctx).
+	now := r.now()
+	result := r.conn(ctx).
 		Model(&RefreshToken{}).
 		Where("id = ?", id).
 		Where("used_at IS NULL").
@@ -109,32 +167,33 @@ func (r *refreshTokenRepository) MarkAsUsed(ctx context.Context, id uuid.UUID) e
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.New("token already used or not found")
+		return errTokenAlreadyUsed
 	}
 
 	return nil
 }
 
 func (r *refreshTokenRepository) RevokeTokenFamily(ctx context.Context, tokenFamily uuid.UUID) error {
-	now := time.Now()
-	return r.db.WithContext(ctx).
+	now := r.now()
+	return r.conn(ctx).
 		Model(&RefreshToken{}).
 		Where("token_family = ?", tokenFamily).
 		Where("revoked_at IS NULL").
 		Update("revoked_at", now).Error
 }
 
-func (r *refreshTokenRepository) RevokeByUserID(ctx context.Context, userID uint) error {
-	now := time.Now()
-	return r.db.WithContext(ctx).
+func (r *refreshTokenRepository) RevokeByUserID(ctx context.Context, userID uint) (int64, error) {
+	now := r.now()
+	result := r.conn(ctx).
 		Model(&RefreshToken{}).
 		Where("user_id = ?", userID).
 		Where("revoked_at IS NULL").
-		Update("revoked_at", now).Error
+		Update("revoked_at", now)
+	return result.RowsAffected, result.Error
 }
 
 func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
-	return r.db.WithContext(ctx).
-		Where("expires_at < ?", time.Now()).
+	return r.conn(ctx).
+		Where("expires_at < ?", r.now()).
 		Delete(&RefreshToken{}).Error
 }
@@ -159,6 +159,31 @@ func TestRefreshTokenRepository_FindByTokenFamily(t *testing.T) {
 	assert.Equal(t, "hash1", tokens[1].TokenHash)
 }
 
+func TestRefreshTokenRepository_FindByUserID(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	ctx := context.Background()
+
+	for _, token := range []*RefreshToken{
+		{UserID: 1, TokenHash: "hash1", TokenFamily: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)},
+		{UserID: 2, TokenHash: "hash2", TokenFamily: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)},
+		{UserID: 1, TokenHash: "hash3", TokenFamily: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)},
+	} {
+		require.NoError(t, repo.Create(ctx, token))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tokens, err := repo.FindByUserID(ctx, 1)
+	assert.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "hash3", tokens[0].TokenHash)
+	assert.Equal(t, "hash1", tokens[1].TokenHash)
+
+	tokens, err = repo.FindByUserID(ctx, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
 func TestRefreshTokenRepository_MarkAsUsed(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRefreshTokenRepository(db)
@@ -251,8 +276,9 @@ func TestRefreshTokenRepository_RevokeByUserID(t *testing.T) {
 	err = repo.Create(ctx, token3)
 	require.NoError(t, err)
 
-	err = repo.RevokeByUserID(ctx, 1)
+	revoked, err := repo.RevokeByUserID(ctx, 1)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revoked)
 
 	var user1Tokens []RefreshToken
 	err = db.Where("user_id = ?", 1).Find(&user1Tokens).Error
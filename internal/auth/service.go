@@ -2,7 +2,10 @@ package auth
 
 import (
 	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -14,6 +17,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
 )
 
 var (
@@ -25,8 +30,16 @@ var (
 	ErrTokenReuse = errors.New("token reuse detected")
 	// ErrTokenRevoked is returned when a refresh token has been revoked
 	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrMissingSecret is returned when the service is constructed without a JWT secret
+	ErrMissingSecret = errors.New("jwt secret is required")
+	// ErrConcurrentRefresh is returned when another request rotated the same
+	// refresh token at the same time; the client should retry
+	ErrConcurrentRefresh = errors.New("refresh token is being rotated by a concurrent request")
 )
 
+// successorKeyLabel is the HKDF info deriving the successor token key from the JWT secret
+const successorKeyLabel = "refresh-successor"
+
 // TokenPair represents an access and refresh token pair
 type TokenPair struct {
 	AccessToken  string    `json:"access_token"`
@@ -44,22 +57,35 @@ type Service interface {
 	ValidateToken(tokenString string) (*Claims, error)
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
 	RevokeUserRefreshToken(ctx context.Context, userID uint, refreshToken string) error
-	RevokeAllUserTokens(ctx context.Context, userID uint) error
+	RevokeAllUserTokens(ctx context.Context, userID uint) (int64, error)
+	ListUserTokens(ctx context.Context, userID uint) ([]TokenFamilySummary, error)
+	CheckUserStatus(ctx context.Context, userID uint) error
+	CacheUserStatus(userID uint, active bool)
 }
 
 type service struct {
-	jwtSecret        string
-	accessTokenTTL   time.Duration
-	refreshTokenTTL  time.Duration
-	refreshTokenRepo RefreshTokenRepository
-	db               *gorm.DB
+	jwtSecret          string
+	successorKey       []byte
+	accessTokenTTL     time.Duration
+	refreshTokenTTL    time.Duration
+	accessOnlyFallback bool
+	refreshReuseGrace  time.Duration
+	leeway             time.Duration
+	notBeforeOffset    time.Duration
+	refreshTokenRepo   RefreshTokenRepository
+	userStatusRepo     UserStatusRepository
+	userStatus         *userStatusCache
+	db                 *gorm.DB
+	// now returns the current time in UTC; tests replace it to move the clock
+	now func() time.Time
 }
 
-// NewService creates a new authentication service using typed config
-func NewService(cfg *config.JWTConfig) Service {
-	jwtSecret := cfg.Secret
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-change-in-production"
+// NewServiceFromConfig creates a new authentication service using typed config.
+// A nil db yields a service without a refresh token store. It returns
+// ErrMissingSecret when no JWT secret is configured.
+func NewServiceFromConfig(cfg *config.JWTConfig, db *gorm.DB) (Service, error) {
+	if cfg.Secret == "" {
+		return nil, ErrMissingSecret
 	}
 
 	accessTokenTTL := cfg.AccessTokenTTL
@@ -76,61 +102,88 @@ func NewService(cfg *config.JWTConfig) Service {
 		refreshTokenTTL = 168 * time.Hour
 	}
 
-	return &service{
-		jwtSecret:       jwtSecret,
-		accessTokenTTL:  accessTokenTTL,
-		refreshTokenTTL: refreshTokenTTL,
+	// WHY: A key of its own, so successor tokens are not MACs made with the key that signs JWTs
+	successorKey, err := hkdf.Key(sha256.New, []byte(cfg.Secret), nil, successorKeyLabel, sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive successor token key: %w", err)
 	}
-}
 
-// NewServiceWithRepo creates a new authentication service with refresh token repository
-func NewServiceWithRepo(cfg *config.JWTConfig, db *gorm.DB) Service {
-	jwtSecret := cfg.Secret
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-change-in-production"
+	s := &service{
+		jwtSecret:          cfg.Secret,
+		successorKey:       successorKey,
+		accessTokenTTL:     accessTokenTTL,
+		refreshTokenTTL:    refreshTokenTTL,
+		accessOnlyFallback: cfg.AccessOnlyFallback,
+		refreshReuseGrace:  cfg.RefreshReuseGrace,
+		leeway:             time.Duration(cfg.Leeway) * time.Second,
+		notBeforeOffset:    cfg.NotBeforeOffset,
+		now:                utcNow,
 	}
-
-	accessTokenTTL := cfg.AccessTokenTTL
-	if accessTokenTTL == 0 {
-		if cfg.TTLHours > 0 {
-			accessTokenTTL = time.Duration(cfg.TTLHours) * time.Hour
-		} else {
-			accessTokenTTL = 15 * time.Minute
-		}
+	if db != nil {
+		s.refreshTokenRepo = NewRefreshTokenRepository(db)
+		s.userStatusRepo = NewUserStatusRepository(db)
+		s.userStatus = newUserStatusCache(cfg.UserStatusCacheTTL, utcNow)
+		s.db = db
 	}
 
-	refreshTokenTTL := cfg.RefreshTokenTTL
-	if refreshTokenTTL == 0 {
-		refreshTokenTTL = 168 * time.Hour
-	}
+	return s, nil
+}
+
+// NewService creates a new authentication service using typed config.
+// It panics when no JWT secret is configured; use NewServiceFromConfig to handle the error.
+func NewService(cfg *config.JWTConfig) Service {
+	return mustService(NewServiceFromConfig(cfg, nil))
+}
 
-	return &service{
-		jwtSecret:        jwtSecret,
-		accessTokenTTL:   accessTokenTTL,
-		refreshTokenTTL:  refreshTokenTTL,
-		refreshTokenRepo: NewRefreshTokenRepository(db),
-		db:               db,
+// NewServiceWithRepo creates a new authentication service with refresh token repository.
+// It panics when no JWT secret is configured; use NewServiceFromConfig to handle the error.
+func NewServiceWithRepo(cfg *config.JWTConfig, db *gorm.DB) Service {
+	return mustService(NewServiceFromConfig(cfg, db))
+}
+
+func mustService(s Service, err error) Service {
+	if err != nil {
+		panic(err)
 	}
+	return s
 }
 
 // GenerateToken generates a JWT token for a user (deprecated: use GenerateTokenPair)
 func (s *service) GenerateToken(userID uint, email string, name string) (string, error) {
-	now := time.Now()
-	expirationTime := now.Add(s.accessTokenTTL)
+	token, _, err := s.generateToken(context.Background(), userID, email, name)
+	return token, err
+}
+
+// generateToken reads the user's roles through the transaction in ctx, if any,
+// so roles assigned earlier in it are included. It also returns the seconds
+// from issuance to the token's exp, which include the not-before offset.
+func (s *service) generateToken(ctx context.Context, userID uint, email string, name string) (string, int64, error) {
+	now := s.now()
+	// WHY: A token issued for later activation is valid for the full TTL once active
+	notBefore := now.Add(s.notBeforeOffset)
+	expirationTime := notBefore.Add(s.accessTokenTTL)
 
 	var roles []string
+	tenantID := tenant.Default
 	if s.db != nil {
 		var roleNames []string
-		err := s.db.Table("roles").
+		err := db.Conn(ctx, s.db).WithContext(ctx).Table(db.AliasTable(s.db, "roles")).
 			Select("roles.name").
-			Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+			Joins("JOIN "+db.AliasTable(s.db, "user_roles")+" ON user_roles.role_id = roles.id").
 			Where("user_roles.user_id = ?", userID).
 			Find(&roleNames).Error
 		if err != nil {
 			// WHY: Security-critical - token with empty roles bypasses authorization
-			return "", fmt.Errorf("failed to fetch user roles: %w", err)
+			return "", 0, fmt.Errorf("failed to fetch user roles: %w", err)
 		}
 		roles = roleNames
+
+		// WHY: The tenant comes from the user's row, never from the request, so a token cannot be minted for another tenant
+		err = db.Conn(ctx, s.db).WithContext(ctx).Table(db.Table(s.db, "users")).
+			Select("tenant_id").Where("id = ?", userID).Scan(&tenantID).Error
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to fetch user tenant: %w", err)
+		}
 	}
 
 	claims := jwt.MapClaims{
@@ -141,14 +194,20 @@ func (s *service) GenerateToken(userID uint, email string, name string) (string,
 		"exp":   expirationTime.Unix(),
 		"iat":   now.Unix(),
 	}
+	if s.notBeforeOffset > 0 {
+		claims["nbf"] = notBefore.Unix()
+	}
+	if tenantID != tenant.Default {
+		claims["tenant_id"] = tenantID
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.jwtSecret))
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return "", 0, fmt.Errorf("failed to sign token: %w", err)
 	}
 
-	return tokenString, nil
+	return tokenString, expirationTime.Unix() - now.Unix(), nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -158,7 +217,7 @@ func (s *service) ValidateToken(tokenString string) (*Claims, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(s.jwtSecret), nil
-	})
+	}, jwt.WithLeeway(s.leeway), jwt.WithTimeFunc(s.now))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -188,6 +247,7 @@ func (s *service) ValidateToken(tokenString string) (*Claims, error) {
 
 	email, _ := claims["email"].(string)
 	name, _ := claims["name"].(string)
+	tenantID, _ := claims["tenant_id"].(string)
 
 	var roles []string
 	if rolesInterface, ok := claims["roles"].([]interface{}); ok {
@@ -198,21 +258,32 @@ func (s *service) ValidateToken(tokenString string) (*Claims, error) {
 		}
 	}
 
-	return &Claims{
-		UserID: uint(userID),
-		Email:  email,
-		Name:   name,
-		Roles:  roles,
-	}, nil
+	result := &Claims{
+		UserID:   uint(userID),
+		Email:    email,
+		Name:     name,
+		Roles:    roles,
+		TenantID: tenantID,
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = exp.UTC()
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		result.IssuedAt = iat.UTC()
+	}
+	return result, nil
 }
 
 // GenerateTokenPair generates both access and refresh tokens with rotation support
 func (s *service) GenerateTokenPair(ctx context.Context, userID uint, email string, name string) (*TokenPair, error) {
 	if s.refreshTokenRepo == nil {
+		if s.accessOnlyFallback {
+			return s.generateAccessOnlyPair(ctx, userID, email, name)
+		}
 		return nil, errors.New("refresh token repository not initialized")
 	}
 
-	accessToken, err := s.GenerateToken(userID, email, name)
+	accessToken, expiresIn, err := s.generateToken(ctx, userID, email, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -229,7 +300,7 @@ func (s *service) GenerateTokenPair(ctx context.Context, userID uint, email stri
 		UserID:      userID,
 		TokenHash:   refreshTokenHash,
 		TokenFamily: tokenFamily,
-		ExpiresAt:   time.Now().Add(s.refreshTokenTTL),
+		ExpiresAt:   s.now().Add(s.refreshTokenTTL),
 	}
 
 	if err := s.refreshTokenRepo.Create(ctx, dbToken); err != nil {
@@ -240,11 +311,25 @@ func (s *service) GenerateTokenPair(ctx context.Context, userID uint, email stri
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+		ExpiresIn:    expiresIn,
 		TokenFamily:  tokenFamily,
 	}, nil
 }
 
+// generateAccessOnlyPair issues a token pair without a refresh token for services running without a refresh token store
+func (s *service) generateAccessOnlyPair(ctx context.Context, userID uint, email string, name string) (*TokenPair, error) {
+	accessToken, expiresIn, err := s.generateToken(ctx, userID, email, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   expiresIn,
+	}, nil
+}
+
 // RefreshAccessToken validates refresh token and generates new token pair with rotation
 func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
 	if s.refreshTokenRepo == nil {
@@ -265,50 +350,51 @@ func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string) (
 		return nil, ErrTokenRevoked
 	}
 
-	if time.Now().After(storedToken.ExpiresAt) {
+	if s.now().After(storedToken.ExpiresAt) {
 		return nil, ErrExpiredToken
 	}
 
 	if storedToken.UsedAt != nil {
-		if err := s.refreshTokenRepo.RevokeTokenFamily(ctx, storedToken.TokenFamily); err != nil {
+		pair, err := s.retryWithinGrace(ctx, storedToken, refreshToken)
+		if err != nil {
+			return nil, err
+		}
+		if pair != nil {
+			return pair, nil
+		}
+		// WHY: The request fails with ErrTokenReuse, which rolls back a request transaction; the revocation must stick
+		if err := s.refreshTokenRepo.RevokeTokenFamily(db.WithoutTx(ctx), storedToken.TokenFamily); err != nil {
 			return nil, fmt.Errorf("failed to revoke token family: %w", err)
 		}
 		return nil, ErrTokenReuse
 	}
 
 	if err := s.refreshTokenRepo.MarkAsUsed(ctx, storedToken.ID); err != nil {
+		if errors.Is(err, errTokenAlreadyUsed) {
+			return nil, ErrConcurrentRefresh
+		}
 		return nil, fmt.Errorf("failed to mark token as used: %w", err)
 	}
 
-	type userModel struct {
-		ID    uint
-		Email string
-		Name  string
-	}
-	var user userModel
-	if err := s.db.WithContext(ctx).Table("users").Select("id, email, name").Where("id = ?", storedToken.UserID).First(&user).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch user for token claims: %w", err)
-	}
-
-	accessToken, err := s.GenerateToken(storedToken.UserID, user.Email, user.Name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	newRefreshToken, err := generateRandomToken()
+	accessToken, expiresIn, err := s.accessTokenForUser(ctx, storedToken.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate new refresh token: %w", err)
+		return nil, err
 	}
 
+	newRefreshToken := s.successorToken(refreshToken)
 	newTokenHash := HashToken(newRefreshToken)
 	newDBToken := &RefreshToken{
 		UserID:      storedToken.UserID,
 		TokenHash:   newTokenHash,
 		TokenFamily: storedToken.TokenFamily,
-		ExpiresAt:   time.Now().Add(s.refreshTokenTTL),
+		ExpiresAt:   s.now().Add(s.refreshTokenTTL),
 	}
 
 	if err := s.refreshTokenRepo.Create(ctx, newDBToken); err != nil {
+		// WHY: The successor is derived from the token, so a duplicate means another request rotated it first
+		if db.IsUniqueViolation(err) {
+			return nil, ErrConcurrentRefresh
+		}
 		return nil, fmt.Errorf("failed to store new refresh token: %w", err)
 	}
 
@@ -316,11 +402,78 @@ func (s *service) RefreshAccessToken(ctx context.Context, refreshToken string) (
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+		ExpiresIn:    expiresIn,
+		TokenFamily:  storedToken.TokenFamily,
+	}, nil
+}
+
+// retryWithinGrace handles a used refresh token presented again shortly after rotation, e.g. a
+// client retrying after a dropped response. It returns a pair carrying the successor already
+// issued for refreshToken when the rotation happened within the reuse grace window and that
+// successor is still unused; otherwise it returns nil and the caller treats it as reuse.
+func (s *service) retryWithinGrace(ctx context.Context, storedToken *RefreshToken, refreshToken string) (*TokenPair, error) {
+	if s.refreshReuseGrace <= 0 || s.now().Sub(*storedToken.UsedAt) > s.refreshReuseGrace {
+		return nil, nil
+	}
+
+	successor := s.successorToken(refreshToken)
+	successorToken, err := s.refreshTokenRepo.FindByTokenHash(ctx, HashToken(successor))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find successor refresh token: %w", err)
+	}
+
+	// WHY: Once the successor has been rotated too, the previous token is no longer the
+	// immediately-previous one in the family and a replay of it is treated as an attack
+	if successorToken.TokenFamily != storedToken.TokenFamily || successorToken.UsedAt != nil || successorToken.RevokedAt != nil {
+		return nil, nil
+	}
+
+	accessToken, expiresIn, err := s.accessTokenForUser(ctx, storedToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: successor,
+		TokenType:    "Bearer",
+		ExpiresIn:    expiresIn,
 		TokenFamily:  storedToken.TokenFamily,
 	}, nil
 }
 
+// successorToken derives the refresh token issued when refreshToken is rotated. Deriving it
+// with a key from the JWT secret lets a retry within the grace window receive the same
+// successor even though only token hashes are stored.
+func (s *service) successorToken(refreshToken string) string {
+	mac := hmac.New(sha256.New, s.successorKey)
+	mac.Write([]byte(refreshToken))
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// accessTokenForUser issues an access token with the user's current email and name,
+// returning it with its expires_in like generateToken
+func (s *service) accessTokenForUser(ctx context.Context, userID uint) (string, int64, error) {
+	type userModel struct {
+		ID    uint
+		Email string
+		Name  string
+	}
+	var user userModel
+	if err := db.Conn(ctx, s.db).WithContext(ctx).Table(db.Table(s.db, "users")).Select("id, email, name").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", 0, fmt.Errorf("failed to fetch user for token claims: %w", err)
+	}
+
+	accessToken, expiresIn, err := s.generateToken(ctx, userID, user.Email, user.Name)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return accessToken, expiresIn, nil
+}
+
 // RevokeRefreshToken revokes a specific refresh token
 func (s *service) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
 	if s.refreshTokenRepo == nil {
@@ -361,15 +514,54 @@ func (s *service) RevokeUserRefreshToken(ctx context.Context, userID uint, refre
 	return s.refreshTokenRepo.RevokeTokenFamily(ctx, storedToken.TokenFamily)
 }
 
-// RevokeAllUserTokens revokes all refresh tokens for a user
-func (s *service) RevokeAllUserTokens(ctx context.Context, userID uint) error {
+// RevokeAllUserTokens revokes all active refresh tokens for a user and returns how many were revoked
+func (s *service) RevokeAllUserTokens(ctx context.Context, userID uint) (int64, error) {
 	if s.refreshTokenRepo == nil {
-		return errors.New("refresh token repository not initialized")
+		return 0, errors.New("refresh token repository not initialized")
 	}
 
 	return s.refreshTokenRepo.RevokeByUserID(ctx, userID)
 }
 
+// ListUserTokens returns the user's refresh tokens grouped by family, most
+// recently issued family first. Token hashes are never included.
+func (s *service) ListUserTokens(ctx context.Context, userID uint) ([]TokenFamilySummary, error) {
+	if s.refreshTokenRepo == nil {
+		return nil, errors.New("refresh token repository not initialized")
+	}
+
+	tokens, err := s.refreshTokenRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	families := []TokenFamilySummary{}
+	index := make(map[uuid.UUID]int)
+	for _, token := range tokens {
+		i, ok := index[token.TokenFamily]
+		if !ok {
+			i = len(families)
+			index[token.TokenFamily] = i
+			families = append(families, TokenFamilySummary{Family: token.TokenFamily})
+		}
+
+		family := &families[i]
+		family.Tokens = append(family.Tokens, RefreshTokenSummary{
+			ID:        token.ID,
+			IssuedAt:  token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+			UsedAt:    token.UsedAt,
+			RevokedAt: token.RevokedAt,
+		})
+		if token.UsedAt == nil && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			family.Active = true
+		}
+	}
+
+	return families, nil
+}
+
 // generateRandomToken generates a cryptographically secure random token
 func generateRandomToken() (string, error) {
 	b := make([]byte, 32)
@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"testing"
 	"time"
 
@@ -17,9 +20,11 @@ import (
 // testUser is a minimal user struct for testing
 type testUser struct {
 	ID           uint   `gorm:"primaryKey"`
+	TenantID     string `gorm:"not null;default:''"`
 	Name         string `gorm:"not null"`
 	Email        string `gorm:"uniqueIndex;not null"`
 	PasswordHash string `gorm:"not null"`
+	Active       bool   `gorm:"not null;default:true"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
@@ -93,10 +98,12 @@ func setupServiceTest(t *testing.T) (*service, *gorm.DB) {
 
 	svc := &service{
 		jwtSecret:        cfg.Secret,
+		successorKey:     []byte("test-successor-key"),
 		accessTokenTTL:   cfg.AccessTokenTTL,
 		refreshTokenTTL:  cfg.RefreshTokenTTL,
 		refreshTokenRepo: NewRefreshTokenRepository(db),
 		db:               db,
+		now:              utcNow,
 	}
 
 	return svc, db
@@ -159,6 +166,78 @@ func TestService_RefreshAccessToken_ReuseDetection(t *testing.T) {
 	}
 }
 
+func TestService_RefreshAccessToken_ReuseGrace(t *testing.T) {
+	assertFamilyRevoked := func(t *testing.T, db *gorm.DB, family uuid.UUID, revoked bool) {
+		var tokens []RefreshToken
+		require.NoError(t, db.Where("token_family = ?", family).Find(&tokens).Error)
+		for _, token := range tokens {
+			assert.Equal(t, revoked, token.RevokedAt != nil)
+		}
+	}
+
+	t.Run("retry within grace returns the same successor", func(t *testing.T) {
+		svc, db := setupServiceTest(t)
+		svc.refreshReuseGrace = time.Minute
+		ctx := context.Background()
+
+		originalPair, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+		require.NoError(t, err)
+
+		rotated, err := svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		require.NoError(t, err)
+
+		retried, err := svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, rotated.RefreshToken, retried.RefreshToken)
+		assert.Equal(t, originalPair.TokenFamily, retried.TokenFamily)
+		assert.NotEmpty(t, retried.AccessToken)
+		assertFamilyRevoked(t, db, originalPair.TokenFamily, false)
+
+		// The successor keeps working after the retry
+		_, err = svc.RefreshAccessToken(ctx, retried.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("replay after the grace window revokes the family", func(t *testing.T) {
+		svc, db := setupServiceTest(t)
+		svc.refreshReuseGrace = time.Minute
+		ctx := context.Background()
+
+		originalPair, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+		require.NoError(t, err)
+
+		_, err = svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		require.NoError(t, err)
+
+		usedAt := time.Now().Add(-2 * time.Minute)
+		require.NoError(t, db.Model(&RefreshToken{}).
+			Where("token_hash = ?", HashToken(originalPair.RefreshToken)).
+			Update("used_at", usedAt).Error)
+
+		_, err = svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		assert.ErrorIs(t, err, ErrTokenReuse)
+		assertFamilyRevoked(t, db, originalPair.TokenFamily, true)
+	})
+
+	t.Run("replay after the successor was rotated revokes the family", func(t *testing.T) {
+		svc, db := setupServiceTest(t)
+		svc.refreshReuseGrace = time.Minute
+		ctx := context.Background()
+
+		originalPair, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+		require.NoError(t, err)
+
+		rotated, err := svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		require.NoError(t, err)
+		_, err = svc.RefreshAccessToken(ctx, rotated.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = svc.RefreshAccessToken(ctx, originalPair.RefreshToken)
+		assert.ErrorIs(t, err, ErrTokenReuse)
+		assertFamilyRevoked(t, db, originalPair.TokenFamily, true)
+	})
+}
+
 func TestService_RefreshAccessToken_InvalidToken(t *testing.T) {
 	svc, _ := setupServiceTest(t)
 	ctx := context.Background()
@@ -237,8 +316,9 @@ func TestService_RevokeAllUserTokens(t *testing.T) {
 	pair3, err := svc.GenerateTokenPair(ctx, 2, "user2@example.com", "User 2")
 	require.NoError(t, err)
 
-	err = svc.RevokeAllUserTokens(ctx, 1)
+	revoked, err := svc.RevokeAllUserTokens(ctx, 1)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revoked)
 
 	var user1Tokens []RefreshToken
 	err = db.Where("user_id = ?", 1).Find(&user1Tokens).Error
@@ -259,6 +339,49 @@ func TestService_RevokeAllUserTokens(t *testing.T) {
 	_ = pair3
 }
 
+func TestService_ListUserTokens(t *testing.T) {
+	svc, _ := setupServiceTest(t)
+	ctx := context.Background()
+
+	rotated, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+	require.NoError(t, err)
+	other, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+	require.NoError(t, err)
+	_, err = svc.GenerateTokenPair(ctx, 2, "user2@example.com", "User 2")
+	require.NoError(t, err)
+	_, err = svc.RefreshAccessToken(ctx, rotated.RefreshToken)
+	require.NoError(t, err)
+
+	families, err := svc.ListUserTokens(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+
+	assert.Equal(t, rotated.TokenFamily, families[0].Family, "the most recently used family comes first")
+	assert.True(t, families[0].Active)
+	require.Len(t, families[0].Tokens, 2)
+	assert.Nil(t, families[0].Tokens[0].UsedAt)
+	assert.NotNil(t, families[0].Tokens[1].UsedAt)
+	assert.Equal(t, other.TokenFamily, families[1].Family)
+	assert.Len(t, families[1].Tokens, 1)
+
+	_, err = svc.RevokeAllUserTokens(ctx, 1)
+	require.NoError(t, err)
+
+	families, err = svc.ListUserTokens(ctx, 1)
+	require.NoError(t, err)
+	for _, family := range families {
+		assert.False(t, family.Active)
+		for _, token := range family.Tokens {
+			assert.NotNil(t, token.RevokedAt)
+		}
+	}
+
+	families, err = svc.ListUserTokens(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, families)
+	assert.NotNil(t, families, "an empty list encodes as [] rather than null")
+}
+
 func TestGenerateRandomToken(t *testing.T) {
 	token1, err := generateRandomToken()
 	require.NoError(t, err)
@@ -286,6 +409,33 @@ func TestService_GenerateTokenPair_NilRepository(t *testing.T) {
 	assert.Contains(t, err.Error(), "refresh token repository not initialized")
 }
 
+func TestService_GenerateTokenPair_NilRepositoryWithFallback(t *testing.T) {
+	cfg := &config.JWTConfig{
+		Secret:             "test-secret-for-jwt-tokens-min-32-chars",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    7 * 24 * time.Hour,
+		AccessOnlyFallback: true,
+	}
+
+	svc := NewService(cfg)
+	ctx := context.Background()
+
+	pair, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+	require.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.Empty(t, pair.RefreshToken)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, int64(900), pair.ExpiresIn)
+
+	claims, err := svc.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+	assert.Equal(t, "test@example.com", claims.Email)
+
+	_, err = svc.RefreshAccessToken(ctx, "some-token")
+	assert.Error(t, err)
+}
+
 func TestService_RefreshAccessToken_NilRepository(t *testing.T) {
 	cfg := &config.JWTConfig{
 		Secret:          "test-secret-for-jwt-tokens-min-32-chars",
@@ -326,7 +476,7 @@ func TestService_RevokeAllUserTokens_NilRepository(t *testing.T) {
 	svc := NewService(cfg)
 	ctx := context.Background()
 
-	err := svc.RevokeAllUserTokens(ctx, 1)
+	_, err := svc.RevokeAllUserTokens(ctx, 1)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "refresh token repository not initialized")
 }
@@ -464,6 +614,7 @@ func TestService_GenerateTokenPair_DatabaseError(t *testing.T) {
 		refreshTokenTTL:  7 * 24 * time.Hour,
 		refreshTokenRepo: NewRefreshTokenRepository(db),
 		db:               db,
+		now:              utcNow,
 	}
 
 	db.Exec("DROP TABLE refresh_tokens")
@@ -487,6 +638,45 @@ func TestService_RefreshAccessToken_MarkAsUsedError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// racingRepository marks a token used on behalf of another request right
+// before the caller does, as a concurrent refresh would
+type racingRepository struct {
+	RefreshTokenRepository
+}
+
+func (r racingRepository) MarkAsUsed(ctx context.Context, id uuid.UUID) error {
+	if err := r.RefreshTokenRepository.MarkAsUsed(ctx, id); err != nil {
+		return err
+	}
+	return r.RefreshTokenRepository.MarkAsUsed(ctx, id)
+}
+
+func TestService_RefreshAccessToken_ConcurrentRefresh(t *testing.T) {
+	svc, _ := setupServiceTest(t)
+	ctx := context.Background()
+
+	pair, err := svc.GenerateTokenPair(ctx, 1, "test@example.com", "Test User")
+	require.NoError(t, err)
+
+	svc.refreshTokenRepo = racingRepository{svc.refreshTokenRepo}
+	_, err = svc.RefreshAccessToken(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrConcurrentRefresh)
+}
+
+func TestNewServiceFromConfig_SuccessorKeyIsDerived(t *testing.T) {
+	cfg := &config.JWTConfig{Secret: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}
+	svc, err := NewServiceFromConfig(cfg, nil)
+	require.NoError(t, err)
+
+	s := svc.(*service)
+	assert.Len(t, s.successorKey, sha256.Size)
+	assert.NotEqual(t, []byte(cfg.Secret), s.successorKey)
+
+	plain := hmac.New(sha256.New, []byte(cfg.Secret))
+	plain.Write([]byte("refresh"))
+	assert.NotEqual(t, base64.URLEncoding.EncodeToString(plain.Sum(nil)), s.successorToken("refresh"))
+}
+
 func TestService_GenerateTokenPair_InvalidSecret(t *testing.T) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
@@ -528,6 +718,7 @@ func TestService_GenerateTokenPair_InvalidSecret(t *testing.T) {
 		refreshTokenTTL:  7 * 24 * time.Hour,
 		refreshTokenRepo: NewRefreshTokenRepository(db),
 		db:               db,
+		now:              utcNow,
 	}
 
 	ctx := context.Background()
@@ -1,12 +1,14 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
 )
@@ -34,20 +36,6 @@ func TestNewService(t *testing.T) {
 				ttl:    48 * time.Hour,
 			},
 		},
-		{
-			name: "with empty secret defaults to default",
-			cfg: &config.JWTConfig{
-				Secret:   "",
-				TTLHours: 12,
-			},
-			expected: struct {
-				secret string
-				ttl    time.Duration
-			}{
-				secret: "default-secret-change-in-production",
-				ttl:    12 * time.Hour,
-			},
-		},
 		{
 			name: "with zero TTL defaults to 24 hours",
 			cfg: &config.JWTConfig{
@@ -82,6 +70,31 @@ func TestNewService(t *testing.T) {
 	}
 }
 
+func TestNewServiceFromConfig(t *testing.T) {
+	t.Run("empty secret is rejected", func(t *testing.T) {
+		svc, err := NewServiceFromConfig(&config.JWTConfig{TTLHours: 12}, nil)
+		assert.ErrorIs(t, err, ErrMissingSecret)
+		assert.Nil(t, svc)
+	})
+
+	t.Run("without db has no refresh token store", func(t *testing.T) {
+		svc, err := NewServiceFromConfig(&config.JWTConfig{Secret: "test-secret"}, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, svc.(*service).refreshTokenRepo)
+	})
+
+	t.Run("with db has refresh token store", func(t *testing.T) {
+		svc, err := NewServiceFromConfig(&config.JWTConfig{Secret: "test-secret"}, setupTestDB(t))
+		assert.NoError(t, err)
+		assert.NotNil(t, svc.(*service).refreshTokenRepo)
+	})
+
+	t.Run("legacy constructors panic instead of falling back to a default secret", func(t *testing.T) {
+		assert.PanicsWithValue(t, ErrMissingSecret, func() { NewService(&config.JWTConfig{}) })
+		assert.PanicsWithValue(t, ErrMissingSecret, func() { NewServiceWithRepo(&config.JWTConfig{}, setupTestDB(t)) })
+	})
+}
+
 func TestNewServiceWithRepo(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -101,17 +114,6 @@ func TestNewServiceWithRepo(t *testing.T) {
 			expectedAccessTTL:  30 * time.Minute,
 			expectedRefreshTTL: 14 * 24 * time.Hour,
 		},
-		{
-			name: "with empty secret defaults to default",
-			cfg: &config.JWTConfig{
-				Secret:          "",
-				AccessTokenTTL:  15 * time.Minute,
-				RefreshTokenTTL: 7 * 24 * time.Hour,
-			},
-			expectedSecret:     "default-secret-change-in-production",
-			expectedAccessTTL:  15 * time.Minute,
-			expectedRefreshTTL: 7 * 24 * time.Hour,
-		},
 		{
 			name: "with zero AccessTokenTTL defaults to 15 minutes",
 			cfg: &config.JWTConfig{
@@ -444,6 +446,100 @@ func TestService_ValidateToken(t *testing.T) {
 	})
 }
 
+func TestService_ValidateToken_Leeway(t *testing.T) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":   "123",
+		"email": "test@example.com",
+		"name":  "Test User",
+		"exp":   now.Add(-3 * time.Second).Unix(),
+		"iat":   now.Add(-15 * time.Minute).Unix(),
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	t.Run("within leeway", func(t *testing.T) {
+		service := NewService(&config.JWTConfig{Secret: "test-secret", Leeway: 30})
+
+		validatedClaims, err := service.ValidateToken(tokenString)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(123), validatedClaims.UserID)
+	})
+
+	t.Run("without leeway", func(t *testing.T) {
+		service := NewService(&config.JWTConfig{Secret: "test-secret"})
+
+		_, err := service.ValidateToken(tokenString)
+
+		assert.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("issued in the future", func(t *testing.T) {
+		claims := jwt.MapClaims{"sub": "123", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(5 * time.Second).Unix()}
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		_, err = NewService(&config.JWTConfig{Secret: "test-secret", Leeway: 30}).ValidateToken(tokenString)
+		assert.NoError(t, err)
+
+		_, err = NewService(&config.JWTConfig{Secret: "test-secret"}).ValidateToken(tokenString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestService_NotBeforeOffset(t *testing.T) {
+	now := time.Now().UTC()
+	svc := NewService(&config.JWTConfig{Secret: "test-secret", AccessTokenTTL: 15 * time.Minute, NotBeforeOffset: time.Minute}).(*service)
+	svc.now = func() time.Time { return now }
+
+	tokenString, err := svc.GenerateToken(123, "test@example.com", "Test User")
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(tokenString, claims)
+	require.NoError(t, err)
+	assert.Equal(t, float64(now.Add(time.Minute).Unix()), claims["nbf"])
+	assert.Equal(t, float64(now.Add(16*time.Minute).Unix()), claims["exp"], "the TTL counts from activation")
+
+	t.Run("rejected before nbf", func(t *testing.T) {
+		svc.now = func() time.Time { return now.Add(30 * time.Second) }
+
+		_, err := svc.ValidateToken(tokenString)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("accepted after nbf", func(t *testing.T) {
+		svc.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+		validatedClaims, err := svc.ValidateToken(tokenString)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(123), validatedClaims.UserID)
+	})
+
+	t.Run("expires_in counts to exp", func(t *testing.T) {
+		svc := NewService(&config.JWTConfig{Secret: "test-secret", AccessTokenTTL: 15 * time.Minute, NotBeforeOffset: time.Minute, AccessOnlyFallback: true}).(*service)
+		svc.now = func() time.Time { return now }
+
+		pair, err := svc.GenerateTokenPair(context.Background(), 123, "test@example.com", "Test User")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(16*60), pair.ExpiresIn)
+	})
+
+	t.Run("zero offset omits nbf", func(t *testing.T) {
+		tokenString, err := NewService(&config.JWTConfig{Secret: "test-secret"}).GenerateToken(123, "test@example.com", "Test User")
+		require.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(tokenString, claims)
+		require.NoError(t, err)
+		assert.NotContains(t, claims, "nbf")
+	})
+}
+
 func TestService_GenerateToken_RoleFetchError(t *testing.T) {
 	db := setupTestDB(t)
 	cfg := &config.JWTConfig{
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+const testURLSecret = "test-secret-for-jwt-tokens-min-32-chars"
+
+func setupSignedURLRouter(signer *URLSigner) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(apiErrors.ErrorHandler())
+	r.GET("/downloads/export.csv", SignedURLMiddleware(signer), func(c *gin.Context) {
+		c.String(http.StatusOK, "id,email")
+	})
+	return r
+}
+
+func serveSigned(r *gin.Engine, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignedURLMiddleware_ValidURL(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, time.Hour)
+	r := setupSignedURLRouter(signer)
+
+	signed, err := signer.Sign("/downloads/export.csv?format=csv", 5*time.Minute)
+	require.NoError(t, err)
+
+	w := serveSigned(r, signed)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id,email", w.Body.String())
+}
+
+func TestSignedURLMiddleware_ExpiredURL(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, time.Hour)
+	r := setupSignedURLRouter(signer)
+
+	signed, err := signer.Sign("/downloads/export.csv", -time.Minute)
+	require.NoError(t, err)
+
+	w := serveSigned(r, signed)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "expired")
+}
+
+func TestSignedURLMiddleware_TamperedURL(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, time.Hour)
+	r := setupSignedURLRouter(signer)
+
+	signed, err := signer.Sign("/downloads/export.csv?format=csv", 5*time.Minute)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		mutate func(q url.Values)
+	}{
+		{
+			name: "tampered signature",
+			mutate: func(q url.Values) {
+				sig := []byte(q.Get(SignedURLSignatureParam))
+				if sig[0] == 'a' {
+					sig[0] = 'b'
+				} else {
+					sig[0] = 'a'
+				}
+				q.Set(SignedURLSignatureParam, string(sig))
+			},
+		},
+		{
+			name: "extended expiry",
+			mutate: func(q url.Values) {
+				q.Set(SignedURLExpiresParam, strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10))
+			},
+		},
+		{
+			name: "changed query parameter",
+			mutate: func(q url.Values) {
+				q.Set("format", "json")
+			},
+		},
+		{
+			name: "missing signature",
+			mutate: func(q url.Values) {
+				q.Del(SignedURLSignatureParam)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := u.Query()
+			tt.mutate(q)
+			tampered := *u
+			tampered.RawQuery = q.Encode()
+
+			w := serveSigned(r, tampered.String())
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestURLSigner_DifferentSecretRejected(t *testing.T) {
+	signed, err := NewURLSigner(testURLSecret, time.Hour).Sign("/downloads/export.csv", 5*time.Minute)
+	require.NoError(t, err)
+
+	r := setupSignedURLRouter(NewURLSigner("another-secret-for-jwt-tokens-32chars", time.Hour))
+	w := serveSigned(r, signed)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSignedURLMiddleware_ClockSkew(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, time.Hour)
+	r := setupSignedURLRouter(signer)
+
+	signed, err := signer.Sign("/downloads/export.csv", -10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveSigned(r, signed).Code, "URLs just past expiry are within the skew tolerance")
+
+	signed, err = signer.Sign("/downloads/export.csv", -SignedURLClockSkew-5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, serveSigned(r, signed).Code)
+
+	// A signer whose clock runs ahead issues expiries past the verifier's maximum
+	ahead := setupSignedURLRouter(NewURLSigner(testURLSecret, time.Hour-10*time.Second))
+	signed, err = signer.Sign("/downloads/export.csv", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveSigned(ahead, signed).Code, "expiries just beyond the maximum are within the skew tolerance")
+
+	far := setupSignedURLRouter(NewURLSigner(testURLSecret, time.Minute))
+	assert.Equal(t, http.StatusUnauthorized, serveSigned(far, signed).Code, "an expiry beyond the maximum and the skew was not issued by this signer")
+}
+
+func TestURLSigner_MaxTTL(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, 5*time.Minute)
+
+	_, err := signer.Sign("/downloads/export.csv", 5*time.Minute)
+	require.NoError(t, err)
+
+	_, err = signer.Sign("/downloads/export.csv", 6*time.Minute)
+	assert.Error(t, err)
+
+	_, err = signer.SignForUser("/downloads/export.csv", 7, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestSignedURLMiddleware_UserScope(t *testing.T) {
+	signer := NewURLSigner(testURLSecret, time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(apiErrors.ErrorHandler())
+	files := r.Group("/files", SignedURLMiddleware(signer))
+	files.GET("/:kind/:id", func(c *gin.Context) {
+		value, _ := c.Get(KeyUser)
+		claims, ok := value.(*Claims)
+		require.True(t, ok, "the middleware sets claims for the signed user")
+		c.String(http.StatusOK, "%s %s for user %d", c.Param("kind"), c.Param("id"), claims.UserID)
+	})
+
+	signed, err := signer.SignForUser("/files/avatars/5", 7, 5*time.Minute)
+	require.NoError(t, err)
+
+	w := serveSigned(r, signed)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "avatars 5 for user 7", w.Body.String())
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	t.Run("other resource", func(t *testing.T) {
+		other := *u
+		other.Path = "/files/exports/9"
+		assert.Equal(t, http.StatusUnauthorized, serveSigned(r, other.String()).Code)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		q := u.Query()
+		q.Set(SignedURLUserParam, "8")
+		tampered := *u
+		tampered.RawQuery = q.Encode()
+		assert.Equal(t, http.StatusUnauthorized, serveSigned(r, tampered.String()).Code)
+	})
+
+	t.Run("user removed", func(t *testing.T) {
+		q := u.Query()
+		q.Del(SignedURLUserParam)
+		tampered := *u
+		tampered.RawQuery = q.Encode()
+		assert.Equal(t, http.StatusUnauthorized, serveSigned(r, tampered.String()).Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := signer.SignForUser("/files/avatars/5", 7, -time.Hour)
+		require.NoError(t, err)
+		w := serveSigned(r, expired)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "expired")
+	})
+
+	t.Run("signed without user scope", func(t *testing.T) {
+		unscoped, err := signer.Sign("/files/avatars/5", time.Minute)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r2 := gin.New()
+		r2.GET("/files/avatars/5", SignedURLMiddleware(signer), func(c *gin.Context) {
+			_, exists := c.Get(KeyUser)
+			assert.False(t, exists)
+			c.Status(http.StatusOK)
+		})
+		r2.ServeHTTP(w, httptest.NewRequest(http.MethodGet, unscoped, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
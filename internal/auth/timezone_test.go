@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timeZones are the host zones the refresh token suite must behave identically in
+var timeZones = []string{"UTC", "Pacific/Auckland"}
+
+// setLocalZone runs the rest of the test as if the host were in zone. TZ is only
+// read once per process, so time.Local is swapped as well.
+func setLocalZone(t *testing.T, zone string) {
+	t.Helper()
+
+	loc, err := time.LoadLocation(zone)
+	require.NoError(t, err)
+
+	t.Setenv("TZ", zone)
+	previous := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = previous })
+}
+
+func TestRefreshTokens_HostTimeZone(t *testing.T) {
+	suite := []struct {
+		name string
+		test func(*testing.T)
+	}{
+		{"refresh", TestService_RefreshAccessToken_Success},
+		{"reuse detection", TestService_RefreshAccessToken_ReuseDetection},
+		{"reuse grace", TestService_RefreshAccessToken_ReuseGrace},
+		{"expired", TestService_RefreshAccessToken_ExpiredToken},
+		{"revoked", TestService_RefreshAccessToken_RevokedToken},
+		{"revoke all", TestService_RevokeAllUserTokens},
+		{"mark as used", TestRefreshTokenRepository_MarkAsUsed},
+		{"delete expired", TestRefreshTokenRepository_DeleteExpired},
+		{"timestamps", testRefreshTokenTimestamps},
+	}
+
+	for _, zone := range timeZones {
+		t.Run(zone, func(t *testing.T) {
+			setLocalZone(t, zone)
+
+			for _, tt := range suite {
+				t.Run(tt.name, tt.test)
+			}
+		})
+	}
+}
+
+// testRefreshTokenTimestamps covers tokens written with local times, as callers
+// outside the auth package may do
+func testRefreshTokenTimestamps(t *testing.T) {
+	svc, db := setupServiceTest(t)
+	repo := NewRefreshTokenRepository(db)
+	ctx := context.Background()
+
+	local := time.Now()
+	expired := &RefreshToken{UserID: 1, TokenHash: HashToken("expired"), TokenFamily: uuid.New(), ExpiresAt: local.Add(-time.Minute)}
+	valid := &RefreshToken{UserID: 1, TokenHash: HashToken("valid"), TokenFamily: uuid.New(), ExpiresAt: local.Add(time.Minute)}
+	require.NoError(t, repo.Create(ctx, expired))
+	require.NoError(t, repo.Create(ctx, valid))
+
+	t.Run("read back in UTC", func(t *testing.T) {
+		stored, err := repo.FindByTokenHash(ctx, HashToken("valid"))
+		require.NoError(t, err)
+		assert.Equal(t, time.UTC, stored.ExpiresAt.Location())
+		assert.Equal(t, time.UTC, stored.CreatedAt.Location())
+		assert.WithinDuration(t, local.Add(time.Minute), stored.ExpiresAt, time.Millisecond)
+	})
+
+	t.Run("expiry compares instants", func(t *testing.T) {
+		_, err := svc.RefreshAccessToken(ctx, "expired")
+		assert.ErrorIs(t, err, ErrExpiredToken)
+
+		_, err = svc.RefreshAccessToken(ctx, "valid")
+		assert.NoError(t, err)
+	})
+
+	t.Run("cleanup deletes only expired tokens", func(t *testing.T) {
+		require.NoError(t, repo.DeleteExpired(ctx))
+
+		var remaining []RefreshToken
+		require.NoError(t, db.Find(&remaining).Error)
+		for _, token := range remaining {
+			assert.NotEqual(t, HashToken("expired"), token.TokenHash)
+			assert.Equal(t, time.UTC, token.ExpiresAt.Location())
+		}
+		assert.Len(t, remaining, 2, "the valid token and its successor remain")
+	})
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+)
+
+// ErrUserInactive is returned when a token belongs to a user that was deleted or deactivated
+var ErrUserInactive = errors.New("user is deleted or deactivated")
+
+// maxUserStatusEntries bounds the user status cache; once full, expired entries
+// are dropped and, failing that, the cache starts over
+const maxUserStatusEntries = 10000
+
+// UserStatusRepository looks up whether users may still use their tokens
+type UserStatusRepository interface {
+	// IsActive reports whether the user exists, is not deleted and is not deactivated
+	IsActive(ctx context.Context, userID uint) (bool, error)
+}
+
+type userStatusRepository struct {
+	db *gorm.DB
+}
+
+// NewUserStatusRepository creates a user status repository reading the users table
+func NewUserStatusRepository(db *gorm.DB) UserStatusRepository {
+	return &userStatusRepository{db: db}
+}
+
+func (r *userStatusRepository) IsActive(ctx context.Context, userID uint) (bool, error) {
+	var ids []uint
+	err := db.Conn(ctx, r.db).WithContext(ctx).Table(db.Table(r.db, "users")).
+		Select("id").
+		Where("id = ? AND deleted_at IS NULL AND active = ?", userID, true).
+		Limit(1).
+		Find(&ids).Error
+	if err != nil {
+		return false, err
+	}
+	return len(ids) > 0, nil
+}
+
+type userStatusEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// userStatusCache remembers user status lookups for ttl; a zero ttl caches nothing
+type userStatusCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[uint]userStatusEntry
+}
+
+func newUserStatusCache(ttl time.Duration, now func() time.Time) *userStatusCache {
+	return &userStatusCache{ttl: ttl, now: now, entries: make(map[uint]userStatusEntry)}
+}
+
+func (c *userStatusCache) get(userID uint) (active, ok bool) {
+	if c == nil || c.ttl <= 0 {
+		return false, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return false, false
+	}
+	return entry.active, true
+}
+
+func (c *userStatusCache) set(userID uint, active bool) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[userID]; !ok && len(c.entries) >= maxUserStatusEntries {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxUserStatusEntries {
+			c.entries = make(map[uint]userStatusEntry)
+		}
+	}
+	c.entries[userID] = userStatusEntry{active: active, expiresAt: now.Add(c.ttl)}
+}
+
+// CheckUserStatus returns ErrUserInactive when the user was deleted or
+// deactivated. Lookups are cached for the configured TTL; without a database
+// every user counts as active.
+func (s *service) CheckUserStatus(ctx context.Context, userID uint) error {
+	if s.userStatusRepo == nil {
+		return nil
+	}
+
+	active, ok := s.userStatus.get(userID)
+	if !ok {
+		var err error
+		active, err = s.userStatusRepo.IsActive(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to look up user status: %w", err)
+		}
+		s.userStatus.set(userID, active)
+	}
+
+	if !active {
+		return ErrUserInactive
+	}
+	return nil
+}
+
+// CacheUserStatus records a user's new status so this instance applies it
+// without waiting for the cached lookup to expire
+func (s *service) CacheUserStatus(userID uint, active bool) {
+	s.userStatus.set(userID, active)
+}
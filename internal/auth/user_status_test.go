@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// countingUserStatusRepository answers from a map and counts the lookups
+type countingUserStatusRepository struct {
+	active  map[uint]bool
+	err     error
+	lookups atomic.Int32
+}
+
+func (r *countingUserStatusRepository) IsActive(_ context.Context, userID uint) (bool, error) {
+	r.lookups.Add(1)
+	return r.active[userID], r.err
+}
+
+func newUserStatusService(repo UserStatusRepository, ttl time.Duration, now func() time.Time) *service {
+	return &service{userStatusRepo: repo, userStatus: newUserStatusCache(ttl, now), now: now}
+}
+
+func TestUserStatusRepository_IsActive(t *testing.T) {
+	_, db := setupServiceTest(t)
+	ctx := context.Background()
+	require.NoError(t, db.Create(&testUser{ID: 2, Name: "Deleted", Email: "deleted@example.com", PasswordHash: "hash"}).Error)
+	require.NoError(t, db.Delete(&testUser{}, 2).Error)
+	require.NoError(t, db.Create(&testUser{ID: 3, Name: "Deactivated", Email: "deactivated@example.com", PasswordHash: "hash"}).Error)
+	require.NoError(t, db.Model(&testUser{}).Where("id = ?", 3).Update("active", false).Error)
+
+	repo := NewUserStatusRepository(db)
+	for _, tt := range []struct {
+		name   string
+		userID uint
+		want   bool
+	}{
+		{name: "active user", userID: 1, want: true},
+		{name: "deleted user", userID: 2, want: false},
+		{name: "deactivated user", userID: 3, want: false},
+		{name: "user never existed", userID: 99, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := repo.IsActive(ctx, tt.userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, active)
+		})
+	}
+}
+
+func TestService_CheckUserStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("warm cache skips the lookup", func(t *testing.T) {
+		repo := &countingUserStatusRepository{active: map[uint]bool{1: true}}
+		svc := newUserStatusService(repo, time.Minute, utcNow)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, svc.CheckUserStatus(ctx, 1))
+		}
+		assert.Equal(t, int32(1), repo.lookups.Load())
+	})
+
+	t.Run("entries expire after the TTL", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		repo := &countingUserStatusRepository{active: map[uint]bool{1: true}}
+		svc := newUserStatusService(repo, time.Minute, func() time.Time { return now })
+
+		require.NoError(t, svc.CheckUserStatus(ctx, 1))
+		repo.active[1] = false
+		require.NoError(t, svc.CheckUserStatus(ctx, 1), "the cached status holds within the TTL")
+
+		now = now.Add(time.Minute)
+		assert.ErrorIs(t, svc.CheckUserStatus(ctx, 1), ErrUserInactive)
+		assert.Equal(t, int32(2), repo.lookups.Load())
+	})
+
+	t.Run("zero TTL looks up every request", func(t *testing.T) {
+		repo := &countingUserStatusRepository{active: map[uint]bool{1: true}}
+		svc := newUserStatusService(repo, 0, utcNow)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, svc.CheckUserStatus(ctx, 1))
+		}
+		assert.Equal(t, int32(3), repo.lookups.Load())
+	})
+
+	t.Run("primed status applies immediately", func(t *testing.T) {
+		repo := &countingUserStatusRepository{active: map[uint]bool{1: true}}
+		svc := newUserStatusService(repo, time.Minute, utcNow)
+		require.NoError(t, svc.CheckUserStatus(ctx, 1))
+
+		svc.CacheUserStatus(1, false)
+
+		assert.ErrorIs(t, svc.CheckUserStatus(ctx, 1), ErrUserInactive)
+		assert.Equal(t, int32(1), repo.lookups.Load())
+	})
+
+	t.Run("lookup errors are not cached", func(t *testing.T) {
+		repo := &countingUserStatusRepository{err: errors.New("db down")}
+		svc := newUserStatusService(repo, time.Minute, utcNow)
+
+		err := svc.CheckUserStatus(ctx, 1)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrUserInactive)
+
+		repo.err = nil
+		repo.active = map[uint]bool{1: true}
+		assert.NoError(t, svc.CheckUserStatus(ctx, 1))
+	})
+
+	t.Run("without a database every user is active", func(t *testing.T) {
+		svc := NewService(&config.JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"})
+
+		assert.NoError(t, svc.CheckUserStatus(ctx, 1))
+	})
+}
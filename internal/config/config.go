@@ -12,19 +12,56 @@ import (
 )
 
 type Config struct {
-	App        AppConfig        `mapstructure:"app" yaml:"app"`
-	Database   DatabaseConfig   `mapstructure:"database" yaml:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt" yaml:"jwt"`
-	Server     ServerConfig     `mapstructure:"server" yaml:"server"`
-	Logging    LoggingConfig    `mapstructure:"logging" yaml:"logging"`
-	Ratelimit  RateLimitConfig  `mapstructure:"ratelimit" yaml:"ratelimit"`
-	Migrations MigrationsConfig `mapstructure:"migrations" yaml:"migrations"`
-	Health     HealthConfig     `mapstructure:"health" yaml:"health"`
+	App         AppConfig         `mapstructure:"app" yaml:"app"`
+	Database    DatabaseConfig    `mapstructure:"database" yaml:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt" yaml:"jwt"`
+	Password    PasswordConfig    `mapstructure:"password" yaml:"password"`
+	Server      ServerConfig      `mapstructure:"server" yaml:"server"`
+	Logging     LoggingConfig     `mapstructure:"logging" yaml:"logging"`
+	Ratelimit   RateLimitConfig   `mapstructure:"ratelimit" yaml:"ratelimit"`
+	Migrations  MigrationsConfig  `mapstructure:"migrations" yaml:"migrations"`
+	Health      HealthConfig      `mapstructure:"health" yaml:"health"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance" yaml:"maintenance"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler" yaml:"scheduler"`
+	Worker      WorkerConfig      `mapstructure:"worker" yaml:"worker"`
+	Outbox      OutboxConfig      `mapstructure:"outbox" yaml:"outbox"`
+	Email       EmailConfig       `mapstructure:"email" yaml:"email"`
+	OAuth       OAuthConfig       `mapstructure:"oauth" yaml:"oauth"`
+	CORS        CORSConfig        `mapstructure:"cors" yaml:"cors"`
+	Metrics     MetricsConfig     `mapstructure:"metrics" yaml:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing" yaml:"tracing"`
+	GraphQL     GraphQLConfig     `mapstructure:"graphql" yaml:"graphql"`
+	GRPC        GRPCConfig        `mapstructure:"grpc" yaml:"grpc"`
+	WebSocket   WebSocketConfig   `mapstructure:"websocket" yaml:"websocket"`
+	Admin       AdminConfig       `mapstructure:"admin" yaml:"admin"`
+	License     LicenseConfig     `mapstructure:"license" yaml:"license"`
+	Tenant      TenantConfig      `mapstructure:"tenant" yaml:"tenant"`
+	Auth        AuthConfig        `mapstructure:"auth" yaml:"auth"`
+	Security    SecurityConfig    `mapstructure:"security" yaml:"security"`
+	// Features overrides feature flags by name; flags left unset follow the
+	// enabled field of their feature (graphql, oauth.google, websocket)
+	Features map[string]bool `mapstructure:"features" yaml:"features"`
+	Settings SettingsConfig  `mapstructure:"config" yaml:"config"`
+
+	// UnknownKeys are config keys, from files or bound variables, that match
+	// no setting and were ignored
+	UnknownKeys []string `mapstructure:"-" yaml:"-"`
+	// UnknownEnv are variables named like overrides of a section, e.g.
+	// JWT_TTL_HOURS, that match no setting and were ignored
+	UnknownEnv []string `mapstructure:"-" yaml:"-"`
+}
+
+// SettingsConfig controls how the configuration itself is loaded
+type SettingsConfig struct {
+	// Strict makes unknown config keys and environment variables a startup
+	// error instead of a warning
+	Strict bool `mapstructure:"strict" yaml:"strict"`
 }
 
 type AppConfig struct {
-	Name        string `mapstructure:"name" yaml:"name"`
-	Version     string `mapstructure:"version" yaml:"version"`
+	Name string `mapstructure:"name" yaml:"name"`
+	// Version is the build version from internal/version, set by the server at startup
+	Version     string `mapstructure:"-" yaml:"-"`
 	Environment string `mapstructure:"environment" yaml:"environment"`
 	Debug       bool   `mapstructure:"debug" yaml:"debug"`
 }
@@ -36,6 +73,11 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password" yaml:"password"`
 	Name     string `mapstructure:"name" yaml:"name"`
 	SSLMode  string `mapstructure:"sslmode" yaml:"sslmode"`
+	// TablePrefix prefixes every table name, for deployments sharing one database
+	TablePrefix string `mapstructure:"table_prefix" yaml:"table_prefix"`
+	// RequestTransactions runs each write request to the auth and admin endpoints
+	// in one transaction, rolled back when the request fails
+	RequestTransactions bool `mapstructure:"request_transactions" yaml:"request_transactions"`
 }
 
 type JWTConfig struct {
@@ -43,41 +85,411 @@ type JWTConfig struct {
 	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl" yaml:"access_token_ttl"`
 	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl" yaml:"refresh_token_ttl"`
 	TTLHours        int           `mapstructure:"ttlhours" yaml:"ttlhours"` // Deprecated: kept for backward compatibility
+	// AccessOnlyFallback issues access-token-only pairs when no refresh token store is configured
+	AccessOnlyFallback bool `mapstructure:"access_only_fallback" yaml:"access_only_fallback"`
+	// LegacyAuthResponse makes register/login return the deprecated {token, user} shape
+	LegacyAuthResponse bool `mapstructure:"legacy_auth_response" yaml:"legacy_auth_response"`
+	// RefreshReuseGrace is how long after rotation the previous refresh token may be retried
+	// and receive the same successor instead of revoking the family; zero disables it
+	RefreshReuseGrace time.Duration `mapstructure:"refresh_reuse_grace" yaml:"refresh_reuse_grace"`
+	// RefreshUpdatesLastLogin counts token refreshes as sign-ins when tracking users' last_login_at
+	RefreshUpdatesLastLogin bool `mapstructure:"refresh_updates_last_login" yaml:"refresh_updates_last_login"`
+	// UserStatusCacheTTL is how long protected routes trust a cached lookup of whether the
+	// token's user still exists and is active; zero looks the user up on every request
+	UserStatusCacheTTL time.Duration `mapstructure:"user_status_cache_ttl" yaml:"user_status_cache_ttl"`
+	// Leeway is how many seconds of clock skew the exp, iat and nbf checks of
+	// access tokens tolerate
+	Leeway int `mapstructure:"leeway" yaml:"leeway"`
+	// NotBeforeOffset delays when new access tokens become valid through their nbf
+	// claim; zero omits the claim
+	NotBeforeOffset time.Duration `mapstructure:"not_before_offset" yaml:"not_before_offset"`
+}
+
+// PasswordConfig sets the rules new user passwords must satisfy; admin accounts
+// created with cmd/createadmin always require every character class
+type PasswordConfig struct {
+	// MinLength is the minimum password length; zero uses the default of 8
+	MinLength      int  `mapstructure:"min_length" yaml:"min_length"`
+	RequireUpper   bool `mapstructure:"require_upper" yaml:"require_upper"`
+	RequireLower   bool `mapstructure:"require_lower" yaml:"require_lower"`
+	RequireDigit   bool `mapstructure:"require_digit" yaml:"require_digit"`
+	RequireSpecial bool `mapstructure:"require_special" yaml:"require_special"`
+	// BreachCheck rejects passwords found by the Pwned Passwords range API; lookups
+	// that fail accept the password
+	BreachCheck        bool          `mapstructure:"breach_check" yaml:"breach_check"`
+	BreachCheckURL     string        `mapstructure:"breach_check_url" yaml:"breach_check_url"`
+	BreachCheckTimeout time.Duration `mapstructure:"breach_check_timeout" yaml:"breach_check_timeout"`
 }
 
 type ServerConfig struct {
-	Port            string `mapstructure:"port" yaml:"port"`
-	ReadTimeout     int    `mapstructure:"readtimeout" yaml:"readtimeout"`
-	WriteTimeout    int    `mapstructure:"writetimeout" yaml:"writetimeout"`
-	IdleTimeout     int    `mapstructure:"idletimeout" yaml:"idletimeout"`
-	ShutdownTimeout int    `mapstructure:"shutdowntimeout" yaml:"shutdowntimeout"`
-	MaxHeaderBytes  int    `mapstructure:"maxheaderbytes" yaml:"maxheaderbytes"`
+	Port        string `mapstructure:"port" yaml:"port"`
+	ReadTimeout int    `mapstructure:"readtimeout" yaml:"readtimeout"`
+	// WriteTimeout bounds writing a whole response, in seconds. The SSE streams
+	// (/api/v1/events, /api/v1/admin/events/stream) lift it for their connections.
+	WriteTimeout    int `mapstructure:"writetimeout" yaml:"writetimeout"`
+	IdleTimeout     int `mapstructure:"idletimeout" yaml:"idletimeout"`
+	ShutdownTimeout int `mapstructure:"shutdowntimeout" yaml:"shutdowntimeout"`
+	MaxHeaderBytes  int `mapstructure:"maxheaderbytes" yaml:"maxheaderbytes"`
+	// ResponseFormat selects the default success body: "standard" or "envelope"
+	ResponseFormat string `mapstructure:"response_format" yaml:"response_format"`
+	// SwaggerEnabled serves the Swagger UI at /swagger
+	SwaggerEnabled bool `mapstructure:"swagger_enabled" yaml:"swagger_enabled"`
+	// BehindProxy trusts X-Forwarded-For from TrustedProxies; otherwise forwarded headers are ignored
+	BehindProxy    bool     `mapstructure:"behind_proxy" yaml:"behind_proxy"`
+	TrustedProxies []string `mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
+	// StrictJSON rejects request bodies with fields unknown to the target struct
+	StrictJSON bool `mapstructure:"strict_json" yaml:"strict_json"`
+	// MaxJSONBytes and MaxJSONDepth bound request bodies; zero uses the defaults (1MB, depth 32)
+	MaxJSONBytes int64 `mapstructure:"max_json_bytes" yaml:"max_json_bytes"`
+	MaxJSONDepth int   `mapstructure:"max_json_depth" yaml:"max_json_depth"`
+	// VersionAdminOnly restricts GET /version to authenticated admins
+	VersionAdminOnly bool `mapstructure:"version_admin_only" yaml:"version_admin_only"`
+	// ServerHeader replaces the Server response header; empty removes it
+	ServerHeader string `mapstructure:"server_header" yaml:"server_header"`
+	// ResponseTimeHeader adds X-Response-Time with the request duration
+	ResponseTimeHeader bool `mapstructure:"response_time_header" yaml:"response_time_header"`
+	// StaticCacheMaxAge is how long clients may cache Swagger UI and other static
+	// assets; zero makes them revalidate. API responses are never cached.
+	StaticCacheMaxAge time.Duration `mapstructure:"static_cache_max_age" yaml:"static_cache_max_age"`
+	// StrictHTTPSemantics answers actions that leave nothing to report, such as
+	// logout, with 204 No Content instead of 200 and a message
+	StrictHTTPSemantics bool `mapstructure:"strict_http_semantics" yaml:"strict_http_semantics"`
+	// MaxInflight caps the requests served at once; more are shed with 503.
+	// Health checks and metrics are exempt. Zero disables the cap.
+	MaxInflight int `mapstructure:"max_inflight" yaml:"max_inflight"`
+	// MaxInflightPerUser caps the requests each authenticated user has in
+	// flight on /users and /admin; more get 429. Zero disables the cap.
+	MaxInflightPerUser int `mapstructure:"max_inflight_per_user" yaml:"max_inflight_per_user"`
 }
 
 type LoggingConfig struct {
 	Level string `mapstructure:"level" yaml:"level"`
+	// Format is json (default) or console, a human readable key=value format
+	Format string `mapstructure:"format" yaml:"format"`
+	// File appends logs to the file instead of writing them to stdout
+	File string `mapstructure:"file" yaml:"file"`
 }
 
 type RateLimitConfig struct {
 	Enabled  bool          `mapstructure:"enabled" yaml:"enabled"`
 	Requests int           `mapstructure:"requests" yaml:"requests"`
 	Window   time.Duration `mapstructure:"window" yaml:"window"`
+	// UserRequests and UserWindow limit each authenticated user on /users routes; zero falls back to Requests/Window
+	UserRequests int           `mapstructure:"user_requests" yaml:"user_requests"`
+	UserWindow   time.Duration `mapstructure:"user_window" yaml:"user_window"`
+	// EmailCheckRequests and EmailCheckWindow limit each IP on the unauthenticated
+	// /auth/email-available lookup; zero uses DefaultEmailCheckRequests/DefaultEmailCheckWindow
+	EmailCheckRequests int           `mapstructure:"email_check_requests" yaml:"email_check_requests"`
+	EmailCheckWindow   time.Duration `mapstructure:"email_check_window" yaml:"email_check_window"`
+	// OmitLegacyHeaders drops the X-RateLimit-* headers and keeps only the RateLimit-* ones
+	OmitLegacyHeaders bool `mapstructure:"omit_legacy_headers" yaml:"omit_legacy_headers"`
+	// CacheSize caps the per-key limiters each store keeps and EntryTTL drops idle ones; zero uses the defaults (5000, 6h)
+	CacheSize int           `mapstructure:"cache_size" yaml:"cache_size"`
+	EntryTTL  time.Duration `mapstructure:"entry_ttl" yaml:"entry_ttl"`
+	// OverflowPolicy is what a full store does with new keys: "evict" the least recently used
+	// limiter or refuse the key so it "shared"s one overflow bucket; empty means evict
+	OverflowPolicy string `mapstructure:"overflow_policy" yaml:"overflow_policy"`
+	// OverflowRequests is the per-window budget of the shared overflow bucket; zero uses Requests
+	OverflowRequests int `mapstructure:"overflow_requests" yaml:"overflow_requests"`
+	// WarningThreshold adds X-RateLimit-Warning once fewer than this fraction of requests remain; zero disables it
+	WarningThreshold float64 `mapstructure:"warning_threshold" yaml:"warning_threshold"`
+	// ExemptRoles and ExemptIPs (IPs or CIDRs) are never rate limited, e.g. admins or monitoring
+	ExemptRoles []string `mapstructure:"exempt_roles" yaml:"exempt_roles"`
+	ExemptIPs   []string `mapstructure:"exempt_ips" yaml:"exempt_ips"`
+}
+
+// Defaults of the email availability rate limit, tighter than the general one
+// because the endpoint can be used to find out who has an account
+const (
+	DefaultEmailCheckRequests = 10
+	DefaultEmailCheckWindow   = time.Minute
+)
+
+// EmailCheckLimit returns the per-IP request budget of the email availability check
+func (r RateLimitConfig) EmailCheckLimit() int {
+	if r.EmailCheckRequests > 0 {
+		return r.EmailCheckRequests
+	}
+	return DefaultEmailCheckRequests
+}
+
+// EmailCheckPeriod returns the window of the email availability rate limit
+func (r RateLimitConfig) EmailCheckPeriod() time.Duration {
+	if r.EmailCheckWindow > 0 {
+		return r.EmailCheckWindow
+	}
+	return DefaultEmailCheckWindow
+}
+
+// PerUserRequests returns the per-user request budget
+func (r RateLimitConfig) PerUserRequests() int {
+	if r.UserRequests > 0 {
+		return r.UserRequests
+	}
+	return r.Requests
+}
+
+// PerUserWindow returns the per-user rate limit window
+func (r RateLimitConfig) PerUserWindow() time.Duration {
+	if r.UserWindow > 0 {
+		return r.UserWindow
+	}
+	return r.Window
 }
 
 type MigrationsConfig struct {
-	Directory   string `mapstructure:"directory" yaml:"directory"`
-	Timeout     int    `mapstructure:"timeout" yaml:"timeout"`
-	LockTimeout int    `mapstructure:"locktimeout" yaml:"locktimeout"`
+	Directory string `mapstructure:"directory" yaml:"directory"`
+	// UseEmbedded applies the migrations compiled into the binary and ignores Directory
+	UseEmbedded bool `mapstructure:"use_embedded" yaml:"use_embedded"`
+	Timeout     int  `mapstructure:"timeout" yaml:"timeout"`
+	LockTimeout int  `mapstructure:"locktimeout" yaml:"locktimeout"`
 }
 
 type HealthConfig struct {
+	// Timeout bounds each readiness checker, in seconds
 	Timeout              int  `mapstructure:"timeout" yaml:"timeout"`
 	DatabaseCheckEnabled bool `mapstructure:"database_check_enabled" yaml:"database_check_enabled"`
+	// MigrationCheckEnabled fails readiness while the database is behind the migration files
+	MigrationCheckEnabled bool `mapstructure:"migration_check_enabled" yaml:"migration_check_enabled"`
+	// CheckerTimeouts overrides Timeout for individual checkers by name, e.g. database: 2s
+	CheckerTimeouts map[string]time.Duration `mapstructure:"checker_timeouts" yaml:"checker_timeouts"`
+	// StreamInterval is how often GET /api/v1/events sends a health snapshot
+	StreamInterval time.Duration `mapstructure:"stream_interval" yaml:"stream_interval"`
+}
+
+type MaintenanceConfig struct {
+	Enabled    bool `mapstructure:"enabled" yaml:"enabled"`
+	RetryAfter int  `mapstructure:"retry_after" yaml:"retry_after"`
+}
+
+type SchedulerConfig struct {
+	Enabled              bool          `mapstructure:"enabled" yaml:"enabled"`
+	TokenCleanupInterval time.Duration `mapstructure:"token_cleanup_interval" yaml:"token_cleanup_interval"`
+}
+
+// WorkerConfig sizes the pool that runs background work, such as sending email,
+// off the request path; zero values use the worker package defaults
+type WorkerConfig struct {
+	Concurrency int `mapstructure:"concurrency" yaml:"concurrency"`
+	QueueSize   int `mapstructure:"queue_size" yaml:"queue_size"`
+}
+
+// OutboxConfig controls the event outbox. Its relay is a scheduler job, so the
+// outbox requires scheduler.enabled.
+type OutboxConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// RelayInterval is how often pending events are dispatched
+	RelayInterval time.Duration `mapstructure:"relay_interval" yaml:"relay_interval"`
+	// BatchSize is how many events one relay run dispatches at most
+	BatchSize int `mapstructure:"batch_size" yaml:"batch_size"`
+	// MaxAttempts is how many failed dispatches make an event dead, leaving it
+	// in the outbox unsent; zero uses outbox.DefaultMaxAttempts
+	MaxAttempts int `mapstructure:"max_attempts" yaml:"max_attempts"`
+}
+
+type EmailConfig struct {
+	From        string        `mapstructure:"from" yaml:"from"`
+	SendTimeout time.Duration `mapstructure:"send_timeout" yaml:"send_timeout"`
+	// ChangeTokenTTL is how long a requested email change can be confirmed
+	ChangeTokenTTL time.Duration `mapstructure:"change_token_ttl" yaml:"change_token_ttl"`
+	// PublicBaseURL is the address clients reach the API at, used for links in emails
+	PublicBaseURL string `mapstructure:"public_base_url" yaml:"public_base_url"`
+	// UnsubscribeTTL is how long one-click unsubscribe links stay valid
+	UnsubscribeTTL time.Duration `mapstructure:"unsubscribe_ttl" yaml:"unsubscribe_ttl"`
+}
+
+type OAuthConfig struct {
+	Google OAuthProviderConfig `mapstructure:"google" yaml:"google"`
+}
+
+type OAuthProviderConfig struct {
+	Enabled      bool   `mapstructure:"enabled" yaml:"enabled"`
+	ClientID     string `mapstructure:"client_id" yaml:"client_id"`
+	ClientSecret string `mapstructure:"client_secret" yaml:"client_secret"`
+	RedirectURL  string `mapstructure:"redirect_url" yaml:"redirect_url"`
 }
 
-// LoadConfig loads configuration using Viper. If configPath is non-empty it
+// MetricsConfig controls the Prometheus HTTP metrics. Empty buckets keep the
+// library defaults so existing dashboards continue to work.
+type MetricsConfig struct {
+	Namespace       string    `mapstructure:"namespace" yaml:"namespace"`
+	DurationBuckets []float64 `mapstructure:"duration_buckets" yaml:"duration_buckets"`
+	SizeBuckets     []float64 `mapstructure:"size_buckets" yaml:"size_buckets"`
+	// Token, when set, is the bearer token scrapers must send to read /metrics;
+	// empty leaves the endpoint open
+	Token string `mapstructure:"token" yaml:"token"`
+}
+
+// TracingConfig controls OpenTelemetry tracing of requests and database queries
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Endpoint is the OTLP/HTTP traces URL of the collector; http:// sends without TLS
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1; requests
+	// that arrive as part of a trace follow the caller's decision
+	SampleRatio float64 `mapstructure:"sample_ratio" yaml:"sample_ratio"`
+	ServiceName string  `mapstructure:"service_name" yaml:"service_name"`
+}
+
+// GraphQLConfig controls the optional /graphql endpoint
+type GraphQLConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+}
+
+// GRPCConfig controls the optional gRPC server for internal services
+type GRPCConfig struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
+	Port    string `mapstructure:"port" yaml:"port"`
+}
+
+// WebSocketConfig controls the optional /api/v1/ws notifications endpoint.
+// Browser origins are checked against cors.allow_origins.
+type WebSocketConfig struct {
+	Enabled      bool          `mapstructure:"enabled" yaml:"enabled"`
+	PingInterval time.Duration `mapstructure:"ping_interval" yaml:"ping_interval"`
+}
+
+// AdminConfig tunes the admin endpoints
+type AdminConfig struct {
+	// BulkMaxUsers caps the user IDs in one bulk role request; zero uses the default (500)
+	BulkMaxUsers int `mapstructure:"bulk_max_users" yaml:"bulk_max_users"`
+	// EventStreamMaxConnections caps the concurrent /api/v1/admin/events/stream
+	// connections; zero uses the default (10)
+	EventStreamMaxConnections int `mapstructure:"event_stream_max_connections" yaml:"event_stream_max_connections"`
+	// BootstrapEnabled creates an admin from Email, Password and Name at startup
+	// when no admin exists yet, so a fresh deployment needs no cmd/createadmin run
+	BootstrapEnabled bool   `mapstructure:"bootstrap_enabled" yaml:"bootstrap_enabled"`
+	Email            string `mapstructure:"email" yaml:"email"`
+	Password         string `mapstructure:"password" yaml:"password"`
+	Name             string `mapstructure:"name" yaml:"name"`
+}
+
+// LicenseConfig holds the limits of a self-hosted license
+type LicenseConfig struct {
+	// MaxUsers caps the users that are not deleted; zero means unlimited
+	MaxUsers int `mapstructure:"max_users" yaml:"max_users"`
+}
+
+// TenantConfig controls multi-tenancy, where one deployment serves several
+// isolated sets of users
+type TenantConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// BaseDomain lets a subdomain such as acme.example.com select the tenant;
+	// empty means only the X-Tenant-ID header does
+	BaseDomain string `mapstructure:"base_domain" yaml:"base_domain"`
+}
+
+// AuthConfig controls the unauthenticated auth endpoints
+type AuthConfig struct {
+	// EmailEnumerationProtection makes /auth/email-available report every email
+	// as available, leaving taken emails to be rejected by registration
+	EmailEnumerationProtection bool `mapstructure:"email_enumeration_protection" yaml:"email_enumeration_protection"`
+}
+
+// SecurityConfig controls account security behavior
+type SecurityConfig struct {
+	// AutoLoginOnRegister makes registration return a token pair; when false it returns
+	// only the created user with 201. Unset means true.
+	AutoLoginOnRegister bool `mapstructure:"auto_login_on_register" yaml:"auto_login_on_register"`
+	// ResetLinkTemplate is the link in password reset emails, with {token} standing for the
+	// reset token, e.g. myapp://reset?token={token}; empty links to the web form under
+	// email.public_base_url
+	ResetLinkTemplate string `mapstructure:"reset_link_template" yaml:"reset_link_template"`
+	// TokenDelivery is how register, login and refresh hand out tokens: body (the
+	// default), cookie (HttpOnly, SameSite=Strict cookies) or both
+	TokenDelivery string `mapstructure:"token_delivery" yaml:"token_delivery"`
+	// CookieSecure marks the token cookies Secure so browsers only send them over
+	// HTTPS. Unset means true; turn it off only to use cookies over plain HTTP in
+	// development.
+	CookieSecure bool `mapstructure:"cookie_secure" yaml:"cookie_secure"`
+	// PasswordHash selects how passwords are hashed
+	PasswordHash PasswordHashConfig `mapstructure:"password_hash" yaml:"password_hash"`
+}
+
+// PasswordHashConfig selects the algorithm and cost of new password hashes.
+// Hashes made under another configuration still verify and, with
+// RehashOnLogin, are replaced at the user's next login. Zero values use the
+// defaults.
+type PasswordHashConfig struct {
+	// Algorithm is bcrypt (the default) or argon2id
+	Algorithm  string `mapstructure:"algorithm" yaml:"algorithm"`
+	BcryptCost int    `mapstructure:"bcrypt_cost" yaml:"bcrypt_cost"`
+	// Argon2Memory is in KiB
+	Argon2Memory      int `mapstructure:"argon2_memory" yaml:"argon2_memory"`
+	Argon2Iterations  int `mapstructure:"argon2_iterations" yaml:"argon2_iterations"`
+	Argon2Parallelism int `mapstructure:"argon2_parallelism" yaml:"argon2_parallelism"`
+	// RehashOnLogin replaces a hash made under another configuration when its
+	// user logs in
+	RehashOnLogin bool `mapstructure:"rehash_on_login" yaml:"rehash_on_login"`
+	// MaxConcurrency bounds the hash operations of logins and registrations
+	// running at once; zero uses GOMAXPROCS
+	MaxConcurrency int `mapstructure:"max_concurrency" yaml:"max_concurrency"`
+	// MaxWait is how long a login or registration waits for a hashing slot
+	// before it is rejected with 503; zero rejects it at once
+	MaxWait time.Duration `mapstructure:"max_wait" yaml:"max_wait"`
+}
+
+// CORSConfig controls cross-origin access. Empty fields fall back to the
+// permissive defaults (all origins, standard methods, 12h preflight cache).
+type CORSConfig struct {
+	AllowOrigins     []string      `mapstructure:"allow_origins" yaml:"allow_origins"`
+	AllowMethods     []string      `mapstructure:"allow_methods" yaml:"allow_methods"`
+	AllowHeaders     []string      `mapstructure:"allow_headers" yaml:"allow_headers"`
+	ExposeHeaders    []string      `mapstructure:"expose_headers" yaml:"expose_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials" yaml:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age" yaml:"max_age"`
+}
+
+// AllowsAllOrigins reports whether any origin may access the API
+func (c CORSConfig) AllowsAllOrigins() bool {
+	if len(c.AllowOrigins) == 0 {
+		return true
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig loads configuration using Viper and validates it. If configPath is non-empty it
 // will be used as the exact config file path, otherwise Viper searches common locations.
+// ${VAR} references in config files are expanded from the environment.
 func LoadConfig(configPath string) (*Config, error) {
+	cfg, err := ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ReadConfig loads configuration like LoadConfig but without validating it,
+// so callers can report on an invalid configuration.
+//
+// Sources take precedence in this order, lowest first: configs/config.yaml,
+// configs/config.<environment>.yaml, configs/config.local.yaml, then
+// environment variables. Outside production a .env file in the working
+// directory is loaded first; its variables count as environment variables
+// but never replace ones that are already set. When configPath is given only
+// that file and the environment are read.
+func ReadConfig(configPath string) (*Config, error) {
+	// WHY: .env holds a developer's local secrets; a production process must
+	// get its environment from the deployment, never from a stray file
+	var dotEnvLoaded bool
+	if os.Getenv("APP_ENVIRONMENT") != "production" {
+		loaded, err := loadDotEnv(DotEnvFile)
+		if err != nil {
+			return nil, err
+		}
+		dotEnvLoaded = loaded
+	}
+
 	v := viper.New()
 
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -87,7 +499,7 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	if configPath != "" {
 		v.SetConfigFile(configPath)
-		if err := v.ReadInConfig(); err != nil {
+		if err := readExpandedConfig(v, false); err != nil {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 				return nil, fmt.Errorf("failed to read config file: %w", err)
 			}
@@ -104,18 +516,27 @@ func LoadConfig(configPath string) (*Config, error) {
 		v.AddConfigPath(".")
 		v.AddConfigPath("./configs")
 
-		if err := v.ReadInConfig(); err != nil {
+		if err := readExpandedConfig(v, false); err != nil {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 				return nil, fmt.Errorf("failed to read base config file: %w", err)
 			}
 		}
 
 		v.SetConfigName(fmt.Sprintf("config.%s", env))
-		if err := v.MergeInConfig(); err != nil {
+		if err := readExpandedConfig(v, true); err != nil {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 				return nil, fmt.Errorf("failed to merge environment config: %w", err)
 			}
 		}
+
+		// config.local.yaml is untracked and merged last, so a developer's
+		// overrides win over every committed file
+		v.SetConfigName("config.local")
+		if err := readExpandedConfig(v, true); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+				return nil, fmt.Errorf("failed to merge local config: %w", err)
+			}
+		}
 	}
 
 	var cfg Config
@@ -123,6 +544,17 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	schema := newConfigSchema()
+	cfg.UnknownKeys = unknownKeys(v, schema)
+	cfg.UnknownEnv = unknownEnv(v, schema, os.Environ())
+
+	if !v.IsSet("security.auto_login_on_register") {
+		cfg.Security.AutoLoginOnRegister = true
+	}
+	if !v.IsSet("security.cookie_secure") {
+		cfg.Security.CookieSecure = true
+	}
+
 	if cfg.App.Environment == "" {
 		if e := v.GetString("app.environment"); e != "" {
 			cfg.App.Environment = e
@@ -131,45 +563,157 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	if dotEnvLoaded && cfg.App.Environment == "production" {
+		return nil, ErrDotEnvInProduction
 	}
 
 	return &cfg, nil
 }
 
+// envBindings maps config keys to the environment variables that override them
+var envBindings = map[string]string{
+	"app.name":                                  "APP_NAME",
+	"app.environment":                           "APP_ENVIRONMENT",
+	"app.debug":                                 "APP_DEBUG",
+	"database.host":                             "DATABASE_HOST",
+	"database.port":                             "DATABASE_PORT",
+	"database.user":                             "DATABASE_USER",
+	"database.password":                         "DATABASE_PASSWORD",
+	"database.name":                             "DATABASE_NAME",
+	"database.sslmode":                          "DATABASE_SSLMODE",
+	"database.request_transactions":             "DATABASE_REQUEST_TRANSACTIONS",
+	"jwt.secret":                                "JWT_SECRET",
+	"jwt.access_token_ttl":                      "JWT_ACCESS_TOKEN_TTL",
+	"jwt.refresh_token_ttl":                     "JWT_REFRESH_TOKEN_TTL",
+	"jwt.ttlhours":                              "JWT_TTLHOURS",
+	"jwt.access_only_fallback":                  "JWT_ACCESS_ONLY_FALLBACK",
+	"jwt.legacy_auth_response":                  "JWT_LEGACY_AUTH_RESPONSE",
+	"jwt.refresh_reuse_grace":                   "JWT_REFRESH_REUSE_GRACE",
+	"jwt.refresh_updates_last_login":            "JWT_REFRESH_UPDATES_LAST_LOGIN",
+	"jwt.user_status_cache_ttl":                 "JWT_USER_STATUS_CACHE_TTL",
+	"jwt.leeway":                                "JWT_LEEWAY",
+	"jwt.not_before_offset":                     "JWT_NOT_BEFORE_OFFSET",
+	"server.port":                               "SERVER_PORT",
+	"server.readtimeout":                        "SERVER_READTIMEOUT",
+	"server.writetimeout":                       "SERVER_WRITETIMEOUT",
+	"server.idletimeout":                        "SERVER_IDLETIMEOUT",
+	"server.shutdowntimeout":                    "SERVER_SHUTDOWNTIMEOUT",
+	"server.maxheaderbytes":                     "SERVER_MAXHEADERBYTES",
+	"server.response_format":                    "SERVER_RESPONSE_FORMAT",
+	"server.swagger_enabled":                    "SERVER_SWAGGER_ENABLED",
+	"server.behind_proxy":                       "SERVER_BEHIND_PROXY",
+	"server.trusted_proxies":                    "SERVER_TRUSTED_PROXIES",
+	"server.strict_json":                        "SERVER_STRICT_JSON",
+	"server.max_json_bytes":                     "SERVER_MAX_JSON_BYTES",
+	"server.max_json_depth":                     "SERVER_MAX_JSON_DEPTH",
+	"server.version_admin_only":                 "SERVER_VERSION_ADMIN_ONLY",
+	"server.server_header":                      "SERVER_SERVER_HEADER",
+	"server.response_time_header":               "SERVER_RESPONSE_TIME_HEADER",
+	"server.static_cache_max_age":               "SERVER_STATIC_CACHE_MAX_AGE",
+	"server.strict_http_semantics":              "SERVER_STRICT_HTTP_SEMANTICS",
+	"server.max_inflight":                       "SERVER_MAX_INFLIGHT",
+	"server.max_inflight_per_user":              "SERVER_MAX_INFLIGHT_PER_USER",
+	"password.min_length":                       "PASSWORD_MIN_LENGTH",
+	"password.require_upper":                    "PASSWORD_REQUIRE_UPPER",
+	"password.require_lower":                    "PASSWORD_REQUIRE_LOWER",
+	"password.require_digit":                    "PASSWORD_REQUIRE_DIGIT",
+	"password.require_special":                  "PASSWORD_REQUIRE_SPECIAL",
+	"password.breach_check":                     "PASSWORD_BREACH_CHECK",
+	"password.breach_check_url":                 "PASSWORD_BREACH_CHECK_URL",
+	"password.breach_check_timeout":             "PASSWORD_BREACH_CHECK_TIMEOUT",
+	"logging.level":                             "LOGGING_LEVEL",
+	"logging.format":                            "LOGGING_FORMAT",
+	"logging.file":                              "LOGGING_FILE",
+	"ratelimit.enabled":                         "RATELIMIT_ENABLED",
+	"ratelimit.requests":                        "RATELIMIT_REQUESTS",
+	"ratelimit.window":                          "RATELIMIT_WINDOW",
+	"ratelimit.user_requests":                   "RATELIMIT_USER_REQUESTS",
+	"ratelimit.user_window":                     "RATELIMIT_USER_WINDOW",
+	"ratelimit.email_check_requests":            "RATELIMIT_EMAIL_CHECK_REQUESTS",
+	"ratelimit.email_check_window":              "RATELIMIT_EMAIL_CHECK_WINDOW",
+	"ratelimit.omit_legacy_headers":             "RATELIMIT_OMIT_LEGACY_HEADERS",
+	"ratelimit.cache_size":                      "RATELIMIT_CACHE_SIZE",
+	"ratelimit.entry_ttl":                       "RATELIMIT_ENTRY_TTL",
+	"ratelimit.overflow_policy":                 "RATELIMIT_OVERFLOW_POLICY",
+	"ratelimit.overflow_requests":               "RATELIMIT_OVERFLOW_REQUESTS",
+	"ratelimit.warning_threshold":               "RATELIMIT_WARNING_THRESHOLD",
+	"ratelimit.exempt_roles":                    "RATELIMIT_EXEMPT_ROLES",
+	"ratelimit.exempt_ips":                      "RATELIMIT_EXEMPT_IPS",
+	"migrations.directory":                      "MIGRATIONS_DIRECTORY",
+	"migrations.use_embedded":                   "MIGRATIONS_USE_EMBEDDED",
+	"migrations.timeout":                        "MIGRATIONS_TIMEOUT",
+	"migrations.locktimeout":                    "MIGRATIONS_LOCKTIMEOUT",
+	"health.timeout":                            "HEALTH_TIMEOUT",
+	"health.database_check_enabled":             "HEALTH_DATABASE_CHECK_ENABLED",
+	"health.migration_check_enabled":            "HEALTH_MIGRATION_CHECK_ENABLED",
+	"health.stream_interval":                    "HEALTH_STREAM_INTERVAL",
+	"maintenance.enabled":                       "MAINTENANCE_ENABLED",
+	"maintenance.retry_after":                   "MAINTENANCE_RETRY_AFTER",
+	"scheduler.enabled":                         "SCHEDULER_ENABLED",
+	"scheduler.token_cleanup_interval":          "SCHEDULER_TOKEN_CLEANUP_INTERVAL",
+	"worker.concurrency":                        "WORKER_CONCURRENCY",
+	"worker.queue_size":                         "WORKER_QUEUE_SIZE",
+	"outbox.enabled":                            "OUTBOX_ENABLED",
+	"outbox.relay_interval":                     "OUTBOX_RELAY_INTERVAL",
+	"outbox.batch_size":                         "OUTBOX_BATCH_SIZE",
+	"outbox.max_attempts":                       "OUTBOX_MAX_ATTEMPTS",
+	"email.from":                                "EMAIL_FROM",
+	"email.send_timeout":                        "EMAIL_SEND_TIMEOUT",
+	"email.change_token_ttl":                    "EMAIL_CHANGE_TOKEN_TTL",
+	"email.public_base_url":                     "EMAIL_PUBLIC_BASE_URL",
+	"email.unsubscribe_ttl":                     "EMAIL_UNSUBSCRIBE_TTL",
+	"oauth.google.enabled":                      "OAUTH_GOOGLE_ENABLED",
+	"oauth.google.client_id":                    "OAUTH_GOOGLE_CLIENT_ID",
+	"oauth.google.client_secret":                "OAUTH_GOOGLE_CLIENT_SECRET",
+	"oauth.google.redirect_url":                 "OAUTH_GOOGLE_REDIRECT_URL",
+	"metrics.namespace":                         "METRICS_NAMESPACE",
+	"metrics.duration_buckets":                  "METRICS_DURATION_BUCKETS",
+	"metrics.size_buckets":                      "METRICS_SIZE_BUCKETS",
+	"metrics.token":                             "METRICS_TOKEN",
+	"tracing.enabled":                           "TRACING_ENABLED",
+	"tracing.endpoint":                          "TRACING_ENDPOINT",
+	"tracing.sample_ratio":                      "TRACING_SAMPLE_RATIO",
+	"tracing.service_name":                      "TRACING_SERVICE_NAME",
+	"graphql.enabled":                           "GRAPHQL_ENABLED",
+	"grpc.enabled":                              "GRPC_ENABLED",
+	"grpc.port":                                 "GRPC_PORT",
+	"websocket.enabled":                         "WEBSOCKET_ENABLED",
+	"websocket.ping_interval":                   "WEBSOCKET_PING_INTERVAL",
+	"admin.bulk_max_users":                      "ADMIN_BULK_MAX_USERS",
+	"admin.event_stream_max_connections":        "ADMIN_EVENT_STREAM_MAX_CONNECTIONS",
+	"auth.email_enumeration_protection":         "AUTH_EMAIL_ENUMERATION_PROTECTION",
+	"admin.bootstrap_enabled":                   "ADMIN_BOOTSTRAP_ENABLED",
+	"admin.email":                               "ADMIN_EMAIL",
+	"admin.password":                            "ADMIN_PASSWORD",
+	"admin.name":                                "ADMIN_NAME",
+	"license.max_users":                         "LICENSE_MAX_USERS",
+	"tenant.enabled":                            "TENANT_ENABLED",
+	"tenant.base_domain":                        "TENANT_BASE_DOMAIN",
+	"security.auto_login_on_register":           "SECURITY_AUTO_LOGIN_ON_REGISTER",
+	"security.token_delivery":                   "SECURITY_TOKEN_DELIVERY",
+	"security.cookie_secure":                    "SECURITY_COOKIE_SECURE",
+	"security.reset_link_template":              "SECURITY_RESET_LINK_TEMPLATE",
+	"security.password_hash.algorithm":          "SECURITY_PASSWORD_HASH_ALGORITHM",
+	"security.password_hash.bcrypt_cost":        "SECURITY_PASSWORD_HASH_BCRYPT_COST",
+	"security.password_hash.argon2_memory":      "SECURITY_PASSWORD_HASH_ARGON2_MEMORY",
+	"security.password_hash.argon2_iterations":  "SECURITY_PASSWORD_HASH_ARGON2_ITERATIONS",
+	"security.password_hash.argon2_parallelism": "SECURITY_PASSWORD_HASH_ARGON2_PARALLELISM",
+	"security.password_hash.rehash_on_login":    "SECURITY_PASSWORD_HASH_REHASH_ON_LOGIN",
+	"security.password_hash.max_concurrency":    "SECURITY_PASSWORD_HASH_MAX_CONCURRENCY",
+	"security.password_hash.max_wait":           "SECURITY_PASSWORD_HASH_MAX_WAIT",
+	"features.graphql":                          "FEATURES_GRAPHQL",
+	"features.oauth":                            "FEATURES_OAUTH",
+	"features.websocket":                        "FEATURES_WEBSOCKET",
+	"cors.allow_origins":                        "CORS_ALLOW_ORIGINS",
+	"cors.allow_methods":                        "CORS_ALLOW_METHODS",
+	"cors.allow_headers":                        "CORS_ALLOW_HEADERS",
+	"cors.expose_headers":                       "CORS_EXPOSE_HEADERS",
+	"cors.allow_credentials":                    "CORS_ALLOW_CREDENTIALS",
+	"cors.max_age":                              "CORS_MAX_AGE",
+	"config.strict":                             "CONFIG_STRICT",
+}
+
 func bindEnvVariables(v *viper.Viper) {
-	envBindings := map[string]string{
-		"app.name":                      "APP_NAME",
-		"app.version":                   "APP_VERSION",
-		"app.environment":               "APP_ENVIRONMENT",
-		"app.debug":                     "APP_DEBUG",
-		"database.host":                 "DATABASE_HOST",
-		"database.port":                 "DATABASE_PORT",
-		"database.user":                 "DATABASE_USER",
-		"database.password":             "DATABASE_PASSWORD",
-		"database.name":                 "DATABASE_NAME",
-		"database.sslmode":              "DATABASE_SSLMODE",
-		"jwt.secret":                    "JWT_SECRET",
-		"jwt.access_token_ttl":          "JWT_ACCESS_TOKEN_TTL",
-		"jwt.refresh_token_ttl":         "JWT_REFRESH_TOKEN_TTL",
-		"jwt.ttlhours":                  "JWT_TTLHOURS",
-		"server.port":                   "SERVER_PORT",
-		"server.readtimeout":            "SERVER_READTIMEOUT",
-		"server.writetimeout":           "SERVER_WRITETIMEOUT",
-		"server.idletimeout":            "SERVER_IDLETIMEOUT",
-		"server.shutdowntimeout":        "SERVER_SHUTDOWNTIMEOUT",
-		"server.maxheaderbytes":         "SERVER_MAXHEADERBYTES",
-		"logging.level":                 "LOGGING_LEVEL",
-		"ratelimit.enabled":             "RATELIMIT_ENABLED",
-		"ratelimit.requests":            "RATELIMIT_REQUESTS",
-		"ratelimit.window":              "RATELIMIT_WINDOW",
-		"migrations.directory":          "MIGRATIONS_DIRECTORY",
-		"migrations.timeout":            "MIGRATIONS_TIMEOUT",
-		"migrations.locktimeout":        "MIGRATIONS_LOCKTIMEOUT",
-		"health.timeout":                "HEALTH_TIMEOUT",
-		"health.database_check_enabled": "HEALTH_DATABASE_CHECK_ENABLED",
-	}
 	for key, env := range envBindings {
 		_ = v.BindEnv(key, env)
 	}
@@ -223,10 +767,32 @@ func GetConfigPath() string {
 func (c *Config) LogSafeConfig(logger *slog.Logger) {
 	logger.Info("Loaded Configuration:")
 	logger.Info("App", "Name", c.App.Name, "Environment", c.App.Environment, "Debug", c.App.Debug)
-	logger.Info("Database", "Host", c.Database.Host, "Port", c.Database.Port, "User", c.Database.User, "Password", "<redacted>", "Name", c.Database.Name, "SSLMode", c.Database.SSLMode)
-	logger.Info("JWT", "Secret", "<redacted>", "AccessTokenTTL", c.JWT.AccessTokenTTL, "RefreshTokenTTL", c.JWT.RefreshTokenTTL)
-	logger.Info("Server", "Port", c.Server.Port, "ReadTimeout", c.Server.ReadTimeout, "WriteTimeout", c.Server.WriteTimeout, "IdleTimeout", c.Server.IdleTimeout, "ShutdownTimeout", c.Server.ShutdownTimeout, "MaxHeaderBytes", c.Server.MaxHeaderBytes)
-	logger.Info("Logging", "Level", c.Logging.Level)
-	logger.Info("RateLimit", "Enabled", c.Ratelimit.Enabled, "Requests", c.Ratelimit.Requests, "Window", c.Ratelimit.Window)
-	logger.Info("Migrations", "Directory", c.Migrations.Directory, "Timeout", c.Migrations.Timeout, "LockTimeout", c.Migrations.LockTimeout)
+	logger.Info("Database", "Host", c.Database.Host, "Port", c.Database.Port, "User", c.Database.User, "Password", "<redacted>", "Name", c.Database.Name, "SSLMode", c.Database.SSLMode, "TablePrefix", c.Database.TablePrefix, "RequestTransactions", c.Database.RequestTransactions)
+	logger.Info("JWT", "Secret", "<redacted>", "AccessTokenTTL", c.JWT.AccessTokenTTL, "RefreshTokenTTL", c.JWT.RefreshTokenTTL, "AccessOnlyFallback", c.JWT.AccessOnlyFallback, "LegacyAuthResponse", c.JWT.LegacyAuthResponse, "RefreshReuseGrace", c.JWT.RefreshReuseGrace, "RefreshUpdatesLastLogin", c.JWT.RefreshUpdatesLastLogin, "UserStatusCacheTTL", c.JWT.UserStatusCacheTTL, "Leeway", c.JWT.Leeway, "NotBeforeOffset", c.JWT.NotBeforeOffset)
+	logger.Info("Password", "MinLength", c.Password.MinLength, "RequireUpper", c.Password.RequireUpper, "RequireLower", c.Password.RequireLower, "RequireDigit", c.Password.RequireDigit, "RequireSpecial", c.Password.RequireSpecial, "BreachCheck", c.Password.BreachCheck, "BreachCheckURL", c.Password.BreachCheckURL, "BreachCheckTimeout", c.Password.BreachCheckTimeout)
+	logger.Info("Server", "Port", c.Server.Port, "ReadTimeout", c.Server.ReadTimeout, "WriteTimeout", c.Server.WriteTimeout, "IdleTimeout", c.Server.IdleTimeout, "ShutdownTimeout", c.Server.ShutdownTimeout, "MaxHeaderBytes", c.Server.MaxHeaderBytes, "ResponseFormat", c.Server.ResponseFormat, "SwaggerEnabled", c.Server.SwaggerEnabled, "BehindProxy", c.Server.BehindProxy, "TrustedProxies", c.Server.TrustedProxies, "StrictJSON", c.Server.StrictJSON, "MaxJSONBytes", c.Server.MaxJSONBytes, "MaxJSONDepth", c.Server.MaxJSONDepth, "VersionAdminOnly", c.Server.VersionAdminOnly, "ServerHeader", c.Server.ServerHeader, "ResponseTimeHeader", c.Server.ResponseTimeHeader, "StaticCacheMaxAge", c.Server.StaticCacheMaxAge, "StrictHTTPSemantics", c.Server.StrictHTTPSemantics, "MaxInflight", c.Server.MaxInflight, "MaxInflightPerUser", c.Server.MaxInflightPerUser)
+	logger.Info("Logging", "Level", c.Logging.Level, "Format", c.Logging.Format, "File", c.Logging.File)
+	logger.Info("RateLimit", "Enabled", c.Ratelimit.Enabled, "Requests", c.Ratelimit.Requests, "Window", c.Ratelimit.Window, "UserRequests", c.Ratelimit.UserRequests, "UserWindow", c.Ratelimit.UserWindow, "EmailCheckRequests", c.Ratelimit.EmailCheckRequests, "EmailCheckWindow", c.Ratelimit.EmailCheckWindow, "OmitLegacyHeaders", c.Ratelimit.OmitLegacyHeaders, "CacheSize", c.Ratelimit.CacheSize, "EntryTTL", c.Ratelimit.EntryTTL, "OverflowPolicy", c.Ratelimit.OverflowPolicy, "OverflowRequests", c.Ratelimit.OverflowRequests, "WarningThreshold", c.Ratelimit.WarningThreshold, "ExemptRoles", c.Ratelimit.ExemptRoles, "ExemptIPs", c.Ratelimit.ExemptIPs)
+	logger.Info("Migrations", "Directory", c.Migrations.Directory, "UseEmbedded", c.Migrations.UseEmbedded, "Timeout", c.Migrations.Timeout, "LockTimeout", c.Migrations.LockTimeout)
+	logger.Info("Health", "Timeout", c.Health.Timeout, "DatabaseCheckEnabled", c.Health.DatabaseCheckEnabled, "MigrationCheckEnabled", c.Health.MigrationCheckEnabled, "StreamInterval", c.Health.StreamInterval)
+	logger.Info("Maintenance", "Enabled", c.Maintenance.Enabled, "RetryAfter", c.Maintenance.RetryAfter)
+	logger.Info("Scheduler", "Enabled", c.Scheduler.Enabled, "TokenCleanupInterval", c.Scheduler.TokenCleanupInterval)
+	logger.Info("Worker", "Concurrency", c.Worker.Concurrency, "QueueSize", c.Worker.QueueSize)
+	logger.Info("Outbox", "Enabled", c.Outbox.Enabled, "RelayInterval", c.Outbox.RelayInterval, "BatchSize", c.Outbox.BatchSize, "MaxAttempts", c.Outbox.MaxAttempts)
+	logger.Info("Email", "From", c.Email.From, "SendTimeout", c.Email.SendTimeout, "ChangeTokenTTL", c.Email.ChangeTokenTTL, "PublicBaseURL", c.Email.PublicBaseURL, "UnsubscribeTTL", c.Email.UnsubscribeTTL)
+	logger.Info("Metrics", "Namespace", c.Metrics.Namespace, "DurationBuckets", c.Metrics.DurationBuckets, "SizeBuckets", c.Metrics.SizeBuckets, "TokenRequired", c.Metrics.Token != "")
+	logger.Info("Tracing", "Enabled", c.Tracing.Enabled, "Endpoint", c.Tracing.Endpoint, "SampleRatio", c.Tracing.SampleRatio, "ServiceName", c.Tracing.ServiceName)
+	logger.Info("GraphQL", "Enabled", c.GraphQL.Enabled)
+	logger.Info("gRPC", "Enabled", c.GRPC.Enabled, "Port", c.GRPC.Port)
+	logger.Info("WebSocket", "Enabled", c.WebSocket.Enabled, "PingInterval", c.WebSocket.PingInterval)
+	logger.Info("Admin", "BulkMaxUsers", c.Admin.BulkMaxUsers, "EventStreamMaxConnections", c.Admin.EventStreamMaxConnections, "BootstrapEnabled", c.Admin.BootstrapEnabled, "Email", c.Admin.Email, "Password", "<redacted>", "Name", c.Admin.Name)
+	logger.Info("License", "MaxUsers", c.License.MaxUsers)
+	logger.Info("Tenant", "Enabled", c.Tenant.Enabled, "BaseDomain", c.Tenant.BaseDomain)
+	logger.Info("Auth", "EmailEnumerationProtection", c.Auth.EmailEnumerationProtection)
+	logger.Info("Security", "AutoLoginOnRegister", c.Security.AutoLoginOnRegister, "ResetLinkTemplate", c.Security.ResetLinkTemplate, "TokenDelivery", c.Security.TokenDelivery, "CookieSecure", c.Security.CookieSecure)
+	logger.Info("PasswordHash", "Algorithm", c.Security.PasswordHash.Algorithm, "BcryptCost", c.Security.PasswordHash.BcryptCost, "Argon2Memory", c.Security.PasswordHash.Argon2Memory, "Argon2Iterations", c.Security.PasswordHash.Argon2Iterations, "Argon2Parallelism", c.Security.PasswordHash.Argon2Parallelism, "RehashOnLogin", c.Security.PasswordHash.RehashOnLogin, "MaxConcurrency", c.Security.PasswordHash.MaxConcurrency, "MaxWait", c.Security.PasswordHash.MaxWait)
+	logger.Info("Features", "Flags", c.Features)
+	logger.Info("Settings", "Strict", c.Settings.Strict)
+	logger.Info("CORS", "AllowOrigins", c.CORS.AllowOrigins, "AllowMethods", c.CORS.AllowMethods, "AllowHeaders", c.CORS.AllowHeaders, "ExposeHeaders", c.CORS.ExposeHeaders, "AllowCredentials", c.CORS.AllowCredentials, "MaxAge", c.CORS.MaxAge)
+	logger.Info("OAuth", "GoogleEnabled", c.OAuth.Google.Enabled, "GoogleClientID", c.OAuth.Google.ClientID, "GoogleClientSecret", "<redacted>", "GoogleRedirectURL", c.OAuth.Google.RedirectURL)
 }
@@ -4,10 +4,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // createTempConfigFile creates a temporary YAML config file for testing.
@@ -221,6 +224,10 @@ database:
 jwt:
   secret: "qrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyzAB"
   ttlhours: 24
+ratelimit:
+  enabled: true
+cors:
+  allow_origins: ["https://app.example.com"]
 `)
 		// Temporarily change working directory so LoadConfig can find the "configs" folder
 		oldWd, err := os.Getwd()
@@ -697,6 +704,219 @@ server:
 	})
 }
 
+func TestLoadConfig_EnvExpansion(t *testing.T) {
+	configContent := `
+app:
+  name: "${EXPAND_TEST_APP}-api"
+  environment: "development"
+database:
+  host: "${EXPAND_TEST_DB_HOST}"
+  port: 5432
+  user: "${EXPAND_TEST_UNSET}"
+  password: "pa$$word$${EXPAND_TEST_DB_HOST}"
+  name: "testdb"
+  sslmode: "disable"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+server:
+  port: "8080"
+`
+	t.Setenv("EXPAND_TEST_APP", "orders")
+	t.Setenv("EXPAND_TEST_DB_HOST", "db.internal")
+	t.Setenv("DATABASE_HOST", "")
+	t.Setenv("DATABASE_USER", "")
+	t.Setenv("DATABASE_PASSWORD", "")
+
+	t.Run("expands set variables and keeps the rest", func(t *testing.T) {
+		path := createTempConfigFile(t, t.TempDir(), "config.yaml", configContent)
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "orders-api", cfg.App.Name)
+		assert.Equal(t, "db.internal", cfg.Database.Host)
+		assert.Equal(t, "${EXPAND_TEST_UNSET}", cfg.Database.User, "unset variables are left as written")
+		assert.Equal(t, "pa$$word${EXPAND_TEST_DB_HOST}", cfg.Database.Password, "$${ escapes a literal ${")
+	})
+
+	t.Run("environment overrides are not expanded", func(t *testing.T) {
+		path := createTempConfigFile(t, t.TempDir(), "config.yaml", configContent)
+		t.Setenv("DATABASE_PASSWORD", "${EXPAND_TEST_APP}")
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "${EXPAND_TEST_APP}", cfg.Database.Password)
+	})
+
+	t.Run("strict mode rejects unset variables", func(t *testing.T) {
+		path := createTempConfigFile(t, t.TempDir(), "config.yaml", configContent)
+		t.Setenv(StrictExpansionEnv, "true")
+
+		_, err := LoadConfig(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "EXPAND_TEST_UNSET")
+	})
+
+	t.Run("values are substituted as plain strings", func(t *testing.T) {
+		path := createTempConfigFile(t, t.TempDir(), "config.yaml", configContent)
+		t.Setenv("EXPAND_TEST_APP", "abc #123")
+		t.Setenv("EXPAND_TEST_DB_HOST", "db.internal\nsslmode: require\n")
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "abc #123-api", cfg.App.Name, "a # in a value is not a comment")
+		assert.Equal(t, "db.internal\nsslmode: require\n", cfg.Database.Host)
+		assert.Equal(t, "disable", cfg.Database.SSLMode, "a value cannot add keys")
+	})
+
+	t.Run("references in comments are ignored", func(t *testing.T) {
+		path := createTempConfigFile(t, t.TempDir(), "config.yaml", "# set ${EXPAND_TEST_UNSET} to ...\n"+strings.ReplaceAll(configContent, "${EXPAND_TEST_UNSET}", "dbuser"))
+		t.Setenv(StrictExpansionEnv, "true")
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "dbuser", cfg.Database.User)
+	})
+}
+
+func TestReadConfig_ShippedConfigIsStrictClean(t *testing.T) {
+	t.Setenv(StrictExpansionEnv, "true")
+
+	_, err := ReadConfig("../../configs/config.yaml")
+	require.NoError(t, err)
+}
+
+// configTree writes files, keyed by path relative to a temporary directory,
+// and makes that directory the working directory for the test
+func configTree(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "configs"), 0o755))
+	for name, content := range files {
+		createTempConfigFile(t, dir, name, content)
+	}
+	t.Chdir(dir)
+}
+
+func TestReadConfig_Precedence(t *testing.T) {
+	const base = `
+app:
+  name: "base"
+  environment: "development"
+database:
+  host: "base-host"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`
+	const environment = `
+app:
+  name: "environment"
+database:
+  host: "environment-host"
+`
+	const local = `
+defaults: &local
+  host: "local-host"
+app:
+  name: "local"
+database:
+  <<: *local
+`
+	unsetEnv(t, "APP_NAME", "APP_ENVIRONMENT", "DATABASE_HOST", "DATABASE_PASSWORD", "JWT_SECRET")
+
+	t.Run("environment file overrides base", func(t *testing.T) {
+		configTree(t, map[string]string{
+			"configs/config.yaml":             base,
+			"configs/config.development.yaml": environment,
+		})
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "environment", cfg.App.Name)
+		assert.Equal(t, "environment-host", cfg.Database.Host)
+	})
+
+	t.Run("local file overrides environment file", func(t *testing.T) {
+		configTree(t, map[string]string{
+			"configs/config.yaml":             base,
+			"configs/config.development.yaml": environment,
+			"configs/config.local.yaml":       local,
+		})
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "local", cfg.App.Name)
+		assert.Equal(t, "local-host", cfg.Database.Host, "YAML anchors and merge keys resolve")
+	})
+
+	t.Run("environment variables override local file", func(t *testing.T) {
+		configTree(t, map[string]string{
+			"configs/config.yaml":       base,
+			"configs/config.local.yaml": local,
+		})
+		t.Setenv("APP_NAME", "from-env")
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", cfg.App.Name)
+		assert.Equal(t, "local-host", cfg.Database.Host)
+	})
+
+	t.Run(".env file counts as environment variables", func(t *testing.T) {
+		unsetEnv(t, "APP_NAME", "DATABASE_PASSWORD")
+		configTree(t, map[string]string{
+			"configs/config.yaml":       base,
+			"configs/config.local.yaml": local,
+			".env":                      "APP_NAME=from-dotenv\nDATABASE_PASSWORD=dotenv-secret\nDATABASE_HOST=dotenv-host\n",
+		})
+		t.Setenv("DATABASE_HOST", "from-env")
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "from-dotenv", cfg.App.Name)
+		assert.Equal(t, "dotenv-secret", cfg.Database.Password)
+		assert.Equal(t, "from-env", cfg.Database.Host, "the real environment wins over .env")
+	})
+
+	t.Run(".env can select the environment file", func(t *testing.T) {
+		unsetEnv(t, "APP_ENVIRONMENT")
+		configTree(t, map[string]string{
+			"configs/config.yaml":         base,
+			"configs/config.staging.yaml": environment,
+			".env":                        "APP_ENVIRONMENT=staging\n",
+		})
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "environment", cfg.App.Name)
+	})
+
+	t.Run("production does not read .env", func(t *testing.T) {
+		unsetEnv(t, "DATABASE_PASSWORD")
+		configTree(t, map[string]string{
+			"configs/config.yaml": base,
+			".env":                "DATABASE_PASSWORD=dotenv-secret\n",
+		})
+		t.Setenv("APP_ENVIRONMENT", "production")
+
+		cfg, err := ReadConfig("")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Database.Password)
+		_, set := os.LookupEnv("DATABASE_PASSWORD")
+		assert.False(t, set)
+	})
+
+	t.Run(".env resolving to production is refused", func(t *testing.T) {
+		unsetEnv(t, "APP_ENVIRONMENT")
+		configTree(t, map[string]string{
+			"configs/config.yaml": base,
+			".env":                "APP_ENVIRONMENT=production\n",
+		})
+
+		_, err := ReadConfig("")
+		assert.ErrorIs(t, err, ErrDotEnvInProduction)
+	})
+}
+
 func TestGetConfigPath_AllPaths(t *testing.T) {
 	t.Run("returns absolute path when config exists", func(t *testing.T) {
 		result := GetConfigPath()
@@ -735,6 +955,141 @@ func TestValidate_ProductionSSLMode(t *testing.T) {
 	assert.Contains(t, err.Error(), "SSL mode cannot be 'disable' in production")
 }
 
+func secureProductionConfig() Config {
+	return Config{
+		App: AppConfig{Environment: "production"},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Password: "securepassword",
+			SSLMode:  "require",
+		},
+		JWT:       JWTConfig{Secret: "longjwtauthenticationkeywithatleastsixtyfourcharsforprodvalidation"},
+		Ratelimit: RateLimitConfig{Enabled: true},
+		CORS:      CORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+	}
+}
+
+func TestValidate_ProductionHardening(t *testing.T) {
+	t.Run("secure config passes", func(t *testing.T) {
+		cfg := secureProductionConfig()
+		assert.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name     string
+		mutate   func(*Config)
+		errorMsg string
+	}{
+		{"debug enabled", func(c *Config) { c.App.Debug = true }, "app.debug must be false in production"},
+		{"default JWT secret", func(c *Config) { c.JWT.Secret = InsecureDefaultJWTSecret }, "jwt.secret must not be the default placeholder"},
+		{"missing database password", func(c *Config) { c.Database.Password = "" }, "database.password is required in production"},
+		{"sslmode disable", func(c *Config) { c.Database.SSLMode = "disable" }, "SSL mode cannot be 'disable' in production"},
+		{"rate limiting disabled", func(c *Config) { c.Ratelimit.Enabled = false }, "ratelimit.enabled must be true in production"},
+		{"swagger enabled", func(c *Config) { c.Server.SwaggerEnabled = true }, "server.swagger_enabled must be false in production"},
+		{"CORS allow-all", func(c *Config) { c.CORS.AllowOrigins = []string{"*"} }, "cors.allow_origins must list explicit origins"},
+		{"CORS origins unset", func(c *Config) { c.CORS.AllowOrigins = nil }, "cors.allow_origins must list explicit origins"},
+		{"behind proxy without trusted proxies", func(c *Config) { c.Server.BehindProxy = true }, "server.trusted_proxies is required in production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := secureProductionConfig()
+			tt.mutate(&cfg)
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "insecure production configuration")
+			assert.Contains(t, err.Error(), tt.errorMsg)
+
+			// The same settings are allowed outside production
+			cfg.App.Environment = "development"
+			assert.NotContains(t, errString(cfg.Validate()), "insecure production configuration")
+		})
+	}
+
+	t.Run("reports every failure at once", func(t *testing.T) {
+		cfg := secureProductionConfig()
+		cfg.App.Debug = true
+		cfg.Ratelimit.Enabled = false
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "app.debug")
+		assert.Contains(t, err.Error(), "ratelimit.enabled")
+	})
+
+	t.Run("behind proxy with trusted proxies passes", func(t *testing.T) {
+		cfg := secureProductionConfig()
+		cfg.Server.BehindProxy = true
+		cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.10"}
+		assert.NoError(t, cfg.Validate())
+	})
+}
+
+func TestValidate_TrustedProxies(t *testing.T) {
+	cfg := Config{
+		App:      AppConfig{Environment: "development"},
+		Database: DatabaseConfig{Host: "localhost"},
+		JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+		Server:   ServerConfig{TrustedProxies: []string{"10.0.0.1", "not-an-ip"}},
+	}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `server.trusted_proxies contains invalid IP or CIDR "not-an-ip"`)
+}
+
+func TestProductionChecks(t *testing.T) {
+	cfg := secureProductionConfig()
+	for _, check := range cfg.ProductionChecks() {
+		assert.True(t, check.Passed, check.Name)
+	}
+
+	cfg.Server.SwaggerEnabled = true
+	var failed []string
+	for _, check := range cfg.ProductionChecks() {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	assert.Equal(t, []string{"server.swagger_enabled"}, failed)
+}
+
+func TestReadConfig_SkipsValidation(t *testing.T) {
+	viper.Reset()
+	t.Setenv("APP_ENVIRONMENT", "")
+	t.Setenv("APP_DEBUG", "")
+	t.Setenv("JWT_SECRET", "")
+
+	path := createTempConfigFile(t, t.TempDir(), "config.yaml", `
+app:
+  environment: "production"
+  debug: true
+database:
+  host: "testhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`)
+
+	cfg, err := ReadConfig(path)
+	assert.NoError(t, err)
+	if assert.NotNil(t, cfg) {
+		assert.True(t, cfg.App.Debug)
+		assert.Error(t, cfg.Validate())
+	}
+
+	_, err = LoadConfig(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "app.debug must be false in production")
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
 func TestValidate_DatabaseHostRequired(t *testing.T) {
 	cfg := Config{
 		App: AppConfig{
@@ -814,6 +1169,8 @@ func TestValidate_JWTSecret(t *testing.T) {
 				JWT: JWTConfig{
 					Secret: tt.jwtSecret,
 				},
+				Ratelimit: RateLimitConfig{Enabled: true},
+				CORS:      CORSConfig{AllowOrigins: []string{"https://app.example.com"}},
 			}
 
 			err := cfg.Validate()
@@ -826,3 +1183,1184 @@ func TestValidate_JWTSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_CORS(t *testing.T) {
+	base := func(cors CORSConfig) Config {
+		return Config{
+			App:      AppConfig{Environment: "development"},
+			Database: DatabaseConfig{Host: "localhost"},
+			JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			CORS:     cors,
+		}
+	}
+
+	cfg := base(CORSConfig{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true, MaxAge: time.Hour})
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base(CORSConfig{MaxAge: -time.Second})
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cors.max_age must be non-negative")
+
+	cfg = base(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cors.allow_credentials requires explicit cors.allow_origins")
+}
+
+func TestCORSConfig_AllowsAllOrigins(t *testing.T) {
+	assert.True(t, CORSConfig{}.AllowsAllOrigins())
+	assert.True(t, CORSConfig{AllowOrigins: []string{"https://a.example.com", "*"}}.AllowsAllOrigins())
+	assert.False(t, CORSConfig{AllowOrigins: []string{"https://a.example.com"}}.AllowsAllOrigins())
+}
+
+func TestValidate_ResponseFormat(t *testing.T) {
+	for _, format := range []string{"", "standard", "envelope"} {
+		cfg := Config{
+			App:      AppConfig{Environment: "development"},
+			Database: DatabaseConfig{Host: "localhost"},
+			JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			Server:   ServerConfig{ResponseFormat: format},
+		}
+		assert.NoError(t, cfg.Validate(), format)
+	}
+
+	cfg := Config{
+		App:      AppConfig{Environment: "development"},
+		Database: DatabaseConfig{Host: "localhost"},
+		JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+		Server:   ServerConfig{ResponseFormat: "xml"},
+	}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "server.response_format")
+}
+
+func TestRateLimitConfig_EmailCheck(t *testing.T) {
+	cfg := RateLimitConfig{Requests: 100, Window: time.Hour}
+	assert.Equal(t, DefaultEmailCheckRequests, cfg.EmailCheckLimit(), "the general budget is not inherited")
+	assert.Equal(t, DefaultEmailCheckWindow, cfg.EmailCheckPeriod())
+
+	cfg.EmailCheckRequests = 3
+	cfg.EmailCheckWindow = 30 * time.Second
+	assert.Equal(t, 3, cfg.EmailCheckLimit())
+	assert.Equal(t, 30*time.Second, cfg.EmailCheckPeriod())
+
+	invalid := Config{
+		App:       AppConfig{Environment: "development"},
+		Database:  DatabaseConfig{Host: "localhost"},
+		JWT:       JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+		Ratelimit: RateLimitConfig{EmailCheckWindow: -time.Second},
+	}
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ratelimit.email_check_requests and ratelimit.email_check_window must be non-negative")
+}
+
+func TestRateLimitConfig_PerUser(t *testing.T) {
+	cfg := RateLimitConfig{Requests: 100, Window: time.Minute}
+	assert.Equal(t, 100, cfg.PerUserRequests())
+	assert.Equal(t, time.Minute, cfg.PerUserWindow())
+
+	cfg.UserRequests = 30
+	cfg.UserWindow = 10 * time.Second
+	assert.Equal(t, 30, cfg.PerUserRequests())
+	assert.Equal(t, 10*time.Second, cfg.PerUserWindow())
+
+	invalid := Config{
+		App:       AppConfig{Environment: "development"},
+		Database:  DatabaseConfig{Host: "localhost"},
+		JWT:       JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+		Ratelimit: RateLimitConfig{UserRequests: -1},
+	}
+	err := invalid.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ratelimit.user_requests")
+}
+
+func TestValidate_RateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		ratelimit RateLimitConfig
+		errorMsg  string
+	}{
+		{name: "defaults", ratelimit: RateLimitConfig{}},
+		{name: "configured", ratelimit: RateLimitConfig{CacheSize: 10000, EntryTTL: time.Hour}},
+		{name: "negative cache size", ratelimit: RateLimitConfig{CacheSize: -1}, errorMsg: "ratelimit.cache_size and ratelimit.entry_ttl must be non-negative"},
+		{name: "negative entry ttl", ratelimit: RateLimitConfig{EntryTTL: -time.Minute}, errorMsg: "ratelimit.cache_size and ratelimit.entry_ttl must be non-negative"},
+		{name: "shared overflow", ratelimit: RateLimitConfig{OverflowPolicy: "shared", OverflowRequests: 10}},
+		{name: "evict overflow", ratelimit: RateLimitConfig{OverflowPolicy: "evict"}},
+		{name: "unknown overflow policy", ratelimit: RateLimitConfig{OverflowPolicy: "drop"}, errorMsg: "ratelimit.overflow_policy must be 'evict' or 'shared'"},
+		{name: "negative overflow requests", ratelimit: RateLimitConfig{OverflowRequests: -1}, errorMsg: "ratelimit.overflow_requests must be non-negative"},
+		{name: "warning threshold", ratelimit: RateLimitConfig{WarningThreshold: 0.1}},
+		{name: "negative warning threshold", ratelimit: RateLimitConfig{WarningThreshold: -0.1}, errorMsg: "ratelimit.warning_threshold must be between 0 and 1"},
+		{name: "warning threshold above one", ratelimit: RateLimitConfig{WarningThreshold: 1.5}, errorMsg: "ratelimit.warning_threshold must be between 0 and 1"},
+		{name: "exempt roles and ips", ratelimit: RateLimitConfig{ExemptRoles: []string{"admin"}, ExemptIPs: []string{"10.0.0.5", "192.168.0.0/16", "::1"}}},
+		{name: "invalid exempt ip", ratelimit: RateLimitConfig{ExemptIPs: []string{"not-an-ip"}}, errorMsg: `ratelimit.exempt_ips contains invalid IP or CIDR "not-an-ip"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:       AppConfig{Environment: "development"},
+				Server:    ServerConfig{Port: "8080"},
+				Database:  DatabaseConfig{Host: "localhost"},
+				JWT:       JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Ratelimit: tt.ratelimit,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_PasswordMinLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password PasswordConfig
+		errorMsg string
+	}{
+		{name: "default", password: PasswordConfig{}},
+		{name: "configured", password: PasswordConfig{MinLength: 12, RequireUpper: true, RequireSpecial: true}},
+		{name: "bcrypt limit", password: PasswordConfig{MinLength: 72}},
+		{name: "negative", password: PasswordConfig{MinLength: -1}, errorMsg: "password.min_length must be between 0 and 72"},
+		{name: "above bcrypt limit", password: PasswordConfig{MinLength: 73}, errorMsg: "password.min_length must be between 0 and 72"},
+		{name: "breach check", password: PasswordConfig{BreachCheck: true, BreachCheckURL: "https://api.pwnedpasswords.com/range/", BreachCheckTimeout: 2 * time.Second}},
+		{name: "breach check default URL", password: PasswordConfig{BreachCheck: true}},
+		{name: "negative breach check timeout", password: PasswordConfig{BreachCheckTimeout: -time.Second}, errorMsg: "password.breach_check_timeout must be non-negative"},
+		{name: "relative breach check URL", password: PasswordConfig{BreachCheck: true, BreachCheckURL: "/range/"}, errorMsg: "password.breach_check_url must be an absolute URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Password: tt.password,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_HealthTimeouts(t *testing.T) {
+	base := func(health HealthConfig) Config {
+		return Config{
+			App:      AppConfig{Environment: "development"},
+			Database: DatabaseConfig{Host: "localhost"},
+			JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			Health:   health,
+		}
+	}
+
+	cfg := base(HealthConfig{Timeout: 5, CheckerTimeouts: map[string]time.Duration{"database": 2 * time.Second}})
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base(HealthConfig{Timeout: -1})
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "health.timeout must be non-negative")
+
+	cfg = base(HealthConfig{CheckerTimeouts: map[string]time.Duration{"database": 0}})
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "health.checker_timeouts.database must be positive")
+
+	cfg = base(HealthConfig{StreamInterval: 5 * time.Second})
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base(HealthConfig{StreamInterval: -time.Second})
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "health.stream_interval must be non-negative")
+}
+
+func TestLoadConfig_HealthCheckerTimeouts(t *testing.T) {
+	viper.Reset()
+	path := createTempConfigFile(t, t.TempDir(), "config.yaml", `
+app:
+  environment: "development"
+database:
+  host: "testhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+health:
+  timeout: 3
+  checker_timeouts:
+    database: 2s
+    scheduler: 500ms
+`)
+
+	cfg, err := LoadConfig(path)
+	assert.NoError(t, err)
+	if assert.NotNil(t, cfg) {
+		assert.Equal(t, 3, cfg.Health.Timeout)
+		assert.Equal(t, map[string]time.Duration{"database": 2 * time.Second, "scheduler": 500 * time.Millisecond}, cfg.Health.CheckerTimeouts)
+	}
+}
+
+func TestValidate_ServerHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   ServerConfig
+		errorMsg string
+	}{
+		{name: "removed", server: ServerConfig{Port: "8080"}},
+		{name: "overridden", server: ServerConfig{Port: "8080", ServerHeader: "api", ResponseTimeHeader: true}},
+		{name: "line break", server: ServerConfig{Port: "8080", ServerHeader: "api\r\nX-Injected: 1"}, errorMsg: "server.server_header must not contain line breaks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   tt.server,
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_JSONLimits(t *testing.T) {
+	base := func(server ServerConfig) Config {
+		return Config{
+			App:      AppConfig{Environment: "development"},
+			Database: DatabaseConfig{Host: "localhost"},
+			JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			Server:   server,
+		}
+	}
+
+	cfg := base(ServerConfig{StrictJSON: true, MaxJSONBytes: 4096, MaxJSONDepth: 8})
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base(ServerConfig{MaxJSONBytes: -1})
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "server.max_json_bytes must be non-negative")
+
+	cfg = base(ServerConfig{MaxJSONDepth: -1})
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "server.max_json_depth must be non-negative")
+}
+
+func TestValidate_StaticCacheMaxAge(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxAge   time.Duration
+		errorMsg string
+	}{
+		{name: "disabled", maxAge: 0},
+		{name: "one hour", maxAge: time.Hour},
+		{name: "negative", maxAge: -time.Second, errorMsg: "server.static_cache_max_age must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080", StaticCacheMaxAge: tt.maxAge},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_MaxInflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxInflight int
+		perUser     int
+		errorMsg    string
+	}{
+		{name: "disabled", maxInflight: 0, perUser: 0},
+		{name: "both caps", maxInflight: 500, perUser: 10},
+		{name: "negative global cap", maxInflight: -1, errorMsg: "server.max_inflight must be non-negative"},
+		{name: "negative per-user cap", perUser: -1, errorMsg: "server.max_inflight_per_user must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080", MaxInflight: tt.maxInflight, MaxInflightPerUser: tt.perUser},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_RefreshReuseGrace(t *testing.T) {
+	base := func(jwt JWTConfig) Config {
+		jwt.Secret = "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+		return Config{
+			App:      AppConfig{Environment: "development"},
+			Database: DatabaseConfig{Host: "localhost"},
+			JWT:      jwt,
+		}
+	}
+
+	cfg := base(JWTConfig{RefreshTokenTTL: time.Hour, RefreshReuseGrace: 10 * time.Second})
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base(JWTConfig{RefreshReuseGrace: -time.Second})
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt.refresh_reuse_grace must be non-negative")
+
+	cfg = base(JWTConfig{RefreshTokenTTL: time.Minute, RefreshReuseGrace: time.Minute})
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt.refresh_reuse_grace must be shorter than jwt.refresh_token_ttl")
+}
+
+func TestValidate_UserStatusCacheTTL(t *testing.T) {
+	cfg := Config{
+		App:      AppConfig{Environment: "development"},
+		Database: DatabaseConfig{Host: "localhost"},
+		JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP", UserStatusCacheTTL: 30 * time.Second},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.JWT.UserStatusCacheTTL = -time.Second
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt.user_status_cache_ttl must be non-negative")
+}
+
+func TestValidate_JWTLeeway(t *testing.T) {
+	cfg := Config{
+		App:      AppConfig{Environment: "development"},
+		Database: DatabaseConfig{Host: "localhost"},
+		JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP", Leeway: 30},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.JWT.Leeway = -1
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt.leeway must be non-negative")
+}
+
+func TestValidate_JWTNotBeforeOffset(t *testing.T) {
+	cfg := Config{
+		App:      AppConfig{Environment: "development"},
+		Database: DatabaseConfig{Host: "localhost"},
+		JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP", NotBeforeOffset: time.Minute},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.JWT.NotBeforeOffset = -time.Second
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt.not_before_offset must be non-negative")
+}
+
+func TestLoadConfig_Metrics(t *testing.T) {
+	tempDir := t.TempDir()
+	path := createTempConfigFile(t, tempDir, "config.yaml", `
+database:
+  host: "localhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+metrics:
+  namespace: "orders"
+  duration_buckets: [0.005, 0.01, 0.05]
+`)
+	t.Setenv("METRICS_SIZE_BUCKETS", "100,1000,10000")
+
+	cfg, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", cfg.Metrics.Namespace)
+	assert.Equal(t, []float64{0.005, 0.01, 0.05}, cfg.Metrics.DurationBuckets)
+	assert.Equal(t, []float64{100, 1000, 10000}, cfg.Metrics.SizeBuckets)
+}
+
+func TestLoadConfig_AutoLoginOnRegister(t *testing.T) {
+	base := `
+database:
+  host: "localhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`
+
+	t.Run("defaults to true when unset", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.True(t, cfg.Security.AutoLoginOnRegister)
+	})
+
+	t.Run("disabled in the config file", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base+`
+security:
+  auto_login_on_register: false
+`))
+		assert.NoError(t, err)
+		assert.False(t, cfg.Security.AutoLoginOnRegister)
+	})
+
+	t.Run("disabled by environment", func(t *testing.T) {
+		t.Setenv("SECURITY_AUTO_LOGIN_ON_REGISTER", "false")
+
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.False(t, cfg.Security.AutoLoginOnRegister)
+	})
+}
+
+func TestLoadConfig_CookieSecure(t *testing.T) {
+	base := `
+database:
+  host: "localhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`
+
+	t.Run("defaults to true when unset", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.True(t, cfg.Security.CookieSecure)
+	})
+
+	t.Run("disabled by environment", func(t *testing.T) {
+		t.Setenv("SECURITY_COOKIE_SECURE", "false")
+
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.False(t, cfg.Security.CookieSecure)
+	})
+}
+
+func TestLoadConfig_Features(t *testing.T) {
+	base := `
+database:
+  host: "localhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`
+
+	t.Run("empty when unset", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.Empty(t, cfg.Features)
+	})
+
+	t.Run("set in the config file", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base+`
+features:
+  graphql: true
+  Beta_Search: false
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"graphql": true, "beta_search": false}, cfg.Features)
+	})
+
+	t.Run("set by environment", func(t *testing.T) {
+		t.Setenv("FEATURES_WEBSOCKET", "true")
+
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"websocket": true}, cfg.Features)
+	})
+}
+
+func TestValidate_Metrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  MetricsConfig
+		errorMsg string
+	}{
+		{name: "defaults", metrics: MetricsConfig{}},
+		{name: "custom", metrics: MetricsConfig{Namespace: "orders_api", DurationBuckets: []float64{0.005, 0.05, 0.5}}},
+		{name: "invalid namespace", metrics: MetricsConfig{Namespace: "orders-api"}, errorMsg: "metrics.namespace"},
+		{name: "unsorted buckets", metrics: MetricsConfig{DurationBuckets: []float64{0.05, 0.01}}, errorMsg: "metrics.duration_buckets must be strictly increasing"},
+		{name: "non-positive bucket", metrics: MetricsConfig{SizeBuckets: []float64{0, 100}}, errorMsg: "metrics.size_buckets must contain positive values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Metrics:  tt.metrics,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_GRPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		grpc     GRPCConfig
+		errorMsg string
+	}{
+		{name: "disabled", grpc: GRPCConfig{}},
+		{name: "enabled", grpc: GRPCConfig{Enabled: true, Port: "9090"}},
+		{name: "missing port", grpc: GRPCConfig{Enabled: true}, errorMsg: "grpc.port is required"},
+		{name: "same port as http", grpc: GRPCConfig{Enabled: true, Port: "8080"}, errorMsg: "grpc.port must differ from server.port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				GRPC:     tt.grpc,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_OAuthFeatureFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		oauth    OAuthProviderConfig
+		features map[string]bool
+		errorMsg string
+	}{
+		{name: "disabled", oauth: OAuthProviderConfig{}},
+		{name: "enabled without credentials", oauth: OAuthProviderConfig{Enabled: true}, errorMsg: "oauth.google requires"},
+		{name: "enabled by the flag without credentials", features: map[string]bool{"oauth": true}, errorMsg: "oauth.google requires"},
+		{name: "disabled by the flag", oauth: OAuthProviderConfig{Enabled: true}, features: map[string]bool{"oauth": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				OAuth:    OAuthConfig{Google: tt.oauth},
+				Features: tt.features,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_LoggingFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		errorMsg string
+	}{
+		{name: "default", format: ""},
+		{name: "json", format: "json"},
+		{name: "console", format: "console"},
+		{name: "unknown", format: "logfmt", errorMsg: "logging.format must be 'json' or 'console'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Logging:  LoggingConfig{Format: tt.format},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_AdminBulkMaxUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		admin    AdminConfig
+		errorMsg string
+	}{
+		{name: "default", admin: AdminConfig{}},
+		{name: "configured", admin: AdminConfig{BulkMaxUsers: 1000}},
+		{name: "negative", admin: AdminConfig{BulkMaxUsers: -1}, errorMsg: "admin.bulk_max_users must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Admin:    tt.admin,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_AdminBootstrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		admin    AdminConfig
+		errorMsg string
+	}{
+		{name: "disabled", admin: AdminConfig{}},
+		{name: "configured", admin: AdminConfig{BootstrapEnabled: true, Email: "admin@example.com", Password: "Str0ng!Passw0rd"}},
+		{name: "missing email", admin: AdminConfig{BootstrapEnabled: true, Password: "Str0ng!Passw0rd"}, errorMsg: "admin.email and admin.password are required"},
+		{name: "missing password", admin: AdminConfig{BootstrapEnabled: true, Email: "admin@example.com"}, errorMsg: "admin.email and admin.password are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Admin:    tt.admin,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_AdminEventStreamMaxConnections(t *testing.T) {
+	tests := []struct {
+		name     string
+		admin    AdminConfig
+		errorMsg string
+	}{
+		{name: "default", admin: AdminConfig{}},
+		{name: "configured", admin: AdminConfig{EventStreamMaxConnections: 50}},
+		{name: "negative", admin: AdminConfig{EventStreamMaxConnections: -1}, errorMsg: "admin.event_stream_max_connections must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Admin:    tt.admin,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_LicenseMaxUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxUsers int
+		errorMsg string
+	}{
+		{name: "unlimited", maxUsers: 0},
+		{name: "limited", maxUsers: 25},
+		{name: "negative", maxUsers: -1, errorMsg: "license.max_users must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				License:  LicenseConfig{MaxUsers: tt.maxUsers},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_Worker(t *testing.T) {
+	tests := []struct {
+		name     string
+		worker   WorkerConfig
+		errorMsg string
+	}{
+		{name: "defaults", worker: WorkerConfig{}},
+		{name: "sized", worker: WorkerConfig{Concurrency: 8, QueueSize: 500}},
+		{name: "negative concurrency", worker: WorkerConfig{Concurrency: -1}, errorMsg: "worker.concurrency must be non-negative"},
+		{name: "negative queue size", worker: WorkerConfig{QueueSize: -1}, errorMsg: "worker.queue_size must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Worker:   tt.worker,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_Outbox(t *testing.T) {
+	tests := []struct {
+		name      string
+		outbox    OutboxConfig
+		scheduler bool
+		errorMsg  string
+	}{
+		{name: "disabled", outbox: OutboxConfig{}},
+		{name: "enabled", outbox: OutboxConfig{Enabled: true, RelayInterval: time.Second, BatchSize: 50}, scheduler: true},
+		{name: "enabled without scheduler", outbox: OutboxConfig{Enabled: true}, errorMsg: "outbox.enabled requires scheduler.enabled"},
+		{name: "negative relay interval", outbox: OutboxConfig{RelayInterval: -time.Second}, errorMsg: "outbox.relay_interval must be non-negative"},
+		{name: "negative batch size", outbox: OutboxConfig{BatchSize: -1}, errorMsg: "outbox.batch_size must be non-negative"},
+		{name: "negative max attempts", outbox: OutboxConfig{MaxAttempts: -1}, errorMsg: "outbox.max_attempts must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:       AppConfig{Environment: "development"},
+				Server:    ServerConfig{Port: "8080"},
+				Database:  DatabaseConfig{Host: "localhost"},
+				JWT:       JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Scheduler: SchedulerConfig{Enabled: tt.scheduler},
+				Outbox:    tt.outbox,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	tests := []struct {
+		name     string
+		tracing  TracingConfig
+		errorMsg string
+	}{
+		{name: "disabled", tracing: TracingConfig{}},
+		{name: "enabled", tracing: TracingConfig{Enabled: true, Endpoint: "http://tempo:4318/v1/traces", SampleRatio: 0.25, ServiceName: "api"}},
+		{name: "sample ratio above one", tracing: TracingConfig{SampleRatio: 1.5}, errorMsg: "tracing.sample_ratio must be between 0 and 1"},
+		{name: "negative sample ratio", tracing: TracingConfig{SampleRatio: -0.1}, errorMsg: "tracing.sample_ratio must be between 0 and 1"},
+		{name: "enabled without endpoint", tracing: TracingConfig{Enabled: true, ServiceName: "api"}, errorMsg: "tracing.endpoint must be an http(s) URL"},
+		{name: "endpoint without scheme", tracing: TracingConfig{Enabled: true, Endpoint: "tempo:4318", ServiceName: "api"}, errorMsg: "tracing.endpoint must be an http(s) URL"},
+		{name: "enabled without service name", tracing: TracingConfig{Enabled: true, Endpoint: "https://tempo.example.com/v1/traces"}, errorMsg: "tracing.service_name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Tracing:  tt.tracing,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_TenantBaseDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseDomain string
+		errorMsg   string
+	}{
+		{name: "header only", baseDomain: ""},
+		{name: "domain", baseDomain: "example.com"},
+		{name: "scheme", baseDomain: "https://example.com", errorMsg: "tenant.base_domain must be a bare domain"},
+		{name: "leading dot", baseDomain: ".example.com", errorMsg: "tenant.base_domain must be a bare domain"},
+		{name: "port", baseDomain: "example.com:8080", errorMsg: "tenant.base_domain must be a bare domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Tenant:   TenantConfig{Enabled: true, BaseDomain: tt.baseDomain},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_PasswordHash(t *testing.T) {
+	tests := []struct {
+		name         string
+		passwordHash PasswordHashConfig
+		errorMsg     string
+	}{
+		{name: "defaults", passwordHash: PasswordHashConfig{}},
+		{name: "bcrypt", passwordHash: PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 12}},
+		{name: "argon2id", passwordHash: PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: 19456, Argon2Iterations: 2, Argon2Parallelism: 1}},
+		{name: "unknown algorithm", passwordHash: PasswordHashConfig{Algorithm: "scrypt"}, errorMsg: "security.password_hash.algorithm must be bcrypt or argon2id"},
+		{name: "bcrypt cost too low", passwordHash: PasswordHashConfig{BcryptCost: 3}, errorMsg: "security.password_hash.bcrypt_cost must be between 4 and 31"},
+		{name: "bcrypt cost too high", passwordHash: PasswordHashConfig{BcryptCost: 32}, errorMsg: "security.password_hash.bcrypt_cost must be between 4 and 31"},
+		{name: "negative memory", passwordHash: PasswordHashConfig{Argon2Memory: -1}, errorMsg: "security.password_hash.argon2_memory"},
+		{name: "negative iterations", passwordHash: PasswordHashConfig{Argon2Iterations: -1}, errorMsg: "security.password_hash.argon2_iterations must be non-negative"},
+		{name: "parallelism too high", passwordHash: PasswordHashConfig{Argon2Parallelism: 256}, errorMsg: "security.password_hash.argon2_parallelism must be between 0 and 255"},
+		{name: "concurrency limit", passwordHash: PasswordHashConfig{MaxConcurrency: 4, MaxWait: 100 * time.Millisecond}},
+		{name: "negative max concurrency", passwordHash: PasswordHashConfig{MaxConcurrency: -1}, errorMsg: "security.password_hash.max_concurrency must be non-negative"},
+		{name: "negative max wait", passwordHash: PasswordHashConfig{MaxWait: -time.Millisecond}, errorMsg: "security.password_hash.max_wait must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Security: SecurityConfig{PasswordHash: tt.passwordHash},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_WebSocket(t *testing.T) {
+	tests := []struct {
+		name      string
+		websocket WebSocketConfig
+		errorMsg  string
+	}{
+		{name: "disabled", websocket: WebSocketConfig{}},
+		{name: "enabled with default interval", websocket: WebSocketConfig{Enabled: true}},
+		{name: "enabled", websocket: WebSocketConfig{Enabled: true, PingInterval: 30 * time.Second}},
+		{name: "negative ping interval", websocket: WebSocketConfig{Enabled: true, PingInterval: -time.Second}, errorMsg: "websocket.ping_interval must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:       AppConfig{Environment: "development"},
+				Server:    ServerConfig{Port: "8080"},
+				Database:  DatabaseConfig{Host: "localhost"},
+				JWT:       JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				WebSocket: tt.websocket,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_ResetLinkTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		errorMsg string
+	}{
+		{name: "default", template: ""},
+		{name: "web", template: "https://app.example.com/reset?token={token}"},
+		{name: "deep link", template: "myapp://reset?token={token}"},
+		{name: "missing token", template: "myapp://reset", errorMsg: "security.reset_link_template must contain {token}"},
+		{name: "relative", template: "/reset?token={token}", errorMsg: "security.reset_link_template must be an absolute URL or deep link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Security: SecurityConfig{ResetLinkTemplate: tt.template},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_TokenDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery string
+		errorMsg string
+	}{
+		{name: "default", delivery: ""},
+		{name: "body", delivery: "body"},
+		{name: "cookie", delivery: "cookie"},
+		{name: "both", delivery: "both"},
+		{name: "unknown", delivery: "header", errorMsg: "security.token_delivery must be body, cookie or both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Security: SecurityConfig{TokenDelivery: tt.delivery},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_EmailLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    EmailConfig
+		errorMsg string
+	}{
+		{name: "defaults", email: EmailConfig{}},
+		{name: "configured", email: EmailConfig{PublicBaseURL: "https://api.example.com", UnsubscribeTTL: 720 * time.Hour}},
+		{name: "relative base url", email: EmailConfig{PublicBaseURL: "api.example.com"}, errorMsg: "email.public_base_url must be an absolute URL"},
+		{name: "negative unsubscribe ttl", email: EmailConfig{UnsubscribeTTL: -time.Hour}, errorMsg: "email.unsubscribe_ttl must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Server:   ServerConfig{Port: "8080"},
+				Database: DatabaseConfig{Host: "localhost"},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+				Email:    tt.email,
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_UnknownKeys(t *testing.T) {
+	base := `
+database:
+  host: "localhost"
+jwt:
+  secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+`
+	misspelled := base + `
+  ttl_hours: 24
+ratelimt:
+  enabled: true
+  window: "1m"
+oauth:
+  gogle:
+    enabled: true
+features:
+  beta_search: true
+`
+
+	t.Run("known keys only", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base+`
+ratelimit:
+  window: "1m"
+  exempt_roles: ["admin"]
+oauth:
+  google:
+    enabled: false
+features:
+  beta_search: true
+`))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.UnknownKeys)
+	})
+
+	t.Run("misspelled sections warn", func(t *testing.T) {
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", misspelled))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"jwt.ttl_hours", "oauth.gogle.enabled", "ratelimt.enabled", "ratelimt.window"}, cfg.UnknownKeys)
+	})
+
+	t.Run("strict mode fails", func(t *testing.T) {
+		_, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", misspelled+`
+config:
+  strict: true
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config.strict: unknown configuration keys: jwt.ttl_hours, oauth.gogle.enabled, ratelimt.enabled, ratelimt.window")
+	})
+
+	t.Run("unknown environment variables", func(t *testing.T) {
+		t.Setenv("JWT_TTL_HOURS", "24")
+		t.Setenv("RATELIMIT_WINDOW", "1m")
+		t.Setenv("SERVER_READ_TIMEOUT", "10s")
+		composeVars := []string{"APP_CONTAINER_NAME", "APP_CONTAINER_NAME_PROD", "DATABASE_CONTAINER_NAME", "DATABASE_CONTAINER_NAME_PROD", "HEALTH_CHECK_INTERVAL"}
+		for _, name := range composeVars {
+			t.Setenv(name, "x")
+		}
+
+		cfg, err := LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		require.NoError(t, err)
+		assert.Contains(t, cfg.UnknownEnv, "JWT_TTL_HOURS")
+		assert.Contains(t, cfg.UnknownEnv, "SERVER_READ_TIMEOUT")
+		assert.NotContains(t, cfg.UnknownEnv, "RATELIMIT_WINDOW")
+		for _, name := range composeVars {
+			assert.NotContains(t, cfg.UnknownEnv, name, "docker-compose variables are not config")
+		}
+
+		t.Setenv("CONFIG_STRICT", "true")
+		_, err = LoadConfig(createTempConfigFile(t, t.TempDir(), "config.yaml", base))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "JWT_TTL_HOURS")
+	})
+}
+
+func TestValidate_TablePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		errorMsg string
+	}{
+		{name: "none", prefix: ""},
+		{name: "tenant", prefix: "tenant_a_"},
+		{name: "uppercase", prefix: "Tenant_", errorMsg: "database.table_prefix must start with a lowercase letter or underscore"},
+		{name: "quote", prefix: `t"; DROP TABLE users; --`, errorMsg: "database.table_prefix"},
+		{name: "leading digit", prefix: "1_", errorMsg: "database.table_prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				App:      AppConfig{Environment: "development"},
+				Database: DatabaseConfig{Host: "localhost", TablePrefix: tt.prefix},
+				JWT:      JWTConfig{Secret: "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"},
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
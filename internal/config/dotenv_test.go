@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	vars, err := parseDotEnv(strings.NewReader(`
+# local secrets
+APP_ENVIRONMENT=development        # development | staging | production
+export JWT_SECRET=abc#def
+DATABASE_PASSWORD="p@ss \"word\"\n2" # quoted
+SINGLE='${NOT_EXPANDED} \n'
+EMPTY=
+SPACED = value
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"APP_ENVIRONMENT":   "development",
+		"JWT_SECRET":        "abc#def",
+		"DATABASE_PASSWORD": "p@ss \"word\"\n2",
+		"SINGLE":            `${NOT_EXPANDED} \n`,
+		"EMPTY":             "",
+		"SPACED":            "value",
+	}, vars)
+}
+
+func TestParseDotEnv_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing separator", "JWT_SECRET", "line 1: expected KEY=VALUE"},
+		{"invalid key", "\nBAD-KEY=value", "line 2: expected KEY=VALUE"},
+		{"unterminated quote", `A="open`, "line 1: unterminated \" quote"},
+		{"trailing text", `A='x' y`, `line 1: unexpected "y" after quoted value`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDotEnv(strings.NewReader(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	unsetEnv(t, "DOTENV_TEST_NEW", "DOTENV_TEST_SET")
+	t.Setenv("DOTENV_TEST_SET", "from-environment")
+	path := createTempConfigFile(t, t.TempDir(), ".env", "DOTENV_TEST_NEW=from-file\nDOTENV_TEST_SET=from-file\n")
+
+	loaded, err := loadDotEnv(path)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Equal(t, "from-file", os.Getenv("DOTENV_TEST_NEW"))
+	assert.Equal(t, "from-environment", os.Getenv("DOTENV_TEST_SET"), "the real environment wins")
+
+	loaded, err = loadDotEnv(filepath.Join(t.TempDir(), ".env"))
+	assert.NoError(t, err, "a missing file is not an error")
+	assert.False(t, loaded)
+}
+
+// unsetEnv unsets the variables for the test and restores them afterwards,
+// including ones a .env file sets through os.Setenv
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
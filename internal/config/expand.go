@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// StrictExpansionEnv names the environment variable that turns references to
+// unset variables in config files into load errors instead of leaving them as is
+const StrictExpansionEnv = "CONFIG_EXPAND_STRICT"
+
+// envReference matches the $${ escape and ${VAR} references. Bare $VAR is not
+// expanded so values such as passwords may contain a literal $.
+var envReference = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandString replaces ${VAR} references in s with the value of the
+// environment variable. References to unset variables are kept verbatim and
+// their names appended to missing. $${ produces a literal ${.
+func expandString(s string, missing *[]string) string {
+	return envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		name := ref[2 : len(ref)-1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			*missing = append(*missing, name)
+			return ref
+		}
+		return value
+	})
+}
+
+// expandTree expands the string scalars of a parsed YAML document. Keys,
+// comments and non-string values are left alone, and a substituted value stays
+// one scalar whatever characters it contains.
+func expandTree(node any, missing *[]string) any {
+	switch n := node.(type) {
+	case string:
+		return expandString(n, missing)
+	case map[string]any:
+		for key, value := range n {
+			n[key] = expandTree(value, missing)
+		}
+	case []any:
+		for i, value := range n {
+			n[i] = expandTree(value, missing)
+		}
+	}
+	return node
+}
+
+// readExpandedConfig reads (or merges) the config file viper resolves and then
+// merges it again with ${VAR} references in its string values expanded.
+// Expansion happens on the parsed file, so values bound directly from the
+// environment are never expanded.
+func readExpandedConfig(v *viper.Viper, merge bool) error {
+	read := v.ReadInConfig
+	if merge {
+		read = v.MergeInConfig
+	}
+	if err := read(); err != nil {
+		return err
+	}
+
+	raw, err := os.ReadFile(v.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return fmt.Errorf("%s: %w", v.ConfigFileUsed(), err)
+	}
+
+	var missing []string
+	expandTree(tree, &missing)
+	if v.GetBool(StrictExpansionEnv) && len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("%s: undefined environment variable(s) referenced in config: %s",
+			v.ConfigFileUsed(), strings.Join(slices.Compact(missing), ", "))
+	}
+	return v.MergeConfigMap(tree)
+}
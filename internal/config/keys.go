@@ -0,0 +1,151 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ignoredEnvPrefixes are variables that share a section prefix with this
+// config but belong to libraries or docker-compose, e.g.
+// GRPC_GO_LOG_SEVERITY_LEVEL for grpc-go or APP_CONTAINER_NAME_PROD
+var ignoredEnvPrefixes = []string{
+	"GRPC_GO_", "GRPC_TRACE", "GRPC_VERBOSITY", "GRPC_DEFAULT_",
+	"APP_CONTAINER_NAME", "DATABASE_CONTAINER_NAME", "HEALTH_CHECK_",
+}
+
+// configSchema holds the keys Config can be unmarshalled from, derived from
+// its mapstructure tags
+type configSchema struct {
+	// leaves are keys holding a value, e.g. jwt.access_token_ttl
+	leaves map[string]bool
+	// maps are keys holding a map whose own keys are free-form, e.g. features
+	maps map[string]bool
+}
+
+func newConfigSchema() configSchema {
+	s := configSchema{leaves: map[string]bool{}, maps: map[string]bool{}}
+	s.walk(reflect.TypeFor[Config](), "")
+	return s
+}
+
+// walk records the keys of the struct t under prefix, descending into nested
+// structs. Durations, slices and other values are leaves.
+func (s configSchema) walk(t reflect.Type, prefix string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft != reflect.TypeFor[time.Time]():
+			s.walk(ft, key+".")
+		case ft.Kind() == reflect.Map:
+			s.maps[key] = true
+		default:
+			s.leaves[key] = true
+		}
+	}
+}
+
+// known reports whether key, as listed by viper's AllKeys, maps to a field
+func (s configSchema) known(key string) bool {
+	if s.leaves[key] || s.maps[key] {
+		return true
+	}
+	for prefix := key; ; {
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			return false
+		}
+		prefix = prefix[:i]
+		if s.maps[prefix] {
+			return true
+		}
+	}
+}
+
+// unknownKeys lists the keys set in v that match no config field, such as
+// ratelimt.enabled or jwt.ttl_hours, which viper would otherwise ignore
+func unknownKeys(v *viper.Viper, schema configSchema) []string {
+	var unknown []string
+	for _, key := range v.AllKeys() {
+		if !schema.known(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownEnv lists the variables in environ that look like config overrides,
+// by starting with the prefix of a section such as JWT_, but that viper does
+// not read: neither bound in bindEnvVariables nor the automatic name of a key
+// the config sets.
+func unknownEnv(v *viper.Viper, schema configSchema, environ []string) []string {
+	applied := map[string]bool{StrictExpansionEnv: true}
+	for _, env := range envBindings {
+		applied[env] = true
+	}
+	for _, key := range v.AllKeys() {
+		if schema.known(key) {
+			applied[strings.ToUpper(strings.ReplaceAll(key, ".", "_"))] = true
+		}
+	}
+
+	var sections []string
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ","); name != "" && name != "-" {
+			sections = append(sections, strings.ToUpper(name)+"_")
+		}
+	}
+
+	var unknown []string
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if applied[name] || hasAnyPrefix(name, ignoredEnvPrefixes) || !hasAnyPrefix(name, sections) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogUnknownKeys warns about each config key and environment variable that
+// was ignored for matching no setting
+func (c *Config) LogUnknownKeys(logger *slog.Logger) {
+	for _, key := range c.UnknownKeys {
+		logger.Warn("Ignoring unknown configuration key", "key", key)
+	}
+	for _, name := range c.UnknownEnv {
+		logger.Warn("Ignoring environment variable that matches no configuration key", "name", name)
+	}
+}
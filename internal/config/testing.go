@@ -30,5 +30,10 @@ func NewTestConfig() *Config {
 			Timeout:              5,
 			DatabaseCheckEnabled: true,
 		},
+		Security: SecurityConfig{
+			AutoLoginOnRegister: true,
+			CookieSecure:        true,
+			PasswordHash:        PasswordHashConfig{RehashOnLogin: true},
+		},
 	}
 }
@@ -2,9 +2,27 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
 )
 
+var metricNamespacePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// tablePrefixPattern keeps prefixed table names valid unquoted identifiers
+var tablePrefixPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// baseDomainPattern matches a bare domain name without scheme, port or path
+var baseDomainPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*$`)
+
 func (c *Config) Validate() error {
+	// WHY: Checked first, since a misspelled section usually fails a later check too
+	if c.Settings.Strict && len(c.UnknownKeys)+len(c.UnknownEnv) > 0 {
+		return fmt.Errorf("config.strict: unknown configuration keys: %s", strings.Join(append(append([]string{}, c.UnknownKeys...), c.UnknownEnv...), ", "))
+	}
+
 	if c.JWT.Secret == "" {
 		return fmt.Errorf("JWT_SECRET environment variable is required - generate with: make generate-jwt-secret")
 	}
@@ -16,10 +34,49 @@ func (c *Config) Validate() error {
 		)
 	}
 
+	if c.JWT.RefreshReuseGrace < 0 {
+		return fmt.Errorf("jwt.refresh_reuse_grace must be non-negative")
+	}
+
+	if c.JWT.RefreshTokenTTL > 0 && c.JWT.RefreshReuseGrace >= c.JWT.RefreshTokenTTL {
+		return fmt.Errorf("jwt.refresh_reuse_grace must be shorter than jwt.refresh_token_ttl")
+	}
+
+	if c.JWT.UserStatusCacheTTL < 0 {
+		return fmt.Errorf("jwt.user_status_cache_ttl must be non-negative")
+	}
+
+	if c.JWT.Leeway < 0 {
+		return fmt.Errorf("jwt.leeway must be non-negative")
+	}
+
+	if c.JWT.NotBeforeOffset < 0 {
+		return fmt.Errorf("jwt.not_before_offset must be non-negative")
+	}
+
+	// WHY: bcrypt ignores everything past 72 bytes, so a longer minimum could never be enforced
+	if c.Password.MinLength < 0 || c.Password.MinLength > 72 {
+		return fmt.Errorf("password.min_length must be between 0 and 72")
+	}
+
+	if c.Password.BreachCheckTimeout < 0 {
+		return fmt.Errorf("password.breach_check_timeout must be non-negative")
+	}
+
+	if c.Password.BreachCheck && c.Password.BreachCheckURL != "" {
+		if u, err := url.Parse(c.Password.BreachCheckURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("password.breach_check_url must be an absolute URL")
+		}
+	}
+
 	if c.Database.Host == "" {
 		return fmt.Errorf("database.host is required")
 	}
 
+	if c.Database.TablePrefix != "" && !tablePrefixPattern.MatchString(c.Database.TablePrefix) {
+		return fmt.Errorf("database.table_prefix must start with a lowercase letter or underscore and contain only lowercase letters, digits and underscores")
+	}
+
 	if c.Server.ReadTimeout < 0 {
 		return fmt.Errorf("server.readtimeout must be non-negative")
 	}
@@ -40,15 +97,354 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server.maxheaderbytes must be non-negative")
 	}
 
+	if c.Server.MaxJSONBytes < 0 {
+		return fmt.Errorf("server.max_json_bytes must be non-negative")
+	}
+
+	if c.Server.MaxJSONDepth < 0 {
+		return fmt.Errorf("server.max_json_depth must be non-negative")
+	}
+
+	if c.Server.StaticCacheMaxAge < 0 {
+		return fmt.Errorf("server.static_cache_max_age must be non-negative")
+	}
+
+	if c.Server.MaxInflight < 0 {
+		return fmt.Errorf("server.max_inflight must be non-negative")
+	}
+
+	if c.Server.MaxInflightPerUser < 0 {
+		return fmt.Errorf("server.max_inflight_per_user must be non-negative")
+	}
+
+	// WHY: A line break would let the configured value inject extra response headers
+	if strings.ContainsAny(c.Server.ServerHeader, "\r\n") {
+		return fmt.Errorf("server.server_header must not contain line breaks")
+	}
+
+	switch c.Logging.Format {
+	case "", "json", "console":
+	default:
+		return fmt.Errorf("logging.format must be 'json' or 'console'")
+	}
+
+	switch c.Server.ResponseFormat {
+	case "", "standard", "envelope":
+	default:
+		return fmt.Errorf("server.response_format must be 'standard' or 'envelope'")
+	}
+
+	if c.Ratelimit.UserRequests < 0 || c.Ratelimit.UserWindow < 0 {
+		return fmt.Errorf("ratelimit.user_requests and ratelimit.user_window must be non-negative")
+	}
+
+	if c.Ratelimit.EmailCheckRequests < 0 || c.Ratelimit.EmailCheckWindow < 0 {
+		return fmt.Errorf("ratelimit.email_check_requests and ratelimit.email_check_window must be non-negative")
+	}
+
+	if c.Ratelimit.CacheSize < 0 || c.Ratelimit.EntryTTL < 0 {
+		return fmt.Errorf("ratelimit.cache_size and ratelimit.entry_ttl must be non-negative")
+	}
+
+	switch c.Ratelimit.OverflowPolicy {
+	case "", "evict", "shared":
+	default:
+		return fmt.Errorf("ratelimit.overflow_policy must be 'evict' or 'shared'")
+	}
+
+	if c.Ratelimit.OverflowRequests < 0 {
+		return fmt.Errorf("ratelimit.overflow_requests must be non-negative")
+	}
+
+	if c.Ratelimit.WarningThreshold < 0 || c.Ratelimit.WarningThreshold > 1 {
+		return fmt.Errorf("ratelimit.warning_threshold must be between 0 and 1")
+	}
+
+	for _, ip := range c.Ratelimit.ExemptIPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return fmt.Errorf("ratelimit.exempt_ips contains invalid IP or CIDR %q", ip)
+			}
+		}
+	}
+
+	if c.Health.Timeout < 0 {
+		return fmt.Errorf("health.timeout must be non-negative")
+	}
+
+	for name, timeout := range c.Health.CheckerTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("health.checker_timeouts.%s must be positive", name)
+		}
+	}
+
+	if c.Health.StreamInterval < 0 {
+		return fmt.Errorf("health.stream_interval must be non-negative")
+	}
+
+	if c.Maintenance.RetryAfter < 0 {
+		return fmt.Errorf("maintenance.retry_after must be non-negative")
+	}
+
+	if c.Scheduler.TokenCleanupInterval < 0 {
+		return fmt.Errorf("scheduler.token_cleanup_interval must be non-negative")
+	}
+
+	if c.Worker.Concurrency < 0 {
+		return fmt.Errorf("worker.concurrency must be non-negative")
+	}
+
+	if c.Worker.QueueSize < 0 {
+		return fmt.Errorf("worker.queue_size must be non-negative")
+	}
+
+	if c.Outbox.RelayInterval < 0 {
+		return fmt.Errorf("outbox.relay_interval must be non-negative")
+	}
+
+	if c.Outbox.BatchSize < 0 {
+		return fmt.Errorf("outbox.batch_size must be non-negative")
+	}
+
+	if c.Outbox.MaxAttempts < 0 {
+		return fmt.Errorf("outbox.max_attempts must be non-negative")
+	}
+
+	if c.Outbox.Enabled && !c.Scheduler.Enabled {
+		return fmt.Errorf("outbox.enabled requires scheduler.enabled, which runs the outbox relay")
+	}
+
+	if c.Email.SendTimeout < 0 {
+		return fmt.Errorf("email.send_timeout must be non-negative")
+	}
+
+	if c.Email.ChangeTokenTTL < 0 {
+		return fmt.Errorf("email.change_token_ttl must be non-negative")
+	}
+
+	if c.Email.UnsubscribeTTL < 0 {
+		return fmt.Errorf("email.unsubscribe_ttl must be non-negative")
+	}
+
+	if c.Email.PublicBaseURL != "" {
+		if u, err := url.Parse(c.Email.PublicBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("email.public_base_url must be an absolute URL, e.g. https://api.example.com")
+		}
+	}
+
+	if c.Security.ResetLinkTemplate != "" {
+		if !strings.Contains(c.Security.ResetLinkTemplate, "{token}") {
+			return fmt.Errorf("security.reset_link_template must contain {token}")
+		}
+		if u, err := url.Parse(strings.ReplaceAll(c.Security.ResetLinkTemplate, "{token}", "token")); err != nil || u.Scheme == "" {
+			return fmt.Errorf("security.reset_link_template must be an absolute URL or deep link, e.g. myapp://reset?token={token}")
+		}
+	}
+
+	switch c.Security.TokenDelivery {
+	case "", "body", "cookie", "both":
+	default:
+		return fmt.Errorf("security.token_delivery must be body, cookie or both")
+	}
+
+	if err := c.Security.PasswordHash.validate(); err != nil {
+		return err
+	}
+
+	oauthEnabled := c.OAuth.Google.Enabled
+	if enabled, ok := c.Features["oauth"]; ok {
+		// WHY: The oauth feature flag overrides oauth.google.enabled
+		oauthEnabled = enabled
+	}
+	if oauthEnabled {
+		if c.OAuth.Google.ClientID == "" || c.OAuth.Google.ClientSecret == "" || c.OAuth.Google.RedirectURL == "" {
+			return fmt.Errorf("oauth.google requires client_id, client_secret and redirect_url when enabled")
+		}
+	}
+
+	if c.GRPC.Enabled {
+		if c.GRPC.Port == "" {
+			return fmt.Errorf("grpc.port is required when grpc is enabled")
+		}
+		if c.GRPC.Port == c.Server.Port {
+			return fmt.Errorf("grpc.port must differ from server.port")
+		}
+	}
+
+	if c.WebSocket.PingInterval < 0 {
+		return fmt.Errorf("websocket.ping_interval must be non-negative")
+	}
+
+	if c.Admin.BulkMaxUsers < 0 {
+		return fmt.Errorf("admin.bulk_max_users must be non-negative")
+	}
+
+	if c.Admin.EventStreamMaxConnections < 0 {
+		return fmt.Errorf("admin.event_stream_max_connections must be non-negative")
+	}
+
+	if c.Admin.BootstrapEnabled && (c.Admin.Email == "" || c.Admin.Password == "") {
+		return fmt.Errorf("admin.email and admin.password are required when admin.bootstrap_enabled is true")
+	}
+
+	if c.License.MaxUsers < 0 {
+		return fmt.Errorf("license.max_users must be non-negative")
+	}
+
+	if c.Tenant.BaseDomain != "" && !baseDomainPattern.MatchString(c.Tenant.BaseDomain) {
+		return fmt.Errorf("tenant.base_domain must be a bare domain such as example.com")
+	}
+
+	if c.Metrics.Namespace != "" && !metricNamespacePattern.MatchString(c.Metrics.Namespace) {
+		return fmt.Errorf("metrics.namespace must match %s", metricNamespacePattern)
+	}
+
+	if err := validateBuckets("metrics.duration_buckets", c.Metrics.DurationBuckets); err != nil {
+		return err
+	}
+
+	if err := validateBuckets("metrics.size_buckets", c.Metrics.SizeBuckets); err != nil {
+		return err
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint must be an http(s) URL when tracing is enabled, e.g. http://tempo:4318/v1/traces")
+		}
+		if c.Tracing.ServiceName == "" {
+			return fmt.Errorf("tracing.service_name is required when tracing is enabled")
+		}
+	}
+
+	if c.CORS.MaxAge < 0 {
+		return fmt.Errorf("cors.max_age must be non-negative")
+	}
+
+	if c.CORS.AllowCredentials && c.CORS.AllowsAllOrigins() {
+		return fmt.Errorf("cors.allow_credentials requires explicit cors.allow_origins (wildcard origins cannot send credentials)")
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("server.trusted_proxies contains invalid IP or CIDR %q", proxy)
+			}
+		}
+	}
+
 	if c.App.Environment == "production" {
-		if c.Database.Password == "" {
-			return fmt.Errorf("database.password is required in production")
+		var failures []string
+		for _, check := range c.ProductionChecks() {
+			if !check.Passed {
+				failures = append(failures, check.Message)
+			}
 		}
+		if len(failures) > 0 {
+			return fmt.Errorf("insecure production configuration: %s", strings.Join(failures, "; "))
+		}
+	}
 
-		if c.Database.SSLMode == "disable" {
-			return fmt.Errorf("database SSL mode cannot be 'disable' in production")
+	return nil
+}
+
+// InsecureDefaultJWTSecret is the placeholder secret older releases fell back to
+const InsecureDefaultJWTSecret = "default-secret-change-in-production"
+
+// ProductionCheck is the outcome of a single production hardening rule
+type ProductionCheck struct {
+	Name    string
+	Passed  bool
+	Message string
+}
+
+// ProductionChecks evaluates the production hardening rules.
+// Validate enforces them only when app.environment is production.
+func (c *Config) ProductionChecks() []ProductionCheck {
+	return []ProductionCheck{
+		{
+			Name:    "app.debug",
+			Passed:  !c.App.Debug,
+			Message: "app.debug must be false in production",
+		},
+		{
+			Name:    "jwt.secret",
+			Passed:  c.JWT.Secret != InsecureDefaultJWTSecret,
+			Message: "jwt.secret must not be the default placeholder in production",
+		},
+		{
+			Name:    "database.password",
+			Passed:  c.Database.Password != "",
+			Message: "database.password is required in production",
+		},
+		{
+			Name:    "database.sslmode",
+			Passed:  c.Database.SSLMode != "disable",
+			Message: "database SSL mode cannot be 'disable' in production",
+		},
+		{
+			Name:    "ratelimit.enabled",
+			Passed:  c.Ratelimit.Enabled,
+			Message: "ratelimit.enabled must be true in production",
+		},
+		{
+			Name:    "server.swagger_enabled",
+			Passed:  !c.Server.SwaggerEnabled,
+			Message: "server.swagger_enabled must be false in production",
+		},
+		{
+			Name:    "cors.allow_origins",
+			Passed:  !c.CORS.AllowsAllOrigins(),
+			Message: "cors.allow_origins must list explicit origins in production",
+		},
+		{
+			Name:    "server.trusted_proxies",
+			Passed:  !c.Server.BehindProxy || len(c.Server.TrustedProxies) > 0,
+			Message: "server.trusted_proxies is required in production when server.behind_proxy is set",
+		},
+	}
+}
+
+// validateBuckets checks that histogram buckets are positive and strictly increasing
+func validateBuckets(key string, buckets []float64) error {
+	for i, b := range buckets {
+		if b <= 0 {
+			return fmt.Errorf("%s must contain positive values", key)
+		}
+		if i > 0 && b <= buckets[i-1] {
+			return fmt.Errorf("%s must be strictly increasing", key)
 		}
 	}
+	return nil
+}
 
+func (p PasswordHashConfig) validate() error {
+	switch p.Algorithm {
+	case "", "bcrypt", "argon2id":
+	default:
+		return fmt.Errorf("security.password_hash.algorithm must be bcrypt or argon2id")
+	}
+	// WHY: The bounds bcrypt.GenerateFromPassword accepts
+	if p.BcryptCost != 0 && (p.BcryptCost < 4 || p.BcryptCost > 31) {
+		return fmt.Errorf("security.password_hash.bcrypt_cost must be between 4 and 31")
+	}
+	if p.Argon2Memory < 0 || p.Argon2Memory > math.MaxUint32 {
+		return fmt.Errorf("security.password_hash.argon2_memory must be between 0 and %d KiB", uint32(math.MaxUint32))
+	}
+	if p.Argon2Iterations < 0 || p.Argon2Iterations > math.MaxUint32 {
+		return fmt.Errorf("security.password_hash.argon2_iterations must be non-negative")
+	}
+	if p.Argon2Parallelism < 0 || p.Argon2Parallelism > math.MaxUint8 {
+		return fmt.Errorf("security.password_hash.argon2_parallelism must be between 0 and 255")
+	}
+	if p.MaxConcurrency < 0 {
+		return fmt.Errorf("security.password_hash.max_concurrency must be non-negative")
+	}
+	if p.MaxWait < 0 {
+		return fmt.Errorf("security.password_hash.max_wait must be non-negative")
+	}
 	return nil
 }
@@ -47,6 +47,8 @@ type Config struct {
 	Password string
 	Name     string
 	SSLMode  string
+	// TablePrefix prefixes every table name; see WithTablePrefix
+	TablePrefix string
 }
 
 // NewPostgresDB creates a new PostgreSQL database connection
@@ -54,12 +56,15 @@ func NewPostgresDB(cfg Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
 		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	gormCfg := &gorm.Config{
 		Logger: customLogger{logger.Default.LogMode(logger.Info)},
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
-	})
+	}
+	WithTablePrefix(cfg.TablePrefix)(gormCfg)
+
+	db, err := gorm.Open(postgres.Open(dsn), gormCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -82,9 +87,12 @@ func NewPostgresDBFromDatabaseConfig(cfg config.DatabaseConfig) (*gorm.DB, error
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
 		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	gormCfg := &gorm.Config{
 		Logger: customLogger{logger.Default.LogMode(logger.Info)},
-	})
+	}
+	WithTablePrefix(cfg.TablePrefix)(gormCfg)
+
+	db, err := gorm.Open(postgres.Open(dsn), gormCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to postgres database: %w", err)
 	}
@@ -102,10 +110,19 @@ func NewPostgresDBFromDatabaseConfig(cfg config.DatabaseConfig) (*gorm.DB, error
 }
 
 // NewSQLiteDB creates a new SQLite database connection (for testing)
-func NewSQLiteDB(dbPath string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+func NewSQLiteDB(dbPath string, opts ...Option) (*gorm.DB, error) {
+	gormCfg := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
-	})
+		// WHY: Same as Postgres; SQLite compares DATETIME text, so mixed offsets sort wrongly
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+	for _, opt := range opts {
+		opt(gormCfg)
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), gormCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to sqlite database: %w", err)
 	}
@@ -116,11 +133,12 @@ func NewSQLiteDB(dbPath string) (*gorm.DB, error) {
 // LoadConfigFromEnv loads database configuration using Viper (env overrides + defaults)
 func LoadConfigFromEnv() Config {
 	return Config{
-		Host:     viper.GetString("database.host"),
-		Port:     viper.GetInt("database.port"),
-		User:     viper.GetString("database.user"),
-		Password: viper.GetString("database.password"),
-		Name:     viper.GetString("database.name"),
-		SSLMode:  viper.GetString("database.sslmode"),
+		Host:        viper.GetString("database.host"),
+		Port:        viper.GetInt("database.port"),
+		User:        viper.GetString("database.user"),
+		Password:    viper.GetString("database.password"),
+		Name:        viper.GetString("database.name"),
+		SSLMode:     viper.GetString("database.sslmode"),
+		TablePrefix: viper.GetString("database.table_prefix"),
 	}
 }
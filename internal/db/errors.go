@@ -0,0 +1,26 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgUniqueViolation is the Postgres SQLSTATE for a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err was caused by a unique constraint
+// violation in Postgres or SQLite, or was translated to gorm.ErrDuplicatedKey
+func IsUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
+
+	return isSQLiteUniqueViolation(err)
+}
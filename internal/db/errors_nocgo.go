@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package db
+
+// WHY: The SQLite driver's error types only exist with cgo; production builds
+// run CGO_ENABLED=0 against Postgres and never see SQLite errors
+func isSQLiteUniqueViolation(error) bool {
+	return false
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Run("postgres unique violation", func(t *testing.T) {
+		err := fmt.Errorf("insert failed: %w", &pgconn.PgError{Code: "23505"})
+		assert.True(t, IsUniqueViolation(err))
+	})
+
+	t.Run("translated duplicate key", func(t *testing.T) {
+		assert.True(t, IsUniqueViolation(fmt.Errorf("create: %w", gorm.ErrDuplicatedKey)))
+	})
+
+	t.Run("other postgres error", func(t *testing.T) {
+		assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: "23503"}))
+	})
+
+	t.Run("sqlite unique violation", func(t *testing.T) {
+		database, err := NewSQLiteDB(":memory:")
+		require.NoError(t, err)
+		require.NoError(t, database.Exec("CREATE TABLE items (name TEXT UNIQUE)").Error)
+		require.NoError(t, database.Exec("INSERT INTO items (name) VALUES ('a')").Error)
+
+		err = database.Exec("INSERT INTO items (name) VALUES ('a')").Error
+		require.Error(t, err)
+		assert.True(t, IsUniqueViolation(err))
+	})
+
+	t.Run("unrelated errors", func(t *testing.T) {
+		assert.False(t, IsUniqueViolation(nil))
+		assert.False(t, IsUniqueViolation(errors.New("connection refused")))
+	})
+}
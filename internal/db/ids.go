@@ -0,0 +1,31 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MaxID is the largest row ID the schema can hold. Primary keys are SERIAL and
+// the columns referencing them INTEGER, both signed 32-bit, so a larger ID can
+// match no row and Postgres rejects it with "integer out of range".
+const MaxID = math.MaxInt32
+
+// ErrIDOutOfRange is returned by ParseID for a number above MaxID
+var ErrIDOutOfRange = fmt.Errorf("id must be at most %d", MaxID)
+
+// ParseID parses a decimal row ID from a path, query or argument. A number
+// above MaxID fails with ErrIDOutOfRange, so callers can answer with a clear
+// client error instead of letting the query fail; other input fails with a
+// *strconv.NumError.
+func ParseID(s string) (uint, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if errors.Is(err, strconv.ErrRange) || (err == nil && id > MaxID) {
+		return 0, ErrIDOutOfRange
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    uint
+		wantErr error
+	}{
+		{name: "id", input: "42", want: 42},
+		{name: "largest id", input: "2147483647", want: MaxID},
+		{name: "above int32", input: "2147483648", wantErr: ErrIDOutOfRange},
+		{name: "above uint32", input: "4294967296", wantErr: ErrIDOutOfRange},
+		{name: "above uint64", input: "18446744073709551616", wantErr: ErrIDOutOfRange},
+		{name: "negative", input: "-1", wantErr: strconv.ErrSyntax},
+		{name: "not a number", input: "abc", wantErr: strconv.ErrSyntax},
+		{name: "empty", input: "", wantErr: strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseID(tt.input)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
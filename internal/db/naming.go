@@ -0,0 +1,41 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Option configures the GORM connection opened by the DB factories
+type Option func(*gorm.Config)
+
+// WithTablePrefix prefixes every table name, e.g. "tenant_" turns users into
+// tenant_users, for deployments sharing one database
+func WithTablePrefix(prefix string) Option {
+	return func(c *gorm.Config) {
+		c.NamingStrategy = schema.NamingStrategy{TablePrefix: prefix}
+	}
+}
+
+// TablePrefix returns the table prefix tx was opened with
+func TablePrefix(tx *gorm.DB) string {
+	if ns, ok := tx.NamingStrategy.(schema.NamingStrategy); ok {
+		return ns.TablePrefix
+	}
+	return ""
+}
+
+// Table returns the name of table with the prefix of tx, for raw SQL and
+// queries that name a table rather than a model
+func Table(tx *gorm.DB, table string) string {
+	return TablePrefix(tx) + table
+}
+
+// AliasTable returns table with the prefix of tx, aliased back to its plain
+// name so column references such as users.id keep working
+func AliasTable(tx *gorm.DB, table string) string {
+	prefix := TablePrefix(tx)
+	if prefix == "" {
+		return table
+	}
+	return prefix + table + " AS " + table
+}
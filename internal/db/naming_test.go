@@ -0,0 +1,25 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTableNames(t *testing.T) {
+	plain, err := NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	prefixed, err := NewSQLiteDB(":memory:", WithTablePrefix("tenant_"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "", TablePrefix(plain))
+	assert.Equal(t, "users", Table(plain, "users"))
+	assert.Equal(t, "users", AliasTable(plain, "users"))
+
+	assert.Equal(t, "tenant_", TablePrefix(prefixed))
+	assert.Equal(t, "tenant_users", Table(prefixed, "users"))
+	assert.Equal(t, "tenant_users AS users", AliasTable(prefixed, "users"))
+	assert.Equal(t, "tenant_", TablePrefix(prefixed.Session(&gorm.Session{})), "sessions keep the prefix")
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// WithTx returns a copy of ctx carrying tx, so every repository that looks its
+// connection up with Conn joins the transaction
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// WithoutTx returns a copy of ctx that no longer carries a transaction, for
+// work that outlives it, e.g. background jobs started by a request
+func WithoutTx(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, commitHooksKey{}, (*commitHooks)(nil))
+	return context.WithValue(ctx, txKey{}, (*gorm.DB)(nil))
+}
+
+// Conn returns the transaction carried by ctx, or fallback outside of one
+func Conn(ctx context.Context, fallback *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok && tx != nil {
+		return tx
+	}
+	return fallback
+}
+
+type commitHooksKey struct{}
+
+// commitHooks collects the functions AfterCommit defers until a commit
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithCommitHooks returns a copy of ctx in which AfterCommit queues its
+// functions instead of running them, and run, which calls them in order. The
+// owner of the transaction calls run after committing and drops it on rollback.
+func WithCommitHooks(ctx context.Context) (context.Context, func()) {
+	hooks := &commitHooks{}
+	run := func() {
+		hooks.mu.Lock()
+		fns := hooks.fns
+		hooks.fns = nil
+		hooks.mu.Unlock()
+		for _, fn := range fns {
+			fn()
+		}
+	}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), run
+}
+
+// AfterCommit runs fn once the transaction of ctx commits, for side effects
+// such as events that must not announce rolled back writes. Outside of one,
+// and in contexts from WithoutTx, fn runs at once.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok || hooks == nil {
+		fn()
+		return
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+// bestEffortSavepoint is the savepoint BestEffort wraps its write in; reusing
+// the name is fine, as a rollback targets the most recent savepoint with it
+const bestEffortSavepoint = "best_effort"
+
+// BestEffort runs fn inside a savepoint of the transaction carried by ctx and
+// rolls back to it when fn fails, so a write whose failure is only logged does
+// not take the surrounding transaction down with it: Postgres rejects every
+// later statement, and the commit, of a transaction with a failed statement.
+// Outside a transaction fn simply runs.
+func BestEffort(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok || tx == nil {
+		return fn(ctx)
+	}
+
+	if err := tx.SavePoint(bestEffortSavepoint).Error; err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if rbErr := tx.RollbackTo(bestEffortSavepoint).Error; rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestConn(t *testing.T) {
+	database, err := NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+
+	assert.Same(t, database, Conn(context.Background(), database))
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		ctx := WithTx(context.Background(), tx)
+		assert.Same(t, tx, Conn(ctx, database))
+		assert.Same(t, database, Conn(WithoutTx(ctx), database))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestAfterCommit(t *testing.T) {
+	var ran []string
+	AfterCommit(context.Background(), func() { ran = append(ran, "immediate") })
+	assert.Equal(t, []string{"immediate"}, ran, "outside a transaction hooks run at once")
+
+	ctx, run := WithCommitHooks(context.Background())
+	AfterCommit(ctx, func() { ran = append(ran, "first") })
+	AfterCommit(ctx, func() { ran = append(ran, "second") })
+	AfterCommit(WithoutTx(ctx), func() { ran = append(ran, "detached") })
+	assert.Equal(t, []string{"immediate", "detached"}, ran)
+
+	run()
+	run()
+	assert.Equal(t, []string{"immediate", "detached", "first", "second"}, ran, "hooks run once, in order")
+}
+
+func TestBestEffort(t *testing.T) {
+	database, err := NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, database.Exec("CREATE TABLE notes (body TEXT NOT NULL)").Error)
+
+	insert := func(body string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if err := Conn(ctx, database).Exec("INSERT INTO notes (body) VALUES (?)", body).Error; err != nil {
+				return err
+			}
+			if body == "doomed" {
+				return errors.New("failed after writing")
+			}
+			return nil
+		}
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		ctx := WithTx(context.Background(), tx)
+		require.NoError(t, BestEffort(ctx, insert("kept")))
+		assert.Error(t, BestEffort(ctx, insert("doomed")))
+		assert.Error(t, BestEffort(ctx, func(ctx context.Context) error {
+			return Conn(ctx, database).Exec("INSERT INTO missing_table VALUES (1)").Error
+		}))
+		return Conn(ctx, database).Exec("INSERT INTO notes (body) VALUES (?)", "after").Error
+	})
+	require.NoError(t, err, "the transaction commits after failed best-effort writes")
+
+	var bodies []string
+	require.NoError(t, database.Raw("SELECT body FROM notes ORDER BY rowid").Scan(&bodies).Error)
+	assert.Equal(t, []string{"kept", "after"}, bodies, "the failed write is rolled back to its savepoint")
+}
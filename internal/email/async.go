@@ -0,0 +1,82 @@
+package email
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/worker"
+)
+
+// Submitter runs jobs in the background; *worker.Pool is one
+type Submitter interface {
+	Submit(job worker.Job) error
+}
+
+// asyncEmailService hands sends of the wrapped service to a worker pool.
+type asyncEmailService struct {
+	next   EmailService
+	pool   Submitter
+	logger *slog.Logger
+}
+
+// WithWorkerPool wraps next so that password reset and email change messages
+// are sent on pool and the caller returns without waiting for the mail server.
+// Failed background sends are logged. When the pool refuses a job, e.g. during
+// shutdown, the message is sent inline instead of being lost.
+func WithWorkerPool(next EmailService, pool Submitter, logger *slog.Logger) EmailService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &asyncEmailService{next: next, pool: pool, logger: logger}
+}
+
+// SendPasswordResetEmail sends the reset link in the background.
+func (s *asyncEmailService) SendPasswordResetEmail(ctx context.Context, to, token string) error {
+	return s.send(ctx, "password_reset", func(ctx context.Context) error {
+		return s.next.SendPasswordResetEmail(ctx, to, token)
+	})
+}
+
+// SendEmailChangeVerification sends the change token in the background.
+func (s *asyncEmailService) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	return s.send(ctx, "email_change_verification", func(ctx context.Context) error {
+		return s.next.SendEmailChangeVerification(ctx, to, token)
+	})
+}
+
+// SendEmailChangeNotice sends the notice in the background.
+func (s *asyncEmailService) SendEmailChangeNotice(ctx context.Context, to, newEmail string) error {
+	return s.send(ctx, "email_change_notice", func(ctx context.Context) error {
+		return s.next.SendEmailChangeNotice(ctx, to, newEmail)
+	})
+}
+
+// SendCategorized delegates inline.
+func (s *asyncEmailService) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	// WHY: Callers tell an opted-out recipient apart by ErrOptedOut, which a background send cannot return
+	return s.next.SendCategorized(ctx, userID, category, message)
+}
+
+func (s *asyncEmailService) send(ctx context.Context, kind string, fn func(context.Context) error) error {
+	// WHY: The request context is cancelled once the response is written; the
+	// job keeps its values, e.g. the request ID, and stops with the pool instead.
+	// The request's transaction is settled by then, so the job must not join it.
+	detached := db.WithoutTx(context.WithoutCancel(ctx))
+
+	err := s.pool.Submit(func(poolCtx context.Context) {
+		jobCtx, cancel := context.WithCancel(detached)
+		defer cancel()
+		stop := context.AfterFunc(poolCtx, cancel)
+		defer stop()
+
+		if err := fn(jobCtx); err != nil {
+			s.logger.ErrorContext(jobCtx, "Failed to send email", "kind", kind, "error", err)
+		}
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Sending email inline", "kind", kind, "reason", err)
+		return fn(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package email
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/worker"
+)
+
+// sentWith is the state of the context an email was sent with
+type sentWith struct {
+	err       error
+	requestID string
+}
+
+// contextMailer reports the context each email change verification was sent with.
+type contextMailer struct {
+	*ConsoleEmailService
+	sent chan sentWith
+}
+
+func (m *contextMailer) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	m.sent <- sentWith{err: ctx.Err(), requestID: requestid.FromContext(ctx)}
+	return nil
+}
+
+// refusingPool refuses every job, like a pool that was stopped.
+type refusingPool struct{}
+
+func (refusingPool) Submit(worker.Job) error { return worker.ErrStopped }
+
+func TestWithWorkerPool(t *testing.T) {
+	t.Run("does not wait for the mail server", func(t *testing.T) {
+		pool := worker.New(worker.Config{Workers: 1, Registerer: prometheus.NewRegistry()})
+		svc := WithWorkerPool(&slowMailer{delay: 200 * time.Millisecond}, pool, nil)
+
+		start := time.Now()
+		err := svc.SendPasswordResetEmail(context.Background(), "user@example.com", "reset-token")
+
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		require.NoError(t, pool.Stop(context.Background()))
+	})
+
+	t.Run("outlives the request context and keeps its values", func(t *testing.T) {
+		pool := worker.New(worker.Config{Workers: 1, Registerer: prometheus.NewRegistry()})
+		mailer := &contextMailer{ConsoleEmailService: NewConsoleEmailService("", nil, nil), sent: make(chan sentWith, 1)}
+		svc := WithWorkerPool(mailer, pool, nil)
+
+		ctx, cancel := context.WithCancel(requestid.NewContext(context.Background(), "req-1"))
+		require.NoError(t, svc.SendEmailChangeVerification(ctx, "new@example.com", "token"))
+		cancel()
+
+		sent := <-mailer.sent
+		assert.NoError(t, sent.err)
+		assert.Equal(t, "req-1", sent.requestID)
+		require.NoError(t, pool.Stop(context.Background()))
+	})
+
+	t.Run("sends inline when the pool refuses", func(t *testing.T) {
+		mailer := &contextMailer{ConsoleEmailService: NewConsoleEmailService("", nil, nil), sent: make(chan sentWith, 1)}
+		svc := WithWorkerPool(mailer, refusingPool{}, nil)
+
+		require.NoError(t, svc.SendEmailChangeVerification(context.Background(), "new@example.com", "token"))
+
+		select {
+		case <-mailer.sent:
+		default:
+			t.Fatal("the email was not sent")
+		}
+	})
+
+	t.Run("categorized messages stay synchronous", func(t *testing.T) {
+		svc := WithWorkerPool(WithPreferences(newRecordingMailer(), staticPreferences{}, nil), refusingPool{}, nil)
+
+		err := svc.SendCategorized(context.Background(), 1, CategoryMarketing, Message{To: "user@example.com"})
+
+		assert.ErrorIs(t, err, ErrOptedOut)
+	})
+}
@@ -0,0 +1,144 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrSendTimeout is returned when a send does not complete within the configured deadline.
+var ErrSendTimeout = errors.New("email send timed out")
+
+// EmailService sends transactional emails.
+type EmailService interface {
+	// SendPasswordResetEmail sends the link that resets a password with token.
+	SendPasswordResetEmail(ctx context.Context, to, token string) error
+	// SendEmailChangeVerification sends the token confirming a change to the new address.
+	SendEmailChangeVerification(ctx context.Context, to, token string) error
+	// SendEmailChangeNotice tells the current address that a change to newEmail was requested.
+	SendEmailChangeNotice(ctx context.Context, to, newEmail string) error
+	// SendCategorized sends a message to userID that the user can opt out of by category.
+	SendCategorized(ctx context.Context, userID uint, category Category, message Message) error
+}
+
+// ConsoleEmailService writes emails to the logger instead of delivering them. Intended for development.
+type ConsoleEmailService struct {
+	from       string
+	resetLinks *ResetLinks
+	logger     *slog.Logger
+}
+
+// NewConsoleEmailService creates an EmailService that logs outgoing messages.
+// Password reset links come from resetLinks; nil links to the web form on a
+// relative path.
+func NewConsoleEmailService(from string, resetLinks *ResetLinks, logger *slog.Logger) *ConsoleEmailService {
+	if resetLinks == nil {
+		resetLinks = NewResetLinks("", "")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ConsoleEmailService{from: from, resetLinks: resetLinks, logger: logger}
+}
+
+// SendPasswordResetEmail logs the password reset link for the recipient.
+func (s *ConsoleEmailService) SendPasswordResetEmail(ctx context.Context, to, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "password reset email", "from", s.from, "to", to, "reset_url", s.resetLinks.URL(token))
+	return nil
+}
+
+// SendEmailChangeVerification logs the email change token for the new address.
+func (s *ConsoleEmailService) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "email change verification", "from", s.from, "to", to, "token", token)
+	return nil
+}
+
+// SendEmailChangeNotice logs the notice sent to the current address.
+func (s *ConsoleEmailService) SendEmailChangeNotice(ctx context.Context, to, newEmail string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "email change notice", "from", s.from, "to", to, "new_email", newEmail)
+	return nil
+}
+
+// SendCategorized logs a categorized message and its unsubscribe link.
+func (s *ConsoleEmailService) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "categorized email", "from", s.from, "to", message.To, "user_id", userID,
+		"category", category, "subject", message.Subject, "unsubscribe_url", message.UnsubscribeURL)
+	return nil
+}
+
+// timeoutEmailService bounds every send of the wrapped service by a fixed deadline.
+type timeoutEmailService struct {
+	next    EmailService
+	timeout time.Duration
+}
+
+// WithTimeout wraps next so that each send returns ErrSendTimeout once timeout elapses,
+// even if the underlying mailer ignores context cancellation. A non-positive timeout disables the wrapper.
+func WithTimeout(next EmailService, timeout time.Duration) EmailService {
+	if timeout <= 0 {
+		return next
+	}
+	return &timeoutEmailService{next: next, timeout: timeout}
+}
+
+// SendPasswordResetEmail delegates to the wrapped service under the configured deadline.
+func (s *timeoutEmailService) SendPasswordResetEmail(ctx context.Context, to, token string) error {
+	return s.send(ctx, func(ctx context.Context) error {
+		return s.next.SendPasswordResetEmail(ctx, to, token)
+	})
+}
+
+// SendEmailChangeVerification delegates to the wrapped service under the configured deadline.
+func (s *timeoutEmailService) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	return s.send(ctx, func(ctx context.Context) error {
+		return s.next.SendEmailChangeVerification(ctx, to, token)
+	})
+}
+
+// SendEmailChangeNotice delegates to the wrapped service under the configured deadline.
+func (s *timeoutEmailService) SendEmailChangeNotice(ctx context.Context, to, newEmail string) error {
+	return s.send(ctx, func(ctx context.Context) error {
+		return s.next.SendEmailChangeNotice(ctx, to, newEmail)
+	})
+}
+
+// SendCategorized delegates to the wrapped service under the configured deadline.
+func (s *timeoutEmailService) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	return s.send(ctx, func(ctx context.Context) error {
+		return s.next.SendCategorized(ctx, userID, category, message)
+	})
+}
+
+func (s *timeoutEmailService) send(ctx context.Context, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %w", ErrSendTimeout, s.timeout, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowMailer blocks for delay regardless of context cancellation, simulating a hung mail server.
+type slowMailer struct {
+	delay time.Duration
+}
+
+func (m *slowMailer) SendPasswordResetEmail(ctx context.Context, to, token string) error {
+	time.Sleep(m.delay)
+	return nil
+}
+
+func (m *slowMailer) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	time.Sleep(m.delay)
+	return nil
+}
+
+func (m *slowMailer) SendEmailChangeNotice(ctx context.Context, to, newEmail string) error {
+	time.Sleep(m.delay)
+	return nil
+}
+
+func (m *slowMailer) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	time.Sleep(m.delay)
+	return nil
+}
+
+func TestWithTimeout_SlowMailerReturnsTimeout(t *testing.T) {
+	svc := WithTimeout(&slowMailer{delay: 2 * time.Second}, 20*time.Millisecond)
+
+	start := time.Now()
+	err := svc.SendPasswordResetEmail(context.Background(), "john@example.com", "reset-token")
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrSendTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, elapsed, time.Second, "send should not wait for the slow mailer")
+}
+
+func TestWithTimeout_FastMailerSucceeds(t *testing.T) {
+	svc := WithTimeout(&slowMailer{delay: 0}, time.Second)
+
+	err := svc.SendPasswordResetEmail(context.Background(), "john@example.com", "reset-token")
+	assert.NoError(t, err)
+}
+
+func TestWithTimeout_CallerCancellation(t *testing.T) {
+	svc := WithTimeout(&slowMailer{delay: 2 * time.Second}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := svc.SendPasswordResetEmail(ctx, "john@example.com", "reset-token")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errors.Is(err, ErrSendTimeout))
+}
+
+func TestWithTimeout_DisabledReturnsInner(t *testing.T) {
+	inner := NewConsoleEmailService("noreply@example.com", nil, nil)
+	assert.Same(t, inner, WithTimeout(inner, 0))
+}
+
+func TestConsoleEmailService_RespectsCancelledContext(t *testing.T) {
+	svc := NewConsoleEmailService("noreply@example.com", nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, svc.SendPasswordResetEmail(ctx, "john@example.com", "reset-token"), context.Canceled)
+	assert.NoError(t, svc.SendPasswordResetEmail(context.Background(), "john@example.com", "reset-token"))
+}
+
+func TestWithTimeout_EmailChangeMessages(t *testing.T) {
+	svc := WithTimeout(&slowMailer{delay: 2 * time.Second}, 20*time.Millisecond)
+
+	assert.ErrorIs(t, svc.SendEmailChangeVerification(context.Background(), "new@example.com", "token"), ErrSendTimeout)
+	assert.ErrorIs(t, svc.SendEmailChangeNotice(context.Background(), "old@example.com", "new@example.com"), ErrSendTimeout)
+}
+
+func TestConsoleEmailService_EmailChangeMessages(t *testing.T) {
+	svc := NewConsoleEmailService("noreply@example.com", nil, nil)
+
+	assert.NoError(t, svc.SendEmailChangeVerification(context.Background(), "new@example.com", "token"))
+	assert.NoError(t, svc.SendEmailChangeNotice(context.Background(), "old@example.com", "new@example.com"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, svc.SendEmailChangeVerification(ctx, "new@example.com", "token"), context.Canceled)
+	assert.ErrorIs(t, svc.SendEmailChangeNotice(ctx, "old@example.com", "new@example.com"), context.Canceled)
+}
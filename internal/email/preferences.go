@@ -0,0 +1,102 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Category groups emails so users can opt out of the ones they do not want.
+type Category string
+
+// Email categories
+const (
+	// CategorySecurity covers account security emails, which are always sent.
+	CategorySecurity Category = "security"
+	CategoryProduct  Category = "product"
+	// CategoryMarketing emails carry a one-click unsubscribe link.
+	CategoryMarketing Category = "marketing"
+)
+
+// Categories lists every known category.
+var Categories = []Category{CategorySecurity, CategoryProduct, CategoryMarketing}
+
+var (
+	// ErrOptedOut is returned when the recipient disabled the message's category.
+	ErrOptedOut = errors.New("recipient opted out of this email category")
+	// ErrUnknownCategory is returned for a category not listed in Categories.
+	ErrUnknownCategory = errors.New("unknown email category")
+)
+
+// Valid reports whether c is a known category.
+func (c Category) Valid() bool {
+	switch c {
+	case CategorySecurity, CategoryProduct, CategoryMarketing:
+		return true
+	}
+	return false
+}
+
+// Optional reports whether users can opt out of c.
+func (c Category) Optional() bool {
+	return c != CategorySecurity
+}
+
+// Message is a categorized email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// UnsubscribeURL lets the recipient opt out of the category without logging in.
+	UnsubscribeURL string
+}
+
+// Preferences reports whether a user accepts emails of a category.
+type Preferences interface {
+	AllowsEmail(ctx context.Context, userID uint, category Category) (bool, error)
+}
+
+// UnsubscribeURLFunc builds the one-click unsubscribe link for a user and category.
+type UnsubscribeURLFunc func(userID uint, category Category) (string, error)
+
+// preferenceGate drops categorized emails the recipient opted out of.
+type preferenceGate struct {
+	EmailService
+	prefs          Preferences
+	unsubscribeURL UnsubscribeURLFunc
+}
+
+// WithPreferences wraps next so that SendCategorized honors the recipient's
+// notification preferences and returns ErrOptedOut instead of sending. Security
+// emails, including password reset and email change messages, are always sent.
+// When unsubscribeURL is set, optional messages without a link get one.
+func WithPreferences(next EmailService, prefs Preferences, unsubscribeURL UnsubscribeURLFunc) EmailService {
+	return &preferenceGate{EmailService: next, prefs: prefs, unsubscribeURL: unsubscribeURL}
+}
+
+// SendCategorized checks the recipient's preferences before delegating.
+func (g *preferenceGate) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	if !category.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownCategory, category)
+	}
+
+	if category.Optional() {
+		allowed, err := g.prefs.AllowsEmail(ctx, userID, category)
+		if err != nil {
+			return fmt.Errorf("failed to check notification preferences: %w", err)
+		}
+		if !allowed {
+			return ErrOptedOut
+		}
+
+		if message.UnsubscribeURL == "" && g.unsubscribeURL != nil {
+			link, err := g.unsubscribeURL(userID, category)
+			if err != nil {
+				return fmt.Errorf("failed to build unsubscribe link: %w", err)
+			}
+			message.UnsubscribeURL = link
+		}
+	}
+
+	return g.EmailService.SendCategorized(ctx, userID, category, message)
+}
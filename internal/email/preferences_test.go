@@ -0,0 +1,103 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer captures categorized messages that made it past the gate.
+type recordingMailer struct {
+	*ConsoleEmailService
+	sent []Message
+}
+
+func newRecordingMailer() *recordingMailer {
+	return &recordingMailer{ConsoleEmailService: NewConsoleEmailService("", nil, nil)}
+}
+
+func (m *recordingMailer) SendCategorized(ctx context.Context, userID uint, category Category, message Message) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+// staticPreferences allows the categories it maps to true.
+type staticPreferences struct {
+	allowed map[Category]bool
+	err     error
+}
+
+func (p staticPreferences) AllowsEmail(ctx context.Context, userID uint, category Category) (bool, error) {
+	return p.allowed[category], p.err
+}
+
+func TestWithPreferences_EnforcesCategories(t *testing.T) {
+	prefs := staticPreferences{allowed: map[Category]bool{CategoryProduct: true, CategoryMarketing: false}}
+
+	tests := []struct {
+		category Category
+		wantErr  error
+	}{
+		{category: CategoryProduct},
+		{category: CategoryMarketing, wantErr: ErrOptedOut},
+		// WHY: Security emails ignore preferences even though the user has no security entry
+		{category: CategorySecurity},
+		{category: Category("newsletter"), wantErr: ErrUnknownCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			next := newRecordingMailer()
+			svc := WithPreferences(next, prefs, nil)
+
+			err := svc.SendCategorized(context.Background(), 1, tt.category, Message{To: "john@example.com", Subject: "Hi"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, next.sent)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, next.sent, 1)
+		})
+	}
+}
+
+func TestWithPreferences_SecurityIgnoresPreferenceErrors(t *testing.T) {
+	next := newRecordingMailer()
+	svc := WithPreferences(next, staticPreferences{err: errors.New("database down")}, nil)
+
+	require.NoError(t, svc.SendCategorized(context.Background(), 1, CategorySecurity, Message{To: "john@example.com"}))
+	assert.Len(t, next.sent, 1)
+
+	err := svc.SendCategorized(context.Background(), 1, CategoryProduct, Message{To: "john@example.com"})
+	assert.ErrorContains(t, err, "database down")
+	assert.Len(t, next.sent, 1)
+}
+
+func TestWithPreferences_AddsUnsubscribeURL(t *testing.T) {
+	next := newRecordingMailer()
+	prefs := staticPreferences{allowed: map[Category]bool{CategoryMarketing: true}}
+	svc := WithPreferences(next, prefs, func(userID uint, category Category) (string, error) {
+		return fmt.Sprintf("https://example.com/unsubscribe?user_id=%d&category=%s", userID, category), nil
+	})
+
+	require.NoError(t, svc.SendCategorized(context.Background(), 7, CategoryMarketing, Message{To: "john@example.com"}))
+	require.NoError(t, svc.SendCategorized(context.Background(), 7, CategorySecurity, Message{To: "john@example.com"}))
+
+	require.Len(t, next.sent, 2)
+	assert.Equal(t, "https://example.com/unsubscribe?user_id=7&category=marketing", next.sent[0].UnsubscribeURL)
+	assert.Empty(t, next.sent[1].UnsubscribeURL, "security emails cannot be unsubscribed from")
+}
+
+func TestWithPreferences_TransactionalEmailsBypassGate(t *testing.T) {
+	next := newRecordingMailer()
+	svc := WithPreferences(next, staticPreferences{err: errors.New("must not be called")}, nil)
+
+	assert.NoError(t, svc.SendPasswordResetEmail(context.Background(), "john@example.com", "reset-token"))
+	assert.NoError(t, svc.SendEmailChangeVerification(context.Background(), "john@example.com", "token"))
+	assert.NoError(t, svc.SendEmailChangeNotice(context.Background(), "john@example.com", "new@example.com"))
+}
@@ -0,0 +1,35 @@
+package email
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// ResetTokenPlaceholder marks where the token goes in a reset link template
+	ResetTokenPlaceholder = "{token}"
+
+	// DefaultResetLinkPath is the web reset form, appended to the public base
+	// URL when no template is configured
+	DefaultResetLinkPath = "/reset-password?token=" + ResetTokenPlaceholder
+)
+
+// ResetLinks builds the links in password reset emails from a template, so
+// they can open the web form or a mobile app, e.g. myapp://reset?token={token}
+type ResetLinks struct {
+	template string
+}
+
+// NewResetLinks creates a link builder. An empty template links to the web
+// form under baseURL, the public address of the API.
+func NewResetLinks(template, baseURL string) *ResetLinks {
+	if template == "" {
+		template = strings.TrimRight(baseURL, "/") + DefaultResetLinkPath
+	}
+	return &ResetLinks{template: template}
+}
+
+// URL returns the link that resets a password with token
+func (l *ResetLinks) URL(token string) string {
+	return strings.ReplaceAll(l.template, ResetTokenPlaceholder, url.QueryEscape(token))
+}
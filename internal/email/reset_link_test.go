@@ -0,0 +1,53 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetLinks_URL(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		baseURL  string
+		want     string
+	}{
+		{
+			name:    "default web form",
+			baseURL: "https://api.example.com/",
+			want:    "https://api.example.com/reset-password?token=abc%2B123",
+		},
+		{
+			name:     "web template",
+			template: "https://app.example.com/account/reset/{token}",
+			baseURL:  "https://api.example.com",
+			want:     "https://app.example.com/account/reset/abc%2B123",
+		},
+		{
+			name:     "deep link template",
+			template: "myapp://reset?token={token}",
+			baseURL:  "https://api.example.com",
+			want:     "myapp://reset?token=abc%2B123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewResetLinks(tt.template, tt.baseURL).URL("abc+123"))
+		})
+	}
+}
+
+func TestConsoleEmailService_RendersResetLink(t *testing.T) {
+	var logs bytes.Buffer
+	svc := NewConsoleEmailService("noreply@example.com", NewResetLinks("myapp://reset?token={token}", ""), slog.New(slog.NewTextHandler(&logs, nil)))
+
+	require.NoError(t, svc.SendPasswordResetEmail(context.Background(), "john@example.com", "abc+123"))
+
+	assert.Contains(t, logs.String(), `reset_url="myapp://reset?token=abc%2B123"`)
+}
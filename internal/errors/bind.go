@@ -0,0 +1,312 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Default limits applied by BindJSON when none are configured.
+const (
+	DefaultMaxJSONBytes = 1 << 20
+	DefaultMaxJSONDepth = 32
+
+	jsonDecodingKey = "json_decoding"
+)
+
+// JSONDecodingConfig controls how BindJSON decodes request bodies.
+type JSONDecodingConfig struct {
+	// Strict rejects fields that do not exist on the target struct
+	Strict bool
+	// MaxBytes caps the body size; zero uses DefaultMaxJSONBytes
+	MaxBytes int64
+	// MaxDepth caps object/array nesting; zero uses DefaultMaxJSONDepth
+	MaxDepth int
+}
+
+// JSONDecoding returns a Gin middleware that sets the JSON decoding rules used by BindJSON.
+func JSONDecoding(cfg JSONDecodingConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(jsonDecodingKey, cfg)
+		c.Next()
+	}
+}
+
+// BindJSON decodes the request body into obj and validates it like
+// ShouldBindJSON, but bounds the body size and nesting depth before decoding
+// and optionally rejects unknown fields. Decoding failures map to a 400
+// "malformed JSON" error; type mismatches and validation failures map through
+// FromGinValidation. In strict mode unknown fields, named by their JSON path,
+// are reported together with any validation failures as one validation error.
+//
+// The body is kept under gin.BodyBytesKey, so middleware that reads it with
+// ShouldBindBodyWith before the handler leaves it for BindJSON and vice versa.
+func BindJSON(c *gin.Context, obj any) *APIError {
+	return bindJSON(c, obj, nil)
+}
+
+// bindJSON is BindJSON, checking the body against schema when one is given
+func bindJSON(c *gin.Context, obj any, schema *Schema) *APIError {
+	var cfg JSONDecodingConfig
+	if v, ok := c.Get(jsonDecodingKey); ok {
+		cfg, _ = v.(JSONDecodingConfig)
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxJSONBytes
+	}
+	maxDepth := cfg.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxJSONDepth
+	}
+
+	body, apiErr := readBody(c, maxBytes)
+	if apiErr != nil {
+		return apiErr
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return malformedJSON("request body is empty")
+	}
+
+	// WHY: Check depth on the raw bytes so pathological nesting is rejected
+	// before encoding/json recurses into it
+	if exceedsDepth(body, maxDepth) {
+		return malformedJSON(fmt.Sprintf("JSON nesting exceeds maximum depth of %d", maxDepth))
+	}
+
+	var details FieldErrors
+	if schema != nil {
+		var apiErr *APIError
+		if details, apiErr = schema.validate(body, cfg.Strict); apiErr != nil {
+			return apiErr
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(obj); err != nil {
+		// Well-formed JSON with a wrong value type is a data error, not a syntax error
+		var typeErr *json.UnmarshalTypeError
+		if stderrors.As(err, &typeErr) {
+			// The schema already named every mistyped field
+			if details != nil {
+				return ValidationError(details)
+			}
+			return FromGinValidation(err)
+		}
+		return malformedJSON(err.Error())
+	}
+	if dec.More() {
+		return malformedJSON("unexpected data after JSON value")
+	}
+
+	// WHY: Unknown fields are collected rather than failing the decode, so a
+	// misspelled required field reports both the typo and the missing field
+	if cfg.Strict {
+		for _, path := range unknownFields(body, obj) {
+			if details == nil {
+				details = FieldErrors{}
+			}
+			details[path] = path + " is not a known field"
+		}
+	}
+
+	if binding.Validator != nil {
+		if err := binding.Validator.ValidateStruct(obj); err != nil {
+			apiErr := FromGinValidation(err)
+			if schema != nil {
+				apiErr = FromJSONValidation(err, obj)
+			}
+			fieldErrs, ok := apiErr.Details.(map[string]string)
+			if details == nil || !ok {
+				return apiErr
+			}
+			for field, message := range fieldErrs {
+				details[field] = message
+			}
+		}
+	}
+
+	if details != nil {
+		return ValidationError(details)
+	}
+	return nil
+}
+
+// readBody returns the request body, reading it at most once per request
+func readBody(c *gin.Context, maxBytes int64) ([]byte, *APIError) {
+	if cached, ok := c.Get(gin.BodyBytesKey); ok {
+		if body, ok := cached.([]byte); ok {
+			if int64(len(body)) > maxBytes {
+				return nil, malformedJSON(fmt.Sprintf("request body exceeds %d bytes", maxBytes))
+			}
+			return body, nil
+		}
+	}
+
+	if c.Request == nil || c.Request.Body == nil {
+		return nil, malformedJSON("request body is empty")
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			return nil, malformedJSON(fmt.Sprintf("request body exceeds %d bytes", maxBytes))
+		}
+		return nil, malformedJSON(err.Error())
+	}
+	c.Set(gin.BodyBytesKey, body)
+	return body, nil
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// unknownFields lists the JSON paths in data, e.g. "pasword" or "items[0].nmae",
+// that match no field of the struct obj points to. Like encoding/json, names
+// match case-insensitively; types with their own UnmarshalJSON are not inspected.
+func unknownFields(data []byte, obj any) []string {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	var paths []string
+	collectUnknownFields(value, reflect.TypeOf(obj), "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func collectUnknownFields(value any, t reflect.Type, path string, paths *[]string) {
+	for t.Kind() == reflect.Pointer {
+		if t.Implements(jsonUnmarshalerType) {
+			return
+		}
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for name, fieldValue := range object {
+			fieldPath := joinFieldPath(path, name)
+			fieldType, ok := fields[strings.ToLower(name)]
+			if !ok {
+				*paths = append(*paths, fieldPath)
+				continue
+			}
+			collectUnknownFields(fieldValue, fieldType, fieldPath, paths)
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := value.([]any)
+		for i, item := range items {
+			collectUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]any)
+		for key, item := range object {
+			collectUnknownFields(item, t.Elem(), joinFieldPath(path, key), paths)
+		}
+	}
+}
+
+// jsonFields maps the lower-cased JSON names of the fields of struct type t,
+// including those promoted from embedded structs, to their types
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(embedded) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// exceedsDepth reports whether objects and arrays in data nest deeper than maxDepth.
+// Brackets inside strings are ignored; syntax errors are left to the decoder.
+func exceedsDepth(data []byte, maxDepth int) bool {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return false
+}
+
+func malformedJSON(details string) *APIError {
+	err := BadRequest("malformed JSON")
+	err.Details = details
+	return err
+}
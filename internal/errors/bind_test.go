@@ -0,0 +1,236 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindTarget struct {
+	Name  string `json:"name" binding:"required"`
+	Count int    `json:"count"`
+	Tags  []any  `json:"tags"`
+}
+
+func performBind(t *testing.T, cfg JSONDecodingConfig, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.Use(JSONDecoding(cfg))
+	r.POST("/widgets", func(c *gin.Context) {
+		var req bindTarget
+		if err := BindJSON(c, &req); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeErrorInfo(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errorInfo, ok := response["error"].(map[string]any)
+	require.True(t, ok, "error should be a map: %s", w.Body.String())
+	return errorInfo
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         JSONDecodingConfig
+		body        string
+		wantStatus  int
+		wantMessage string
+		wantDetails string
+	}{
+		{"valid body", JSONDecodingConfig{}, `{"name":"widget","count":2}`, http.StatusOK, "", ""},
+		{"syntax error", JSONDecodingConfig{}, `{invalid-json}`, http.StatusBadRequest, "malformed JSON", "invalid character"},
+		{"empty body", JSONDecodingConfig{}, ``, http.StatusBadRequest, "malformed JSON", "request body is empty"},
+		{"trailing data", JSONDecodingConfig{}, `{"name":"a"} {"name":"b"}`, http.StatusBadRequest, "malformed JSON", "unexpected data after JSON value"},
+		{"body too large", JSONDecodingConfig{MaxBytes: 16}, `{"name":"a very long widget name"}`, http.StatusBadRequest, "malformed JSON", "request body exceeds 16 bytes"},
+		{"nesting too deep", JSONDecodingConfig{MaxDepth: 3}, `{"name":"a","tags":[[[1]]]}`, http.StatusBadRequest, "malformed JSON", "maximum depth of 3"},
+		{"brackets inside strings do not count", JSONDecodingConfig{MaxDepth: 1}, `{"name":"[[{{\"]]"}`, http.StatusOK, "", ""},
+		{"unknown field when lenient", JSONDecodingConfig{}, `{"name":"a","extra":true}`, http.StatusOK, "", ""},
+		{"unknown field when strict", JSONDecodingConfig{Strict: true}, `{"name":"a","extra":true}`, http.StatusBadRequest, "Validation failed", ""},
+		{"wrong value type", JSONDecodingConfig{}, `{"name":"a","count":"two"}`, http.StatusBadRequest, "Invalid request data format", ""},
+		{"validation failure", JSONDecodingConfig{}, `{"count":1}`, http.StatusBadRequest, "Validation failed", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performBind(t, tt.cfg, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+
+			errorInfo := decodeErrorInfo(t, w)
+			assert.Equal(t, CodeValidation, errorInfo["code"])
+			assert.Equal(t, tt.wantMessage, errorInfo["message"])
+			if tt.wantDetails != "" {
+				assert.Contains(t, errorInfo["details"], tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestBindJSON_DefaultLimitsWithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := strings.Repeat("[", DefaultMaxJSONDepth+1) + strings.Repeat("]", DefaultMaxJSONDepth+1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(body))
+
+	var req []any
+	err := BindJSON(c, &req)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Status)
+	assert.Equal(t, "malformed JSON", err.Message)
+}
+
+type strictItem struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity"`
+}
+
+type strictAddress struct {
+	City string `json:"city"`
+}
+
+type strictAudit struct {
+	Note string `json:"note"`
+}
+
+type strictOrder struct {
+	strictAudit
+	Email    string            `json:"email" binding:"required"`
+	Password string            `json:"password" binding:"required"`
+	Address  *strictAddress    `json:"address"`
+	Items    []strictItem      `json:"items" binding:"dive"`
+	Labels   map[string]string `json:"labels"`
+	Extra    any               `json:"extra"`
+	Internal string            `json:"-"`
+}
+
+func TestBindJSON_StrictUnknownFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantDetails map[string]any
+	}{
+		{
+			name:        "unknown field",
+			body:        `{"email":"a@example.com","password":"secret","nickname":"al"}`,
+			wantDetails: map[string]any{"nickname": "nickname is not a known field"},
+		},
+		{
+			name: "misspelled required field",
+			body: `{"email":"a@example.com","pasword":"secret"}`,
+			wantDetails: map[string]any{
+				"pasword":  "pasword is not a known field",
+				"Password": "Password is required",
+			},
+		},
+		{
+			name: "nested objects and arrays",
+			body: `{"email":"a@example.com","password":"secret","address":{"city":"Oslo","zip":"0150"},"items":[{"sku":"a"},{"sku":"b","qty":2}]}`,
+			wantDetails: map[string]any{
+				"address.zip":  "address.zip is not a known field",
+				"items[1].qty": "items[1].qty is not a known field",
+			},
+		},
+		{
+			name:        "ignored field",
+			body:        `{"email":"a@example.com","password":"secret","internal":"x"}`,
+			wantDetails: map[string]any{"internal": "internal is not a known field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performStrictBind(t, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			errorInfo := decodeErrorInfo(t, w)
+			assert.Equal(t, CodeValidation, errorInfo["code"])
+			assert.Equal(t, "Validation failed", errorInfo["message"])
+			assert.Equal(t, tt.wantDetails, errorInfo["details"])
+		})
+	}
+
+	t.Run("known fields in any case, embedded structs, maps and any values pass", func(t *testing.T) {
+		w := performStrictBind(t, `{"EMAIL":"a@example.com","password":"secret","note":"n","labels":{"free":"form"},"extra":{"anything":[1]},"items":[{"sku":"a","quantity":1}]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
+
+func performStrictBind(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.Use(JSONDecoding(JSONDecodingConfig{Strict: true}))
+	r.POST("/orders", func(c *gin.Context) {
+		var req strictOrder
+		if err := BindJSON(c, &req); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBindJSON_SharesBodyWithMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.Use(func(c *gin.Context) {
+		// Middleware keyed on a body field, e.g. a per-email limiter
+		var peek struct {
+			Name string `json:"name"`
+		}
+		_ = c.ShouldBindBodyWith(&peek, binding.JSON)
+		c.Header("X-Seen-Name", peek.Name)
+		c.Next()
+	})
+	r.POST("/widgets", func(c *gin.Context) {
+		var req bindTarget
+		if err := BindJSON(c, &req); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(`{"name":"widget","count":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "widget", w.Header().Get("X-Seen-Name"))
+	assert.JSONEq(t, `{"name":"widget","count":2,"tags":null}`, w.Body.String())
+}
@@ -0,0 +1,45 @@
+package errors
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+// catalog documents every code in codes.go, with the status its constructors use
+var catalog = []api.ErrorCodeInfo{
+	{Code: CodeInternal, Status: http.StatusInternalServerError, Description: "The server failed to handle the request."},
+	{Code: CodeNotFound, Status: http.StatusNotFound, Description: "The route or resource does not exist; details name the resource and ID when known."},
+	{Code: CodeMethodNotAllowed, Status: http.StatusMethodNotAllowed, Description: "The route exists but not for this method; details list the allowed methods."},
+	{Code: CodeUnauthorized, Status: http.StatusUnauthorized, Description: "Authentication is missing or invalid."},
+	{Code: CodeTokenExpired, Status: http.StatusUnauthorized, Description: "The access token has expired; refresh it and retry."},
+	{Code: CodeForbidden, Status: http.StatusForbidden, Description: "The caller is authenticated but may not perform this action."},
+	{Code: CodeAccountDisabled, Status: http.StatusForbidden, Description: "The account has been deactivated."},
+	{Code: CodeLicenseLimit, Status: http.StatusForbidden, Description: "Creating the user would exceed the licensed number of seats."},
+	{Code: CodeValidation, Status: http.StatusBadRequest, Description: "The request is malformed or fails validation; details map fields to what is wrong with them."},
+	{Code: CodeConflict, Status: http.StatusConflict, Description: "The request conflicts with existing data, such as an email already in use."},
+	{Code: CodeTooManyRequests, Status: http.StatusTooManyRequests, Description: "A rate limit was exceeded; retry after the seconds in retry_after."},
+	{Code: CodeServiceUnavailable, Status: http.StatusServiceUnavailable, Description: "The server is temporarily unable to handle the request, e.g. during maintenance; retry after the seconds in retry_after when given."},
+	{Code: CodeNotImplemented, Status: http.StatusNotImplemented, Description: "The server does not support the requested operation."},
+	{Code: CodeRequestCanceled, Status: StatusClientClosedRequest, Description: "The client abandoned the request before the response was written."},
+	{Code: CodeRequestTimeout, Status: http.StatusRequestTimeout, Description: "The request ran past its deadline."},
+}
+
+// Catalog returns the documented error codes, in the order of codes.go
+func Catalog() []api.ErrorCodeInfo {
+	return slices.Clone(catalog)
+}
+
+// CatalogHandler godoc
+// @Summary Error code catalog
+// @Description List every error code the API returns with its HTTP status and meaning, so clients can program against stable codes
+// @Tags meta
+// @Produce json,xml
+// @Success 200 {object} errors.Response{success=bool,data=api.ErrorCatalogResponse} "Error codes"
+// @Router /api/v1/errors [get]
+func CatalogHandler(c *gin.Context) {
+	Respond(c, http.StatusOK, api.ErrorCatalogResponse{Codes: Catalog()})
+}
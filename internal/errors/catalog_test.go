@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	statuses := make(map[string]int)
+	for _, info := range Catalog() {
+		assert.NotContains(t, statuses, info.Code, "duplicate code")
+		assert.NotEmpty(t, info.Description, info.Code)
+		statuses[info.Code] = info.Status
+	}
+
+	for code, status := range map[string]int{
+		"VALIDATION_ERROR":      http.StatusBadRequest,
+		"UNAUTHORIZED":          http.StatusUnauthorized,
+		"TOKEN_EXPIRED":         http.StatusUnauthorized,
+		"FORBIDDEN":             http.StatusForbidden,
+		"NOT_FOUND":             http.StatusNotFound,
+		"METHOD_NOT_ALLOWED":    http.StatusMethodNotAllowed,
+		"CONFLICT":              http.StatusConflict,
+		"TOO_MANY_REQUESTS":     http.StatusTooManyRequests,
+		"REQUEST_CANCELED":      StatusClientClosedRequest,
+		"INTERNAL_ERROR":        http.StatusInternalServerError,
+		"SERVICE_UNAVAILABLE":   http.StatusServiceUnavailable,
+		"LICENSE_LIMIT_REACHED": http.StatusForbidden,
+	} {
+		assert.Equal(t, status, statuses[code], code)
+	}
+
+	// Constructors are the source of truth for statuses
+	for _, err := range []*APIError{
+		NotFound(""), MethodNotAllowed("", nil), BadRequest(""), Conflict(""), Forbidden(""),
+		AccountDisabled(""), LicenseLimitReached(""), Unauthorized(""), TokenExpired(""),
+		InternalServerError(assert.AnError), NotImplemented(""), RequestCanceled(), RequestTimeout(),
+		&TooManyRequests(1).APIError, &ServiceUnavailable("", 1).APIError,
+	} {
+		assert.Equal(t, err.Status, statuses[err.Code], err.Code)
+	}
+}
+
+func TestCatalog_CoversEveryCode(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../../pkg/api/errors.go", nil, 0)
+	require.NoError(t, err)
+
+	var codes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.ValueSpec); ok && len(spec.Values) == 1 {
+			if lit, ok := spec.Values[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				code, err := strconv.Unquote(lit.Value)
+				require.NoError(t, err)
+				codes = append(codes, code)
+			}
+		}
+		return true
+	})
+	require.NotEmpty(t, codes)
+
+	documented := make([]string, 0, len(catalog))
+	for _, info := range catalog {
+		documented = append(documented, info.Code)
+	}
+	assert.ElementsMatch(t, codes, documented, "every Code constant needs a catalog entry")
+}
+
+func TestCatalogHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/errors", CatalogHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Codes []struct {
+				Code        string `json:"code"`
+				Status      int    `json:"status"`
+				Description string `json:"description"`
+			} `json:"codes"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	require.Len(t, response.Data.Codes, len(catalog))
+	assert.Equal(t, CodeInternal, response.Data.Codes[0].Code)
+	assert.Equal(t, http.StatusInternalServerError, response.Data.Codes[0].Status)
+}
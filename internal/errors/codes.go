@@ -1,12 +1,23 @@
 package errors
 
-// Error code constants for machine-readable API error identification.
+import "github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+
+// Error code constants for machine-readable API error identification. They are
+// defined in pkg/api so Go clients can match on them.
 const (
-	CodeInternal        = "INTERNAL_ERROR"
-	CodeNotFound        = "NOT_FOUND"
-	CodeUnauthorized    = "UNAUTHORIZED"
-	CodeForbidden       = "FORBIDDEN"
-	CodeValidation      = "VALIDATION_ERROR"
-	CodeConflict        = "CONFLICT"
-	CodeTooManyRequests = "TOO_MANY_REQUESTS"
+	CodeInternal           = api.CodeInternal
+	CodeNotFound           = api.CodeNotFound
+	CodeMethodNotAllowed   = api.CodeMethodNotAllowed
+	CodeUnauthorized       = api.CodeUnauthorized
+	CodeTokenExpired       = api.CodeTokenExpired
+	CodeForbidden          = api.CodeForbidden
+	CodeAccountDisabled    = api.CodeAccountDisabled
+	CodeLicenseLimit       = api.CodeLicenseLimit
+	CodeValidation         = api.CodeValidation
+	CodeConflict           = api.CodeConflict
+	CodeTooManyRequests    = api.CodeTooManyRequests
+	CodeServiceUnavailable = api.CodeServiceUnavailable
+	CodeNotImplemented     = api.CodeNotImplemented
+	CodeRequestCanceled    = api.CodeRequestCanceled
+	CodeRequestTimeout     = api.CodeRequestTimeout
 )
@@ -1,13 +1,20 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// StatusClientClosedRequest is the non-standard status, popularized by nginx, for
+// requests the client abandoned before the response was written.
+const StatusClientClosedRequest = 499
+
 // APIError represents a structured API error with code, message, details and HTTP status.
 type APIError struct {
 	Code    string `json:"code"`
@@ -16,7 +23,18 @@ type APIError struct {
 	Status  int    `json:"-"`
 }
 
-// RateLimitError extends APIError with retry-after information for rate limiting.
+// FieldErrors maps request fields, by their JSON name, to what is wrong with
+// their value. It is the Details of errors about specific fields.
+type FieldErrors map[string]string
+
+// ResourceDetails identifies the resource a NotFoundWithResource error is about
+type ResourceDetails struct {
+	Resource string `json:"resource"`
+	ID       any    `json:"id"`
+}
+
+// RateLimitError extends APIError with retry-after information, for rate limiting
+// and temporary unavailability. A positive RetryAfter is sent as the Retry-After header.
 type RateLimitError struct {
 	APIError
 	RetryAfter int `json:"retry_after"`
@@ -35,6 +53,27 @@ func NotFound(message string) *APIError {
 	}
 }
 
+// NotFoundWithResource creates a 404 Not Found error for the resource with the
+// given ID, e.g. "User not found" with details {resource: "user", id: 42}.
+func NotFoundWithResource(resource string, id any) *APIError {
+	return &APIError{
+		Code:    CodeNotFound,
+		Message: capitalize(resource) + " not found",
+		Details: ResourceDetails{Resource: resource, ID: id},
+		Status:  http.StatusNotFound,
+	}
+}
+
+// MethodNotAllowed creates a 405 Method Not Allowed error listing the allowed methods as details.
+func MethodNotAllowed(message string, allowed []string) *APIError {
+	return &APIError{
+		Code:    CodeMethodNotAllowed,
+		Message: message,
+		Details: allowed,
+		Status:  http.StatusMethodNotAllowed,
+	}
+}
+
 // BadRequest creates a 400 Bad Request error for validation failures.
 func BadRequest(message string) *APIError {
 	return &APIError{
@@ -53,6 +92,25 @@ func Conflict(message string) *APIError {
 	}
 }
 
+// ConflictWithField creates a 409 Conflict error for a field whose value is
+// taken, e.g. "Email already in use" with details {email: "already in use"}.
+func ConflictWithField(field, message string) *APIError {
+	return &APIError{
+		Code:    CodeConflict,
+		Message: capitalize(field) + " " + message,
+		Details: FieldErrors{field: message},
+		Status:  http.StatusConflict,
+	}
+}
+
+// capitalize upper-cases the first letter of an ASCII identifier
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 // Forbidden creates a 403 Forbidden error for authorization failures.
 func Forbidden(message string) *APIError {
 	return &APIError{
@@ -62,6 +120,24 @@ func Forbidden(message string) *APIError {
 	}
 }
 
+// AccountDisabled creates a 403 Forbidden error for sign-ins to a deactivated account.
+func AccountDisabled(message string) *APIError {
+	return &APIError{
+		Code:    CodeAccountDisabled,
+		Message: message,
+		Status:  http.StatusForbidden,
+	}
+}
+
+// LicenseLimitReached creates a 403 Forbidden error for creating a user past the licensed seats.
+func LicenseLimitReached(message string) *APIError {
+	return &APIError{
+		Code:    CodeLicenseLimit,
+		Message: message,
+		Status:  http.StatusForbidden,
+	}
+}
+
 // Unauthorized creates a 401 Unauthorized error for authentication failures.
 func Unauthorized(message string) *APIError {
 	return &APIError{
@@ -71,6 +147,15 @@ func Unauthorized(message string) *APIError {
 	}
 }
 
+// TokenExpired creates a 401 Unauthorized error signalling that the client should refresh its access token.
+func TokenExpired(message string) *APIError {
+	return &APIError{
+		Code:    CodeTokenExpired,
+		Message: message,
+		Status:  http.StatusUnauthorized,
+	}
+}
+
 // InternalServerError creates a 500 Internal Server Error with details from the original error.
 func InternalServerError(err error) *APIError {
 	return &APIError{
@@ -81,6 +166,60 @@ func InternalServerError(err error) *APIError {
 	}
 }
 
+// ServerError creates a 500 Internal Server Error like InternalServerError, unless
+// err comes from a canceled or expired request context, which is the client's
+// doing rather than a server failure and becomes RequestCanceled or RequestTimeout.
+func ServerError(err error) *APIError {
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return RequestCanceled()
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return RequestTimeout()
+	default:
+		return InternalServerError(err)
+	}
+}
+
+// ServiceUnavailable creates a 503 Service Unavailable error for temporarily unavailable
+// operations, with the seconds after which to retry; zero sends no Retry-After.
+func ServiceUnavailable(message string, retryAfter int) *RateLimitError {
+	return &RateLimitError{
+		APIError: APIError{
+			Code:    CodeServiceUnavailable,
+			Message: message,
+			Status:  http.StatusServiceUnavailable,
+		},
+		RetryAfter: retryAfter,
+	}
+}
+
+// NotImplemented creates a 501 Not Implemented error for operations the server does not support.
+func NotImplemented(message string) *APIError {
+	return &APIError{
+		Code:    CodeNotImplemented,
+		Message: message,
+		Status:  http.StatusNotImplemented,
+	}
+}
+
+// RequestCanceled creates a 499 Client Closed Request error for requests the client abandoned.
+func RequestCanceled() *APIError {
+	return &APIError{
+		Code:    CodeRequestCanceled,
+		Message: "Request canceled",
+		Status:  StatusClientClosedRequest,
+	}
+}
+
+// RequestTimeout creates a 408 Request Timeout error for requests that ran past their deadline.
+func RequestTimeout() *APIError {
+	return &APIError{
+		Code:    CodeRequestTimeout,
+		Message: "Request timed out",
+		Status:  http.StatusRequestTimeout,
+	}
+}
+
 // TooManyRequests creates a 429 Too Many Requests error with retry-after seconds.
 func TooManyRequests(ra int) *RateLimitError {
 	return &RateLimitError{
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
@@ -30,6 +32,15 @@ func TestNotFound(t *testing.T) {
 	assert.Nil(t, err.Details)
 }
 
+func TestNotFoundWithResource(t *testing.T) {
+	err := NotFoundWithResource("user", uint(42))
+
+	assert.Equal(t, CodeNotFound, err.Code)
+	assert.Equal(t, "User not found", err.Message)
+	assert.Equal(t, http.StatusNotFound, err.Status)
+	assert.Equal(t, ResourceDetails{Resource: "user", ID: uint(42)}, err.Details)
+}
+
 func TestBadRequest(t *testing.T) {
 	err := BadRequest("Invalid input")
 
@@ -48,6 +59,24 @@ func TestConflict(t *testing.T) {
 	assert.Nil(t, err.Details)
 }
 
+func TestConflictWithField(t *testing.T) {
+	err := ConflictWithField("email", "already in use")
+
+	assert.Equal(t, CodeConflict, err.Code)
+	assert.Equal(t, "Email already in use", err.Message)
+	assert.Equal(t, http.StatusConflict, err.Status)
+	assert.Equal(t, FieldErrors{"email": "already in use"}, err.Details)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	err := MethodNotAllowed("Method POST is not allowed for /health", []string{"GET", "HEAD"})
+
+	assert.Equal(t, CodeMethodNotAllowed, err.Code)
+	assert.Equal(t, "Method POST is not allowed for /health", err.Message)
+	assert.Equal(t, http.StatusMethodNotAllowed, err.Status)
+	assert.Equal(t, []string{"GET", "HEAD"}, err.Details)
+}
+
 func TestForbidden(t *testing.T) {
 	err := Forbidden("Access denied")
 
@@ -57,6 +86,24 @@ func TestForbidden(t *testing.T) {
 	assert.Nil(t, err.Details)
 }
 
+func TestAccountDisabled(t *testing.T) {
+	err := AccountDisabled("Account is disabled")
+
+	assert.Equal(t, CodeAccountDisabled, err.Code)
+	assert.Equal(t, "Account is disabled", err.Message)
+	assert.Equal(t, http.StatusForbidden, err.Status)
+	assert.Nil(t, err.Details)
+}
+
+func TestLicenseLimitReached(t *testing.T) {
+	err := LicenseLimitReached("User limit reached")
+
+	assert.Equal(t, CodeLicenseLimit, err.Code)
+	assert.Equal(t, "User limit reached", err.Message)
+	assert.Equal(t, http.StatusForbidden, err.Status)
+	assert.Nil(t, err.Details)
+}
+
 func TestUnauthorized(t *testing.T) {
 	err := Unauthorized("Authentication required")
 
@@ -66,6 +113,15 @@ func TestUnauthorized(t *testing.T) {
 	assert.Nil(t, err.Details)
 }
 
+func TestTokenExpired(t *testing.T) {
+	err := TokenExpired("Access token has expired")
+
+	assert.Equal(t, CodeTokenExpired, err.Code)
+	assert.Equal(t, "Access token has expired", err.Message)
+	assert.Equal(t, http.StatusUnauthorized, err.Status)
+	assert.Nil(t, err.Details)
+}
+
 func TestInternalServerError(t *testing.T) {
 	originalErr := errors.New("database connection failed")
 	err := InternalServerError(originalErr)
@@ -76,6 +132,64 @@ func TestInternalServerError(t *testing.T) {
 	assert.Equal(t, "database connection failed", err.Details)
 }
 
+func TestServerError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		status int
+	}{
+		{name: "database failure", err: errors.New("database connection failed"), code: CodeInternal, status: http.StatusInternalServerError},
+		{name: "canceled", err: fmt.Errorf("failed to find user: %w", context.Canceled), code: CodeRequestCanceled, status: StatusClientClosedRequest},
+		{name: "deadline exceeded", err: fmt.Errorf("failed to find user: %w", context.DeadlineExceeded), code: CodeRequestTimeout, status: http.StatusRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ServerError(tt.err)
+			assert.Equal(t, tt.code, err.Code)
+			assert.Equal(t, tt.status, err.Status)
+		})
+	}
+}
+
+func TestServiceUnavailable(t *testing.T) {
+	err := ServiceUnavailable("Service under maintenance", 120)
+
+	assert.Equal(t, CodeServiceUnavailable, err.Code)
+	assert.Equal(t, "Service under maintenance", err.Message)
+	assert.Equal(t, http.StatusServiceUnavailable, err.Status)
+	assert.Equal(t, 120, err.RetryAfter)
+	assert.Nil(t, err.Details)
+}
+
+func TestNotImplemented(t *testing.T) {
+	err := NotImplemented("Exports are not supported yet")
+
+	assert.Equal(t, CodeNotImplemented, err.Code)
+	assert.Equal(t, "Exports are not supported yet", err.Message)
+	assert.Equal(t, http.StatusNotImplemented, err.Status)
+	assert.Nil(t, err.Details)
+}
+
+func TestRequestCanceled(t *testing.T) {
+	err := RequestCanceled()
+
+	assert.Equal(t, CodeRequestCanceled, err.Code)
+	assert.Equal(t, "Request canceled", err.Message)
+	assert.Equal(t, StatusClientClosedRequest, err.Status)
+	assert.Nil(t, err.Details)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	err := RequestTimeout()
+
+	assert.Equal(t, CodeRequestTimeout, err.Code)
+	assert.Equal(t, "Request timed out", err.Message)
+	assert.Equal(t, http.StatusRequestTimeout, err.Status)
+	assert.Nil(t, err.Details)
+}
+
 func TestTooManyRequests(t *testing.T) {
 	retryAfter := 60
 	err := TooManyRequests(retryAfter)
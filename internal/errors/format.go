@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response formats for successful responses.
+const (
+	// FormatStandard is the default {success, data} body.
+	FormatStandard = "standard"
+	// FormatEnvelope is a {data, meta} body carrying request metadata.
+	FormatEnvelope = "envelope"
+
+	// ResponseFormatHeader lets a client choose the format for a single request.
+	ResponseFormatHeader = "X-Response-Format"
+
+	responseFormatKey = "response_format"
+)
+
+// Envelope is the body written for FormatEnvelope.
+type Envelope struct {
+	Data any   `json:"data"`
+	Meta *Meta `json:"meta"`
+}
+
+// IsValidResponseFormat reports whether format names a supported response format.
+func IsValidResponseFormat(format string) bool {
+	return format == FormatStandard || format == FormatEnvelope
+}
+
+// ResponseFormat returns a Gin middleware that sets the default response format
+// for the request. An empty or unknown format leaves the standard format in place.
+func ResponseFormat(defaultFormat string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsValidResponseFormat(defaultFormat) {
+			c.Set(responseFormatKey, defaultFormat)
+		}
+		c.Next()
+	}
+}
+
+// Respond writes a successful response in the format selected for the request:
+// the X-Response-Format header wins over the configured default. GET requests
+// that prefer XML receive data itself as the XML document, without an envelope.
+func Respond(c *gin.Context, status int, data any) {
+	c.Writer.Header().Add("Vary", "Accept")
+	c.Writer.Header().Add("Vary", ResponseFormatHeader)
+
+	if wantsXML(c) && renderXML(c, status, data) {
+		return
+	}
+
+	if selectedResponseFormat(c) == FormatEnvelope {
+		requestID, _ := c.Get("request_id")
+		reqID, _ := requestID.(string)
+		c.JSON(status, Envelope{
+			Data: data,
+			Meta: &Meta{RequestID: reqID, Timestamp: time.Now()},
+		})
+		return
+	}
+
+	c.JSON(status, Success(data))
+}
+
+func selectedResponseFormat(c *gin.Context) string {
+	if format := c.GetHeader(ResponseFormatHeader); IsValidResponseFormat(format) {
+		return format
+	}
+	if format := c.GetString(responseFormatKey); format != "" {
+		return format
+	}
+	return FormatStandard
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFormatRouter(defaultFormat string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-123")
+		c.Next()
+	})
+	r.Use(ResponseFormat(defaultFormat))
+	r.GET("/widgets/1", func(c *gin.Context) {
+		Respond(c, http.StatusOK, gin.H{"id": 1, "name": "widget"})
+	})
+	return r
+}
+
+func performFormatRequest(r *gin.Engine, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/widgets/1", nil)
+	if header != "" {
+		req.Header.Set(ResponseFormatHeader, header)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRespond_Formats(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultFormat string
+		header        string
+		wantEnvelope  bool
+	}{
+		{name: "standard by default", defaultFormat: "", wantEnvelope: false},
+		{name: "configured standard", defaultFormat: FormatStandard, wantEnvelope: false},
+		{name: "configured envelope", defaultFormat: FormatEnvelope, wantEnvelope: true},
+		{name: "header selects envelope", defaultFormat: FormatStandard, header: FormatEnvelope, wantEnvelope: true},
+		{name: "header selects standard over configured envelope", defaultFormat: FormatEnvelope, header: FormatStandard, wantEnvelope: false},
+		{name: "unknown header falls back to configured format", defaultFormat: FormatEnvelope, header: "xml", wantEnvelope: true},
+		{name: "unknown configured format is standard", defaultFormat: "bogus", wantEnvelope: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performFormatRequest(setupFormatRouter(tt.defaultFormat), tt.header)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Values("Vary"), ResponseFormatHeader)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			data, ok := body["data"].(map[string]any)
+			require.True(t, ok, "data is present in both formats")
+			assert.Equal(t, "widget", data["name"])
+
+			if tt.wantEnvelope {
+				assert.NotContains(t, body, "success")
+				meta, ok := body["meta"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "req-123", meta["request_id"])
+				assert.NotEmpty(t, meta["timestamp"])
+			} else {
+				assert.Equal(t, true, body["success"])
+				assert.NotContains(t, body, "meta")
+			}
+		})
+	}
+}
+
+func TestRespond_WithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/widgets", nil)
+
+	Respond(c, http.StatusCreated, gin.H{"id": 2})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"success":true,"data":{"id":2}}`, w.Body.String())
+}
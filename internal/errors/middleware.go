@@ -2,13 +2,17 @@ package errors
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tracing"
 )
 
 // ErrorHandler returns a Gin middleware that handles errors added to the context via c.Error().
 // It converts APIError types to appropriate JSON responses and wraps unknown errors as internal server errors.
+// GET requests that prefer XML receive the error as an XML document instead.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
@@ -18,51 +22,37 @@ func ErrorHandler() gin.HandlerFunc {
 			requestID, _ := c.Get("request_id")
 			reqID, _ := requestID.(string)
 
-			if rateLimitErr, ok := err.Err.(*RateLimitError); ok {
-				response := Response{
-					Success: false,
-					Error: &ErrorInfo{
-						Code:       rateLimitErr.Code,
-						Message:    rateLimitErr.Message,
-						Details:    rateLimitErr.Details,
-						Timestamp:  time.Now(),
-						Path:       getRequestPath(c),
-						RequestID:  reqID,
-						RetryAfter: &rateLimitErr.RetryAfter,
-					},
-				}
-				c.JSON(rateLimitErr.Status, response)
-				return
+			status := http.StatusInternalServerError
+			info := &ErrorInfo{
+				Code:      CodeInternal,
+				Message:   "Internal server error",
+				Details:   err.Err.Error(),
+				Timestamp: time.Now(),
+				Path:      getRequestPath(c),
+				RequestID: reqID,
+				TraceID:   tracing.TraceID(c.Request.Context()),
 			}
 
-			if apiErr, ok := err.Err.(*APIError); ok {
-				response := Response{
-					Success: false,
-					Error: &ErrorInfo{
-						Code:      apiErr.Code,
-						Message:   apiErr.Message,
-						Details:   apiErr.Details,
-						Timestamp: time.Now(),
-						Path:      getRequestPath(c),
-						RequestID: reqID,
-					},
+			if rateLimitErr, ok := err.Err.(*RateLimitError); ok {
+				status = rateLimitErr.Status
+				info.Code = rateLimitErr.Code
+				info.Message = rateLimitErr.Message
+				info.Details = rateLimitErr.Details
+				if rateLimitErr.RetryAfter > 0 {
+					c.Header("Retry-After", strconv.Itoa(rateLimitErr.RetryAfter))
+					info.RetryAfter = &rateLimitErr.RetryAfter
 				}
-				c.JSON(apiErr.Status, response)
-				return
+			} else if apiErr, ok := err.Err.(*APIError); ok {
+				status = apiErr.Status
+				info.Code = apiErr.Code
+				info.Message = apiErr.Message
+				info.Details = apiErr.Details
 			}
 
-			response := Response{
-				Success: false,
-				Error: &ErrorInfo{
-					Code:      CodeInternal,
-					Message:   "Internal server error",
-					Details:   err.Err.Error(),
-					Timestamp: time.Now(),
-					Path:      getRequestPath(c),
-					RequestID: reqID,
-				},
+			if wantsXML(c) && renderXML(c, status, info) {
+				return
 			}
-			c.JSON(http.StatusInternalServerError, response)
+			c.JSON(status, Response{Success: false, Error: info})
 		}
 	}
 }
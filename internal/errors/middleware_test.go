@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestGetRequestPath(t *testing.T) {
@@ -149,6 +151,24 @@ func TestErrorHandler_WithUnknownError(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Internal server error")
 }
 
+func TestErrorHandler_IncludesTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+	c.Request = httptest.NewRequest("GET", "/test", nil).WithContext(ctx)
+
+	_ = c.Error(NotFound("User not found"))
+
+	ErrorHandler()(c)
+
+	var response Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, span.SpanContext().TraceID().String(), response.Error.TraceID)
+}
+
 func TestErrorHandler_WithNoErrors(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -190,6 +210,7 @@ func TestErrorHandler_RateLimitError(t *testing.T) {
 	ErrorHandler()(c)
 
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
 
 	var response map[string]interface{}
 	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
@@ -201,6 +222,46 @@ func TestErrorHandler_RateLimitError(t *testing.T) {
 	assert.Equal(t, float64(60), errorObj["retry_after"])
 }
 
+func TestErrorHandler_ServiceUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		retryAfter string
+	}{
+		{name: "service unavailable", err: ServiceUnavailable("Service is under maintenance", 120), status: http.StatusServiceUnavailable, code: CodeServiceUnavailable, retryAfter: "120"},
+		{name: "service unavailable without retry after", err: ServiceUnavailable("Service is under maintenance", 0), status: http.StatusServiceUnavailable, code: CodeServiceUnavailable},
+		{name: "not implemented", err: NotImplemented("Exports are not supported yet"), status: http.StatusNotImplemented, code: CodeNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			_ = c.Error(tt.err)
+
+			ErrorHandler()(c)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			errorObj := response["error"].(map[string]interface{})
+			assert.Equal(t, tt.code, errorObj["code"])
+			if tt.retryAfter == "" {
+				assert.NotContains(t, errorObj, "retry_after")
+			} else {
+				assert.Equal(t, float64(120), errorObj["retry_after"])
+			}
+		})
+	}
+}
+
 func TestErrorHandler_ValidationErrorWithDetails(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
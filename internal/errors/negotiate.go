@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"encoding/xml"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// wantsXML reports whether the client prefers XML on a GET request.
+// JSON stays the default for other methods and for wildcard Accept headers.
+func wantsXML(c *gin.Context) bool {
+	if c.Request == nil || c.Request.Method != http.MethodGet {
+		return false
+	}
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEXML, binding.MIMEXML2) {
+	case binding.MIMEXML, binding.MIMEXML2:
+		return true
+	default:
+		return false
+	}
+}
+
+// renderXML writes v as an XML document. It writes nothing and returns false
+// when v cannot be represented as a single XML element, so the caller can fall
+// back to JSON.
+func renderXML(c *gin.Context, status int, v any) bool {
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Struct {
+		return false
+	}
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return false
+	}
+	c.Data(status, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+	return true
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type xmlWidget struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func TestWantsXML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		method string
+		accept string
+		want   bool
+	}{
+		{http.MethodGet, "", false},
+		{http.MethodGet, "*/*", false},
+		{http.MethodGet, "application/json", false},
+		{http.MethodGet, "application/xml", true},
+		{http.MethodGet, "text/xml", true},
+		{http.MethodGet, "application/xml, application/json", true},
+		{http.MethodGet, "application/json, application/xml", false},
+		{http.MethodPost, "application/xml", false},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(tt.method, "/", nil)
+		if tt.accept != "" {
+			c.Request.Header.Set("Accept", tt.accept)
+		}
+		assert.Equal(t, tt.want, wantsXML(c), "%s %q", tt.method, tt.accept)
+	}
+}
+
+func TestRespond_XML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	respond := func(data any) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/widgets/1", nil)
+		c.Request.Header.Set("Accept", "application/xml")
+		Respond(c, http.StatusOK, data)
+		return w
+	}
+
+	t.Run("struct is rendered as xml document", func(t *testing.T) {
+		w := respond(xmlWidget{ID: 1, Name: "widget"})
+
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept")
+
+		var body xmlWidget
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, xmlWidget{ID: 1, Name: "widget"}, body)
+	})
+
+	t.Run("values without a single root fall back to json", func(t *testing.T) {
+		for _, data := range []any{
+			[]xmlWidget{{ID: 1}, {ID: 2}},
+			gin.H{"message": "ok"},
+			struct {
+				Meta map[string]any `xml:"meta"`
+			}{Meta: map[string]any{"a": 1}},
+		} {
+			w := respond(data)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		}
+	})
+}
+
+func TestErrorHandler_XML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.Any("/widgets/1", func(c *gin.Context) {
+		_ = c.Error(NotFound("Widget not found"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/1", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+	assert.Contains(t, w.Body.String(), "<error><code>NOT_FOUND</code><message>Widget not found</message>")
+
+	req = httptest.NewRequest(http.MethodDelete, "/widgets/1", nil)
+	req.Header.Set("Accept", "application/xml")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
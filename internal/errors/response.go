@@ -1,6 +1,9 @@
 package errors
 
-import "time"
+import (
+	"encoding/xml"
+	"time"
+)
 
 // Response wraps all API responses with consistent structure
 type Response struct {
@@ -12,13 +15,26 @@ type Response struct {
 
 // ErrorInfo contains detailed error information
 type ErrorInfo struct {
-	Code       string      `json:"code"`
-	Message    string      `json:"message"`
-	Details    interface{} `json:"details,omitempty"`
-	Timestamp  time.Time   `json:"timestamp"`
-	Path       string      `json:"path,omitempty"`
-	RequestID  string      `json:"request_id,omitempty"`
-	RetryAfter *int        `json:"retry_after,omitempty"`
+	XMLName    xml.Name    `json:"-" xml:"error"`
+	Code       string      `json:"code" xml:"code"`
+	Message    string      `json:"message" xml:"message"`
+	Details    interface{} `json:"details,omitempty" xml:"details,omitempty"`
+	Timestamp  time.Time   `json:"timestamp" xml:"timestamp"`
+	Path       string      `json:"path,omitempty" xml:"path,omitempty"`
+	RequestID  string      `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	TraceID    string      `json:"trace_id,omitempty" xml:"trace_id,omitempty"`
+	RetryAfter *int        `json:"retry_after,omitempty" xml:"retry_after,omitempty"`
+}
+
+// EmailConflictInfo is the error of a 409 for an email that is already in
+// use. It only exists to give the API docs an example.
+type EmailConflictInfo struct {
+	Code      string            `json:"code" example:"CONFLICT"`
+	Message   string            `json:"message" example:"Email already in use"`
+	Details   map[string]string `json:"details" example:"email:already in use"`
+	Timestamp time.Time         `json:"timestamp"`
+	Path      string            `json:"path,omitempty" example:"/api/v1/auth/register"`
+	RequestID string            `json:"request_id,omitempty"`
 }
 
 // Meta contains response metadata for pagination and tracking
@@ -0,0 +1,201 @@
+package errors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var schemaPrinter = message.NewPrinter(language.English)
+
+// Schema is a compiled JSON Schema that request bodies can be checked against
+// on top of struct-tag validation, for rules tags cannot express such as
+// additionalProperties or conditions across fields.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// CompileSchema compiles a JSON Schema document; name identifies it in errors.
+// Formats such as "email" are asserted, not just annotated.
+func CompileSchema(name string, source []byte) (*Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("parse schema %s: %w", name, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	if err := compiler.AddResource(name, doc); err != nil {
+		return nil, fmt.Errorf("add schema %s: %w", name, err)
+	}
+	schema, err := compiler.Compile(name)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema %s: %w", name, err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// MustCompileSchema is CompileSchema for schemas declared in package
+// variables; it panics on an invalid schema.
+func MustCompileSchema(name string, source []byte) *Schema {
+	schema, err := CompileSchema(name, source)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// BindJSONWithSchema is BindJSON, but also checks the body against schema.
+// Violations and struct-tag failures alike are keyed by the JSON path of the
+// offending value, like unknown fields in strict mode, and reported as one
+// validation error. additionalProperties follows the strict JSON setting: extra
+// fields are only rejected in strict mode.
+func BindJSONWithSchema(c *gin.Context, obj any, schema *Schema) *APIError {
+	return bindJSON(c, obj, schema)
+}
+
+// validate returns the schema violations of body, or nil when it conforms.
+// Outside strict mode additionalProperties violations are ignored.
+func (s *Schema) validate(body []byte, strict bool) (FieldErrors, *APIError) {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return nil, malformedJSON(err.Error())
+	}
+
+	err = s.schema.Validate(inst)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !stderrors.As(err, &validationErr) {
+		return nil, ServerError(err)
+	}
+
+	details := FieldErrors{}
+	collectSchemaErrors(validationErr, details, strict)
+	if len(details) == 0 {
+		return nil, nil
+	}
+	return details, nil
+}
+
+// collectSchemaErrors adds the leaf violations of err to details
+func collectSchemaErrors(err *jsonschema.ValidationError, details FieldErrors, strict bool) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			collectSchemaErrors(cause, details, strict)
+		}
+		return
+	}
+
+	path := schemaPath(err.InstanceLocation)
+	switch k := err.ErrorKind.(type) {
+	case *kind.Required:
+		for _, field := range k.Missing {
+			details[joinPath(path, field)] = joinPath(path, field) + " is required"
+		}
+	case *kind.AdditionalProperties:
+		if !strict {
+			return
+		}
+		for _, field := range k.Properties {
+			details[joinPath(path, field)] = joinPath(path, field) + " is not a known field"
+		}
+	default:
+		if path == "" {
+			path = "body"
+		}
+		details[path] = path + " is invalid: " + k.LocalizedString(schemaPrinter)
+	}
+}
+
+// schemaPath renders a JSON pointer's tokens like the paths of unknown fields,
+// e.g. address.zip or items[1].qty
+func schemaPath(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil {
+			sb.WriteString("[" + token + "]")
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(token)
+	}
+	return sb.String()
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// FromJSONValidation is FromGinValidation, but keys each struct-tag failure of
+// obj by the JSON path of the field, e.g. items[0].name, so its details match
+// the schema's
+func FromJSONValidation(err error, obj any) *APIError {
+	var validationErrs validator.ValidationErrors
+	if !stderrors.As(err, &validationErrs) {
+		return FromGinValidation(err)
+	}
+
+	details := make(map[string]string, len(validationErrs))
+	for _, fe := range validationErrs {
+		path := jsonPath(reflect.TypeOf(obj), fe.StructNamespace())
+		details[path] = path + strings.TrimPrefix(formatValidationError(fe), fe.Field())
+	}
+	return ValidationError(details)
+}
+
+// jsonPath translates a validator struct namespace such as
+// Order.Items[0].Name into the JSON path of that field in type t
+func jsonPath(t reflect.Type, namespace string) string {
+	// WHY: The first segment is the name of the validated struct itself
+	_, namespace, _ = strings.Cut(namespace, ".")
+
+	var sb strings.Builder
+	for _, segment := range strings.Split(namespace, ".") {
+		name, index, _ := strings.Cut(segment, "[")
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		jsonName := name
+		if t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+					jsonName = tag
+				}
+				t = field.Type
+			}
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(jsonName)
+
+		if index != "" {
+			sb.WriteString("[" + index)
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const widgetSchema = `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "maxLength": 5},
+    "count": {"type": "integer", "minimum": 0},
+    "tags": {"type": "array", "items": {"type": "object", "required": ["label"], "additionalProperties": false, "properties": {"label": {"type": "string"}}}}
+  },
+  "additionalProperties": false
+}`
+
+func performSchemaBind(t *testing.T, strict bool, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	schema := MustCompileSchema("widget.json", []byte(widgetSchema))
+
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.Use(JSONDecoding(JSONDecodingConfig{Strict: strict}))
+	r.POST("/widgets", func(c *gin.Context) {
+		var req bindTarget
+		if err := BindJSONWithSchema(c, &req, schema); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBindJSONWithSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		strict      bool
+		body        string
+		wantDetails map[string]any
+	}{
+		{
+			name:        "extra field is rejected in strict mode",
+			strict:      true,
+			body:        `{"name":"a","extra":true}`,
+			wantDetails: map[string]any{"extra": "extra is not a known field"},
+		},
+		{
+			name:   "violations are keyed by path",
+			strict: true,
+			body:   `{"name":"too long","count":-1,"tags":[{"label":"a"},{"colour":"red"}]}`,
+			wantDetails: map[string]any{
+				"name":           "name is invalid: maxLength: got 8, want 5",
+				"count":          "count is invalid: minimum: got -1, want 0",
+				"tags[1].label":  "tags[1].label is required",
+				"tags[1].colour": "tags[1].colour is not a known field",
+			},
+		},
+		{
+			name:   "merged with struct validation under JSON names",
+			strict: true,
+			body:   `{"nmae":"a"}`,
+			wantDetails: map[string]any{
+				"nmae": "nmae is not a known field",
+				"name": "name is required",
+			},
+		},
+		{
+			name:        "wrong type reported by the schema",
+			body:        `{"name":"a","count":"two"}`,
+			wantDetails: map[string]any{"count": "count is invalid: got string, want integer"},
+		},
+		{
+			name:        "root violation",
+			body:        `[1]`,
+			wantDetails: map[string]any{"body": "body is invalid: got array, want object"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performSchemaBind(t, tt.strict, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			errorInfo := decodeErrorInfo(t, w)
+			assert.Equal(t, CodeValidation, errorInfo["code"])
+			assert.Equal(t, "Validation failed", errorInfo["message"])
+			assert.Equal(t, tt.wantDetails, errorInfo["details"])
+		})
+	}
+
+	t.Run("extra field is accepted outside strict mode", func(t *testing.T) {
+		w := performSchemaBind(t, false, `{"name":"a","extra":true,"tags":[{"label":"x","colour":"red"}]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("valid body", func(t *testing.T) {
+		w := performSchemaBind(t, false, `{"name":"a","count":2,"tags":[{"label":"x"}]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		w := performSchemaBind(t, false, `{"name":`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "malformed JSON", decodeErrorInfo(t, w)["message"])
+	})
+}
+
+func TestJSONPath(t *testing.T) {
+	type item struct {
+		Label string `json:"label"`
+	}
+	type order struct {
+		Name  string  `json:"name,omitempty"`
+		Items []*item `json:"items"`
+		Note  string
+	}
+
+	typ := reflect.TypeFor[order]()
+	assert.Equal(t, "name", jsonPath(typ, "order.Name"))
+	assert.Equal(t, "items[1].label", jsonPath(typ, "order.Items[1].Label"))
+	assert.Equal(t, "Note", jsonPath(typ, "order.Note"), "fields without a JSON name keep theirs")
+}
+
+func TestCompileSchema_Invalid(t *testing.T) {
+	_, err := CompileSchema("broken.json", []byte(`{"type": 5}`))
+	require.Error(t, err)
+
+	_, err = CompileSchema("broken.json", []byte(`{`))
+	require.Error(t, err)
+}
@@ -0,0 +1,188 @@
+// Package events is an in-process publish/subscribe bus for notifications
+// addressed to a single user, e.g. to push them over a WebSocket, and for
+// feeds of every event, e.g. for admin dashboards.
+package events
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	// TypeSessionRevoked is published when an admin revokes a user's sessions
+	TypeSessionRevoked = "session.revoked"
+	// TypeUserUpdated is published when a user's profile changes
+	TypeUserUpdated = "user.updated"
+	// TypeUserCreated is delivered through the outbox once a user is created
+	TypeUserCreated = "user.created"
+	// TypeUserRegistered is published when a user signs up
+	TypeUserRegistered = "user.registered"
+	// TypeAuditRecorded is published when an admin action is audited
+	TypeAuditRecorded = "audit.recorded"
+)
+
+// DefaultBufferSize is how many undelivered events a subscriber may queue
+const DefaultBufferSize = 16
+
+// HistorySize is how many recent events the bus keeps to replay to feeds
+// that resume after a reconnect
+const HistorySize = 128
+
+// Event is a notification for one user
+type Event struct {
+	// ID is the sequence number the bus assigns on publish
+	ID uint64 `json:"-"`
+	// TenantID is the tenant of UserID; feeds only pass on events of their own tenant
+	TenantID   string    `json:"-"`
+	Type       string    `json:"type"`
+	UserID     uint      `json:"user_id"`
+	Data       any       `json:"data,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Bus fans events out to the subscribers of the target user. A nil *Bus
+// drops every event, so publishers can use it unconditionally.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[chan Event]struct{}
+	feeds       map[chan Event]struct{}
+	bufferSize  int
+
+	// history is a ring of the last HistorySize events, oldest at next once full
+	history []Event
+	next    int
+	lastID  uint64
+}
+
+// NewBus creates an empty bus. A non-positive bufferSize uses DefaultBufferSize.
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Bus{
+		subscribers: make(map[uint]map[chan Event]struct{}),
+		feeds:       make(map[chan Event]struct{}),
+		bufferSize:  bufferSize,
+		history:     make([]Event, 0, HistorySize),
+	}
+}
+
+// Subscribe returns a channel receiving the events published for userID and
+// a function that unsubscribes and closes the channel.
+func (b *Bus) Subscribe(userID uint) (<-chan Event, func()) {
+	ch := make(chan Event, b.bufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// SubscribeAll returns the events still in the history whose ID is greater
+// than afterID, a channel receiving every event published from then on and a
+// function that unsubscribes and closes the channel. An afterID of zero
+// replays nothing.
+func (b *Bus) SubscribeAll(afterID uint64) ([]Event, <-chan Event, func()) {
+	ch := make(chan Event, b.bufferSize)
+
+	// WHY: Replaying and subscribing under one lock means no event is missed or sent twice in between
+	b.mu.Lock()
+	var replay []Event
+	if afterID > 0 {
+		for _, event := range b.recent() {
+			if event.ID > afterID {
+				replay = append(replay, event)
+			}
+		}
+	}
+	b.feeds[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.feeds, ch)
+			close(ch)
+		})
+	}
+	return replay, ch, unsubscribe
+}
+
+// recent returns the history oldest first; it must be called with mu held
+func (b *Bus) recent() []Event {
+	return append(slices.Clone(b.history[b.next:]), b.history[:b.next]...)
+}
+
+// Publish assigns event the next ID and delivers it to every subscriber of
+// event.UserID and every feed without blocking. Subscribers whose buffer is
+// full miss the event.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event.ID = b.lastID
+	if len(b.history) < HistorySize {
+		b.history = append(b.history, event)
+	} else {
+		b.history[b.next] = event
+		b.next = (b.next + 1) % HistorySize
+	}
+
+	for ch := range b.subscribers[event.UserID] {
+		deliver(ch, event)
+	}
+	for ch := range b.feeds {
+		deliver(ch, event)
+	}
+}
+
+func deliver(ch chan Event, event Event) {
+	select {
+	case ch <- event:
+	default:
+		// WHY: A slow client must not block the request that published the event
+		slog.Warn("Dropped event for slow subscriber", "type", event.Type, "user_id", event.UserID)
+	}
+}
+
+// Subscribers returns how many subscriptions userID has
+func (b *Bus) Subscribers(userID uint) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[userID])
+}
+
+// Feeds returns how many SubscribeAll subscriptions there are
+func (b *Bus) Feeds() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.feeds)
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishToSubscriber(t *testing.T) {
+	bus := NewBus(0)
+	ch, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	bus.Publish(Event{Type: TypeUserUpdated, UserID: 2})
+	bus.Publish(Event{Type: TypeSessionRevoked, UserID: 1, Data: map[string]any{"revoked_refresh_tokens": 3}})
+
+	select {
+	case event := <-ch:
+		assert.Equal(t, TypeSessionRevoked, event.Type)
+		assert.Equal(t, uint(1), event.UserID)
+		assert.False(t, event.OccurredAt.IsZero())
+	default:
+		t.Fatal("expected an event for user 1")
+	}
+
+	select {
+	case event := <-ch:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestBus_MultipleSubscribers(t *testing.T) {
+	bus := NewBus(0)
+	first, unsubscribeFirst := bus.Subscribe(1)
+	second, unsubscribeSecond := bus.Subscribe(1)
+	defer unsubscribeSecond()
+	assert.Equal(t, 2, bus.Subscribers(1))
+
+	bus.Publish(Event{Type: TypeUserUpdated, UserID: 1})
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 1)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	assert.Equal(t, 1, bus.Subscribers(1))
+
+	<-first
+	_, ok := <-first
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+}
+
+func TestBus_DropsWhenBufferFull(t *testing.T) {
+	bus := NewBus(1)
+	ch, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	bus.Publish(Event{Type: TypeUserUpdated, UserID: 1})
+	bus.Publish(Event{Type: TypeSessionRevoked, UserID: 1})
+
+	require.Len(t, ch, 1)
+	assert.Equal(t, TypeUserUpdated, (<-ch).Type)
+}
+
+func TestBus_NilIsNoop(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() {
+		bus.Publish(Event{Type: TypeUserUpdated, UserID: 1})
+	})
+}
+
+func TestBus_SubscribeAll(t *testing.T) {
+	bus := NewBus(0)
+	_, feed, unsubscribe := bus.SubscribeAll(0)
+	assert.Equal(t, 1, bus.Feeds())
+
+	bus.Publish(Event{Type: TypeUserRegistered, UserID: 1})
+	bus.Publish(Event{Type: TypeUserUpdated, UserID: 2})
+
+	require.Len(t, feed, 2)
+	first, second := <-feed, <-feed
+	assert.Equal(t, TypeUserRegistered, first.Type)
+	assert.Equal(t, uint64(1), first.ID)
+	assert.Equal(t, uint64(2), second.ID)
+
+	unsubscribe()
+	assert.Zero(t, bus.Feeds())
+}
+
+func TestBus_SubscribeAllReplaysHistory(t *testing.T) {
+	bus := NewBus(0)
+	for range HistorySize + 2 {
+		bus.Publish(Event{Type: TypeUserUpdated, UserID: 1})
+	}
+
+	t.Run("after id", func(t *testing.T) {
+		replay, _, unsubscribe := bus.SubscribeAll(HistorySize)
+		defer unsubscribe()
+
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint64(HistorySize+1), replay[0].ID)
+		assert.Equal(t, uint64(HistorySize+2), replay[1].ID)
+	})
+
+	t.Run("id older than the history", func(t *testing.T) {
+		replay, _, unsubscribe := bus.SubscribeAll(1)
+		defer unsubscribe()
+
+		require.Len(t, replay, HistorySize)
+		assert.Equal(t, uint64(3), replay[0].ID)
+	})
+
+	t.Run("no id", func(t *testing.T) {
+		replay, _, unsubscribe := bus.SubscribeAll(0)
+		defer unsubscribe()
+
+		assert.Empty(t, replay)
+	})
+}
@@ -0,0 +1,86 @@
+package featureflags
+
+import (
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// Names of the flags gating the optional features
+const (
+	GraphQL   = "graphql"
+	OAuth     = "oauth"
+	WebSocket = "websocket"
+)
+
+// Flags holds the runtime feature flags shared by the gated routes and the admin handler.
+// Names are case-insensitive and unknown flags are disabled.
+type Flags struct {
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+// New creates flags with the given initial values.
+func New(values map[string]bool) *Flags {
+	f := &Flags{}
+	f.replace(values)
+	return f
+}
+
+// Load creates flags from the configuration.
+func Load(cfg *config.Config) *Flags {
+	return New(Values(cfg))
+}
+
+// Values resolves the flags of the configuration: the enabled field of each
+// optional feature, overridden by the features section.
+func Values(cfg *config.Config) map[string]bool {
+	values := map[string]bool{
+		GraphQL:   cfg.GraphQL.Enabled,
+		OAuth:     cfg.OAuth.Google.Enabled,
+		WebSocket: cfg.WebSocket.Enabled,
+	}
+	for name, enabled := range cfg.Features {
+		values[strings.ToLower(name)] = enabled
+	}
+	return values
+}
+
+// Reload replaces all flags with those of the configuration.
+func (f *Flags) Reload(cfg *config.Config) {
+	f.replace(Values(cfg))
+}
+
+// IsEnabled reports whether the named flag is on.
+func (f *Flags) IsEnabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.values[strings.ToLower(name)]
+}
+
+// Set switches the named flag on or off.
+func (f *Flags) Set(name string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values[strings.ToLower(name)] = enabled
+}
+
+// All returns a copy of every flag and its state.
+func (f *Flags) All() map[string]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return maps.Clone(f.values)
+}
+
+func (f *Flags) replace(values map[string]bool) {
+	normalized := make(map[string]bool, len(values))
+	for name, enabled := range values {
+		normalized[strings.ToLower(name)] = enabled
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values = normalized
+}
@@ -0,0 +1,61 @@
+package featureflags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+func TestFlags_EnableDisable(t *testing.T) {
+	flags := New(map[string]bool{"Beta_Search": true})
+
+	assert.True(t, flags.IsEnabled("beta_search"))
+	assert.True(t, flags.IsEnabled("BETA_SEARCH"))
+	assert.False(t, flags.IsEnabled("unknown"), "unknown flags are disabled")
+
+	flags.Set("beta_search", false)
+	assert.False(t, flags.IsEnabled("beta_search"))
+
+	flags.Set("unknown", true)
+	assert.True(t, flags.IsEnabled("unknown"))
+}
+
+func TestValues(t *testing.T) {
+	cfg := config.NewTestConfig()
+	cfg.GraphQL.Enabled = true
+	cfg.WebSocket.Enabled = true
+	cfg.Features = map[string]bool{"websocket": false, "Beta_Search": true}
+
+	assert.Equal(t, map[string]bool{
+		GraphQL:       true,
+		OAuth:         false,
+		WebSocket:     false,
+		"beta_search": true,
+	}, Values(cfg))
+}
+
+func TestFlags_Reload(t *testing.T) {
+	cfg := config.NewTestConfig()
+	cfg.Features = map[string]bool{"beta_search": true}
+	flags := Load(cfg)
+	flags.Set(GraphQL, true)
+
+	reloaded := config.NewTestConfig()
+	reloaded.OAuth.Google.Enabled = true
+	flags.Reload(reloaded)
+
+	assert.True(t, flags.IsEnabled(OAuth))
+	assert.False(t, flags.IsEnabled(GraphQL), "runtime changes are replaced by the configuration")
+	assert.False(t, flags.IsEnabled("beta_search"), "flags removed from the configuration are dropped")
+}
+
+func TestFlags_AllReturnsCopy(t *testing.T) {
+	flags := New(map[string]bool{GraphQL: true})
+
+	all := flags.All()
+	all[GraphQL] = false
+
+	assert.True(t, flags.IsEnabled(GraphQL))
+}
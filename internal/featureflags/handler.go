@@ -0,0 +1,37 @@
+package featureflags
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// ListResponse represents the current feature flags
+type ListResponse struct {
+	Flags map[string]bool `json:"flags"`
+}
+
+// Handler exposes the admin endpoint for inspecting feature flags
+type Handler struct {
+	flags *Flags
+}
+
+// NewHandler creates a new feature flag handler
+func NewHandler(flags *Flags) *Handler {
+	return &Handler{flags: flags}
+}
+
+// List godoc
+// @Summary List feature flags (Admin only)
+// @Description Report every feature flag and whether it is enabled; flags are reloaded from the configuration on SIGHUP
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} errors.Response{success=bool,data=ListResponse} "Current feature flags"
+// @Failure 403 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Admin access required"
+// @Router /api/v1/admin/flags [get]
+func (h *Handler) List(c *gin.Context) {
+	apiErrors.Respond(c, http.StatusOK, ListResponse{Flags: h.flags.All()})
+}
@@ -0,0 +1,65 @@
+package featureflags
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+func setupHandlerRouter(flags *Flags) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.GET("/admin/flags", NewHandler(flags).List)
+	router.GET("/beta", Require(flags, "beta"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return router
+}
+
+func TestHandler_List(t *testing.T) {
+	flags := New(map[string]bool{GraphQL: true, OAuth: false})
+	router := setupHandlerRouter(flags)
+
+	list := func() map[string]bool {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/flags", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Success bool         `json:"success"`
+			Data    ListResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		return resp.Data.Flags
+	}
+
+	assert.Equal(t, map[string]bool{GraphQL: true, OAuth: false}, list())
+
+	flags.Set(OAuth, true)
+	assert.Equal(t, map[string]bool{GraphQL: true, OAuth: true}, list())
+}
+
+func TestRequire(t *testing.T) {
+	flags := New(map[string]bool{"beta": true})
+	router := setupHandlerRouter(flags)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beta", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	flags.Set("beta", false)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beta", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "No route matches GET /beta")
+}
@@ -0,0 +1,22 @@
+package featureflags
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// Require answers 404 while the named flag is off, so a disabled feature looks
+// as if it was never mounted.
+func Require(flags *Flags, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.IsEnabled(name) {
+			_ = c.Error(apiErrors.NotFound(fmt.Sprintf("No route matches %s %s", c.Request.Method, c.Request.URL.Path)))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
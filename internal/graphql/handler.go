@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+// Request is a GraphQL request body
+type Request struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler serves GraphQL queries
+type Handler struct {
+	schema gql.Schema
+}
+
+// NewHandler creates a GraphQL handler with the user schema
+func NewHandler(userService user.Service) (*Handler, error) {
+	schema, err := NewSchema(userService)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema}, nil
+}
+
+// Serve executes a GraphQL query
+// @Summary Execute a GraphQL query
+// @Description Run me, user(id) and users(filter, page, perPage) queries. Errors are reported in the GraphQL errors array with the API error code in extensions.code (requires authentication)
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body Request true "GraphQL request"
+// @Security BearerAuth
+// @Success 200 {object} object "GraphQL result with data and errors"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Malformed request"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Router /graphql [post]
+func (h *Handler) Serve(c *gin.Context) {
+	var req Request
+	if err := apiErrors.BindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	result := gql.Do(gql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        withGinContext(c.Request.Context(), c),
+	})
+
+	// WHY: GraphQL clients expect the spec's {data, errors} body with 200, not the API envelope
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// setupTestRouter serves the GraphQL handler behind a stub auth middleware that
+// trusts the claims passed in by the test
+func setupTestRouter(t *testing.T) (*gin.Engine, user.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	userService := user.NewService(user.NewRepository(testutil.NewSQLiteDB(t)))
+	handler, err := NewHandler(userService)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.POST("/graphql", func(c *gin.Context) {
+		if claims, ok := c.Request.Context().Value(claimsKey{}).(*auth.Claims); ok {
+			c.Set(auth.KeyUser, claims)
+		}
+	}, handler.Serve)
+
+	return router, userService
+}
+
+type claimsKey struct{}
+
+func execute(t *testing.T, router http.Handler, claims *auth.Claims, query string, variables map[string]any) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(Request{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if claims != nil {
+		req = req.WithContext(context.WithValue(req.Context(), claimsKey{}, claims))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func errorCode(resp graphQLResponse) string {
+	if len(resp.Errors) == 0 {
+		return ""
+	}
+	code, _ := resp.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestHandler_Queries(t *testing.T) {
+	router, userService := setupTestRouter(t)
+	ctx := context.Background()
+
+	alice, err := userService.RegisterUser(ctx, user.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	bob, err := userService.RegisterUser(ctx, user.RegisterRequest{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+	admin, err := userService.RegisterUser(ctx, user.RegisterRequest{Name: "Admin", Email: "admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, userService.PromoteToAdmin(ctx, admin.ID))
+
+	aliceClaims := &auth.Claims{UserID: alice.ID, Email: alice.Email, Roles: []string{user.RoleUser}}
+	adminClaims := &auth.Claims{UserID: admin.ID, Email: admin.Email, Roles: []string{user.RoleUser, user.RoleAdmin}}
+
+	t.Run("me returns the caller", func(t *testing.T) {
+		resp := execute(t, router, aliceClaims, `{ me { id name email roles } }`, nil)
+
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"id":"1","name":"Alice","email":"alice@example.com","roles":["user"]}`, string(resp.Data["me"]))
+	})
+
+	t.Run("unauthenticated query is rejected", func(t *testing.T) {
+		resp := execute(t, router, nil, `{ me { id } }`, nil)
+
+		assert.Equal(t, apiErrors.CodeUnauthorized, errorCode(resp))
+	})
+
+	t.Run("user can read own record", func(t *testing.T) {
+		resp := execute(t, router, aliceClaims, `query($id: ID!) { user(id: $id) { email } }`, map[string]any{"id": "1"})
+
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"email":"alice@example.com"}`, string(resp.Data["user"]))
+	})
+
+	t.Run("user cannot read another user", func(t *testing.T) {
+		resp := execute(t, router, aliceClaims, `query($id: ID!) { user(id: $id) { email } }`, map[string]any{"id": "2"})
+
+		assert.Equal(t, apiErrors.CodeForbidden, errorCode(resp))
+		assert.Equal(t, "null", string(resp.Data["user"]))
+	})
+
+	t.Run("admin can read any user", func(t *testing.T) {
+		resp := execute(t, router, adminClaims, `{ user(id: "2") { name } }`, nil)
+
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"name":"Bob"}`, string(resp.Data["user"]))
+	})
+
+	t.Run("unknown user is not found", func(t *testing.T) {
+		resp := execute(t, router, adminClaims, `{ user(id: "99") { name } }`, nil)
+
+		assert.Equal(t, apiErrors.CodeNotFound, errorCode(resp))
+	})
+
+	t.Run("users requires admin", func(t *testing.T) {
+		resp := execute(t, router, aliceClaims, `{ users { total } }`, nil)
+
+		assert.Equal(t, apiErrors.CodeForbidden, errorCode(resp))
+	})
+
+	t.Run("admin lists users with filter and pagination", func(t *testing.T) {
+		resp := execute(t, router, adminClaims,
+			`{ users(filter: {role: "user", sort: "name", order: "asc"}, page: 1, perPage: 2) { total page perPage totalPages users { name } } }`, nil)
+
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"total":3,"page":1,"perPage":2,"totalPages":2,"users":[{"name":"Admin"},{"name":"Alice"}]}`, string(resp.Data["users"]))
+	})
+
+	t.Run("admin search", func(t *testing.T) {
+		resp := execute(t, router, adminClaims, `{ users(filter: {search: "bob"}) { total users { id } } }`, nil)
+
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"total":1,"users":[{"id":"`+jsonID(bob.ID)+`"}]}`, string(resp.Data["users"]))
+	})
+
+	t.Run("invalid sort is a validation error", func(t *testing.T) {
+		resp := execute(t, router, adminClaims, `{ users(filter: {sort: "password_hash"}) { total } }`, nil)
+
+		assert.Equal(t, apiErrors.CodeValidation, errorCode(resp))
+	})
+}
+
+func TestHandler_MalformedRequest(t *testing.T) {
+	router, _ := setupTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(`{"variables":{}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func jsonID(id uint) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
@@ -0,0 +1,239 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+type ginContextKey struct{}
+
+// withGinContext makes the Gin context, and with it the authenticated claims,
+// available to resolvers
+func withGinContext(ctx context.Context, c *gin.Context) context.Context {
+	return context.WithValue(ctx, ginContextKey{}, c)
+}
+
+func ginContext(ctx context.Context) (*gin.Context, error) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	if !ok || !contextutil.IsAuthenticated(c) {
+		return nil, resolverError{apiErrors.Unauthorized("User not authenticated")}
+	}
+	return c, nil
+}
+
+// resolverError exposes the API error code to clients as the "code" extension
+type resolverError struct {
+	*apiErrors.APIError
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+// NewSchema builds the read-only user schema backed by userService. Resolvers
+// apply the same authorization rules as the REST handlers: me requires
+// authentication, user(id) is limited to the caller or an admin and users is
+// admin only.
+func NewSchema(userService user.Service) (gql.Schema, error) {
+	r := &resolver{userService: userService}
+
+	userType := gql.NewObject(gql.ObjectConfig{
+		Name: "User",
+		Fields: gql.Fields{
+			"id":           &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"name":         &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"email":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"pendingEmail": &gql.Field{Type: gql.String},
+			"roles":        &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String)))},
+			"createdAt":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"updatedAt":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+		},
+	})
+
+	userListType := gql.NewObject(gql.ObjectConfig{
+		Name: "UserList",
+		Fields: gql.Fields{
+			"users":      &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(userType)))},
+			"total":      &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"page":       &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"perPage":    &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"totalPages": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	userFilterType := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "UserFilter",
+		Fields: gql.InputObjectConfigFieldMap{
+			"role":   &gql.InputObjectFieldConfig{Type: gql.String, Description: "Filter by role (user or admin)"},
+			"search": &gql.InputObjectFieldConfig{Type: gql.String, Description: "Search by name or email"},
+			"sort":   &gql.InputObjectFieldConfig{Type: gql.String, Description: "created_at, updated_at, name, email or id"},
+			"order":  &gql.InputObjectFieldConfig{Type: gql.String, Description: "asc or desc"},
+		},
+	})
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"me": &gql.Field{
+				Type:    gql.NewNonNull(userType),
+				Resolve: r.me,
+			},
+			"user": &gql.Field{
+				Type: userType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: r.user,
+			},
+			"users": &gql.Field{
+				Type: gql.NewNonNull(userListType),
+				Args: gql.FieldConfigArgument{
+					"filter":  &gql.ArgumentConfig{Type: userFilterType},
+					"page":    &gql.ArgumentConfig{Type: gql.Int, DefaultValue: middleware.DefaultPage},
+					"perPage": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: middleware.DefaultPerPage},
+				},
+				Resolve: r.users,
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+type resolver struct {
+	userService user.Service
+}
+
+func (r *resolver) me(p gql.ResolveParams) (any, error) {
+	c, err := ginContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := r.userService.GetUserByID(p.Context, contextutil.GetUserID(c))
+	if err != nil {
+		return nil, serviceError(p.Context, err)
+	}
+	return userFields(u), nil
+}
+
+func (r *resolver) user(p gql.ResolveParams) (any, error) {
+	c, err := ginContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	idArg, _ := p.Args["id"].(string)
+	id, err := db.ParseID(idArg)
+	if errors.Is(err, db.ErrIDOutOfRange) {
+		return nil, resolverError{apiErrors.BadRequest(fmt.Sprintf("User ID must be at most %d", db.MaxID))}
+	}
+	if err != nil {
+		return nil, resolverError{apiErrors.BadRequest("Invalid user ID")}
+	}
+	if !contextutil.CanAccessUser(c, id) {
+		return nil, resolverError{apiErrors.Forbidden("Forbidden user ID")}
+	}
+
+	u, err := r.userService.GetUserByID(p.Context, id)
+	if err != nil {
+		return nil, serviceError(p.Context, err)
+	}
+	return userFields(u), nil
+}
+
+func (r *resolver) users(p gql.ResolveParams) (any, error) {
+	c, err := ginContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	if !contextutil.IsAdmin(c) {
+		return nil, resolverError{apiErrors.Forbidden("Admin access required")}
+	}
+
+	page, _ := p.Args["page"].(int)
+	if page < 1 {
+		page = middleware.DefaultPage
+	}
+	perPage, _ := p.Args["perPage"].(int)
+	if perPage < 1 {
+		perPage = middleware.DefaultPerPage
+	}
+	perPage = min(perPage, middleware.MaxPerPage)
+
+	filter, _ := p.Args["filter"].(map[string]any)
+	stringArg := func(name string) string {
+		s, _ := filter[name].(string)
+		return s
+	}
+	filters := user.NewUserFilterParams(stringArg("role"), stringArg("search"), stringArg("sort"), stringArg("order"))
+
+	users, total, err := r.userService.ListUsers(p.Context, filters, page, perPage)
+	if err != nil {
+		return nil, serviceError(p.Context, err)
+	}
+
+	items := make([]map[string]any, len(users))
+	for i := range users {
+		items[i] = userFields(&users[i])
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage > 0 {
+		totalPages++
+	}
+
+	return map[string]any{
+		"users":      items,
+		"total":      int(total),
+		"page":       page,
+		"perPage":    perPage,
+		"totalPages": totalPages,
+	}, nil
+}
+
+// userFields maps a user onto the GraphQL User type using the REST response representation
+func userFields(u *user.User) map[string]any {
+	resp := user.ToUserResponse(u)
+	fields := map[string]any{
+		"id":        strconv.FormatUint(uint64(resp.ID), 10),
+		"name":      resp.Name,
+		"email":     resp.Email,
+		"roles":     resp.Roles,
+		"createdAt": resp.CreatedAt,
+		"updatedAt": resp.UpdatedAt,
+	}
+	if resp.PendingEmail != "" {
+		fields["pendingEmail"] = resp.PendingEmail
+	}
+	return fields
+}
+
+// serviceError maps user service errors to resolver errors
+func serviceError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return resolverError{apiErrors.NotFound("User not found")}
+	case errors.Is(err, user.ErrInvalidRole):
+		return resolverError{apiErrors.BadRequest("Invalid role filter")}
+	case errors.Is(err, user.ErrInvalidSort):
+		return resolverError{apiErrors.BadRequest("Invalid sort parameters: sort must be one of created_at, updated_at, name, email, id and order must be asc or desc")}
+	default:
+		// WHY: GraphQL errors only carry the message, so log the cause here
+		slog.ErrorContext(ctx, "GraphQL resolver failed", "error", err)
+		return resolverError{apiErrors.InternalServerError(err)}
+	}
+}
@@ -0,0 +1,86 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+type claimsKey struct{}
+
+// claimsFromContext returns the claims stored by UnaryAuthInterceptor, or nil
+func claimsFromContext(ctx context.Context) *auth.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	return claims
+}
+
+// UnaryTenantInterceptor puts the tenant named by the "x-tenant-id" metadata
+// into the context, the way middleware.TenantMiddleware reads the X-Tenant-ID
+// header. Calls naming none use tenant.Default, and an invalid tenant ID is
+// rejected. UnaryAuthInterceptor replaces it with the tenant of the access
+// token, so it only decides the tenant of public methods.
+func UnaryTenantInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var id string
+		if values := md.Get(strings.ToLower(tenant.Header)); len(values) > 0 {
+			id = strings.ToLower(values[0])
+		}
+
+		if id != tenant.Default && !tenant.ValidID(id) {
+			return nil, toStatus(ctx, apiErrors.BadRequest("Invalid tenant ID"))
+		}
+
+		return handler(tenant.NewContext(ctx, id), req)
+	}
+}
+
+// UnaryAuthInterceptor validates the bearer token in the "authorization"
+// metadata the same way auth.AuthMiddleware validates the HTTP header, and
+// runs the call in the tenant of the token. Methods listed in public (full
+// method names) are served without a token.
+func UnaryAuthInterceptor(authService auth.Service, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if slices.Contains(public, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(strings.ToLower(auth.AuthorizationHeader))
+		if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			return nil, toStatus(ctx, apiErrors.Unauthorized("Authorization metadata required"))
+		}
+
+		fields := strings.Fields(values[0])
+		if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+			return nil, toStatus(ctx, apiErrors.Unauthorized("Invalid authorization metadata format"))
+		}
+
+		claims, err := authService.ValidateToken(fields[1])
+		if err != nil {
+			if errors.Is(err, auth.ErrExpiredToken) {
+				return nil, toStatus(ctx, apiErrors.TokenExpired("Access token has expired"))
+			}
+			return nil, toStatus(ctx, apiErrors.Unauthorized("Invalid access token"))
+		}
+
+		if err := authService.CheckUserStatus(ctx, claims.UserID); err != nil {
+			if errors.Is(err, auth.ErrUserInactive) {
+				return nil, toStatus(ctx, apiErrors.Unauthorized("User account is no longer active"))
+			}
+			return nil, toStatus(ctx, apiErrors.ServerError(err))
+		}
+
+		// WHY: The tenant comes from the signed token, so metadata cannot reach another tenant's users
+		ctx = tenant.NewContext(ctx, claims.TenantID)
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
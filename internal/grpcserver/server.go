@@ -0,0 +1,275 @@
+// Package grpcserver exposes the user API over gRPC so internal services can
+// skip the HTTP/JSON layer. It applies the same validation, authorization and
+// error mapping as the REST handlers.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "github.com/vahiiiid/go-rest-api-boilerplate/api/proto/user/v1"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+// publicMethods are served without an access token
+var publicMethods = []string{
+	userv1.UserService_Register_FullMethodName,
+	userv1.UserService_Login_FullMethodName,
+}
+
+// Server implements userv1.UserServiceServer on top of user.Service and auth.Service
+type Server struct {
+	userv1.UnimplementedUserServiceServer
+	userService user.Service
+	authService auth.Service
+	// autoLoginOnRegister returns a token pair from Register instead of just the new user
+	autoLoginOnRegister bool
+	authMetrics         *middleware.AuthMetrics
+	// tenantMetadata selects the tenant of public calls from x-tenant-id metadata
+	tenantMetadata bool
+}
+
+// ServerOption configures a Server
+type ServerOption func(*Server)
+
+// WithAutoLoginOnRegister controls whether Register signs the new user in and
+// returns tokens (enabled by default) or responds with just the user, like the
+// REST handler's security.auto_login_on_register
+func WithAutoLoginOnRegister(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.autoLoginOnRegister = enabled
+	}
+}
+
+// WithAuthMetrics counts login outcomes in the counters the REST handler uses
+// (disabled by default)
+func WithAuthMetrics(metrics *middleware.AuthMetrics) ServerOption {
+	return func(s *Server) {
+		s.authMetrics = metrics
+	}
+}
+
+// WithTenantMetadata controls whether public calls such as Register and Login
+// run in the tenant named by x-tenant-id metadata, like TenantMiddleware does
+// for REST (disabled by default). Authenticated calls always run in the tenant
+// of their access token.
+func WithTenantMetadata(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.tenantMetadata = enabled
+	}
+}
+
+// NewServer creates the user service implementation
+func NewServer(userService user.Service, authService auth.Service, opts ...ServerOption) *Server {
+	s := &Server{userService: userService, authService: authService, autoLoginOnRegister: true}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// New creates a gRPC server with server registered behind the JWT interceptor
+func New(server *Server, opts ...grpc.ServerOption) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{UnaryAuthInterceptor(server.authService, publicMethods...)}
+	if server.tenantMetadata {
+		interceptors = append([]grpc.UnaryServerInterceptor{UnaryTenantInterceptor()}, interceptors...)
+	}
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}, opts...)
+
+	s := grpc.NewServer(opts...)
+	userv1.RegisterUserServiceServer(s, server)
+	return s
+}
+
+// Register creates a user and returns it with a token pair, or with no tokens
+// when auto login on register is disabled
+func (s *Server) Register(ctx context.Context, req *userv1.RegisterRequest) (*userv1.AuthResponse, error) {
+	registerReq := user.RegisterRequest{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+	// WHY: Details are keyed by JSON name, as the REST register endpoint reports them
+	if err := binding.Validator.ValidateStruct(&registerReq); err != nil {
+		return nil, toStatus(ctx, apiErrors.FromJSONValidation(err, &registerReq))
+	}
+
+	u, err := s.userService.RegisterUser(ctx, registerReq)
+	if err != nil {
+		var policyErr *auth.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return nil, toStatus(ctx, apiErrors.ValidationError(map[string]string{"password": policyErr.Error()}))
+		}
+		if errors.Is(err, user.ErrEmailExists) {
+			return nil, toStatus(ctx, apiErrors.ConflictWithField("email", "already in use"))
+		}
+		if errors.Is(err, user.ErrLicenseLimitReached) {
+			return nil, toStatus(ctx, apiErrors.LicenseLimitReached("The user limit of the license has been reached"))
+		}
+		if errors.Is(err, user.ErrOverloaded) {
+			return nil, retryStatus(ctx, user.OverloadedError())
+		}
+		return nil, toStatus(ctx, apiErrors.ServerError(err))
+	}
+
+	if !s.autoLoginOnRegister {
+		return &userv1.AuthResponse{User: toProtoUser(u)}, nil
+	}
+	return s.authResponse(ctx, u)
+}
+
+// Login authenticates a user and returns it with a token pair
+func (s *Server) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.AuthResponse, error) {
+	loginReq := user.LoginRequest{Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := binding.Validator.ValidateStruct(&loginReq); err != nil {
+		return nil, toStatus(ctx, apiErrors.FromGinValidation(err))
+	}
+
+	u, err := s.userService.AuthenticateUser(ctx, loginReq)
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) {
+			s.authMetrics.RecordLogin(middleware.LoginInvalidCredentials)
+			return nil, toStatus(ctx, apiErrors.Unauthorized("Invalid email or password"))
+		}
+		if errors.Is(err, user.ErrAccountDisabled) {
+			s.authMetrics.RecordLogin(middleware.LoginAccountDisabled)
+			return nil, toStatus(ctx, apiErrors.AccountDisabled("Account is disabled"))
+		}
+		if errors.Is(err, user.ErrOverloaded) {
+			s.authMetrics.RecordLogin(middleware.LoginShed)
+			return nil, retryStatus(ctx, user.OverloadedError())
+		}
+		s.authMetrics.RecordLogin(middleware.LoginError)
+		return nil, toStatus(ctx, apiErrors.ServerError(err))
+	}
+
+	resp, err := s.authResponse(ctx, u)
+	if err != nil {
+		s.authMetrics.RecordLogin(middleware.LoginError)
+		return nil, err
+	}
+	s.authMetrics.RecordLogin(middleware.LoginSuccess)
+	return resp, nil
+}
+
+// GetUser returns a user; callers may only read themselves unless they are admins
+func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	id, err := userID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if !canAccessUser(ctx, id) {
+		return nil, toStatus(ctx, apiErrors.Forbidden("Forbidden user ID"))
+	}
+
+	u, err := s.userService.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, toStatus(ctx, userError(err))
+	}
+	return toProtoUser(u), nil
+}
+
+// UpdateUser changes a user's name and email; a new email stays pending until verified
+func (s *Server) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.User, error) {
+	id, err := userID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if !canAccessUser(ctx, id) {
+		return nil, toStatus(ctx, apiErrors.Forbidden("Forbidden user ID"))
+	}
+
+	updateReq := user.UpdateUserRequest{Name: req.GetName(), Email: req.GetEmail()}
+	if err := binding.Validator.ValidateStruct(&updateReq); err != nil {
+		return nil, toStatus(ctx, apiErrors.FromGinValidation(err))
+	}
+
+	u, err := s.userService.UpdateUser(ctx, id, updateReq)
+	if err != nil {
+		return nil, toStatus(ctx, userError(err))
+	}
+	return toProtoUser(u), nil
+}
+
+// DeleteUser removes a user
+func (s *Server) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
+	id, err := userID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if !canAccessUser(ctx, id) {
+		return nil, toStatus(ctx, apiErrors.Forbidden("Forbidden user ID"))
+	}
+
+	if err := s.userService.DeleteUser(ctx, id); err != nil {
+		return nil, toStatus(ctx, userError(err))
+	}
+	return &userv1.DeleteUserResponse{}, nil
+}
+
+func (s *Server) authResponse(ctx context.Context, u *user.User) (*userv1.AuthResponse, error) {
+	tokenPair, err := s.authService.GenerateTokenPair(ctx, u.ID, u.Email, u.Name)
+	if err != nil {
+		return nil, toStatus(ctx, apiErrors.ServerError(err))
+	}
+
+	return &userv1.AuthResponse{
+		User:         toProtoUser(u),
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    tokenPair.TokenType,
+		ExpiresIn:    tokenPair.ExpiresIn,
+	}, nil
+}
+
+// userID converts a requested user ID, rejecting IDs above db.MaxID, which no
+// user can have, the way the REST handlers do
+func userID(ctx context.Context, id uint32) (uint, error) {
+	if id > db.MaxID {
+		return 0, toStatus(ctx, apiErrors.BadRequest(fmt.Sprintf("User ID must be at most %d", db.MaxID)))
+	}
+	return uint(id), nil
+}
+
+// canAccessUser mirrors contextutil.CanAccessUser for the claims set by the interceptor
+func canAccessUser(ctx context.Context, targetUserID uint) bool {
+	claims := claimsFromContext(ctx)
+	if claims == nil {
+		return false
+	}
+	return claims.UserID == targetUserID || slices.Contains(claims.Roles, user.RoleAdmin)
+}
+
+// userError maps user service errors the same way the REST handlers do
+func userError(err error) *apiErrors.APIError {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return apiErrors.NotFound("User not found")
+	case errors.Is(err, user.ErrEmailExists):
+		return apiErrors.ConflictWithField("email", "already in use")
+	default:
+		return apiErrors.ServerError(err)
+	}
+}
+
+func toProtoUser(u *user.User) *userv1.User {
+	pb := &userv1.User{
+		Id:        uint32(u.ID),
+		Name:      u.Name,
+		Email:     u.Email,
+		Roles:     u.GetRoleNames(),
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+	if u.HasPendingEmailChange(time.Now()) {
+		pb.PendingEmail = u.PendingEmail
+	}
+	return pb
+}
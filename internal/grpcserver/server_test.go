@@ -0,0 +1,302 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	userv1 "github.com/vahiiiid/go-rest-api-boilerplate/api/proto/user/v1"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+type testEnv struct {
+	client      userv1.UserServiceClient
+	userService user.Service
+}
+
+func setupTestServer(t *testing.T, opts ...ServerOption) *testEnv {
+	t.Helper()
+
+	database := testutil.NewSQLiteDB(t)
+	authService := auth.NewServiceWithRepo(&config.NewTestConfig().JWT, database)
+	userService := user.NewService(user.NewRepository(database))
+
+	listener := bufconn.Listen(1 << 20)
+	srv := New(NewServer(userService, authService, opts...))
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testEnv{client: userv1.NewUserServiceClient(conn), userService: userService}
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// assertStatus checks the gRPC code and the API error code carried as ErrorInfo reason
+func assertStatus(t *testing.T, err error, code codes.Code, apiCode string) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "expected gRPC status, got %v", err)
+	assert.Equal(t, code, st.Code(), st.Message())
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, apiCode, info.GetReason())
+}
+
+func TestServer_RegisterAndLogin(t *testing.T) {
+	env := setupTestServer(t)
+	ctx := context.Background()
+
+	registered, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, registered.GetAccessToken())
+	assert.NotEmpty(t, registered.GetRefreshToken())
+	assert.Equal(t, "Bearer", registered.GetTokenType())
+	assert.Equal(t, "alice@example.com", registered.GetUser().GetEmail())
+	assert.Equal(t, []string{user.RoleUser}, registered.GetUser().GetRoles())
+	assert.False(t, registered.GetUser().GetCreatedAt().AsTime().IsZero())
+
+	t.Run("duplicate email", func(t *testing.T) {
+		_, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+		assertStatus(t, err, codes.AlreadyExists, apiErrors.CodeConflict)
+		assert.Equal(t, "Email already in use", status.Convert(err).Message())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "A", Email: "not-an-email", Password: "pw"})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+		assert.Contains(t, status.Convert(err).Message(), "email must be a valid email address", "keyed by JSON name, as over REST")
+	})
+
+	t.Run("login", func(t *testing.T) {
+		resp, err := env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, registered.GetUser().GetId(), resp.GetUser().GetId())
+		assert.NotEmpty(t, resp.GetAccessToken())
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "wrong-password"})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+	})
+}
+
+func TestServer_RegisterWithoutAutoLogin(t *testing.T) {
+	env := setupTestServer(t, WithAutoLoginOnRegister(false))
+
+	registered, err := env.client.Register(context.Background(), &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", registered.GetUser().GetEmail())
+	assert.Empty(t, registered.GetAccessToken())
+	assert.Empty(t, registered.GetRefreshToken())
+	assert.Empty(t, registered.GetTokenType())
+}
+
+func TestServer_LoginMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	env := setupTestServer(t, WithAuthMetrics(middleware.NewAuthMetrics(middleware.MetricsConfig{Registerer: registry})))
+	ctx := context.Background()
+
+	_, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "wrong-password"})
+	require.Error(t, err)
+
+	expected := `
+# HELP auth_logins_total Total number of password login attempts by result.
+# TYPE auth_logins_total counter
+auth_logins_total{result="account_disabled"} 0
+auth_logins_total{result="error"} 0
+auth_logins_total{result="invalid_credentials"} 1
+auth_logins_total{result="shed"} 0
+auth_logins_total{result="success"} 1
+`
+	assert.NoError(t, promtestutil.GatherAndCompare(registry, strings.NewReader(expected), "auth_logins_total"))
+}
+
+func TestServer_AuthInterceptor(t *testing.T) {
+	env := setupTestServer(t)
+
+	t.Run("missing token", func(t *testing.T) {
+		_, err := env.client.GetUser(context.Background(), &userv1.GetUserRequest{Id: 1})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+	})
+
+	t.Run("malformed metadata", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token abc")
+		_, err := env.client.GetUser(ctx, &userv1.GetUserRequest{Id: 1})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := env.client.GetUser(withToken("not-a-jwt"), &userv1.GetUserRequest{Id: 1})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		registered, err := env.client.Register(context.Background(), &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, env.userService.DeleteUser(context.Background(), uint(registered.GetUser().GetId())))
+
+		_, err = env.client.GetUser(withToken(registered.GetAccessToken()), &userv1.GetUserRequest{Id: registered.GetUser().GetId()})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+		assert.Equal(t, "User account is no longer active", status.Convert(err).Message())
+	})
+}
+
+func TestServer_UserRPCs(t *testing.T) {
+	env := setupTestServer(t)
+	ctx := context.Background()
+
+	alice, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	bob, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	aliceID := alice.GetUser().GetId()
+	bobID := bob.GetUser().GetId()
+	aliceCtx := withToken(alice.GetAccessToken())
+
+	t.Run("get own user", func(t *testing.T) {
+		u, err := env.client.GetUser(aliceCtx, &userv1.GetUserRequest{Id: aliceID})
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", u.GetName())
+	})
+
+	t.Run("get other user is forbidden", func(t *testing.T) {
+		_, err := env.client.GetUser(aliceCtx, &userv1.GetUserRequest{Id: bobID})
+		assertStatus(t, err, codes.PermissionDenied, apiErrors.CodeForbidden)
+	})
+
+	t.Run("ids above the schema limit are invalid", func(t *testing.T) {
+		_, err := env.client.GetUser(aliceCtx, &userv1.GetUserRequest{Id: db.MaxID + 1})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+		_, err = env.client.UpdateUser(aliceCtx, &userv1.UpdateUserRequest{Id: db.MaxID + 1, Name: "Alice"})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+		_, err = env.client.DeleteUser(aliceCtx, &userv1.DeleteUserRequest{Id: db.MaxID + 1})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+	})
+
+	t.Run("update name and request email change", func(t *testing.T) {
+		u, err := env.client.UpdateUser(aliceCtx, &userv1.UpdateUserRequest{Id: aliceID, Name: "Alice Smith", Email: "alice.smith@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Alice Smith", u.GetName())
+		assert.Equal(t, "alice@example.com", u.GetEmail())
+		assert.Equal(t, "alice.smith@example.com", u.GetPendingEmail())
+	})
+
+	t.Run("update with taken email", func(t *testing.T) {
+		_, err := env.client.UpdateUser(aliceCtx, &userv1.UpdateUserRequest{Id: aliceID, Email: "bob@example.com"})
+		assertStatus(t, err, codes.AlreadyExists, apiErrors.CodeConflict)
+		assert.Equal(t, "Email already in use", status.Convert(err).Message())
+	})
+
+	t.Run("update with invalid email", func(t *testing.T) {
+		_, err := env.client.UpdateUser(aliceCtx, &userv1.UpdateUserRequest{Id: aliceID, Email: "nope"})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+	})
+
+	t.Run("admin can delete another user", func(t *testing.T) {
+		require.NoError(t, env.userService.PromoteToAdmin(ctx, uint(aliceID)))
+		login, err := env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		require.NoError(t, err)
+		adminCtx := withToken(login.GetAccessToken())
+
+		_, err = env.client.DeleteUser(adminCtx, &userv1.DeleteUserRequest{Id: bobID})
+		require.NoError(t, err)
+
+		_, err = env.client.GetUser(adminCtx, &userv1.GetUserRequest{Id: bobID})
+		assertStatus(t, err, codes.NotFound, apiErrors.CodeNotFound)
+	})
+}
+
+func TestServer_Tenants(t *testing.T) {
+	env := setupTestServer(t, WithTenantMetadata(true))
+	ctx := context.Background()
+	acmeCtx := metadata.AppendToOutgoingContext(ctx, "x-tenant-id", "acme")
+
+	bob, err := env.client.Register(ctx, &userv1.RegisterRequest{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+	alice, err := env.client.Register(acmeCtx, &userv1.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, env.userService.PromoteToAdmin(tenant.NewContext(ctx, "acme"), uint(alice.GetUser().GetId())))
+
+	t.Run("login needs the tenant of the account", func(t *testing.T) {
+		_, err := env.client.Login(ctx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		assertStatus(t, err, codes.Unauthenticated, apiErrors.CodeUnauthorized)
+	})
+
+	t.Run("admin cannot reach another tenant's users", func(t *testing.T) {
+		login, err := env.client.Login(acmeCtx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		// No metadata: the tenant comes from the access token
+		_, err = env.client.GetUser(withToken(login.GetAccessToken()), &userv1.GetUserRequest{Id: bob.GetUser().GetId()})
+		assertStatus(t, err, codes.NotFound, apiErrors.CodeNotFound)
+
+		u, err := env.client.GetUser(withToken(login.GetAccessToken()), &userv1.GetUserRequest{Id: alice.GetUser().GetId()})
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", u.GetName())
+	})
+
+	t.Run("invalid tenant ID", func(t *testing.T) {
+		badCtx := metadata.AppendToOutgoingContext(ctx, "x-tenant-id", "not_a_label")
+		_, err := env.client.Login(badCtx, &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"})
+		assertStatus(t, err, codes.InvalidArgument, apiErrors.CodeValidation)
+	})
+}
+
+func TestRetryStatus(t *testing.T) {
+	err := retryStatus(context.Background(), user.OverloadedError())
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	require.Len(t, st.Details(), 2)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, apiErrors.CodeServiceUnavailable, info.GetReason())
+	retry, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, time.Second, retry.GetRetryDelay().AsDuration())
+
+	t.Run("no retry-after", func(t *testing.T) {
+		err := retryStatus(context.Background(), apiErrors.ServiceUnavailable("Service is under maintenance", 0))
+		assertStatus(t, err, codes.Unavailable, apiErrors.CodeServiceUnavailable)
+	})
+}
@@ -0,0 +1,78 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// statusCodes maps API error codes to their gRPC equivalents
+var statusCodes = map[string]codes.Code{
+	apiErrors.CodeValidation:         codes.InvalidArgument,
+	apiErrors.CodeUnauthorized:       codes.Unauthenticated,
+	apiErrors.CodeTokenExpired:       codes.Unauthenticated,
+	apiErrors.CodeForbidden:          codes.PermissionDenied,
+	apiErrors.CodeAccountDisabled:    codes.PermissionDenied,
+	apiErrors.CodeLicenseLimit:       codes.PermissionDenied,
+	apiErrors.CodeNotFound:           codes.NotFound,
+	apiErrors.CodeConflict:           codes.AlreadyExists,
+	apiErrors.CodeTooManyRequests:    codes.ResourceExhausted,
+	apiErrors.CodeServiceUnavailable: codes.Unavailable,
+	apiErrors.CodeNotImplemented:     codes.Unimplemented,
+	apiErrors.CodeRequestCanceled:    codes.Canceled,
+	apiErrors.CodeRequestTimeout:     codes.DeadlineExceeded,
+}
+
+// toStatus converts an API error into a gRPC status. The API error code is
+// attached as an ErrorInfo reason so clients can tell e.g. TOKEN_EXPIRED from
+// UNAUTHORIZED, as they can over HTTP.
+func toStatus(ctx context.Context, apiErr *apiErrors.APIError) error {
+	code, ok := statusCodes[apiErr.Code]
+	if !ok {
+		// WHY: Only the HTTP error handler logs internal errors, so log the cause here
+		slog.ErrorContext(ctx, "gRPC request failed", "error", apiErr.Details)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+
+	message := apiErr.Message
+	if details, ok := apiErr.Details.(map[string]string); ok && len(details) > 0 {
+		var fields []string
+		for _, field := range slices.Sorted(maps.Keys(details)) {
+			fields = append(fields, details[field])
+		}
+		message = fmt.Sprintf("%s: %s", message, strings.Join(fields, "; "))
+	}
+
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{Reason: apiErr.Code})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+// retryStatus converts a rate limit or unavailability error like toStatus and
+// attaches its retry-after as a RetryInfo, gRPC's Retry-After header
+func retryStatus(ctx context.Context, rlErr *apiErrors.RateLimitError) error {
+	err := toStatus(ctx, &rlErr.APIError)
+	if rlErr.RetryAfter <= 0 {
+		return err
+	}
+	st, detailErr := status.Convert(err).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Duration(rlErr.RetryAfter) * time.Second),
+	})
+	if detailErr != nil {
+		return err
+	}
+	return st.Err()
+}
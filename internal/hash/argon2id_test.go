@@ -0,0 +1,51 @@
+package hash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2id_Format(t *testing.T) {
+	encoded, err := NewArgon2id(Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}).Hash("password123")
+	require.NoError(t, err)
+
+	parts := strings.Split(encoded, "$")
+	require.Len(t, parts, 6)
+	assert.Equal(t, []string{"", "argon2id", "v=19", "m=64,t=1,p=1"}, parts[:4])
+}
+
+func TestParseArgon2id(t *testing.T) {
+	params, salt, key, err := parseArgon2id("$argon2id$v=19$m=65536,t=3,p=4$c29tZXNhbHRzb21lc2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U")
+
+	require.NoError(t, err)
+	assert.Equal(t, Argon2idParams{Memory: 65536, Iterations: 3, Parallelism: 4, SaltLength: 16, KeyLength: 32}, params)
+	assert.Equal(t, "somesaltsomesalt", string(salt))
+	assert.Len(t, key, 32)
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "other algorithm", encoded: "$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$a2V5"},
+		{name: "missing part", encoded: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA"},
+		{name: "other version", encoded: "$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5"},
+		{name: "malformed parameters", encoded: "$argon2id$v=19$t=3,m=65536,p=4$c2FsdA$a2V5"},
+		{name: "zero parameter", encoded: "$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$a2V5"},
+		{name: "bad salt", encoded: "$argon2id$v=19$m=65536,t=3,p=4$!!$a2V5"},
+		{name: "empty key", encoded: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := parseArgon2id(tt.encoded)
+			assert.ErrorIs(t, err, ErrUnknownFormat)
+		})
+	}
+}
+
+func TestNewArgon2id_Defaults(t *testing.T) {
+	assert.Equal(t, DefaultArgon2idParams, NewArgon2id(Argon2idParams{}).params)
+	assert.Equal(t, uint32(128), NewArgon2id(Argon2idParams{Memory: 128}).params.Memory)
+}
@@ -0,0 +1,113 @@
+// Package hash hashes passwords for storage. Every hash embeds its algorithm
+// and parameters, so a Hasher verifies hashes made under any configuration and
+// reports the ones that should be replaced.
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// Algorithms a Hasher can hash new passwords with
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	// ErrMismatch is returned when the password does not match the hash
+	ErrMismatch = errors.New("hash: password does not match")
+	// ErrUnknownFormat is returned when a stored hash is of no supported algorithm
+	ErrUnknownFormat = errors.New("hash: unknown hash format")
+)
+
+// Hasher hashes passwords and verifies them against stored hashes
+type Hasher interface {
+	// Hash returns the encoded hash of password
+	Hash(password string) (string, error)
+	// Verify checks password against an encoded hash of any supported algorithm
+	Verify(encoded, password string) error
+	// NeedsRehash reports that an encoded hash was made with another algorithm
+	// or parameters than Hash uses
+	NeedsRehash(encoded string) bool
+}
+
+// New returns a Hasher that hashes with the configured algorithm and verifies
+// bcrypt and argon2id hashes alike
+func New(cfg config.PasswordHashConfig) (Hasher, error) {
+	h := &hasher{
+		bcrypt: NewBcrypt(cfg.BcryptCost),
+		argon2id: NewArgon2id(Argon2idParams{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+		}),
+	}
+
+	switch cfg.Algorithm {
+	case "", AlgorithmBcrypt:
+		h.algorithm = AlgorithmBcrypt
+	case AlgorithmArgon2id:
+		h.algorithm = AlgorithmArgon2id
+	default:
+		return nil, fmt.Errorf("hash: unsupported algorithm %q", cfg.Algorithm)
+	}
+	return h, nil
+}
+
+// Default returns the Hasher of an empty configuration: bcrypt at bcrypt.DefaultCost
+func Default() Hasher {
+	h, _ := New(config.PasswordHashConfig{})
+	return h
+}
+
+type hasher struct {
+	algorithm string
+	bcrypt    *Bcrypt
+	argon2id  *Argon2id
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmArgon2id {
+		return h.argon2id.Hash(password)
+	}
+	return h.bcrypt.Hash(password)
+}
+
+func (h *hasher) Verify(encoded, password string) error {
+	switch Algorithm(encoded) {
+	case AlgorithmBcrypt:
+		return h.bcrypt.Verify(encoded, password)
+	case AlgorithmArgon2id:
+		return h.argon2id.Verify(encoded, password)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func (h *hasher) NeedsRehash(encoded string) bool {
+	algorithm := Algorithm(encoded)
+	if algorithm != h.algorithm {
+		return true
+	}
+	if algorithm == AlgorithmArgon2id {
+		return h.argon2id.NeedsRehash(encoded)
+	}
+	return h.bcrypt.NeedsRehash(encoded)
+}
+
+// Algorithm returns the algorithm an encoded hash was made with, or "" when
+// it is of no supported algorithm
+func Algorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
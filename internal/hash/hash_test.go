@@ -0,0 +1,118 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// fastArgon2id keeps argon2id cheap enough for unit tests
+var fastArgon2id = config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+
+func mustNew(t testing.TB, cfg config.PasswordHashConfig) Hasher {
+	t.Helper()
+	h, err := New(cfg)
+	require.NoError(t, err)
+	return h
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(config.PasswordHashConfig{Algorithm: "scrypt"})
+	assert.EqualError(t, err, `hash: unsupported algorithm "scrypt"`)
+
+	encoded, err := Default().Hash("password123")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmBcrypt, Algorithm(encoded))
+	cost, err := bcrypt.Cost([]byte(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestHasher_CrossAlgorithm(t *testing.T) {
+	bcryptHasher := mustNew(t, config.PasswordHashConfig{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	argon2Hasher := mustNew(t, fastArgon2id)
+
+	bcryptHash, err := bcryptHasher.Hash("password123")
+	require.NoError(t, err)
+	argon2Hash, err := argon2Hasher.Hash("password123")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		hasher          Hasher
+		encoded         string
+		wantNeedsRehash bool
+	}{
+		{name: "bcrypt under bcrypt", hasher: bcryptHasher, encoded: bcryptHash},
+		{name: "argon2id under argon2id", hasher: argon2Hasher, encoded: argon2Hash},
+		{name: "bcrypt under argon2id", hasher: argon2Hasher, encoded: bcryptHash, wantNeedsRehash: true},
+		{name: "argon2id under bcrypt", hasher: bcryptHasher, encoded: argon2Hash, wantNeedsRehash: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.hasher.Verify(tt.encoded, "password123"))
+			assert.Equal(t, tt.wantNeedsRehash, tt.hasher.NeedsRehash(tt.encoded))
+
+			assert.ErrorIs(t, tt.hasher.Verify(tt.encoded, "wrongpassword"), ErrMismatch)
+		})
+	}
+}
+
+func TestHasher_ChangedParameters(t *testing.T) {
+	t.Run("bcrypt cost", func(t *testing.T) {
+		encoded, err := mustNew(t, config.PasswordHashConfig{BcryptCost: bcrypt.MinCost}).Hash("password123")
+		require.NoError(t, err)
+
+		h := mustNew(t, config.PasswordHashConfig{BcryptCost: bcrypt.MinCost + 1})
+
+		require.NoError(t, h.Verify(encoded, "password123"))
+		assert.True(t, h.NeedsRehash(encoded))
+	})
+
+	t.Run("argon2id memory", func(t *testing.T) {
+		encoded, err := mustNew(t, fastArgon2id).Hash("password123")
+		require.NoError(t, err)
+
+		stronger := fastArgon2id
+		stronger.Argon2Memory = 128
+		h := mustNew(t, stronger)
+
+		require.NoError(t, h.Verify(encoded, "password123"))
+		assert.True(t, h.NeedsRehash(encoded))
+	})
+}
+
+func TestHasher_UnknownFormat(t *testing.T) {
+	h := Default()
+
+	for _, encoded := range []string{"", "plaintext", "$scrypt$ln=16,r=8,p=1$c2FsdA$a2V5"} {
+		assert.ErrorIs(t, h.Verify(encoded, "password123"), ErrUnknownFormat, encoded)
+		assert.True(t, h.NeedsRehash(encoded), encoded)
+	}
+}
+
+func BenchmarkHash(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		cfg  config.PasswordHashConfig
+	}{
+		{name: "bcrypt", cfg: config.PasswordHashConfig{Algorithm: AlgorithmBcrypt}},
+		{name: "argon2id", cfg: config.PasswordHashConfig{Algorithm: AlgorithmArgon2id}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			h := mustNew(b, bm.cfg)
+			for b.Loop() {
+				if _, err := h.Hash("password123"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
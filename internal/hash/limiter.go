@@ -0,0 +1,121 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+// ErrOverloaded is returned when no hashing slot frees up in time and the
+// operation was shed
+var ErrOverloaded = errors.New("hash: too many password hash operations in flight")
+
+// LimiterConfig configures a Limiter; zero values use the defaults
+type LimiterConfig struct {
+	// MaxConcurrency is how many hash operations run at once (defaults to GOMAXPROCS)
+	MaxConcurrency int
+	// MaxWait is how long an operation waits for a slot before it is shed; zero
+	// sheds it as soon as every slot is taken
+	MaxWait time.Duration
+	// Namespace prefixes the metric names
+	Namespace string
+	// Registerer receives the limiter metrics (defaults to prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+}
+
+// Limiter bounds the number of password hash operations running at once, so a
+// flood of logins sheds excess attempts instead of queueing every request
+// behind the CPU. A nil *Limiter does not limit.
+type Limiter struct {
+	sem     *semaphore.Weighted
+	maxWait time.Duration
+	metrics *limiterMetrics
+}
+
+// NewLimiter creates a Limiter
+func NewLimiter(cfg LimiterConfig) *Limiter {
+	size := cfg.MaxConcurrency
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	return &Limiter{
+		sem:     semaphore.NewWeighted(int64(size)),
+		maxWait: cfg.MaxWait,
+		metrics: newLimiterMetrics(cfg.Namespace, registerer),
+	}
+}
+
+// Do runs fn once a slot is free. Without running fn, it returns ErrOverloaded
+// when no slot frees up within MaxWait, and the error of ctx when ctx is done
+// first.
+func (l *Limiter) Do(ctx context.Context, fn func()) error {
+	if l == nil {
+		fn()
+		return nil
+	}
+
+	if !l.sem.TryAcquire(1) {
+		if err := l.acquire(ctx); err != nil {
+			return err
+		}
+	}
+	defer l.sem.Release(1)
+
+	l.metrics.inFlight.Inc()
+	defer l.metrics.inFlight.Dec()
+
+	fn()
+	return nil
+}
+
+func (l *Limiter) acquire(ctx context.Context) error {
+	if l.maxWait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, l.maxWait)
+		defer cancel()
+		if l.sem.Acquire(waitCtx, 1) == nil {
+			return nil
+		}
+	}
+	// WHY: A client that gave up is not load that was shed
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.metrics.shed.Inc()
+	return ErrOverloaded
+}
+
+type limiterMetrics struct {
+	inFlight prometheus.Gauge
+	shed     prometheus.Counter
+}
+
+func newLimiterMetrics(namespace string, registerer prometheus.Registerer) *limiterMetrics {
+	m := &limiterMetrics{
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "password_hash_in_flight",
+			Help:      "Number of password hash operations running.",
+		}),
+		shed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "password_hash_shed_total",
+			Help:      "Total number of password hash operations shed because every slot was taken.",
+		}),
+	}
+
+	m.inFlight = metrics.Register(registerer, m.inFlight)
+	m.shed = metrics.Register(registerer, m.shed)
+
+	return m
+}
@@ -0,0 +1,85 @@
+package hash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hold takes every slot of l until the returned func is called
+func hold(t *testing.T, l *Limiter, slots int) func() {
+	t.Helper()
+	release := make(chan struct{})
+	started := make(chan struct{}, slots)
+	for range slots {
+		go func() {
+			_ = l.Do(context.Background(), func() {
+				started <- struct{}{}
+				<-release
+			})
+		}()
+	}
+	for range slots {
+		<-started
+	}
+	return func() { close(release) }
+}
+
+func TestLimiter_Do(t *testing.T) {
+	l := NewLimiter(LimiterConfig{MaxConcurrency: 2, Registerer: prometheus.NewRegistry()})
+	release := hold(t, l, 2)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.metrics.inFlight))
+
+	ran := false
+	err := l.Do(context.Background(), func() { ran = true })
+	assert.ErrorIs(t, err, ErrOverloaded)
+	assert.False(t, ran)
+	assert.Equal(t, float64(1), testutil.ToFloat64(l.metrics.shed))
+
+	release()
+	require.Eventually(t, func() bool { return testutil.ToFloat64(l.metrics.inFlight) == 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, l.Do(context.Background(), func() { ran = true }))
+	assert.True(t, ran)
+}
+
+func TestLimiter_MaxWait(t *testing.T) {
+	l := NewLimiter(LimiterConfig{MaxConcurrency: 1, MaxWait: time.Second, Registerer: prometheus.NewRegistry()})
+	release := hold(t, l, 1)
+	time.AfterFunc(20*time.Millisecond, release)
+
+	ran := false
+	require.NoError(t, l.Do(context.Background(), func() { ran = true }))
+	assert.True(t, ran, "a slot freed up within MaxWait")
+	assert.Equal(t, float64(0), testutil.ToFloat64(l.metrics.shed))
+}
+
+func TestLimiter_ContextDone(t *testing.T) {
+	l := NewLimiter(LimiterConfig{MaxConcurrency: 1, MaxWait: time.Second, Registerer: prometheus.NewRegistry()})
+	release := hold(t, l, 1)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := l.Do(ctx, func() { t.Error("fn ran without a slot") })
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the request deadline cuts the wait short")
+	assert.Equal(t, float64(0), testutil.ToFloat64(l.metrics.shed))
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+
+	ran := false
+	require.NoError(t, l.Do(context.Background(), func() { ran = true }))
+	assert.True(t, ran)
+}
@@ -2,18 +2,25 @@ package health
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service Service
+	service        Service
+	streamInterval time.Duration
 }
 
-func NewHandler(service Service) *Handler {
-	return &Handler{
-		service: service,
+func NewHandler(service Service, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		service:        service,
+		streamInterval: DefaultStreamInterval,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Health godoc
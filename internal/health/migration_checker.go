@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrationVersioner reports the schema version applied to the database and the
+// latest version available in the migration files
+type MigrationVersioner interface {
+	Version(ctx context.Context) (version uint, dirty bool, err error)
+	LatestVersion() (uint, error)
+}
+
+// MigrationDetails is reported with every migration check result
+type MigrationDetails struct {
+	Current uint `json:"current"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+// MigrationChecker fails readiness while the database schema is behind the
+// migrations shipped with the binary, so a new deployment does not serve
+// traffic against an un-migrated schema
+type MigrationChecker struct {
+	migrator MigrationVersioner
+}
+
+func NewMigrationChecker(migrator MigrationVersioner) *MigrationChecker {
+	return &MigrationChecker{migrator: migrator}
+}
+
+func (m *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (m *MigrationChecker) Check(ctx context.Context) CheckResult {
+	latest, err := m.migrator.LatestVersion()
+	if err != nil {
+		return CheckResult{
+			Status:  CheckFail,
+			Message: "Failed to read migration files",
+		}
+	}
+
+	current, dirty, err := m.migrator.Version(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return CheckResult{
+				Status:  CheckFail,
+				Message: "Migration version check canceled",
+			}
+		}
+		return CheckResult{
+			Status:  CheckFail,
+			Message: "Failed to read database migration version",
+		}
+	}
+
+	details := MigrationDetails{Current: current, Latest: latest, Dirty: dirty}
+	switch {
+	case dirty:
+		return CheckResult{
+			Status:  CheckFail,
+			Message: fmt.Sprintf("Database migration %d is dirty", current),
+			Details: details,
+		}
+	case current < latest:
+		return CheckResult{
+			Status:  CheckFail,
+			Message: fmt.Sprintf("Database schema is behind: version %d, migrations up to %d are pending", current, latest),
+			Details: details,
+		}
+	case current > latest:
+		// WHY: A rolled back deployment can still serve a newer schema, but it is worth surfacing
+		return CheckResult{
+			Status:  CheckWarn,
+			Message: fmt.Sprintf("Database schema version %d is ahead of the latest migration %d", current, latest),
+			Details: details,
+		}
+	}
+
+	return CheckResult{
+		Status:  CheckPass,
+		Message: "Database schema is up to date",
+		Details: details,
+	}
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubMigrator struct {
+	current   uint
+	latest    uint
+	dirty     bool
+	err       error
+	latestErr error
+}
+
+func (s stubMigrator) Version(ctx context.Context) (uint, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, err
+	}
+	return s.current, s.dirty, s.err
+}
+func (s stubMigrator) LatestVersion() (uint, error) { return s.latest, s.latestErr }
+
+func TestMigrationChecker_Name(t *testing.T) {
+	assert.Equal(t, "migrations", NewMigrationChecker(stubMigrator{}).Name())
+}
+
+func TestMigrationChecker_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		migrator stubMigrator
+		status   CheckStatus
+		message  string
+	}{
+		{"up to date", stubMigrator{current: 20251207090000, latest: 20251207090000}, CheckPass, "up to date"},
+		{"behind the migration files", stubMigrator{current: 20251201000000, latest: 20251207090000}, CheckFail, "pending"},
+		{"never migrated", stubMigrator{latest: 20251207090000}, CheckFail, "behind"},
+		{"dirty", stubMigrator{current: 20251207090000, latest: 20251207090000, dirty: true}, CheckFail, "dirty"},
+		{"ahead of the migration files", stubMigrator{current: 20251208000000, latest: 20251207090000}, CheckWarn, "ahead"},
+		{"version error", stubMigrator{err: errors.New("connection refused")}, CheckFail, "database migration version"},
+		{"migration files error", stubMigrator{latestErr: errors.New("no such directory")}, CheckFail, "migration files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMigrationChecker(tt.migrator).Check(context.Background())
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Contains(t, result.Message, tt.message)
+		})
+	}
+
+	t.Run("honours the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := NewMigrationChecker(stubMigrator{current: 1, latest: 1}).Check(ctx)
+
+		assert.Equal(t, CheckFail, result.Status)
+		assert.Contains(t, result.Message, "canceled")
+	})
+
+	t.Run("reports both versions", func(t *testing.T) {
+		result := NewMigrationChecker(stubMigrator{current: 1, latest: 2}).Check(context.Background())
+
+		assert.Equal(t, MigrationDetails{Current: 1, Latest: 2}, result.Details)
+	})
+}
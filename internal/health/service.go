@@ -3,6 +3,7 @@ package health
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -12,20 +13,54 @@ type Service interface {
 	GetReadiness(ctx context.Context) HealthResponse
 }
 
+// DefaultCheckTimeout bounds each checker when no timeout is configured
+const DefaultCheckTimeout = 5 * time.Second
+
 type service struct {
-	checkers    []Checker
-	startTime   time.Time
-	version     string
-	environment string
+	checkers        []Checker
+	startTime       time.Time
+	version         string
+	environment     string
+	timeout         time.Duration
+	checkerTimeouts map[string]time.Duration
+}
+
+// ServiceOption configures optional Service behavior
+type ServiceOption func(*service)
+
+// WithTimeout sets how long each checker may run before it is reported as failed
+func WithTimeout(timeout time.Duration) ServiceOption {
+	return func(s *service) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
 }
 
-func NewService(checkers []Checker, version, environment string) Service {
-	return &service{
-		checkers:    checkers,
-		startTime:   time.Now(),
-		version:     version,
-		environment: environment,
+// WithCheckerTimeouts overrides the timeout for individual checkers by name
+func WithCheckerTimeouts(timeouts map[string]time.Duration) ServiceOption {
+	return func(s *service) {
+		for name, timeout := range timeouts {
+			if timeout > 0 {
+				s.checkerTimeouts[name] = timeout
+			}
+		}
+	}
+}
+
+func NewService(checkers []Checker, version, environment string, opts ...ServiceOption) Service {
+	s := &service{
+		checkers:        checkers,
+		startTime:       time.Now(),
+		version:         version,
+		environment:     environment,
+		timeout:         DefaultCheckTimeout,
+		checkerTimeouts: make(map[string]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *service) GetHealth(ctx context.Context) HealthResponse {
@@ -50,12 +85,26 @@ func (s *service) GetLiveness(ctx context.Context) HealthResponse {
 	}
 }
 
+// GetReadiness runs all checkers concurrently, so its latency is bounded by the
+// slowest checker rather than their sum. Any failed check makes the service
+// unhealthy; otherwise any warning makes it degraded.
 func (s *service) GetReadiness(ctx context.Context) HealthResponse {
+	results := make([]CheckResult, len(s.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
 	checks := make(map[string]CheckResult)
 	overallStatus := StatusHealthy
 
-	for _, checker := range s.checkers {
-		result := checker.Check(ctx)
+	for i, checker := range s.checkers {
+		result := results[i]
 		checks[checker.Name()] = result
 
 		if result.Status == CheckFail {
@@ -75,6 +124,41 @@ func (s *service) GetReadiness(ctx context.Context) HealthResponse {
 	}
 }
 
+// runCheck runs checker with its timeout. A checker that does not return in
+// time, or before the caller gives up, is reported as failed; it keeps running
+// in the background until it observes the cancelled context.
+func (s *service) runCheck(parent context.Context, checker Checker) CheckResult {
+	timeout := s.timeout
+	if t, ok := s.checkerTimeouts[checker.Name()]; ok {
+		timeout = t
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	done := make(chan CheckResult, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		// WHY: A probe that went away is not a slow dependency
+		if err := parent.Err(); err != nil {
+			return CheckResult{
+				Status:  CheckFail,
+				Message: fmt.Sprintf("Check canceled: %v", err),
+			}
+		}
+		return CheckResult{
+			Status:  CheckFail,
+			Message: fmt.Sprintf("Check timed out after %s", timeout),
+		}
+	}
+}
+
 func (s *service) formatUptime() string {
 	uptime := time.Since(s.startTime)
 	days := int(uptime.Hours() / 24)
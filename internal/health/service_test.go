@@ -2,10 +2,13 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockChecker struct {
@@ -142,3 +145,143 @@ func TestService_FormatUptime(t *testing.T) {
 		})
 	}
 }
+
+type slowChecker struct {
+	name  string
+	delay time.Duration
+}
+
+func (s *slowChecker) Name() string {
+	return s.name
+}
+
+func (s *slowChecker) Check(ctx context.Context) CheckResult {
+	time.Sleep(s.delay)
+	return CheckResult{Status: CheckPass, Message: "OK"}
+}
+
+// barrierChecker returns its result only once every checker sharing the
+// barrier has started, so it passes only when the checks overlap
+type barrierChecker struct {
+	name    string
+	arrived *sync.WaitGroup
+	all     <-chan struct{}
+	result  CheckResult
+}
+
+func (b *barrierChecker) Name() string {
+	return b.name
+}
+
+func (b *barrierChecker) Check(ctx context.Context) CheckResult {
+	b.arrived.Done()
+	select {
+	case <-b.all:
+		return b.result
+	case <-ctx.Done():
+		return CheckResult{Status: CheckFail, Message: "checks did not overlap"}
+	}
+}
+
+func TestService_GetReadiness_Concurrent(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []CheckResult
+		expectedStatus HealthStatus
+	}{
+		{
+			name:           "all pass",
+			results:        []CheckResult{{Status: CheckPass}, {Status: CheckPass}, {Status: CheckPass}},
+			expectedStatus: StatusHealthy,
+		},
+		{
+			name:           "warn degrades",
+			results:        []CheckResult{{Status: CheckPass}, {Status: CheckWarn}, {Status: CheckPass}},
+			expectedStatus: StatusDegraded,
+		},
+		{
+			name:           "fail takes precedence over warn",
+			results:        []CheckResult{{Status: CheckWarn}, {Status: CheckFail}, {Status: CheckWarn}},
+			expectedStatus: StatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var arrived sync.WaitGroup
+			arrived.Add(len(tt.results))
+			all := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(all)
+			}()
+
+			var checkers []Checker
+			for i, result := range tt.results {
+				checkers = append(checkers, &barrierChecker{
+					name:    fmt.Sprintf("checker-%d", i),
+					arrived: &arrived,
+					all:     all,
+					result:  result,
+				})
+			}
+			svc := NewService(checkers, "1.0.0", "test", WithTimeout(time.Second))
+
+			response := svc.GetReadiness(context.Background())
+
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			require.Len(t, response.Checks, len(tt.results))
+			for i, result := range tt.results {
+				assert.Equal(t, result, response.Checks[fmt.Sprintf("checker-%d", i)])
+			}
+		})
+	}
+}
+
+func TestService_GetReadiness_Timeout(t *testing.T) {
+	t.Run("hung checker fails with timeout message", func(t *testing.T) {
+		svc := NewService([]Checker{
+			&slowChecker{name: "database", delay: 500 * time.Millisecond},
+			&mockChecker{name: "cache", result: CheckResult{Status: CheckPass, Message: "OK"}},
+		}, "1.0.0", "test", WithTimeout(50*time.Millisecond))
+
+		start := time.Now()
+		response := svc.GetReadiness(context.Background())
+
+		assert.Less(t, time.Since(start), 400*time.Millisecond)
+		assert.Equal(t, StatusUnhealthy, response.Status)
+		assert.Equal(t, CheckFail, response.Checks["database"].Status)
+		assert.Equal(t, "Check timed out after 50ms", response.Checks["database"].Message)
+		assert.Equal(t, CheckPass, response.Checks["cache"].Status)
+	})
+
+	t.Run("per-checker timeout overrides the global timeout", func(t *testing.T) {
+		svc := NewService([]Checker{
+			&slowChecker{name: "database", delay: 100 * time.Millisecond},
+			&slowChecker{name: "cache", delay: 100 * time.Millisecond},
+		}, "1.0.0", "test",
+			WithTimeout(time.Second),
+			WithCheckerTimeouts(map[string]time.Duration{"cache": 20 * time.Millisecond}),
+		)
+
+		response := svc.GetReadiness(context.Background())
+
+		assert.Equal(t, StatusUnhealthy, response.Status)
+		assert.Equal(t, CheckPass, response.Checks["database"].Status)
+		assert.Equal(t, CheckFail, response.Checks["cache"].Status)
+		assert.Contains(t, response.Checks["cache"].Message, "timed out")
+	})
+
+	t.Run("caller cancellation is not reported as a timeout", func(t *testing.T) {
+		svc := NewService([]Checker{
+			&slowChecker{name: "database", delay: 500 * time.Millisecond},
+		}, "1.0.0", "test", WithTimeout(time.Second))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		response := svc.GetReadiness(ctx)
+
+		assert.Equal(t, CheckFail, response.Checks["database"].Status)
+		assert.Equal(t, "Check canceled: context deadline exceeded", response.Checks["database"].Message)
+	})
+}
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceService returns the configured statuses in order, repeating the last one
+type sequenceService struct {
+	mockService
+	mu       sync.Mutex
+	statuses []HealthStatus
+}
+
+func (s *sequenceService) GetReadiness(ctx context.Context) HealthResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	return HealthResponse{Status: status, Version: "1.0.0", Checks: map[string]CheckResult{}}
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	retry string
+	data  string
+}
+
+// readEvents parses n events from an SSE stream
+func readEvents(t *testing.T, reader *bufio.Reader, n int) []sseEvent {
+	t.Helper()
+
+	var events []sseEvent
+	var current sseEvent
+	for len(events) < n {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		if line == "" {
+			events = append(events, current)
+			current = sseEvent{}
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		require.True(t, ok, "malformed line %q", line)
+		switch field {
+		case "id":
+			current.id = value
+		case "event":
+			current.event = value
+		case "retry":
+			current.retry = value
+		case "data":
+			current.data = value
+		default:
+			t.Fatalf("unexpected field %q", field)
+		}
+	}
+	return events
+}
+
+func openStream(t *testing.T, service Service, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/v1/events", NewHandler(service, WithStreamInterval(10*time.Millisecond)).Stream)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set(LastEventIDHeader, lastEventID)
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestHandler_Stream(t *testing.T) {
+	service := &sequenceService{statuses: []HealthStatus{StatusHealthy, StatusHealthy, StatusUnhealthy}}
+	resp, reader := openStream(t, service, "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	events := readEvents(t, reader, 4)
+
+	assert.Equal(t, "1", events[0].id)
+	assert.Equal(t, EventHealth, events[0].event)
+	assert.Equal(t, "10", events[0].retry)
+	var snapshot HealthResponse
+	require.NoError(t, json.Unmarshal([]byte(events[0].data), &snapshot))
+	assert.Equal(t, StatusHealthy, snapshot.Status)
+
+	assert.Equal(t, "2", events[1].id)
+	assert.Equal(t, EventHealth, events[1].event)
+
+	assert.Equal(t, "3", events[2].id)
+	assert.Contains(t, events[2].data, `"status":"unhealthy"`)
+
+	assert.Equal(t, "4", events[3].id)
+	assert.Equal(t, EventStatusChange, events[3].event)
+	assert.JSONEq(t, `{"from":"healthy","to":"unhealthy"}`, events[3].data)
+}
+
+func TestHandler_Stream_ResumesAfterLastEventID(t *testing.T) {
+	service := &sequenceService{statuses: []HealthStatus{StatusDegraded}}
+
+	_, reader := openStream(t, service, "41")
+	events := readEvents(t, reader, 2)
+	assert.Equal(t, "42", events[0].id)
+	assert.Equal(t, "43", events[1].id)
+
+	_, reader = openStream(t, service, "not-a-number")
+	events = readEvents(t, reader, 1)
+	assert.Equal(t, "1", events[0].id)
+}
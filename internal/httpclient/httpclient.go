@@ -0,0 +1,61 @@
+// Package httpclient builds the HTTP clients used to call external services.
+// Every outbound request forwards the caller's request ID and is recorded in
+// the outbound request metrics.
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+)
+
+// New returns an http.Client whose requests go through a Transport named name.
+// A zero timeout leaves requests bounded only by their context.
+func New(name string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &Transport{Name: name},
+	}
+}
+
+// Transport forwards the request ID found in the request context as the
+// X-Request-ID header and records the latency and outcome of each request
+type Transport struct {
+	// Name identifies the downstream service in metrics, e.g. "oauth_google"
+	Name string
+	// Base performs the request; nil means http.DefaultTransport
+	Base http.RoundTripper
+	// Metrics records the request; nil means the metrics set by SetDefaultMetrics
+	Metrics *Metrics
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := requestid.FromContext(req.Context()); id != "" && req.Header.Get(requestid.Header) == "" {
+		// WHY: A RoundTripper must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set(requestid.Header, id)
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	metrics := t.Metrics
+	if metrics == nil {
+		metrics = defaultMetrics.Load()
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.record(t.Name, req.Method, status, time.Since(start))
+
+	return resp, err
+}
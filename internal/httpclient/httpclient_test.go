@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+)
+
+// newEchoServer answers every request with the X-Request-ID it received
+func newEchoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Received-ID", r.Header.Get(requestid.Header))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(metrics *Metrics) *http.Client {
+	return &http.Client{Transport: &Transport{Name: "test", Metrics: metrics}}
+}
+
+func get(t *testing.T, client *http.Client, ctx context.Context, url string, header http.Header) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	return resp
+}
+
+func TestTransport_ForwardsRequestID(t *testing.T) {
+	srv := newEchoServer(t)
+	client := newTestClient(nil)
+
+	t.Run("forwards the ID from the context", func(t *testing.T) {
+		ctx := requestid.NewContext(context.Background(), "req-123")
+		resp := get(t, client, ctx, srv.URL, nil)
+
+		assert.Equal(t, "req-123", resp.Header.Get("X-Received-ID"))
+	})
+
+	t.Run("keeps an ID set by the caller", func(t *testing.T) {
+		ctx := requestid.NewContext(context.Background(), "req-123")
+		resp := get(t, client, ctx, srv.URL, http.Header{requestid.Header: {"explicit"}})
+
+		assert.Equal(t, "explicit", resp.Header.Get("X-Received-ID"))
+	})
+
+	t.Run("sends no header without an ID", func(t *testing.T) {
+		resp := get(t, client, context.Background(), srv.URL, nil)
+
+		assert.Empty(t, resp.Header.Get("X-Received-ID"))
+	})
+
+	t.Run("does not modify the caller's request", func(t *testing.T) {
+		ctx := requestid.NewContext(context.Background(), "req-123")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Empty(t, req.Header.Get(requestid.Header))
+	})
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTransport_RecordsMetrics(t *testing.T) {
+	srv := newEchoServer(t)
+	metrics := NewMetrics("", prometheus.NewRegistry())
+
+	get(t, newTestClient(metrics), context.Background(), srv.URL, nil)
+	get(t, newTestClient(metrics), context.Background(), srv.URL, nil)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.requests.WithLabelValues("test", http.MethodGet, "202")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+
+	failing := &http.Client{Transport: &Transport{Name: "test", Base: failingTransport{}, Metrics: metrics}}
+	_, err := failing.Get(srv.URL)
+	require.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.requests.WithLabelValues("test", http.MethodGet, "error")))
+}
+
+func TestTransport_DefaultMetrics(t *testing.T) {
+	srv := newEchoServer(t)
+	metrics := NewMetrics("", prometheus.NewRegistry())
+	SetDefaultMetrics(metrics)
+	t.Cleanup(func() { SetDefaultMetrics(nil) })
+
+	get(t, New("default", 0), context.Background(), srv.URL, nil)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.requests.WithLabelValues("default", http.MethodGet, "202")))
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	srv := newEchoServer(t)
+
+	resp := get(t, New("unmetered", 0), context.Background(), srv.URL, nil)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
@@ -0,0 +1,58 @@
+package httpclient
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+var defaultMetrics atomic.Pointer[Metrics]
+
+// SetDefaultMetrics makes every Transport without its own Metrics record into m
+func SetDefaultMetrics(m *Metrics) {
+	defaultMetrics.Store(m)
+}
+
+// Metrics holds the outbound request collectors. A nil *Metrics records
+// nothing, so clients work before metrics are configured.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics creates and registers the outbound request collectors under
+// namespace. A nil registerer uses the global Prometheus registry.
+func NewMetrics(namespace string, registerer prometheus.Registerer) *Metrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_client_requests_total",
+			Help:      "Total number of outbound HTTP requests by client, method and status code.",
+		}, []string{"client", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_client_request_duration_seconds",
+			Help:      "Duration of outbound HTTP requests in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"client", "method"}),
+	}
+
+	m.requests = metrics.Register(registerer, m.requests)
+	m.duration = metrics.Register(registerer, m.duration)
+	return m
+}
+
+func (m *Metrics) record(client, method, status string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(client, method, status).Inc()
+	m.duration.WithLabelValues(client, method).Observe(duration.Seconds())
+}
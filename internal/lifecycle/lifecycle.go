@@ -0,0 +1,112 @@
+// Package lifecycle starts the background workers of the server together and
+// stops them together on shutdown, so none leaks or drops in-flight work.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ErrAlreadyStarted is returned when a worker is added or Start is called
+// after the manager started
+var ErrAlreadyStarted = errors.New("lifecycle manager already started")
+
+// Worker is a background component with a start and a graceful stop. Stop
+// waits for in-flight work and returns ctx's error if ctx expires first.
+type Worker interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// StopFunc is a Worker that already runs when it is added and only needs
+// stopping, such as a pool started by its constructor
+type StopFunc func(ctx context.Context) error
+
+// Start does nothing
+func (f StopFunc) Start(context.Context) error {
+	return nil
+}
+
+// Stop calls f
+func (f StopFunc) Stop(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedWorker struct {
+	name   string
+	worker Worker
+}
+
+// Manager starts workers in the order they were added and stops them in
+// reverse, so a worker is stopped before the ones it hands work to
+type Manager struct {
+	mu      sync.Mutex
+	workers []namedWorker
+	started []namedWorker
+	running bool
+	logger  *slog.Logger
+}
+
+// New creates a manager that logs through logger, or slog.Default when nil
+func New(logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{logger: logger}
+}
+
+// Add registers worker under name. Workers must be added before Start.
+func (m *Manager) Add(name string, worker Worker) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return ErrAlreadyStarted
+	}
+	m.workers = append(m.workers, namedWorker{name: name, worker: worker})
+	return nil
+}
+
+// Start starts every worker. If one fails to start, the rest are not started
+// and the error is returned; Shutdown still stops those already running.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return ErrAlreadyStarted
+	}
+	m.running = true
+
+	for _, w := range m.workers {
+		if err := w.worker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start %s: %w", w.name, err)
+		}
+		m.started = append(m.started, w)
+	}
+	return nil
+}
+
+// Shutdown stops the started workers one after another, all within ctx. A
+// worker that fails to stop in time does not keep the others from stopping;
+// the errors are returned joined.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		w := started[i]
+		m.logger.Info("Stopping background worker", "worker", w.name)
+		if err := w.worker.Stop(ctx); err != nil {
+			m.logger.Error("Background worker did not stop cleanly", "worker", w.name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", w.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorker runs a goroutine until it is told to stop, then takes drain to finish
+type fakeWorker struct {
+	name  string
+	drain time.Duration
+	order *[]string
+	mu    *sync.Mutex
+
+	stop    chan struct{}
+	done    chan struct{}
+	stopped bool
+}
+
+func newFakeWorker(name string, drain time.Duration, order *[]string, mu *sync.Mutex) *fakeWorker {
+	return &fakeWorker{name: name, drain: drain, order: order, mu: mu, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (w *fakeWorker) Start(context.Context) error {
+	go func() {
+		defer close(w.done)
+		<-w.stop
+		time.Sleep(w.drain)
+	}()
+	return nil
+}
+
+func (w *fakeWorker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	*w.order = append(*w.order, w.name)
+	w.mu.Unlock()
+
+	close(w.stop)
+	select {
+	case <-w.done:
+		w.stopped = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestManager_StopsWorkersWithinDeadline(t *testing.T) {
+	var order []string
+	var mu sync.Mutex
+	first := newFakeWorker("first", 10*time.Millisecond, &order, &mu)
+	second := newFakeWorker("second", 10*time.Millisecond, &order, &mu)
+
+	m := New(nil)
+	require.NoError(t, m.Add("first", first))
+	require.NoError(t, m.Add("second", second))
+	require.NoError(t, m.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, m.Shutdown(ctx))
+
+	assert.True(t, first.stopped)
+	assert.True(t, second.stopped)
+	assert.Equal(t, []string{"second", "first"}, order, "workers stop in reverse order")
+}
+
+func TestManager_ShutdownReportsWorkersPastDeadline(t *testing.T) {
+	var order []string
+	var mu sync.Mutex
+	slow := newFakeWorker("slow", time.Second, &order, &mu)
+	fast := newFakeWorker("fast", 0, &order, &mu)
+
+	m := New(nil)
+	require.NoError(t, m.Add("fast", fast))
+	require.NoError(t, m.Add("slow", slow))
+	require.NoError(t, m.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := m.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "failed to stop slow")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "shutdown is bounded by the deadline")
+	assert.Equal(t, []string{"slow", "fast"}, order, "a slow worker does not keep the others running")
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	startErr := errors.New("boom")
+	stopped := false
+
+	m := New(nil)
+	require.NoError(t, m.Add("running", StopFunc(func(context.Context) error {
+		stopped = true
+		return nil
+	})))
+	require.NoError(t, m.Add("broken", failingWorker{err: startErr}))
+
+	err := m.Start(context.Background())
+	assert.ErrorIs(t, err, startErr)
+	assert.ErrorContains(t, err, "failed to start broken")
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.True(t, stopped, "workers started before the failure are still stopped")
+}
+
+func TestManager_AddAfterStart(t *testing.T) {
+	m := New(nil)
+	require.NoError(t, m.Start(context.Background()))
+
+	assert.ErrorIs(t, m.Add("late", StopFunc(func(context.Context) error { return nil })), ErrAlreadyStarted)
+	assert.ErrorIs(t, m.Start(context.Background()), ErrAlreadyStarted)
+}
+
+type failingWorker struct {
+	err error
+}
+
+func (w failingWorker) Start(context.Context) error { return w.err }
+
+func (w failingWorker) Stop(context.Context) error { return nil }
@@ -0,0 +1,48 @@
+// Package logging builds the application's slog logger from LoggingConfig so
+// startup and request logs share one format and destination.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
+)
+
+// Format values accepted by LoggingConfig.Format
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// New builds the logger described by cfg, tagging every entry with service.
+// Logs go to stdout unless cfg.File is set; the returned close function
+// releases the file and must be called on shutdown.
+func New(cfg config.LoggingConfig, service string) (*slog.Logger, func() error, error) {
+	if cfg.File == "" {
+		return NewWithWriter(os.Stdout, cfg, service), func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return NewWithWriter(f, cfg, service), f.Close, nil
+}
+
+// NewWithWriter builds the logger described by cfg writing to w, ignoring cfg.File
+func NewWithWriter(w io.Writer, cfg config.LoggingConfig, service string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.GetLogLevel()}
+
+	var handler slog.Handler
+	if cfg.Format == FormatConsole {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(handler).With(slog.String("service", service), version.LogAttr())
+}
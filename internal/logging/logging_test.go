@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+)
+
+// logStartupAndRequest writes a startup line and a request line through one logger
+func logStartupAndRequest(t *testing.T, cfg config.LoggingConfig) []string {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, cfg, "Test API")
+
+	logger.Info("Starting Go REST API Boilerplate...")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Logger(&middleware.LoggerConfig{Logger: logger}))
+	router.GET("/users", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	return lines
+}
+
+func TestNewWithWriter_JSON(t *testing.T) {
+	for _, line := range logStartupAndRequest(t, config.LoggingConfig{Level: "info", Format: FormatJSON}) {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), "every line is JSON: %s", line)
+		assert.Equal(t, "Test API", entry["service"])
+		assert.Contains(t, entry, "version")
+	}
+}
+
+func TestNewWithWriter_Console(t *testing.T) {
+	lines := logStartupAndRequest(t, config.LoggingConfig{Level: "info", Format: FormatConsole})
+
+	assert.Contains(t, lines[0], `msg="Starting Go REST API Boilerplate..."`)
+	assert.Contains(t, lines[1], `msg="HTTP Request"`)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "time="), "every line is key=value: %s", line)
+		assert.Contains(t, line, `service="Test API"`)
+	}
+}
+
+func TestNewWithWriter_DefaultsToJSON(t *testing.T) {
+	var buf bytes.Buffer
+	NewWithWriter(&buf, config.LoggingConfig{}, "api").Info("hello")
+
+	assert.True(t, json.Valid(buf.Bytes()))
+}
+
+func TestNewWithWriter_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, config.LoggingConfig{Level: "warn"}, "api")
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+}
+
+func TestNew_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o600))
+
+	logger, closeLogger, err := New(config.LoggingConfig{File: path}, "api")
+	require.NoError(t, err)
+	logger.Info("written to file")
+	require.NoError(t, closeLogger())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "existing\n"), "the file is appended to")
+	assert.Contains(t, string(data), "written to file")
+
+	_, _, err = New(config.LoggingConfig{File: filepath.Join(t.TempDir(), "missing", "app.log")}, "api")
+	assert.ErrorContains(t, err, "failed to open log file")
+}
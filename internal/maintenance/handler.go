@@ -0,0 +1,74 @@
+package maintenance
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// StatusResponse represents the current maintenance mode state
+type StatusResponse struct {
+	Enabled    bool `json:"enabled"`
+	RetryAfter int  `json:"retry_after"`
+}
+
+// UpdateRequest represents a maintenance mode toggle request
+type UpdateRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// Handler exposes admin endpoints for inspecting and toggling maintenance mode
+type Handler struct {
+	mode *Mode
+}
+
+// NewHandler creates a new maintenance handler
+func NewHandler(mode *Mode) *Handler {
+	return &Handler{mode: mode}
+}
+
+// GetStatus godoc
+// @Summary Get maintenance mode status (Admin only)
+// @Description Report whether the API is currently in maintenance mode
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} errors.Response{success=bool,data=StatusResponse} "Current maintenance state"
+// @Failure 403 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Admin access required"
+// @Router /api/v1/admin/maintenance [get]
+func (h *Handler) GetStatus(c *gin.Context) {
+	apiErrors.Respond(c, http.StatusOK, h.status())
+}
+
+// Update godoc
+// @Summary Toggle maintenance mode (Admin only)
+// @Description Enable or disable maintenance mode at runtime; mutating requests receive 503 while enabled
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateRequest true "Maintenance toggle"
+// @Success 200 {object} errors.Response{success=bool,data=StatusResponse} "Updated maintenance state"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Validation error"
+// @Failure 403 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Admin access required"
+// @Router /api/v1/admin/maintenance [put]
+func (h *Handler) Update(c *gin.Context) {
+	var req UpdateRequest
+	if err := apiErrors.BindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	h.mode.SetEnabled(*req.Enabled)
+
+	apiErrors.Respond(c, http.StatusOK, h.status())
+}
+
+func (h *Handler) status() StatusResponse {
+	return StatusResponse{
+		Enabled:    h.mode.Enabled(),
+		RetryAfter: h.mode.RetryAfter(),
+	}
+}
@@ -0,0 +1,90 @@
+package maintenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+func setupHandlerRouter(mode *Mode) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(mode)
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(Middleware(mode, "/admin/maintenance"))
+	router.GET("/admin/maintenance", handler.GetStatus)
+	router.PUT("/admin/maintenance", handler.Update)
+	router.POST("/resource", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	return router
+}
+
+func TestHandler_GetStatus(t *testing.T) {
+	router := setupHandlerRouter(NewMode(true, 60))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Success bool           `json:"success"`
+		Data    StatusResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.True(t, resp.Data.Enabled)
+	assert.Equal(t, 60, resp.Data.RetryAfter)
+}
+
+func TestHandler_Update_TogglesMode(t *testing.T) {
+	mode := NewMode(false, 60)
+	router := setupHandlerRouter(mode)
+
+	toggle := func(enabled bool) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]bool{"enabled": enabled})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := toggle(true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, mode.Enabled())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/resource", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = toggle(false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, mode.Enabled())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/resource", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestHandler_Update_RequiresEnabledField(t *testing.T) {
+	mode := NewMode(true, 60)
+	router := setupHandlerRouter(mode)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.True(t, mode.Enabled())
+}
@@ -0,0 +1,44 @@
+package maintenance
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// Middleware rejects mutating requests with 503 while maintenance mode is enabled.
+// Safe methods, health probes and the given exempt paths are always allowed through.
+func Middleware(mode *Mode, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool)
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if !mode.Enabled() || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		path := c.Request.URL.Path
+		if exempt[path] || strings.HasPrefix(path, "/health") {
+			c.Next()
+			return
+		}
+
+		_ = c.Error(apiErrors.ServiceUnavailable("Service is under maintenance, please try again later", mode.RetryAfter()))
+		c.Abort()
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,120 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+func setupMaintenanceRouter(mode *Mode, exemptPaths ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(Middleware(mode, exemptPaths...))
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/users/1", ok)
+	router.POST("/api/v1/users", ok)
+	router.PUT("/api/v1/users/1", ok)
+	router.PATCH("/api/v1/users/1", ok)
+	router.DELETE("/api/v1/users/1", ok)
+	router.POST("/health/check", ok)
+	router.PUT("/api/v1/admin/maintenance", ok)
+
+	return router
+}
+
+func TestMiddleware_BlocksMutatingRequestsWhenEnabled(t *testing.T) {
+	router := setupMaintenanceRouter(NewMode(true, 120))
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/users"},
+		{http.MethodPut, "/api/v1/users/1"},
+		{http.MethodPatch, "/api/v1/users/1"},
+		{http.MethodDelete, "/api/v1/users/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, "120", w.Header().Get("Retry-After"))
+			assert.Contains(t, w.Body.String(), apiErrors.CodeServiceUnavailable)
+		})
+	}
+}
+
+func TestMiddleware_AllowsGetWhenEnabled(t *testing.T) {
+	router := setupMaintenanceRouter(NewMode(true, 120))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestMiddleware_AllowsHealthAndExemptPaths(t *testing.T) {
+	router := setupMaintenanceRouter(NewMode(true, 120), "/api/v1/admin/maintenance")
+
+	for _, tt := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/health/check"},
+		{http.MethodPut, "/api/v1/admin/maintenance"},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+	}
+}
+
+func TestMiddleware_AllowsEverythingWhenDisabled(t *testing.T) {
+	router := setupMaintenanceRouter(NewMode(false, 120))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMiddleware_OmitsRetryAfterWhenZero(t *testing.T) {
+	router := setupMaintenanceRouter(NewMode(true, 0))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestMode_Reload(t *testing.T) {
+	mode := NewMode(false, 120)
+	mode.SetEnabled(true)
+
+	mode.Reload(config.MaintenanceConfig{Enabled: false, RetryAfter: 30})
+	assert.False(t, mode.Enabled(), "the reloaded configuration replaces the runtime toggle")
+	assert.Equal(t, 30, mode.RetryAfter())
+
+	mode.Reload(config.MaintenanceConfig{Enabled: true, RetryAfter: 30})
+	assert.True(t, mode.Enabled())
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import (
+	"sync/atomic"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+)
+
+// Mode holds the runtime maintenance state shared by the middleware and the admin handler.
+type Mode struct {
+	enabled    atomic.Bool
+	retryAfter atomic.Int64
+}
+
+// NewMode creates a maintenance mode with the given initial state and Retry-After seconds.
+func NewMode(enabled bool, retryAfter int) *Mode {
+	m := &Mode{}
+	m.enabled.Store(enabled)
+	m.retryAfter.Store(int64(retryAfter))
+	return m
+}
+
+// Enabled reports whether maintenance mode is currently active.
+func (m *Mode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// SetEnabled switches maintenance mode on or off.
+func (m *Mode) SetEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// RetryAfter returns the number of seconds clients are asked to wait before retrying.
+func (m *Mode) RetryAfter() int {
+	return int(m.retryAfter.Load())
+}
+
+// Reload applies a reloaded configuration, replacing any state set at runtime
+// through the admin endpoint.
+func (m *Mode) Reload(cfg config.MaintenanceConfig) {
+	m.retryAfter.Store(int64(cfg.RetryAfter))
+	m.enabled.Store(cfg.Enabled)
+}
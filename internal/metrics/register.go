@@ -0,0 +1,24 @@
+// Package metrics holds the helpers shared by the packages that export
+// Prometheus collectors.
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers collector and returns it, or returns the collector that
+// was already registered for the same metric, so constructors can run more
+// than once against one registry
+func Register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return collector
+}
@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	opts := prometheus.CounterOpts{Name: "test_total", Help: "Test counter."}
+
+	first := Register(registry, prometheus.NewCounter(opts))
+	second := Register(registry, prometheus.NewCounter(opts))
+
+	assert.Same(t, first, second, "the already registered collector is reused")
+}
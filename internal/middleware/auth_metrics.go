@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+// LoginResult is the outcome label of auth_logins_total
+type LoginResult string
+
+// Login outcomes
+const (
+	LoginSuccess            LoginResult = "success"
+	LoginInvalidCredentials LoginResult = "invalid_credentials"
+	LoginAccountDisabled    LoginResult = "account_disabled"
+	LoginShed               LoginResult = "shed"
+	LoginError              LoginResult = "error"
+)
+
+// RefreshResult is the outcome label of auth_token_refreshes_total
+type RefreshResult string
+
+// Token refresh outcomes
+const (
+	RefreshSuccess RefreshResult = "success"
+	// RefreshInvalid covers malformed, unknown and expired refresh tokens
+	RefreshInvalid RefreshResult = "invalid"
+	RefreshReused  RefreshResult = "reused"
+	RefreshRevoked RefreshResult = "revoked"
+	// RefreshConcurrent is a refresh that lost the race to rotate the same token
+	RefreshConcurrent RefreshResult = "concurrent"
+	RefreshError      RefreshResult = "error"
+)
+
+var (
+	loginResults   = []LoginResult{LoginSuccess, LoginInvalidCredentials, LoginAccountDisabled, LoginShed, LoginError}
+	refreshResults = []RefreshResult{RefreshSuccess, RefreshInvalid, RefreshReused, RefreshRevoked, RefreshConcurrent, RefreshError}
+)
+
+// AuthMetrics counts authentication outcomes for security dashboards. A nil
+// *AuthMetrics records nothing, so handlers can use it unconditionally.
+type AuthMetrics struct {
+	logins    *prometheus.CounterVec
+	refreshes *prometheus.CounterVec
+}
+
+// NewAuthMetrics creates and registers the authentication counters. Only the
+// Namespace and Registerer fields of cfg are used.
+func NewAuthMetrics(cfg MetricsConfig) *AuthMetrics {
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &AuthMetrics{
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "auth_logins_total",
+			Help:      "Total number of password login attempts by result.",
+		}, []string{"result"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "auth_token_refreshes_total",
+			Help:      "Total number of refresh token exchanges by result.",
+		}, []string{"result"}),
+	}
+
+	m.logins = metrics.Register(registerer, m.logins)
+	m.refreshes = metrics.Register(registerer, m.refreshes)
+
+	// WHY: Export every known outcome at zero so rate() works before the first failure
+	for _, result := range loginResults {
+		m.logins.WithLabelValues(string(result))
+	}
+	for _, result := range refreshResults {
+		m.refreshes.WithLabelValues(string(result))
+	}
+
+	return m
+}
+
+// RecordLogin counts a login attempt
+func (m *AuthMetrics) RecordLogin(result LoginResult) {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(string(result)).Inc()
+}
+
+// RecordRefresh counts a refresh token exchange
+func (m *AuthMetrics) RecordRefresh(result RefreshResult) {
+	if m == nil {
+		return
+	}
+	m.refreshes.WithLabelValues(string(result)).Inc()
+}
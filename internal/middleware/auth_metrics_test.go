@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMetrics_Record(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewAuthMetrics(MetricsConfig{Namespace: "orders", Registerer: registry})
+
+	metrics.RecordLogin(LoginInvalidCredentials)
+	metrics.RecordLogin(LoginInvalidCredentials)
+	metrics.RecordLogin(LoginSuccess)
+	metrics.RecordRefresh(RefreshRevoked)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.logins.WithLabelValues("invalid_credentials")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.logins.WithLabelValues("success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.refreshes.WithLabelValues("revoked")))
+
+	// Every known outcome is exported, even before it first happens
+	count, err := testutil.GatherAndCount(registry, "orders_auth_logins_total", "orders_auth_token_refreshes_total")
+	assert.NoError(t, err)
+	assert.Equal(t, len(loginResults)+len(refreshResults), count)
+}
+
+func TestAuthMetrics_ReusesRegisteredCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first := NewAuthMetrics(MetricsConfig{Registerer: registry})
+	second := NewAuthMetrics(MetricsConfig{Registerer: registry})
+
+	second.RecordLogin(LoginSuccess)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(first.logins.WithLabelValues("success")))
+}
+
+func TestAuthMetrics_NilIsNoop(t *testing.T) {
+	var metrics *AuthMetrics
+
+	assert.NotPanics(t, func() {
+		metrics.RecordLogin(LoginSuccess)
+		metrics.RecordRefresh(RefreshSuccess)
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControlHeader is the response header carrying caching directives
+const CacheControlHeader = "Cache-Control"
+
+// NoStore marks responses as not cacheable by browsers or proxies. API
+// responses carry user data, so it is installed for every route and static
+// routes override it with CacheControl.
+func NoStore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(CacheControlHeader, "no-store")
+		c.Next()
+	}
+}
+
+// CacheControl lets clients cache responses for maxAge by setting Cache-Control
+// and the equivalent Expires header for HTTP/1.0 caches. A zero maxAge makes
+// clients revalidate on every use.
+func CacheControl(maxAge time.Duration) gin.HandlerFunc {
+	seconds := int(maxAge.Seconds())
+	if seconds <= 0 {
+		return func(c *gin.Context) {
+			c.Header(CacheControlHeader, "no-cache")
+			c.Next()
+		}
+	}
+
+	value := "public, max-age=" + strconv.Itoa(seconds)
+	return func(c *gin.Context) {
+		c.Header(CacheControlHeader, value)
+		c.Header("Expires", time.Now().Add(maxAge).UTC().Format(http.TimeFormat))
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheRouter(static gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.Use(NoStore())
+	router.GET("/api", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/static", static, func(c *gin.Context) {
+		c.String(http.StatusOK, "asset")
+	})
+	return router
+}
+
+func TestCacheControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("API routes are not stored", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newCacheRouter(CacheControl(time.Hour)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+
+		assert.Equal(t, "no-store", w.Header().Get(CacheControlHeader))
+		assert.Empty(t, w.Header().Get("Expires"))
+	})
+
+	t.Run("static routes are cached for max age", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newCacheRouter(CacheControl(time.Hour)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static", nil))
+
+		assert.Equal(t, "public, max-age=3600", w.Header().Get(CacheControlHeader))
+		expires, err := http.ParseTime(w.Header().Get("Expires"))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expires, 2*time.Second)
+	})
+
+	t.Run("zero max age revalidates", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newCacheRouter(CacheControl(0)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static", nil))
+
+		assert.Equal(t, "no-cache", w.Header().Get(CacheControlHeader))
+		assert.Empty(t, w.Header().Get("Expires"))
+	})
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+// InFlightRetryAfter is the Retry-After, in seconds, of requests shed by the in-flight caps
+const InFlightRetryAfter = 1
+
+// Labels of the in-flight cap metrics
+const (
+	inFlightLimitGlobal = "global"
+	inFlightLimitUser   = "user"
+)
+
+// InFlightMetrics holds the collectors of the in-flight caps. A nil
+// *InFlightMetrics records nothing.
+type InFlightMetrics struct {
+	inFlight *prometheus.GaugeVec
+	shed     *prometheus.CounterVec
+}
+
+// NewInFlightMetrics creates and registers the in-flight cap collectors. Only
+// the Namespace and Registerer fields of cfg are used.
+func NewInFlightMetrics(cfg MetricsConfig) *InFlightMetrics {
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &InFlightMetrics{
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Name:      "inflight_limit_requests",
+			Help:      "Number of requests currently holding a slot of each in-flight cap.",
+		}, []string{"limit"}),
+		shed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "inflight_limit_shed_total",
+			Help:      "Total number of requests rejected because an in-flight cap was reached.",
+		}, []string{"limit"}),
+	}
+
+	m.inFlight = metrics.Register(registerer, m.inFlight)
+	m.shed = metrics.Register(registerer, m.shed)
+
+	return m
+}
+
+func (m *InFlightMetrics) acquired(limit string) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(limit).Inc()
+}
+
+func (m *InFlightMetrics) released(limit string) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(limit).Dec()
+}
+
+func (m *InFlightMetrics) rejected(limit string) {
+	if m == nil {
+		return
+	}
+	m.shed.WithLabelValues(limit).Inc()
+}
+
+// MaxInFlight caps the requests served at once across all clients, so a burst
+// of slow requests cannot exhaust the database pool for everyone. Requests
+// over the cap are shed with 503 and Retry-After; requests to skipPaths, such
+// as health checks and metrics, are neither counted nor shed.
+func MaxInFlight(limit int, metrics *InFlightMetrics, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+	var current atomic.Int64
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		if current.Add(1) > int64(limit) {
+			current.Add(-1)
+			metrics.rejected(inFlightLimitGlobal)
+			_ = c.Error(apiErrors.ServiceUnavailable("Too many requests in progress, please try again shortly", InFlightRetryAfter))
+			c.Abort()
+			return
+		}
+		metrics.acquired(inFlightLimitGlobal)
+		defer func() {
+			current.Add(-1)
+			metrics.released(inFlightLimitGlobal)
+		}()
+
+		c.Next()
+	}
+}
+
+// MaxInFlightPerUser caps the requests each authenticated user has in flight,
+// keyed by the user ID of the token claims, so one client cannot hold many
+// slow requests open at once. Requests over the cap get 429 with Retry-After.
+// It must run after the auth middleware; anonymous requests pass.
+func MaxInFlightPerUser(limit int, metrics *InFlightMetrics) gin.HandlerFunc {
+	return newUserInFlight(limit).middleware(metrics)
+}
+
+// userInFlight counts the requests in flight per user. A user's counter is
+// dropped when their last request finishes, so idle users hold no memory.
+type userInFlight struct {
+	limit  int
+	mu     sync.Mutex
+	counts map[uint]int
+}
+
+func newUserInFlight(limit int) *userInFlight {
+	return &userInFlight{limit: limit, counts: make(map[uint]int)}
+}
+
+func (u *userInFlight) acquire(userID uint) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.counts[userID] >= u.limit {
+		return false
+	}
+	u.counts[userID]++
+	return true
+}
+
+func (u *userInFlight) release(userID uint) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.counts[userID] <= 1 {
+		delete(u.counts, userID)
+		return
+	}
+	u.counts[userID]--
+}
+
+// users reports how many users have a request in flight
+func (u *userInFlight) users() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.counts)
+}
+
+func (u *userInFlight) middleware(metrics *InFlightMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := contextutil.GetUserID(c)
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		if !u.acquire(userID) {
+			metrics.rejected(inFlightLimitUser)
+			_ = c.Error(apiErrors.TooManyRequests(InFlightRetryAfter))
+			c.Abort()
+			return
+		}
+		metrics.acquired(inFlightLimitUser)
+		defer func() {
+			u.release(userID)
+			metrics.released(inFlightLimitUser)
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// blockingRouter serves /slow until release is closed, signalling entered as
+// each request starts, and answers /fast and /health at once
+func blockingRouter(entered chan<- struct{}, release <-chan struct{}, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			userID, _ := strconv.ParseUint(id, 10, 32)
+			c.Set(auth.KeyUser, &auth.Claims{UserID: uint(userID)})
+		}
+		c.Next()
+	})
+	router.Use(middlewares...)
+	router.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/fast", ok)
+	router.GET("/health", ok)
+	return router
+}
+
+func serveAs(router *gin.Engine, path, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// startSlow starts n blocked requests and waits until every one is being served
+func startSlow(t *testing.T, router *gin.Engine, entered <-chan struct{}, n int, userID string) *sync.WaitGroup {
+	t.Helper()
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveAs(router, "/slow", userID)
+		}()
+	}
+	for range n {
+		<-entered
+	}
+	return &wg
+}
+
+func TestMaxInFlight(t *testing.T) {
+	metrics := NewInFlightMetrics(MetricsConfig{Registerer: prometheus.NewRegistry()})
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := blockingRouter(entered, release, MaxInFlight(2, metrics, "/health"))
+
+	wg := startSlow(t, router, entered, 2, "")
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.inFlight.WithLabelValues(inFlightLimitGlobal)))
+
+	w := serveAs(router, "/fast", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	var response apiErrors.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Success)
+	assert.Equal(t, apiErrors.CodeServiceUnavailable, response.Error.Code)
+	require.NotNil(t, response.Error.RetryAfter)
+	assert.Equal(t, InFlightRetryAfter, *response.Error.RetryAfter)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.shed.WithLabelValues(inFlightLimitGlobal)))
+
+	assert.Equal(t, http.StatusOK, serveAs(router, "/health", "").Code, "skipped paths answer while shedding")
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, serveAs(router, "/fast", "").Code, "slots are freed when requests finish")
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.inFlight.WithLabelValues(inFlightLimitGlobal)))
+}
+
+func TestMaxInFlightPerUser(t *testing.T) {
+	metrics := NewInFlightMetrics(MetricsConfig{Registerer: prometheus.NewRegistry()})
+	limiter := newUserInFlight(2)
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := blockingRouter(entered, release, limiter.middleware(metrics))
+
+	wg := startSlow(t, router, entered, 2, "1")
+	assert.Equal(t, 1, limiter.users())
+
+	w := serveAs(router, "/fast", "1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	var response apiErrors.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, apiErrors.CodeTooManyRequests, response.Error.Code)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.shed.WithLabelValues(inFlightLimitUser)))
+
+	assert.Equal(t, http.StatusOK, serveAs(router, "/fast", "2").Code, "other users have their own cap")
+	assert.Equal(t, http.StatusOK, serveAs(router, "/fast", "").Code, "anonymous requests are not capped")
+
+	close(release)
+	wg.Wait()
+	assert.Zero(t, limiter.users(), "counters of users with nothing in flight are dropped")
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.inFlight.WithLabelValues(inFlightLimitUser)))
+	assert.Equal(t, http.StatusOK, serveAs(router, "/fast", "1").Code)
+}
+
+func TestUserInFlight_ConcurrentRequests(t *testing.T) {
+	limiter := newUserInFlight(3)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func(userID uint) {
+			defer wg.Done()
+			for range 100 {
+				if limiter.acquire(userID) {
+					limiter.release(userID)
+				}
+			}
+		}(uint(i%5 + 1))
+	}
+	wg.Wait()
+
+	assert.Zero(t, limiter.users())
+}
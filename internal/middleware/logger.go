@@ -1,12 +1,20 @@
 package middleware
 
 import (
+	"io"
 	"log/slog"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tracing"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
 // LoggerConfig defines the configuration for the logger middleware
@@ -19,30 +27,27 @@ type LoggerConfig struct {
 
 // DefaultLoggerConfig returns a default configuration for the logger middleware
 func DefaultLoggerConfig() *LoggerConfig {
-	// Create a JSON logger that writes to stdout
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
-
 	return &LoggerConfig{
 		SkipPaths: []string{"/health"},
-		Logger:    logger,
+		Logger:    newJSONLogger(os.Stdout, slog.LevelInfo),
 	}
 }
 
 // NewLoggerConfig creates a logger configuration from logging config
 func NewLoggerConfig(logLevel slog.Level, skipPaths []string) *LoggerConfig {
-	// Create a JSON logger that writes to stdout
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-
 	return &LoggerConfig{
 		SkipPaths: skipPaths,
-		Logger:    logger,
+		Logger:    newJSONLogger(os.Stdout, logLevel),
 	}
 }
 
+// newJSONLogger creates a JSON logger tagging every entry with the build version
+func newJSONLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+	})).With(version.LogAttr())
+}
+
 // Logger returns a Gin middleware for structured request logging
 func Logger(config *LoggerConfig) gin.HandlerFunc {
 	if config == nil {
@@ -67,12 +72,14 @@ func Logger(config *LoggerConfig) gin.HandlerFunc {
 		raw := c.Request.URL.RawQuery
 
 		// Generate request ID if not present
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(requestid.Header)
 		if requestID == "" {
 			requestID = uuid.New().String()
 		}
 		c.Set("request_id", requestID)
-		c.Writer.Header().Set("X-Request-ID", requestID)
+		c.Writer.Header().Set(requestid.Header, requestID)
+		// WHY: Outbound clients read the ID from the request context to forward it
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
 
 		// Process request
 		c.Next()
@@ -88,9 +95,10 @@ func Logger(config *LoggerConfig) gin.HandlerFunc {
 		// Get response status
 		statusCode := c.Writer.Status()
 
-		// Add query string to path if present
+		// Add query string to the raw path if present
+		rawPath := path
 		if raw != "" {
-			path = path + "?" + raw
+			rawPath = path + "?" + redactQuery(raw)
 		}
 
 		// Determine log level based on status code
@@ -102,30 +110,48 @@ func Logger(config *LoggerConfig) gin.HandlerFunc {
 		}
 
 		// Log structured data
-		logger.Log(c.Request.Context(), level, "HTTP Request",
-			slog.String("request_id", requestID),
+		attrs := []any{slog.String("request_id", requestID)}
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			attrs = append(attrs, slog.String("trace_id", traceID))
+		}
+		logger.Log(c.Request.Context(), level, "HTTP Request", append(attrs,
 			slog.String("method", c.Request.Method),
-			slog.String("path", path),
+			slog.String("path", RouteLabel(c)),
+			slog.String("raw_path", rawPath),
 			slog.Int("status", statusCode),
 			slog.Duration("duration", duration),
 			slog.String("duration_ms", formatDuration(duration)),
 			slog.String("client_ip", c.ClientIP()),
 			slog.String("user_agent", c.Request.UserAgent()),
 			slog.Int("response_size", c.Writer.Size()),
-		)
+		)...)
 
 		// Log error if present
 		if len(c.Errors) > 0 {
 			for _, e := range c.Errors {
-				logger.Error("Request error",
-					slog.String("request_id", requestID),
-					slog.String("error", e.Error()),
-				)
+				logger.Error("Request error", append(attrs, slog.String("error", e.Error()))...)
 			}
 		}
 	}
 }
 
+// redactedQueryParams carry credentials, such as the WebSocket access token or
+// the token of an email link, and are never logged
+var redactedQueryParams = []string{"access_token", "token"}
+
+// redactQuery replaces the values of redactedQueryParams in a raw query string,
+// leaving the rest as the client sent it
+func redactQuery(raw string) string {
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && slices.Contains(redactedQueryParams, name) {
+			parts[i] = key + "=<redacted>"
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
 // formatDuration formats duration to milliseconds string
 func formatDuration(d time.Duration) string {
 	return d.Round(time.Millisecond).String()
@@ -133,13 +159,9 @@ func formatDuration(d time.Duration) string {
 
 // LoggerWithConfig returns a Gin middleware for structured request logging with custom configuration
 func LoggerWithConfig(skipPaths []string, logLevel slog.Level) gin.HandlerFunc {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-
 	config := &LoggerConfig{
 		SkipPaths: skipPaths,
-		Logger:    logger,
+		Logger:    newJSONLogger(os.Stdout, logLevel),
 	}
 
 	return Logger(config)
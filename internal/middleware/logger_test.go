@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/requestid"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
 func init() {
@@ -153,7 +156,9 @@ func TestLoggerWithProvidedRequestID(t *testing.T) {
 
 	router := gin.New()
 	router.Use(Logger(config))
+	var contextID string
 	router.GET("/test", func(c *gin.Context) {
+		contextID = requestid.FromContext(c.Request.Context())
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
@@ -169,6 +174,11 @@ func TestLoggerWithProvidedRequestID(t *testing.T) {
 	if !strings.Contains(logOutput, providedID) {
 		t.Errorf("Expected log to contain provided request ID: %s", providedID)
 	}
+
+	// Verify outbound clients can read it from the request context
+	if contextID != providedID {
+		t.Errorf("Expected request context to carry request ID %s, got %q", providedID, contextID)
+	}
 }
 
 // TestLoggerStatusCodes tests logging of different status codes
@@ -312,6 +322,31 @@ func TestLoggerQueryParameters(t *testing.T) {
 	}
 }
 
+// TestLoggerRedactsTokens tests that credentials in the query string are not logged
+func TestLoggerRedactsTokens(t *testing.T) {
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(Logger(&LoggerConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}))
+	router.GET("/api/v1/ws", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/ws?access_token=secret-jwt&page=2&token=reset-token&tokens=kept", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	want := "/api/v1/ws?access_token=<redacted>&page=2&token=<redacted>&tokens=kept"
+	if entry["raw_path"] != want {
+		t.Errorf("Expected raw_path %q, got %v", want, entry["raw_path"])
+	}
+	if strings.Contains(buf.String(), "secret-jwt") || strings.Contains(buf.String(), "reset-token") {
+		t.Error("Expected tokens to be left out of the log")
+	}
+}
+
 // TestNewLoggerConfig tests the NewLoggerConfig function
 func TestNewLoggerConfig(t *testing.T) {
 	tests := []struct {
@@ -421,3 +456,59 @@ func TestNewLoggerConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestNewJSONLogger_IncludesVersion tests that every log entry carries the build version
+func TestNewJSONLogger_IncludesVersion(t *testing.T) {
+	oldVersion := version.Version
+	version.Version = "v1.2.3"
+	defer func() { version.Version = oldVersion }()
+
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(Logger(&LoggerConfig{Logger: newJSONLogger(&buf, slog.LevelInfo)}))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	if entry["version"] != "v1.2.3" {
+		t.Errorf("Expected version v1.2.3 in log entry, got %v", entry["version"])
+	}
+}
+
+// TestLoggerRouteTemplate tests that the path field carries the route template metrics use
+func TestLoggerRouteTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(Logger(&LoggerConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}))
+	router.GET("/api/v1/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/users/42?fields=name", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	if entry["path"] != "/api/v1/users/:id" {
+		t.Errorf("Expected path to be the route template, got %v", entry["path"])
+	}
+	if entry["raw_path"] != "/api/v1/users/42?fields=name" {
+		t.Errorf("Expected raw_path to be the requested path, got %v", entry["raw_path"])
+	}
+
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log output: %v", err)
+	}
+	if entry["path"] != "unmatched" {
+		t.Errorf("Expected unmatched path label, got %v", entry["path"])
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
+)
+
+func setupMetricsRouter(cfg MetricsConfig) (*gin.Engine, *MetricsRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder, mw := NewMetricsMiddleware(cfg)
+
+	r := gin.New()
+	r.Use(mw)
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	r.GET("/metrics", gin.WrapH(recorder.Handler()))
+	return r, recorder
+}
+
+func scrape(t *testing.T, r *gin.Engine) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsMiddleware_RecordsRequests(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r, recorder := setupMetricsRouter(MetricsConfig{Registerer: registry, Gatherer: registry})
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(recorder.requests.WithLabelValues("GET", "/users/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.requests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(recorder.inFlight))
+	assert.Equal(t, 1, testutil.CollectAndCount(recorder.duration.WithLabelValues("GET", "/users/:id").(prometheus.Histogram)))
+}
+
+func TestMetricsMiddleware_DefaultBucketsAndNames(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r, _ := setupMetricsRouter(MetricsConfig{Registerer: registry, Gatherer: registry})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	output := scrape(t, r)
+
+	assert.Contains(t, output, "\nhttp_requests_total{")
+	assert.Contains(t, output, `http_request_duration_seconds_bucket{method="GET",path="/users/:id",le="0.005"}`)
+	assert.Contains(t, output, `http_request_duration_seconds_bucket{method="GET",path="/users/:id",le="10"}`)
+	assert.Contains(t, output, `http_response_size_bytes_bucket{method="GET",path="/users/:id",le="1e+08"}`)
+}
+
+func TestMetricsMiddleware_CustomBucketsAndNamespace(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r, _ := setupMetricsRouter(MetricsConfig{
+		Namespace:       "orders",
+		DurationBuckets: []float64{0.001, 0.01, 0.05},
+		SizeBuckets:     []float64{64, 1024},
+		Registerer:      registry,
+		Gatherer:        registry,
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	output := scrape(t, r)
+
+	for _, le := range []string{"0.001", "0.01", "0.05", "+Inf"} {
+		assert.Contains(t, output, `orders_http_request_duration_seconds_bucket{method="GET",path="/users/:id",le="`+le+`"} 1`)
+	}
+	assert.NotContains(t, output, `le="0.005"`, "default buckets are replaced, not extended")
+	assert.Contains(t, output, `orders_http_response_size_bytes_bucket{method="GET",path="/users/:id",le="64"} 1`)
+	assert.Contains(t, output, `orders_http_response_size_bytes_bucket{method="GET",path="/users/:id",le="1024"} 1`)
+	assert.Contains(t, output, `orders_http_requests_total{method="GET",path="/users/:id",status="200"} 1`)
+	assert.Contains(t, output, "orders_http_requests_in_flight")
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "http_") {
+			t.Errorf("unprefixed metric exported: %s", line)
+		}
+	}
+}
+
+func TestNewMetricsRecorder_ReusesRegisteredCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first := NewMetricsRecorder(MetricsConfig{Registerer: registry, Gatherer: registry})
+	second := NewMetricsRecorder(MetricsConfig{Registerer: registry, Gatherer: registry})
+
+	assert.Same(t, first.requests, second.requests)
+	assert.Same(t, first.duration, second.duration)
+}
+
+func TestMetricsRecorder_BuildInfo(t *testing.T) {
+	oldVersion, oldCommit, oldDate := version.Version, version.Commit, version.BuildDate
+	version.Version, version.Commit, version.BuildDate = "v1.2.3", "abc1234", "2026-01-02T03:04:05Z"
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildDate = oldVersion, oldCommit, oldDate
+	})
+
+	registry := prometheus.NewRegistry()
+	r, recorder := setupMetricsRouter(MetricsConfig{Namespace: "orders", Registerer: registry, Gatherer: registry})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.buildInfo.WithLabelValues("v1.2.3", "abc1234", "2026-01-02T03:04:05Z")))
+	assert.Contains(t, scrape(t, r), `orders_build_info{build_date="2026-01-02T03:04:05Z",commit="abc1234",version="v1.2.3"} 1`)
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	recorder := NewMetricsRecorder(MetricsConfig{Registerer: registry, Gatherer: registry})
+
+	newRouter := func(token string) *gin.Engine {
+		r := gin.New()
+		r.Use(apiErrors.ErrorHandler())
+		r.GET("/metrics", RequireBearerToken(token), gin.WrapH(recorder.Handler()))
+		return r
+	}
+	get := func(r *gin.Engine, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	protected := newRouter("scrape-secret")
+	assert.Equal(t, http.StatusOK, get(protected, "Bearer scrape-secret").Code)
+
+	for _, authorization := range []string{"", "Bearer wrong", "scrape-secret", "Basic c2NyYXBlLXNlY3JldA=="} {
+		w := get(protected, authorization)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	}
+
+	assert.Equal(t, http.StatusOK, get(newRouter(""), "").Code, "no token configured leaves the endpoint open")
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseTimeParam reads the query parameter name as an RFC3339 timestamp. A
+// missing parameter yields nil; the error names the parameter so callers can
+// wrap it in their own sentinel and show it to the client.
+func ParseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &t, nil
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parse := func(query string) (*time.Time, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		return ParseTimeParam(c, "from")
+	}
+
+	got, err := parse("")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = parse("from=2024-01-02T03:04:05Z")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	_, err = parse("from=yesterday")
+	assert.EqualError(t, err, "from must be an RFC3339 timestamp")
+}
@@ -2,81 +2,261 @@ package middleware
 
 import (
 	"math"
+	"net"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/hashicorp/golang-lru/v2/expirable"
 	"golang.org/x/time/rate"
 
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
 	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
 )
 
 // Storage abstracts the backing store for per-key limiters.
 type Storage interface {
+	// Add stores the limiter for a key and reports whether it was stored; it
+	// returns false when the key already has a limiter or the store refuses it
 	Add(string, *rate.Limiter) bool
 	Get(string) (*rate.Limiter, bool)
 }
 
-var (
-	// Default LRU capacity and TTL for limiter entries.
-	DefaultCacheSize = 5000
-	DefaultTTL       = 6 * time.Hour
-)
+// IPKey keys the limiter on the client IP.
+func IPKey(c *gin.Context) string {
+	ip := c.ClientIP()
+	if ip == "" {
+		ip = c.GetHeader("X-Forwarded-For")
+		if ip == "" {
+			ip = c.GetHeader("X-Real-IP")
+		}
+		if ip == "" {
+			ip = "unknown"
+		}
+	}
+	return ip
+}
+
+// UserOrIPKey keys the limiter on the authenticated user, falling back to the
+// client IP for anonymous requests. Must run after the auth middleware.
+func UserOrIPKey(c *gin.Context) string {
+	if userID := contextutil.GetUserID(c); userID != 0 {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip:" + IPKey(c)
+}
+
+// RateLimitOption configures optional rate limiter behavior
+type RateLimitOption func(*rateLimitOptions)
+
+type rateLimitOptions struct {
+	omitLegacyHeaders bool
+	storeOptions      []LRUStoreOption
+	warningThreshold  float64
+	overflowRequests  int
+	exemptions        []func(*gin.Context) bool
+}
+
+// WithoutLegacyHeaders stops the limiter from emitting the X-RateLimit-* headers,
+// leaving only the RateLimit-* headers and Retry-After
+func WithoutLegacyHeaders() RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.omitLegacyHeaders = true
+	}
+}
+
+// WithWarningThreshold adds X-RateLimit-Warning: true once the remaining requests
+// drop below fraction of the limit, so clients can slow down before being
+// blocked; zero disables the warning
+func WithWarningThreshold(fraction float64) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.warningThreshold = fraction
+	}
+}
+
+// WithOverflowRequests sets the budget per window of the bucket shared by keys
+// the store cannot hold; zero uses the same budget as every other key. A
+// smaller budget keeps a flood of unique keys from getting far.
+func WithOverflowRequests(requests int) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.overflowRequests = requests
+	}
+}
+
+// WithExemption lets requests for which exempt returns true skip the limiter; they
+// are neither counted nor given rate limit headers. A request matching any of
+// several exemptions is skipped.
+func WithExemption(exempt func(*gin.Context) bool) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.exemptions = append(o.exemptions, exempt)
+	}
+}
+
+// ExemptRoles exempts authenticated users holding any of roles. Roles are only
+// known once an auth middleware has run, so anonymous requests are never exempt.
+func ExemptRoles(roles ...string) func(*gin.Context) bool {
+	return func(c *gin.Context) bool {
+		for _, role := range roles {
+			if contextutil.HasRole(c, role) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExemptIPs exempts clients whose IP, as seen by IPKey, is one of ips or inside
+// one of its CIDR ranges. Entries that are neither are ignored.
+func ExemptIPs(ips ...string) func(*gin.Context) bool {
+	var networks []*net.IPNet
+	for _, entry := range ips {
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	return func(c *gin.Context) bool {
+		ip := net.ParseIP(IPKey(c))
+		if ip == nil {
+			return false
+		}
+		for _, network := range networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+}
 
-// Default in-memory store (LRU with TTL).
-var defaultStore = expirable.NewLRU[string, *rate.Limiter](DefaultCacheSize, nil, DefaultTTL)
+// WithStoreOptions configures the LRU store created when NewRateLimitMiddleware
+// is given a nil store
+func WithStoreOptions(opts ...LRUStoreOption) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.storeOptions = append(o.storeOptions, opts...)
+	}
+}
 
 // NewRateLimitMiddleware installs a token-bucket rate limiter per key.
 // R = requests / window (req/s). Burst = requests (allows short spikes up to N).
+//
+// Every response carries the IETF draft RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers, where Reset is the number of seconds until the bucket
+// is full again. The legacy X-RateLimit-* headers carry the same values except
+// that X-RateLimit-Reset is the Unix time of that moment.
+//
+// Remaining is the number of whole tokens left after the current request, so a
+// client can always spend it without being blocked.
+//
+// A nil store creates a new LRU store configured by WithStoreOptions. Keys the
+// store refuses share one overflow bucket, sized by WithOverflowRequests.
 func NewRateLimitMiddleware(
 	window time.Duration,
 	requests int,
 	keyFunc func(*gin.Context) string,
 	store Storage,
+	opts ...RateLimitOption,
 ) gin.HandlerFunc {
 
+	var options rateLimitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	if store == nil {
-		store = defaultStore
+		store = NewLRUStore(options.storeOptions...)
 	}
 
 	r := rate.Limit(float64(requests) / window.Seconds())
 	burst := requests
 
+	// WHY: Keys the store cannot hold share one bucket; a fresh limiter per
+	// request would let an attacker who overflows the store skip the limit
+	overflow := rate.NewLimiter(r, burst)
+	if options.overflowRequests > 0 {
+		overflow = rate.NewLimiter(rate.Limit(float64(options.overflowRequests)/window.Seconds()), options.overflowRequests)
+	}
+
 	return func(c *gin.Context) {
+		for _, exempt := range options.exemptions {
+			if exempt(c) {
+				c.Next()
+				return
+			}
+		}
+
 		key := keyFunc(c)
 
 		lim, ok := store.Get(key)
 		if !ok {
 			lim = rate.NewLimiter(r, burst)
-			store.Add(key, lim)
+			if !store.Add(key, lim) {
+				// WHY: A concurrent request may have stored a limiter for the key first
+				if lim, ok = store.Get(key); !ok {
+					lim = overflow
+				}
+			}
 		}
 
-		res := lim.Reserve()
-		delay := res.Delay()
+		now := time.Now()
+		res := lim.ReserveN(now, 1)
+		delay := res.DelayFrom(now)
 
 		if delay > 0 {
-			res.Cancel()
+			res.CancelAt(now)
 			ra := int(math.Ceil(delay.Seconds()))
-			resetAt := time.Now().Add(time.Duration(ra) * time.Second).Unix()
 
 			c.Header("Retry-After", strconv.Itoa(ra))
-			c.Header("X-RateLimit-Limit", strconv.Itoa(requests))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt, 10))
+			setRateLimitHeaders(c, now, lim.Burst(), 0, secondsUntilFull(lim, now), options)
 
 			_ = c.Error(apiErrors.TooManyRequests(ra))
 			c.Abort()
 			return
 		}
 
-		remaining := lim.Tokens()
-		resetAt := time.Now().Add(window).Unix()
-
-		c.Header("X-RateLimit-Limit", strconv.Itoa(requests))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(int(remaining)))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt, 10))
+		remaining := remainingTokens(lim, now)
+		setRateLimitHeaders(c, now, lim.Burst(), remaining, secondsUntilFull(lim, now), options)
 
 		c.Next()
 	}
 }
+
+// remainingTokens returns the whole tokens left in lim at now. It must use the
+// same instant as the reservation so refill between the two is not counted.
+func remainingTokens(lim *rate.Limiter, now time.Time) int {
+	return max(int(math.Floor(lim.TokensAt(now))), 0)
+}
+
+// secondsUntilFull returns how long, in whole seconds, until lim refills to its burst
+func secondsUntilFull(lim *rate.Limiter, now time.Time) int {
+	missing := float64(lim.Burst()) - lim.TokensAt(now)
+	if missing <= 0 || lim.Limit() <= 0 {
+		return 0
+	}
+	return int(math.Ceil(missing / float64(lim.Limit())))
+}
+
+func setRateLimitHeaders(c *gin.Context, now time.Time, limit, remaining, reset int, options rateLimitOptions) {
+	c.Header("RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+	if options.warningThreshold > 0 && float64(remaining) < options.warningThreshold*float64(limit) {
+		c.Header("X-RateLimit-Warning", "true")
+	}
+
+	if options.omitLegacyHeaders {
+		return
+	}
+	resetAt := now.Add(time.Duration(reset) * time.Second).Unix()
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt, 10))
+}
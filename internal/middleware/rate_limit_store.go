@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+const (
+	// DefaultCacheSize is the LRU capacity used when none is configured
+	DefaultCacheSize = 5000
+	// DefaultTTL is how long an idle limiter entry is kept when none is configured
+	DefaultTTL = 6 * time.Hour
+
+	// evictionLogInterval samples eviction logs: the first eviction and every Nth after it are logged
+	evictionLogInterval = 1000
+)
+
+// OverflowPolicy decides what a full LRUStore does with a new key
+type OverflowPolicy string
+
+const (
+	// OverflowEvict drops the least recently used limiter to make room for the new key
+	OverflowEvict OverflowPolicy = "evict"
+	// OverflowShared refuses the new key so the rate limiter puts it in its shared
+	// overflow bucket, keeping the limits of clients already tracked intact
+	OverflowShared OverflowPolicy = "shared"
+)
+
+// LRUStore is an in-memory limiter store bounded by size, dropping the least
+// recently used entries first and any entry idle for longer than its TTL.
+type LRUStore struct {
+	mu        sync.Mutex
+	cache     *expirable.LRU[string, *rate.Limiter]
+	size      int
+	overflow  OverflowPolicy
+	name      string
+	metrics   *RateLimitMetrics
+	evictions atomic.Uint64
+	overflows atomic.Uint64
+}
+
+// LRUStoreOption configures optional LRUStore behavior
+type LRUStoreOption func(*lruStoreOptions)
+
+type lruStoreOptions struct {
+	size     int
+	ttl      time.Duration
+	overflow OverflowPolicy
+	name     string
+	metrics  *RateLimitMetrics
+}
+
+// WithCacheSize caps the number of limiters kept; zero keeps DefaultCacheSize
+func WithCacheSize(size int) LRUStoreOption {
+	return func(o *lruStoreOptions) {
+		if size > 0 {
+			o.size = size
+		}
+	}
+}
+
+// WithEntryTTL sets how long an idle limiter is kept; zero keeps DefaultTTL
+func WithEntryTTL(ttl time.Duration) LRUStoreOption {
+	return func(o *lruStoreOptions) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to new keys once the store is full; an
+// empty policy keeps OverflowEvict
+func WithOverflowPolicy(policy OverflowPolicy) LRUStoreOption {
+	return func(o *lruStoreOptions) {
+		if policy != "" {
+			o.overflow = policy
+		}
+	}
+}
+
+// WithStoreMetrics reports the store size and evictions under the given store label
+func WithStoreMetrics(metrics *RateLimitMetrics, name string) LRUStoreOption {
+	return func(o *lruStoreOptions) {
+		o.metrics = metrics
+		o.name = name
+	}
+}
+
+// NewLRUStore creates an in-memory limiter store (LRU with TTL) so separate
+// limiters do not share buckets.
+func NewLRUStore(opts ...LRUStoreOption) *LRUStore {
+	options := lruStoreOptions{size: DefaultCacheSize, ttl: DefaultTTL, overflow: OverflowEvict, name: "default"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s := &LRUStore{size: options.size, overflow: options.overflow, name: options.name, metrics: options.metrics}
+	s.cache = expirable.NewLRU(options.size, s.onEvict, options.ttl)
+	return s
+}
+
+// Add stores the limiter unless the key already has one, reporting whether it was stored.
+// When the store is full the least recently used entry is evicted, or with
+// OverflowShared the new key is refused.
+func (s *LRUStore) Add(key string, limiter *rate.Limiter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache.Contains(key) {
+		return false
+	}
+	if s.overflow == OverflowShared && s.cache.Len() >= s.size {
+		s.onOverflow(key)
+		return false
+	}
+	s.cache.Add(key, limiter)
+	s.metrics.addEntry(s.name)
+	return true
+}
+
+// Get returns the limiter stored for key
+func (s *LRUStore) Get(key string) (*rate.Limiter, bool) {
+	return s.cache.Get(key)
+}
+
+// Len returns the number of stored limiters
+func (s *LRUStore) Len() int {
+	return s.cache.Len()
+}
+
+// onEvict runs for entries dropped because the store is full or they expired
+func (s *LRUStore) onEvict(key string, _ *rate.Limiter) {
+	s.metrics.evictEntry(s.name)
+
+	// WHY: A flood of spoofed keys evicts constantly, so only a sample is logged
+	if n := s.evictions.Add(1); n%evictionLogInterval == 1 {
+		slog.Warn("Rate limiter store evicted entries",
+			"store", s.name,
+			"key", key,
+			"evictions", n,
+		)
+	}
+}
+
+// onOverflow runs for new keys refused because the store is full
+func (s *LRUStore) onOverflow(key string) {
+	s.metrics.overflowEntry(s.name)
+
+	if n := s.overflows.Add(1); n%evictionLogInterval == 1 {
+		slog.Warn("Rate limiter store is full, new keys share the overflow bucket",
+			"store", s.name,
+			"key", key,
+			"overflows", n,
+		)
+	}
+}
+
+// RateLimitMetrics reports the size of rate limiter stores. A nil
+// *RateLimitMetrics records nothing.
+type RateLimitMetrics struct {
+	entries   *prometheus.GaugeVec
+	evictions *prometheus.CounterVec
+	overflows *prometheus.CounterVec
+}
+
+// NewRateLimitMetrics creates and registers the rate limiter store collectors.
+// Only the Namespace and Registerer fields of cfg are used.
+func NewRateLimitMetrics(cfg MetricsConfig) *RateLimitMetrics {
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &RateLimitMetrics{
+		entries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Name:      "ratelimit_store_entries",
+			Help:      "Number of per-key limiters held by each rate limiter store.",
+		}, []string{"store"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "ratelimit_store_evictions_total",
+			Help:      "Total number of limiters evicted from each rate limiter store because it was full or they expired.",
+		}, []string{"store"}),
+		overflows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "ratelimit_store_overflows_total",
+			Help:      "Total number of new keys each full rate limiter store refused, which were limited by the shared overflow bucket instead.",
+		}, []string{"store"}),
+	}
+
+	m.entries = metrics.Register(registerer, m.entries)
+	m.evictions = metrics.Register(registerer, m.evictions)
+	m.overflows = metrics.Register(registerer, m.overflows)
+
+	return m
+}
+
+func (m *RateLimitMetrics) addEntry(store string) {
+	if m == nil {
+		return
+	}
+	m.entries.WithLabelValues(store).Inc()
+}
+
+func (m *RateLimitMetrics) evictEntry(store string) {
+	if m == nil {
+		return
+	}
+	m.entries.WithLabelValues(store).Dec()
+	m.evictions.WithLabelValues(store).Inc()
+}
+
+func (m *RateLimitMetrics) overflowEntry(store string) {
+	if m == nil {
+		return
+	}
+	m.overflows.WithLabelValues(store).Inc()
+}
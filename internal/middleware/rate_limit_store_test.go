@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// refusingStorage never keeps a limiter, like a store that is always full
+type refusingStorage struct{}
+
+func (refusingStorage) Add(string, *rate.Limiter) bool   { return false }
+func (refusingStorage) Get(string) (*rate.Limiter, bool) { return nil, false }
+
+func newHeaderKeyedRouter(store Storage) *gin.Engine {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(NewRateLimitMiddleware(time.Minute, 1, func(c *gin.Context) string {
+		return c.GetHeader("X-Key")
+	}, store))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func sendWithKey(router *gin.Engine, key string) int {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("X-Key", key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLRUStore_BoundedUnderKeyFlood(t *testing.T) {
+	const cacheSize = 100
+	const keys = 10 * cacheSize
+
+	metrics := NewRateLimitMetrics(MetricsConfig{Registerer: prometheus.NewRegistry()})
+	store := NewLRUStore(WithCacheSize(cacheSize), WithStoreMetrics(metrics, "ip"))
+	router := newHeaderKeyedRouter(store)
+
+	for i := range keys {
+		require.Equal(t, http.StatusOK, sendWithKey(router, strconv.Itoa(i)))
+	}
+
+	assert.Equal(t, cacheSize, store.Len(), "the store never grows past its size")
+	assert.Equal(t, float64(cacheSize), testutil.ToFloat64(metrics.entries.WithLabelValues("ip")))
+	assert.Equal(t, float64(keys-cacheSize), testutil.ToFloat64(metrics.evictions.WithLabelValues("ip")))
+
+	// The most recent keys are still limited
+	assert.Equal(t, http.StatusTooManyRequests, sendWithKey(router, strconv.Itoa(keys-1)))
+
+	// An evicted key gets a new limiter, which limits it again
+	_, ok := store.Get("0")
+	require.False(t, ok, "the oldest key was evicted")
+	assert.Equal(t, http.StatusOK, sendWithKey(router, "0"))
+	assert.Equal(t, http.StatusTooManyRequests, sendWithKey(router, "0"))
+	assert.Equal(t, cacheSize, store.Len())
+}
+
+func TestLRUStore_SharedOverflowUnderKeyFlood(t *testing.T) {
+	const cacheSize = 100
+	const keys = 10 * cacheSize
+
+	metrics := NewRateLimitMetrics(MetricsConfig{Registerer: prometheus.NewRegistry()})
+	store := NewLRUStore(WithCacheSize(cacheSize), WithOverflowPolicy(OverflowShared), WithStoreMetrics(metrics, "ip"))
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(NewRateLimitMiddleware(time.Minute, 5, func(c *gin.Context) string {
+		return c.GetHeader("X-Key")
+	}, store, WithOverflowRequests(2)))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := range cacheSize {
+		require.Equal(t, http.StatusOK, sendWithKey(router, strconv.Itoa(i)))
+	}
+
+	allowed := 0
+	for i := cacheSize; i < keys; i++ {
+		if sendWithKey(router, strconv.Itoa(i)) == http.StatusOK {
+			allowed++
+		}
+	}
+
+	assert.Equal(t, 2, allowed, "keys past the store size share the smaller overflow budget")
+	assert.Equal(t, cacheSize, store.Len())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.evictions.WithLabelValues("ip")), "tracked clients are not evicted")
+	assert.Equal(t, float64(keys-cacheSize), testutil.ToFloat64(metrics.overflows.WithLabelValues("ip")))
+
+	// Clients tracked before the flood keep their own budget
+	assert.Equal(t, http.StatusOK, sendWithKey(router, "0"))
+}
+
+func TestLRUStore_EntryTTL(t *testing.T) {
+	metrics := NewRateLimitMetrics(MetricsConfig{Registerer: prometheus.NewRegistry()})
+	store := NewLRUStore(WithEntryTTL(50*time.Millisecond), WithStoreMetrics(metrics, "user"))
+
+	require.True(t, store.Add("user:1", rate.NewLimiter(1, 1)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.entries.WithLabelValues("user")))
+
+	assert.Eventually(t, func() bool {
+		return store.Len() == 0
+	}, time.Second, 10*time.Millisecond, "idle entries expire")
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.entries.WithLabelValues("user")) == 0
+	}, time.Second, 10*time.Millisecond, "expired entries are removed from the gauge")
+}
+
+func TestLRUStore_AddKeepsExistingLimiter(t *testing.T) {
+	store := NewLRUStore()
+	first := rate.NewLimiter(1, 1)
+
+	assert.True(t, store.Add("key", first))
+	assert.False(t, store.Add("key", rate.NewLimiter(1, 1)))
+
+	stored, ok := store.Get("key")
+	require.True(t, ok)
+	assert.Same(t, first, stored)
+}
+
+func TestRateLimitMiddleware_ConcurrentNewKey(t *testing.T) {
+	router := newHeaderKeyedRouter(NewLRUStore())
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sendWithKey(router, "shared") == http.StatusOK {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, allowed, "requests racing on a new key share one limiter")
+}
+
+func TestRateLimitMiddleware_StoreRefusesEntries(t *testing.T) {
+	router := newHeaderKeyedRouter(refusingStorage{})
+
+	assert.Equal(t, http.StatusOK, sendWithKey(router, "a"))
+	assert.Equal(t, http.StatusTooManyRequests, sendWithKey(router, "b"), "keys the store cannot hold are still limited")
+	assert.Equal(t, http.StatusTooManyRequests, sendWithKey(router, "a"))
+}
+
+func TestRateLimitMetrics_NilIsNoop(t *testing.T) {
+	var metrics *RateLimitMetrics
+	assert.NotPanics(t, func() {
+		metrics.addEntry("ip")
+		metrics.evictEntry("ip")
+		metrics.overflowEntry("ip")
+	})
+}
@@ -10,8 +10,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
 	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
 )
 
@@ -161,10 +163,14 @@ func TestNewRateLimitMiddleware(t *testing.T) {
 					var response map[string]interface{}
 					assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
 
+					// The 429 body uses the standard error envelope rendered by ErrorHandler
 					assert.False(t, response["success"].(bool))
 					errorObj := response["error"].(map[string]interface{})
+					assert.Equal(t, apiErrors.CodeTooManyRequests, errorObj["code"])
 					assert.Equal(t, "Rate limit exceeded", errorObj["message"])
-					assert.Contains(t, errorObj, "retry_after")
+					assert.NotEmpty(t, errorObj["details"])
+					assert.Equal(t, w.Header().Get("Retry-After"), strconv.Itoa(int(errorObj["retry_after"].(float64))),
+						"retry_after in the body matches the Retry-After header")
 				}
 			}
 
@@ -258,3 +264,224 @@ func TestRateLimitMiddleware_Headers(t *testing.T) {
 		}
 	}
 }
+
+// TestRateLimitMiddleware_StandardHeaders tests exact header values on an allowed request and on the request that is rejected
+func TestRateLimitMiddleware_StandardHeaders(t *testing.T) {
+	// 2 requests per 2s refills one token per second
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(NewRateLimitMiddleware(2*time.Second, 2, func(c *gin.Context) string {
+		return "test"
+	}, NewMockStorage()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		return w
+	}
+
+	start := time.Now().Unix()
+	w := send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assertUnixResetIn(t, w.Header().Get("X-RateLimit-Reset"), start, 1)
+
+	w = send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+
+	w = send()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assertUnixResetIn(t, w.Header().Get("X-RateLimit-Reset"), start, 2)
+}
+
+// TestRateLimitMiddleware_WithoutLegacyHeaders tests that the X-RateLimit-* headers can be suppressed
+func TestRateLimitMiddleware_WithoutLegacyHeaders(t *testing.T) {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(NewRateLimitMiddleware(time.Second, 1, func(c *gin.Context) string {
+		return "test"
+	}, NewMockStorage(), WithoutLegacyHeaders()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		assert.Equal(t, expected, w.Code)
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+		assert.Empty(t, w.Header().Get("X-RateLimit-Remaining"))
+		assert.Empty(t, w.Header().Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestRateLimitMiddleware_Warning(t *testing.T) {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(NewRateLimitMiddleware(time.Minute, 10, func(c *gin.Context) string {
+		return "test"
+	}, NewMockStorage(), WithWarningThreshold(0.2)))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	previous := 10
+	for i := 1; i <= 10; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		remaining, err := strconv.Atoi(w.Header().Get("RateLimit-Remaining"))
+		require.NoError(t, err)
+		assert.Equal(t, 10-i, remaining, "request %d", i)
+		assert.Less(t, remaining, previous, "remaining decreases with every request")
+		assert.Equal(t, w.Header().Get("RateLimit-Remaining"), w.Header().Get("X-RateLimit-Remaining"))
+		previous = remaining
+
+		// Fewer than 2 of 10 requests left
+		if remaining < 2 {
+			assert.Equal(t, "true", w.Header().Get("X-RateLimit-Warning"), "remaining %d", remaining)
+		} else {
+			assert.Empty(t, w.Header().Get("X-RateLimit-Warning"), "remaining %d", remaining)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-RateLimit-Warning"))
+}
+
+func TestRateLimitMiddleware_WarningDisabled(t *testing.T) {
+	router := gin.New()
+	router.Use(NewRateLimitMiddleware(time.Minute, 1, func(c *gin.Context) string {
+		return "test"
+	}, NewMockStorage()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Empty(t, w.Header().Get("X-RateLimit-Warning"))
+}
+
+// assertUnixResetIn checks that a Unix timestamp header is delta seconds after start, allowing for a clock tick
+func assertUnixResetIn(t *testing.T, header string, start int64, delta int64) {
+	t.Helper()
+	resetAt, err := strconv.ParseInt(header, 10, 64)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, resetAt, start+delta)
+	assert.LessOrEqual(t, resetAt, time.Now().Unix()+delta)
+}
+
+// TestRateLimitMiddleware_PerUser tests that users sharing an IP get independent buckets
+func TestRateLimitMiddleware_PerUser(t *testing.T) {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			userID, _ := strconv.ParseUint(id, 10, 32)
+			c.Set(auth.KeyUser, &auth.Claims{UserID: uint(userID)})
+		}
+		c.Next()
+	})
+	router.Use(NewRateLimitMiddleware(time.Minute, 1, UserOrIPKey, NewLRUStore()))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	send := func(userID string) int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if userID != "" {
+			req.Header.Set("X-Test-User", userID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("1"), "first request from user 1 should succeed")
+	assert.Equal(t, http.StatusOK, send("2"), "user 2 behind the same IP has its own bucket")
+	assert.Equal(t, http.StatusTooManyRequests, send("1"), "user 1 is limited independently")
+	assert.Equal(t, http.StatusTooManyRequests, send("2"), "user 2 is limited independently")
+	assert.Equal(t, http.StatusOK, send(""), "anonymous requests fall back to the IP bucket")
+	assert.Equal(t, http.StatusTooManyRequests, send(""), "anonymous requests share the IP bucket")
+}
+
+func TestRateLimitMiddleware_Exemptions(t *testing.T) {
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.Use(func(c *gin.Context) {
+		if role := c.GetHeader("X-Test-Role"); role != "" {
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{role}})
+		}
+		c.Next()
+	})
+	router.Use(NewRateLimitMiddleware(time.Minute, 1, IPKey, NewLRUStore(),
+		WithExemption(ExemptRoles("admin")),
+		WithExemption(ExemptIPs("198.51.100.10", "10.0.0.0/8", "not-an-ip")),
+	))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(ip, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = ip + ":1234"
+		if role != "" {
+			req.Header.Set("X-Test-Role", role)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for range 3 {
+		w := send("203.0.113.7", "admin")
+		assert.Equal(t, http.StatusOK, w.Code, "admins are never limited")
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"), "exempt requests get no rate limit headers")
+	}
+	assert.Equal(t, http.StatusOK, send("203.0.113.7", "user").Code, "exempt requests do not spend the IP budget")
+	assert.Equal(t, http.StatusTooManyRequests, send("203.0.113.7", "user").Code)
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, send("198.51.100.10", "").Code, "an exempt IP is never limited")
+		assert.Equal(t, http.StatusOK, send("10.1.2.3", "").Code, "an IP in an exempt range is never limited")
+	}
+	assert.Equal(t, http.StatusOK, send("198.51.100.11", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("198.51.100.11", "").Code)
+}
+
+func TestUserOrIPKey(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+	c.Request.RemoteAddr = "203.0.113.7:1234"
+
+	assert.Equal(t, "ip:203.0.113.7", UserOrIPKey(c))
+
+	c.Set(auth.KeyUser, &auth.Claims{UserID: 42})
+	assert.Equal(t, "user:42", UserOrIPKey(c))
+}
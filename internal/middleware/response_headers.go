@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ServerHeader is the response header naming the server software
+	ServerHeader = "Server"
+	// ResponseTimeHeader reports how long the request took to handle
+	ResponseTimeHeader = "X-Response-Time"
+)
+
+// ResponseHeadersConfig controls headers added to or removed from every response
+type ResponseHeadersConfig struct {
+	// Server replaces the Server header; empty removes it
+	Server string
+	// ResponseTime adds X-Response-Time with the handling duration
+	ResponseTime bool
+}
+
+// ResponseHeaders returns a middleware that hides the Server header (or
+// replaces it with cfg.Server) and optionally adds X-Response-Time. The headers
+// are applied just before the response header is written, so they also cover
+// values set by handlers and the time spent in them.
+func ResponseHeaders(cfg ResponseHeadersConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		w := &headerHookWriter{ResponseWriter: c.Writer}
+		w.before = func() {
+			if cfg.Server == "" {
+				w.Header().Del(ServerHeader)
+			} else {
+				w.Header().Set(ServerHeader, cfg.Server)
+			}
+			if cfg.ResponseTime {
+				w.Header().Set(ResponseTimeHeader, time.Since(start).String())
+			}
+		}
+		c.Writer = w
+
+		c.Next()
+
+		// WHY: Responses without a body are written by gin after the chain returns,
+		// bypassing the wrapper
+		w.apply()
+	}
+}
+
+// headerHookWriter runs before once, right before the response header is sent
+type headerHookWriter struct {
+	gin.ResponseWriter
+	before  func()
+	applied bool
+}
+
+func (w *headerHookWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	w.before()
+}
+
+func (w *headerHookWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *headerHookWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *headerHookWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *headerHookWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *headerHookWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupResponseHeadersRouter(cfg ResponseHeadersConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ResponseHeaders(cfg))
+	router.GET("/json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/leaky", func(c *gin.Context) {
+		c.Header(ServerHeader, "gin/1.9.1")
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		time.Sleep(time.Millisecond)
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// serve returns the headers as they were sent, not as modified afterwards
+func serve(router *gin.Engine, path string) *http.Response {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Result()
+}
+
+func TestResponseHeaders_Server(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		want   []string
+	}{
+		{name: "removed by default", server: "", want: nil},
+		{name: "overridden", server: "api", want: []string{"api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupResponseHeadersRouter(ResponseHeadersConfig{Server: tt.server})
+
+			for _, path := range []string{"/json", "/leaky", "/empty"} {
+				resp := serve(router, path)
+				assert.Equal(t, tt.want, resp.Header.Values(ServerHeader), path)
+			}
+		})
+	}
+}
+
+func TestResponseHeaders_ResponseTime(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		router := setupResponseHeadersRouter(ResponseHeadersConfig{ResponseTime: true})
+
+		for _, path := range []string{"/json", "/empty"} {
+			resp := serve(router, path)
+			value := resp.Header.Get(ResponseTimeHeader)
+			require.NotEmpty(t, value, path)
+
+			duration, err := time.ParseDuration(value)
+			require.NoError(t, err, "%s: %q is not a duration", path, value)
+			assert.Positive(t, duration, path)
+		}
+
+		resp := serve(router, "/empty")
+		duration, _ := time.ParseDuration(resp.Header.Get(ResponseTimeHeader))
+		assert.GreaterOrEqual(t, duration, time.Millisecond, "includes time spent in the handler")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := setupResponseHeadersRouter(ResponseHeadersConfig{})
+
+		resp := serve(router, "/json")
+		assert.Empty(t, resp.Header.Get(ResponseTimeHeader))
+	})
+}
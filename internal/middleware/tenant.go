@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+// TenantMiddleware puts the tenant of the request into its context. The
+// X-Tenant-ID header wins; otherwise a host one label below baseDomain names
+// the tenant, e.g. acme.example.com. Requests naming neither use
+// tenant.Default, and an invalid tenant ID is rejected with 400. The header is
+// not trusted on its own: AuthMiddleware rejects access tokens whose tenant_id
+// claim names another tenant.
+func TenantMiddleware(baseDomain string) gin.HandlerFunc {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+
+	return func(c *gin.Context) {
+		id := strings.ToLower(c.GetHeader(tenant.Header))
+		if id == "" && baseDomain != "" {
+			id = subdomain(c.Request.Host, suffix)
+		}
+
+		if id != tenant.Default && !tenant.ValidID(id) {
+			_ = c.Error(errors.BadRequest("Invalid tenant ID"))
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// subdomain returns the label of host directly below suffix, or "" when host is
+// not such a subdomain
+func subdomain(host, suffix string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	label, ok := strings.CutSuffix(host, suffix)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		baseDomain string
+		host       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "header", header: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "header is lowercased", header: "ACME", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "subdomain", baseDomain: "example.com", host: "acme.example.com", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "subdomain with port", baseDomain: "example.com", host: "acme.example.com:8080", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "header wins over subdomain", baseDomain: "example.com", host: "acme.example.com", header: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "apex domain", baseDomain: "example.com", host: "example.com", wantStatus: http.StatusOK, wantTenant: tenant.Default},
+		{name: "nested subdomain", baseDomain: "example.com", host: "api.acme.example.com", wantStatus: http.StatusOK, wantTenant: tenant.Default},
+		{name: "other domain", baseDomain: "example.com", host: "acme.example.org", wantStatus: http.StatusOK, wantTenant: tenant.Default},
+		{name: "subdomains ignored without base domain", host: "acme.example.com", wantStatus: http.StatusOK, wantTenant: tenant.Default},
+		{name: "invalid header", header: "acme_corp", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			router := gin.New()
+			router.Use(errors.ErrorHandler(), TenantMiddleware(tt.baseDomain))
+			router.GET("/test", func(c *gin.Context) {
+				got = tenant.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set(tenant.Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantTenant, got)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+)
+
+// Transaction runs each request in one database transaction, which every
+// repository looking its connection up with db.Conn joins. The transaction is
+// committed when the handler succeeds (status below 400 and no c.Errors) and
+// rolled back when it fails or panics. GET, HEAD and OPTIONS requests run
+// without one. Functions queued with db.AfterCommit run after the commit and
+// are dropped on rollback; writes that must survive a failed request, such as
+// revoking a reused token family, use db.WithoutTx; writes whose failure is
+// only logged, such as the last login time, use db.BestEffort.
+//
+// The response is held back until the commit, so a failed commit turns into a
+// 500 instead of a success the database does not reflect. Streaming handlers
+// must not run behind it.
+func Transaction(database *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		tx := database.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			_ = c.Error(fmt.Errorf("failed to begin request transaction: %w", tx.Error))
+			c.Abort()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, header: c.Writer.Header().Clone()}
+		c.Writer = writer
+		txCtx, runCommitHooks := db.WithCommitHooks(db.WithTx(ctx, tx))
+		c.Request = c.Request.WithContext(txCtx)
+
+		committed := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if !committed {
+				// WHY: Runs on panics too, before Recovery writes its response
+				tx.Rollback()
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) > 0 || writer.Status() >= http.StatusBadRequest {
+			writer.flush()
+			return
+		}
+		if err := tx.Commit().Error; err != nil {
+			slog.ErrorContext(ctx, "Failed to commit request transaction", "error", err)
+			_ = c.Error(fmt.Errorf("failed to commit request transaction: %w", err))
+			return
+		}
+		committed = true
+		writer.flush()
+		runCommitHooks()
+	}
+}
+
+// bufferedWriter holds the headers, status and body of a response until flush
+type bufferedWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+// Header keeps headers apart, so a response discarded after a failed commit
+// leaves no cookies behind
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Flush is a no-op; the response is only sent once the transaction is settled
+func (w *bufferedWriter) Flush() {}
+
+// flush sends what the handler wrote to the underlying writer
+func (w *bufferedWriter) flush() {
+	header := w.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, w.header)
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+type txNote struct {
+	ID   uint
+	Text string
+}
+
+func setupTransactionTest(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	// WHY: Every connection to :memory: is a new database, so the test must share the transaction's connection
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, database.AutoMigrate(&txNote{}))
+
+	write := func(c *gin.Context) {
+		require.NoError(t, db.Conn(c.Request.Context(), database).Create(&txNote{Text: c.Query("text")}).Error)
+	}
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	router.Use(errors.ErrorHandler())
+	group := router.Group("", Transaction(database))
+	group.POST("/ok", func(c *gin.Context) {
+		write(c)
+		c.Header("Set-Cookie", "session=1")
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	group.POST("/error", func(c *gin.Context) {
+		write(c)
+		_ = c.Error(errors.BadRequest("forced"))
+	})
+	group.POST("/status", func(c *gin.Context) {
+		write(c)
+		c.JSON(http.StatusConflict, gin.H{"ok": false})
+	})
+	group.POST("/panic", func(c *gin.Context) {
+		write(c)
+		panic("boom")
+	})
+	group.GET("/read", func(c *gin.Context) {
+		inTx := db.Conn(c.Request.Context(), database) != database
+		var count int64
+		require.NoError(t, db.Conn(c.Request.Context(), database).Model(&txNote{}).Count(&count).Error)
+		c.JSON(http.StatusOK, gin.H{"count": count, "tx": inTx})
+	})
+	return router, database
+}
+
+func TestTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantRows   int64
+	}{
+		{name: "commits on success", path: "/ok", wantStatus: http.StatusCreated, wantRows: 1},
+		{name: "rolls back on c.Error", path: "/error", wantStatus: http.StatusBadRequest},
+		{name: "rolls back on error status", path: "/status", wantStatus: http.StatusConflict},
+		{name: "rolls back on panic", path: "/panic", wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, database := setupTransactionTest(t)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path+"?text=hello", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var count int64
+			require.NoError(t, database.Model(&txNote{}).Count(&count).Error)
+			assert.Equal(t, tt.wantRows, count)
+		})
+	}
+}
+
+func TestTransaction_BuffersResponseUntilCommit(t *testing.T) {
+	router, _ := setupTransactionTest(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ok?text=hello", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	assert.Equal(t, "session=1", w.Header().Get("Set-Cookie"))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestTransaction_SkipsReadOnlyMethods(t *testing.T) {
+	router, database := setupTransactionTest(t)
+	require.NoError(t, database.Create(&txNote{Text: "existing"}).Error)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/read", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count":1,"tx":false}`, w.Body.String())
+}
+
+func TestTransaction_RunsCommitHooksOnlyAfterCommit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+
+	var ran []string
+	queue := func(status int) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			db.AfterCommit(c.Request.Context(), func() { ran = append(ran, c.FullPath()) })
+			assert.Empty(t, ran, "hooks wait for the transaction")
+			c.Status(status)
+		}
+	}
+	router := gin.New()
+	router.POST("/ok", Transaction(database), queue(http.StatusOK))
+	router.POST("/fail", Transaction(database), queue(http.StatusConflict))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fail", nil))
+	assert.Empty(t, ran, "hooks are dropped on rollback")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/ok", nil))
+	assert.Equal(t, []string{"/ok"}, ran)
+}
@@ -9,15 +9,34 @@ import (
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/migrations"
+)
+
+// Supported database dialects
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
 )
 
 type Config struct {
 	DatabaseURL   string
 	MigrationsDir string
-	Timeout       time.Duration
-	LockTimeout   time.Duration
+	// UseEmbedded reads the migrations compiled into the binary instead of MigrationsDir
+	UseEmbedded bool
+	// Dialect selects the database driver; empty means DialectPostgres
+	Dialect string
+	// TablePrefix is prepended to the tables and indexes the migrations
+	// create, and to the migrations table; see database.table_prefix
+	TablePrefix string
+	Timeout     time.Duration
+	LockTimeout time.Duration
 }
 
 type migrateInterface interface {
@@ -32,36 +51,81 @@ type migrateInterface interface {
 
 type Migrator struct {
 	migrate migrateInterface
+	source  source.Driver
 	db      *sql.DB
 	config  Config
 }
 
 func New(db *sql.DB, cfg Config) (*Migrator, error) {
-	driver, err := postgres.WithInstance(db, &postgres.Config{
-		MigrationsTable:       "schema_migrations",
-		MultiStatementEnabled: true,
-		StatementTimeout:      cfg.Timeout,
-	})
+	dialect := cfg.Dialect
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+
+	driver, err := newDatabaseDriver(db, dialect, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create postgres driver: %w", err)
+		return nil, err
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", cfg.MigrationsDir),
-		"postgres",
-		driver,
-	)
+	src, err := newSourceDriver(cfg)
 	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations source: %w", err)
+	}
+	if cfg.TablePrefix != "" {
+		src = prefixSource{Driver: src, prefix: cfg.TablePrefix}
+	}
+	if dialect == DialectSQLite {
+		src = sqliteSource{src}
+	}
+
+	m, err := migrate.NewWithInstance("migrations", src, dialect, driver)
+	if err != nil {
+		_ = src.Close()
 		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
 
 	return &Migrator{
 		migrate: m,
+		source:  src,
 		db:      db,
 		config:  cfg,
 	}, nil
 }
 
+func newDatabaseDriver(db *sql.DB, dialect string, cfg Config) (database.Driver, error) {
+	switch dialect {
+	case DialectPostgres:
+		driver, err := postgres.WithInstance(db, &postgres.Config{
+			MigrationsTable:       cfg.TablePrefix + "schema_migrations",
+			MultiStatementEnabled: true,
+			StatementTimeout:      cfg.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create postgres driver: %w", err)
+		}
+		return driver, nil
+	case DialectSQLite:
+		// WHY: Migration files manage their own BEGIN/COMMIT and SQLite rejects nested transactions
+		driver, err := sqlite3.WithInstance(db, &sqlite3.Config{
+			MigrationsTable: cfg.TablePrefix + "schema_migrations",
+			NoTxWrap:        true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sqlite driver: %w", err)
+		}
+		return driver, nil
+	default:
+		return nil, fmt.Errorf("failed to create driver: unsupported dialect %q", dialect)
+	}
+}
+
+func newSourceDriver(cfg Config) (source.Driver, error) {
+	if cfg.UseEmbedded {
+		return iofs.New(migrations.FS, ".")
+	}
+	return (&file.File{}).Open(fmt.Sprintf("file://%s", cfg.MigrationsDir))
+}
+
 func (m *Migrator) Up(ctx context.Context) error {
 	slog.Info("Running migrations...")
 
@@ -168,6 +232,12 @@ func (m *Migrator) Version() (uint, bool, error) {
 	return version, dirty, nil
 }
 
+// LatestVersion returns the highest version among the available migration
+// files, or 0 when there are none
+func (m *Migrator) LatestVersion() (uint, error) {
+	return highestVersion(m.source)
+}
+
 func (m *Migrator) Force(version int) error {
 	slog.Warn("Forcing migration version", "version", version)
 
@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +15,8 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/migrations"
 )
 
 type mockMigrate struct {
@@ -664,3 +670,260 @@ func TestMigrator_Close_DatabaseError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to close database")
 }
+
+func TestNew_UnsupportedDialect(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = New(db, Config{UseEmbedded: true, Dialect: "mysql"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported dialect")
+}
+
+// latestVersion returns the highest version among the embedded migrations
+func latestVersion(t *testing.T) uint {
+	t.Helper()
+
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	var latest uint64
+	for _, name := range files {
+		v, err := strconv.ParseUint(strings.SplitN(name, "_", 2)[0], 10, 64)
+		require.NoError(t, err)
+		latest = max(latest, v)
+	}
+	return uint(latest)
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestMigrator_SQLite_UpAndDown(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"embedded", Config{UseEmbedded: true, Dialect: DialectSQLite, Timeout: 30 * time.Second}},
+		{"directory", Config{MigrationsDir: "../../migrations", Dialect: DialectSQLite, Timeout: 30 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlite3", ":memory:")
+			require.NoError(t, err)
+			// WHY: Every connection to :memory: is a separate database
+			db.SetMaxOpenConns(1)
+
+			migrator, err := New(db, tt.cfg)
+			require.NoError(t, err)
+			defer func() { _ = migrator.Close() }()
+
+			latest, err := migrator.LatestVersion()
+			require.NoError(t, err)
+			assert.Equal(t, latestVersion(t), latest)
+
+			ctx := context.Background()
+			require.NoError(t, migrator.Up(ctx))
+
+			version, dirty, err := migrator.Version()
+			require.NoError(t, err)
+			assert.False(t, dirty)
+			assert.Equal(t, latestVersion(t), version)
+
+			for _, table := range []string{"users", "refresh_tokens", "roles", "user_roles", "audit_logs", "oauth_identities"} {
+				assert.True(t, tableExists(t, db, table), table)
+			}
+
+			var roles int
+			require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM roles").Scan(&roles))
+			assert.Equal(t, 2, roles)
+
+			files, err := fs.Glob(migrations.FS, "*.down.sql")
+			require.NoError(t, err)
+			require.NoError(t, migrator.Down(ctx, len(files)))
+
+			version, _, err = migrator.Version()
+			require.NoError(t, err)
+			assert.Zero(t, version)
+			assert.False(t, tableExists(t, db, "users"))
+		})
+	}
+}
+
+func TestToSQLite(t *testing.T) {
+	query := `BEGIN;
+CREATE TABLE t (
+    id SERIAL PRIMARY KEY,
+    token UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+ALTER TABLE t DROP CONSTRAINT IF EXISTS t_email_key;
+ALTER TABLE t ADD CONSTRAINT t_email_key UNIQUE (email);
+ALTER TABLE t ADD COLUMN IF NOT EXISTS note VARCHAR(255);
+ALTER TABLE t DROP COLUMN IF EXISTS note;
+DROP TABLE IF EXISTS t CASCADE;
+COMMENT ON TABLE t IS 'It''s a table; with a semicolon';
+COMMENT ON COLUMN t.id IS 'Primary key';
+COMMIT;`
+
+	expected := `BEGIN;
+CREATE TABLE t (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token TEXT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS t_email_key ON t(email);
+CREATE UNIQUE INDEX IF NOT EXISTS t_name_key ON t(name);
+DROP INDEX IF EXISTS t_email_key;
+CREATE UNIQUE INDEX t_email_key ON t(email);
+ALTER TABLE t ADD COLUMN note VARCHAR(255);
+ALTER TABLE t DROP COLUMN note;
+DROP TABLE IF EXISTS t;
+COMMIT;`
+
+	assert.Equal(t, expected, string(toSQLite([]byte(query))))
+}
+
+func TestMigrator_SQLite_TablePrefix(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	migrator, err := New(db, Config{UseEmbedded: true, Dialect: DialectSQLite, TablePrefix: "tenant_", Timeout: 30 * time.Second})
+	require.NoError(t, err)
+	defer func() { _ = migrator.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, migrator.Up(ctx))
+
+	for _, table := range prefixedTables {
+		assert.True(t, tableExists(t, db, "tenant_"+table), table)
+		assert.False(t, tableExists(t, db, table), table)
+	}
+	assert.True(t, tableExists(t, db, "tenant_schema_migrations"))
+	assert.False(t, tableExists(t, db, "schema_migrations"))
+
+	var roles int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM tenant_roles").Scan(&roles))
+	assert.Equal(t, 2, roles)
+
+	var index string
+	require.NoError(t, db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'tenant_users' AND name = 'idx_tenant_users_email'").Scan(&index))
+	assert.Equal(t, "idx_tenant_users_email", index)
+
+	files, err := fs.Glob(migrations.FS, "*.down.sql")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Down(ctx, len(files)))
+	assert.False(t, tableExists(t, db, "tenant_users"))
+}
+
+func TestPrefixedTables_CoverMigrations(t *testing.T) {
+	createTable := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	for _, file := range files {
+		query, err := fs.ReadFile(migrations.FS, file)
+		require.NoError(t, err)
+		for _, match := range createTable.FindAllSubmatch(query, -1) {
+			assert.Contains(t, prefixedTables, string(match[1]), "%s creates a table missing from prefixedTables", file)
+		}
+	}
+}
+
+func TestWithTablePrefix(t *testing.T) {
+	query := `CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    role_id INTEGER NOT NULL REFERENCES roles(id)
+);
+CREATE INDEX idx_user_roles_user_id ON user_roles(user_id);
+COMMENT ON COLUMN users.pending_email IS 'New address';
+INSERT INTO roles (id, name) VALUES (1, 'user');
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;`
+
+	expected := `CREATE TABLE IF NOT EXISTS app_user_roles (
+    user_id INTEGER NOT NULL REFERENCES app_users(id),
+    role_id INTEGER NOT NULL REFERENCES app_roles(id)
+);
+CREATE INDEX idx_app_user_roles_user_id ON app_user_roles(user_id);
+COMMENT ON COLUMN app_users.pending_email IS 'New address';
+INSERT INTO app_roles (id, name) VALUES (1, 'user');
+ALTER TABLE app_users DROP CONSTRAINT IF EXISTS app_users_email_key;`
+
+	assert.Equal(t, expected, string(withTablePrefix([]byte(query), "app_")))
+}
+
+func TestStatus_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// WHY: Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	defer func() { _ = db.Close() }()
+
+	cfg := Config{UseEmbedded: true, Dialect: DialectSQLite, Timeout: 30 * time.Second}
+	migrator, err := New(db, cfg)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+
+	status, err := NewStatus(db, cfg)
+	require.NoError(t, err)
+	defer func() { _ = status.Close() }()
+
+	latest, err := status.LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, latestVersion(t), latest)
+
+	version, dirty, err := status.Version(context.Background())
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, latest, version)
+
+	// The only connection is back in the pool after every check
+	for range 3 {
+		_, _, err = status.Version(context.Background())
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.PingContext(context.Background()))
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := status.Version(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMigrator_SQLite_EmailUniqueIgnoresCase(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	defer func() { _ = db.Close() }()
+
+	migrator, err := New(db, Config{UseEmbedded: true, Dialect: DialectSQLite, Timeout: 30 * time.Second})
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+
+	insert := "INSERT INTO users (name, email, password_hash, tenant_id) VALUES ('User', ?, 'hash', ?)"
+	_, err = db.Exec(insert, "john@example.com", "")
+	require.NoError(t, err)
+
+	_, err = db.Exec(insert, "John@Example.com", "")
+	assert.Error(t, err, "emails differing only in case collide within a tenant")
+
+	_, err = db.Exec(insert, "John@Example.com", "other")
+	assert.NoError(t, err, "other tenants may use the email")
+}
@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// prefixedTables are the tables created in migrations/. A migration adding a
+// table needs it listed here to follow database.table_prefix.
+var prefixedTables = []string{
+	"users",
+	"refresh_tokens",
+	"roles",
+	"user_roles",
+	"audit_logs",
+	"oauth_identities",
+	"notification_settings",
+	"outbox_messages",
+}
+
+var (
+	tableReference = regexp.MustCompile(`\b(?:` + strings.Join(prefixedTables, "|") + `)\b`)
+	// WHY: Index names share a namespace with tables in PostgreSQL, so two
+	// prefixes in one schema would otherwise collide on idx_users_email
+	indexName = regexp.MustCompile(`\bidx_`)
+	// constraintName matches the names PostgreSQL gives inline constraints,
+	// e.g. users_email_key, which follow the name of their table
+	constraintName = regexp.MustCompile(`\b(?:` + strings.Join(prefixedTables, "|") + `)_\w+_key\b`)
+)
+
+// withTablePrefix prefixes the table and index names in a migration
+func withTablePrefix(query []byte, prefix string) []byte {
+	query = tableReference.ReplaceAllFunc(query, func(table []byte) []byte {
+		return append([]byte(prefix), table...)
+	})
+	query = constraintName.ReplaceAllFunc(query, func(name []byte) []byte {
+		return append([]byte(prefix), name...)
+	})
+	return indexName.ReplaceAll(query, []byte("idx_"+prefix))
+}
+
+// prefixSource serves the migrations with table names prefixed, so the
+// schema matches the names GORM uses under the same prefix
+type prefixSource struct {
+	source.Driver
+	prefix string
+}
+
+func (s prefixSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	return rewriteQuery(r, identifier, err, s.apply)
+}
+
+func (s prefixSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	return rewriteQuery(r, identifier, err, s.apply)
+}
+
+func (s prefixSource) apply(query []byte) []byte {
+	return withTablePrefix(query, s.prefix)
+}
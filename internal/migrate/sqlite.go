@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// sqliteRewrites translate the PostgreSQL dialect used in migrations/ into
+// SQLite. They cover the constructs the migrations actually use; a new
+// migration relying on other PostgreSQL-only syntax needs a rule here.
+var sqliteRewrites = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	// COMMENT ON has no SQLite equivalent
+	{regexp.MustCompile(`(?is)COMMENT\s+ON\s+[^;]*?\s+IS\s+'(?:[^']|'')*'\s*;\n?`), ""},
+	{regexp.MustCompile(`(?i)\bSERIAL\s+PRIMARY\s+KEY\b`), "INTEGER PRIMARY KEY AUTOINCREMENT"},
+	// WHY: go-sqlite3 only scans columns declared DATETIME/TIMESTAMP/DATE into time.Time
+	{regexp.MustCompile(`(?i)\bTIMESTAMP\s+WITH(?:OUT)?\s+TIME\s+ZONE\b`), "DATETIME"},
+	{regexp.MustCompile(`(?i)\bUUID\b`), "TEXT"},
+	// IDs are generated by the application; SQLite has no gen_random_uuid()
+	{regexp.MustCompile(`(?i)\s+DEFAULT\s+gen_random_uuid\(\)`), ""},
+	{regexp.MustCompile(`(?i)\bADD\s+COLUMN\s+IF\s+NOT\s+EXISTS\b`), "ADD COLUMN"},
+	{regexp.MustCompile(`(?i)\bDROP\s+COLUMN\s+IF\s+EXISTS\b`), "DROP COLUMN"},
+	{regexp.MustCompile(`(?i)\s+CASCADE\s*;`), ";"},
+	// Unique constraints are indexes in SQLite; see namedUniqueIndexes
+	{regexp.MustCompile(`(?i)ALTER\s+TABLE\s+\w+\s+DROP\s+CONSTRAINT\s+IF\s+EXISTS\s+(\w+)\s*;`), "DROP INDEX IF EXISTS $1;"},
+	{regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(\w+)\s+ADD\s+CONSTRAINT\s+(\w+)\s+UNIQUE\s*\(([^)]*)\)\s*;`), "CREATE UNIQUE INDEX $2 ON $1($3);"},
+}
+
+var (
+	createTable  = regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\((.*?)\n\);`)
+	inlineUnique = regexp.MustCompile(`(?im)^(\s*)(\w+)(\s[^,\n]*?)\s+UNIQUE\b`)
+)
+
+// namedUniqueIndexes turns inline UNIQUE column constraints into unique indexes
+// named as PostgreSQL names the constraints, e.g. users_email_key. SQLite
+// cannot drop inline constraints, but later migrations can drop these indexes.
+func namedUniqueIndexes(query []byte) []byte {
+	return createTable.ReplaceAllFunc(query, func(stmt []byte) []byte {
+		m := createTable.FindSubmatch(stmt)
+		table := string(m[1])
+		var indexes []byte
+		stmt = inlineUnique.ReplaceAllFunc(stmt, func(column []byte) []byte {
+			c := inlineUnique.FindSubmatch(column)
+			indexes = fmt.Appendf(indexes, "\nCREATE UNIQUE INDEX IF NOT EXISTS %s_%s_key ON %s(%s);", table, c[2], table, c[2])
+			return slices.Concat(c[1], c[2], c[3])
+		})
+		return append(stmt, indexes...)
+	})
+}
+
+// toSQLite rewrites a PostgreSQL migration so it runs on SQLite
+func toSQLite(query []byte) []byte {
+	query = namedUniqueIndexes(query)
+	for _, rw := range sqliteRewrites {
+		query = rw.pattern.ReplaceAll(query, []byte(rw.replace))
+	}
+	return query
+}
+
+// sqliteSource serves the PostgreSQL migrations translated to SQLite, so the
+// SQLite schema used by tests always follows migrations/
+type sqliteSource struct {
+	source.Driver
+}
+
+func (s sqliteSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	return rewriteQuery(r, identifier, err, toSQLite)
+}
+
+func (s sqliteSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	return rewriteQuery(r, identifier, err, toSQLite)
+}
+
+// rewriteQuery passes the migration read from a source driver through rewrite
+func rewriteQuery(r io.ReadCloser, identifier string, err error, rewrite func([]byte) []byte) (io.ReadCloser, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = r.Close() }()
+
+	query, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return io.NopCloser(bytes.NewReader(rewrite(query))), identifier, nil
+}
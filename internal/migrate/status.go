@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Status reads the applied and available migration versions for long-lived
+// callers such as health checks. Unlike Migrator it holds no database
+// connection between calls and never closes the database it is given.
+type Status struct {
+	db     *sql.DB
+	table  string
+	source source.Driver
+}
+
+// NewStatus reads migrations from the source cfg selects and versions from
+// the migrations table in db
+func NewStatus(db *sql.DB, cfg Config) (*Status, error) {
+	src, err := newSourceDriver(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations source: %w", err)
+	}
+	return &Status{db: db, table: cfg.TablePrefix + "schema_migrations", source: src}, nil
+}
+
+// Version returns the applied version and whether it is dirty, or 0 when no
+// migration has been applied. The connection it uses is released on return.
+func (s *Status) Version(ctx context.Context) (uint, bool, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var (
+		version int64
+		dirty   bool
+	)
+	query := `SELECT version, dirty FROM "` + strings.ReplaceAll(s.table, `"`, `""`) + `" LIMIT 1`
+	err = conn.QueryRowContext(ctx, query).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	if version == int64(database.NilVersion) {
+		return 0, dirty, nil
+	}
+	return uint(version), dirty, nil
+}
+
+// LatestVersion returns the highest version among the available migration
+// files, or 0 when there are none
+func (s *Status) LatestVersion() (uint, error) {
+	return highestVersion(s.source)
+}
+
+// Close releases the migrations source; the database is left open
+func (s *Status) Close() error {
+	return s.source.Close()
+}
+
+func highestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		version = next
+	}
+}
@@ -0,0 +1,165 @@
+package notification
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// Handler exposes the notification preference endpoints
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new notification handler
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetSettings godoc
+// @Summary Get notification preferences
+// @Description Get the current user's email preferences per category. Security emails are always sent.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} errors.Response{success=bool,data=Settings} "Notification preferences"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Failed to load notification settings"
+// @Router /api/v1/users/me/notifications [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	settings, err := h.service.GetSettings(c.Request.Context(), contextutil.GetUserID(c))
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	apiErrors.Respond(c, http.StatusOK, settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update notification preferences
+// @Description Replace the current user's email preferences. Disabling security only records the preference; security emails are always sent.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UpdateSettingsRequest true "Notification preferences"
+// @Security BearerAuth
+// @Success 200 {object} errors.Response{success=bool,data=Settings} "Updated notification preferences"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Validation error"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Failed to save notification settings"
+// @Router /api/v1/users/me/notifications [put]
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := apiErrors.BindJSON(c, &req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), contextutil.GetUserID(c), req)
+	if err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	apiErrors.Respond(c, http.StatusOK, settings)
+}
+
+// confirmPage asks the recipient to confirm an unsubscribe, so that link
+// scanners and prefetchers following the link do not unsubscribe anyone. The
+// form posts back to the signed link.
+var confirmPage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<p>Stop receiving {{.Category}} emails?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+// ConfirmUnsubscribe godoc
+// @Summary Confirm an unsubscribe
+// @Description Serve the page an unsubscribe link in an email opens. It changes nothing and asks the recipient to confirm, which posts to the same signed link; no login required
+// @Tags notifications
+// @Produce html
+// @Param user_id query int true "User ID"
+// @Param category query string true "Email category" Enums(product, marketing)
+// @Param expires query int true "Link expiry (unix seconds)"
+// @Param sig query string true "Link signature"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Invalid category"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Invalid or expired link"
+// @Router /api/v1/notifications/unsubscribe [get]
+func (h *Handler) ConfirmUnsubscribe(c *gin.Context) {
+	_, category, ok := unsubscribeTarget(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = confirmPage.Execute(c.Writer, struct {
+		Action   string
+		Category email.Category
+	}{Action: c.Request.URL.RequestURI(), Category: category})
+}
+
+// Unsubscribe godoc
+// @Summary One-click unsubscribe
+// @Description Disable an email category using the signed link included in the email; no login required. Mail clients may post List-Unsubscribe=One-Click to the link as RFC 8058 describes; the body is not required.
+// @Tags notifications
+// @Produce json
+// @Param user_id query int true "User ID"
+// @Param category query string true "Email category" Enums(product, marketing)
+// @Param expires query int true "Link expiry (unix seconds)"
+// @Param sig query string true "Link signature"
+// @Success 200 {object} errors.Response{success=bool,data=UnsubscribeResponse} "Unsubscribed"
+// @Failure 400 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Invalid category"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Invalid or expired link"
+// @Failure 500 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Failed to save notification settings"
+// @Router /api/v1/notifications/unsubscribe [post]
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	userID, category, ok := unsubscribeTarget(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.Unsubscribe(c.Request.Context(), userID, category); err != nil {
+		_ = c.Error(apiErrors.InternalServerError(err))
+		return
+	}
+
+	apiErrors.Respond(c, http.StatusOK, UnsubscribeResponse{Category: category, Subscribed: false})
+}
+
+// unsubscribeTarget reads the user and category of an unsubscribe link,
+// reporting an error and false when they are invalid
+func unsubscribeTarget(c *gin.Context) (uint, email.Category, bool) {
+	userID, err := db.ParseID(c.Query(UserIDParam))
+	if err != nil {
+		_ = c.Error(apiErrors.BadRequest("Invalid user ID"))
+		return 0, "", false
+	}
+
+	category := email.Category(c.Query(CategoryParam))
+	if !category.Valid() {
+		_ = c.Error(apiErrors.BadRequest("Invalid notification category"))
+		return 0, "", false
+	}
+	if !category.Optional() {
+		_ = c.Error(apiErrors.BadRequest("Security emails cannot be unsubscribed from"))
+		return 0, "", false
+	}
+	return userID, category, true
+}
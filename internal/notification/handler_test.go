@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+)
+
+const testSecret = "hKLmNpQrStUvWxYzABCDEFGHIJKLMNOP"
+
+type handlerEnv struct {
+	router  *gin.Engine
+	service Service
+	signer  *auth.URLSigner
+	links   *UnsubscribeLinks
+}
+
+func setupHandler(t *testing.T) *handlerEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := testutil.NewSQLiteDB(t)
+	createUser(t, db, 1)
+	svc := NewService(NewRepository(db))
+	handler := NewHandler(svc)
+	signer := auth.NewURLSigner(testSecret, time.Hour)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	me := router.Group("/api/v1/users/me", func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+	})
+	me.GET("/notifications", handler.GetSettings)
+	me.PUT("/notifications", handler.UpdateSettings)
+	router.GET(UnsubscribePath, auth.SignedURLMiddleware(signer), handler.ConfirmUnsubscribe)
+	router.POST(UnsubscribePath, auth.SignedURLMiddleware(signer), handler.Unsubscribe)
+
+	return &handlerEnv{
+		router:  router,
+		service: svc,
+		signer:  signer,
+		links:   NewUnsubscribeLinks(signer, "https://api.example.com/", time.Hour),
+	}
+}
+
+func (env *handlerEnv) do(method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+// requestURI strips the scheme and host of a signed link
+func requestURI(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	return u.RequestURI()
+}
+
+func TestHandler_Settings(t *testing.T) {
+	env := setupHandler(t)
+
+	w := env.do(http.MethodGet, "/api/v1/users/me/notifications", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"security":true,"product":true,"marketing":false`)
+
+	w = env.do(http.MethodPut, "/api/v1/users/me/notifications", `{"security":true,"product":false,"marketing":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data Settings `json:"data"`
+	}
+	w = env.do(http.MethodGet, "/api/v1/users/me/notifications", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Product)
+	assert.True(t, response.Data.Marketing)
+
+	t.Run("missing category", func(t *testing.T) {
+		w := env.do(http.MethodPut, "/api/v1/users/me/notifications", `{"security":true,"product":false}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_Unsubscribe(t *testing.T) {
+	env := setupHandler(t)
+	_, err := env.service.UpdateSettings(context.Background(), 1, UpdateSettingsRequest{Security: boolPtr(true), Product: boolPtr(true), Marketing: boolPtr(true)})
+	require.NoError(t, err)
+
+	link, err := env.links.URL(1, email.CategoryMarketing)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://api.example.com"+UnsubscribePath+"?"), link)
+
+	w := env.do(http.MethodGet, requestURI(t, link), "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `<form method="post" action="`+html.EscapeString(requestURI(t, link))+`">`)
+
+	allowed, err := env.service.AllowsEmail(context.Background(), 1, email.CategoryMarketing)
+	require.NoError(t, err)
+	assert.True(t, allowed, "opening the link only asks for confirmation")
+
+	w = env.do(http.MethodPost, requestURI(t, link), "List-Unsubscribe=One-Click")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"category":"marketing","subscribed":false`)
+
+	allowed, err = env.service.AllowsEmail(context.Background(), 1, email.CategoryMarketing)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = env.service.AllowsEmail(context.Background(), 1, email.CategoryProduct)
+	require.NoError(t, err)
+	assert.True(t, allowed, "only the linked category is disabled")
+}
+
+func TestHandler_UnsubscribeTokenValidation(t *testing.T) {
+	env := setupHandler(t)
+
+	link, err := env.links.URL(1, email.CategoryMarketing)
+	require.NoError(t, err)
+	valid := requestURI(t, link)
+
+	expired, err := env.signer.Sign(UnsubscribePath+"?category=marketing&user_id=1", -time.Minute)
+	require.NoError(t, err)
+	security, err := env.signer.Sign(UnsubscribePath+"?category=security&user_id=1", time.Hour)
+	require.NoError(t, err)
+	outOfRange, err := env.signer.Sign(UnsubscribePath+"?category=marketing&user_id=4294967296", time.Hour)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "other user", target: strings.Replace(valid, "user_id=1", "user_id=2", 1), wantStatus: http.StatusUnauthorized},
+		{name: "other category", target: strings.Replace(valid, "category=marketing", "category=product", 1), wantStatus: http.StatusUnauthorized},
+		{name: "missing signature", target: UnsubscribePath + "?category=marketing&user_id=1", wantStatus: http.StatusUnauthorized},
+		{name: "expired", target: expired, wantStatus: http.StatusUnauthorized},
+		{name: "security category", target: security, wantStatus: http.StatusBadRequest},
+		{name: "user id out of range", target: outOfRange, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				w := env.do(method, tt.target, "")
+				assert.Equal(t, tt.wantStatus, w.Code, method+" "+w.Body.String())
+			}
+		})
+	}
+
+	settings, err := env.service.GetSettings(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSettings(1).Product, settings.Product, "rejected links must not change settings")
+}
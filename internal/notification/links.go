@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+)
+
+const (
+	// DefaultUnsubscribeTTL is how long unsubscribe links stay valid unless configured
+	DefaultUnsubscribeTTL = 30 * 24 * time.Hour
+
+	// UnsubscribePath is the route of unsubscribe links: GET asks for
+	// confirmation and POST, also sent by RFC 8058 one-click clients, unsubscribes
+	UnsubscribePath = "/api/v1/notifications/unsubscribe"
+
+	// UserIDParam and CategoryParam are the signed query parameters of an unsubscribe link
+	UserIDParam   = "user_id"
+	CategoryParam = "category"
+)
+
+// UnsubscribeLinks mints signed unsubscribe links, verified on the
+// unsubscribe route by auth.SignedURLMiddleware
+type UnsubscribeLinks struct {
+	signer  *auth.URLSigner
+	baseURL string
+	ttl     time.Duration
+}
+
+// NewUnsubscribeLinks creates a link builder. baseURL is the public address of
+// the API, e.g. https://api.example.com. A non-positive ttl uses DefaultUnsubscribeTTL.
+func NewUnsubscribeLinks(signer *auth.URLSigner, baseURL string, ttl time.Duration) *UnsubscribeLinks {
+	return &UnsubscribeLinks{signer: signer, baseURL: strings.TrimRight(baseURL, "/"), ttl: LinkTTL(ttl)}
+}
+
+// LinkTTL returns how long unsubscribe links stay valid when configured with
+// ttl, the maximum their signer must allow
+func LinkTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultUnsubscribeTTL
+	}
+	return ttl
+}
+
+// URL returns the link that unsubscribes userID from category; it matches
+// email.UnsubscribeURLFunc
+func (l *UnsubscribeLinks) URL(userID uint, category email.Category) (string, error) {
+	query := url.Values{}
+	query.Set(UserIDParam, strconv.FormatUint(uint64(userID), 10))
+	query.Set(CategoryParam, string(category))
+	return l.signer.Sign(l.baseURL+UnsubscribePath+"?"+query.Encode(), l.ttl)
+}
@@ -0,0 +1,71 @@
+// Package notification stores users' email notification preferences and
+// enforces them for the email layer.
+package notification
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+)
+
+// Settings holds a user's per-category email preferences
+type Settings struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	// Security is stored but not enforced: security emails are always sent
+	Security  bool      `gorm:"not null" json:"security"`
+	Product   bool      `gorm:"not null" json:"product"`
+	Marketing bool      `gorm:"not null" json:"marketing"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Settings, notification_settings with
+// the configured table prefix
+func (Settings) TableName(namer schema.Namer) string {
+	return namer.TableName("notification_setting")
+}
+
+// DefaultSettings returns the preferences of a user who never changed them.
+// Marketing emails are opt-in.
+func DefaultSettings(userID uint) *Settings {
+	return &Settings{UserID: userID, Security: true, Product: true, Marketing: false}
+}
+
+// Allows reports whether emails of category may be sent to the user
+func (s *Settings) Allows(category email.Category) bool {
+	switch category {
+	case email.CategorySecurity:
+		return true
+	case email.CategoryProduct:
+		return s.Product
+	case email.CategoryMarketing:
+		return s.Marketing
+	}
+	return false
+}
+
+// set changes the preference for category
+func (s *Settings) set(category email.Category, enabled bool) {
+	switch category {
+	case email.CategorySecurity:
+		s.Security = enabled
+	case email.CategoryProduct:
+		s.Product = enabled
+	case email.CategoryMarketing:
+		s.Marketing = enabled
+	}
+}
+
+// UpdateSettingsRequest replaces all notification preferences of the current user
+type UpdateSettingsRequest struct {
+	Security  *bool `json:"security" binding:"required"`
+	Product   *bool `json:"product" binding:"required"`
+	Marketing *bool `json:"marketing" binding:"required"`
+}
+
+// UnsubscribeResponse confirms a one-click unsubscribe
+type UnsubscribeResponse struct {
+	Category   email.Category `json:"category"`
+	Subscribed bool           `json:"subscribed"`
+}
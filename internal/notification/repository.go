@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines notification settings persistence operations
+type Repository interface {
+	// FindByUserID returns nil without error when the user has no stored settings
+	FindByUserID(ctx context.Context, userID uint) (*Settings, error)
+	Save(ctx context.Context, settings *Settings) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new notification settings repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByUserID(ctx context.Context, userID uint) (*Settings, error) {
+	var settings Settings
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Save inserts or replaces the settings of settings.UserID
+func (r *repository) Save(ctx context.Context, settings *Settings) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"security", "product", "marketing", "updated_at"}),
+		}).
+		Create(settings).Error
+}
@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+)
+
+// ErrCategoryNotOptional is returned when unsubscribing from security emails
+var ErrCategoryNotOptional = errors.New("security emails cannot be unsubscribed from")
+
+// Service manages notification preferences. It implements email.Preferences.
+type Service interface {
+	GetSettings(ctx context.Context, userID uint) (*Settings, error)
+	UpdateSettings(ctx context.Context, userID uint, req UpdateSettingsRequest) (*Settings, error)
+	Unsubscribe(ctx context.Context, userID uint, category email.Category) (*Settings, error)
+	AllowsEmail(ctx context.Context, userID uint, category email.Category) (bool, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a new notification service
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// GetSettings returns the stored settings or the defaults
+func (s *service) GetSettings(ctx context.Context, userID uint) (*Settings, error) {
+	settings, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification settings: %w", err)
+	}
+	if settings == nil {
+		return DefaultSettings(userID), nil
+	}
+	return settings, nil
+}
+
+// UpdateSettings replaces every preference of the user
+func (s *service) UpdateSettings(ctx context.Context, userID uint, req UpdateSettingsRequest) (*Settings, error) {
+	settings := &Settings{
+		UserID:    userID,
+		Security:  *req.Security,
+		Product:   *req.Product,
+		Marketing: *req.Marketing,
+	}
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Unsubscribe disables a single optional category, keeping the other preferences
+func (s *service) Unsubscribe(ctx context.Context, userID uint, category email.Category) (*Settings, error) {
+	if !category.Optional() {
+		return nil, ErrCategoryNotOptional
+	}
+
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.set(category, false)
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, fmt.Errorf("failed to save notification settings: %w", err)
+	}
+	return settings, nil
+}
+
+// AllowsEmail reports whether the user accepts emails of category
+func (s *service) AllowsEmail(ctx context.Context, userID uint, category email.Category) (bool, error) {
+	if !category.Optional() {
+		return true, nil
+	}
+
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return settings.Allows(category), nil
+}
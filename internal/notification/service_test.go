@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// createUser inserts a bare user row for the settings foreign key
+func createUser(t *testing.T, db *gorm.DB, id uint) {
+	t.Helper()
+	require.NoError(t, db.Exec(
+		"INSERT INTO users (id, name, email, password_hash) VALUES (?, ?, ?, ?)",
+		id, "User", fmt.Sprintf("user%d@example.com", id), "hash",
+	).Error)
+}
+
+func setupService(t *testing.T) Service {
+	t.Helper()
+	db := testutil.NewSQLiteDB(t)
+	createUser(t, db, 1)
+	return NewService(NewRepository(db))
+}
+
+func TestService_GetSettings_Defaults(t *testing.T) {
+	svc := setupService(t)
+
+	settings, err := svc.GetSettings(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, settings.Security)
+	assert.True(t, settings.Product)
+	assert.False(t, settings.Marketing, "marketing emails are opt-in")
+}
+
+func TestService_UpdateSettings(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.UpdateSettings(ctx, 1, UpdateSettingsRequest{Security: boolPtr(true), Product: boolPtr(false), Marketing: boolPtr(true)})
+	require.NoError(t, err)
+
+	settings, err := svc.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, settings.Product)
+	assert.True(t, settings.Marketing)
+
+	// Saving again updates the existing row
+	_, err = svc.UpdateSettings(ctx, 1, UpdateSettingsRequest{Security: boolPtr(false), Product: boolPtr(true), Marketing: boolPtr(false)})
+	require.NoError(t, err)
+
+	settings, err = svc.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, settings.Security)
+	assert.True(t, settings.Product)
+	assert.False(t, settings.Marketing)
+}
+
+func TestService_AllowsEmail(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.UpdateSettings(ctx, 1, UpdateSettingsRequest{Security: boolPtr(false), Product: boolPtr(false), Marketing: boolPtr(true)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		category email.Category
+		want     bool
+	}{
+		// WHY: Security emails are sent even though the user disabled them
+		{category: email.CategorySecurity, want: true},
+		{category: email.CategoryProduct, want: false},
+		{category: email.CategoryMarketing, want: true},
+		{category: email.Category("unknown"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			allowed, err := svc.AllowsEmail(ctx, 1, tt.category)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+}
+
+func TestService_Unsubscribe(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.UpdateSettings(ctx, 1, UpdateSettingsRequest{Security: boolPtr(true), Product: boolPtr(true), Marketing: boolPtr(true)})
+	require.NoError(t, err)
+
+	settings, err := svc.Unsubscribe(ctx, 1, email.CategoryMarketing)
+	require.NoError(t, err)
+	assert.False(t, settings.Marketing)
+	assert.True(t, settings.Product, "other categories are kept")
+
+	_, err = svc.Unsubscribe(ctx, 1, email.CategorySecurity)
+	assert.ErrorIs(t, err, ErrCategoryNotOptional)
+}
+
+func TestService_GatesEmail(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	mailer := email.WithPreferences(email.NewConsoleEmailService("", nil, nil), svc, nil)
+
+	assert.ErrorIs(t, mailer.SendCategorized(ctx, 1, email.CategoryMarketing, email.Message{To: "user1@example.com"}), email.ErrOptedOut)
+	assert.NoError(t, mailer.SendCategorized(ctx, 1, email.CategoryProduct, email.Message{To: "user1@example.com"}))
+
+	_, err := svc.Unsubscribe(ctx, 1, email.CategoryProduct)
+	require.NoError(t, err)
+	assert.ErrorIs(t, mailer.SendCategorized(ctx, 1, email.CategoryProduct, email.Message{To: "user1@example.com"}), email.ErrOptedOut)
+	assert.NoError(t, mailer.SendCategorized(ctx, 1, email.CategorySecurity, email.Message{To: "user1@example.com"}))
+}
@@ -0,0 +1,71 @@
+// Package outbox delivers events reliably. Events are written to the
+// outbox_messages table in the transaction of the change that caused them, and
+// a relay dispatches the pending ones, so an event is neither lost when the
+// process stops after the commit nor sent for a change that was rolled back.
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+// Message is an event in the outbox
+type Message struct {
+	ID       uint   `gorm:"primaryKey;index:idx_outbox_messages_sent_at_id,priority:2"`
+	TenantID string `gorm:"not null;default:''"`
+	Type     string `gorm:"type:varchar(100);not null"`
+	UserID   uint   `gorm:"not null"`
+	// Payload is the JSON encoding of the event data
+	Payload   string    `gorm:"type:text"`
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"not null"`
+	// SentAt is nil until the event is dispatched
+	SentAt *time.Time `gorm:"index:idx_outbox_messages_sent_at_id,priority:1"`
+}
+
+// TableName specifies the table name for Message, outbox_messages with the
+// configured table prefix
+func (Message) TableName(namer schema.Namer) string {
+	return namer.TableName("outbox_message")
+}
+
+// BeforeCreate is a GORM hook that stamps messages in UTC, like the other
+// tables, so SQLite compares them correctly
+func (m *Message) BeforeCreate(tx *gorm.DB) error {
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	} else {
+		m.CreatedAt = m.CreatedAt.UTC()
+	}
+	return nil
+}
+
+// newMessage encodes event for the outbox
+func newMessage(event events.Event) (*Message, error) {
+	msg := &Message{TenantID: event.TenantID, Type: event.Type, UserID: event.UserID, CreatedAt: event.OccurredAt}
+	if event.Data != nil {
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+		}
+		msg.Payload = string(payload)
+	}
+	return msg, nil
+}
+
+// Event decodes the event m holds. Data is the raw JSON of the payload, so the
+// event serializes as it was written.
+func (m Message) Event() events.Event {
+	event := events.Event{TenantID: m.TenantID, Type: m.Type, UserID: m.UserID, OccurredAt: m.CreatedAt}
+	if m.Payload != "" {
+		event.Data = json.RawMessage(m.Payload)
+	}
+	return event
+}
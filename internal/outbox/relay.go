@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+// DefaultBatchSize is how many messages a relay run dispatches at most
+const DefaultBatchSize = 100
+
+// DefaultMaxAttempts is how many times a relay tries to dispatch a message
+// before leaving it in the outbox as dead
+const DefaultMaxAttempts = 10
+
+// Dispatcher delivers an event; an error leaves the message pending for the next run
+type Dispatcher func(ctx context.Context, event events.Event) error
+
+// PublishTo returns a Dispatcher that publishes events on bus
+func PublishTo(bus *events.Bus) Dispatcher {
+	return func(ctx context.Context, event events.Event) error {
+		bus.Publish(event)
+		return nil
+	}
+}
+
+// RelayConfig configures a Relay; zero values use the defaults
+type RelayConfig struct {
+	BatchSize int
+	// MaxAttempts is how many failed dispatches make a message dead. Dead
+	// messages stay unsent with their last error and are no longer retried
+	// unless MaxAttempts is raised.
+	MaxAttempts int
+	Logger      *slog.Logger
+}
+
+// Relay dispatches the pending messages of the outbox and marks them sent
+type Relay struct {
+	repo        Repository
+	dispatch    Dispatcher
+	batchSize   int
+	maxAttempts int
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewRelay creates a relay dispatching the messages of repo with dispatch
+func NewRelay(repo Repository, dispatch Dispatcher, cfg RelayConfig) *Relay {
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Relay{
+		repo:        repo,
+		dispatch:    dispatch,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		logger:      logger,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run dispatches one batch of pending messages, oldest first, in a single
+// transaction. It suits scheduler.Job.Run. A message whose dispatch fails is
+// counted and retried on the next run, until it has failed MaxAttempts times;
+// the others are still dispatched.
+//
+// A message is marked sent in the transaction that dispatched it, so it is
+// dispatched once unless the process stops between the dispatch and the commit.
+func (r *Relay) Run(ctx context.Context) error {
+	return r.repo.Transaction(ctx, func(txCtx context.Context) error {
+		messages, err := r.repo.Pending(txCtx, r.batchSize, r.maxAttempts)
+		if err != nil {
+			return fmt.Errorf("failed to read pending outbox messages: %w", err)
+		}
+
+		for _, msg := range messages {
+			if err := r.dispatch(ctx, msg.Event()); err != nil {
+				if msg.Attempts+1 >= r.maxAttempts {
+					r.logger.ErrorContext(ctx, "Giving up on outbox message", "id", msg.ID, "type", msg.Type, "attempts", msg.Attempts+1, "error", err)
+				} else {
+					r.logger.WarnContext(ctx, "Failed to dispatch outbox message", "id", msg.ID, "type", msg.Type, "attempts", msg.Attempts+1, "error", err)
+				}
+				if err := r.repo.MarkFailed(txCtx, msg.ID, err.Error()); err != nil {
+					return fmt.Errorf("failed to record outbox dispatch failure: %w", err)
+				}
+				continue
+			}
+			if err := r.repo.MarkSent(txCtx, msg.ID, r.now()); err != nil {
+				return fmt.Errorf("failed to mark outbox message sent: %w", err)
+			}
+		}
+		return nil
+	})
+}
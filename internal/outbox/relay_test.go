@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
+)
+
+// recorder is a Dispatcher remembering what it delivered; it fails while err is set
+type recorder struct {
+	delivered []events.Event
+	err       error
+}
+
+func (r *recorder) dispatch(ctx context.Context, event events.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.delivered = append(r.delivered, event)
+	return nil
+}
+
+func pending(t *testing.T, database *gorm.DB) []Message {
+	t.Helper()
+	messages, err := NewRepository(database).Pending(context.Background(), 10, 0)
+	require.NoError(t, err)
+	return messages
+}
+
+func TestRepository_AddJoinsTransaction(t *testing.T) {
+	database := testutil.NewSQLiteDB(t)
+	repo := NewRepository(database)
+	ctx := context.Background()
+
+	rolledBack := errors.New("rolled back")
+	err := repo.Transaction(ctx, func(txCtx context.Context) error {
+		require.NoError(t, repo.Add(txCtx, events.Event{Type: events.TypeUserCreated, UserID: 1}))
+		return rolledBack
+	})
+	require.ErrorIs(t, err, rolledBack)
+	assert.Empty(t, pending(t, database), "a rolled back change leaves no event behind")
+
+	err = repo.Transaction(ctx, func(txCtx context.Context) error {
+		return repo.Add(txCtx, events.Event{Type: events.TypeUserCreated, UserID: 2, Data: map[string]string{"name": "Jane"}})
+	})
+	require.NoError(t, err)
+
+	messages := pending(t, database)
+	require.Len(t, messages, 1)
+	assert.Equal(t, uint(2), messages[0].UserID)
+	assert.JSONEq(t, `{"name":"Jane"}`, messages[0].Payload)
+}
+
+func TestRelay_DeliversOnce(t *testing.T) {
+	database := testutil.NewSQLiteDB(t)
+	repo := NewRepository(database)
+	ctx := context.Background()
+	require.NoError(t, repo.Add(ctx, events.Event{TenantID: "acme", Type: events.TypeUserCreated, UserID: 1, Data: map[string]string{"name": "John"}}))
+	require.NoError(t, repo.Add(ctx, events.Event{Type: events.TypeUserUpdated, UserID: 2}))
+
+	rec := &recorder{}
+	relay := NewRelay(repo, rec.dispatch, RelayConfig{})
+
+	require.NoError(t, relay.Run(ctx))
+	require.NoError(t, relay.Run(ctx))
+
+	require.Len(t, rec.delivered, 2)
+	assert.Equal(t, events.TypeUserCreated, rec.delivered[0].Type)
+	assert.Equal(t, uint(1), rec.delivered[0].UserID)
+	assert.Equal(t, "acme", rec.delivered[0].TenantID)
+	assert.False(t, rec.delivered[0].OccurredAt.IsZero())
+	data, err := json.Marshal(rec.delivered[0].Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"John"}`, string(data))
+	assert.Equal(t, events.TypeUserUpdated, rec.delivered[1].Type)
+	assert.Empty(t, pending(t, database))
+}
+
+func TestRelay_RetriesFailedDispatch(t *testing.T) {
+	database := testutil.NewSQLiteDB(t)
+	repo := NewRepository(database)
+	ctx := context.Background()
+	require.NoError(t, repo.Add(ctx, events.Event{Type: events.TypeUserCreated, UserID: 1}))
+
+	rec := &recorder{err: errors.New("subscriber unavailable")}
+	relay := NewRelay(repo, rec.dispatch, RelayConfig{})
+
+	require.NoError(t, relay.Run(ctx))
+	messages := pending(t, database)
+	require.Len(t, messages, 1)
+	assert.Equal(t, 1, messages[0].Attempts)
+	assert.Equal(t, "subscriber unavailable", messages[0].LastError)
+
+	rec.err = nil
+	require.NoError(t, relay.Run(ctx))
+	assert.Len(t, rec.delivered, 1)
+	assert.Empty(t, pending(t, database))
+}
+
+func TestRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	database := testutil.NewSQLiteDB(t)
+	repo := NewRepository(database)
+	ctx := context.Background()
+	require.NoError(t, repo.Add(ctx, events.Event{Type: events.TypeUserCreated, UserID: 1}))
+
+	rec := &recorder{err: errors.New("subscriber unavailable")}
+	relay := NewRelay(repo, rec.dispatch, RelayConfig{MaxAttempts: 2})
+
+	require.NoError(t, relay.Run(ctx))
+	require.NoError(t, relay.Run(ctx))
+	rec.err = nil
+	require.NoError(t, relay.Run(ctx))
+
+	assert.Empty(t, rec.delivered, "a dead message is not retried")
+	messages := pending(t, database)
+	require.Len(t, messages, 1, "a dead message stays in the outbox")
+	assert.Equal(t, 2, messages[0].Attempts)
+	assert.Equal(t, "subscriber unavailable", messages[0].LastError)
+}
+
+func TestRelay_BatchSize(t *testing.T) {
+	database := testutil.NewSQLiteDB(t)
+	repo := NewRepository(database)
+	ctx := context.Background()
+	for userID := range uint(3) {
+		require.NoError(t, repo.Add(ctx, events.Event{Type: events.TypeUserCreated, UserID: userID + 1}))
+	}
+
+	rec := &recorder{}
+	relay := NewRelay(repo, rec.dispatch, RelayConfig{BatchSize: 2})
+
+	require.NoError(t, relay.Run(ctx))
+	assert.Len(t, rec.delivered, 2)
+	require.NoError(t, relay.Run(ctx))
+	assert.Len(t, rec.delivered, 3)
+	assert.Equal(t, uint(3), rec.delivered[2].UserID, "messages are dispatched oldest first")
+}
+
+func TestPublishTo(t *testing.T) {
+	bus := events.NewBus(0)
+	ch, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	require.NoError(t, PublishTo(bus)(context.Background(), events.Event{Type: events.TypeUserCreated, UserID: 1}))
+
+	require.Len(t, ch, 1)
+	assert.Equal(t, events.TypeUserCreated, (<-ch).Type)
+}
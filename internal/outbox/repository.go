@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+// Repository defines outbox persistence operations. Every method joins the
+// transaction carried by ctx, see db.WithTx.
+type Repository interface {
+	// Add writes event to the outbox
+	Add(ctx context.Context, event events.Event) error
+	// Pending returns up to limit unsent messages, oldest first. A positive
+	// maxAttempts leaves out the messages that failed that many times. Inside a
+	// transaction on PostgreSQL the rows stay locked until it ends, and rows
+	// locked by another relay are skipped.
+	Pending(ctx context.Context, limit, maxAttempts int) ([]Message, error)
+	MarkSent(ctx context.Context, id uint, sentAt time.Time) error
+	// MarkFailed counts a failed dispatch of a message, which stays pending
+	MarkFailed(ctx context.Context, id uint, cause string) error
+	Transaction(ctx context.Context, fn func(context.Context) error) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new outbox repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) conn(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).WithContext(ctx)
+}
+
+func (r *repository) Add(ctx context.Context, event events.Event) error {
+	msg, err := newMessage(event)
+	if err != nil {
+		return err
+	}
+	return r.conn(ctx).Create(msg).Error
+}
+
+func (r *repository) Pending(ctx context.Context, limit, maxAttempts int) ([]Message, error) {
+	query := r.conn(ctx).Where("sent_at IS NULL").Order("id").Limit(limit)
+	if maxAttempts > 0 {
+		query = query.Where("attempts < ?", maxAttempts)
+	}
+	// WHY: SQLite has no row locks; it only serves single instances, whose scheduler never overlaps relay runs
+	if query.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+
+	var messages []Message
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *repository) MarkSent(ctx context.Context, id uint, sentAt time.Time) error {
+	return r.conn(ctx).Model(&Message{}).Where("id = ?", id).Update("sent_at", sentAt.UTC()).Error
+}
+
+func (r *repository) MarkFailed(ctx context.Context, id uint, cause string) error {
+	return r.conn(ctx).Model(&Message{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": cause,
+	}).Error
+}
+
+func (r *repository) Transaction(ctx context.Context, fn func(context.Context) error) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(db.WithTx(ctx, tx))
+	})
+}
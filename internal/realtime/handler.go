@@ -0,0 +1,216 @@
+// Package realtime pushes events from the events bus to connected users over
+// WebSockets.
+package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+const (
+	// DefaultPingInterval is how often idle connections are pinged
+	DefaultPingInterval = 30 * time.Second
+
+	// AccessTokenQueryParam carries the access token for clients that cannot
+	// set headers on the upgrade request, such as browsers
+	AccessTokenQueryParam = "access_token"
+
+	writeWait      = 10 * time.Second
+	maxMessageSize = 512
+)
+
+// Handler upgrades authenticated requests to WebSockets and streams the
+// caller's events
+type Handler struct {
+	authService  auth.Service
+	bus          *events.Bus
+	upgrader     websocket.Upgrader
+	pingInterval time.Duration
+}
+
+// HandlerOption configures optional Handler behavior
+type HandlerOption func(*Handler)
+
+// WithPingInterval sets how often the server pings the client; a client that
+// does not answer within two intervals is disconnected
+func WithPingInterval(interval time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if interval > 0 {
+			h.pingInterval = interval
+		}
+	}
+}
+
+// WithAllowedOrigins restricts which browser origins may connect. An empty
+// list or "*" allows every origin; requests without an Origin header are
+// always allowed.
+func WithAllowedOrigins(origins []string) HandlerOption {
+	return func(h *Handler) {
+		if len(origins) == 0 || slices.Contains(origins, "*") {
+			h.upgrader.CheckOrigin = func(*http.Request) bool { return true }
+			return
+		}
+		h.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || slices.ContainsFunc(origins, func(allowed string) bool {
+				return strings.EqualFold(allowed, origin)
+			})
+		}
+	}
+}
+
+// NewHandler creates a WebSocket handler. Without WithAllowedOrigins only
+// same-origin browser connections are accepted.
+func NewHandler(authService auth.Service, bus *events.Bus, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		authService:  authService,
+		bus:          bus,
+		pingInterval: DefaultPingInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Serve godoc
+// @Summary Subscribe to real-time notifications
+// @Description Upgrade to a WebSocket that receives the caller's events (session.revoked, user.updated) as JSON messages. Browsers may pass the access token in the access_token query parameter or, when tokens are delivered as cookies, rely on the access_token cookie. The connection is closed once the token expires or the account is deleted or deactivated.
+// @Tags users
+// @Security BearerAuth
+// @Param access_token query string false "Access token, when the Authorization header cannot be set"
+// @Success 101 {object} events.Event "Switching protocols; events are sent as messages"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized"
+// @Router /api/v1/ws [get]
+func (h *Handler) Serve(c *gin.Context) {
+	token, apiErr := accessToken(c)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	claims, err := h.authService.ValidateToken(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrExpiredToken) {
+			_ = c.Error(apiErrors.TokenExpired("Access token has expired"))
+		} else {
+			_ = c.Error(apiErrors.Unauthorized("Invalid access token"))
+		}
+		return
+	}
+
+	if apiErr := h.checkUserStatus(c.Request.Context(), claims.UserID); apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	// Upgrade writes its own error response when the handshake is invalid
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	eventsCh, unsubscribe := h.bus.Subscribe(claims.UserID)
+	defer unsubscribe()
+
+	h.stream(c.Request.Context(), conn, token, eventsCh)
+}
+
+// checkUserStatus rejects users that were deleted or deactivated after their
+// token was issued, like auth.AuthMiddleware
+func (h *Handler) checkUserStatus(ctx context.Context, userID uint) *apiErrors.APIError {
+	if err := h.authService.CheckUserStatus(ctx, userID); err != nil {
+		if errors.Is(err, auth.ErrUserInactive) {
+			return apiErrors.Unauthorized("User account is no longer active")
+		}
+		return apiErrors.ServerError(err)
+	}
+	return nil
+}
+
+// stream writes events and pings until the client goes away, the access
+// token stops being valid or its user is deleted or deactivated
+func (h *Handler) stream(ctx context.Context, conn *websocket.Conn, token string, eventsCh <-chan events.Event) {
+	pongWait := 2 * h.pingInterval
+
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	// WHY: Control frames (pong, close) are only processed while reading
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			claims, err := h.authService.ValidateToken(token)
+			if err == nil {
+				err = h.authService.CheckUserStatus(ctx, claims.UserID)
+			}
+			if err != nil {
+				message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "access token is no longer valid")
+				_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait))
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// accessToken reads the bearer token from the Authorization header, falling
+// back to the access_token query parameter and then the access_token cookie
+func accessToken(c *gin.Context) (string, *apiErrors.APIError) {
+	header := c.GetHeader(auth.AuthorizationHeader)
+	if strings.TrimSpace(header) == "" {
+		if token := c.Query(AccessTokenQueryParam); token != "" {
+			return token, nil
+		}
+		if token, ok := auth.AccessTokenFromCookie(c); ok {
+			return token, nil
+		}
+		return "", apiErrors.Unauthorized("Authorization header, access_token query parameter or access_token cookie required")
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return "", apiErrors.Unauthorized("Invalid authorization header format")
+	}
+	return fields[1], nil
+}
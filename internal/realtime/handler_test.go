@@ -0,0 +1,227 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+type testEnv struct {
+	url         string
+	bus         *events.Bus
+	authService *userStatusService
+}
+
+// userStatusService reports the users stored in inactive as deleted or deactivated
+type userStatusService struct {
+	auth.Service
+	inactive sync.Map
+}
+
+func (s *userStatusService) CheckUserStatus(_ context.Context, userID uint) error {
+	if _, ok := s.inactive.Load(userID); ok {
+		return auth.ErrUserInactive
+	}
+	return nil
+}
+
+func setupTestServer(t *testing.T, opts ...HandlerOption) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	authService := &userStatusService{Service: auth.NewService(&config.NewTestConfig().JWT)}
+	bus := events.NewBus(0)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.GET("/api/v1/ws", NewHandler(authService, bus, opts...).Serve)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &testEnv{
+		url:         "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/ws",
+		bus:         bus,
+		authService: authService,
+	}
+}
+
+func (env *testEnv) token(t *testing.T, userID uint) string {
+	t.Helper()
+	token, err := env.authService.GenerateToken(userID, "alice@example.com", "Alice")
+	require.NoError(t, err)
+	return token
+}
+
+func (env *testEnv) dial(t *testing.T, url string, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// waitForSubscriber blocks until the handler has subscribed, so events
+// published afterwards are not missed
+func waitForSubscriber(t *testing.T, bus *events.Bus, userID uint) {
+	t.Helper()
+	require.Eventually(t, func() bool { return bus.Subscribers(userID) > 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestHandler_ReceivesOwnEvents(t *testing.T) {
+	env := setupTestServer(t)
+	header := http.Header{auth.AuthorizationHeader: {"Bearer " + env.token(t, 1)}}
+	conn := env.dial(t, env.url, header)
+	waitForSubscriber(t, env.bus, 1)
+
+	env.bus.Publish(events.Event{Type: events.TypeUserUpdated, UserID: 2})
+	env.bus.Publish(events.Event{Type: events.TypeSessionRevoked, UserID: 1, Data: map[string]any{"revoked_refresh_tokens": 2}})
+
+	var received map[string]any
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&received))
+	assert.Equal(t, events.TypeSessionRevoked, received["type"])
+	assert.Equal(t, float64(1), received["user_id"])
+	assert.Equal(t, map[string]any{"revoked_refresh_tokens": float64(2)}, received["data"])
+	assert.NotEmpty(t, received["occurred_at"])
+}
+
+func TestHandler_TokenInQuery(t *testing.T) {
+	env := setupTestServer(t)
+	conn := env.dial(t, env.url+"?access_token="+env.token(t, 7), nil)
+	waitForSubscriber(t, env.bus, 7)
+
+	env.bus.Publish(events.Event{Type: events.TypeUserUpdated, UserID: 7})
+
+	var received events.Event
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&received))
+	assert.Equal(t, events.TypeUserUpdated, received.Type)
+}
+
+func TestHandler_TokenInCookie(t *testing.T) {
+	env := setupTestServer(t)
+	header := http.Header{"Cookie": {auth.AccessTokenCookie + "=" + env.token(t, 8)}}
+	conn := env.dial(t, env.url, header)
+	waitForSubscriber(t, env.bus, 8)
+
+	env.bus.Publish(events.Event{Type: events.TypeUserUpdated, UserID: 8})
+
+	var received events.Event
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&received))
+	assert.Equal(t, events.TypeUserUpdated, received.Type)
+}
+
+func TestHandler_Unauthorized(t *testing.T) {
+	env := setupTestServer(t)
+
+	tests := []struct {
+		name   string
+		url    string
+		header http.Header
+	}{
+		{name: "missing token", url: env.url},
+		{name: "malformed header", url: env.url, header: http.Header{auth.AuthorizationHeader: {"Token abc"}}},
+		{name: "invalid token", url: env.url + "?access_token=not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp, err := websocket.DefaultDialer.Dial(tt.url, tt.header)
+			require.ErrorIs(t, err, websocket.ErrBadHandshake)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func TestHandler_InactiveUser(t *testing.T) {
+	t.Run("rejected on connect", func(t *testing.T) {
+		env := setupTestServer(t)
+		env.authService.inactive.Store(uint(1), true)
+
+		_, resp, err := websocket.DefaultDialer.Dial(env.url+"?access_token="+env.token(t, 1), nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("disconnected once deactivated", func(t *testing.T) {
+		env := setupTestServer(t, WithPingInterval(20*time.Millisecond))
+		conn := env.dial(t, env.url+"?access_token="+env.token(t, 1), nil)
+		waitForSubscriber(t, env.bus, 1)
+
+		env.authService.inactive.Store(uint(1), true)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+	})
+}
+
+func TestHandler_Ping(t *testing.T) {
+	env := setupTestServer(t, WithPingInterval(20*time.Millisecond))
+	conn := env.dial(t, env.url+"?access_token="+env.token(t, 1), nil)
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	// WHY: The client only handles control frames while reading
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("expected a ping from the server")
+	}
+}
+
+func TestHandler_UnsubscribesOnDisconnect(t *testing.T) {
+	env := setupTestServer(t)
+	conn := env.dial(t, env.url+"?access_token="+env.token(t, 1), nil)
+	waitForSubscriber(t, env.bus, 1)
+
+	require.NoError(t, conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second)))
+
+	assert.Eventually(t, func() bool { return env.bus.Subscribers(1) == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWithAllowedOrigins(t *testing.T) {
+	env := setupTestServer(t, WithAllowedOrigins([]string{"https://app.example.com"}))
+	token := env.token(t, 1)
+
+	env.dial(t, env.url+"?access_token="+token, http.Header{"Origin": {"https://APP.example.com"}})
+
+	_, resp, err := websocket.DefaultDialer.Dial(env.url+"?access_token="+token, http.Header{"Origin": {"https://evil.example.com"}})
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,190 @@
+package realtime
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/contextutil"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often an idle event stream sends a comment
+	// so proxies do not close it
+	DefaultHeartbeatInterval = 15 * time.Second
+
+	// DefaultMaxStreams caps the concurrent event streams
+	DefaultMaxStreams = 10
+
+	// LastEventIDHeader is sent by EventSource clients when they reconnect
+	LastEventIDHeader = "Last-Event-ID"
+
+	// streamFullRetryAfter is the Retry-After, in seconds, sent when every stream slot is taken
+	streamFullRetryAfter = 5
+)
+
+// StreamHandler streams the events of the bus to admin dashboards as
+// Server-Sent Events, each admin seeing only the events of their own tenant
+type StreamHandler struct {
+	bus               *events.Bus
+	authService       auth.Service
+	heartbeatInterval time.Duration
+	maxStreams        int64
+	streams           atomic.Int64
+}
+
+// StreamOption configures optional StreamHandler behavior
+type StreamOption func(*StreamHandler)
+
+// WithHeartbeatInterval sets how often an idle stream sends a heartbeat comment
+func WithHeartbeatInterval(interval time.Duration) StreamOption {
+	return func(h *StreamHandler) {
+		if interval > 0 {
+			h.heartbeatInterval = interval
+		}
+	}
+}
+
+// WithMaxStreams caps the concurrent streams; further requests get a 503
+func WithMaxStreams(n int) StreamOption {
+	return func(h *StreamHandler) {
+		if n > 0 {
+			h.maxStreams = int64(n)
+		}
+	}
+}
+
+// WithUserStatusCheck re-checks on every heartbeat that the authenticated
+// user is still active, ending the stream once they are deleted or deactivated
+func WithUserStatusCheck(authService auth.Service) StreamOption {
+	return func(h *StreamHandler) {
+		h.authService = authService
+	}
+}
+
+// NewStreamHandler creates a Server-Sent Events handler for the events of bus
+func NewStreamHandler(bus *events.Bus, opts ...StreamOption) *StreamHandler {
+	h := &StreamHandler{
+		bus:               bus,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		maxStreams:        DefaultMaxStreams,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Serve godoc
+// @Summary      Admin event stream (Admin only)
+// @Description  Server-Sent Events stream of every user lifecycle and audit event (user.registered, user.created, user.updated, session.revoked, audit.recorded) in the admin's tenant. The SSE event name is the event type and the data is the event as JSON. A comment is sent every 15 seconds while idle. The stream ends once the admin's account is deleted or deactivated. On reconnect, events after the Last-Event-ID still in the recent history are replayed.
+// @Tags         admin
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        Last-Event-ID  header  int  false  "ID of the last event received, sent by EventSource on reconnect"
+// @Success      200  {object}  events.Event  "Stream of events"
+// @Failure      401  {object}  errors.Response{success=bool,error=errors.ErrorInfo}  "Unauthorized"
+// @Failure      403  {object}  errors.Response{success=bool,error=errors.ErrorInfo}  "Admin access required"
+// @Failure      503  {object}  errors.Response{success=bool,error=errors.ErrorInfo}  "Too many streams"
+// @Router       /api/v1/admin/events/stream [get]
+func (h *StreamHandler) Serve(c *gin.Context) {
+	if h.streams.Add(1) > h.maxStreams {
+		h.streams.Add(-1)
+		_ = c.Error(apiErrors.ServiceUnavailable("Too many event streams are open", streamFullRetryAfter))
+		return
+	}
+	defer h.streams.Add(-1)
+
+	ctx := c.Request.Context()
+
+	// WHY: A malformed ID resumes nothing, like a fresh connection
+	lastID, _ := strconv.ParseUint(c.GetHeader(LastEventIDHeader), 10, 64)
+	replay, feed, unsubscribe := h.bus.SubscribeAll(lastID)
+	defer unsubscribe()
+	tenantID := streamTenant(c)
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// WHY: Stops nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+
+	// WHY: server.writetimeout bounds the whole response, so it would cut the stream off
+	// after that many seconds; the deadline is lifted for this connection only
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	// WHY: Sends the headers now so the client sees the stream open before the first event
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		if len(replay) > 0 {
+			for _, event := range replay {
+				if event.TenantID == tenantID {
+					render(c, event)
+				}
+			}
+			replay = nil
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-feed:
+			if !ok {
+				return false
+			}
+			if event.TenantID != tenantID {
+				return true
+			}
+			render(c, event)
+			heartbeat.Reset(h.heartbeatInterval)
+		case <-heartbeat.C:
+			if !h.userActive(c) {
+				return false
+			}
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+		}
+		return true
+	})
+}
+
+// streamTenant returns the tenant of the access token the stream was opened
+// with; the bus carries the events of every tenant
+func streamTenant(c *gin.Context) string {
+	if claims := contextutil.GetUser(c); claims != nil {
+		return claims.TenantID
+	}
+	return tenant.Default
+}
+
+// userActive reports whether the user the stream was opened for is still
+// active; lookup failures keep the stream open like a missed heartbeat would
+func (h *StreamHandler) userActive(c *gin.Context) bool {
+	claims := contextutil.GetUser(c)
+	if h.authService == nil || claims == nil {
+		return true
+	}
+	return !errors.Is(h.authService.CheckUserStatus(c.Request.Context(), claims.UserID), auth.ErrUserInactive)
+}
+
+func render(c *gin.Context, event events.Event) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(event.ID, 10),
+		Event: event.Type,
+		Data:  event,
+	})
+}
@@ -0,0 +1,239 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+)
+
+type sseMessage struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// readMessage parses the next message or comment of an SSE stream
+func readMessage(t *testing.T, reader *bufio.Reader) sseMessage {
+	t.Helper()
+
+	var message sseMessage
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return message
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		switch field {
+		case "":
+			message.comment = strings.TrimSpace(value)
+		case "id":
+			message.id = value
+		case "event":
+			message.event = value
+		case "data":
+			message.data = value
+		default:
+			t.Fatalf("unexpected field %q", field)
+		}
+	}
+}
+
+func setupStreamServer(t *testing.T, bus *events.Bus, opts ...StreamOption) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.GET("/api/v1/admin/events/stream", NewStreamHandler(bus, opts...).Serve)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server.URL + "/api/v1/admin/events/stream"
+}
+
+// openEventStream connects and returns the response and a function that disconnects
+func openEventStream(t *testing.T, url, lastEventID string) (*http.Response, func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set(LastEventIDHeader, lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	disconnect := func() {
+		cancel()
+		_ = resp.Body.Close()
+	}
+	t.Cleanup(disconnect)
+	return resp, disconnect
+}
+
+func waitForFeeds(t *testing.T, bus *events.Bus, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return bus.Feeds() == n }, time.Second, 5*time.Millisecond)
+}
+
+func TestStreamHandler_DeliversEvents(t *testing.T) {
+	bus := events.NewBus(0)
+	url := setupStreamServer(t, bus)
+	resp, _ := openEventStream(t, url, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	waitForFeeds(t, bus, 1)
+
+	bus.Publish(events.Event{Type: events.TypeUserRegistered, UserID: 7, Data: map[string]any{"email": "new@example.com"}})
+
+	message := readMessage(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "1", message.id)
+	assert.Equal(t, events.TypeUserRegistered, message.event)
+	var event events.Event
+	require.NoError(t, json.Unmarshal([]byte(message.data), &event))
+	assert.Equal(t, uint(7), event.UserID)
+	assert.Equal(t, map[string]any{"email": "new@example.com"}, event.Data)
+}
+
+func TestStreamHandler_OnlyDeliversOwnTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus(0)
+	handler := NewStreamHandler(bus)
+
+	router := gin.New()
+	router.GET("/stream/:tenant", func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1, TenantID: c.Param("tenant")})
+	}, handler.Serve)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	acmeResp, _ := openEventStream(t, server.URL+"/stream/acme", "")
+	globexResp, _ := openEventStream(t, server.URL+"/stream/globex", "")
+	waitForFeeds(t, bus, 2)
+
+	bus.Publish(events.Event{TenantID: "acme", Type: events.TypeUserRegistered, UserID: 7})
+	bus.Publish(events.Event{TenantID: "globex", Type: events.TypeUserRegistered, UserID: 8})
+
+	acme := readMessage(t, bufio.NewReader(acmeResp.Body))
+	assert.Equal(t, "1", acme.id)
+	assert.Contains(t, acme.data, `"user_id":7`)
+	assert.NotContains(t, acme.data, "acme", "the tenant is not sent to clients")
+
+	globex := readMessage(t, bufio.NewReader(globexResp.Body))
+	assert.Equal(t, "2", globex.id, "the other tenant's event is skipped")
+	assert.Contains(t, globex.data, `"user_id":8`)
+
+	t.Run("replay skips other tenants", func(t *testing.T) {
+		resp, _ := openEventStream(t, server.URL+"/stream/acme", acme.id)
+		waitForFeeds(t, bus, 3)
+		bus.Publish(events.Event{TenantID: "acme", Type: events.TypeUserUpdated, UserID: 7})
+
+		message := readMessage(t, bufio.NewReader(resp.Body))
+		assert.Equal(t, "3", message.id, "event 2 belongs to globex")
+	})
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	bus := events.NewBus(0)
+	url := setupStreamServer(t, bus, WithHeartbeatInterval(10*time.Millisecond))
+	resp, _ := openEventStream(t, url, "")
+
+	message := readMessage(t, bufio.NewReader(resp.Body))
+
+	assert.Equal(t, "heartbeat", message.comment)
+	assert.Empty(t, message.event)
+}
+
+func TestStreamHandler_EndsForInactiveUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus(0)
+	authService := &userStatusService{}
+	handler := NewStreamHandler(bus, WithHeartbeatInterval(10*time.Millisecond), WithUserStatusCheck(authService))
+
+	router := gin.New()
+	router.GET("/stream", func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+	}, handler.Serve)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	resp, _ := openEventStream(t, server.URL+"/stream", "")
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "heartbeat", readMessage(t, reader).comment)
+
+	authService.inactive.Store(uint(1), true)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, reader)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "the stream should end cleanly")
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end")
+	}
+}
+
+func TestStreamHandler_ReplaysAfterReconnect(t *testing.T) {
+	bus := events.NewBus(0)
+	url := setupStreamServer(t, bus)
+	resp, disconnect := openEventStream(t, url, "")
+	waitForFeeds(t, bus, 1)
+
+	bus.Publish(events.Event{Type: events.TypeUserRegistered, UserID: 1})
+	first := readMessage(t, bufio.NewReader(resp.Body))
+	require.Equal(t, "1", first.id)
+
+	disconnect()
+	waitForFeeds(t, bus, 0)
+	bus.Publish(events.Event{Type: events.TypeUserUpdated, UserID: 1})
+	bus.Publish(events.Event{Type: events.TypeAuditRecorded, UserID: 2})
+
+	resp, _ = openEventStream(t, url, first.id)
+	reader := bufio.NewReader(resp.Body)
+
+	second, third := readMessage(t, reader), readMessage(t, reader)
+	assert.Equal(t, "2", second.id)
+	assert.Equal(t, events.TypeUserUpdated, second.event)
+	assert.Equal(t, "3", third.id)
+	assert.Equal(t, events.TypeAuditRecorded, third.event)
+}
+
+func TestStreamHandler_MaxStreams(t *testing.T) {
+	bus := events.NewBus(0)
+	url := setupStreamServer(t, bus, WithMaxStreams(1))
+	_, disconnect := openEventStream(t, url, "")
+	waitForFeeds(t, bus, 1)
+
+	resp, _ := openEventStream(t, url, "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("Retry-After"))
+
+	disconnect()
+	waitForFeeds(t, bus, 0)
+	require.Eventually(t, func() bool {
+		resp, disconnect := openEventStream(t, url, "")
+		defer disconnect()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
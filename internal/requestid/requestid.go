@@ -0,0 +1,21 @@
+// Package requestid carries the request correlation ID through a context so
+// outbound calls made while serving a request can forward it.
+package requestid
+
+import "context"
+
+// Header is the HTTP header that carries the request ID in and out of the API
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" when there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/health"
+)
+
+// staleFactor is how many intervals may pass without a success before a job is reported stale
+const staleFactor = 3
+
+// Checker reports degraded health when a job has not succeeded within staleFactor intervals
+type Checker struct {
+	scheduler *Scheduler
+}
+
+// NewChecker creates a health checker for the scheduler's jobs
+func NewChecker(s *Scheduler) *Checker {
+	return &Checker{scheduler: s}
+}
+
+func (c *Checker) Name() string {
+	return "scheduler"
+}
+
+func (c *Checker) Check(ctx context.Context) health.CheckResult {
+	startedAt := c.scheduler.StartedAt()
+	if startedAt.IsZero() {
+		return health.CheckResult{
+			Status:  health.CheckWarn,
+			Message: "Scheduler not started",
+		}
+	}
+
+	now := c.scheduler.clock.Now()
+	stale := make(map[string]string)
+	for _, status := range c.scheduler.Statuses() {
+		since := status.LastSuccess
+		if since.IsZero() {
+			since = startedAt
+		}
+		if now.Sub(since) > staleFactor*status.Interval {
+			stale[status.Name] = fmt.Sprintf("no successful run for %s", now.Sub(since).Round(time.Second))
+		}
+	}
+
+	if len(stale) > 0 {
+		return health.CheckResult{
+			Status:  health.CheckWarn,
+			Message: fmt.Sprintf("%d scheduled job(s) stale", len(stale)),
+			Details: stale,
+		}
+	}
+
+	return health.CheckResult{
+		Status:  health.CheckPass,
+		Message: "Scheduled jobs healthy",
+	}
+}
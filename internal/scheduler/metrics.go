@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	appmetrics "github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+type metrics struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scheduler_job_runs_total",
+			Help: "Total number of scheduled job runs by result.",
+		}, []string{"job", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "scheduler_job_duration_seconds",
+			Help:    "Duration of scheduled job runs in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"job"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "scheduler_job_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful run of a scheduled job.",
+		}, []string{"job"}),
+	}
+
+	m.runs = appmetrics.Register(registerer, m.runs)
+	m.duration = appmetrics.Register(registerer, m.duration)
+	m.lastSuccess = appmetrics.Register(registerer, m.lastSuccess)
+
+	return m
+}
@@ -0,0 +1,296 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+	resultPanic   = "panic"
+	resultSkipped = "skipped"
+)
+
+var (
+	// ErrInvalidJob is returned when a job is missing a name, interval or run function
+	ErrInvalidJob = errors.New("invalid job")
+	// ErrDuplicateJob is returned when a job with the same name is already registered
+	ErrDuplicateJob = errors.New("job already registered")
+	// ErrAlreadyStarted is returned when jobs are added or the scheduler is started twice
+	ErrAlreadyStarted = errors.New("scheduler already started")
+)
+
+// Job describes a named background task executed periodically
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) to every interval to avoid thundering herds
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+// Clock abstracts time so tests can drive the scheduler deterministically
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Config holds scheduler dependencies; zero values fall back to sensible defaults
+type Config struct {
+	Clock      Clock
+	Logger     *slog.Logger
+	Registerer prometheus.Registerer
+}
+
+// JobStatus is a point-in-time snapshot of a job's scheduling state
+type JobStatus struct {
+	Name        string
+	Interval    time.Duration
+	LastSuccess time.Time
+	Running     bool
+}
+
+type jobState struct {
+	job         Job
+	running     atomic.Bool
+	lastSuccess atomic.Int64
+}
+
+// Scheduler runs registered jobs in their own goroutines with panic recovery,
+// overlap prevention and Prometheus instrumentation
+type Scheduler struct {
+	clock   Clock
+	logger  *slog.Logger
+	metrics *metrics
+
+	mu        sync.Mutex
+	jobs      []*jobState
+	started   bool
+	startedAt time.Time
+	stop      chan struct{}
+	cancel    context.CancelFunc
+	loops     sync.WaitGroup
+	runs      sync.WaitGroup
+}
+
+// New creates a scheduler
+func New(cfg Config) *Scheduler {
+	clock := cfg.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	return &Scheduler{
+		clock:   clock,
+		logger:  logger,
+		metrics: newMetrics(registerer),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Add registers a job; jobs must be added before Start
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Interval <= 0 || job.Run == nil {
+		return fmt.Errorf("%w: name, positive interval and run function are required", ErrInvalidJob)
+	}
+	if job.Jitter < 0 {
+		return fmt.Errorf("%w: jitter must be non-negative", ErrInvalidJob)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrAlreadyStarted
+	}
+	for _, existing := range s.jobs {
+		if existing.job.Name == job.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &jobState{job: job})
+	return nil
+}
+
+// Start launches one scheduling loop per registered job
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrAlreadyStarted
+	}
+	s.started = true
+	s.startedAt = s.clock.Now()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	for _, state := range s.jobs {
+		s.loops.Add(1)
+		go s.loop(runCtx, state)
+	}
+
+	s.logger.Info("Scheduler started", "jobs", len(s.jobs))
+	return nil
+}
+
+// Stop halts scheduling and waits for in-flight runs to finish. If ctx expires
+// first, in-flight runs are cancelled and ctx's error is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return fmt.Errorf("scheduler stop: %w", ctx.Err())
+	}
+}
+
+// Statuses returns a snapshot of every registered job
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		status := JobStatus{
+			Name:     state.job.Name,
+			Interval: state.job.Interval,
+			Running:  state.running.Load(),
+		}
+		if ts := state.lastSuccess.Load(); ts > 0 {
+			status.LastSuccess = time.Unix(0, ts)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// StartedAt returns when the scheduler was started (zero if not started)
+func (s *Scheduler) StartedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startedAt
+}
+
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	defer s.loops.Done()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(s.nextDelay(state.job)):
+		}
+
+		// WHY: Skip the tick instead of queueing when the previous run is still in flight
+		if !state.running.CompareAndSwap(false, true) {
+			s.metrics.runs.WithLabelValues(state.job.Name, resultSkipped).Inc()
+			s.logger.Warn("Skipping job run, previous run still in progress", "job", state.job.Name)
+			continue
+		}
+
+		s.runs.Add(1)
+		go func() {
+			defer s.runs.Done()
+			defer state.running.Store(false)
+			s.execute(ctx, state)
+		}()
+	}
+}
+
+func (s *Scheduler) nextDelay(job Job) time.Duration {
+	if job.Jitter <= 0 {
+		return job.Interval
+	}
+	return job.Interval + time.Duration(rand.Int64N(int64(job.Jitter)))
+}
+
+func (s *Scheduler) execute(ctx context.Context, state *jobState) {
+	name := state.job.Name
+	start := s.clock.Now()
+
+	result := resultSuccess
+	err := s.safeRun(ctx, state.job)
+	if err != nil {
+		result = resultError
+		var pe *panicError
+		if errors.As(err, &pe) {
+			result = resultPanic
+		}
+		s.logger.Error("Job run failed", "job", name, "result", result, "error", err)
+	} else {
+		now := s.clock.Now()
+		state.lastSuccess.Store(now.UnixNano())
+		s.metrics.lastSuccess.WithLabelValues(name).Set(float64(now.Unix()))
+	}
+
+	s.metrics.runs.WithLabelValues(name, result).Inc()
+	s.metrics.duration.WithLabelValues(name).Observe(s.clock.Now().Sub(start).Seconds())
+}
+
+type panicError struct {
+	value any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func (s *Scheduler) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,392 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/health"
+)
+
+// fakeClock is a manually advanced Clock for deterministic scheduling tests
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires every waiter whose deadline has passed
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if !w.deadline.After(f.now) {
+			w.ch <- f.now
+		} else {
+			remaining = append(remaining, w)
+		}
+	}
+	f.waiters = remaining
+}
+
+// BlockUntil waits until n goroutines are waiting on the clock
+func (f *fakeClock) BlockUntil(t *testing.T, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return len(f.waiters) >= n
+	}, time.Second, time.Millisecond)
+}
+
+func newTestScheduler(clock Clock) (*Scheduler, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	return New(Config{Clock: clock, Registerer: registry}), registry
+}
+
+func expectRun(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("expected job to run")
+	}
+}
+
+func expectNoRun(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+		t.Fatal("job ran too early")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestScheduler_Add_Validation(t *testing.T) {
+	s, _ := newTestScheduler(newFakeClock())
+	noop := func(context.Context) error { return nil }
+
+	assert.ErrorIs(t, s.Add(Job{Interval: time.Second, Run: noop}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Add(Job{Name: "job", Run: noop}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Add(Job{Name: "job", Interval: time.Second}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Add(Job{Name: "job", Interval: time.Second, Jitter: -1, Run: noop}), ErrInvalidJob)
+
+	require.NoError(t, s.Add(Job{Name: "job", Interval: time.Second, Run: noop}))
+	assert.ErrorIs(t, s.Add(Job{Name: "job", Interval: time.Second, Run: noop}), ErrDuplicateJob)
+
+	require.NoError(t, s.Start(context.Background()))
+	assert.ErrorIs(t, s.Add(Job{Name: "late", Interval: time.Second, Run: noop}), ErrAlreadyStarted)
+	assert.ErrorIs(t, s.Start(context.Background()), ErrAlreadyStarted)
+	require.NoError(t, s.Stop(context.Background()))
+}
+
+func TestScheduler_RunsOnInterval(t *testing.T) {
+	clock := newFakeClock()
+	s, registry := newTestScheduler(clock)
+
+	ran := make(chan struct{}, 10)
+	require.NoError(t, s.Add(Job{
+		Name:     "interval",
+		Interval: 10 * time.Second,
+		Run: func(context.Context) error {
+			ran <- struct{}{}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(9 * time.Second)
+	expectNoRun(t, ran)
+
+	clock.Advance(time.Second)
+	expectRun(t, ran)
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, ran)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(s.metrics.runs.WithLabelValues("interval", resultSuccess)) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, float64(clock.Now().Unix()), testutil.ToFloat64(s.metrics.lastSuccess.WithLabelValues("interval")))
+
+	count, err := testutil.GatherAndCount(registry, "scheduler_job_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestScheduler_JitterDelaysWithinBounds(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	ran := make(chan struct{}, 10)
+	require.NoError(t, s.Add(Job{
+		Name:     "jittered",
+		Interval: 10 * time.Second,
+		Jitter:   5 * time.Second,
+		Run: func(context.Context) error {
+			ran <- struct{}{}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(10*time.Second - time.Millisecond)
+	expectNoRun(t, ran)
+
+	clock.Advance(5 * time.Second)
+	expectRun(t, ran)
+}
+
+func TestScheduler_PanicIsolation(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	goodRan := make(chan struct{}, 10)
+	badRan := make(chan struct{}, 10)
+	require.NoError(t, s.Add(Job{
+		Name:     "bad",
+		Interval: time.Second,
+		Run: func(context.Context) error {
+			badRan <- struct{}{}
+			panic("boom")
+		},
+	}))
+	require.NoError(t, s.Add(Job{
+		Name:     "good",
+		Interval: time.Second,
+		Run: func(context.Context) error {
+			goodRan <- struct{}{}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(t, 2)
+		clock.Advance(time.Second)
+		expectRun(t, badRan)
+		expectRun(t, goodRan)
+	}
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(s.metrics.runs.WithLabelValues("bad", resultPanic)) == 2 &&
+			testutil.ToFloat64(s.metrics.runs.WithLabelValues("good", resultSuccess)) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestScheduler_ErrorResultRecorded(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	require.NoError(t, s.Add(Job{
+		Name:     "failing",
+		Interval: time.Second,
+		Run:      func(context.Context) error { return errors.New("db down") },
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(s.metrics.runs.WithLabelValues("failing", resultError)) == 1
+	}, time.Second, time.Millisecond)
+	assert.True(t, s.Statuses()[0].LastSuccess.IsZero())
+}
+
+func TestScheduler_PreventsOverlappingRuns(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:     "slow",
+		Interval: time.Second,
+		Run: func(context.Context) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	expectRun(t, started)
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(s.metrics.runs.WithLabelValues("slow", resultSkipped)) == 1
+	}, time.Second, time.Millisecond)
+	expectNoRun(t, started)
+
+	close(release)
+	require.NoError(t, s.Stop(context.Background()))
+}
+
+func TestScheduler_StopWaitsForInFlightRuns(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:     "inflight",
+		Interval: time.Second,
+		Run: func(context.Context) error {
+			started <- struct{}{}
+			<-release
+			close(finished)
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	expectRun(t, started)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before in-flight run finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after in-flight run finished")
+	}
+	<-finished
+}
+
+func TestScheduler_StopTimeoutCancelsRuns(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{})
+	require.NoError(t, s.Add(Job{
+		Name:     "stuck",
+		Interval: time.Second,
+		Run: func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	expectRun(t, started)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := s.Stop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected in-flight run to be cancelled")
+	}
+}
+
+func TestChecker_ReportsStaleJobs(t *testing.T) {
+	clock := newFakeClock()
+	s, _ := newTestScheduler(clock)
+	checker := NewChecker(s)
+
+	assert.Equal(t, "scheduler", checker.Name())
+	assert.Equal(t, health.CheckWarn, checker.Check(context.Background()).Status)
+
+	fail := make(chan bool, 10)
+	require.NoError(t, s.Add(Job{
+		Name:     "cleanup",
+		Interval: time.Minute,
+		Run: func(context.Context) error {
+			if <-fail {
+				return errors.New("failed")
+			}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	assert.Equal(t, health.CheckPass, checker.Check(context.Background()).Status)
+
+	for i := 0; i < 3; i++ {
+		fail <- true
+		clock.BlockUntil(t, 1)
+		clock.Advance(time.Minute)
+	}
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+
+	require.Eventually(t, func() bool {
+		return checker.Check(context.Background()).Status == health.CheckWarn
+	}, time.Second, time.Millisecond)
+	result := checker.Check(context.Background())
+	assert.Contains(t, result.Details, "cleanup")
+
+	fail <- false
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Minute)
+
+	require.Eventually(t, func() bool {
+		return checker.Check(context.Background()).Status == health.CheckPass
+	}, time.Second, time.Millisecond)
+}
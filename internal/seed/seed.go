@@ -0,0 +1,215 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/hash"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+// EmailDomain is the domain used for every seeded account
+const EmailDomain = "example.dev"
+
+// ErrProductionEnvironment is returned when seeding is attempted against production without force
+var ErrProductionEnvironment = errors.New("refusing to seed a production environment without --force")
+
+// sessionNamespace derives stable token family IDs so re-runs find the same sessions
+var sessionNamespace = uuid.MustParse("6f1c1f0e-2f8e-4c9a-9d3b-5b1e7c2a9e40")
+
+var (
+	firstNames = []string{"Ava", "Liam", "Mia", "Noah", "Zoe", "Ethan", "Leila", "Omar", "Sara", "Lucas", "Nora", "Arash", "Emma", "Kai", "Yara", "Mateo"}
+	lastNames  = []string{"Smith", "Garcia", "Chen", "Novak", "Rahimi", "Okafor", "Müller", "Silva", "Kim", "Rossi", "Haddad", "Larsen", "Patel", "Ivanova"}
+)
+
+// Options controls how much data is generated
+type Options struct {
+	Users           int    // number of users to ensure exist
+	Admins          int    // how many of the first users get the admin role
+	SessionsPerUser int    // refresh-token sessions to ensure per user
+	Seed            int64  // random seed; the same seed always yields the same data
+	Password        string // plain-text password shared by all seeded users
+}
+
+// DefaultOptions returns options suitable for local development
+func DefaultOptions() Options {
+	return Options{
+		Users:           25,
+		Admins:          2,
+		SessionsPerUser: 2,
+		Seed:            42,
+		Password:        "password123",
+	}
+}
+
+// Summary reports what a run created versus what already existed
+type Summary struct {
+	UsersCreated        int
+	UsersExisting       int
+	AdminsAssigned      int
+	SessionsCreated     int
+	AuditEntriesCreated int
+}
+
+// String renders the summary for CLI output
+func (s Summary) String() string {
+	return fmt.Sprintf(
+		"users created: %d, users already present: %d, admins assigned: %d, sessions created: %d, audit entries created: %d",
+		s.UsersCreated, s.UsersExisting, s.AdminsAssigned, s.SessionsCreated, s.AuditEntriesCreated,
+	)
+}
+
+// CheckEnvironment refuses to seed production unless force is set
+func CheckEnvironment(environment string, force bool) error {
+	if environment == "production" && !force {
+		return ErrProductionEnvironment
+	}
+	return nil
+}
+
+// Email returns the fixed address of the n-th seeded user (1-based)
+func Email(n int) string {
+	return fmt.Sprintf("user%03d@%s", n, EmailDomain)
+}
+
+// Run idempotently populates the database. Users are keyed by their fixed email,
+// sessions by a deterministic token hash and audit entries are only written when
+// an admin role is newly granted, so running twice never duplicates rows.
+func Run(ctx context.Context, db *gorm.DB, opts Options) (*Summary, error) {
+	if opts.Users < 0 || opts.Admins < 0 || opts.SessionsPerUser < 0 {
+		return nil, fmt.Errorf("seed counts must be non-negative")
+	}
+	if opts.Admins > opts.Users {
+		return nil, fmt.Errorf("admins (%d) cannot exceed users (%d)", opts.Admins, opts.Users)
+	}
+
+	// WHY: Password hashing is deliberately slow; hashing once keeps large datasets fast to build
+	passwordHash, err := hash.Default().Hash(opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userRepo := user.NewRepository(db)
+	tokenRepo := auth.NewRefreshTokenRepository(db)
+	auditRepo := audit.NewRepository(db)
+	rng := rand.New(rand.NewSource(opts.Seed))
+	now := time.Now().UTC()
+
+	summary := &Summary{}
+	var firstAdminID uint
+
+	for n := 1; n <= opts.Users; n++ {
+		// Draw names even for existing users so the sequence stays stable across runs
+		name := firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))]
+
+		u, created, err := ensureUser(ctx, userRepo, Email(n), name, passwordHash)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			summary.UsersCreated++
+		} else {
+			summary.UsersExisting++
+		}
+
+		if n <= opts.Admins {
+			if !u.IsAdmin() {
+				if err := userRepo.AssignRole(ctx, u.ID, user.RoleAdmin); err != nil {
+					return nil, fmt.Errorf("failed to assign admin role to %s: %w", u.Email, err)
+				}
+				summary.AdminsAssigned++
+
+				actorID := firstAdminID
+				if actorID == 0 {
+					actorID = u.ID
+				}
+				targetID := u.ID
+				if err := auditRepo.Create(ctx, &audit.Entry{
+					ActorID:   actorID,
+					Action:    audit.ActionUserPromote,
+					TargetID:  &targetID,
+					Metadata:  map[string]any{"role": user.RoleAdmin, "source": "seed"},
+					CreatedAt: now,
+				}); err != nil {
+					return nil, fmt.Errorf("failed to write audit entry: %w", err)
+				}
+				summary.AuditEntriesCreated++
+			}
+			if firstAdminID == 0 {
+				firstAdminID = u.ID
+			}
+		}
+
+		for s := 1; s <= opts.SessionsPerUser; s++ {
+			created, err := ensureSession(ctx, tokenRepo, u.ID, opts.Seed, n, s, now)
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				summary.SessionsCreated++
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+func ensureUser(ctx context.Context, repo user.Repository, email, name, passwordHash string) (*user.User, bool, error) {
+	existing, err := repo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to look up %s: %w", email, err)
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	u := &user.User{Name: name, Email: email, PasswordHash: passwordHash}
+	err = repo.Transaction(ctx, func(txCtx context.Context) error {
+		if err := repo.Create(txCtx, u); err != nil {
+			return fmt.Errorf("failed to create %s: %w", email, err)
+		}
+		if err := repo.AssignRole(txCtx, u.ID, user.RoleUser); err != nil {
+			return fmt.Errorf("failed to assign default role to %s: %w", email, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	u, err = repo.FindByID(ctx, u.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reload %s: %w", email, err)
+	}
+	return u, true, nil
+}
+
+func ensureSession(ctx context.Context, repo auth.RefreshTokenRepository, userID uint, seed int64, userN, sessionN int, now time.Time) (bool, error) {
+	key := fmt.Sprintf("seed-%d-user-%d-session-%d", seed, userN, sessionN)
+	tokenHash := auth.HashToken(key)
+
+	if _, err := repo.FindByTokenHash(ctx, tokenHash); err == nil {
+		return false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to look up session: %w", err)
+	}
+
+	token := &auth.RefreshToken{
+		UserID:      userID,
+		TokenHash:   tokenHash,
+		TokenFamily: uuid.NewSHA1(sessionNamespace, []byte(key)),
+		ExpiresAt:   now.Add(7 * 24 * time.Hour),
+	}
+	if err := repo.Create(ctx, token); err != nil {
+		return false, fmt.Errorf("failed to create session: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,38 @@
+package seed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		force       bool
+		expectErr   bool
+	}{
+		{name: "development allowed", environment: "development"},
+		{name: "empty environment allowed", environment: ""},
+		{name: "production refused", environment: "production", expectErr: true},
+		{name: "production allowed with force", environment: "production", force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckEnvironment(tt.environment, tt.force)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrProductionEnvironment)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	assert.Equal(t, "user001@example.dev", Email(1))
+	assert.Equal(t, "user042@example.dev", Email(42))
+	assert.Equal(t, "user1234@example.dev", Email(1234))
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// getAndHead is used in place of GET because Gin does not answer HEAD for GET routes
+var getAndHead = []string{http.MethodGet, http.MethodHead}
+
+// methodOrder is the order methods are listed in the Allow header
+var methodOrder = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// registerFallbacks makes unknown routes and unsupported methods answer with
+// the standard error response. Both run through the global middleware, so
+// they are logged and counted like any other request.
+func registerFallbacks(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(noRoute)
+	router.NoMethod(noMethod(router))
+}
+
+func noRoute(c *gin.Context) {
+	_ = c.Error(errors.NotFound(fmt.Sprintf("No route matches %s %s", c.Request.Method, c.Request.URL.Path)))
+}
+
+func noMethod(router *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := allowedMethods(router.Routes(), c.Request.URL.Path)
+		c.Header("Allow", strings.Join(allowed, ", "))
+		_ = c.Error(errors.MethodNotAllowed(
+			fmt.Sprintf("Method %s is not allowed for %s", c.Request.Method, c.Request.URL.Path),
+			allowed,
+		))
+	}
+}
+
+// allowedMethods lists the methods of every route whose path template matches path
+func allowedMethods(routes gin.RoutesInfo, path string) []string {
+	var allowed []string
+	for _, route := range routes {
+		if !slices.Contains(allowed, route.Method) && matchesTemplate(route.Path, path) {
+			allowed = append(allowed, route.Method)
+		}
+	}
+	slices.SortFunc(allowed, func(a, b string) int {
+		return methodRank(a) - methodRank(b)
+	})
+	return allowed
+}
+
+func methodRank(method string) int {
+	if i := slices.Index(methodOrder, method); i >= 0 {
+		return i
+	}
+	return len(methodOrder)
+}
+
+// matchesTemplate reports whether path matches a Gin route template with
+// :param and *catchAll segments
+func matchesTemplate(template, path string) bool {
+	tmplSegs := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range tmplSegs {
+		if strings.HasPrefix(seg, "*") {
+			return true
+		}
+		if i >= len(pathSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") {
+			if pathSegs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return len(tmplSegs) == len(pathSegs)
+}
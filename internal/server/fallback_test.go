@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func TestSetupRouter_Fallbacks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	mockAuthService := auth.NewService(&config.JWTConfig{Secret: "test-secret", TTLHours: 24})
+
+	testConfig := &config.Config{
+		App:    config.AppConfig{Version: "1.0.0", Environment: "test"},
+		Health: config.HealthConfig{Timeout: 5},
+		CORS:   config.CORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+	}
+
+	router := SetupRouter(&user.Handler{}, mockAuthService, testConfig, db)
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) errors.Response {
+		t.Helper()
+		var resp errors.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Error)
+		return resp
+	}
+
+	t.Run("wrong method returns 405 with Allow header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/health", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+
+		resp := decode(t, w)
+		assert.False(t, resp.Success)
+		assert.Equal(t, errors.CodeMethodNotAllowed, resp.Error.Code)
+		assert.Equal(t, "/health", resp.Error.Path)
+	})
+
+	t.Run("Allow lists every method of a parameterized route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/42", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, HEAD, PUT, PATCH, DELETE", w.Header().Get("Allow"))
+	})
+
+	t.Run("GET on a POST-only route returns 405", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/login", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "POST", w.Header().Get("Allow"))
+	})
+
+	t.Run("unknown route returns 404 JSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/nope", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Allow"))
+
+		resp := decode(t, w)
+		assert.Equal(t, errors.CodeNotFound, resp.Error.Code)
+		assert.Equal(t, "No route matches GET /api/v1/nope", resp.Error.Message)
+		assert.Equal(t, "/api/v1/nope", resp.Error.Path)
+	})
+
+	t.Run("HEAD is served by GET routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodHead, "/health/live", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("CORS preflight still returns 204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/api/v1/auth/login", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Allow"))
+	})
+}
+
+func TestMatchesTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		want     bool
+	}{
+		{"/health", "/health", true},
+		{"/health", "/health/live", false},
+		{"/api/v1/users/:id", "/api/v1/users/7", true},
+		{"/api/v1/users/:id", "/api/v1/users", false},
+		{"/api/v1/users/:id", "/api/v1/users/7/promote", false},
+		{"/swagger/*any", "/swagger/index.html", true},
+		{"/api/v1/users/me/identities", "/api/v1/users/me/identities/", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchesTemplate(tt.template, tt.path), "%s vs %s", tt.template, tt.path)
+	}
+}
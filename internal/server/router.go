@@ -1,113 +1,420 @@
 package server
 
 import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth/oauth"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/featureflags"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/graphql"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/health"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/maintenance"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/notification"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/realtime"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
+// exposedHeaders are always readable by browser clients so they can
+// correlate requests and back off when rate limited
+var exposedHeaders = []string{
+	"X-Request-ID",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Warning",
+	"Retry-After",
+	"X-Response-Time",
+}
+
+// newCORSConfig builds the CORS middleware configuration, layering the
+// configured values over the library defaults
+func newCORSConfig(cfg config.CORSConfig) cors.Config {
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization")
+	corsConfig.AllowHeaders = appendUnique(corsConfig.AllowHeaders, cfg.AllowHeaders...)
+	corsConfig.ExposeHeaders = appendUnique(append([]string{}, exposedHeaders...), cfg.ExposeHeaders...)
+	corsConfig.AllowCredentials = cfg.AllowCredentials
+
+	if cfg.AllowsAllOrigins() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowOrigins
+	}
+	if len(cfg.AllowMethods) > 0 {
+		corsConfig.AllowMethods = cfg.AllowMethods
+	}
+	if cfg.MaxAge > 0 {
+		corsConfig.MaxAge = cfg.MaxAge
+	}
+
+	return corsConfig
+}
+
+func appendUnique(values []string, extra ...string) []string {
+	for _, v := range extra {
+		if !slices.ContainsFunc(values, func(existing string) bool { return strings.EqualFold(existing, v) }) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// RouterOption configures optional SetupRouter dependencies
+type RouterOption func(*routerOptions)
+
+type routerOptions struct {
+	checkers        []health.Checker
+	maintenanceMode *maintenance.Mode
+}
+
+// WithHealthCheckers includes extra checkers (e.g. for background jobs) in the readiness probe
+func WithHealthCheckers(checkers ...health.Checker) RouterOption {
+	return func(o *routerOptions) {
+		o.checkers = append(o.checkers, checkers...)
+	}
+}
+
+// WithMaintenanceMode makes the router use mode instead of one built from
+// cfg.Maintenance, so the caller can reload it
+func WithMaintenanceMode(mode *maintenance.Mode) RouterOption {
+	return func(o *routerOptions) {
+		o.maintenanceMode = mode
+	}
+}
+
 // SetupRouter creates and configures the Gin router
-func SetupRouter(userHandler *user.Handler, authService auth.Service, cfg *config.Config, db *gorm.DB) *gin.Engine {
+func SetupRouter(userHandler *user.Handler, authService auth.Service, cfg *config.Config, db *gorm.DB, opts ...RouterOption) *gin.Engine {
+	var options routerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	router := gin.New()
 
+	// WHY: Gin trusts X-Forwarded-For from every peer by default, which lets
+	// clients spoof their IP and evade rate limiting
+	if cfg.Server.BehindProxy {
+		_ = router.SetTrustedProxies(cfg.Server.TrustedProxies)
+	} else {
+		_ = router.SetTrustedProxies(nil)
+	}
+
 	if cfg.App.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	} else {
 		gin.SetMode(gin.DebugMode)
 	}
 
+	if cfg.Tracing.Enabled {
+		// WHY: Outermost, so the request log line and error bodies see the request span
+		router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	}
+
 	skipPaths := config.GetSkipPaths(cfg.App.Environment)
-	loggerConfig := middleware.NewLoggerConfig(
-		cfg.Logging.GetLogLevel(),
-		skipPaths,
-	)
+	// WHY: The server installs the logger built from cfg.Logging as the default,
+	// so request logs share the format and output of the startup logs
+	loggerConfig := &middleware.LoggerConfig{
+		SkipPaths: skipPaths,
+		Logger:    slog.Default(),
+	}
+	router.Use(middleware.ResponseHeaders(middleware.ResponseHeadersConfig{
+		Server:       cfg.Server.ServerHeader,
+		ResponseTime: cfg.Server.ResponseTimeHeader,
+	}))
+	router.Use(middleware.NoStore())
 	router.Use(middleware.Logger(loggerConfig))
 	router.Use(errors.ErrorHandler())
+	router.Use(errors.ResponseFormat(cfg.Server.ResponseFormat))
+	router.Use(errors.JSONDecoding(errors.JSONDecodingConfig{
+		Strict:   cfg.Server.StrictJSON,
+		MaxBytes: cfg.Server.MaxJSONBytes,
+		MaxDepth: cfg.Server.MaxJSONDepth,
+	}))
 	router.Use(gin.Recovery())
 
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization")
-	router.Use(cors.New(corsConfig))
+	router.Use(cors.New(newCORSConfig(cfg.CORS)))
+
+	metricsRecorder, metricsMiddleware := middleware.NewMetricsMiddleware(middleware.MetricsConfig{
+		Namespace:       cfg.Metrics.Namespace,
+		DurationBuckets: cfg.Metrics.DurationBuckets,
+		SizeBuckets:     cfg.Metrics.SizeBuckets,
+	})
+	router.Use(metricsMiddleware)
+
+	inFlightMetrics := middleware.NewInFlightMetrics(middleware.MetricsConfig{Namespace: cfg.Metrics.Namespace})
+	if cfg.Server.MaxInflight > 0 {
+		// WHY: Probes and scrapes must answer while shedding; the streams stay open for as long as a client watches
+		router.Use(middleware.MaxInFlight(cfg.Server.MaxInflight, inFlightMetrics,
+			"/health", "/health/live", "/health/ready", "/metrics",
+			"/api/v1/events", "/api/v1/admin/events/stream", "/api/v1/ws",
+		))
+	}
+	var perUserInFlight []gin.HandlerFunc
+	if cfg.Server.MaxInflightPerUser > 0 {
+		perUserInFlight = append(perUserInFlight, middleware.MaxInFlightPerUser(cfg.Server.MaxInflightPerUser, inFlightMetrics))
+	}
 
 	var checkers []health.Checker
 	if cfg.Health.DatabaseCheckEnabled {
 		dbChecker := health.NewDatabaseChecker(db)
 		checkers = append(checkers, dbChecker)
 	}
-	healthService := health.NewService(checkers, cfg.App.Version, cfg.App.Environment)
-	healthHandler := health.NewHandler(healthService)
+	checkers = append(checkers, options.checkers...)
+	healthService := health.NewService(checkers, cfg.App.Version, cfg.App.Environment,
+		health.WithTimeout(time.Duration(cfg.Health.Timeout)*time.Second),
+		health.WithCheckerTimeouts(cfg.Health.CheckerTimeouts),
+	)
+	healthHandler := health.NewHandler(healthService, health.WithStreamInterval(cfg.Health.StreamInterval))
+
+	router.Match(getAndHead, "/health", healthHandler.Health)
+	router.Match(getAndHead, "/health/live", healthHandler.Live)
+	router.Match(getAndHead, "/health/ready", healthHandler.Ready)
+
+	router.Match(getAndHead, "/metrics", middleware.RequireBearerToken(cfg.Metrics.Token), gin.WrapH(metricsRecorder.Handler()))
+	if cfg.Server.VersionAdminOnly {
+		router.Match(getAndHead, "/version", auth.AuthMiddleware(authService), middleware.RequireAdmin(), version.Handler)
+	} else {
+		router.Match(getAndHead, "/version", version.Handler)
+	}
+	if cfg.Server.SwaggerEnabled {
+		router.Match(getAndHead, "/swagger/*any", middleware.CacheControl(cfg.Server.StaticCacheMaxAge), ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	auditHandler := audit.NewHandler(audit.NewRepository(db))
+	notificationHandler := notification.NewHandler(notification.NewService(notification.NewRepository(db)))
 
-	router.GET("/health", healthHandler.Health)
-	router.GET("/health/live", healthHandler.Live)
-	router.GET("/health/ready", healthHandler.Ready)
+	maintenanceMode := options.maintenanceMode
+	if maintenanceMode == nil {
+		maintenanceMode = maintenance.NewMode(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfter)
+	}
+	maintenanceHandler := maintenance.NewHandler(maintenanceMode)
+	// WHY: Admins must be able to switch maintenance off while it is on, which
+	// takes a token they can still get once the one they had has expired
+	router.Use(maintenance.Middleware(maintenanceMode, "/api/v1/admin/maintenance", "/api/v1/auth/login", "/api/v1/auth/refresh"))
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if cfg.Tenant.Enabled {
+		// WHY: Before any middleware that reads the token, which must match the tenant of the request
+		router.Use(middleware.TenantMiddleware(cfg.Tenant.BaseDomain))
+	}
 
 	rlCfg := cfg.Ratelimit
+	var rlOpts []middleware.RateLimitOption
+	if rlCfg.OmitLegacyHeaders {
+		rlOpts = append(rlOpts, middleware.WithoutLegacyHeaders())
+	}
+	if rlCfg.WarningThreshold > 0 {
+		rlOpts = append(rlOpts, middleware.WithWarningThreshold(rlCfg.WarningThreshold))
+	}
+	if rlCfg.OverflowRequests > 0 {
+		rlOpts = append(rlOpts, middleware.WithOverflowRequests(rlCfg.OverflowRequests))
+	}
+	if len(rlCfg.ExemptIPs) > 0 {
+		rlOpts = append(rlOpts, middleware.WithExemption(middleware.ExemptIPs(rlCfg.ExemptIPs...)))
+	}
+	if len(rlCfg.ExemptRoles) > 0 {
+		rlOpts = append(rlOpts, middleware.WithExemption(middleware.ExemptRoles(rlCfg.ExemptRoles...)))
+	}
+	rlMetrics := middleware.NewRateLimitMetrics(middleware.MetricsConfig{Namespace: cfg.Metrics.Namespace})
+	// rlStore builds the options for a limiter store reported under name
+	rlStore := func(name string) middleware.RateLimitOption {
+		return middleware.WithStoreOptions(
+			middleware.WithCacheSize(rlCfg.CacheSize),
+			middleware.WithEntryTTL(rlCfg.EntryTTL),
+			middleware.WithOverflowPolicy(middleware.OverflowPolicy(rlCfg.OverflowPolicy)),
+			middleware.WithStoreMetrics(rlMetrics, name),
+		)
+	}
 	if rlCfg.Enabled {
+		if len(rlCfg.ExemptRoles) > 0 {
+			// WHY: The IP limiter runs before route auth, so it needs the roles from the token itself
+			router.Use(auth.OptionalAuthMiddleware(authService))
+		}
 		router.Use(
 			middleware.NewRateLimitMiddleware(
 				rlCfg.Window,
 				rlCfg.Requests,
-				func(c *gin.Context) string {
-					ip := c.ClientIP()
-					if ip == "" {
-						ip = c.GetHeader("X-Forwarded-For")
-						if ip == "" {
-							ip = c.GetHeader("X-Real-IP")
-						}
-						if ip == "" {
-							ip = "unknown"
-						}
-					}
-					return ip
-				},
+				middleware.IPKey,
 				nil,
+				append(slices.Clone(rlOpts), rlStore("ip"))...,
 			),
 		)
 	}
 
+	var requestTx []gin.HandlerFunc
+	if cfg.Database.RequestTransactions {
+		// WHY: Only groups whose endpoints write several rows at once; read-only groups go without
+		requestTx = append(requestTx, middleware.Transaction(db))
+	}
+
 	v1 := router.Group("/api/v1")
 	{
-		authGroup := v1.Group("/auth")
+		authGroup := v1.Group("/auth", requestTx...)
 		{
 			authGroup.POST("/register", userHandler.Register)
 			authGroup.POST("/login", userHandler.Login)
 			authGroup.POST("/refresh", userHandler.RefreshToken)
 			authGroup.POST("/logout", auth.AuthMiddleware(authService), userHandler.Logout)
-			authGroup.GET("/me", auth.AuthMiddleware(authService), userHandler.GetMe)
+			authGroup.Match(getAndHead, "/me", auth.AuthMiddleware(authService), userHandler.GetTokenClaims)
+		}
+
+		var emailCheckLimit []gin.HandlerFunc
+		if rlCfg.Enabled {
+			// WHY: Unauthenticated and answers whether an account exists, so each IP gets a far smaller budget than the rest of the API
+			emailCheckLimit = append(emailCheckLimit, middleware.NewRateLimitMiddleware(
+				rlCfg.EmailCheckPeriod(),
+				rlCfg.EmailCheckLimit(),
+				middleware.IPKey,
+				nil,
+				append(slices.Clone(rlOpts), rlStore("email_check"))...,
+			))
+		}
+		v1.Match(getAndHead, "/auth/email-available", append(emailCheckLimit, userHandler.EmailAvailable)...)
+		v1.Match(getAndHead, "/errors", middleware.CacheControl(cfg.Server.StaticCacheMaxAge), errors.CatalogHandler)
+
+		// WHY: Links in emails must work without logging in, so the signature authorizes the request
+		// WHY: GET only confirms, since link scanners and prefetchers follow links; the POST unsubscribes
+		unsubscribeLink := auth.SignedURLMiddleware(auth.NewURLSigner(cfg.JWT.Secret, notification.LinkTTL(cfg.Email.UnsubscribeTTL)))
+		v1.GET("/notifications/unsubscribe", unsubscribeLink, notificationHandler.ConfirmUnsubscribe)
+		v1.POST("/notifications/unsubscribe", unsubscribeLink, notificationHandler.Unsubscribe)
+
+		// File downloads - authorized by a signed URL from /users/me/download-urls, so browsers can fetch them without an Authorization header
+		filesGroup := v1.Group("/files")
+		filesGroup.Use(auth.SignedURLMiddleware(auth.NewURLSigner(cfg.JWT.Secret, user.DownloadURLTTL)))
+		{
+			filesGroup.Match(getAndHead, "/export", userHandler.ExportAccount)
 		}
 
 		// User endpoints - authenticated users can access their own resources
 		usersGroup := v1.Group("/users")
 		usersGroup.Use(auth.AuthMiddleware(authService))
+		if rlCfg.Enabled {
+			// WHY: Users behind a shared NAT get their own bucket instead of sharing the IP's
+			usersGroup.Use(middleware.NewRateLimitMiddleware(
+				rlCfg.PerUserWindow(),
+				rlCfg.PerUserRequests(),
+				middleware.UserOrIPKey,
+				nil,
+				append(slices.Clone(rlOpts), rlStore("user"))...,
+			))
+		}
+		usersGroup.Use(perUserInFlight...)
 		{
-			usersGroup.GET("/:id", userHandler.GetUser)
+			usersGroup.Match(getAndHead, "/me", userHandler.GetMe)
+			usersGroup.POST("/me/download-urls", userHandler.CreateDownloadURL)
+			usersGroup.POST("/me/confirm-email-change", userHandler.ConfirmEmailChange)
+			usersGroup.DELETE("/me/email-change", userHandler.CancelEmailChange)
+			usersGroup.Match(getAndHead, "/me/notifications", notificationHandler.GetSettings)
+			usersGroup.PUT("/me/notifications", notificationHandler.UpdateSettings)
+			usersGroup.Match(getAndHead, "/:id", userHandler.GetUser)
 			usersGroup.PUT("/:id", userHandler.UpdateUser)
+			usersGroup.PATCH("/:id", userHandler.PatchUser)
 			usersGroup.DELETE("/:id", userHandler.DeleteUser)
 		}
 
 		// Admin endpoints - admin role required, following REST best practices
 		adminGroup := v1.Group("/admin")
 		adminGroup.Use(auth.AuthMiddleware(authService), middleware.RequireAdmin())
+		adminGroup.Use(perUserInFlight...)
+		adminGroup.Use(requestTx...)
 		{
 			// User management endpoints
-			adminGroup.GET("/users", userHandler.ListUsers)
-			adminGroup.GET("/users/:id", userHandler.GetUser)
-			adminGroup.PUT("/users/:id", userHandler.UpdateUser)
+			adminGroup.Match(getAndHead, "/users", userHandler.ListUsers)
+			adminGroup.Match(getAndHead, "/stats", userHandler.GetUserStats)
+			adminGroup.Match(getAndHead, "/users/:id", userHandler.GetAdminUser)
+			adminGroup.PUT("/users/:id", userHandler.AdminUpdateUser)
+			adminGroup.PATCH("/users/:id", userHandler.PatchUser)
 			adminGroup.DELETE("/users/:id", userHandler.DeleteUser)
+			adminGroup.POST("/users/:id/restore", userHandler.RestoreUser)
+			adminGroup.POST("/users/:id/revoke-sessions", userHandler.RevokeUserSessions)
+			adminGroup.POST("/users/:id/revoke-tokens", userHandler.RevokeUserSessions)
+			adminGroup.Match(getAndHead, "/users/:id/tokens", userHandler.ListUserTokens)
+			adminGroup.DELETE("/users/:id/tokens", userHandler.RevokeUserSessions)
+			adminGroup.POST("/users/:id/promote", userHandler.PromoteUser)
+			adminGroup.POST("/users/:id/deactivate", userHandler.DeactivateUser)
+			adminGroup.POST("/users/:id/reactivate", userHandler.ReactivateUser)
+
+			// Bulk role endpoints
+			adminGroup.POST("/roles/:role/assign", userHandler.AssignRole)
+			adminGroup.POST("/roles/:role/remove", userHandler.RemoveRole)
+
+			// Audit log endpoints
+			adminGroup.Match(getAndHead, "/audit", auditHandler.List)
+
+			// Maintenance mode endpoints
+			adminGroup.Match(getAndHead, "/maintenance", maintenanceHandler.GetStatus)
+			adminGroup.PUT("/maintenance", maintenanceHandler.Update)
 		}
+
+		// Server-Sent Events for admin dashboards
+		v1.GET("/events", auth.AuthMiddleware(authService), middleware.RequireAdmin(), healthHandler.Stream)
 	}
 
+	registerFallbacks(router)
+
 	return router
 }
+
+// RegisterOAuthRoutes mounts the login and callback endpoints for an OAuth provider
+// under /api/v1/auth/oauth/{provider}, served while the oauth flag is on.
+func RegisterOAuthRoutes(router *gin.Engine, flags *featureflags.Flags, provider string, handler *oauth.Handler) {
+	oauthGroup := router.Group("/api/v1/auth/oauth/" + provider)
+	oauthGroup.Use(featureflags.Require(flags, featureflags.OAuth))
+	{
+		oauthGroup.Match(getAndHead, "/login", handler.Login)
+		oauthGroup.Match(getAndHead, "/callback", handler.Callback)
+	}
+}
+
+// RegisterGraphQLRoutes mounts the authenticated GraphQL endpoint at /graphql,
+// served while the graphql flag is on.
+func RegisterGraphQLRoutes(router *gin.Engine, flags *featureflags.Flags, authService auth.Service, handler *graphql.Handler) {
+	router.POST("/graphql", featureflags.Require(flags, featureflags.GraphQL), auth.AuthMiddleware(authService), handler.Serve)
+}
+
+// RegisterWebSocketRoutes mounts the real-time notifications endpoint at /api/v1/ws,
+// served while the websocket flag is on.
+// The handler authenticates the upgrade request itself so browsers can pass the token as a query parameter.
+func RegisterWebSocketRoutes(router *gin.Engine, flags *featureflags.Flags, handler *realtime.Handler) {
+	router.GET("/api/v1/ws", featureflags.Require(flags, featureflags.WebSocket), handler.Serve)
+}
+
+// RegisterAdminEventRoutes mounts the admin event stream at /api/v1/admin/events/stream.
+// The handler lifts server.writetimeout for its own connections, since the stream stays open.
+func RegisterAdminEventRoutes(router *gin.Engine, authService auth.Service, handler *realtime.StreamHandler) {
+	router.GET("/api/v1/admin/events/stream", auth.AuthMiddleware(authService), middleware.RequireAdmin(), handler.Serve)
+}
+
+// RegisterIdentityRoutes mounts the endpoints for managing the current user's linked OAuth identities,
+// served while the oauth flag is on.
+func RegisterIdentityRoutes(router *gin.Engine, flags *featureflags.Flags, authService auth.Service, handler *oauth.IdentityHandler) {
+	identitiesGroup := router.Group("/api/v1/users/me/identities")
+	identitiesGroup.Use(featureflags.Require(flags, featureflags.OAuth), auth.AuthMiddleware(authService))
+	{
+		identitiesGroup.Match(getAndHead, "", handler.List)
+		identitiesGroup.POST("", handler.Link)
+		identitiesGroup.DELETE("/:provider", handler.Unlink)
+	}
+}
+
+// RegisterFeatureFlagRoutes mounts the admin endpoint listing the feature flags at /api/v1/admin/flags.
+func RegisterFeatureFlagRoutes(router *gin.Engine, authService auth.Service, handler *featureflags.Handler) {
+	router.Match(getAndHead, "/api/v1/admin/flags", auth.AuthMiddleware(authService), middleware.RequireAdmin(), handler.List)
+}
@@ -1,18 +1,26 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	_ "github.com/vahiiiid/go-rest-api-boilerplate/api/docs"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/version"
 )
 
 func TestSetupRouter_HealthEndpoint(t *testing.T) {
@@ -59,3 +67,217 @@ func TestSetupRouter_HealthEndpoint(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "status")
 	assert.Contains(t, w.Body.String(), "healthy")
 }
+
+func TestSetupRouter_CORSPreflight(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	mockAuthService := auth.NewService(&config.JWTConfig{Secret: "test-secret", TTLHours: 24})
+
+	testConfig := &config.Config{
+		App: config.AppConfig{
+			Version:     "1.0.0",
+			Environment: "test",
+		},
+		Ratelimit: config.RateLimitConfig{
+			Enabled:  true,
+			Requests: 100,
+			Window:   time.Minute,
+		},
+		Health: config.HealthConfig{
+			Timeout: 5,
+		},
+		CORS: config.CORSConfig{
+			AllowOrigins:  []string{"https://app.example.com"},
+			AllowMethods:  []string{"GET", "PATCH"},
+			ExposeHeaders: []string{"X-Custom-Header"},
+			MaxAge:        2 * time.Hour,
+		},
+	}
+
+	router := SetupRouter(&user.Handler{}, mockAuthService, testConfig, db)
+
+	t.Run("preflight is cached and limited to configured methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/api/v1/users/1", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "PATCH")
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "7200", w.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, "GET,PATCH", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	})
+
+	t.Run("responses expose request id and rate limit headers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		exposed := strings.Split(strings.ToLower(w.Header().Get("Access-Control-Expose-Headers")), ",")
+		for _, header := range []string{"x-request-id", "x-ratelimit-limit", "x-ratelimit-remaining", "x-ratelimit-reset", "retry-after", "x-custom-header"} {
+			assert.Contains(t, exposed, header)
+		}
+	})
+
+	t.Run("unknown origin is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/api/v1/users/1", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "PATCH")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestSetupRouter_BuildInfo(t *testing.T) {
+	oldVersion, oldCommit, oldDate := version.Version, version.Commit, version.BuildDate
+	version.Version, version.Commit, version.BuildDate = "v9.8.7", "deadbee", "2026-01-02T03:04:05Z"
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildDate = oldVersion, oldCommit, oldDate
+	})
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	mockAuthService := auth.NewService(&config.JWTConfig{Secret: "test-secret", TTLHours: 24})
+
+	newConfig := func(adminOnly bool) *config.Config {
+		return &config.Config{
+			// main copies the linked version into the config before building the router
+			App:    config.AppConfig{Version: version.Version, Environment: "test"},
+			Server: config.ServerConfig{VersionAdminOnly: adminOnly},
+			Health: config.HealthConfig{Timeout: 5},
+		}
+	}
+	get := func(router http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	router := SetupRouter(&user.Handler{}, mockAuthService, newConfig(false), db)
+
+	t.Run("health reports the linked version", func(t *testing.T) {
+		w := get(router, "/health")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":"v9.8.7"`)
+	})
+
+	t.Run("version endpoint returns build info", func(t *testing.T) {
+		w := get(router, "/version")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"success":true,"data":{"version":"v9.8.7","commit":"deadbee","build_date":"2026-01-02T03:04:05Z"}}`, w.Body.String())
+	})
+
+	t.Run("metrics expose build_info", func(t *testing.T) {
+		w := get(router, "/metrics")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `build_info{build_date="2026-01-02T03:04:05Z",commit="deadbee",version="v9.8.7"} 1`)
+	})
+
+	t.Run("version endpoint can require admin", func(t *testing.T) {
+		adminRouter := SetupRouter(&user.Handler{}, mockAuthService, newConfig(true), db)
+		w := get(adminRouter, "/version")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestSetupRouter_SwaggerDocIncludesRoutes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	mockAuthService := auth.NewService(&config.JWTConfig{Secret: "test-secret", TTLHours: 24})
+	cfg := &config.Config{
+		App:    config.AppConfig{Environment: "test"},
+		Server: config.ServerConfig{SwaggerEnabled: true},
+		Health: config.HealthConfig{Timeout: 5},
+	}
+	router := SetupRouter(&user.Handler{}, mockAuthService, cfg, db)
+
+	w := httptest.NewRecorder()
+	// WHY: gin-swagger matches on RequestURI, which only httptest.NewRequest sets
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil))
+
+	// The spec is generated by `make swag` (CI runs it before the tests); the
+	// committed api/docs package is only a placeholder
+	if w.Code == http.StatusInternalServerError {
+		t.Skip("swagger docs not generated; run make swag")
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Failed to parse swagger doc: %v", err)
+	}
+
+	expected := map[string][]string{
+		"/api/v1/auth/me":                          {"get"},
+		"/api/v1/errors":                           {"get"},
+		"/api/v1/users/{id}":                       {"get", "put", "patch", "delete"},
+		"/api/v1/users/me/confirm-email-change":    {"post"},
+		"/api/v1/users/me/email-change":            {"delete"},
+		"/api/v1/admin/users":                      {"get"},
+		"/api/v1/admin/users/{id}":                 {"get", "put", "patch", "delete"},
+		"/api/v1/admin/users/{id}/promote":         {"post"},
+		"/api/v1/admin/users/{id}/revoke-sessions": {"post"},
+		"/api/v1/admin/users/{id}/revoke-tokens":   {"post"},
+		"/api/v1/admin/roles/{role}/assign":        {"post"},
+		"/api/v1/admin/roles/{role}/remove":        {"post"},
+	}
+	for path, methods := range expected {
+		for _, method := range methods {
+			assert.Contains(t, spec.Paths[path], method, "swagger doc should document %s %s", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestSetupRouter_CacheHeaders(t *testing.T) {
+	db := testutil.NewSQLiteDB(t)
+	authService := auth.NewService(&config.JWTConfig{Secret: "test-secret", TTLHours: 24})
+	userService := user.NewService(user.NewRepository(db))
+	cfg := &config.Config{
+		App:    config.AppConfig{Environment: "test"},
+		Server: config.ServerConfig{SwaggerEnabled: true, StaticCacheMaxAge: 10 * time.Minute},
+		Health: config.HealthConfig{Timeout: 5},
+	}
+	router := SetupRouter(user.NewHandler(userService, authService), authService, cfg, db)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=600", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("Expires"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=600", w.Header().Get("Cache-Control"), "the error catalog only changes between releases")
+
+	u, err := userService.RegisterUser(context.Background(), user.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	token, err := authService.GenerateToken(u.ID, u.Email, u.Name)
+	require.NoError(t, err)
+	for _, path := range []string{"/api/v1/auth/me", "/api/v1/users/me", fmt.Sprintf("/api/v1/users/%d", u.ID)} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"), path)
+		assert.Empty(t, w.Header().Get("Expires"), path)
+	}
+}
@@ -0,0 +1,36 @@
+// Package tenant carries the tenant of a request through a context so the
+// repositories can keep the data of each tenant apart.
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Header is the HTTP header that selects the tenant of a request
+const Header = "X-Tenant-ID"
+
+// Default is the tenant of requests that name none, and of every request when
+// multi-tenancy is disabled
+const Default = ""
+
+// idPattern matches a DNS label, so any tenant ID can also be a subdomain
+var idPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID stored in ctx, or Default when there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// ValidID reports whether id is a lowercase DNS label of at most 63 characters
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
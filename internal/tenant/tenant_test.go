@@ -0,0 +1,23 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, "acme", FromContext(NewContext(context.Background(), "acme")))
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"acme", "a", "acme-eu-1", "42", strings.Repeat("a", 63)} {
+		assert.True(t, ValidID(id), id)
+	}
+	for _, id := range []string{"", "Acme", "-acme", "acme-", "acme.eu", "acme_eu", strings.Repeat("a", 64)} {
+		assert.False(t, ValidID(id), id)
+	}
+}
@@ -0,0 +1,47 @@
+// Package testutil provides helpers shared by tests across packages.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/migrate"
+)
+
+// NewSQLiteDB opens an in-memory SQLite database with the schema built by the
+// embedded migrations, so tests run against the same schema as production
+func NewSQLiteDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	database, err := db.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	if err := MigrateSQLite(database); err != nil {
+		t.Fatalf("failed to migrate sqlite database: %v", err)
+	}
+	return database
+}
+
+// MigrateSQLite applies every embedded migration to a SQLite database
+func MigrateSQLite(database *gorm.DB) error {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	migrator, err := migrate.New(sqlDB, migrate.Config{
+		UseEmbedded: true,
+		Dialect:     migrate.DialectSQLite,
+		TablePrefix: db.TablePrefix(database),
+	})
+	if err != nil {
+		return err
+	}
+	// WHY: The migrator is not closed because that closes the database tests keep using
+	return migrator.Up(context.Background())
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+)
+
+type tracedNote struct {
+	ID   uint
+	Text string
+}
+
+func setupTracedDB(t *testing.T) (*gorm.DB, *sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(&tracedNote{}))
+	require.NoError(t, InstrumentGORM(database, provider))
+	return database, provider, exporter
+}
+
+func TestInstrumentGORM_RecordsChildSpans(t *testing.T) {
+	database, provider, exporter := setupTracedDB(t)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	require.NoError(t, database.WithContext(ctx).Create(&tracedNote{Text: "hello"}).Error)
+	var notes []tracedNote
+	require.NoError(t, database.WithContext(ctx).Find(&notes).Error)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "gorm.create", spans[0].Name)
+	assert.Equal(t, "gorm.query", spans[1].Name)
+	for _, span := range spans[:2] {
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	}
+
+	attributes := map[string]string{}
+	for _, attr := range spans[1].Attributes {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "sqlite", attributes["db.system.name"])
+	assert.Equal(t, "traced_notes", attributes["db.collection.name"])
+	assert.Contains(t, attributes["db.query.text"], "SELECT")
+}
+
+func TestInstrumentGORM_SkipsUntracedContexts(t *testing.T) {
+	database, _, exporter := setupTracedDB(t)
+
+	require.NoError(t, database.Create(&tracedNote{Text: "startup"}).Error)
+
+	assert.Empty(t, exporter.GetSpans())
+}
+
+func TestTraceID(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	assert.Equal(t, span.SpanContext().TraceID().String(), TraceID(ctx))
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(context.Background(), config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    "http://localhost:4318/v1/traces",
+		SampleRatio: 1,
+		ServiceName: "test-service",
+	}, "1.2.3")
+	require.NoError(t, err)
+	// WHY: Nothing was recorded, so shutting down does not reach for the collector
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
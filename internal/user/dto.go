@@ -1,66 +1,225 @@
 package user
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+var patchValidator = validator.New()
+
 // RegisterRequest represents registration request payload
-type RegisterRequest struct {
-	Name     string `json:"name" binding:"required,min=2,max=100"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-}
+type RegisterRequest = api.RegisterRequest
 
 // LoginRequest represents login request payload
-type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
-}
+type LoginRequest = api.LoginRequest
 
 // UpdateUserRequest represents user update request payload
-type UpdateUserRequest struct {
-	Name  string `json:"name" binding:"omitempty,min=2,max=100"`
-	Email string `json:"email" binding:"omitempty,email"`
+type UpdateUserRequest = api.UpdateUserRequest
+
+// AdminUpdateUserRequest is the payload of an admin updating any user
+type AdminUpdateUserRequest = api.AdminUpdateUserRequest
+
+// EmailAvailabilityQuery is the query of an email availability check
+type EmailAvailabilityQuery struct {
+	Email string `form:"email" binding:"required,max=254,email"`
 }
 
-// UserResponse represents user response (without sensitive fields)
-type UserResponse struct {
-	ID        uint     `json:"id"`
-	Name      string   `json:"name"`
-	Email     string   `json:"email"`
-	Roles     []string `json:"roles"`
-	CreatedAt string   `json:"created_at"`
-	UpdatedAt string   `json:"updated_at"`
+// EmailAvailabilityResponse reports whether an email can be used to register
+type EmailAvailabilityResponse = api.EmailAvailabilityResponse
+
+// OptionalString is a tri-state JSON string: absent (Set=false), explicit null (Null=true) or a value
+type OptionalString struct {
+	Set   bool
+	Null  bool
+	Value string
 }
 
-// AuthResponse represents authentication response
-type AuthResponse struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	TokenType    string       `json:"token_type"`
-	ExpiresIn    int64        `json:"expires_in"`
-	User         UserResponse `json:"user"`
+// UnmarshalJSON records that the field was present and whether it was null
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		o.Null = true
+		o.Value = ""
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// PatchUserRequest represents a partial user update payload where absent fields are left untouched
+type PatchUserRequest struct {
+	Name  OptionalString `json:"name" swaggertype:"string"`
+	Email OptionalString `json:"email" swaggertype:"string"`
+}
+
+// Validate checks explicitly provided fields and returns field-level messages keyed like gin validation errors
+func (r PatchUserRequest) Validate() map[string]string {
+	details := make(map[string]string)
+
+	if r.Name.Set {
+		switch n := utf8.RuneCountInString(r.Name.Value); {
+		case r.Name.Null:
+			details["Name"] = "Name cannot be null"
+		case n == 0:
+			details["Name"] = "Name cannot be empty"
+		case n < 2:
+			details["Name"] = "Name is too short (minimum 2)"
+		case n > 100:
+			details["Name"] = "Name is too long (maximum 100)"
+		}
+	}
+
+	if r.Email.Set {
+		switch {
+		case r.Email.Null:
+			details["Email"] = "Email cannot be null"
+		case r.Email.Value == "":
+			details["Email"] = "Email cannot be empty"
+		case patchValidator.Var(r.Email.Value, "email") != nil:
+			details["Email"] = "Email must be a valid email address"
+		}
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
+// RevokeSessionsRequest represents an admin request to revoke all sessions of a user
+type RevokeSessionsRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// BulkRoleRequest represents an admin request to give or take a role from many users at once
+type BulkRoleRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,dive,min=1"`
+	// DryRun reports what would change without changing anything
+	DryRun bool `json:"dry_run"`
+}
+
+// ConfirmEmailChangeRequest represents the payload confirming a pending email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
+// UserResponse represents user response (without sensitive fields)
+type UserResponse = api.UserResponse
+
+// AdminUserResponse is the user representation returned by admin endpoints; it
+// adds fields that are not shown to the users themselves
+type AdminUserResponse = api.AdminUserResponse
+
+// AuthResponse represents authentication response
+type AuthResponse = api.AuthResponse
+
 // LegacyAuthResponse represents legacy authentication response (deprecated)
 type LegacyAuthResponse struct {
 	Token string       `json:"token"`
 	User  UserResponse `json:"user"`
 }
 
+// MessageResponse is the body of actions whose only result is a confirmation
+type MessageResponse = api.MessageResponse
+
+// RevokeSessionsResponse represents the result of revoking a user's sessions
+type RevokeSessionsResponse struct {
+	UserID               uint  `json:"user_id"`
+	RevokedRefreshTokens int64 `json:"revoked_refresh_tokens"`
+}
+
+// UserTokensResponse lists a user's refresh-token families for admin review
+type UserTokensResponse struct {
+	UserID   uint                      `json:"user_id"`
+	Families []auth.TokenFamilySummary `json:"families"`
+}
+
+// Outcomes of a bulk role change for a single user
+const (
+	RoleChangeAssigned  = "assigned"
+	RoleChangeRemoved   = "removed"
+	RoleChangeUnchanged = "unchanged"
+	RoleChangeNotFound  = "not_found"
+)
+
+// RoleChangeResult is the outcome of a bulk role change for one user; in a dry
+// run it is the outcome the change would have
+type RoleChangeResult struct {
+	UserID uint   `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// BulkRoleResponse represents the result of a bulk role change
+type BulkRoleResponse struct {
+	Role    string             `json:"role"`
+	DryRun  bool               `json:"dry_run"`
+	Changed int                `json:"changed"`
+	Results []RoleChangeResult `json:"results"`
+}
+
 // UserListResponse represents paginated user list response
-type UserListResponse struct {
-	Users      []UserResponse `json:"users"`
-	Total      int64          `json:"total"`
-	Page       int            `json:"page"`
-	PerPage    int            `json:"per_page"`
-	TotalPages int            `json:"total_pages"`
+type UserListResponse = api.UserListResponse
+
+// RoleCounts is the number of users holding each role
+type RoleCounts = api.RoleCounts
+
+// UserStatsResponse is the number of users against the license limit
+type UserStatsResponse = api.UserStatsResponse
+
+// ToUserStatsResponse converts seat usage to its API representation
+func ToUserStatsResponse(usage SeatUsage) UserStatsResponse {
+	resp := UserStatsResponse{Users: usage.Users, MaxUsers: usage.MaxUsers}
+	if remaining := usage.Remaining(); remaining >= 0 {
+		resp.RemainingSeats = &remaining
+	}
+	return resp
 }
 
 // ToUserResponse converts User model to UserResponse DTO
 func ToUserResponse(user *User) UserResponse {
-	return UserResponse{
+	resp := UserResponse{
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
 		Roles:     user.GetRoleNames(),
+		Active:    user.Active,
 		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
+	if user.HasPendingEmailChange(time.Now()) {
+		resp.PendingEmail = user.PendingEmail
+	}
+	return resp
+}
+
+// ToAdminUserResponse converts User model to AdminUserResponse DTO
+func ToAdminUserResponse(user *User) AdminUserResponse {
+	resp := AdminUserResponse{UserResponse: ToUserResponse(user)}
+	if user.LastLoginAt != nil {
+		lastLogin := user.LastLoginAt.UTC().Format("2006-01-02T15:04:05Z")
+		resp.LastLoginAt = &lastLogin
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
+		resp.DeletedAt = &deletedAt
+	}
+	return resp
+}
+
+// DownloadURLRequest names the file to sign a download URL for
+type DownloadURLRequest struct {
+	File string `json:"file" binding:"required,oneof=export" example:"export"`
+}
+
+// DownloadURLResponse is a signed URL that downloads a file without an Authorization header
+type DownloadURLResponse struct {
+	URL       string `json:"url" example:"/api/v1/files/export?expires=1735689900&sig=3q2-7w&uid=1"`
+	ExpiresAt string `json:"expires_at" example:"2025-01-01T00:05:00Z"`
 }
@@ -1,29 +1,158 @@
 package user
 
 import (
+	"fmt"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+)
+
+// SortField is a column the user list can be ordered by
+type SortField string
+
+// SortOrder is the direction of the user list ordering
+type SortOrder string
+
+// Supported sort fields and orders
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByName      SortField = "name"
+	SortByEmail     SortField = "email"
+	SortByID        SortField = "id"
+
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+
+	DefaultSortField = SortByCreatedAt
+	DefaultSortOrder = SortDesc
 )
 
-// UserFilterParams represents filtering parameters for user list
+// sortColumns maps each supported sort field to its column; the repository
+// only ever orders by values taken from this table
+var sortColumns = map[SortField]string{
+	SortByCreatedAt: "created_at",
+	SortByUpdatedAt: "updated_at",
+	SortByName:      "name",
+	SortByEmail:     "email",
+	SortByID:        "id",
+}
+
+// ParseSortField converts a raw sort value into a SortField.
+// An empty value yields the default; unknown values return ErrInvalidSort.
+func ParseSortField(s string) (SortField, error) {
+	if s == "" {
+		return DefaultSortField, nil
+	}
+	field := SortField(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := sortColumns[field]; !ok {
+		return "", fmt.Errorf("%w: unsupported field %q", ErrInvalidSort, s)
+	}
+	return field, nil
+}
+
+// ParseSortOrder converts a raw order value into a SortOrder.
+// An empty value yields the default; unknown values return ErrInvalidSort.
+func ParseSortOrder(s string) (SortOrder, error) {
+	if s == "" {
+		return DefaultSortOrder, nil
+	}
+	switch order := SortOrder(strings.ToLower(strings.TrimSpace(s))); order {
+	case SortAsc, SortDesc:
+		return order, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported order %q", ErrInvalidSort, s)
+	}
+}
+
+// UserFilterParams represents filtering parameters for user list as received
+// from the client. Sort and Order are validated by the service.
 type UserFilterParams struct {
 	Role   string
 	Search string
 	Sort   string
 	Order  string
+	// RegisteredFrom and RegisteredTo bound the signup time, inclusive; nil leaves that side open
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
+	// LastLoginBefore keeps users whose last sign-in, if any, is before this time
+	LastLoginBefore *time.Time
+}
+
+// UserListQuery is the validated form of UserFilterParams passed to the repository
+type UserListQuery struct {
+	Role            string
+	Search          string
+	Sort            SortField
+	Order           SortOrder
+	RegisteredFrom  *time.Time
+	RegisteredTo    *time.Time
+	LastLoginBefore *time.Time
 }
 
-// ParseUserFilters parses and validates user filter parameters from request
-func ParseUserFilters(c *gin.Context) UserFilterParams {
-	role := c.Query("role")
+// Query parameters filtering listed users by signup and last sign-in time
+const (
+	RegisteredFromParam  = "registered_from"
+	RegisteredToParam    = "registered_to"
+	LastLoginBeforeParam = "last_login_before"
+)
+
+// ParseRegisteredRange reads the registered_from and registered_to query
+// parameters as RFC3339 timestamps. Missing parameters yield nil.
+func ParseRegisteredRange(c *gin.Context) (from, to *time.Time, err error) {
+	if from, err = parseTimeParam(c, RegisteredFromParam); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parseTimeParam(c, RegisteredToParam); err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+// ParseLastLoginBefore reads the last_login_before query parameter as an
+// RFC3339 timestamp. A missing parameter yields nil.
+func ParseLastLoginBefore(c *gin.Context) (*time.Time, error) {
+	return parseTimeParam(c, LastLoginBeforeParam)
+}
+
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	t, err := middleware.ParseTimeParam(c, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDateRange, err)
+	}
+	return t, nil
+}
+
+// ParseUserFilters parses and validates user filter parameters from request.
+// An omitted sort or order takes the default, but one given empty (?sort=)
+// returns ErrInvalidSort rather than being silently replaced.
+func ParseUserFilters(c *gin.Context) (UserFilterParams, error) {
+	for _, name := range []string{"sort", "order"} {
+		if value, ok := c.GetQuery(name); ok && strings.TrimSpace(value) == "" {
+			return UserFilterParams{}, fmt.Errorf("%w: %s must not be empty", ErrInvalidSort, name)
+		}
+	}
+
+	return NewUserFilterParams(
+		c.Query("role"),
+		c.Query("search"),
+		c.DefaultQuery("sort", string(DefaultSortField)),
+		c.DefaultQuery("order", string(DefaultSortOrder)),
+	), nil
+}
+
+// NewUserFilterParams sanitizes raw filter values: unknown roles are dropped,
+// search is trimmed and capped, and empty sort/order fall back to the defaults
+func NewUserFilterParams(role, search, sort, order string) UserFilterParams {
 	if role != "" && role != RoleUser && role != RoleAdmin {
 		role = ""
 	}
 
 	// Sanitize search parameter: limit length and strip dangerous characters
-	search := c.Query("search")
 	if search != "" {
 		// Limit search length to prevent DoS
 		if utf8.RuneCountInString(search) > 100 {
@@ -33,20 +162,11 @@ func ParseUserFilters(c *gin.Context) UserFilterParams {
 		search = strings.TrimSpace(search)
 	}
 
-	sort := c.DefaultQuery("sort", "created_at")
-	validSorts := map[string]bool{
-		"name":       true,
-		"email":      true,
-		"created_at": true,
-		"updated_at": true,
+	if sort == "" {
+		sort = string(DefaultSortField)
 	}
-	if !validSorts[sort] {
-		sort = "created_at"
-	}
-
-	order := c.DefaultQuery("order", "desc")
-	if order != "asc" && order != "desc" {
-		order = "desc"
+	if order == "" {
+		order = string(DefaultSortOrder)
 	}
 
 	return UserFilterParams{
@@ -9,8 +9,50 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestParseSortField(t *testing.T) {
+	valid := map[string]SortField{
+		"":           SortByCreatedAt,
+		"created_at": SortByCreatedAt,
+		"updated_at": SortByUpdatedAt,
+		"name":       SortByName,
+		"email":      SortByEmail,
+		"id":         SortByID,
+		" Name ":     SortByName,
+	}
+	for input, expected := range valid {
+		field, err := ParseSortField(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, field, input)
+	}
+
+	for _, input := range []string{"password_hash", "users.name", "name desc", "name; DROP TABLE users", "deleted_at"} {
+		_, err := ParseSortField(input)
+		assert.ErrorIs(t, err, ErrInvalidSort, input)
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	valid := map[string]SortOrder{
+		"":     SortDesc,
+		"asc":  SortAsc,
+		"desc": SortDesc,
+		"ASC":  SortAsc,
+	}
+	for input, expected := range valid {
+		order, err := ParseSortOrder(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, order, input)
+	}
+
+	for _, input := range []string{"ascending", "random", "desc;--", "1"} {
+		_, err := ParseSortOrder(input)
+		assert.ErrorIs(t, err, ErrInvalidSort, input)
+	}
+}
+
 func TestParseUserFilters(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -130,12 +172,12 @@ func TestParseUserFilters(t *testing.T) {
 			},
 		},
 		{
-			name:  "invalid sort defaults to created_at",
+			name:  "invalid sort passed through for service validation",
 			query: "sort=invalid_column",
 			expected: UserFilterParams{
 				Role:   "",
 				Search: "",
-				Sort:   "created_at",
+				Sort:   "invalid_column",
 				Order:  "desc",
 			},
 		},
@@ -160,13 +202,13 @@ func TestParseUserFilters(t *testing.T) {
 			},
 		},
 		{
-			name:  "invalid order defaults to desc",
+			name:  "invalid order passed through for service validation",
 			query: "order=random",
 			expected: UserFilterParams{
 				Role:   "",
 				Search: "",
 				Sort:   "created_at",
-				Order:  "desc",
+				Order:  "random",
 			},
 		},
 		{
@@ -185,8 +227,8 @@ func TestParseUserFilters(t *testing.T) {
 			expected: UserFilterParams{
 				Role:   "",
 				Search: "test",
-				Sort:   "created_at",
-				Order:  "desc",
+				Sort:   "invalid",
+				Order:  "invalid",
 			},
 		},
 		{
@@ -227,7 +269,8 @@ func TestParseUserFilters(t *testing.T) {
 			c, _ := gin.CreateTestContext(w)
 			c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
 
-			result := ParseUserFilters(c)
+			result, err := ParseUserFilters(c)
+			require.NoError(t, err)
 
 			assert.Equal(t, tt.expected.Role, result.Role)
 			assert.Equal(t, tt.expected.Search, result.Search)
@@ -236,3 +279,17 @@ func TestParseUserFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUserFilters_EmptySort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, query := range []string{"sort=", "order=", "sort=%20&order=asc"} {
+		t.Run(query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+
+			_, err := ParseUserFilters(c)
+			assert.ErrorIs(t, err, ErrInvalidSort)
+		})
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
 	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
@@ -147,6 +150,24 @@ func TestHandler_RefreshToken(t *testing.T) {
 				assert.Contains(t, errorInfo["message"], "revoked")
 			},
 		},
+		{
+			name: "concurrent refresh",
+			requestBody: auth.RefreshTokenRequest{
+				RefreshToken: "racing-token",
+			},
+			setupMocks: func(mas *MockAuthService) {
+				mas.On("RefreshAccessToken", mock.Anything, "racing-token").Return(nil, auth.ErrConcurrentRefresh)
+			},
+			expectedStatus: http.StatusConflict,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, "CONFLICT", errorInfo["code"])
+			},
+		},
 		{
 			name: "internal server error",
 			requestBody: auth.RefreshTokenRequest{
@@ -199,12 +220,13 @@ func TestHandler_RefreshToken(t *testing.T) {
 
 func TestHandler_Logout(t *testing.T) {
 	tests := []struct {
-		name           string
-		requestBody    interface{}
-		setupMocks     func(*MockAuthService)
-		setupContext   func(*gin.Context)
-		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+		name                string
+		requestBody         interface{}
+		strictHTTPSemantics bool
+		setupMocks          func(*MockAuthService)
+		setupContext        func(*gin.Context)
+		expectedStatus      int
+		checkResponse       func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
 			name: "successful logout",
@@ -220,13 +242,31 @@ func TestHandler_Logout(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response map[string]interface{}
-				err := json.Unmarshal(w.Body.Bytes(), &response)
-				assert.NoError(t, err)
-				assert.Equal(t, true, response["success"])
-				data, ok := response["data"].(map[string]interface{})
-				assert.True(t, ok, "data should be a map")
-				assert.Equal(t, "Successfully logged out", data["message"])
+				var response struct {
+					Success bool            `json:"success"`
+					Data    MessageResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.Success)
+				assert.Equal(t, MessageResponse{Message: "Successfully logged out"}, response.Data)
+			},
+		},
+		{
+			name: "successful logout with strict HTTP semantics",
+			requestBody: auth.RefreshTokenRequest{
+				RefreshToken: "valid-refresh-token",
+			},
+			strictHTTPSemantics: true,
+			setupMocks: func(mas *MockAuthService) {
+				mas.On("RevokeUserRefreshToken", mock.Anything, uint(1), "valid-refresh-token").Return(nil)
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: http.StatusNoContent,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Empty(t, w.Body.String())
 			},
 		},
 		{
@@ -285,13 +325,13 @@ func TestHandler_Logout(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
-				var response map[string]interface{}
-				err := json.Unmarshal(w.Body.Bytes(), &response)
-				assert.NoError(t, err)
-				assert.Equal(t, true, response["success"])
-				data, ok := response["data"].(map[string]interface{})
-				assert.True(t, ok, "data should be a map")
-				assert.Equal(t, "Successfully logged out", data["message"])
+				var response struct {
+					Success bool            `json:"success"`
+					Data    MessageResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.Success)
+				assert.Equal(t, MessageResponse{Message: "Successfully logged out"}, response.Data)
 			},
 		},
 		{
@@ -325,7 +365,8 @@ func TestHandler_Logout(t *testing.T) {
 			tt.setupMocks(mockAuthService)
 
 			handler := &Handler{
-				authService: mockAuthService,
+				authService:         mockAuthService,
+				strictHTTPSemantics: tt.strictHTTPSemantics,
 			}
 
 			bodyBytes, _ := json.Marshal(tt.requestBody)
@@ -337,6 +378,8 @@ func TestHandler_Logout(t *testing.T) {
 
 			handler.Logout(c)
 			apiErrors.ErrorHandler()(c)
+			// WHY: Gin writes a status without a body only when the chain ends
+			c.Writer.WriteHeaderNow()
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			tt.checkResponse(t, w)
@@ -345,3 +388,71 @@ func TestHandler_Logout(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_RefreshToken_LastLogin(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			mockService := new(MockService)
+			mockAuthService := new(MockAuthService)
+			mockAuthService.On("RefreshAccessToken", mock.Anything, "valid-refresh-token").
+				Return(&auth.TokenPair{AccessToken: "new-access-token", RefreshToken: "new-refresh-token", TokenType: "Bearer", ExpiresIn: 900}, nil)
+			if enabled {
+				mockAuthService.On("ValidateToken", "new-access-token").Return(&auth.Claims{UserID: 7}, nil)
+				mockService.On("RecordLogin", mock.Anything, uint(7)).Return()
+			}
+
+			handler := NewHandler(mockService, mockAuthService, WithRefreshUpdatesLastLogin(enabled))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBufferString(`{"refresh_token":"valid-refresh-token"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.RefreshToken(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RefreshToken_Cookie(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.On("RefreshAccessToken", mock.Anything, "cookie-refresh-token").
+		Return(&auth.TokenPair{AccessToken: "new-access-token", RefreshToken: "new-refresh-token", TokenType: "Bearer", ExpiresIn: 900}, nil)
+	mockAuthService.On("RevokeUserRefreshToken", mock.Anything, uint(1), "new-refresh-token").Return(nil)
+
+	handler := NewHandler(new(MockService), mockAuthService, WithTokenDelivery(auth.TokenDeliveryCookie, time.Hour))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	c.Request.AddCookie(&http.Cookie{Name: auth.RefreshTokenCookie, Value: "cookie-refresh-token"})
+
+	handler.RefreshToken(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 2)
+	assert.Equal(t, "new-access-token", cookies[0].Value)
+	assert.Equal(t, "new-refresh-token", cookies[1].Value)
+	assert.NotContains(t, w.Body.String(), "new-refresh-token")
+
+	t.Run("logout clears the cookies", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		c.Request.AddCookie(&http.Cookie{Name: auth.RefreshTokenCookie, Value: "new-refresh-token"})
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+
+		handler.Logout(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		for _, cookie := range w.Result().Cookies() {
+			assert.Empty(t, cookie.Value)
+			assert.Negative(t, cookie.MaxAge)
+		}
+		mockAuthService.AssertExpectations(t)
+	})
+}
@@ -4,17 +4,35 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
 	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
 )
 
 // MockAuthService is a mock implementation of the auth service
@@ -61,11 +79,28 @@ func (m *MockAuthService) RevokeUserRefreshToken(ctx context.Context, userID uin
 	return args.Error(0)
 }
 
-func (m *MockAuthService) RevokeAllUserTokens(ctx context.Context, userID uint) error {
+func (m *MockAuthService) RevokeAllUserTokens(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthService) ListUserTokens(ctx context.Context, userID uint) ([]auth.TokenFamilySummary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.TokenFamilySummary), args.Error(1)
+}
+
+func (m *MockAuthService) CheckUserStatus(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockAuthService) CacheUserStatus(userID uint, active bool) {
+	m.Called(userID, active)
+}
+
 func TestHandler_Register(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -87,7 +122,7 @@ func TestHandler_Register(t *testing.T) {
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}
-				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("user.RegisterRequest")).Return(user, nil)
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).Return(user, nil)
 				tokenPair := &auth.TokenPair{
 					AccessToken:  "mock-access-token",
 					RefreshToken: "mock-refresh-token",
@@ -143,6 +178,85 @@ func TestHandler_Register(t *testing.T) {
 				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
 			},
 		},
+		{
+			name:        "unknown field ignored outside strict mode",
+			requestBody: `{"name":"John Doe","email":"john@example.com","password":"password123","role":"admin"}`,
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RegisterUser", mock.Anything, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}).
+					Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				mas.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").
+					Return(&auth.TokenPair{AccessToken: "mock-access-token", RefreshToken: "mock-refresh-token", TokenType: "Bearer", ExpiresIn: 900}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+		{
+			name:           "details are keyed by JSON name",
+			requestBody:    `{"name":"John Doe","email":42}`,
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, map[string]interface{}{"email": "email is invalid: got number, want string"}, errorInfo["details"])
+			},
+		},
+		{
+			name: "missing password keyed by JSON name",
+			requestBody: RegisterRequest{
+				Name:  "John Doe",
+				Email: "john@example.com",
+			},
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, map[string]interface{}{"password": "password is required"}, errorInfo["details"])
+			},
+		},
+		{
+			name: "password longer than bcrypt accepts",
+			requestBody: RegisterRequest{
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Password: strings.Repeat("é", 40),
+			},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).
+					Return(nil, &auth.PasswordPolicyError{Reason: "password must be at most 72 bytes long"})
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, map[string]interface{}{"password": "password must be at most 72 bytes long"}, errorInfo["details"])
+			},
+		},
+		{
+			name: "password fails policy",
+			requestBody: RegisterRequest{
+				Name:     "Jane Doe",
+				Email:    "jane@example.com",
+				Password: "pass123",
+			},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).
+					Return(nil, &auth.PasswordPolicyError{Reason: "password must be at least 8 characters long"})
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, apiErrors.CodeValidation, errorInfo["code"])
+				details := errorInfo["details"].(map[string]interface{})
+				assert.Equal(t, "password must be at least 8 characters long", details["password"])
+			},
+		},
 		{
 			name: "email already exists",
 			requestBody: RegisterRequest{
@@ -151,7 +265,7 @@ func TestHandler_Register(t *testing.T) {
 				Password: "password123",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("user.RegisterRequest")).Return(nil, ErrEmailExists)
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).Return(nil, ErrEmailExists)
 			},
 			expectedStatus: http.StatusConflict,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -161,7 +275,8 @@ func TestHandler_Register(t *testing.T) {
 				assert.Equal(t, false, response["success"])
 				errorInfo, ok := response["error"].(map[string]interface{})
 				assert.True(t, ok, "error should be a map")
-				assert.Equal(t, "Email already exists", errorInfo["message"])
+				assert.Equal(t, "Email already in use", errorInfo["message"])
+				assert.Equal(t, map[string]interface{}{"email": "already in use"}, errorInfo["details"])
 			},
 		},
 		{
@@ -172,7 +287,7 @@ func TestHandler_Register(t *testing.T) {
 				Password: "password123",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("user.RegisterRequest")).Return(nil, errors.New("database connection error"))
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).Return(nil, errors.New("database connection error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -198,7 +313,7 @@ func TestHandler_Register(t *testing.T) {
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}
-				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("user.RegisterRequest")).Return(user, nil)
+				ms.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).Return(user, nil)
 				mas.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").Return(nil, errors.New("token generation failed"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -265,6 +380,143 @@ func TestHandler_Register(t *testing.T) {
 	}
 }
 
+func TestHandler_RegisterAutoLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []HandlerOption
+		expectedStatus int
+		expectTokens   bool
+	}{
+		{name: "default signs the user in", expectedStatus: http.StatusOK, expectTokens: true},
+		{name: "enabled signs the user in", opts: []HandlerOption{WithAutoLoginOnRegister(true)}, expectedStatus: http.StatusOK, expectTokens: true},
+		{name: "disabled returns only the user", opts: []HandlerOption{WithAutoLoginOnRegister(false)}, expectedStatus: http.StatusCreated, expectTokens: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			mockService.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).
+				Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+			if tt.expectTokens {
+				mockAuthService.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").
+					Return(&auth.TokenPair{AccessToken: "mock-access-token", RefreshToken: "mock-refresh-token", TokenType: "Bearer", ExpiresIn: 900}, nil)
+			}
+
+			handler := NewHandler(mockService, mockAuthService, tt.opts...)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			reqBody, _ := json.Marshal(RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+			c.Request, _ = http.NewRequest("POST", "/register", bytes.NewBuffer(reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Register(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "data should be a map")
+			if tt.expectTokens {
+				assert.Contains(t, data, "access_token")
+				assert.Contains(t, data, "refresh_token")
+				assert.Contains(t, data, "user")
+			} else {
+				assert.NotContains(t, data, "access_token")
+				assert.NotContains(t, data, "refresh_token")
+				assert.Equal(t, "john@example.com", data["email"])
+			}
+
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_LoginTokenDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		delivery     string
+		wantCookies  bool
+		wantInBody   bool
+		legacyAccept bool
+		insecure     bool
+	}{
+		{name: "body", delivery: auth.TokenDeliveryBody, wantInBody: true},
+		{name: "cookie", delivery: auth.TokenDeliveryCookie, wantCookies: true},
+		{name: "both", delivery: auth.TokenDeliveryBoth, wantCookies: true, wantInBody: true},
+		{name: "cookie with legacy response", delivery: auth.TokenDeliveryCookie, wantCookies: true, legacyAccept: true},
+		{name: "cookie without Secure", delivery: auth.TokenDeliveryCookie, wantCookies: true, insecure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			mockService.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).
+				Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+			mockAuthService.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").
+				Return(&auth.TokenPair{AccessToken: "mock-access-token", RefreshToken: "mock-refresh-token", TokenType: "Bearer", ExpiresIn: 900}, nil)
+
+			handler := NewHandler(mockService, mockAuthService, WithTokenDelivery(tt.delivery, 24*time.Hour), WithSecureCookies(!tt.insecure))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			reqBody, _ := json.Marshal(LoginRequest{Email: "john@example.com", Password: "password123"})
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.legacyAccept {
+				c.Request.Header.Set("Accept", LegacyAuthMediaType)
+			}
+
+			handler.Login(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			cookies := map[string]*http.Cookie{}
+			for _, cookie := range w.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+			if tt.wantCookies {
+				require.Contains(t, cookies, auth.AccessTokenCookie)
+				require.Contains(t, cookies, auth.RefreshTokenCookie)
+				access, refresh := cookies[auth.AccessTokenCookie], cookies[auth.RefreshTokenCookie]
+				assert.Equal(t, "mock-access-token", access.Value)
+				assert.Equal(t, 900, access.MaxAge)
+				assert.Equal(t, "/", access.Path)
+				assert.Equal(t, "mock-refresh-token", refresh.Value)
+				assert.Equal(t, 86400, refresh.MaxAge)
+				assert.Equal(t, "/api/v1/auth", refresh.Path)
+				for _, cookie := range cookies {
+					assert.Equal(t, !tt.insecure, cookie.Secure)
+					assert.True(t, cookie.HttpOnly)
+					assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+				}
+			} else {
+				assert.Empty(t, cookies)
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			if tt.legacyAccept {
+				assert.Empty(t, data["token"])
+				return
+			}
+			if tt.wantInBody {
+				assert.Equal(t, "mock-access-token", data["access_token"])
+				assert.Equal(t, "mock-refresh-token", data["refresh_token"])
+			} else {
+				assert.Empty(t, data["access_token"])
+				assert.Empty(t, data["refresh_token"])
+				assert.Equal(t, float64(900), data["expires_in"])
+			}
+		})
+	}
+}
+
 func TestHandler_GetUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -323,6 +575,46 @@ func TestHandler_GetUser(t *testing.T) {
 				assert.Equal(t, "Invalid user ID", errorInfo["message"])
 			},
 		},
+		{
+			name:   "user ID above the 32-bit schema limit",
+			userID: "2147483648",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
+				assert.Equal(t, "User ID must be at most 2147483647", errorInfo["message"])
+			},
+		},
+		{
+			name:   "user ID beyond uint64",
+			userID: "18446744073709551616",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
+				assert.Equal(t, "User ID must be at most 2147483647", errorInfo["message"])
+			},
+		},
 		{
 			name:   "unauthenticated user - no context",
 			userID: "1",
@@ -380,6 +672,7 @@ func TestHandler_GetUser(t *testing.T) {
 				errorInfo, ok := response["error"].(map[string]interface{})
 				assert.True(t, ok, "error should be a map")
 				assert.Equal(t, "User not found", errorInfo["message"])
+				assert.Equal(t, map[string]interface{}{"resource": "user", "id": float64(999)}, errorInfo["details"])
 			},
 		},
 		{
@@ -403,6 +696,46 @@ func TestHandler_GetUser(t *testing.T) {
 				assert.Equal(t, "database connection error", errorInfo["details"])
 			},
 		},
+		{
+			name:   "client disconnected",
+			userID: "1",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(1)).Return(nil, context.Canceled)
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: apiErrors.StatusClientClosedRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, apiErrors.CodeRequestCanceled, errorInfo["code"])
+			},
+		},
+		{
+			name:   "request timed out",
+			userID: "1",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(1)).Return(nil, context.DeadlineExceeded)
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: http.StatusRequestTimeout,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, apiErrors.CodeRequestTimeout, errorInfo["code"])
+			},
+		},
 		{
 			name:   "zero user ID",
 			userID: "0",
@@ -454,6 +787,92 @@ func TestHandler_GetUser(t *testing.T) {
 	}
 }
 
+func TestHandler_GetAdminUser(t *testing.T) {
+	deletedAt := time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		checkResponse  func(*testing.T, map[string]interface{})
+	}{
+		{
+			name:   "deleted user",
+			userID: "1",
+			setupMocks: func(ms *MockService) {
+				user := &User{ID: 1, Name: "John Doe", Email: "john@example.com", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+				ms.On("GetUserByIDIncludingDeleted", mock.Anything, uint(1)).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "john@example.com", data["email"])
+				assert.Equal(t, "2025-06-01T08:30:00Z", data["deleted_at"])
+			},
+		},
+		{
+			name:   "active user",
+			userID: "2",
+			setupMocks: func(ms *MockService) {
+				ms.On("GetUserByIDIncludingDeleted", mock.Anything, uint(2)).Return(&User{ID: 2, Name: "Jane Doe"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Contains(t, data, "deleted_at")
+				assert.Nil(t, data["deleted_at"])
+			},
+		},
+		{
+			name:   "user never existed",
+			userID: "999",
+			setupMocks: func(ms *MockService) {
+				ms.On("GetUserByIDIncludingDeleted", mock.Anything, uint(999)).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "User not found", errorInfo["message"])
+			},
+		},
+		{
+			name:           "invalid user ID format",
+			userID:         "invalid",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "Invalid user ID", errorInfo["message"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			tt.setupMocks(mockService)
+
+			handler := NewHandler(mockService, &MockAuthService{})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/admin/users/"+tt.userID, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+
+			handler.GetAdminUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			tt.checkResponse(t, response)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_Login(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -474,7 +893,7 @@ func TestHandler_Login(t *testing.T) {
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}
-				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("user.LoginRequest")).Return(user, nil)
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(user, nil)
 				tokenPair := &auth.TokenPair{
 					AccessToken:  "mock-access-token",
 					RefreshToken: "mock-refresh-token",
@@ -507,7 +926,7 @@ func TestHandler_Login(t *testing.T) {
 				Password: "wrongpassword",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("user.LoginRequest")).Return(nil, ErrInvalidCredentials)
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(nil, ErrInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -521,38 +940,75 @@ func TestHandler_Login(t *testing.T) {
 			},
 		},
 		{
-			name: "service error",
+			name: "password hashing saturated",
 			requestBody: LoginRequest{
 				Email:    "john@example.com",
 				Password: "password123",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("user.LoginRequest")).Return(nil, errors.New("failed to authenticate user"))
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(nil, ErrOverloaded)
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusServiceUnavailable,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, "1", w.Header().Get("Retry-After"))
 				var response map[string]interface{}
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Equal(t, false, response["success"])
-				errorInfo, ok := response["error"].(map[string]interface{})
-				assert.True(t, ok, "error should be a map")
-				assert.Equal(t, "failed to authenticate user", errorInfo["details"])
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "SERVICE_UNAVAILABLE", errorInfo["code"])
 			},
 		},
 		{
-			name: "token generation error",
+			name: "deactivated account",
 			requestBody: LoginRequest{
 				Email:    "john@example.com",
 				Password: "password123",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				user := &User{
-					ID:    1,
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(nil, ErrAccountDisabled)
+			},
+			expectedStatus: http.StatusForbidden,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, apiErrors.CodeAccountDisabled, errorInfo["code"])
+			},
+		},
+		{
+			name: "service error",
+			requestBody: LoginRequest{
+				Email:    "john@example.com",
+				Password: "password123",
+			},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(nil, errors.New("failed to authenticate user"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, false, response["success"])
+				errorInfo, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok, "error should be a map")
+				assert.Equal(t, "failed to authenticate user", errorInfo["details"])
+			},
+		},
+		{
+			name: "token generation error",
+			requestBody: LoginRequest{
+				Email:    "john@example.com",
+				Password: "password123",
+			},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				user := &User{
+					ID:    1,
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}
-				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("user.LoginRequest")).Return(user, nil)
+				ms.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(user, nil)
 				mas.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").Return(nil, errors.New("failed to generate token"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -579,7 +1035,7 @@ func TestHandler_Login(t *testing.T) {
 				errorInfo, ok := response["error"].(map[string]interface{})
 				assert.True(t, ok, "error should be a map")
 				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
-				assert.Equal(t, "Invalid request data format", errorInfo["message"])
+				assert.Equal(t, "malformed JSON", errorInfo["message"])
 			},
 		},
 	}
@@ -622,6 +1078,99 @@ func TestHandler_Login(t *testing.T) {
 	}
 }
 
+func TestHandler_AuthResponseShape(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpoint     string
+		legacyFlag   bool
+		acceptHeader string
+		expectLegacy bool
+	}{
+		{name: "login default shape", endpoint: "login", expectLegacy: false},
+		{name: "login legacy via config flag", endpoint: "login", legacyFlag: true, expectLegacy: true},
+		{name: "login legacy via accept header", endpoint: "login", acceptHeader: LegacyAuthMediaType, expectLegacy: true},
+		{name: "register default shape", endpoint: "register", acceptHeader: "application/json", expectLegacy: false},
+		{name: "register legacy via config flag", endpoint: "register", legacyFlag: true, expectLegacy: true},
+		{name: "register legacy via accept header", endpoint: "register", acceptHeader: LegacyAuthMediaType, expectLegacy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+
+			user := &User{
+				ID:    1,
+				Name:  "John Doe",
+				Email: "john@example.com",
+			}
+			tokenPair := &auth.TokenPair{
+				AccessToken:  "mock-access-token",
+				RefreshToken: "mock-refresh-token",
+				TokenType:    "Bearer",
+				ExpiresIn:    900,
+			}
+
+			var requestBody interface{}
+			if tt.endpoint == "register" {
+				requestBody = RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}
+				mockService.On("RegisterUser", mock.Anything, mock.AnythingOfType("api.RegisterRequest")).Return(user, nil)
+			} else {
+				requestBody = LoginRequest{Email: "john@example.com", Password: "password123"}
+				mockService.On("AuthenticateUser", mock.Anything, mock.AnythingOfType("api.LoginRequest")).Return(user, nil)
+			}
+			mockAuthService.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").Return(tokenPair, nil)
+
+			handler := NewHandler(mockService, mockAuthService, WithLegacyAuthResponse(tt.legacyFlag))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			body, err := json.Marshal(requestBody)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/auth/"+tt.endpoint, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptHeader != "" {
+				req.Header.Set("Accept", tt.acceptHeader)
+			}
+			c.Request = req
+
+			if tt.endpoint == "register" {
+				handler.Register(c)
+			} else {
+				handler.Login(c)
+			}
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			data, ok := response["data"].(map[string]interface{})
+			assert.True(t, ok, "data should be a map")
+
+			if tt.expectLegacy {
+				assert.Equal(t, "mock-access-token", data["token"])
+				assert.NotContains(t, data, "access_token")
+				assert.NotContains(t, data, "refresh_token")
+			} else {
+				assert.Equal(t, "mock-access-token", data["access_token"])
+				assert.Equal(t, "mock-refresh-token", data["refresh_token"])
+				assert.NotContains(t, data, "token")
+			}
+
+			respUser, ok := data["user"].(map[string]interface{})
+			assert.True(t, ok, "user should be a map")
+			assert.Equal(t, "john@example.com", respUser["email"])
+
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_UpdateUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -645,7 +1194,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 					Name:  "John Updated",
 					Email: "john.updated@example.com",
 				}
-				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("user.UpdateUserRequest")).Return(updatedUser, nil)
+				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("api.UpdateUserRequest")).Return(updatedUser, nil)
 			},
 			setupContext: func(c *gin.Context) {
 				claims := &auth.Claims{UserID: 1}
@@ -711,7 +1260,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 				Email: "john.updated@example.com",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("UpdateUser", mock.Anything, uint(999), mock.AnythingOfType("user.UpdateUserRequest")).Return(nil, ErrUserNotFound)
+				ms.On("UpdateUser", mock.Anything, uint(999), mock.AnythingOfType("api.UpdateUserRequest")).Return(nil, ErrUserNotFound)
 			},
 			setupContext: func(c *gin.Context) {
 				claims := &auth.Claims{UserID: 999}
@@ -736,7 +1285,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 				Email: "existing@example.com",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("user.UpdateUserRequest")).Return(nil, ErrEmailExists)
+				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("api.UpdateUserRequest")).Return(nil, ErrEmailExists)
 			},
 			setupContext: func(c *gin.Context) {
 				claims := &auth.Claims{UserID: 1}
@@ -750,7 +1299,8 @@ func TestHandler_UpdateUser(t *testing.T) {
 				assert.Equal(t, false, response["success"])
 				errorInfo, ok := response["error"].(map[string]interface{})
 				assert.True(t, ok, "error should be a map")
-				assert.Equal(t, "Email already exists", errorInfo["message"])
+				assert.Equal(t, "Email already in use", errorInfo["message"])
+				assert.Equal(t, map[string]interface{}{"email": "already in use"}, errorInfo["details"])
 			},
 		},
 		{
@@ -761,7 +1311,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 				Email: "john.updated@example.com",
 			},
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
-				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("user.UpdateUserRequest")).Return(nil, errors.New("failed to update user"))
+				ms.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("api.UpdateUserRequest")).Return(nil, errors.New("failed to update user"))
 			},
 			setupContext: func(c *gin.Context) {
 				claims := &auth.Claims{UserID: 1}
@@ -796,7 +1346,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 				errorInfo, ok := response["error"].(map[string]interface{})
 				assert.True(t, ok, "error should be a map")
 				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
-				assert.Equal(t, "Invalid request data format", errorInfo["message"])
+				assert.Equal(t, "malformed JSON", errorInfo["message"])
 			},
 		},
 	}
@@ -842,6 +1392,202 @@ func TestHandler_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestHandler_PatchUser(t *testing.T) {
+	asOwner := func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+	}
+
+	tests := []struct {
+		name           string
+		userID         string
+		requestBody    string
+		setupMocks     func(*MockService)
+		setupContext   func(*gin.Context)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "name value, email absent",
+			userID:      "1",
+			requestBody: `{"name":"John Patched"}`,
+			setupMocks: func(ms *MockService) {
+				patch := PatchUserRequest{Name: OptionalString{Set: true, Value: "John Patched"}}
+				ms.On("UpdateUserPartial", mock.Anything, uint(1), patch).Return(&User{ID: 1, Name: "John Patched", Email: "john@example.com"}, nil)
+			},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "John Patched", data["name"])
+				assert.Equal(t, "john@example.com", data["email"])
+			},
+		},
+		{
+			name:        "email value, name absent",
+			userID:      "1",
+			requestBody: `{"email":"new@example.com"}`,
+			setupMocks: func(ms *MockService) {
+				patch := PatchUserRequest{Email: OptionalString{Set: true, Value: "new@example.com"}}
+				ms.On("UpdateUserPartial", mock.Anything, uint(1), patch).Return(&User{ID: 1, Name: "John Doe", Email: "new@example.com"}, nil)
+			},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "new@example.com", data["email"])
+			},
+		},
+		{
+			name:        "all fields absent",
+			userID:      "1",
+			requestBody: `{}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserPartial", mock.Anything, uint(1), PatchUserRequest{}).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+			},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusOK,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+		{
+			name:           "name explicitly empty",
+			userID:         "1",
+			requestBody:    `{"name":""}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
+				details := errorInfo["details"].(map[string]interface{})
+				assert.Equal(t, "Name cannot be empty", details["Name"])
+			},
+		},
+		{
+			name:           "name null",
+			userID:         "1",
+			requestBody:    `{"name":null}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+				assert.Equal(t, "Name cannot be null", details["Name"])
+			},
+		},
+		{
+			name:           "email null",
+			userID:         "1",
+			requestBody:    `{"name":"John Doe","email":null}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+				assert.Equal(t, "Email cannot be null", details["Email"])
+				assert.NotContains(t, details, "Name")
+			},
+		},
+		{
+			name:           "email invalid",
+			userID:         "1",
+			requestBody:    `{"email":"not-an-email"}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+				assert.Equal(t, "Email must be a valid email address", details["Email"])
+			},
+		},
+		{
+			name:        "email already exists",
+			userID:      "1",
+			requestBody: `{"email":"taken@example.com"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserPartial", mock.Anything, uint(1), mock.AnythingOfType("user.PatchUserRequest")).Return(nil, ErrEmailExists)
+			},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusConflict,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+		{
+			name:        "user not found",
+			userID:      "1",
+			requestBody: `{"name":"John Doe"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserPartial", mock.Anything, uint(1), mock.AnythingOfType("user.PatchUserRequest")).Return(nil, ErrUserNotFound)
+			},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusNotFound,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+		{
+			name:           "forbidden for other user",
+			userID:         "2",
+			requestBody:    `{"name":"John Doe"}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusForbidden,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+		{
+			name:           "invalid json",
+			userID:         "1",
+			requestBody:    `{invalid-json}`,
+			setupMocks:     func(ms *MockService) {},
+			setupContext:   asOwner,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse:  func(t *testing.T, w *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			tt.setupMocks(mockService)
+
+			handler := NewHandler(mockService, mockAuthService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			req := httptest.NewRequest("PATCH", "/users/"+tt.userID, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+
+			tt.setupContext(c)
+
+			handler.PatchUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			tt.checkResponse(t, w)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_DeleteUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -856,6 +1602,25 @@ func TestHandler_DeleteUser(t *testing.T) {
 			userID: "1",
 			setupMocks: func(ms *MockService, mas *MockAuthService) {
 				ms.On("DeleteUser", mock.Anything, uint(1)).Return(nil)
+				mas.On("CacheUserStatus", uint(1), false).Return()
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(int64(2), nil)
+			},
+			setupContext: func(c *gin.Context) {
+				claims := &auth.Claims{UserID: 1}
+				c.Set(auth.KeyUser, claims)
+			},
+			expectedStatus: http.StatusOK, // Note: Gin test recorder returns 200 for c.Status(204) without response body
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, "", w.Body.String())
+			},
+		},
+		{
+			name:   "token revocation failure after deletion",
+			userID: "1",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("DeleteUser", mock.Anything, uint(1)).Return(nil)
+				mas.On("CacheUserStatus", uint(1), false).Return()
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(1)).Return(int64(0), errors.New("database unavailable"))
 			},
 			setupContext: func(c *gin.Context) {
 				claims := &auth.Claims{UserID: 1}
@@ -1030,7 +1795,7 @@ func TestHandler_GetMe(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
-			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
 			c.Request = req
 
 			if tt.userID > 0 {
@@ -1050,36 +1815,254 @@ func TestHandler_GetMe(t *testing.T) {
 	}
 }
 
-func TestHandler_ListUsers(t *testing.T) {
+func TestHandler_DownloadURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	signer := auth.NewURLSigner("test-secret-for-jwt-tokens-min-32-chars", DownloadURLTTL)
+	mockService := new(MockService)
+	mockService.On("GetUserByID", mock.Anything, uint(7)).Return(&User{ID: 7, Name: "John Doe", Email: "john@example.com"}, nil)
+	handler := NewHandler(mockService, new(MockAuthService), WithURLSigner(signer))
+
+	r := gin.New()
+	r.Use(apiErrors.ErrorHandler())
+	r.POST("/api/v1/users/me/download-urls", func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 7})
+	}, handler.CreateDownloadURL)
+	r.GET("/api/v1/files/export", auth.SignedURLMiddleware(signer), handler.ExportAccount)
+
+	createURL := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/download-urls", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := createURL(`{"file":"export"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data DownloadURLResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Data.URL, "/api/v1/files/export?"))
+	expiresAt, err := time.Parse(time.RFC3339, created.Data.ExpiresAt)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DownloadURLTTL), expiresAt, 2*time.Second)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, created.Data.URL, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.Contains(t, w.Body.String(), `"email":"john@example.com"`)
+
+	t.Run("unknown file", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, createURL(`{"file":"passwords"}`).Code)
+	})
+
+	t.Run("url not scoped to a user", func(t *testing.T) {
+		unscoped, err := signer.Sign("/api/v1/files/export", time.Minute)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, unscoped, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("no signer configured", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/me/download-urls", strings.NewReader(`{"file":"export"}`))
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 7})
+		NewHandler(mockService, new(MockAuthService)).CreateDownloadURL(c)
+		apiErrors.ErrorHandler()(c)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
+
+func TestHandler_GetTokenClaims(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
 		name           string
-		queryParams    string
+		accessTokenTTL time.Duration
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "echoes the encoded claims", accessTokenTTL: 15 * time.Minute, expectedStatus: http.StatusOK},
+		{name: "expired token", accessTokenTTL: -time.Minute, expectedStatus: http.StatusUnauthorized, expectedCode: apiErrors.CodeTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := auth.NewService(&config.JWTConfig{Secret: "test-secret", AccessTokenTTL: tt.accessTokenTTL})
+			token, err := authService.GenerateToken(42, "claims@example.com", "Claims User")
+			require.NoError(t, err)
+			// WHY: The handler must not touch the user service; an unexpected call fails the mock
+			handler := NewHandler(new(MockService), authService)
+
+			router := gin.New()
+			router.Use(apiErrors.ErrorHandler())
+			router.GET("/api/v1/auth/me", auth.AuthMiddleware(authService), handler.GetTokenClaims)
+
+			before := time.Now()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			var response apiErrors.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedCode != "" {
+				require.NotNil(t, response.Error)
+				assert.Equal(t, tt.expectedCode, response.Error.Code)
+				return
+			}
+
+			data := response.Data.(map[string]interface{})
+			assert.Equal(t, "42", data["sub"])
+			assert.Equal(t, "claims@example.com", data["email"])
+			assert.Equal(t, "Claims User", data["name"])
+			assert.Equal(t, []interface{}{}, data["roles"])
+			iat := int64(data["iat"].(float64))
+			assert.InDelta(t, before.Unix(), iat, 1)
+			assert.Equal(t, iat+int64(tt.accessTokenTTL.Seconds()), int64(data["exp"].(float64)))
+		})
+	}
+}
+
+func TestHandler_EmailAvailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		protection     bool
 		setupMocks     func(*MockService)
 		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+		expectedCode   string
+		available      bool
 	}{
 		{
-			name:        "successful list with defaults",
-			queryParams: "",
+			name:  "available",
+			query: "?email=new@example.com",
 			setupMocks: func(ms *MockService) {
-				users := []User{
-					{ID: 1, Name: "User 1", Email: "user1@example.com"},
-					{ID: 2, Name: "User 2", Email: "user2@example.com"},
-				}
-				ms.On("ListUsers", mock.Anything, mock.MatchedBy(func(f UserFilterParams) bool {
-					return f.Sort == "created_at" && f.Order == "desc"
-				}), 1, 20).Return(users, int64(2), nil)
+				ms.On("IsEmailAvailable", mock.Anything, "new@example.com").Return(true, nil)
 			},
 			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+			available:      true,
+		},
+		{
+			name:  "taken",
+			query: "?email=John@Example.com",
+			setupMocks: func(ms *MockService) {
+				ms.On("IsEmailAvailable", mock.Anything, "John@Example.com").Return(false, nil)
+			},
+			expectedStatus: http.StatusOK,
+			available:      false,
+		},
+		{
+			name:           "enumeration protection reports taken emails as available",
+			query:          "?email=john@example.com",
+			protection:     true,
+			expectedStatus: http.StatusOK,
+			available:      true,
+		},
+		{
+			name:           "enumeration protection still validates",
+			query:          "?email=not-an-email",
+			protection:     true,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:           "malformed email",
+			query:          "?email=john%40",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:           "missing email",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:           "overlong email",
+			query:          "?email=" + strings.Repeat("a", 250) + "@example.com",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:  "lookup failure",
+			query: "?email=new@example.com",
+			setupMocks: func(ms *MockService) {
+				ms.On("IsEmailAvailable", mock.Anything, "new@example.com").Return(false, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   apiErrors.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockService)
+			}
+			handler := NewHandler(mockService, new(MockAuthService), WithEmailEnumerationProtection(tt.protection))
+
+			router := gin.New()
+			router.Use(apiErrors.ErrorHandler())
+			router.GET("/api/v1/auth/email-available", handler.EmailAvailable)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/email-available"+tt.query, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			var response apiErrors.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedCode != "" {
+				require.NotNil(t, response.Error)
+				assert.Equal(t, tt.expectedCode, response.Error.Code)
+			} else {
+				assert.Equal(t, map[string]interface{}{"available": tt.available}, response.Data)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "successful list with defaults",
+			queryParams: "",
+			setupMocks: func(ms *MockService) {
+				users := []User{
+					{ID: 1, Name: "User 1", Email: "user1@example.com"},
+					{ID: 2, Name: "User 2", Email: "user2@example.com"},
+				}
+				ms.On("ListUsers", mock.Anything, mock.MatchedBy(func(f UserFilterParams) bool {
+					return f.Sort == "created_at" && f.Order == "desc"
+				}), 1, 20).Return(users, int64(2), nil)
+				ms.On("CountUsersByRole", mock.Anything, mock.Anything).Return(map[string]int64{RoleUser: 2, RoleAdmin: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
 				var response map[string]interface{}
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
 				assert.True(t, response["success"].(bool))
 				data := response["data"].(map[string]interface{})
 				assert.Equal(t, float64(2), data["total"])
+				assert.Equal(t, map[string]interface{}{"user": float64(2), "admin": float64(1)}, data["role_counts"])
 			},
 		},
 		{
@@ -1092,6 +2075,9 @@ func TestHandler_ListUsers(t *testing.T) {
 				ms.On("ListUsers", mock.Anything, mock.MatchedBy(func(f UserFilterParams) bool {
 					return f.Role == "admin"
 				}), 1, 10).Return(users, int64(1), nil)
+				ms.On("CountUsersByRole", mock.Anything, mock.MatchedBy(func(f UserFilterParams) bool {
+					return f.Role == "admin"
+				})).Return(map[string]int64{RoleUser: 1, RoleAdmin: 1}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -1112,6 +2098,7 @@ func TestHandler_ListUsers(t *testing.T) {
 				ms.On("ListUsers", mock.Anything, mock.MatchedBy(func(f UserFilterParams) bool {
 					return f.Search == "john"
 				}), 1, 20).Return(users, int64(1), nil)
+				ms.On("CountUsersByRole", mock.Anything, mock.Anything).Return(map[string]int64{RoleUser: 1, RoleAdmin: 0}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -1125,6 +2112,7 @@ func TestHandler_ListUsers(t *testing.T) {
 			queryParams: "",
 			setupMocks: func(ms *MockService) {
 				ms.On("ListUsers", mock.Anything, mock.Anything, 1, 20).Return([]User{}, int64(0), nil)
+				ms.On("CountUsersByRole", mock.Anything, mock.Anything).Return(map[string]int64{RoleUser: 0, RoleAdmin: 0}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -1155,6 +2143,117 @@ func TestHandler_ListUsers(t *testing.T) {
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
 			},
 		},
+		{
+			name:        "invalid sort parameters",
+			queryParams: "?sort=password_hash",
+			setupMocks: func(ms *MockService) {
+				ms.On("ListUsers", mock.Anything, UserFilterParams{Sort: "password_hash", Order: "desc"}, 1, 20).
+					Return(nil, int64(0), fmt.Errorf("%w: unsupported field %q", ErrInvalidSort, "password_hash"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid sort parameters")
+			},
+		},
+		{
+			name:           "empty sort parameter",
+			queryParams:    "?sort=",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid sort parameters")
+			},
+		},
+		{
+			name:        "registration date filters",
+			queryParams: "?registered_from=2025-01-01T00:00:00Z&registered_to=2025-02-01T00:00:00%2B02:00",
+			setupMocks: func(ms *MockService) {
+				from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				to := time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC)
+				matches := mock.MatchedBy(func(f UserFilterParams) bool {
+					return f.RegisteredFrom != nil && f.RegisteredFrom.Equal(from) &&
+						f.RegisteredTo != nil && f.RegisteredTo.Equal(to)
+				})
+				ms.On("ListUsers", mock.Anything, matches, 1, 20).Return([]User{}, int64(0), nil)
+				ms.On("CountUsersByRole", mock.Anything, matches).Return(map[string]int64{RoleUser: 0, RoleAdmin: 0}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid registered_from",
+			queryParams:    "?registered_from=2025-01-01",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid date filter")
+			},
+		},
+		{
+			name:           "invalid registered_to",
+			queryParams:    "?registered_to=yesterday",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid date filter")
+			},
+		},
+		{
+			name:        "dormant users with last login",
+			queryParams: "?last_login_before=2025-03-01T00:00:00Z",
+			setupMocks: func(ms *MockService) {
+				lastLogin := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+				users := []User{
+					{ID: 1, Name: "Dormant", Email: "dormant@example.com", LastLoginAt: &lastLogin},
+					{ID: 2, Name: "Never", Email: "never@example.com"},
+				}
+				cutoff := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+				matchesCutoff := mock.MatchedBy(func(f UserFilterParams) bool {
+					return f.LastLoginBefore != nil && f.LastLoginBefore.Equal(cutoff)
+				})
+				ms.On("ListUsers", mock.Anything, matchesCutoff, 1, 20).Return(users, int64(2), nil)
+				ms.On("CountUsersByRole", mock.Anything, matchesCutoff).Return(map[string]int64{RoleUser: 2, RoleAdmin: 0}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				users := response["data"].(map[string]interface{})["users"].([]interface{})
+				require.Len(t, users, 2)
+				assert.Equal(t, "2025-01-15T12:00:00Z", users[0].(map[string]interface{})["last_login_at"])
+				assert.Contains(t, users[1], "last_login_at")
+				assert.Nil(t, users[1].(map[string]interface{})["last_login_at"], "never signed in")
+			},
+		},
+		{
+			name:           "invalid last_login_before",
+			queryParams:    "?last_login_before=last-year",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid date filter")
+			},
+		},
+		{
+			name:        "inverted registration range",
+			queryParams: "?registered_from=2025-02-01T00:00:00Z&registered_to=2025-01-01T00:00:00Z",
+			setupMocks: func(ms *MockService) {
+				ms.On("ListUsers", mock.Anything, mock.Anything, 1, 20).
+					Return(nil, int64(0), fmt.Errorf("%w: registered_from must not be after registered_to", ErrInvalidDateRange))
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "Invalid date filter")
+			},
+		},
+		{
+			name:        "role count error",
+			queryParams: "",
+			setupMocks: func(ms *MockService) {
+				ms.On("ListUsers", mock.Anything, mock.Anything, 1, 20).Return([]User{}, int64(0), nil)
+				ms.On("CountUsersByRole", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1181,3 +2280,1255 @@ func TestHandler_ListUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_RevokeUserSessions(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		requestBody    string
+		setupMocks     func(*MockService, *MockAuthService)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "successful revocation with reason",
+			userID:      "2",
+			requestBody: `{"reason":"compromised account"}`,
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(3), nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				assert.Equal(t, true, response["success"])
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, float64(2), data["user_id"])
+				assert.Equal(t, float64(3), data["revoked_refresh_tokens"])
+			},
+		},
+		{
+			name:        "successful revocation without body",
+			userID:      "2",
+			requestBody: "",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid user ID",
+			userID:         "invalid",
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			userID:         "2",
+			requestBody:    `{"reason":`,
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "user not found",
+			userID: "99",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(99)).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "User not found")
+			},
+		},
+		{
+			name:   "revocation error",
+			userID: "2",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(0), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			tt.setupMocks(mockService, mockAuthService)
+
+			handler := NewHandler(mockService, mockAuthService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+tt.userID+"/revoke-sessions", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			handler.RevokeUserSessions(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUserTokens(t *testing.T) {
+	family := uuid.New()
+	issuedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	usedAt := issuedAt.Add(time.Hour)
+	families := []auth.TokenFamilySummary{{
+		Family: family,
+		Active: true,
+		Tokens: []auth.RefreshTokenSummary{
+			{ID: uuid.New(), IssuedAt: usedAt, ExpiresAt: usedAt.Add(24 * time.Hour)},
+			{ID: uuid.New(), IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(24 * time.Hour), UsedAt: &usedAt},
+		},
+	}}
+
+	tests := []struct {
+		name           string
+		method         string
+		userID         string
+		roles          []string
+		setupMocks     func(*MockService, *MockAuthService)
+		expectedStatus int
+		checkResponse  func(*testing.T, map[string]interface{})
+	}{
+		{
+			name:   "lists token families without hashes",
+			method: http.MethodGet,
+			userID: "2",
+			roles:  []string{RoleAdmin},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("ListUserTokens", mock.Anything, uint(2)).Return(families, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, float64(2), data["user_id"])
+
+				got := data["families"].([]interface{})
+				require.Len(t, got, 1)
+				first := got[0].(map[string]interface{})
+				assert.Equal(t, family.String(), first["family"])
+				assert.Equal(t, true, first["active"])
+
+				tokens := first["tokens"].([]interface{})
+				require.Len(t, tokens, 2)
+				latest, rotated := tokens[0].(map[string]interface{}), tokens[1].(map[string]interface{})
+				assert.Equal(t, usedAt.Format(time.RFC3339), latest["issued_at"])
+				assert.NotContains(t, latest, "used_at")
+				assert.Equal(t, usedAt.Format(time.RFC3339), rotated["used_at"])
+				for _, token := range tokens {
+					assert.NotContains(t, token, "token_hash")
+				}
+			},
+		},
+		{
+			name:   "user without tokens",
+			method: http.MethodGet,
+			userID: "2",
+			roles:  []string{RoleAdmin},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("ListUserTokens", mock.Anything, uint(2)).Return([]auth.TokenFamilySummary{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, []interface{}{}, data["families"])
+			},
+		},
+		{
+			name:           "invalid user ID",
+			method:         http.MethodGet,
+			userID:         "invalid",
+			roles:          []string{RoleAdmin},
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "user not found",
+			method: http.MethodGet,
+			userID: "99",
+			roles:  []string{RoleAdmin},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(99)).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "list error",
+			method: http.MethodGet,
+			userID: "2",
+			roles:  []string{RoleAdmin},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("ListUserTokens", mock.Anything, uint(2)).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "admin revokes all tokens",
+			method: http.MethodDelete,
+			userID: "2",
+			roles:  []string{RoleAdmin},
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(2), nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, response map[string]interface{}) {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, float64(2), data["user_id"])
+				assert.Equal(t, float64(2), data["revoked_refresh_tokens"])
+			},
+		},
+		{
+			name:           "non-admin cannot list",
+			method:         http.MethodGet,
+			userID:         "2",
+			roles:          []string{RoleUser},
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "non-admin cannot revoke",
+			method:         http.MethodDelete,
+			userID:         "2",
+			roles:          []string{RoleUser},
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			tt.setupMocks(mockService, mockAuthService)
+
+			handler := NewHandler(mockService, mockAuthService)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(apiErrors.ErrorHandler(), func(c *gin.Context) {
+				c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: tt.roles})
+			}, middleware.RequireRole(RoleAdmin))
+			router.GET("/api/v1/admin/users/:id/tokens", handler.ListUserTokens)
+			router.DELETE("/api/v1/admin/users/:id/tokens", handler.RevokeUserSessions)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, "/api/v1/admin/users/"+tt.userID+"/tokens", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.checkResponse != nil {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				tt.checkResponse(t, response)
+			}
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+// recordingAuditLogger captures audit events in memory
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Record(ctx context.Context, event audit.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestHandler_BulkRoles(t *testing.T) {
+	tests := []struct {
+		name           string
+		remove         bool
+		body           string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		expectedCode   string
+		changed        int
+		auditTargets   []uint
+	}{
+		{
+			name: "assign audits each changed user",
+			body: `{"user_ids":[2,3,99]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("BulkAssignRole", mock.Anything, RoleAdmin, []uint{2, 3, 99}, false).Return([]RoleChangeResult{
+					{UserID: 2, Status: RoleChangeAssigned},
+					{UserID: 3, Status: RoleChangeUnchanged},
+					{UserID: 99, Status: RoleChangeNotFound},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			changed:        1,
+			auditTargets:   []uint{2},
+		},
+		{
+			name:   "dry run is not audited",
+			remove: true,
+			body:   `{"user_ids":[2],"dry_run":true}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("BulkRemoveRole", mock.Anything, RoleAdmin, []uint{2}, true).Return([]RoleChangeResult{
+					{UserID: 2, Status: RoleChangeRemoved},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			changed:        1,
+		},
+		{
+			name:   "last admin",
+			remove: true,
+			body:   `{"user_ids":[1,2]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("BulkRemoveRole", mock.Anything, RoleAdmin, []uint{1, 2}, false).Return(nil, ErrLastAdmin)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   apiErrors.CodeConflict,
+		},
+		{
+			name: "unknown role",
+			body: `{"user_ids":[2]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("BulkAssignRole", mock.Anything, RoleAdmin, []uint{2}, false).Return(nil, ErrInvalidRole)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:           "no user IDs",
+			body:           `{"user_ids":[]}`,
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+		{
+			name:           "too many user IDs",
+			body:           `{"user_ids":[1,2,3,4]}`,
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apiErrors.CodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			tt.setupMocks(mockService)
+			auditLogger := &recordingAuditLogger{}
+
+			handler := NewHandler(mockService, &MockAuthService{}, WithAuditLogger(auditLogger), WithBulkMaxUsers(3))
+			serve, action := handler.AssignRole, audit.ActionUserRoleAssign
+			if tt.remove {
+				serve, action = handler.RemoveRole, audit.ActionUserRoleRemove
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/roles/admin/assign", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "role", Value: RoleAdmin}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			serve(c)
+			apiErrors.ErrorHandler()(c)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			} else {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, RoleAdmin, data["role"])
+				assert.Equal(t, float64(tt.changed), data["changed"])
+				assert.Len(t, data["results"], len(mockService.Calls[0].ReturnArguments.Get(0).([]RoleChangeResult)))
+			}
+
+			var targets []uint
+			for _, event := range auditLogger.events {
+				assert.Equal(t, action, event.Action)
+				assert.Equal(t, uint(1), event.ActorID)
+				targets = append(targets, *event.TargetID)
+			}
+			assert.Equal(t, tt.auditTargets, targets)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PromoteUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		expectAudit    bool
+	}{
+		{
+			name:   "successful promotion is audited",
+			userID: "2",
+			setupMocks: func(ms *MockService) {
+				ms.On("PromoteToAdmin", mock.Anything, uint(2)).Return(nil)
+				ms.On("GetUserByID", mock.Anything, uint(2)).Return(&User{
+					ID:    2,
+					Name:  "Jane Doe",
+					Email: "jane@example.com",
+					Roles: []Role{{Name: RoleUser}, {Name: RoleAdmin}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectAudit:    true,
+		},
+		{
+			name:   "user not found is not audited",
+			userID: "99",
+			setupMocks: func(ms *MockService) {
+				ms.On("PromoteToAdmin", mock.Anything, uint(99)).Return(ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid user ID",
+			userID:         "abc",
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			tt.setupMocks(mockService)
+			auditLogger := &recordingAuditLogger{}
+
+			handler := NewHandler(mockService, &MockAuthService{}, WithAuditLogger(auditLogger))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/users/"+tt.userID+"/promote", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			handler.PromoteUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectAudit {
+				assert.Len(t, auditLogger.events, 1)
+				event := auditLogger.events[0]
+				assert.Equal(t, uint(1), event.ActorID)
+				assert.Equal(t, audit.ActionUserPromote, event.Action)
+				if assert.NotNil(t, event.TargetID) {
+					assert.Equal(t, uint(2), *event.TargetID)
+				}
+			} else {
+				assert.Empty(t, auditLogger.events)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AdminUpdateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		body           string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		expectedRoles  []interface{}
+		expectAudit    bool
+	}{
+		{
+			name:   "admin edits another user and changes roles",
+			userID: "2",
+			body:   `{"name":"Jane Updated","roles":["user","admin"]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserAsAdmin", mock.Anything, uint(2), AdminUpdateUserRequest{Name: "Jane Updated", Roles: []string{RoleUser, RoleAdmin}}).Return(&User{
+					ID:    2,
+					Name:  "Jane Updated",
+					Email: "jane@example.com",
+					Roles: []Role{{Name: RoleUser}, {Name: RoleAdmin}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRoles:  []interface{}{RoleUser, RoleAdmin},
+			expectAudit:    true,
+		},
+		{
+			name:   "duplicate email",
+			userID: "2",
+			body:   `{"email":"taken@example.com"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserAsAdmin", mock.Anything, uint(2), mock.Anything).Return(nil, ErrEmailExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "unknown role",
+			userID: "2",
+			body:   `{"roles":["owner"]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserAsAdmin", mock.Anything, uint(2), mock.Anything).Return(nil, ErrInvalidRole)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "last admin",
+			userID: "1",
+			body:   `{"roles":["user"]}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserAsAdmin", mock.Anything, uint(1), mock.Anything).Return(nil, ErrLastAdmin)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "user not found",
+			userID: "99",
+			body:   `{"name":"Nobody"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("UpdateUserAsAdmin", mock.Anything, uint(99), mock.Anything).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "empty roles list",
+			userID:         "2",
+			body:           `{"roles":[]}`,
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid user ID",
+			userID:         "abc",
+			body:           `{"name":"Jane Updated"}`,
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			tt.setupMocks(mockService)
+			auditLogger := &recordingAuditLogger{}
+
+			handler := NewHandler(mockService, &MockAuthService{}, WithAuditLogger(auditLogger))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PUT", "/api/v1/admin/users/"+tt.userID, strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			handler.AdminUpdateUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedRoles != nil {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "Jane Updated", data["name"])
+				assert.Equal(t, tt.expectedRoles, data["roles"])
+			}
+			if tt.expectAudit {
+				require.Len(t, auditLogger.events, 1)
+				assert.Equal(t, audit.ActionUserUpdate, auditLogger.events[0].Action)
+				assert.Equal(t, []string{RoleUser, RoleAdmin}, auditLogger.events[0].Metadata["roles"])
+			} else {
+				assert.Empty(t, auditLogger.events)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeactivateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		setupMocks     func(*MockService, *MockAuthService)
+		expectedStatus int
+		expectAudit    bool
+	}{
+		{
+			name:   "deactivation revokes tokens and is audited",
+			userID: "2",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("SetUserActive", mock.Anything, uint(2), false).Return(&User{ID: 2, Email: "jane@example.com", Active: false}, nil)
+				mas.On("CacheUserStatus", uint(2), false).Return()
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(2), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectAudit:    true,
+		},
+		{
+			name:           "own account",
+			userID:         "1",
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid user ID",
+			userID:         "abc",
+			setupMocks:     func(ms *MockService, mas *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "user not found",
+			userID: "99",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("SetUserActive", mock.Anything, uint(99), false).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "revocation error",
+			userID: "2",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("SetUserActive", mock.Anything, uint(2), false).Return(&User{ID: 2}, nil)
+				mas.On("CacheUserStatus", uint(2), false).Return()
+				mas.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(0), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			tt.setupMocks(mockService, mockAuthService)
+			auditLogger := &recordingAuditLogger{}
+
+			handler := NewHandler(mockService, mockAuthService, WithAuditLogger(auditLogger))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+tt.userID+"/deactivate", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.userID}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			handler.DeactivateUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectAudit {
+				require.Len(t, auditLogger.events, 1)
+				assert.Equal(t, audit.ActionUserDeactivate, auditLogger.events[0].Action)
+				assert.Equal(t, int64(2), auditLogger.events[0].Metadata["revoked_refresh_tokens"])
+
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, false, response["data"].(map[string]interface{})["active"])
+			} else {
+				assert.Empty(t, auditLogger.events)
+			}
+
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_StatusCacheWaitsForCommit(t *testing.T) {
+	deactivate := func(mockService *MockService, mockAuthService *MockAuthService) (int, func()) {
+		handler := NewHandler(mockService, mockAuthService)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		ctx, runCommitHooks := db.WithCommitHooks(context.Background())
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/2/deactivate", nil).WithContext(ctx)
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+		handler.DeactivateUser(c)
+		apiErrors.ErrorHandler()(c)
+		return w.Code, runCommitHooks
+	}
+
+	t.Run("failed request leaves the cache alone", func(t *testing.T) {
+		mockService := &MockService{}
+		mockAuthService := &MockAuthService{}
+		mockService.On("SetUserActive", mock.Anything, uint(2), false).Return(&User{ID: 2}, nil)
+		mockAuthService.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(0), errors.New("database error"))
+
+		status, _ := deactivate(mockService, mockAuthService)
+
+		assert.Equal(t, http.StatusInternalServerError, status)
+		mockAuthService.AssertNotCalled(t, "CacheUserStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cache is updated after the commit", func(t *testing.T) {
+		mockService := &MockService{}
+		mockAuthService := &MockAuthService{}
+		mockService.On("SetUserActive", mock.Anything, uint(2), false).Return(&User{ID: 2}, nil)
+		mockAuthService.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(1), nil)
+		mockAuthService.On("CacheUserStatus", uint(2), false).Return()
+
+		status, runCommitHooks := deactivate(mockService, mockAuthService)
+
+		require.Equal(t, http.StatusOK, status)
+		mockAuthService.AssertNotCalled(t, "CacheUserStatus", mock.Anything, mock.Anything)
+		runCommitHooks()
+		mockAuthService.AssertCalled(t, "CacheUserStatus", uint(2), false)
+	})
+}
+
+func TestHandler_RestoreUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMocks     func(*MockService, *MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "restores and is audited",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RestoreUser", mock.Anything, uint(2)).Return(&User{ID: 2, Email: "jane@example.com", Active: true}, nil)
+				mas.On("CacheUserStatus", uint(2), true).Return()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "user not found",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RestoreUser", mock.Anything, uint(2)).Return(nil, ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "user not deleted",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RestoreUser", mock.Anything, uint(2)).Return(nil, ErrUserNotDeleted)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "email claimed",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RestoreUser", mock.Anything, uint(2)).Return(nil, ErrEmailExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "license full",
+			setupMocks: func(ms *MockService, mas *MockAuthService) {
+				ms.On("RestoreUser", mock.Anything, uint(2)).Return(nil, ErrLicenseLimitReached)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			mockAuthService := &MockAuthService{}
+			tt.setupMocks(mockService, mockAuthService)
+			auditLogger := &recordingAuditLogger{}
+
+			handler := NewHandler(mockService, mockAuthService, WithAuditLogger(auditLogger))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/2/restore", nil)
+			c.Params = gin.Params{{Key: "id", Value: "2"}}
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+
+			handler.RestoreUser(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.Len(t, auditLogger.events, 1)
+				assert.Equal(t, audit.ActionUserRestore, auditLogger.events[0].Action)
+				assert.Equal(t, uint(2), *auditLogger.events[0].TargetID)
+			} else {
+				assert.Empty(t, auditLogger.events)
+			}
+
+			mockService.AssertExpectations(t)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeactivateReactivateLogin(t *testing.T) {
+	userService := NewService(NewRepository(setupTestDB(t)))
+	u, err := userService.RegisterUser(context.Background(), RegisterRequest{Name: "Jane Doe", Email: "jane@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	mockAuthService := &MockAuthService{}
+	mockAuthService.On("RevokeAllUserTokens", mock.Anything, u.ID).Return(int64(1), nil)
+	mockAuthService.On("CacheUserStatus", u.ID, false).Return().Once()
+	mockAuthService.On("CacheUserStatus", u.ID, true).Return().Once()
+	mockAuthService.On("GenerateTokenPair", mock.Anything, u.ID, u.Email, u.Name).
+		Return(&auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}, nil)
+	handler := NewHandler(userService, mockAuthService)
+
+	adminAction := func(action func(*gin.Context)) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(u.ID)}}
+		c.Set(auth.KeyUser, &auth.Claims{UserID: u.ID + 1, Roles: []string{RoleAdmin}})
+		action(c)
+		apiErrors.ErrorHandler()(c)
+		return w.Code
+	}
+	login := func() int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"jane@example.com","password":"password123"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.Login(c)
+		apiErrors.ErrorHandler()(c)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, adminAction(handler.DeactivateUser))
+	assert.Equal(t, http.StatusForbidden, login(), "deactivated users cannot log in")
+
+	require.Equal(t, http.StatusOK, adminAction(handler.ReactivateUser))
+	assert.Equal(t, http.StatusOK, login(), "reactivated users can log in again")
+
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestHandler_SelfServiceUpdateIsNotAudited(t *testing.T) {
+	mockService := &MockService{}
+	mockService.On("UpdateUser", mock.Anything, uint(1), mock.AnythingOfType("api.UpdateUserRequest")).
+		Return(&User{ID: 1, Name: "John Updated", Email: "john@example.com"}, nil)
+	auditLogger := &recordingAuditLogger{}
+
+	handler := NewHandler(mockService, &MockAuthService{}, WithAuditLogger(auditLogger))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/users/1", bytes.NewBufferString(`{"name":"John Updated"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+
+	handler.UpdateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, auditLogger.events)
+}
+
+func TestHandler_GetUser_XMLNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(ms *MockService) *gin.Engine {
+		handler := NewHandler(ms, new(MockAuthService))
+		router := gin.New()
+		router.Use(apiErrors.ErrorHandler())
+		router.GET("/api/v1/users/:id", func(c *gin.Context) {
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+			handler.GetUser(c)
+		})
+		return router
+	}
+
+	t.Run("xml requested", func(t *testing.T) {
+		ms := new(MockService)
+		ms.On("GetUserByID", mock.Anything, uint(1)).
+			Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com", Roles: []Role{{Name: RoleUser}}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+		req.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+		setup(ms).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+		assert.Contains(t, w.Body.String(), "<UserResponse>")
+
+		var body UserResponse
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, uint(1), body.ID)
+		assert.Equal(t, "John Doe", body.Name)
+		assert.Equal(t, "john@example.com", body.Email)
+		assert.Equal(t, []string{RoleUser}, body.Roles)
+	})
+
+	t.Run("json stays the default", func(t *testing.T) {
+		ms := new(MockService)
+		ms.On("GetUserByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe"}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+		req.Header.Set("Accept", "*/*")
+		w := httptest.NewRecorder()
+		setup(ms).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	})
+
+	t.Run("errors are negotiated too", func(t *testing.T) {
+		ms := new(MockService)
+		ms.On("GetUserByID", mock.Anything, uint(1)).Return(nil, ErrUserNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+		req.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+		setup(ms).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+
+		var body apiErrors.ErrorInfo
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "error", body.XMLName.Local)
+		assert.Equal(t, apiErrors.CodeNotFound, body.Code)
+	})
+}
+
+func TestHandler_UpdateUser_UnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(ms *MockService, strict bool) *gin.Engine {
+		handler := NewHandler(ms, new(MockAuthService))
+		router := gin.New()
+		router.Use(apiErrors.ErrorHandler())
+		router.Use(apiErrors.JSONDecoding(apiErrors.JSONDecodingConfig{Strict: strict}))
+		router.PUT("/api/v1/users/:id", func(c *gin.Context) {
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+			handler.UpdateUser(c)
+		})
+		return router
+	}
+	body := `{"name":"John Updated","nickname":"johnny"}`
+
+	t.Run("rejected when strict", func(t *testing.T) {
+		ms := new(MockService)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/users/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setup(ms, true).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		errorInfo := response["error"].(map[string]interface{})
+		assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
+		assert.Equal(t, "Validation failed", errorInfo["message"])
+		assert.Equal(t, map[string]interface{}{"nickname": "nickname is not a known field"}, errorInfo["details"])
+		ms.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("accepted when lenient", func(t *testing.T) {
+		ms := new(MockService)
+		ms.On("UpdateUser", mock.Anything, uint(1), UpdateUserRequest{Name: "John Updated"}).
+			Return(&User{ID: 1, Name: "John Updated", Email: "john@example.com"}, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/users/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setup(ms, false).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		ms.AssertExpectations(t)
+	})
+}
+
+func TestHandler_ConfirmEmailChange(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMocks     func(*MockService)
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{
+			name: "successful confirmation",
+			body: `{"token":"abc"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("ConfirmEmailChange", mock.Anything, uint(1), "abc").
+					Return(&User{ID: 1, Name: "John Doe", Email: "new@example.com"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			body:           `{}`,
+			setupMocks:     func(ms *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid token",
+			body: `{"token":"abc"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("ConfirmEmailChange", mock.Anything, uint(1), "abc").Return(nil, ErrInvalidEmailChangeToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "Invalid email change token",
+		},
+		{
+			name: "expired token",
+			body: `{"token":"abc"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("ConfirmEmailChange", mock.Anything, uint(1), "abc").Return(nil, ErrEmailChangeExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    "Email change request has expired",
+		},
+		{
+			name: "email taken in the meantime",
+			body: `{"token":"abc"}`,
+			setupMocks: func(ms *MockService) {
+				ms.On("ConfirmEmailChange", mock.Anything, uint(1), "abc").Return(nil, ErrEmailExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedMsg:    "Email already in use",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			tt.setupMocks(mockService)
+			handler := NewHandler(mockService, &MockAuthService{})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/users/me/confirm-email-change", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+
+			handler.ConfirmEmailChange(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedMsg != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedMsg)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CancelEmailChange(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"successful cancellation", nil, http.StatusOK},
+		{"no pending change", ErrNoPendingEmailChange, http.StatusBadRequest},
+		{"user not found", ErrUserNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockService{}
+			if tt.err != nil {
+				mockService.On("CancelEmailChange", mock.Anything, uint(1)).Return(nil, tt.err)
+			} else {
+				mockService.On("CancelEmailChange", mock.Anything, uint(1)).
+					Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+			}
+			handler := NewHandler(mockService, &MockAuthService{})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/users/me/email-change", nil)
+			c.Set(auth.KeyUser, &auth.Claims{UserID: 1})
+
+			handler.CancelEmailChange(c)
+			apiErrors.ErrorHandler()(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AuthMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := prometheus.NewRegistry()
+	metrics := middleware.NewAuthMetrics(middleware.MetricsConfig{Registerer: registry})
+
+	mockService := &MockService{}
+	mockAuthService := &MockAuthService{}
+	u := &User{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	mockService.On("AuthenticateUser", mock.Anything, LoginRequest{Email: "john@example.com", Password: "wrong-password"}).Return(nil, ErrInvalidCredentials)
+	mockService.On("AuthenticateUser", mock.Anything, LoginRequest{Email: "john@example.com", Password: "password123"}).Return(u, nil)
+	mockAuthService.On("GenerateTokenPair", mock.Anything, uint(1), "john@example.com", "John Doe").Return(&auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil)
+	mockAuthService.On("RefreshAccessToken", mock.Anything, "refresh").Return(&auth.TokenPair{AccessToken: "access2", RefreshToken: "refresh2", TokenType: "Bearer"}, nil)
+	mockAuthService.On("RefreshAccessToken", mock.Anything, "stolen").Return(nil, auth.ErrTokenReuse)
+
+	handler := NewHandler(mockService, mockAuthService, WithAuthMetrics(metrics))
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.POST("/auth/login", handler.Login)
+	router.POST("/auth/refresh", handler.RefreshToken)
+
+	post := func(path, body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("/auth/login", `{"email":"john@example.com","password":"wrong-password"}`))
+	assert.Equal(t, http.StatusOK, post("/auth/login", `{"email":"john@example.com","password":"password123"}`))
+	assert.Equal(t, http.StatusOK, post("/auth/refresh", `{"refresh_token":"refresh"}`))
+	assert.Equal(t, http.StatusForbidden, post("/auth/refresh", `{"refresh_token":"stolen"}`))
+
+	expected := `
+# HELP auth_logins_total Total number of password login attempts by result.
+# TYPE auth_logins_total counter
+auth_logins_total{result="account_disabled"} 0
+auth_logins_total{result="error"} 0
+auth_logins_total{result="invalid_credentials"} 1
+auth_logins_total{result="shed"} 0
+auth_logins_total{result="success"} 1
+# HELP auth_token_refreshes_total Total number of refresh token exchanges by result.
+# TYPE auth_token_refreshes_total counter
+auth_token_refreshes_total{result="concurrent"} 0
+auth_token_refreshes_total{result="error"} 0
+auth_token_refreshes_total{result="invalid"} 0
+auth_token_refreshes_total{result="reused"} 1
+auth_token_refreshes_total{result="revoked"} 0
+auth_token_refreshes_total{result="success"} 1
+`
+	assert.NoError(t, promtestutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func TestHandler_EventBus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bus := events.NewBus(0)
+	userEvents, unsubscribe := bus.Subscribe(2)
+	defer unsubscribe()
+	_, feed, unsubscribeFeed := bus.SubscribeAll(0)
+	defer unsubscribeFeed()
+
+	mockService := &MockService{}
+	mockAuthService := &MockAuthService{}
+	mockService.On("UpdateUser", mock.Anything, uint(2), mock.AnythingOfType("api.UpdateUserRequest")).
+		Return(&User{ID: 2, Name: "Jane Updated", Email: "jane@example.com"}, nil)
+	mockService.On("GetUserByID", mock.Anything, uint(2)).Return(&User{ID: 2}, nil)
+	mockAuthService.On("RevokeAllUserTokens", mock.Anything, uint(2)).Return(int64(3), nil)
+
+	handler := NewHandler(mockService, mockAuthService, WithEventBus(bus))
+
+	serve := func(method, path, body string, handle gin.HandlerFunc) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+		handle(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/admin/users/2", `{"name":"Jane Updated"}`, handler.UpdateUser))
+	require.Len(t, userEvents, 1)
+	updated := <-userEvents
+	assert.Equal(t, events.TypeUserUpdated, updated.Type)
+	assert.Equal(t, "Jane Updated", updated.Data.(UserResponse).Name)
+	require.Len(t, feed, 2, "admin feeds also get the audit entry")
+	audited := <-feed
+	assert.Equal(t, events.TypeAuditRecorded, audited.Type)
+	assert.Equal(t, audit.ActionUserUpdate, audited.Data.(audit.Event).Action)
+	assert.Equal(t, events.TypeUserUpdated, (<-feed).Type)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/admin/users/2/revoke-sessions", "", handler.RevokeUserSessions))
+	require.Len(t, userEvents, 1)
+	revoked := <-userEvents
+	assert.Equal(t, events.TypeSessionRevoked, revoked.Type)
+	assert.Equal(t, map[string]any{"revoked_refresh_tokens": int64(3)}, revoked.Data)
+}
+
+// TestHandlers_UseTypedResponses keeps ad hoc gin.H bodies out of the user and
+// auth handlers, so every response has a schema in the generated docs
+func TestHandlers_UseTypedResponses(t *testing.T) {
+	var files []string
+	for _, dir := range []string{".", "../auth", "../auth/oauth"} {
+		matches, err := filepath.Glob(filepath.Join(dir, "*handler*.go"))
+		require.NoError(t, err)
+		for _, match := range matches {
+			if !strings.HasSuffix(match, "_test.go") {
+				files = append(files, match)
+			}
+		}
+	}
+	require.NotEmpty(t, files)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			if sel, ok := node.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "gin" && sel.Sel.Name == "H" {
+					t.Errorf("%s: use a typed response instead of gin.H", fset.Position(sel.Pos()))
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestHandler_AdminActionOnOwnAccountIsAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockService{}
+	mockService.On("UpdateUserPartial", mock.Anything, uint(1), mock.AnythingOfType("user.PatchUserRequest")).
+		Return(&User{ID: 1, Name: "Admin Patched", Email: "admin@example.com"}, nil)
+	auditLogger := &recordingAuditLogger{}
+	handler := NewHandler(mockService, &MockAuthService{}, WithAuditLogger(auditLogger))
+
+	r := gin.New()
+	r.Use(apiErrors.ErrorHandler(), func(c *gin.Context) {
+		c.Set(auth.KeyUser, &auth.Claims{UserID: 1, Roles: []string{RoleAdmin}})
+	})
+	r.PATCH("/api/v1/users/:id", handler.PatchUser)
+	r.PATCH("/api/v1/admin/users/:id", handler.PatchUser)
+
+	patch := func(path string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"name":"Admin Patched"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	patch("/api/v1/users/1")
+	assert.Empty(t, auditLogger.events, "self-service route")
+
+	patch("/api/v1/admin/users/1")
+	require.Len(t, auditLogger.events, 1)
+	assert.Equal(t, audit.ActionUserUpdate, auditLogger.events[0].Action)
+	assert.Equal(t, uint(1), auditLogger.events[0].ActorID)
+	assert.Equal(t, uint(1), *auditLogger.events[0].TargetID)
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -35,6 +36,14 @@ func (m *MockService) GetUserByID(ctx context.Context, id uint) (*User, error) {
 	return args.Get(0).(*User), args.Error(1)
 }
 
+func (m *MockService) GetUserByIDIncludingDeleted(ctx context.Context, id uint) (*User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
 func (m *MockService) UpdateUser(ctx context.Context, id uint, req UpdateUserRequest) (*User, error) {
 	args := m.Called(ctx, id, req)
 	if args.Get(0) == nil {
@@ -43,6 +52,22 @@ func (m *MockService) UpdateUser(ctx context.Context, id uint, req UpdateUserReq
 	return args.Get(0).(*User), args.Error(1)
 }
 
+func (m *MockService) UpdateUserPartial(ctx context.Context, id uint, patch PatchUserRequest) (*User, error) {
+	args := m.Called(ctx, id, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) UpdateUserAsAdmin(ctx context.Context, id uint, req AdminUpdateUserRequest) (*User, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
 func (m *MockService) DeleteUser(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -56,11 +81,97 @@ func (m *MockService) ListUsers(ctx context.Context, filters UserFilterParams, p
 	return args.Get(0).([]User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockService) CountUsersByRole(ctx context.Context, filters UserFilterParams) (map[string]int64, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockService) RestoreUser(ctx context.Context, id uint) (*User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) SetUserActive(ctx context.Context, userID uint, active bool) (*User, error) {
+	args := m.Called(ctx, userID, active)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) RecordLogin(ctx context.Context, userID uint) {
+	m.Called(ctx, userID)
+}
+
+func (m *MockService) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockService) PromoteToAdmin(ctx context.Context, userID uint) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockService) BootstrapAdmin(ctx context.Context, req RegisterRequest) (*User, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) BulkAssignRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error) {
+	args := m.Called(ctx, roleName, userIDs, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]RoleChangeResult), args.Error(1)
+}
+
+func (m *MockService) BulkRemoveRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error) {
+	args := m.Called(ctx, roleName, userIDs, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]RoleChangeResult), args.Error(1)
+}
+
+func (m *MockService) FindOrCreateOAuthUser(ctx context.Context, email, name string) (*User, error) {
+	args := m.Called(ctx, email, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) ConfirmEmailChange(ctx context.Context, userID uint, token string) (*User, error) {
+	args := m.Called(ctx, userID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) CancelEmailChange(ctx context.Context, userID uint) (*User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockService) SeatUsage(ctx context.Context) (SeatUsage, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(SeatUsage), args.Error(1)
+}
+
 // MockRepository is a mock implementation of the user repository for testing services
 type MockRepository struct {
 	mock.Mock
@@ -87,6 +198,19 @@ func (m *MockRepository) FindByID(ctx context.Context, id uint) (*User, error) {
 	return args.Get(0).(*User), args.Error(1)
 }
 
+func (m *MockRepository) FindByIDUnscoped(ctx context.Context, id uint) (*User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *MockRepository) EmailInUse(ctx context.Context, email string, excludeUserID uint) (bool, error) {
+	args := m.Called(ctx, email, excludeUserID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockRepository) Update(ctx context.Context, user *User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -97,7 +221,7 @@ func (m *MockRepository) Delete(ctx context.Context, id uint) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) ListAllUsers(ctx context.Context, filters UserFilterParams, page, perPage int) ([]User, int64, error) {
+func (m *MockRepository) ListAllUsers(ctx context.Context, filters UserListQuery, page, perPage int) ([]User, int64, error) {
 	args := m.Called(ctx, filters, page, perPage)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
@@ -105,6 +229,49 @@ func (m *MockRepository) ListAllUsers(ctx context.Context, filters UserFilterPar
 	return args.Get(0).([]User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockRepository) CountUsersByRole(ctx context.Context, filters UserListQuery) (map[string]int64, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockRepository) UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CountUsers(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) LockUserCount(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) LockAdminCount(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	args := m.Called(ctx, id, active)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateLastLogin(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
 func (m *MockRepository) AssignRole(ctx context.Context, userID uint, roleName string) error {
 	args := m.Called(ctx, userID, roleName)
 	return args.Error(0)
@@ -115,6 +282,32 @@ func (m *MockRepository) RemoveRole(ctx context.Context, userID uint, roleName s
 	return args.Error(0)
 }
 
+func (m *MockRepository) AssignRoleToUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	args := m.Called(ctx, roleID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RemoveRoleFromUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	args := m.Called(ctx, roleID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindExistingUserIDs(ctx context.Context, userIDs []uint) ([]uint, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockRepository) FindUserIDsWithRole(ctx context.Context, roleID uint, userIDs []uint) ([]uint, error) {
+	args := m.Called(ctx, roleID, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
 func (m *MockRepository) FindRoleByName(ctx context.Context, roleName string) (*Role, error) {
 	args := m.Called(ctx, roleName)
 	if args.Get(0) == nil {
@@ -4,23 +4,34 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-// User represents a user in the system
+// User represents a user in the system.
+// PendingEmail holds a requested email change awaiting verification; Email stays
+// the login identity until the change is confirmed. TenantID is the tenant the
+// user belongs to; an email is unique within its tenant.
 type User struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Name         string         `gorm:"not null" json:"name"`
-	Email        string         `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash string         `gorm:"not null" json:"-"`
-	Roles        []Role         `gorm:"many2many:user_roles;" json:"-"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                   uint           `gorm:"primaryKey" json:"id"`
+	TenantID             string         `gorm:"index;uniqueIndex:idx_users_tenant_email;not null;default:''" json:"-"`
+	Name                 string         `gorm:"not null" json:"name"`
+	Email                string         `gorm:"uniqueIndex:idx_users_tenant_email;not null" json:"email"`
+	PasswordHash         string         `gorm:"not null" json:"-"`
+	PendingEmail         string         `gorm:"index" json:"-"`
+	EmailChangeTokenHash string         `json:"-"`
+	EmailChangeExpiresAt *time.Time     `json:"-"`
+	Active               bool           `gorm:"not null;default:true" json:"active"`
+	LastLoginAt          *time.Time     `json:"-"`
+	Roles                []Role         `gorm:"many2many:user_roles;" json:"-"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// TableName specifies the table name for User model
-func (User) TableName() string {
-	return "users"
+// TableName specifies the table name for User model, users with
+// the configured table prefix
+func (User) TableName(namer schema.Namer) string {
+	return namer.TableName("user")
 }
 
 // HasRole checks if user has specific role
@@ -33,6 +44,23 @@ func (u *User) HasRole(roleName string) bool {
 	return false
 }
 
+// HasPassword reports whether the user can sign in with a password (OAuth-only accounts cannot)
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// HasPendingEmailChange reports whether an email change is awaiting verification at now
+func (u *User) HasPendingEmailChange(now time.Time) bool {
+	return u.PendingEmail != "" && u.EmailChangeExpiresAt != nil && now.Before(*u.EmailChangeExpiresAt)
+}
+
+// ClearPendingEmailChange discards any pending email change
+func (u *User) ClearPendingEmailChange() {
+	u.PendingEmail = ""
+	u.EmailChangeTokenHash = ""
+	u.EmailChangeExpiresAt = nil
+}
+
 // IsAdmin checks if user has admin role
 func (u *User) IsAdmin() bool {
 	return u.HasRole(RoleAdmin)
@@ -1,17 +1,20 @@
 package user
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 func TestUser_TableName(t *testing.T) {
 	user := User{}
-	tableName := user.TableName()
 
-	assert.Equal(t, "users", tableName)
+	assert.Equal(t, "users", user.TableName(schema.NamingStrategy{}))
+	assert.Equal(t, "tenant_users", user.TableName(schema.NamingStrategy{TablePrefix: "tenant_"}))
 }
 
 func TestToUserResponse_WithDates(t *testing.T) {
@@ -33,6 +36,37 @@ func TestToUserResponse_WithDates(t *testing.T) {
 	assert.NotEmpty(t, response.UpdatedAt)
 }
 
+func TestToAdminUserResponse_LastLogin(t *testing.T) {
+	lastLogin := time.Date(2025, 6, 1, 8, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	user := &User{ID: 1, Name: "John Doe", Email: "john@example.com", LastLoginAt: &lastLogin}
+
+	response := ToAdminUserResponse(user)
+	assert.Equal(t, uint(1), response.ID)
+	if assert.NotNil(t, response.LastLoginAt) {
+		assert.Equal(t, "2025-06-01T06:30:00Z", *response.LastLoginAt)
+	}
+
+	body, err := json.Marshal(ToUserResponse(user))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "last_login_at", "users do not see their own login tracking")
+
+	assert.Nil(t, ToAdminUserResponse(&User{ID: 2}).LastLoginAt)
+}
+
+func TestToAdminUserResponse_DeletedAt(t *testing.T) {
+	deletedAt := time.Date(2025, 6, 1, 8, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	user := &User{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+
+	response := ToAdminUserResponse(user)
+	if assert.NotNil(t, response.DeletedAt) {
+		assert.Equal(t, "2025-06-01T06:30:00Z", *response.DeletedAt)
+	}
+
+	body, err := json.Marshal(ToAdminUserResponse(&User{ID: 2}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"deleted_at":null`)
+}
+
 func TestUser_HasRole(t *testing.T) {
 	tests := []struct {
 		name     string
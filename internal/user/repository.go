@@ -3,25 +3,41 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-)
 
-type txKey struct{}
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
 
 // Repository defines user repository interface
 type Repository interface {
 	Create(ctx context.Context, user *User) error
 	FindByEmail(ctx context.Context, email string) (*User, error)
 	FindByID(ctx context.Context, id uint) (*User, error)
+	FindByIDUnscoped(ctx context.Context, id uint) (*User, error)
+	EmailInUse(ctx context.Context, email string, excludeUserID uint) (bool, error)
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id uint) error
-	ListAllUsers(ctx context.Context, filters UserFilterParams, page, perPage int) ([]User, int64, error)
+	Restore(ctx context.Context, id uint) error
+	SetActive(ctx context.Context, id uint, active bool) error
+	UpdateLastLogin(ctx context.Context, id uint, at time.Time) error
+	UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error
+	ListAllUsers(ctx context.Context, filters UserListQuery, page, perPage int) ([]User, int64, error)
+	CountUsersByRole(ctx context.Context, filters UserListQuery) (map[string]int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	LockUserCount(ctx context.Context) error
+	LockAdminCount(ctx context.Context) error
 	AssignRole(ctx context.Context, userID uint, roleName string) error
 	RemoveRole(ctx context.Context, userID uint, roleName string) error
+	AssignRoleToUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	RemoveRoleFromUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	FindExistingUserIDs(ctx context.Context, userIDs []uint) ([]uint, error)
+	FindUserIDsWithRole(ctx context.Context, roleID uint, userIDs []uint) ([]uint, error)
 	FindRoleByName(ctx context.Context, name string) (*Role, error)
 	GetUserRoles(ctx context.Context, userID uint) ([]Role, error)
 	Transaction(ctx context.Context, fn func(context.Context) error) error
@@ -38,14 +54,18 @@ func NewRepository(db *gorm.DB) Repository {
 
 // getDB returns the DB from context if in transaction, otherwise returns the repository's DB
 func (r *repository) getDB(ctx context.Context) *gorm.DB {
-	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
-		return tx
-	}
-	return r.db
+	return db.Conn(ctx, r.db)
+}
+
+// scoped returns the DB for ctx limited to the users of the tenant in ctx. It
+// suits queries on the users table alone; joined queries filter on users.tenant_id.
+func (r *repository) scoped(ctx context.Context) *gorm.DB {
+	return r.getDB(ctx).WithContext(ctx).Where("tenant_id = ?", tenant.FromContext(ctx))
 }
 
-// Create creates a new user in the database
+// Create creates a new user in the tenant in ctx
 func (r *repository) Create(ctx context.Context, user *User) error {
+	user.TenantID = tenant.FromContext(ctx)
 	result := r.getDB(ctx).WithContext(ctx).Create(user)
 	if result.Error != nil {
 		return result.Error
@@ -53,10 +73,10 @@ func (r *repository) Create(ctx context.Context, user *User) error {
 	return nil
 }
 
-// FindByEmail finds a user by email
+// FindByEmail finds a user by email, ignoring case
 func (r *repository) FindByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	result := r.getDB(ctx).WithContext(ctx).Preload("Roles").Where("email = ?", email).First(&user)
+	result := r.scoped(ctx).Preload("Roles").Where("LOWER(email) = LOWER(?)", email).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -69,7 +89,20 @@ func (r *repository) FindByEmail(ctx context.Context, email string) (*User, erro
 // FindByID finds a user by ID
 func (r *repository) FindByID(ctx context.Context, id uint) (*User, error) {
 	var user User
-	result := r.getDB(ctx).WithContext(ctx).Preload("Roles").First(&user, id)
+	result := r.scoped(ctx).Preload("Roles").First(&user, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// FindByIDUnscoped finds a user by ID, including soft-deleted users
+func (r *repository) FindByIDUnscoped(ctx context.Context, id uint) (*User, error) {
+	var user User
+	result := r.scoped(ctx).Unscoped().Preload("Roles").First(&user, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -79,10 +112,26 @@ func (r *repository) FindByID(ctx context.Context, id uint) (*User, error) {
 	return &user, nil
 }
 
+// EmailInUse reports whether another user has email, ignoring case, as their
+// address or as an unexpired pending change
+func (r *repository) EmailInUse(ctx context.Context, email string, excludeUserID uint) (bool, error) {
+	var count int64
+	result := r.scoped(ctx).Model(&User{}).
+		Where("id <> ?", excludeUserID).
+		Where("LOWER(email) = LOWER(?) OR (LOWER(pending_email) = LOWER(?) AND email_change_expires_at > ?)", email, email, time.Now()).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
 // Update updates a user in the database
 func (r *repository) Update(ctx context.Context, user *User) error {
 	// WHY: Save() syncs associations, potentially clearing roles
-	result := r.getDB(ctx).WithContext(ctx).Select("name", "email", "password_hash", "updated_at").Save(user)
+	result := r.scoped(ctx).
+		Select("name", "email", "password_hash", "pending_email", "email_change_token_hash", "email_change_expires_at", "updated_at").
+		Save(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -91,7 +140,20 @@ func (r *repository) Update(ctx context.Context, user *User) error {
 
 // Delete soft deletes a user from the database
 func (r *repository) Delete(ctx context.Context, id uint) error {
-	result := r.getDB(ctx).WithContext(ctx).Delete(&User{}, id)
+	result := r.scoped(ctx).Delete(&User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Restore undeletes a soft-deleted user; users that are not deleted are not found
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	result := r.scoped(ctx).Unscoped().Model(&User{}).Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{"deleted_at": nil, "updated_at": time.Now()})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -101,26 +163,41 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// SetActive activates or deactivates a user
+func (r *repository) SetActive(ctx context.Context, id uint, active bool) error {
+	result := r.scoped(ctx).Model(&User{}).Where("id = ?", id).
+		Updates(map[string]any{"active": active, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateLastLogin sets only last_login_at, leaving updated_at and every other
+// column alone so it never overwrites a concurrent profile edit
+func (r *repository) UpdateLastLogin(ctx context.Context, id uint, at time.Time) error {
+	return r.scoped(ctx).Model(&User{}).Where("id = ?", id).
+		UpdateColumn("last_login_at", at).Error
+}
+
+// UpdatePasswordHash replaces only password_hash, leaving updated_at alone
+// since the password itself did not change
+func (r *repository) UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error {
+	return r.scoped(ctx).Model(&User{}).Where("id = ?", id).
+		UpdateColumn("password_hash", passwordHash).Error
+}
+
 // ListAllUsers retrieves paginated list of users with filters
-func (r *repository) ListAllUsers(ctx context.Context, filters UserFilterParams, page, perPage int) ([]User, int64, error) {
+func (r *repository) ListAllUsers(ctx context.Context, filters UserListQuery, page, perPage int) ([]User, int64, error) {
 	var users []User
 	var total int64
 
-	query := r.getDB(ctx).WithContext(ctx).Model(&User{}).Preload("Roles")
-
-	if filters.Role != "" {
-		query = query.Joins("JOIN user_roles ON user_roles.user_id = users.id").
-			Joins("JOIN roles ON roles.id = user_roles.role_id").
-			Where("roles.name = ?", filters.Role)
-	}
-
-	if filters.Search != "" {
-		// WHY: Escape SQL LIKE wildcards to prevent incorrect matches
-		escapedSearch := strings.ReplaceAll(filters.Search, "%", "\\%")
-		escapedSearch = strings.ReplaceAll(escapedSearch, "_", "\\_")
-		searchPattern := "%" + escapedSearch + "%"
-		query = query.Where("users.name LIKE ? OR users.email LIKE ?", searchPattern, searchPattern)
-	}
+	tx := r.getDB(ctx).WithContext(ctx)
+	query := applyUserFilters(tx.Model(&User{}).Table(db.AliasTable(tx, "users")).Preload("Roles").
+		Where("users.tenant_id = ?", tenant.FromContext(ctx)), filters)
 
 	// WHY: Count distinct user IDs when using JOINs to avoid inflated totals
 	if err := query.Distinct("users.id").Count(&total).Error; err != nil {
@@ -129,21 +206,18 @@ func (r *repository) ListAllUsers(ctx context.Context, filters UserFilterParams,
 
 	offset := (page - 1) * perPage
 
-	// Defense-in-depth: Validate sort parameters at repository layer
-	validSorts := map[string]bool{
-		"name": true, "email": true, "created_at": true, "updated_at": true,
-	}
-	if !validSorts[filters.Sort] {
-		return nil, 0, errors.New("invalid sort field")
+	// WHY: The column name comes from a fixed table, never from the caller
+	column, ok := sortColumns[filters.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: unsupported field %q", ErrInvalidSort, filters.Sort)
 	}
-	if filters.Order != "asc" && filters.Order != "desc" {
-		return nil, 0, errors.New("invalid sort order")
+	if filters.Order != SortAsc && filters.Order != SortDesc {
+		return nil, 0, fmt.Errorf("%w: unsupported order %q", ErrInvalidSort, filters.Order)
 	}
 
-	// Use type-safe GORM clause to prevent SQL injection
 	orderColumn := clause.OrderByColumn{
-		Column: clause.Column{Table: "users", Name: filters.Sort},
-		Desc:   filters.Order == "desc",
+		Column: clause.Column{Table: "users", Name: column},
+		Desc:   filters.Order == SortDesc,
 	}
 
 	// WHY: Use Distinct with explicit columns to avoid duplicate users with JOINs
@@ -154,6 +228,104 @@ func (r *repository) ListAllUsers(ctx context.Context, filters UserFilterParams,
 	return users, total, nil
 }
 
+// CountUsersByRole counts the users matching the filters per role name in a
+// single grouped query; pagination and sorting are ignored
+func (r *repository) CountUsersByRole(ctx context.Context, filters UserListQuery) (map[string]int64, error) {
+	tx := r.getDB(ctx).WithContext(ctx)
+	matching := applyUserFilters(tx.Model(&User{}).Table(db.AliasTable(tx, "users")).Select("users.id").
+		Where("users.tenant_id = ?", tenant.FromContext(ctx)), filters)
+
+	var rows []struct {
+		Role  string
+		Count int64
+	}
+	err := tx.Table(db.Table(tx, "user_roles")+" AS ur").
+		Select("r.name AS role, COUNT(DISTINCT ur.user_id) AS count").
+		Joins("JOIN "+db.Table(tx, "roles")+" AS r ON r.id = ur.role_id").
+		Where("ur.user_id IN (?)", matching).
+		Group("r.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
+}
+
+// CountUsers counts the users that are not deleted, in every tenant since the
+// license covers the whole deployment
+func (r *repository) CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.getDB(ctx).WithContext(ctx).Model(&User{}).Count(&count).Error
+	return count, err
+}
+
+// userCountLockKey identifies the advisory lock serializing user creation on Postgres
+const userCountLockKey = 7310001
+
+// LockUserCount holds off other transactions that create users until the
+// current transaction ends, so a count taken after it stays accurate. It must
+// be called inside Transaction.
+func (r *repository) LockUserCount(ctx context.Context) error {
+	tx := r.getDB(ctx).WithContext(ctx)
+	if tx.Dialector.Name() == "postgres" {
+		return tx.Exec("SELECT pg_advisory_xact_lock(?)", userCountLockKey).Error
+	}
+	// WHY: SQLite has no advisory locks; a write that matches nothing takes the database write lock
+	return tx.Exec("UPDATE " + db.Table(tx, "users") + " SET id = id WHERE 1 = 0").Error
+}
+
+// adminCountLockKey identifies the advisory lock serializing admin role removals on Postgres
+const adminCountLockKey = 7310002
+
+// LockAdminCount holds off other transactions that take the admin role away
+// or bootstrap the first admin until the current transaction ends, so two of
+// them cannot each see another admin left and together remove the last one, or
+// each see none and both create one. It must be called inside Transaction,
+// before counting the admins.
+func (r *repository) LockAdminCount(ctx context.Context) error {
+	tx := r.getDB(ctx).WithContext(ctx)
+	if tx.Dialector.Name() == "postgres" {
+		return tx.Exec("SELECT pg_advisory_xact_lock(?)", adminCountLockKey).Error
+	}
+	// WHY: SQLite has no advisory locks; a write that matches nothing takes the database write lock
+	return tx.Exec("UPDATE " + db.Table(tx, "user_roles") + " SET user_id = user_id WHERE 1 = 0").Error
+}
+
+// applyUserFilters adds the role, search and registration filters of the user list to query
+func applyUserFilters(query *gorm.DB, filters UserListQuery) *gorm.DB {
+	if filters.Role != "" {
+		query = query.Joins("JOIN "+db.AliasTable(query, "user_roles")+" ON user_roles.user_id = users.id").
+			Joins("JOIN "+db.AliasTable(query, "roles")+" ON roles.id = user_roles.role_id").
+			Where("roles.name = ?", filters.Role)
+	}
+
+	if filters.Search != "" {
+		// WHY: Escape SQL LIKE wildcards to prevent incorrect matches
+		escapedSearch := strings.ReplaceAll(filters.Search, "%", "\\%")
+		escapedSearch = strings.ReplaceAll(escapedSearch, "_", "\\_")
+		searchPattern := "%" + escapedSearch + "%"
+		query = query.Where("users.name LIKE ? OR users.email LIKE ?", searchPattern, searchPattern)
+	}
+
+	if filters.RegisteredFrom != nil {
+		query = query.Where("users.created_at >= ?", *filters.RegisteredFrom)
+	}
+	if filters.RegisteredTo != nil {
+		query = query.Where("users.created_at <= ?", *filters.RegisteredTo)
+	}
+	if filters.LastLoginBefore != nil {
+		// WHY: Users who never signed in are the most dormant of all
+		query = query.Where("(users.last_login_at < ? OR users.last_login_at IS NULL)", *filters.LastLoginBefore)
+	}
+
+	return query
+}
+
 // AssignRole assigns a role to a user
 func (r *repository) AssignRole(ctx context.Context, userID uint, roleName string) error {
 	role, err := r.FindRoleByName(ctx, roleName)
@@ -166,8 +338,9 @@ func (r *repository) AssignRole(ctx context.Context, userID uint, roleName strin
 
 	// Use database-level conflict handling for race-safe, idempotent role assignment
 	// Works with both PostgreSQL and SQLite
-	return r.getDB(ctx).WithContext(ctx).Exec(`
-		INSERT INTO user_roles (user_id, role_id, assigned_at)
+	tx := r.getDB(ctx).WithContext(ctx)
+	return tx.Exec(`
+		INSERT INTO `+db.Table(tx, "user_roles")+` (user_id, role_id, assigned_at)
 		VALUES (?, ?, ?)
 		ON CONFLICT (user_id, role_id) DO NOTHING
 	`, userID, role.ID, time.Now()).Error
@@ -183,12 +356,74 @@ func (r *repository) RemoveRole(ctx context.Context, userID uint, roleName strin
 		return errors.New("role not found")
 	}
 
-	return r.getDB(ctx).WithContext(ctx).Exec(
-		"DELETE FROM user_roles WHERE user_id = ? AND role_id = ?",
+	tx := r.getDB(ctx).WithContext(ctx)
+	return tx.Exec(
+		"DELETE FROM "+db.Table(tx, "user_roles")+" WHERE user_id = ? AND role_id = ?",
 		userID, role.ID,
 	).Error
 }
 
+// AssignRoleToUsers gives an already resolved role to every user in one
+// statement; users that have it already are left alone
+func (r *repository) AssignRoleToUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	placeholders := make([]string, len(userIDs))
+	args := make([]any, 0, 3*len(userIDs))
+	for i, userID := range userIDs {
+		placeholders[i] = "(?, ?, ?)"
+		args = append(args, userID, roleID, now)
+	}
+
+	tx := r.getDB(ctx).WithContext(ctx)
+	return tx.Exec(
+		"INSERT INTO "+db.Table(tx, "user_roles")+" (user_id, role_id, assigned_at) VALUES "+strings.Join(placeholders, ", ")+
+			" ON CONFLICT (user_id, role_id) DO NOTHING",
+		args...,
+	).Error
+}
+
+// RemoveRoleFromUsers takes an already resolved role away from every user in one statement
+func (r *repository) RemoveRoleFromUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	tx := r.getDB(ctx).WithContext(ctx)
+	return tx.Exec(
+		"DELETE FROM "+db.Table(tx, "user_roles")+" WHERE role_id = ? AND user_id IN ?",
+		roleID, userIDs,
+	).Error
+}
+
+// FindExistingUserIDs returns the IDs among userIDs that belong to users that are not deleted
+func (r *repository) FindExistingUserIDs(ctx context.Context, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := r.scoped(ctx).Model(&User{}).
+		Where("id IN ?", userIDs).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindUserIDsWithRole returns the IDs among userIDs that have the role
+func (r *repository) FindUserIDsWithRole(ctx context.Context, roleID uint, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	tx := r.getDB(ctx).WithContext(ctx)
+	err := tx.Table(db.Table(tx, "user_roles")).
+		Where("role_id = ? AND user_id IN ?", roleID, userIDs).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 // FindRoleByName finds a role by name
 func (r *repository) FindRoleByName(ctx context.Context, name string) (*Role, error) {
 	var role Role
@@ -205,9 +440,9 @@ func (r *repository) FindRoleByName(ctx context.Context, name string) (*Role, er
 // GetUserRoles retrieves all roles for a user
 func (r *repository) GetUserRoles(ctx context.Context, userID uint) ([]Role, error) {
 	var roles []Role
-	err := r.getDB(ctx).WithContext(ctx).
-		Table("roles").
-		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+	tx := r.getDB(ctx).WithContext(ctx)
+	err := tx.Table(db.AliasTable(tx, "roles")).
+		Joins("JOIN "+db.AliasTable(tx, "user_roles")+" ON user_roles.role_id = roles.id").
 		Where("user_roles.user_id = ?", userID).
 		Find(&roles).Error
 	if err != nil {
@@ -216,11 +451,11 @@ func (r *repository) GetUserRoles(ctx context.Context, userID uint) ([]Role, err
 	return roles, nil
 }
 
-// Transaction executes a function within a database transaction
+// Transaction executes a function within a database transaction, nested as a
+// savepoint when ctx already carries one
 func (r *repository) Transaction(ctx context.Context, fn func(context.Context) error) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.getDB(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Inject transaction into context
-		txCtx := context.WithValue(ctx, txKey{}, tx)
-		return fn(txCtx)
+		return fn(db.WithTx(ctx, tx))
 	})
 }
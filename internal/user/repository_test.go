@@ -4,60 +4,18 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	require.NoError(t, err)
-
-	sqlDB, err := db.DB()
-	require.NoError(t, err)
-
-	_, err = sqlDB.Exec(`
-		CREATE TABLE users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			email TEXT UNIQUE NOT NULL,
-			password_hash TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			deleted_at DATETIME
-		);
-		CREATE INDEX idx_users_email ON users(email);
-		CREATE INDEX idx_users_deleted_at ON users(deleted_at);
-
-		CREATE TABLE roles (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT UNIQUE NOT NULL,
-			description TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-		CREATE INDEX idx_roles_name ON roles(name);
-
-		CREATE TABLE user_roles (
-			user_id INTEGER NOT NULL,
-			role_id INTEGER NOT NULL,
-			assigned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			PRIMARY KEY (user_id, role_id),
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-			FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
-		);
-		CREATE INDEX idx_user_roles_user_id ON user_roles(user_id);
-		CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
-
-		INSERT INTO roles (id, name, description) VALUES 
-			(1, 'user', 'Standard user with basic permissions'),
-			(2, 'admin', 'Administrator with full system access');
-	`)
-	require.NoError(t, err)
-
-	return db
+	return testutil.NewSQLiteDB(t)
 }
 
 func TestNewRepository(t *testing.T) {
@@ -126,6 +84,13 @@ func TestRepository_FindByEmail(t *testing.T) {
 		assert.Equal(t, "john@example.com", user.Email)
 	})
 
+	t.Run("user found ignoring case", func(t *testing.T) {
+		user, err := repo.FindByEmail(context.Background(), "John@Example.COM")
+		assert.NoError(t, err)
+		require.NotNil(t, user)
+		assert.Equal(t, originalUser.ID, user.ID)
+	})
+
 	t.Run("user not found", func(t *testing.T) {
 		user, err := repo.FindByEmail(context.Background(), "notfound@example.com")
 		assert.NoError(t, err)
@@ -221,6 +186,157 @@ func TestRepository_Delete(t *testing.T) {
 	assert.Nil(t, deletedUser)
 }
 
+func TestRepository_CountUsers(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	for _, email := range []string{"one@example.com", "two@example.com"} {
+		require.NoError(t, repo.Create(ctx, &User{Name: "Counted", Email: email, PasswordHash: "hashed_password"}))
+	}
+	deleted := &User{Name: "Deleted", Email: "deleted@example.com", PasswordHash: "hashed_password"}
+	require.NoError(t, repo.Create(ctx, deleted))
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+
+	err := repo.Transaction(ctx, func(txCtx context.Context) error {
+		if err := repo.LockUserCount(txCtx); err != nil {
+			return err
+		}
+		count, err := repo.CountUsers(txCtx)
+		assert.Equal(t, int64(2), count)
+		return err
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestRepository_LockAdminCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	admin := &User{Name: "Admin", Email: "admin@example.com", PasswordHash: "hashed_password"}
+	require.NoError(t, repo.Create(ctx, admin))
+	require.NoError(t, repo.AssignRole(ctx, admin.ID, RoleAdmin))
+
+	err := repo.Transaction(ctx, func(txCtx context.Context) error {
+		if err := repo.LockAdminCount(txCtx); err != nil {
+			return err
+		}
+		counts, err := repo.CountUsersByRole(txCtx, UserListQuery{})
+		assert.Equal(t, int64(1), counts[RoleAdmin])
+		return err
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestRepository_TenantIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	tenantA := tenant.NewContext(context.Background(), "tenant-a")
+	tenantB := tenant.NewContext(context.Background(), "tenant-b")
+
+	alice := &User{Name: "Alice", Email: "alice@example.com", PasswordHash: "hashed_password"}
+	require.NoError(t, repo.Create(tenantA, alice))
+	require.NoError(t, repo.AssignRole(tenantA, alice.ID, RoleUser))
+	bob := &User{Name: "Bob", Email: "bob@example.com", PasswordHash: "hashed_password"}
+	require.NoError(t, repo.Create(tenantB, bob))
+	require.NoError(t, repo.AssignRole(tenantB, bob.ID, RoleUser))
+
+	assert.Equal(t, "tenant-a", alice.TenantID, "Create assigns the tenant in the context")
+
+	t.Run("find", func(t *testing.T) {
+		found, err := repo.FindByEmail(tenantA, "bob@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = repo.FindByID(tenantA, bob.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = repo.FindByIDUnscoped(tenantA, bob.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = repo.FindByID(tenantB, bob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "Bob", found.Name)
+	})
+
+	t.Run("list and count", func(t *testing.T) {
+		users, total, err := repo.ListAllUsers(tenantA, UserListQuery{Sort: "id", Order: SortAsc}, 1, 20)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, users, 1)
+		assert.Equal(t, alice.ID, users[0].ID)
+
+		counts, err := repo.CountUsersByRole(tenantA, UserListQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{RoleUser: 1}, counts)
+
+		ids, err := repo.FindExistingUserIDs(tenantA, []uint{alice.ID, bob.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{alice.ID}, ids)
+	})
+
+	t.Run("email in use", func(t *testing.T) {
+		inUse, err := repo.EmailInUse(tenantA, "bob@example.com", 0)
+		require.NoError(t, err)
+		assert.False(t, inUse)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		bob.Name = "Mallory"
+		require.NoError(t, repo.Update(tenantA, bob))
+		assert.ErrorIs(t, repo.SetActive(tenantA, bob.ID, false), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Delete(tenantA, bob.ID), gorm.ErrRecordNotFound)
+
+		found, err := repo.FindByID(tenantB, bob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "Bob", found.Name)
+		assert.True(t, found.Active)
+	})
+
+	t.Run("default tenant", func(t *testing.T) {
+		_, total, err := repo.ListAllUsers(context.Background(), UserListQuery{Sort: "id", Order: SortAsc}, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestRepository_FindByIDUnscoped(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	user := &User{Name: "John Doe", Email: "john@example.com", PasswordHash: "hashed_password"}
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.AssignRole(ctx, user.ID, RoleUser))
+	require.NoError(t, repo.Delete(ctx, user.ID))
+
+	t.Run("deleted user found", func(t *testing.T) {
+		found, err := repo.FindByIDUnscoped(ctx, user.ID)
+		assert.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "john@example.com", found.Email)
+		assert.True(t, found.DeletedAt.Valid)
+		assert.Equal(t, []string{RoleUser}, found.GetRoleNames())
+
+		scoped, err := repo.FindByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, scoped, "the scoped lookup still hides deleted users")
+	})
+
+	t.Run("user never existed", func(t *testing.T) {
+		found, err := repo.FindByIDUnscoped(ctx, 999999)
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
 func TestRepository_Delete_NonExistentUser(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
@@ -331,6 +447,57 @@ func TestRepository_RemoveRole(t *testing.T) {
 	})
 }
 
+func TestRepository_RoleBatches(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+
+	var ids []uint
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		u := &User{Name: "User", Email: email, PasswordHash: "hash"}
+		require.NoError(t, repo.Create(ctx, u))
+		ids = append(ids, u.ID)
+	}
+	require.NoError(t, repo.AssignRole(ctx, ids[0], RoleUser))
+	require.NoError(t, repo.AssignRole(ctx, ids[0], RoleAdmin))
+	require.NoError(t, repo.Delete(ctx, ids[2]))
+
+	admin, err := repo.FindRoleByName(ctx, RoleAdmin)
+	require.NoError(t, err)
+
+	t.Run("existing users", func(t *testing.T) {
+		existing, err := repo.FindExistingUserIDs(ctx, append(ids, 999))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, ids[:2], existing, "deleted and unknown users are left out")
+	})
+
+	t.Run("assign is idempotent", func(t *testing.T) {
+		require.NoError(t, repo.AssignRoleToUsers(ctx, admin.ID, ids[:2]))
+
+		holders, err := repo.FindUserIDsWithRole(ctx, admin.ID, ids)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, ids[:2], holders)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, repo.RemoveRoleFromUsers(ctx, admin.ID, ids[:2]))
+
+		holders, err := repo.FindUserIDsWithRole(ctx, admin.ID, ids)
+		require.NoError(t, err)
+		assert.Empty(t, holders)
+
+		roles, err := repo.GetUserRoles(ctx, ids[0])
+		require.NoError(t, err)
+		require.Len(t, roles, 1, "only the given role is removed")
+		assert.Equal(t, RoleUser, roles[0].Name)
+	})
+
+	t.Run("empty batches are no-ops", func(t *testing.T) {
+		assert.NoError(t, repo.AssignRoleToUsers(ctx, admin.ID, nil))
+		assert.NoError(t, repo.RemoveRoleFromUsers(ctx, admin.ID, nil))
+	})
+}
+
 func TestRepository_GetUserRoles(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
@@ -392,7 +559,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("list all users with defaults", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
@@ -400,7 +567,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("filter by admin role", func(t *testing.T) {
-		filters := UserFilterParams{Role: RoleAdmin, Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Role: RoleAdmin, Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -409,7 +576,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("filter by user role", func(t *testing.T) {
-		filters := UserFilterParams{Role: RoleUser, Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Role: RoleUser, Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -418,7 +585,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("search by name", func(t *testing.T) {
-		filters := UserFilterParams{Search: "alice", Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Search: "alice", Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -427,7 +594,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("search by email", func(t *testing.T) {
-		filters := UserFilterParams{Search: "bob@", Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Search: "bob@", Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -436,7 +603,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("pagination - page 1", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "created_at", Order: "asc"}
+		filters := UserListQuery{Sort: "created_at", Order: "asc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 2)
 		assert.NoError(t, err)
 		assert.Len(t, users, 2)
@@ -444,7 +611,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("pagination - page 2", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "created_at", Order: "asc"}
+		filters := UserListQuery{Sort: "created_at", Order: "asc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 2, 2)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -452,7 +619,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("sort by email asc", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "email", Order: "asc"}
+		filters := UserListQuery{Sort: "email", Order: "asc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 10)
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
@@ -463,7 +630,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("no results for nonexistent search", func(t *testing.T) {
-		filters := UserFilterParams{Search: "nonexistent", Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Search: "nonexistent", Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Empty(t, users)
@@ -471,25 +638,23 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("invalid sort field", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "invalid_field", Order: "asc"}
+		filters := UserListQuery{Sort: "invalid_field", Order: "asc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
-		assert.Error(t, err)
-		assert.Equal(t, "invalid sort field", err.Error())
+		assert.ErrorIs(t, err, ErrInvalidSort)
 		assert.Nil(t, users)
 		assert.Equal(t, int64(0), total)
 	})
 
 	t.Run("invalid sort order", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "email", Order: "invalid"}
+		filters := UserListQuery{Sort: "email", Order: "invalid"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
-		assert.Error(t, err)
-		assert.Equal(t, "invalid sort order", err.Error())
+		assert.ErrorIs(t, err, ErrInvalidSort)
 		assert.Nil(t, users)
 		assert.Equal(t, int64(0), total)
 	})
 
 	t.Run("sort by name desc", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "name", Order: "desc"}
+		filters := UserListQuery{Sort: "name", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 10)
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
@@ -498,7 +663,7 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 
 	t.Run("sort by updated_at asc", func(t *testing.T) {
-		filters := UserFilterParams{Sort: "updated_at", Order: "asc"}
+		filters := UserListQuery{Sort: "updated_at", Order: "asc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 10)
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
@@ -506,6 +671,234 @@ func TestRepository_ListAllUsers(t *testing.T) {
 	})
 }
 
+func TestRepository_RegistrationFiltersAndRoleCounts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	jan := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 15, 12, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	seed := []struct {
+		name       string
+		email      string
+		registered time.Time
+		roles      []string
+	}{
+		{"Alice Admin", "alice@example.com", jan, []string{RoleUser, RoleAdmin}},
+		{"Bob User", "bob@example.com", jan, []string{RoleUser}},
+		{"Carol User", "carol@example.com", feb, []string{RoleUser}},
+		{"Dave Admin", "dave@example.com", feb, []string{RoleUser, RoleAdmin}},
+		{"Erin User", "erin@example.com", mar, []string{RoleUser}},
+		{"Frank Nobody", "frank@example.com", mar, nil},
+	}
+	for _, s := range seed {
+		u := &User{Name: s.name, Email: s.email, PasswordHash: "hash", CreatedAt: s.registered, UpdatedAt: s.registered}
+		require.NoError(t, repo.Create(ctx, u))
+		for _, role := range s.roles {
+			require.NoError(t, repo.AssignRole(ctx, u.ID, role))
+		}
+	}
+
+	deleted := &User{Name: "Gone Admin", Email: "gone@example.com", PasswordHash: "hash", CreatedAt: feb, UpdatedAt: feb}
+	require.NoError(t, repo.Create(ctx, deleted))
+	require.NoError(t, repo.AssignRole(ctx, deleted.ID, RoleAdmin))
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+
+	timePtr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name       string
+		filters    UserListQuery
+		wantEmails []string
+		wantCounts map[string]int64
+	}{
+		{
+			name:       "no filters",
+			filters:    UserListQuery{},
+			wantEmails: []string{"alice@example.com", "bob@example.com", "carol@example.com", "dave@example.com", "erin@example.com", "frank@example.com"},
+			wantCounts: map[string]int64{RoleUser: 5, RoleAdmin: 2},
+		},
+		{
+			name:       "registered from",
+			filters:    UserListQuery{RegisteredFrom: timePtr(feb)},
+			wantEmails: []string{"carol@example.com", "dave@example.com", "erin@example.com", "frank@example.com"},
+			wantCounts: map[string]int64{RoleUser: 3, RoleAdmin: 1},
+		},
+		{
+			name:       "registered to",
+			filters:    UserListQuery{RegisteredTo: timePtr(feb)},
+			wantEmails: []string{"alice@example.com", "bob@example.com", "carol@example.com", "dave@example.com"},
+			wantCounts: map[string]int64{RoleUser: 4, RoleAdmin: 2},
+		},
+		{
+			name:       "registration window",
+			filters:    UserListQuery{RegisteredFrom: timePtr(feb.Add(-time.Hour)), RegisteredTo: timePtr(feb.Add(time.Hour))},
+			wantEmails: []string{"carol@example.com", "dave@example.com"},
+			wantCounts: map[string]int64{RoleUser: 2, RoleAdmin: 1},
+		},
+		{
+			name:       "role filter counts the other roles of matching users",
+			filters:    UserListQuery{Role: RoleAdmin},
+			wantEmails: []string{"alice@example.com", "dave@example.com"},
+			wantCounts: map[string]int64{RoleUser: 2, RoleAdmin: 2},
+		},
+		{
+			name:       "search with registration window",
+			filters:    UserListQuery{Search: "admin", RegisteredFrom: timePtr(feb)},
+			wantEmails: []string{"dave@example.com"},
+			wantCounts: map[string]int64{RoleUser: 1, RoleAdmin: 1},
+		},
+		{
+			name:       "no matches",
+			filters:    UserListQuery{RegisteredFrom: timePtr(mar.Add(time.Hour))},
+			wantEmails: []string{},
+			wantCounts: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.Sort = SortByEmail
+			tt.filters.Order = SortAsc
+
+			// A page of one shows the counts ignore pagination
+			_, total, err := repo.ListAllUsers(ctx, tt.filters, 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.wantEmails)), total)
+
+			users, _, err := repo.ListAllUsers(ctx, tt.filters, 1, 100)
+			require.NoError(t, err)
+			emails := make([]string, 0, len(users))
+			for _, u := range users {
+				emails = append(emails, u.Email)
+			}
+			assert.Equal(t, tt.wantEmails, emails)
+
+			counts, err := repo.CountUsersByRole(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCounts, counts)
+		})
+	}
+}
+
+func TestRepository_UpdateLastLogin(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	created := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	u := &User{Name: "John Doe", Email: "john@example.com", PasswordHash: "hash", CreatedAt: created, UpdatedAt: created}
+	require.NoError(t, repo.Create(ctx, u))
+
+	// A profile edit lands while the login still holds the user it loaded earlier
+	stale, err := repo.FindByID(ctx, u.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&User{}).Where("id = ?", u.ID).UpdateColumn("name", "Johnny Doe").Error)
+
+	loginAt := time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+	require.NoError(t, repo.UpdateLastLogin(ctx, stale.ID, loginAt))
+
+	found, err := repo.FindByID(ctx, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.LastLoginAt)
+	assert.True(t, loginAt.Equal(*found.LastLoginAt))
+	assert.Equal(t, "Johnny Doe", found.Name, "the concurrent edit is not overwritten")
+	assert.Equal(t, "john@example.com", found.Email)
+	assert.Equal(t, "hash", found.PasswordHash)
+	assert.True(t, found.Active)
+	assert.True(t, created.Equal(found.UpdatedAt), "recording a login is not a profile update")
+}
+
+func TestRepository_ListAllUsers_LastLoginBefore(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	jan := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	jun := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, s := range []struct {
+		email     string
+		lastLogin *time.Time
+	}{
+		{"dormant@example.com", &jan},
+		{"recent@example.com", &jun},
+		{"never@example.com", nil},
+	} {
+		u := &User{Name: "User", Email: s.email, PasswordHash: "hash"}
+		require.NoError(t, repo.Create(ctx, u))
+		if s.lastLogin != nil {
+			require.NoError(t, repo.UpdateLastLogin(ctx, u.ID, *s.lastLogin))
+		}
+	}
+
+	cutoff := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	users, total, err := repo.ListAllUsers(ctx, UserListQuery{Sort: SortByEmail, Order: SortAsc, LastLoginBefore: &cutoff}, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, users, 2)
+	assert.Equal(t, "dormant@example.com", users[0].Email)
+	assert.Equal(t, "never@example.com", users[1].Email, "users who never signed in are dormant")
+
+	// The condition is grouped so it composes with the search filter
+	users, total, err = repo.ListAllUsers(ctx, UserListQuery{Sort: SortByEmail, Order: SortAsc, Search: "never", LastLoginBefore: &cutoff}, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "never@example.com", users[0].Email)
+}
+
+func TestRepository_ListAllUsers_SortFields(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+
+	// Insert in an order that differs from both name and email ordering
+	seed := []User{
+		{Name: "Bravo", Email: "charlie@example.com", PasswordHash: "hash"},
+		{Name: "Charlie", Email: "alpha@example.com", PasswordHash: "hash"},
+		{Name: "Alpha", Email: "bravo@example.com", PasswordHash: "hash"},
+	}
+	base := time.Now().Add(-time.Hour)
+	for i := range seed {
+		seed[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		seed[i].UpdatedAt = base.Add(time.Duration(len(seed)-i) * time.Minute)
+		require.NoError(t, repo.Create(context.Background(), &seed[i]))
+	}
+
+	tests := []struct {
+		sort     SortField
+		order    SortOrder
+		expected []string
+	}{
+		{SortByID, SortAsc, []string{"Bravo", "Charlie", "Alpha"}},
+		{SortByID, SortDesc, []string{"Alpha", "Charlie", "Bravo"}},
+		{SortByName, SortAsc, []string{"Alpha", "Bravo", "Charlie"}},
+		{SortByName, SortDesc, []string{"Charlie", "Bravo", "Alpha"}},
+		{SortByEmail, SortAsc, []string{"Charlie", "Alpha", "Bravo"}},
+		{SortByEmail, SortDesc, []string{"Bravo", "Alpha", "Charlie"}},
+		{SortByCreatedAt, SortAsc, []string{"Bravo", "Charlie", "Alpha"}},
+		{SortByCreatedAt, SortDesc, []string{"Alpha", "Charlie", "Bravo"}},
+		{SortByUpdatedAt, SortAsc, []string{"Alpha", "Charlie", "Bravo"}},
+		{SortByUpdatedAt, SortDesc, []string{"Bravo", "Charlie", "Alpha"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort)+" "+string(tt.order), func(t *testing.T) {
+			users, total, err := repo.ListAllUsers(context.Background(), UserListQuery{Sort: tt.sort, Order: tt.order}, 1, 10)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), total)
+
+			names := make([]string, len(users))
+			for i, u := range users {
+				names[i] = u.Name
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
 func TestRepository_Transaction(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
@@ -603,7 +996,7 @@ func TestRepository_ListAllUsers_ErrorCases(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("search with empty string", func(t *testing.T) {
-		filters := UserFilterParams{Search: "", Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Search: "", Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -611,7 +1004,7 @@ func TestRepository_ListAllUsers_ErrorCases(t *testing.T) {
 	})
 
 	t.Run("role filter with empty string", func(t *testing.T) {
-		filters := UserFilterParams{Role: "", Sort: "created_at", Order: "desc"}
+		filters := UserListQuery{Role: "", Sort: "created_at", Order: "desc"}
 		users, total, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
 		assert.NoError(t, err)
 		assert.Len(t, users, 1)
@@ -668,28 +1061,26 @@ func TestRepository_ListAllUsers_InvalidSortField(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
 
-	filters := UserFilterParams{
+	filters := UserListQuery{
 		Sort:  "invalid_field",
 		Order: "asc",
 	}
 
 	_, _, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid sort field")
+	assert.ErrorIs(t, err, ErrInvalidSort)
 }
 
 func TestRepository_ListAllUsers_InvalidSortOrder(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewRepository(db)
 
-	filters := UserFilterParams{
+	filters := UserListQuery{
 		Sort:  "name",
 		Order: "invalid_order",
 	}
 
 	_, _, err := repo.ListAllUsers(context.Background(), filters, 1, 20)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid sort order")
+	assert.ErrorIs(t, err, ErrInvalidSort)
 }
 
 func TestRepository_FindRoleByName_Error(t *testing.T) {
@@ -715,3 +1106,42 @@ func TestRepository_GetUserRoles_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, roles)
 }
+
+func TestRepository_EmailInUse(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	expiredAt := time.Now().Add(-time.Hour)
+	owner := &User{Name: "Owner", Email: "owner@example.com", PasswordHash: "hash"}
+	pending := &User{Name: "Pending", Email: "pending@example.com", PasswordHash: "hash", PendingEmail: "claimed@example.com", EmailChangeExpiresAt: &expiresAt}
+	expired := &User{Name: "Expired", Email: "expired@example.com", PasswordHash: "hash", PendingEmail: "stale@example.com", EmailChangeExpiresAt: &expiredAt}
+	for _, u := range []*User{owner, pending, expired} {
+		require.NoError(t, repo.Create(ctx, u))
+	}
+
+	tests := []struct {
+		name      string
+		email     string
+		excludeID uint
+		expected  bool
+	}{
+		{"current email of another user", "owner@example.com", pending.ID, true},
+		{"own current email", "owner@example.com", owner.ID, false},
+		{"pending email of another user", "claimed@example.com", owner.ID, true},
+		{"own pending email", "claimed@example.com", pending.ID, false},
+		{"expired pending email", "stale@example.com", owner.ID, false},
+		{"unused email", "free@example.com", owner.ID, false},
+		{"current email in another case", "Owner@Example.com", pending.ID, true},
+		{"pending email in another case", "CLAIMED@example.com", owner.ID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inUse, err := repo.EmailInUse(ctx, tt.email, tt.excludeID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, inUse)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
 
 const (
 	RoleUser  = "user"
@@ -16,7 +20,8 @@ type Role struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// TableName specifies the table name for Role model
-func (Role) TableName() string {
-	return "roles"
+// TableName specifies the table name for Role model, roles with
+// the configured table prefix
+func (Role) TableName(namer schema.Namer) string {
+	return namer.TableName("role")
 }
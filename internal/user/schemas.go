@@ -0,0 +1,25 @@
+package user
+
+import (
+	"embed"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// WHY: Struct tags keep the required, format and length rules; the schemas
+// report every mistyped field at once, keyed by its JSON path
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// registerSchema checks register bodies; the password policy caps passwords
+// at bcrypt's 72 bytes
+var registerSchema = mustLoadSchema("schemas/register.json")
+
+func mustLoadSchema(name string) *apiErrors.Schema {
+	source, err := schemaFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return apiErrors.MustCompileSchema(name, source)
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// seatCountTTL is how long a cached user count is trusted before creating a
+// user counts again; the count inside the creating transaction is always fresh
+const seatCountTTL = 10 * time.Second
+
+// SeatUsage is how many users exist against the license limit
+type SeatUsage struct {
+	Users int64
+	// MaxUsers is zero when the number of users is unlimited
+	MaxUsers int
+}
+
+// Remaining returns how many more users can be created, or -1 when unlimited
+func (u SeatUsage) Remaining() int64 {
+	if u.MaxUsers <= 0 {
+		return -1
+	}
+	return max(int64(u.MaxUsers)-u.Users, 0)
+}
+
+// seatCache remembers the user count so a full license rejects new users
+// without a COUNT per attempt
+type seatCache struct {
+	mu        sync.Mutex
+	count     int64
+	expiresAt time.Time
+}
+
+func (c *seatCache) get(now time.Time) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count, now.Before(c.expiresAt)
+}
+
+func (c *seatCache) set(count int64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count = count
+	c.expiresAt = now.Add(seatCountTTL)
+}
+
+func (c *seatCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiresAt = time.Time{}
+}
+
+// WithMaxUsers caps the users that are not deleted; zero means unlimited
+func WithMaxUsers(n int) ServiceOption {
+	return func(s *service) {
+		if n > 0 {
+			s.maxUsers = n
+		}
+	}
+}
+
+// checkSeats fails fast with ErrLicenseLimitReached when the cached count says
+// the license is full; createWithDefaultRole makes the authoritative check
+func (s *service) checkSeats(ctx context.Context) error {
+	if s.maxUsers == 0 {
+		return nil
+	}
+	count, ok := s.seats.get(s.now())
+	if !ok {
+		var err error
+		if count, err = s.repo.CountUsers(ctx); err != nil {
+			return repoError("count users", err)
+		}
+		s.seats.set(count, s.now())
+	}
+	if count >= int64(s.maxUsers) {
+		return ErrLicenseLimitReached
+	}
+	return nil
+}
+
+// reserveSeat counts the users under a lock held until the transaction of
+// txCtx ends, so concurrent creations cannot together exceed the limit
+func (s *service) reserveSeat(txCtx context.Context) (int64, error) {
+	if err := s.repo.LockUserCount(txCtx); err != nil {
+		return 0, repoError("lock user count", err)
+	}
+	count, err := s.repo.CountUsers(txCtx)
+	if err != nil {
+		return 0, repoError("count users", err)
+	}
+	if count >= int64(s.maxUsers) {
+		return count, ErrLicenseLimitReached
+	}
+	return count, nil
+}
+
+// SeatUsage returns the current user count and the license limit
+func (s *service) SeatUsage(ctx context.Context) (SeatUsage, error) {
+	count, err := s.repo.CountUsers(ctx)
+	if err != nil {
+		return SeatUsage{}, repoError("count users", err)
+	}
+	s.seats.set(count, s.now())
+	return SeatUsage{Users: count, MaxUsers: s.maxUsers}, nil
+}
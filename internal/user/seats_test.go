@@ -0,0 +1,146 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLimitedService returns a service over SQLite allowing maxUsers users
+func newLimitedService(t *testing.T, maxUsers int) (Service, Repository) {
+	t.Helper()
+
+	database := setupTestDB(t)
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	// WHY: Every connection to :memory: opens its own empty database
+	sqlDB.SetMaxOpenConns(1)
+
+	repo := NewRepository(database)
+	return NewService(repo, WithMaxUsers(maxUsers)), repo
+}
+
+func registerRequest(i int) RegisterRequest {
+	return RegisterRequest{Name: "Seat User", Email: fmt.Sprintf("seat%d@example.com", i), Password: "password123"}
+}
+
+func TestService_MaxUsers_ConcurrentRegistrations(t *testing.T) {
+	svc, repo := newLimitedService(t, 3)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.RegisterUser(ctx, registerRequest(i))
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrLicenseLimitReached)
+	}
+	assert.Equal(t, 3, created)
+
+	count, err := repo.CountUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestService_MaxUsers_DeletionFreesSeat(t *testing.T) {
+	svc, _ := newLimitedService(t, 3)
+	ctx := context.Background()
+
+	var first *User
+	for i := range 3 {
+		u, err := svc.RegisterUser(ctx, registerRequest(i))
+		require.NoError(t, err)
+		if first == nil {
+			first = u
+		}
+	}
+
+	_, err := svc.RegisterUser(ctx, registerRequest(3))
+	require.ErrorIs(t, err, ErrLicenseLimitReached)
+
+	require.NoError(t, svc.DeleteUser(ctx, first.ID))
+
+	_, err = svc.RegisterUser(ctx, registerRequest(3))
+	assert.NoError(t, err, "the cached count is dropped when a user is deleted")
+}
+
+func TestService_MaxUsers_OAuthUser(t *testing.T) {
+	svc, _ := newLimitedService(t, 1)
+	ctx := context.Background()
+
+	_, err := svc.FindOrCreateOAuthUser(ctx, "first@example.com", "First")
+	require.NoError(t, err)
+
+	_, err = svc.FindOrCreateOAuthUser(ctx, "second@example.com", "Second")
+	assert.ErrorIs(t, err, ErrLicenseLimitReached)
+
+	_, err = svc.FindOrCreateOAuthUser(ctx, "first@example.com", "First")
+	assert.NoError(t, err, "existing users still sign in")
+}
+
+func TestService_SeatUsage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("limited", func(t *testing.T) {
+		svc, _ := newLimitedService(t, 3)
+		_, err := svc.RegisterUser(ctx, registerRequest(0))
+		require.NoError(t, err)
+
+		usage, err := svc.SeatUsage(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, SeatUsage{Users: 1, MaxUsers: 3}, usage)
+		assert.Equal(t, int64(2), usage.Remaining())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		svc, _ := newLimitedService(t, 0)
+
+		usage, err := svc.SeatUsage(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(-1), usage.Remaining())
+	})
+
+	t.Run("over the limit after lowering it", func(t *testing.T) {
+		assert.Equal(t, int64(0), SeatUsage{Users: 5, MaxUsers: 3}.Remaining())
+	})
+}
+
+func TestSeatCache(t *testing.T) {
+	var cache seatCache
+	now := time.Now()
+
+	_, ok := cache.get(now)
+	assert.False(t, ok)
+
+	cache.set(2, now)
+	count, ok := cache.get(now.Add(seatCountTTL - time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), count)
+
+	_, ok = cache.get(now.Add(seatCountTTL))
+	assert.False(t, ok, "expired")
+
+	cache.set(2, now)
+	cache.invalidate()
+	_, ok = cache.get(now)
+	assert.False(t, ok)
+}
@@ -2,13 +2,30 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/hash"
 )
 
+// DefaultEmailChangeTTL is how long an email change can be confirmed when no TTL is configured
+const DefaultEmailChangeTTL = 24 * time.Hour
+
+// bulkRoleBatchSize is how many users a bulk role change writes per statement
+const bulkRoleBatchSize = 500
+
 var (
 	// ErrUserNotFound is returned when user is not found
 	ErrUserNotFound = errors.New("user not found")
@@ -18,41 +35,193 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	// ErrInvalidRole is returned when role is invalid
 	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidSort is returned when the sort field or order is not supported
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrInvalidDateRange is returned when a registration date filter is malformed or inverted
+	ErrInvalidDateRange = errors.New("invalid date range")
+	// ErrFieldNotNullable is returned when a partial update tries to null a required field
+	ErrFieldNotNullable = errors.New("field cannot be null")
+	// ErrNoPendingEmailChange is returned when confirming or cancelling without a pending email change
+	ErrNoPendingEmailChange = errors.New("no pending email change")
+	// ErrEmailChangeExpired is returned when the pending email change is past its expiry
+	ErrEmailChangeExpired = errors.New("email change expired")
+	// ErrInvalidEmailChangeToken is returned when the confirmation token does not match
+	ErrInvalidEmailChangeToken = errors.New("invalid email change token")
+	// ErrAccountDisabled is returned when a deactivated user tries to sign in
+	ErrAccountDisabled = errors.New("account disabled")
+	// ErrLastAdmin is returned when a change would leave no user with the admin role
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+	// ErrUserNotDeleted is returned when restoring a user that is not deleted
+	ErrUserNotDeleted = errors.New("user is not deleted")
+	// ErrLicenseLimitReached is returned when creating a user would exceed license.max_users
+	ErrLicenseLimitReached = errors.New("license user limit reached")
+	// ErrOverloaded is returned when password hashing is saturated and the
+	// login or registration was shed; the client should retry shortly
+	ErrOverloaded = errors.New("too many password hash operations in flight")
+	// ErrBootstrapEmailTaken is returned when the bootstrap admin email belongs
+	// to a user whose password differs from the configured one
+	ErrBootstrapEmailTaken = errors.New("bootstrap admin email belongs to a user with a different password")
 )
 
 // Service defines user service interface
 type Service interface {
 	RegisterUser(ctx context.Context, req RegisterRequest) (*User, error)
 	AuthenticateUser(ctx context.Context, req LoginRequest) (*User, error)
+	IsEmailAvailable(ctx context.Context, email string) (bool, error)
 	GetUserByID(ctx context.Context, id uint) (*User, error)
+	GetUserByIDIncludingDeleted(ctx context.Context, id uint) (*User, error)
 	UpdateUser(ctx context.Context, id uint, req UpdateUserRequest) (*User, error)
+	UpdateUserPartial(ctx context.Context, id uint, patch PatchUserRequest) (*User, error)
+	UpdateUserAsAdmin(ctx context.Context, id uint, req AdminUpdateUserRequest) (*User, error)
 	DeleteUser(ctx context.Context, id uint) error
+	RestoreUser(ctx context.Context, id uint) (*User, error)
 	ListUsers(ctx context.Context, filters UserFilterParams, page, perPage int) ([]User, int64, error)
+	CountUsersByRole(ctx context.Context, filters UserFilterParams) (map[string]int64, error)
 	PromoteToAdmin(ctx context.Context, userID uint) error
+	BootstrapAdmin(ctx context.Context, req RegisterRequest) (*User, error)
+	BulkAssignRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error)
+	BulkRemoveRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error)
+	SetUserActive(ctx context.Context, userID uint, active bool) (*User, error)
+	RecordLogin(ctx context.Context, userID uint)
+	FindOrCreateOAuthUser(ctx context.Context, email, name string) (*User, error)
+	ConfirmEmailChange(ctx context.Context, userID uint, token string) (*User, error)
+	CancelEmailChange(ctx context.Context, userID uint) (*User, error)
+	SeatUsage(ctx context.Context) (SeatUsage, error)
 }
 
 type service struct {
-	repo Repository
+	repo           Repository
+	mailer         email.EmailService
+	emailChangeTTL time.Duration
+	passwordPolicy auth.PasswordPolicy
+	hasher         hash.Hasher
+	hashLimiter    *hash.Limiter
+	rehashOnLogin  bool
+	now            func() time.Time
+	maxUsers       int
+	seats          seatCache
+	outbox         EventOutbox
+
+	isUniqueViolation func(error) bool
+}
+
+// EventOutbox records events in the transaction carried by ctx, so they are
+// delivered only if the change that caused them commits
+type EventOutbox interface {
+	Add(ctx context.Context, event events.Event) error
+}
+
+// ServiceOption configures optional Service behavior
+type ServiceOption func(*service)
+
+// WithEmailService sets where email change verifications and notices are sent (defaults to the console)
+func WithEmailService(mailer email.EmailService) ServiceOption {
+	return func(s *service) {
+		if mailer != nil {
+			s.mailer = mailer
+		}
+	}
+}
+
+// WithEmailChangeTTL sets how long a requested email change can be confirmed
+func WithEmailChangeTTL(ttl time.Duration) ServiceOption {
+	return func(s *service) {
+		if ttl > 0 {
+			s.emailChangeTTL = ttl
+		}
+	}
+}
+
+// WithPasswordPolicy sets the rules new passwords must satisfy (defaults to auth.DefaultPasswordPolicy)
+func WithPasswordPolicy(policy auth.PasswordPolicy) ServiceOption {
+	return func(s *service) {
+		s.passwordPolicy = policy
+	}
+}
+
+// WithPasswordHasher sets how passwords are hashed and verified (defaults to hash.Default, bcrypt)
+func WithPasswordHasher(hasher hash.Hasher) ServiceOption {
+	return func(s *service) {
+		if hasher != nil {
+			s.hasher = hasher
+		}
+	}
+}
+
+// WithRehashOnLogin sets whether a login replaces a password hash made with
+// another algorithm or parameters than the hasher's (defaults to true)
+func WithRehashOnLogin(enabled bool) ServiceOption {
+	return func(s *service) {
+		s.rehashOnLogin = enabled
+	}
+}
+
+// WithHashLimiter bounds how many password hash operations run at once; excess
+// logins and registrations fail with ErrOverloaded (defaults to no limit)
+func WithHashLimiter(limiter *hash.Limiter) ServiceOption {
+	return func(s *service) {
+		s.hashLimiter = limiter
+	}
+}
+
+// WithOutbox records a user.created event in the transaction creating a user
+// (defaults to none)
+func WithOutbox(outbox EventOutbox) ServiceOption {
+	return func(s *service) {
+		s.outbox = outbox
+	}
+}
+
+// WithUniqueViolationCheck sets how database errors caused by a duplicate email
+// are recognized (defaults to db.IsUniqueViolation, which knows Postgres and SQLite)
+func WithUniqueViolationCheck(check func(error) bool) ServiceOption {
+	return func(s *service) {
+		if check != nil {
+			s.isUniqueViolation = check
+		}
+	}
 }
 
 // NewService creates a new user service
-func NewService(repo Repository) Service {
-	return &service{
-		repo: repo,
+func NewService(repo Repository, opts ...ServiceOption) Service {
+	s := &service{
+		repo:           repo,
+		mailer:         email.NewConsoleEmailService("", nil, nil),
+		emailChangeTTL: DefaultEmailChangeTTL,
+		passwordPolicy: auth.DefaultPasswordPolicy(),
+		hasher:         hash.Default(),
+		rehashOnLogin:  true,
+		now:            time.Now,
+
+		isUniqueViolation: db.IsUniqueViolation,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // RegisterUser registers a new user
 func (s *service) RegisterUser(ctx context.Context, req RegisterRequest) (*User, error) {
-	existingUser, err := s.repo.FindByEmail(ctx, req.Email)
+	if err := s.passwordPolicy.ValidateContext(ctx, req.Password); err != nil {
+		return nil, err
+	}
+
+	// WHY: A pending email change claims the address too, or confirming it would collide later
+	inUse, err := s.repo.EmailInUse(ctx, req.Email, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing email: %w", err)
+		return nil, repoError("check existing email", err)
 	}
-	if existingUser != nil {
+	if inUse {
 		return nil, ErrEmailExists
 	}
 
-	hashedPassword, err := hashPassword(req.Password)
+	// WHY: Rejected before hashing so a full license does not cost a bcrypt round per attempt
+	if err := s.checkSeats(ctx); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -63,57 +232,175 @@ func (s *service) RegisterUser(ctx context.Context, req RegisterRequest) (*User,
 		PasswordHash: hashedPassword,
 	}
 
-	// Use transaction to ensure atomic user creation and role assignment
-	err = s.repo.Transaction(ctx, func(txCtx context.Context) error {
+	return s.createWithDefaultRole(ctx, user)
+}
+
+// IsEmailAvailable reports whether no user has email, ignoring case, as their
+// address or pending change, so registering with it would not fail with ErrEmailExists
+func (s *service) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	inUse, err := s.repo.EmailInUse(ctx, email, 0)
+	if err != nil {
+		return false, repoError("check existing email", err)
+	}
+	return !inUse, nil
+}
+
+// createWithDefaultRole creates the user and assigns RoleUser in one transaction,
+// so a failure at any step leaves no user without a role behind. The returned
+// user is reloaded inside the transaction with its roles preloaded. With a
+// license limit, the users are counted under a lock first. With an outbox, the
+// user.created event is written in the same transaction.
+func (s *service) createWithDefaultRole(ctx context.Context, user *User) (*User, error) {
+	var created *User
+	var count int64
+	err := s.repo.Transaction(ctx, func(txCtx context.Context) error {
+		if s.maxUsers > 0 {
+			var err error
+			if count, err = s.reserveSeat(txCtx); err != nil {
+				return err
+			}
+		}
+
 		if err := s.repo.Create(txCtx, user); err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
+			// WHY: A concurrent registration can take the email after the FindByEmail check
+			if s.isUniqueViolation(err) {
+				return ErrEmailExists
+			}
+			return repoError("create user", err)
 		}
 
 		if err := s.repo.AssignRole(txCtx, user.ID, RoleUser); err != nil {
-			return fmt.Errorf("failed to assign default role: %w", err)
+			return repoError("assign default role", err)
 		}
 
+		reloaded, err := s.repo.FindByID(txCtx, user.ID)
+		if err != nil {
+			return repoError("reload user", err)
+		}
+		if reloaded == nil {
+			return fmt.Errorf("failed to reload user: user not found after creation")
+		}
+		created = reloaded
+
+		if s.outbox != nil {
+			err := s.outbox.Add(txCtx, events.Event{TenantID: created.TenantID, Type: events.TypeUserCreated, UserID: created.ID, Data: ToUserResponse(created), OccurredAt: s.now()})
+			if err != nil {
+				return repoError("write user created event", err)
+			}
+		}
 		return nil
 	})
-
+	if errors.Is(err, ErrLicenseLimitReached) {
+		s.seats.set(count, s.now())
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Reload user with roles after successful transaction
-	user, err = s.repo.FindByID(ctx, user.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to reload user: %w", err)
-	}
-	if user == nil {
-		return nil, fmt.Errorf("failed to reload user: user not found after creation")
+	if s.maxUsers > 0 {
+		s.seats.set(count+1, s.now())
 	}
-
-	return user, nil
+	return created, nil
 }
 
 // AuthenticateUser authenticates a user with email and password
 func (s *service) AuthenticateUser(ctx context.Context, req LoginRequest) (*User, error) {
 	user, err := s.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, repoError("find user", err)
 	}
 	if user == nil {
 		return nil, ErrInvalidCredentials
 	}
 
-	if err := verifyPassword(user.PasswordHash, req.Password); err != nil {
+	var verifyErr error
+	err = s.hashLimiter.Do(ctx, func() { verifyErr = s.hasher.Verify(user.PasswordHash, req.Password) })
+	if err != nil {
+		return nil, limiterError(err)
+	}
+	if verifyErr != nil {
 		return nil, ErrInvalidCredentials
 	}
 
+	// WHY: Checked after the password so the response does not reveal which accounts are disabled
+	if !user.Active {
+		return nil, ErrAccountDisabled
+	}
+
+	if s.rehashOnLogin && s.hasher.NeedsRehash(user.PasswordHash) {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+	s.RecordLogin(ctx, user.ID)
+
 	return user, nil
 }
 
+// rehashPassword replaces a hash made with an older algorithm or parameters
+// while the plain password is at hand. Failures are logged and never fail the
+// sign-in; the next one tries again.
+func (s *service) rehashPassword(ctx context.Context, user *User, password string) {
+	hashed, err := s.hashPassword(ctx, password)
+	if err == nil {
+		// WHY: A savepoint, so a failed update does not abort the request transaction and fail the sign-in
+		err = db.BestEffort(ctx, func(ctx context.Context) error {
+			return s.repo.UpdatePasswordHash(ctx, user.ID, hashed)
+		})
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.PasswordHash = hashed
+}
+
+// hashPassword hashes password within the hash limiter
+func (s *service) hashPassword(ctx context.Context, password string) (string, error) {
+	var hashed string
+	var hashErr error
+	if err := s.hashLimiter.Do(ctx, func() { hashed, hashErr = s.hasher.Hash(password) }); err != nil {
+		return "", limiterError(err)
+	}
+	return hashed, hashErr
+}
+
+// limiterError translates a shed hash operation into the error of the service
+func limiterError(err error) error {
+	if errors.Is(err, hash.ErrOverloaded) {
+		return ErrOverloaded
+	}
+	return err
+}
+
+// RecordLogin stamps the user's last sign-in time. Failures are logged and
+// never fail the sign-in that triggered them.
+func (s *service) RecordLogin(ctx context.Context, userID uint) {
+	// WHY: A savepoint, so a failed update does not abort the request transaction and fail the sign-in
+	err := db.BestEffort(ctx, func(ctx context.Context) error {
+		return s.repo.UpdateLastLogin(ctx, userID, s.now())
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to record last login", "user_id", userID, "error", err)
+	}
+}
+
 // GetUserByID retrieves a user by ID
 func (s *service) GetUserByID(ctx context.Context, id uint) (*User, error) {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, repoError("find user", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// GetUserByIDIncludingDeleted retrieves a user by ID even if they were deleted,
+// for admins; DeletedAt tells the two apart
+func (s *service) GetUserByIDIncludingDeleted(ctx context.Context, id uint) (*User, error) {
+	user, err := s.repo.FindByIDUnscoped(ctx, id)
+	if err != nil {
+		return nil, repoError("find user", err)
 	}
 	if user == nil {
 		return nil, ErrUserNotFound
@@ -125,7 +412,7 @@ func (s *service) GetUserByID(ctx context.Context, id uint) (*User, error) {
 func (s *service) UpdateUser(ctx context.Context, id uint, req UpdateUserRequest) (*User, error) {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, repoError("find user", err)
 	}
 	if user == nil {
 		return nil, ErrUserNotFound
@@ -134,35 +421,322 @@ func (s *service) UpdateUser(ctx context.Context, id uint, req UpdateUserRequest
 	if req.Name != "" {
 		user.Name = req.Name
 	}
-	if req.Email != "" {
-		existingUser, err := s.repo.FindByEmail(ctx, req.Email)
+	var token string
+	if req.Email != "" && req.Email != user.Email {
+		if token, err = s.stageEmailChange(ctx, user, req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, repoError("update user", err)
+	}
+
+	if token != "" {
+		if err := s.sendEmailChangeMessages(ctx, user, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// UpdateUserAsAdmin applies UpdateUser and, when req.Roles is set, replaces the
+// user's roles with the listed ones, all in one transaction. An unknown role or
+// an empty, non-nil list fails with ErrInvalidRole; taking the admin role from
+// the last admin fails with ErrLastAdmin.
+func (s *service) UpdateUserAsAdmin(ctx context.Context, id uint, req AdminUpdateUserRequest) (*User, error) {
+	// WHY: An empty list would strip every role; nil leaves the roles alone
+	if req.Roles != nil && len(req.Roles) == 0 {
+		return nil, ErrInvalidRole
+	}
+
+	var updated *User
+	err := s.repo.Transaction(ctx, func(ctx context.Context) error {
+		if req.Roles != nil {
+			if err := s.replaceRoles(ctx, id, req.Roles); err != nil {
+				return err
+			}
+		}
+		// WHY: Last, so its email change messages only go out once everything else succeeded
+		if _, err := s.UpdateUser(ctx, id, UpdateUserRequest{Name: req.Name, Email: req.Email}); err != nil {
+			return err
+		}
+
+		var err error
+		updated, err = s.GetUserByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// replaceRoles gives the user exactly the named roles
+func (s *service) replaceRoles(ctx context.Context, userID uint, roleNames []string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return repoError("find user", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	want := make(map[string]bool, len(roleNames))
+	var toAssign []string
+	for _, name := range roleNames {
+		if want[name] {
+			continue
+		}
+		role, err := s.repo.FindRoleByName(ctx, name)
+		if err != nil {
+			return repoError("find role", err)
+		}
+		if role == nil {
+			return ErrInvalidRole
+		}
+		want[name] = true
+		if !user.HasRole(name) {
+			toAssign = append(toAssign, name)
+		}
+	}
+
+	if user.HasRole(RoleAdmin) && !want[RoleAdmin] {
+		if err := s.repo.LockAdminCount(ctx); err != nil {
+			return repoError("lock admin count", err)
+		}
+		counts, err := s.repo.CountUsersByRole(ctx, UserListQuery{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to check existing email: %w", err)
+			return repoError("count admins", err)
+		}
+		if counts[RoleAdmin] <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	for _, name := range toAssign {
+		if err := s.repo.AssignRole(ctx, userID, name); err != nil {
+			return repoError("assign role", err)
+		}
+	}
+	for _, role := range user.Roles {
+		if want[role.Name] {
+			continue
 		}
-		if existingUser != nil && existingUser.ID != user.ID {
-			return nil, ErrEmailExists
+		if err := s.repo.RemoveRole(ctx, userID, role.Name); err != nil {
+			return repoError("remove role", err)
+		}
+	}
+	return nil
+}
+
+// UpdateUserPartial applies only the fields present in patch; null is rejected for non-nullable fields
+func (s *service) UpdateUserPartial(ctx context.Context, id uint, patch PatchUserRequest) (*User, error) {
+	if patch.Name.Null {
+		return nil, fmt.Errorf("%w: name", ErrFieldNotNullable)
+	}
+	if patch.Email.Null {
+		return nil, fmt.Errorf("%w: email", ErrFieldNotNullable)
+	}
+
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, repoError("find user", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if patch.Name.Set {
+		user.Name = patch.Name.Value
+	}
+	var token string
+	if patch.Email.Set && patch.Email.Value != user.Email {
+		if token, err = s.stageEmailChange(ctx, user, patch.Email.Value); err != nil {
+			return nil, err
 		}
-		user.Email = req.Email
 	}
 
 	if err := s.repo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+		return nil, repoError("update user", err)
+	}
+
+	if token != "" {
+		if err := s.sendEmailChangeMessages(ctx, user, token); err != nil {
+			return nil, err
+		}
 	}
 
 	return user, nil
 }
 
+// ConfirmEmailChange applies the pending email change when token matches and has not expired
+func (s *service) ConfirmEmailChange(ctx context.Context, userID uint, token string) (*User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, repoError("find user", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.PendingEmail == "" {
+		return nil, ErrNoPendingEmailChange
+	}
+	if !user.HasPendingEmailChange(s.now()) {
+		return nil, ErrEmailChangeExpired
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(token)), []byte(user.EmailChangeTokenHash)) != 1 {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	// WHY: Another account may have taken the address since the change was requested
+	inUse, err := s.repo.EmailInUse(ctx, user.PendingEmail, user.ID)
+	if err != nil {
+		return nil, repoError("check existing email", err)
+	}
+	if inUse {
+		return nil, ErrEmailExists
+	}
+
+	user.Email = user.PendingEmail
+	user.ClearPendingEmailChange()
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, repoError("update user", err)
+	}
+
+	return user, nil
+}
+
+// CancelEmailChange discards the pending email change
+func (s *service) CancelEmailChange(ctx context.Context, userID uint) (*User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, repoError("find user", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.PendingEmail == "" {
+		return nil, ErrNoPendingEmailChange
+	}
+
+	user.ClearPendingEmailChange()
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, repoError("update user", err)
+	}
+
+	return user, nil
+}
+
+// stageEmailChange records newEmail as pending on user and returns the plaintext verification token.
+// The login email is left unchanged until ConfirmEmailChange.
+func (s *service) stageEmailChange(ctx context.Context, user *User, newEmail string) (string, error) {
+	inUse, err := s.repo.EmailInUse(ctx, newEmail, user.ID)
+	if err != nil {
+		return "", repoError("check existing email", err)
+	}
+	if inUse {
+		return "", ErrEmailExists
+	}
+
+	token, err := generateEmailChangeToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate email change token: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.emailChangeTTL)
+	user.PendingEmail = newEmail
+	user.EmailChangeTokenHash = auth.HashToken(token)
+	user.EmailChangeExpiresAt = &expiresAt
+
+	return token, nil
+}
+
+// sendEmailChangeMessages sends the token to the new address and warns the current one
+func (s *service) sendEmailChangeMessages(ctx context.Context, user *User, token string) error {
+	if err := s.mailer.SendEmailChangeVerification(ctx, user.PendingEmail, token); err != nil {
+		return fmt.Errorf("failed to send email change verification: %w", err)
+	}
+	if err := s.mailer.SendEmailChangeNotice(ctx, user.Email, user.PendingEmail); err != nil {
+		return fmt.Errorf("failed to send email change notice: %w", err)
+	}
+	return nil
+}
+
 // DeleteUser deletes a user
 func (s *service) DeleteUser(ctx context.Context, id uint) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrUserNotFound
 		}
-		return fmt.Errorf("failed to delete user: %w", err)
+		return repoError("delete user", err)
 	}
+	s.seats.invalidate()
 	return nil
 }
 
+// RestoreUser undeletes a soft-deleted user and returns them. The restored user
+// takes a license seat again, so a full license refuses the restore like a
+// registration. Their email must still be free of other users' pending changes.
+func (s *service) RestoreUser(ctx context.Context, id uint) (*User, error) {
+	var restored *User
+	var count int64
+	err := s.repo.Transaction(ctx, func(txCtx context.Context) error {
+		user, err := s.repo.FindByIDUnscoped(txCtx, id)
+		if err != nil {
+			return repoError("find user", err)
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if !user.DeletedAt.Valid {
+			return ErrUserNotDeleted
+		}
+
+		inUse, err := s.repo.EmailInUse(txCtx, user.Email, id)
+		if err != nil {
+			return repoError("check existing email", err)
+		}
+		if inUse {
+			return ErrEmailExists
+		}
+
+		if s.maxUsers > 0 {
+			if count, err = s.reserveSeat(txCtx); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Restore(txCtx, id); err != nil {
+			// WHY: A concurrent restore got there first
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotDeleted
+			}
+			return repoError("restore user", err)
+		}
+
+		if restored, err = s.repo.FindByID(txCtx, id); err != nil {
+			return repoError("reload user", err)
+		}
+		if restored == nil {
+			return fmt.Errorf("failed to reload user: user not found after restore")
+		}
+		return nil
+	})
+	if errors.Is(err, ErrLicenseLimitReached) {
+		s.seats.set(count, s.now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.maxUsers > 0 {
+		s.seats.set(count+1, s.now())
+	}
+	return restored, nil
+}
+
 // ListUsers retrieves paginated list of users with filtering
 func (s *service) ListUsers(ctx context.Context, filters UserFilterParams, page, perPage int) ([]User, int64, error) {
 	// Validate pagination parameters
@@ -176,23 +750,74 @@ func (s *service) ListUsers(ctx context.Context, filters UserFilterParams, page,
 		return nil, 0, fmt.Errorf("perPage must be <= 100")
 	}
 
-	if filters.Role != "" && filters.Role != RoleUser && filters.Role != RoleAdmin {
-		return nil, 0, ErrInvalidRole
+	query, err := newUserListQuery(filters)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	users, total, err := s.repo.ListAllUsers(ctx, filters, page, perPage)
+	users, total, err := s.repo.ListAllUsers(ctx, query, page, perPage)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return nil, 0, repoError("list users", err)
 	}
 
 	return users, total, nil
 }
 
+// CountUsersByRole returns how many users matching the filters hold each
+// known role, ignoring pagination; roles nobody holds are reported as zero
+func (s *service) CountUsersByRole(ctx context.Context, filters UserFilterParams) (map[string]int64, error) {
+	query, err := newUserListQuery(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountUsersByRole(ctx, query)
+	if err != nil {
+		return nil, repoError("count users by role", err)
+	}
+
+	result := map[string]int64{RoleUser: 0, RoleAdmin: 0}
+	for role, count := range counts {
+		result[role] = count
+	}
+	return result, nil
+}
+
+// newUserListQuery validates the client filters
+func newUserListQuery(filters UserFilterParams) (UserListQuery, error) {
+	if filters.Role != "" && filters.Role != RoleUser && filters.Role != RoleAdmin {
+		return UserListQuery{}, ErrInvalidRole
+	}
+
+	sort, err := ParseSortField(filters.Sort)
+	if err != nil {
+		return UserListQuery{}, err
+	}
+	order, err := ParseSortOrder(filters.Order)
+	if err != nil {
+		return UserListQuery{}, err
+	}
+
+	if filters.RegisteredFrom != nil && filters.RegisteredTo != nil && filters.RegisteredFrom.After(*filters.RegisteredTo) {
+		return UserListQuery{}, fmt.Errorf("%w: registered_from must not be after registered_to", ErrInvalidDateRange)
+	}
+
+	return UserListQuery{
+		Role:            filters.Role,
+		Search:          filters.Search,
+		Sort:            sort,
+		Order:           order,
+		RegisteredFrom:  filters.RegisteredFrom,
+		RegisteredTo:    filters.RegisteredTo,
+		LastLoginBefore: filters.LastLoginBefore,
+	}, nil
+}
+
 // PromoteToAdmin promotes a user to admin role
 func (s *service) PromoteToAdmin(ctx context.Context, userID uint) error {
 	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("failed to find user: %w", err)
+		return repoError("find user", err)
 	}
 	if user == nil {
 		return ErrUserNotFound
@@ -203,22 +828,247 @@ func (s *service) PromoteToAdmin(ctx context.Context, userID uint) error {
 	}
 
 	if err := s.repo.AssignRole(ctx, userID, RoleAdmin); err != nil {
-		return fmt.Errorf("failed to assign admin role: %w", err)
+		return repoError("assign admin role", err)
 	}
 
 	return nil
 }
 
-// hashPassword hashes a plain text password using bcrypt
-func hashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// BootstrapAdmin creates the first admin from req when no user has the admin
+// role yet, and returns nil without changes otherwise, so it is safe to call on
+// every start. The password must satisfy auth.StrongPasswordPolicy, as with
+// cmd/createadmin. An existing user with req.Email is promoted only if
+// req.Password is theirs, so whoever registers that email first cannot
+// become admin; otherwise it fails with ErrBootstrapEmailTaken.
+func (s *service) BootstrapAdmin(ctx context.Context, req RegisterRequest) (*User, error) {
+	if err := auth.StrongPasswordPolicy().Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	var admin *User
+	err := s.repo.Transaction(ctx, func(ctx context.Context) error {
+		// WHY: Replicas starting together would each see no admin and each create one
+		if err := s.repo.LockAdminCount(ctx); err != nil {
+			return repoError("lock admin count", err)
+		}
+		counts, err := s.repo.CountUsersByRole(ctx, UserListQuery{})
+		if err != nil {
+			return repoError("count admins", err)
+		}
+		if counts[RoleAdmin] > 0 {
+			return nil
+		}
+
+		user, err := s.repo.FindByEmail(ctx, req.Email)
+		if err != nil {
+			return repoError("find user", err)
+		}
+		if user == nil {
+			if user, err = s.RegisterUser(ctx, req); err != nil {
+				return err
+			}
+		} else if s.hasher.Verify(user.PasswordHash, req.Password) != nil {
+			return ErrBootstrapEmailTaken
+		}
+
+		if err := s.PromoteToAdmin(ctx, user.ID); err != nil {
+			return err
+		}
+		admin, err = s.GetUserByID(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// BulkAssignRole gives a role to every listed user in one transaction and
+// reports the outcome per user. With dryRun nothing is written.
+func (s *service) BulkAssignRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error) {
+	return s.bulkChangeRole(ctx, roleName, userIDs, true, dryRun)
+}
+
+// BulkRemoveRole takes a role from every listed user in one transaction and
+// reports the outcome per user. With dryRun nothing is written. Removing the
+// admin role from every remaining admin fails with ErrLastAdmin.
+func (s *service) BulkRemoveRole(ctx context.Context, roleName string, userIDs []uint, dryRun bool) ([]RoleChangeResult, error) {
+	return s.bulkChangeRole(ctx, roleName, userIDs, false, dryRun)
+}
+
+func (s *service) bulkChangeRole(ctx context.Context, roleName string, userIDs []uint, assign, dryRun bool) ([]RoleChangeResult, error) {
+	role, err := s.repo.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, repoError("find role", err)
+	}
+	if role == nil {
+		return nil, ErrInvalidRole
+	}
+
+	var results []RoleChangeResult
+	change := func(ctx context.Context) error {
+		planned, toChange, err := s.planRoleChange(ctx, role, uniqueIDs(userIDs), assign)
+		if err != nil {
+			return err
+		}
+		results = planned
+
+		if !assign && role.Name == RoleAdmin && len(toChange) > 0 {
+			if !dryRun {
+				if err := s.repo.LockAdminCount(ctx); err != nil {
+					return repoError("lock admin count", err)
+				}
+			}
+			counts, err := s.repo.CountUsersByRole(ctx, UserListQuery{})
+			if err != nil {
+				return repoError("count admins", err)
+			}
+			if counts[RoleAdmin] <= int64(len(toChange)) {
+				return ErrLastAdmin
+			}
+		}
+
+		if dryRun {
+			return nil
+		}
+		for batch := range slices.Chunk(toChange, bulkRoleBatchSize) {
+			write := s.repo.RemoveRoleFromUsers
+			if assign {
+				write = s.repo.AssignRoleToUsers
+			}
+			if err := write(ctx, role.ID, batch); err != nil {
+				return repoError("update roles", err)
+			}
+		}
+		return nil
+	}
+
+	// WHY: A dry run writes nothing, so it does not need to hold a transaction open
+	if dryRun {
+		err = change(ctx)
+	} else {
+		err = s.repo.Transaction(ctx, change)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// planRoleChange works out the outcome of a bulk role change for each user and
+// which users actually need to change
+func (s *service) planRoleChange(ctx context.Context, role *Role, userIDs []uint, assign bool) ([]RoleChangeResult, []uint, error) {
+	existing, err := s.repo.FindExistingUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, repoError("find users", err)
+	}
+	withRole, err := s.repo.FindUserIDsWithRole(ctx, role.ID, userIDs)
+	if err != nil {
+		return nil, nil, repoError("find role holders", err)
+	}
+
+	exists := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+	hasRole := make(map[uint]bool, len(withRole))
+	for _, id := range withRole {
+		hasRole[id] = true
+	}
+
+	changedStatus := RoleChangeRemoved
+	if assign {
+		changedStatus = RoleChangeAssigned
+	}
+
+	results := make([]RoleChangeResult, 0, len(userIDs))
+	var toChange []uint
+	for _, id := range userIDs {
+		result := RoleChangeResult{UserID: id}
+		switch {
+		case !exists[id]:
+			result.Status = RoleChangeNotFound
+		case hasRole[id] == assign:
+			result.Status = RoleChangeUnchanged
+		default:
+			result.Status = changedStatus
+			toChange = append(toChange, id)
+		}
+		results = append(results, result)
+	}
+	return results, toChange, nil
+}
+
+// SetUserActive deactivates or reactivates a user and returns the updated user.
+// Deactivated users keep their data but cannot sign in.
+func (s *service) SetUserActive(ctx context.Context, userID uint, active bool) (*User, error) {
+	if err := s.repo.SetActive(ctx, userID, active); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, repoError("update user status", err)
+	}
+
+	return s.GetUserByID(ctx, userID)
+}
+
+// FindOrCreateOAuthUser returns the user with the given email, creating one with the default role
+// and no password if none exists. Callers must only pass provider-verified emails.
+func (s *service) FindOrCreateOAuthUser(ctx context.Context, email, name string) (*User, error) {
+	existingUser, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
+		return nil, repoError("check existing email", err)
+	}
+	if existingUser != nil {
+		return existingUser, nil
+	}
+
+	if name == "" {
+		name = email
+	}
+	// WHY: An empty hash never matches in bcrypt, so the account can only sign in via OAuth
+	user := &User{
+		Name:  name,
+		Email: email,
+	}
+
+	return s.createWithDefaultRole(ctx, user)
+}
+
+// generateEmailChangeToken generates a cryptographically secure email change token
+func generateEmailChangeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	return string(hashedBytes), nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsContextError reports whether err means the request was canceled or ran out
+// of time rather than that the database failed
+func IsContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// repoError wraps a repository error with the failed action. Context errors are
+// returned as is so callers can report a canceled or timed out request instead
+// of a database failure.
+func repoError(action string, err error) error {
+	if IsContextError(err) {
+		return err
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
 }
 
-// verifyPassword verifies a password against a hash
-func verifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// uniqueIDs returns ids without duplicates, keeping the first occurrence of each
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
 }
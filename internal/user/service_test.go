@@ -3,12 +3,22 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/email"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/hash"
 )
 
 func TestNewService(t *testing.T) {
@@ -34,7 +44,7 @@ func TestService_RegisterUser(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
 				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Run(func(args mock.Arguments) {
 					user := args.Get(1).(*User)
 					user.ID = 1
@@ -53,8 +63,7 @@ func TestService_RegisterUser(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				existingUser := &User{ID: 1, Email: "existing@example.com"}
-				m.On("FindByEmail", mock.Anything, "existing@example.com").Return(existingUser, nil)
+				m.On("EmailInUse", mock.Anything, "existing@example.com", uint(0)).Return(true, nil)
 			},
 			expectedErr: ErrEmailExists,
 		},
@@ -66,7 +75,7 @@ func TestService_RegisterUser(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, errors.New("db error"))
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, errors.New("db error"))
 			},
 			expectedErr: errors.New("failed to check existing email: db error"),
 		},
@@ -78,11 +87,25 @@ func TestService_RegisterUser(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
 				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Return(errors.New("create error"))
 			},
 			expectedErr: errors.New("failed to create user: create error"),
 		},
+		{
+			name: "concurrent registration hits unique violation",
+			request: RegisterRequest{
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Password: "password123",
+			},
+			setupMock: func(m *MockRepository) {
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
+				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).
+					Return(fmt.Errorf("insert failed: %w", &pgconn.PgError{Code: "23505"}))
+			},
+			expectedErr: ErrEmailExists,
+		},
 	}
 
 	for _, tt := range tests {
@@ -110,6 +133,94 @@ func TestService_RegisterUser(t *testing.T) {
 	}
 }
 
+func TestService_FindOrCreateOAuthUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		userName     string
+		setupMock    func(*MockRepository)
+		expectedErr  error
+		expectedID   uint
+		expectedName string
+	}{
+		{
+			name:     "existing user is returned unchanged",
+			email:    "john@example.com",
+			userName: "Johnny",
+			setupMock: func(m *MockRepository) {
+				m.On("FindByEmail", mock.Anything, "john@example.com").Return(&User{ID: 3, Name: "John Doe", Email: "john@example.com"}, nil)
+			},
+			expectedID:   3,
+			expectedName: "John Doe",
+		},
+		{
+			name:     "new user is created with default role",
+			email:    "jane@example.com",
+			userName: "Jane Doe",
+			setupMock: func(m *MockRepository) {
+				m.On("FindByEmail", mock.Anything, "jane@example.com").Return(nil, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *User) bool {
+					return u.Name == "Jane Doe" && !u.HasPassword()
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*User).ID = 9
+				}).Return(nil)
+				m.On("AssignRole", mock.Anything, uint(9), RoleUser).Return(nil)
+				m.On("FindByID", mock.Anything, uint(9)).Return(&User{ID: 9, Name: "Jane Doe", Email: "jane@example.com"}, nil)
+			},
+			expectedID:   9,
+			expectedName: "Jane Doe",
+		},
+		{
+			name:     "missing name falls back to email",
+			email:    "anon@example.com",
+			userName: "",
+			setupMock: func(m *MockRepository) {
+				m.On("FindByEmail", mock.Anything, "anon@example.com").Return(nil, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *User) bool {
+					return u.Name == "anon@example.com"
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*User).ID = 10
+				}).Return(nil)
+				m.On("AssignRole", mock.Anything, uint(10), RoleUser).Return(nil)
+				m.On("FindByID", mock.Anything, uint(10)).Return(&User{ID: 10, Name: "anon@example.com", Email: "anon@example.com"}, nil)
+			},
+			expectedID:   10,
+			expectedName: "anon@example.com",
+		},
+		{
+			name:     "repository error on lookup",
+			email:    "jane@example.com",
+			userName: "Jane Doe",
+			setupMock: func(m *MockRepository) {
+				m.On("FindByEmail", mock.Anything, "jane@example.com").Return(nil, errors.New("db error"))
+			},
+			expectedErr: errors.New("failed to check existing email: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			tt.setupMock(mockRepo)
+
+			service := NewService(mockRepo)
+			user, err := service.FindOrCreateOAuthUser(context.Background(), tt.email, tt.userName)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr.Error())
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, user.ID)
+				assert.Equal(t, tt.expectedName, user.Name)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestService_AuthenticateUser(t *testing.T) {
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 
@@ -130,11 +241,48 @@ func TestService_AuthenticateUser(t *testing.T) {
 					ID:           1,
 					Email:        "john@example.com",
 					PasswordHash: string(hashedPassword),
+					Active:       true,
 				}
 				m.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+				m.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "failing to record the login does not fail it",
+			request: LoginRequest{
+				Email:    "john@example.com",
+				Password: "password123",
+			},
+			setupMock: func(m *MockRepository) {
+				user := &User{
+					ID:           1,
+					Email:        "john@example.com",
+					PasswordHash: string(hashedPassword),
+					Active:       true,
+				}
+				m.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+				m.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(errors.New("db error"))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "deactivated account",
+			request: LoginRequest{
+				Email:    "john@example.com",
+				Password: "password123",
+			},
+			setupMock: func(m *MockRepository) {
+				user := &User{
+					ID:           1,
+					Email:        "john@example.com",
+					PasswordHash: string(hashedPassword),
+					Active:       false,
+				}
+				m.On("FindByEmail", mock.Anything, "john@example.com").Return(user, nil)
+			},
+			expectedErr: ErrAccountDisabled,
+		},
 		{
 			name: "user not found",
 			request: LoginRequest{
@@ -255,6 +403,23 @@ func TestService_GetUserByID(t *testing.T) {
 	}
 }
 
+func TestService_GetUserByIDIncludingDeleted(t *testing.T) {
+	deleted := &User{ID: 1, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	mockRepo := &MockRepository{}
+	mockRepo.On("FindByIDUnscoped", mock.Anything, uint(1)).Return(deleted, nil)
+	mockRepo.On("FindByIDUnscoped", mock.Anything, uint(999)).Return(nil, nil)
+	service := NewService(mockRepo)
+
+	user, err := service.GetUserByIDIncludingDeleted(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Same(t, deleted, user)
+
+	_, err = service.GetUserByIDIncludingDeleted(context.Background(), 999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_UpdateUser(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -273,7 +438,7 @@ func TestService_UpdateUser(t *testing.T) {
 			setupMock: func(m *MockRepository) {
 				user := &User{ID: 1, Name: "John Doe", Email: "john@example.com"}
 				m.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
-				m.On("FindByEmail", mock.Anything, "updated@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "updated@example.com", uint(1)).Return(false, nil)
 				m.On("Update", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
 			},
 			expectedErr: nil,
@@ -297,9 +462,8 @@ func TestService_UpdateUser(t *testing.T) {
 			},
 			setupMock: func(m *MockRepository) {
 				user := &User{ID: 1, Name: "John Doe", Email: "john@example.com"}
-				existingUser := &User{ID: 2, Email: "existing@example.com"}
 				m.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
-				m.On("FindByEmail", mock.Anything, "existing@example.com").Return(existingUser, nil)
+				m.On("EmailInUse", mock.Anything, "existing@example.com", uint(1)).Return(true, nil)
 			},
 			expectedErr: ErrEmailExists,
 		},
@@ -324,7 +488,8 @@ func TestService_UpdateUser(t *testing.T) {
 					assert.Equal(t, tt.request.Name, user.Name)
 				}
 				if tt.request.Email != "" {
-					assert.Equal(t, tt.request.Email, user.Email)
+					assert.Equal(t, "john@example.com", user.Email)
+					assert.Equal(t, tt.request.Email, user.PendingEmail)
 				}
 			}
 
@@ -390,30 +555,281 @@ func TestService_DeleteUser(t *testing.T) {
 	}
 }
 
-func TestHashPassword(t *testing.T) {
+func TestService_DefaultPasswordHasher(t *testing.T) {
+	svc := NewService(&MockRepository{}).(*service)
 	password := "testpassword123"
-	hashedPassword, err := hashPassword(password)
 
-	assert.NoError(t, err)
-	assert.NotEmpty(t, hashedPassword)
-	assert.NotEqual(t, password, hashedPassword)
+	hashedPassword, err := svc.hasher.Hash(password)
+	require.NoError(t, err)
+	assert.Equal(t, hash.AlgorithmBcrypt, hash.Algorithm(hashedPassword))
 
-	err = verifyPassword(hashedPassword, password)
-	assert.NoError(t, err)
+	assert.NoError(t, svc.hasher.Verify(hashedPassword, password))
+	assert.False(t, svc.hasher.NeedsRehash(hashedPassword))
+
+	assert.ErrorIs(t, svc.hasher.Verify(hashedPassword, "wrongpassword"), hash.ErrMismatch)
 }
 
-func TestVerifyPassword(t *testing.T) {
-	password := "testpassword123"
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// slowHasher stands in for an expensive hash configuration
+type slowHasher struct {
+	hash.Hasher
+	delay time.Duration
+}
+
+func (h slowHasher) Verify(encoded, password string) error {
+	time.Sleep(h.delay)
+	return h.Hasher.Verify(encoded, password)
+}
+
+func TestService_AuthenticateUser_ShedsWhenHashingSaturated(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	mockRepo := &MockRepository{}
+	mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+		Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+	mockRepo.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+	svc := NewService(mockRepo,
+		WithPasswordHasher(slowHasher{Hasher: hash.NewBcrypt(bcrypt.MinCost), delay: 200 * time.Millisecond}),
+		WithHashLimiter(hash.NewLimiter(hash.LimiterConfig{MaxConcurrency: 2, Registerer: prometheus.NewRegistry()})),
+	)
+
+	type attempt struct {
+		password string
+		user     *User
+		err      error
+		took     time.Duration
+	}
+	const attempts = 20
+	results := make(chan attempt, attempts)
+	start := make(chan struct{})
+	for i := range attempts {
+		password := "password123"
+		if i%2 == 1 {
+			password = "wrongpassword"
+		}
+		go func() {
+			<-start
+			began := time.Now()
+			user, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: password})
+			results <- attempt{password: password, user: user, err: err, took: time.Since(began)}
+		}()
+	}
+	close(start)
+
+	var verified, shed int
+	for range attempts {
+		a := <-results
+		if errors.Is(a.err, ErrOverloaded) {
+			shed++
+			assert.Less(t, a.took, 100*time.Millisecond, "shed attempts fail fast instead of queueing")
+			continue
+		}
+		verified++
+		if a.password == "password123" {
+			require.NoError(t, a.err)
+			assert.Equal(t, uint(1), a.user.ID)
+		} else {
+			assert.ErrorIs(t, a.err, ErrInvalidCredentials)
+		}
+	}
+
+	assert.Positive(t, shed)
+	assert.Positive(t, verified)
+}
+
+func TestService_AuthenticateUser_Rehash(t *testing.T) {
+	argon2Hasher, err := hash.New(config.PasswordHashConfig{Algorithm: hash.AlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+	require.NoError(t, err)
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	t.Run("outdated hash is replaced", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo, WithPasswordHasher(argon2Hasher))
+		mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+			Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+		mockRepo.On("UpdatePasswordHash", mock.Anything, uint(1), mock.MatchedBy(func(h string) bool {
+			return hash.Algorithm(h) == hash.AlgorithmArgon2id
+		})).Return(nil)
+		mockRepo.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+		user, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, hash.AlgorithmArgon2id, hash.Algorithm(user.PasswordHash))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("low-cost bcrypt hash is upgraded to the configured cost", func(t *testing.T) {
+		const cost = bcrypt.MinCost + 1
+		bcryptHasher, err := hash.New(config.PasswordHashConfig{Algorithm: hash.AlgorithmBcrypt, BcryptCost: cost})
+		require.NoError(t, err)
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo, WithPasswordHasher(bcryptHasher))
+		mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+			Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+		mockRepo.On("UpdatePasswordHash", mock.Anything, uint(1), mock.MatchedBy(func(h string) bool {
+			c, err := bcrypt.Cost([]byte(h))
+			return err == nil && c == cost
+		})).Return(nil)
+		mockRepo.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+		user, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		require.NoError(t, err)
+		upgraded, err := bcrypt.Cost([]byte(user.PasswordHash))
+		require.NoError(t, err)
+		assert.Equal(t, cost, upgraded)
+		assert.False(t, bcryptHasher.NeedsRehash(user.PasswordHash))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failing to persist the new hash does not fail the login", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo, WithPasswordHasher(argon2Hasher))
+		mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+			Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+		mockRepo.On("UpdatePasswordHash", mock.Anything, uint(1), mock.Anything).Return(errors.New("db error"))
+		mockRepo.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+		_, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: "password123"})
 
-	t.Run("correct password", func(t *testing.T) {
-		err := verifyPassword(string(hashedPassword), password)
 		assert.NoError(t, err)
 	})
 
-	t.Run("incorrect password", func(t *testing.T) {
-		err := verifyPassword(string(hashedPassword), "wrongpassword")
-		assert.Error(t, err)
+	t.Run("wrong password is not rehashed", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo, WithPasswordHasher(argon2Hasher))
+		mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+			Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+
+		_, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: "wrongpassword"})
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rehash on login disabled keeps the old hash", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo, WithPasswordHasher(argon2Hasher), WithRehashOnLogin(false))
+		mockRepo.On("FindByEmail", mock.Anything, "john@example.com").
+			Return(&User{ID: 1, Email: "john@example.com", PasswordHash: string(bcryptHash), Active: true}, nil)
+		mockRepo.On("UpdateLastLogin", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+		user, err := svc.AuthenticateUser(context.Background(), LoginRequest{Email: "john@example.com", Password: "password123"})
+
+		require.NoError(t, err)
+		assert.Equal(t, hash.AlgorithmBcrypt, hash.Algorithm(user.PasswordHash))
+		mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_AuthenticateUser_RehashPersists(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	_, err := NewService(repo).RegisterUser(ctx, RegisterRequest{Name: "Rehash User", Email: "rehash@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	argon2Hasher, err := hash.New(config.PasswordHashConfig{Algorithm: hash.AlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+	require.NoError(t, err)
+	_, err = NewService(repo, WithPasswordHasher(argon2Hasher)).
+		AuthenticateUser(ctx, LoginRequest{Email: "rehash@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	stored, err := repo.FindByEmail(ctx, "rehash@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, hash.AlgorithmArgon2id, hash.Algorithm(stored.PasswordHash))
+
+	_, err = NewService(repo).AuthenticateUser(ctx, LoginRequest{Email: "rehash@example.com", Password: "password123"})
+	assert.NoError(t, err, "argon2id hashes verify under the bcrypt configuration")
+}
+
+// callRecordingRepository records the order of the admin lock and count calls
+type callRecordingRepository struct {
+	Repository
+	calls []string
+}
+
+func (r *callRecordingRepository) LockAdminCount(ctx context.Context) error {
+	r.calls = append(r.calls, "LockAdminCount")
+	return r.Repository.LockAdminCount(ctx)
+}
+
+func (r *callRecordingRepository) CountUsersByRole(ctx context.Context, filters UserListQuery) (map[string]int64, error) {
+	r.calls = append(r.calls, "CountUsersByRole")
+	return r.Repository.CountUsersByRole(ctx, filters)
+}
+
+func TestService_BootstrapAdmin(t *testing.T) {
+	req := RegisterRequest{Name: "Admin", Email: "admin@example.com", Password: "Str0ng!Passw0rd"}
+
+	t.Run("creates the admin once", func(t *testing.T) {
+		repo := NewRepository(setupTestDB(t))
+		ctx := context.Background()
+
+		admin, err := NewService(repo).BootstrapAdmin(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, admin)
+		assert.True(t, admin.IsAdmin())
+		assert.Equal(t, "admin@example.com", admin.Email)
+
+		again, err := NewService(repo).BootstrapAdmin(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, again, "subsequent starts do nothing")
+
+		other, err := NewService(repo).BootstrapAdmin(ctx, RegisterRequest{Name: "Other", Email: "other@example.com", Password: req.Password})
+		require.NoError(t, err)
+		assert.Nil(t, other, "any existing admin skips the bootstrap")
+
+		count, err := repo.CountUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("promotes an existing user with the configured password", func(t *testing.T) {
+		repo := NewRepository(setupTestDB(t))
+		ctx := context.Background()
+		existing, err := NewService(repo).RegisterUser(ctx, req)
+		require.NoError(t, err)
+
+		admin, err := NewService(repo).BootstrapAdmin(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, admin)
+		assert.Equal(t, existing.ID, admin.ID)
+		assert.True(t, admin.IsAdmin())
+	})
+
+	t.Run("does not promote an existing user with another password", func(t *testing.T) {
+		repo := NewRepository(setupTestDB(t))
+		ctx := context.Background()
+		_, err := NewService(repo).RegisterUser(ctx, RegisterRequest{Name: "Squatter", Email: req.Email, Password: "password123"})
+		require.NoError(t, err)
+
+		_, err = NewService(repo).BootstrapAdmin(ctx, req)
+		assert.ErrorIs(t, err, ErrBootstrapEmailTaken)
+
+		stored, err := repo.FindByEmail(ctx, req.Email)
+		require.NoError(t, err)
+		assert.False(t, stored.IsAdmin())
+	})
+
+	t.Run("counts admins under the admin lock", func(t *testing.T) {
+		repo := &callRecordingRepository{Repository: NewRepository(setupTestDB(t))}
+
+		_, err := NewService(repo).BootstrapAdmin(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"LockAdminCount", "CountUsersByRole"}, repo.calls[:2])
+	})
+
+	t.Run("rejects a weak password", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+
+		_, err := NewService(mockRepo).BootstrapAdmin(context.Background(), RegisterRequest{Name: "Admin", Email: req.Email, Password: "password123"})
+
+		var policyErr *auth.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		mockRepo.AssertNotCalled(t, "Transaction", mock.Anything, mock.Anything)
 	})
 }
 
@@ -443,7 +859,7 @@ func TestService_ListUsers(t *testing.T) {
 					{ID: 1, Name: "User 1", Email: "user1@example.com"},
 					{ID: 2, Name: "User 2", Email: "user2@example.com"},
 				}
-				m.On("ListAllUsers", mock.Anything, UserFilterParams{Sort: "created_at", Order: "desc"}, 1, 20).
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Sort: "created_at", Order: "desc"}, 1, 20).
 					Return(users, int64(2), nil)
 			},
 			expectedUsers: []User{
@@ -466,7 +882,7 @@ func TestService_ListUsers(t *testing.T) {
 				users := []User{
 					{ID: 1, Name: "Admin User", Email: "admin@example.com", Roles: []Role{{Name: RoleAdmin}}},
 				}
-				m.On("ListAllUsers", mock.Anything, UserFilterParams{Role: RoleAdmin, Sort: "created_at", Order: "desc"}, 1, 20).
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Role: RoleAdmin, Sort: "created_at", Order: "desc"}, 1, 20).
 					Return(users, int64(1), nil)
 			},
 			expectedUsers: []User{
@@ -488,7 +904,7 @@ func TestService_ListUsers(t *testing.T) {
 				users := []User{
 					{ID: 1, Name: "John Doe", Email: "john@example.com"},
 				}
-				m.On("ListAllUsers", mock.Anything, UserFilterParams{Search: "john", Sort: "created_at", Order: "desc"}, 1, 20).
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Search: "john", Sort: "created_at", Order: "desc"}, 1, 20).
 					Return(users, int64(1), nil)
 			},
 			expectedUsers: []User{
@@ -511,6 +927,68 @@ func TestService_ListUsers(t *testing.T) {
 			expectedTotal: 0,
 			expectedErr:   ErrInvalidRole,
 		},
+		{
+			name:    "empty sort and order fall back to defaults",
+			filters: UserFilterParams{},
+			page:    1,
+			perPage: 20,
+			setupMocks: func(m *MockRepository) {
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Sort: SortByCreatedAt, Order: SortDesc}, 1, 20).
+					Return([]User{}, int64(0), nil)
+			},
+			expectedUsers: []User{},
+			expectedTotal: 0,
+			expectedErr:   nil,
+		},
+		{
+			name: "sort by id asc",
+			filters: UserFilterParams{
+				Sort:  "id",
+				Order: "asc",
+			},
+			page:    1,
+			perPage: 20,
+			setupMocks: func(m *MockRepository) {
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Sort: SortByID, Order: SortAsc}, 1, 20).
+					Return([]User{}, int64(0), nil)
+			},
+			expectedUsers: []User{},
+			expectedTotal: 0,
+			expectedErr:   nil,
+		},
+		{
+			name: "unknown sort field returns error",
+			filters: UserFilterParams{
+				Sort:  "password_hash",
+				Order: "desc",
+			},
+			page:        1,
+			perPage:     20,
+			setupMocks:  func(m *MockRepository) {},
+			expectedErr: ErrInvalidSort,
+		},
+		{
+			name: "sql injection in sort field returns error",
+			filters: UserFilterParams{
+				Sort:  "name; DROP TABLE users--",
+				Order: "desc",
+			},
+			page:        1,
+			perPage:     20,
+			setupMocks:  func(m *MockRepository) {},
+			expectedErr: ErrInvalidSort,
+		},
+		{
+			name: "unknown sort order returns error",
+			filters: UserFilterParams{
+				Sort:  "created_at",
+				Order: "sideways",
+			},
+			page:        1,
+			perPage:     20,
+			setupMocks:  func(m *MockRepository) {},
+			expectedErr: ErrInvalidSort,
+		},
 		{
 			name: "repository error",
 			filters: UserFilterParams{
@@ -520,7 +998,7 @@ func TestService_ListUsers(t *testing.T) {
 			page:    1,
 			perPage: 20,
 			setupMocks: func(m *MockRepository) {
-				m.On("ListAllUsers", mock.Anything, UserFilterParams{Sort: "created_at", Order: "desc"}, 1, 20).
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Sort: "created_at", Order: "desc"}, 1, 20).
 					Return(nil, int64(0), errors.New("database error"))
 			},
 			expectedUsers: nil,
@@ -536,7 +1014,7 @@ func TestService_ListUsers(t *testing.T) {
 			page:    1,
 			perPage: 20,
 			setupMocks: func(m *MockRepository) {
-				m.On("ListAllUsers", mock.Anything, UserFilterParams{Sort: "created_at", Order: "desc"}, 1, 20).
+				m.On("ListAllUsers", mock.Anything, UserListQuery{Sort: "created_at", Order: "desc"}, 1, 20).
 					Return([]User{}, int64(0), nil)
 			},
 			expectedUsers: []User{},
@@ -558,6 +1036,9 @@ func TestService_ListUsers(t *testing.T) {
 				if tt.expectedErr == ErrInvalidRole {
 					assert.Equal(t, ErrInvalidRole, err)
 				}
+				if tt.expectedErr == ErrInvalidSort {
+					assert.ErrorIs(t, err, ErrInvalidSort)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedUsers, users)
@@ -666,7 +1147,7 @@ func TestService_RegisterUser_ErrorPaths(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
 				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Run(func(args mock.Arguments) {
 					user := args.Get(1).(*User)
 					user.ID = 1
@@ -683,7 +1164,7 @@ func TestService_RegisterUser_ErrorPaths(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
 				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Run(func(args mock.Arguments) {
 					user := args.Get(1).(*User)
 					user.ID = 1
@@ -701,7 +1182,7 @@ func TestService_RegisterUser_ErrorPaths(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockRepository) {
-				m.On("FindByEmail", mock.Anything, "john@example.com").Return(nil, nil)
+				m.On("EmailInUse", mock.Anything, "john@example.com", uint(0)).Return(false, nil)
 				m.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Run(func(args mock.Arguments) {
 					user := args.Get(1).(*User)
 					user.ID = 1
@@ -781,7 +1262,7 @@ func TestService_ListUsers_PaginationErrors(t *testing.T) {
 func TestService_ListUsers_RepositoryError(t *testing.T) {
 	mockRepo := &MockRepository{}
 	filters := UserFilterParams{Sort: "created_at", Order: "desc"}
-	mockRepo.On("ListAllUsers", mock.Anything, filters, 1, 20).Return(nil, int64(0), errors.New("database error"))
+	mockRepo.On("ListAllUsers", mock.Anything, UserListQuery{Sort: SortByCreatedAt, Order: SortDesc}, 1, 20).Return(nil, int64(0), errors.New("database error"))
 
 	service := NewService(mockRepo)
 	users, total, err := service.ListUsers(context.Background(), filters, 1, 20)
@@ -794,6 +1275,145 @@ func TestService_ListUsers_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_CountUsersByRole(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	t.Run("fills missing roles with zero", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		mockRepo.On("CountUsersByRole", mock.Anything, UserListQuery{Role: RoleAdmin, Sort: SortByCreatedAt, Order: SortDesc, RegisteredFrom: &from, RegisteredTo: &to}).
+			Return(map[string]int64{RoleAdmin: 3}, nil)
+
+		service := NewService(mockRepo)
+		counts, err := service.CountUsersByRole(context.Background(), UserFilterParams{Role: RoleAdmin, RegisteredFrom: &from, RegisteredTo: &to})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{RoleUser: 0, RoleAdmin: 3}, counts)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("inverted registration range", func(t *testing.T) {
+		service := NewService(&MockRepository{})
+
+		_, err := service.CountUsersByRole(context.Background(), UserFilterParams{RegisteredFrom: &to, RegisteredTo: &from})
+		assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+		_, _, err = service.ListUsers(context.Background(), UserFilterParams{RegisteredFrom: &to, RegisteredTo: &from}, 1, 20)
+		assert.ErrorIs(t, err, ErrInvalidDateRange)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		mockRepo.On("CountUsersByRole", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		_, err := NewService(mockRepo).CountUsersByRole(context.Background(), UserFilterParams{})
+		assert.ErrorContains(t, err, "failed to count users by role")
+	})
+}
+
+func TestService_UpdateUserPartial(t *testing.T) {
+	tests := []struct {
+		name          string
+		patch         PatchUserRequest
+		setupMock     func(*MockRepository)
+		expectedErr   error
+		expectedName  string
+		expectedEmail string
+	}{
+		{
+			name:  "absent fields leave user unchanged",
+			patch: PatchUserRequest{},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
+			},
+			expectedName:  "John Doe",
+			expectedEmail: "john@example.com",
+		},
+		{
+			name:  "name value updates only name without email check",
+			patch: PatchUserRequest{Name: OptionalString{Set: true, Value: "Jane Doe"}},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
+			},
+			expectedName:  "Jane Doe",
+			expectedEmail: "john@example.com",
+		},
+		{
+			name:  "email value checks uniqueness and stays pending",
+			patch: PatchUserRequest{Email: OptionalString{Set: true, Value: "jane@example.com"}},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				m.On("EmailInUse", mock.Anything, "jane@example.com", uint(1)).Return(false, nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
+			},
+			expectedName:  "John Doe",
+			expectedEmail: "john@example.com",
+		},
+		{
+			name:  "unchanged email skips uniqueness check",
+			patch: PatchUserRequest{Email: OptionalString{Set: true, Value: "john@example.com"}},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				m.On("Update", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
+			},
+			expectedName:  "John Doe",
+			expectedEmail: "john@example.com",
+		},
+		{
+			name:  "email taken by another user",
+			patch: PatchUserRequest{Email: OptionalString{Set: true, Value: "taken@example.com"}},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(&User{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+				m.On("EmailInUse", mock.Anything, "taken@example.com", uint(1)).Return(true, nil)
+			},
+			expectedErr: ErrEmailExists,
+		},
+		{
+			name:        "null name rejected",
+			patch:       PatchUserRequest{Name: OptionalString{Set: true, Null: true}},
+			setupMock:   func(m *MockRepository) {},
+			expectedErr: ErrFieldNotNullable,
+		},
+		{
+			name:        "null email rejected",
+			patch:       PatchUserRequest{Email: OptionalString{Set: true, Null: true}},
+			setupMock:   func(m *MockRepository) {},
+			expectedErr: ErrFieldNotNullable,
+		},
+		{
+			name:  "user not found",
+			patch: PatchUserRequest{Name: OptionalString{Set: true, Value: "Jane Doe"}},
+			setupMock: func(m *MockRepository) {
+				m.On("FindByID", mock.Anything, uint(1)).Return(nil, nil)
+			},
+			expectedErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			tt.setupMock(mockRepo)
+
+			service := NewService(mockRepo)
+			user, err := service.UpdateUserPartial(context.Background(), 1, tt.patch)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedName, user.Name)
+				assert.Equal(t, tt.expectedEmail, user.Email)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestService_UpdateUser_ErrorPaths(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -836,8 +1456,7 @@ func TestService_UpdateUser_ErrorPaths(t *testing.T) {
 			setupMock: func(m *MockRepository) {
 				existingUser := &User{ID: 1, Name: "John Doe", Email: "john@example.com"}
 				m.On("FindByID", mock.Anything, uint(1)).Return(existingUser, nil)
-				otherUser := &User{ID: 2, Email: "taken@example.com"}
-				m.On("FindByEmail", mock.Anything, "taken@example.com").Return(otherUser, nil)
+				m.On("EmailInUse", mock.Anything, "taken@example.com", uint(1)).Return(true, nil)
 			},
 			expectedErr: "email already exists",
 		},
@@ -851,7 +1470,7 @@ func TestService_UpdateUser_ErrorPaths(t *testing.T) {
 			setupMock: func(m *MockRepository) {
 				existingUser := &User{ID: 1, Name: "John Doe", Email: "john@example.com"}
 				m.On("FindByID", mock.Anything, uint(1)).Return(existingUser, nil)
-				m.On("FindByEmail", mock.Anything, "new@example.com").Return(nil, errors.New("database error"))
+				m.On("EmailInUse", mock.Anything, "new@example.com", uint(1)).Return(false, errors.New("database error"))
 			},
 			expectedErr: "failed to check existing email",
 		},
@@ -886,3 +1505,453 @@ func TestService_UpdateUser_ErrorPaths(t *testing.T) {
 		})
 	}
 }
+
+// recordingMailer captures the email change messages sent by the service
+type recordingMailer struct {
+	verifications map[string]string
+	notices       map[string]string
+}
+
+func newRecordingMailer() *recordingMailer {
+	return &recordingMailer{verifications: map[string]string{}, notices: map[string]string{}}
+}
+
+func (m *recordingMailer) SendPasswordResetEmail(ctx context.Context, to, token string) error {
+	return nil
+}
+
+func (m *recordingMailer) SendEmailChangeVerification(ctx context.Context, to, token string) error {
+	m.verifications[to] = token
+	return nil
+}
+
+func (m *recordingMailer) SendEmailChangeNotice(ctx context.Context, to, newEmail string) error {
+	m.notices[to] = newEmail
+	return nil
+}
+
+func (m *recordingMailer) SendCategorized(ctx context.Context, userID uint, category email.Category, message email.Message) error {
+	return nil
+}
+
+// staleEmailCheckRepository misses existing emails, as when a concurrent
+// registration commits between the email check and the insert
+type staleEmailCheckRepository struct {
+	Repository
+}
+
+func (staleEmailCheckRepository) EmailInUse(ctx context.Context, email string, excludeUserID uint) (bool, error) {
+	return false, nil
+}
+
+func TestService_RegisterUser_PasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	req := RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}
+
+	t.Run("weak password is rejected before touching the repository", func(t *testing.T) {
+		mockRepo := &MockRepository{}
+		svc := NewService(mockRepo)
+
+		_, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "pass12"})
+
+		var policyErr *auth.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, "password must be at least 8 characters long", policyErr.Reason)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("configured policy", func(t *testing.T) {
+		svc := NewService(NewRepository(setupTestDB(t)), WithPasswordPolicy(auth.StrongPasswordPolicy()))
+
+		_, err := svc.RegisterUser(ctx, req)
+		var policyErr *auth.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+
+		req.Password = "Password123!"
+		_, err = svc.RegisterUser(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("breached password", func(t *testing.T) {
+		policy := auth.DefaultPasswordPolicy()
+		policy.BreachChecker = breachedPasswords{"password123": true}
+		svc := NewService(NewRepository(setupTestDB(t)), WithPasswordPolicy(policy))
+
+		_, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+		var policyErr *auth.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Contains(t, policyErr.Reason, "data breach")
+
+		_, err = svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+	})
+}
+
+// breachedPasswords is an in-memory auth.BreachChecker
+type breachedPasswords map[string]bool
+
+func (b breachedPasswords) IsBreached(_ context.Context, password string) (bool, error) {
+	return b[password], nil
+}
+
+func TestService_RegisterUser_UniqueViolation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(setupTestDB(t))
+	req := RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}
+
+	_, err := NewService(repo).RegisterUser(ctx, req)
+	require.NoError(t, err)
+
+	_, err = NewService(staleEmailCheckRepository{repo}).RegisterUser(ctx, req)
+	assert.ErrorIs(t, err, ErrEmailExists, "the SQLite unique constraint maps to ErrEmailExists")
+
+	t.Run("custom check", func(t *testing.T) {
+		svc := NewService(staleEmailCheckRepository{repo}, WithUniqueViolationCheck(func(error) bool { return false }))
+		_, err := svc.RegisterUser(ctx, req)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrEmailExists)
+	})
+}
+
+func TestService_RegisterUser_RollsBackWhenRoleAssignmentFails(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	svc := NewService(NewRepository(database))
+
+	// Without the default role AssignRole fails after the user row was inserted
+	require.NoError(t, database.Exec("DELETE FROM roles WHERE name = ?", RoleUser).Error)
+
+	user, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to assign default role")
+	assert.Nil(t, user)
+
+	var count int64
+	require.NoError(t, database.Model(&User{}).Count(&count).Error)
+	assert.Zero(t, count, "the user row is rolled back with the failed role assignment")
+}
+
+func TestService_AuthenticateUser_RecordsLastLogin(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(setupTestDB(t))
+	svc := NewService(repo).(*service)
+
+	loginAt := time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return loginAt }
+
+	u, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Nil(t, u.LastLoginAt, "registering is not a sign-in")
+
+	_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "wrongpassword"})
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	found, err := repo.FindByID(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.LastLoginAt, "failed logins are not recorded")
+
+	_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	found, err = repo.FindByID(ctx, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.LastLoginAt)
+	assert.True(t, loginAt.Equal(*found.LastLoginAt))
+}
+
+func TestService_SetUserActive(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewRepository(setupTestDB(t)))
+
+	u, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.True(t, u.Active, "new users are active")
+
+	deactivated, err := svc.SetUserActive(ctx, u.ID, false)
+	require.NoError(t, err)
+	assert.False(t, deactivated.Active)
+
+	_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, ErrAccountDisabled)
+
+	// A wrong password must not reveal that the account exists but is disabled
+	_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "wrongpassword"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	reactivated, err := svc.SetUserActive(ctx, u.ID, true)
+	require.NoError(t, err)
+	assert.True(t, reactivated.Active)
+
+	_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = svc.SetUserActive(ctx, 999, false)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestService_EmailChange(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*service, *recordingMailer, *User) {
+		repo := NewRepository(setupTestDB(t))
+		mailer := newRecordingMailer()
+		svc := NewService(repo, WithEmailService(mailer)).(*service)
+
+		u, err := svc.RegisterUser(ctx, RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+		require.NoError(t, err)
+		return svc, mailer, u
+	}
+
+	t.Run("change stays pending until confirmed", func(t *testing.T) {
+		svc, mailer, u := setup(t)
+
+		updated, err := svc.UpdateUser(ctx, u.ID, UpdateUserRequest{Email: "new@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", updated.Email)
+		assert.Equal(t, "new@example.com", updated.PendingEmail)
+		assert.NotEmpty(t, mailer.verifications["new@example.com"])
+		assert.Equal(t, "new@example.com", mailer.notices["john@example.com"])
+
+		// Login keeps using the current address until the change is confirmed
+		_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "new@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		confirmed, err := svc.ConfirmEmailChange(ctx, u.ID, mailer.verifications["new@example.com"])
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", confirmed.Email)
+		assert.Empty(t, confirmed.PendingEmail)
+		assert.Empty(t, confirmed.EmailChangeTokenHash)
+		assert.Nil(t, confirmed.EmailChangeExpiresAt)
+
+		_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "new@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		_, err = svc.AuthenticateUser(ctx, LoginRequest{Email: "john@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		svc, _, u := setup(t)
+
+		_, err := svc.UpdateUser(ctx, u.ID, UpdateUserRequest{Email: "new@example.com"})
+		require.NoError(t, err)
+
+		_, err = svc.ConfirmEmailChange(ctx, u.ID, "wrong-token")
+		assert.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		svc, mailer, u := setup(t)
+
+		_, err := svc.UpdateUser(ctx, u.ID, UpdateUserRequest{Email: "new@example.com"})
+		require.NoError(t, err)
+
+		svc.now = func() time.Time { return time.Now().Add(DefaultEmailChangeTTL + time.Minute) }
+		_, err = svc.ConfirmEmailChange(ctx, u.ID, mailer.verifications["new@example.com"])
+		assert.ErrorIs(t, err, ErrEmailChangeExpired)
+	})
+
+	t.Run("no pending change", func(t *testing.T) {
+		svc, _, u := setup(t)
+
+		_, err := svc.ConfirmEmailChange(ctx, u.ID, "token")
+		assert.ErrorIs(t, err, ErrNoPendingEmailChange)
+		_, err = svc.CancelEmailChange(ctx, u.ID)
+		assert.ErrorIs(t, err, ErrNoPendingEmailChange)
+	})
+
+	t.Run("pending email of another user conflicts", func(t *testing.T) {
+		svc, _, u := setup(t)
+		other, err := svc.RegisterUser(ctx, RegisterRequest{Name: "Jane Doe", Email: "jane@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		_, err = svc.UpdateUser(ctx, u.ID, UpdateUserRequest{Email: "shared@example.com"})
+		require.NoError(t, err)
+
+		_, err = svc.UpdateUserPartial(ctx, other.ID, PatchUserRequest{Email: OptionalString{Set: true, Value: "shared@example.com"}})
+		assert.ErrorIs(t, err, ErrEmailExists)
+	})
+
+	t.Run("cancel discards the pending change", func(t *testing.T) {
+		svc, mailer, u := setup(t)
+
+		_, err := svc.UpdateUser(ctx, u.ID, UpdateUserRequest{Email: "new@example.com"})
+		require.NoError(t, err)
+
+		cancelled, err := svc.CancelEmailChange(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", cancelled.Email)
+		assert.Empty(t, cancelled.PendingEmail)
+
+		_, err = svc.ConfirmEmailChange(ctx, u.ID, mailer.verifications["new@example.com"])
+		assert.ErrorIs(t, err, ErrNoPendingEmailChange)
+	})
+}
+
+func TestService_ContextErrors(t *testing.T) {
+	database := setupTestDB(t)
+	svc := NewService(NewRepository(database))
+
+	u, err := svc.RegisterUser(context.Background(), RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	t.Run("canceled mid-query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// WHY: Cancels after the service has started the lookup, like a client disconnecting
+		require.NoError(t, database.Callback().Query().Before("gorm:query").Register("test:cancel", func(*gorm.DB) {
+			cancel()
+		}))
+		defer func() {
+			_ = database.Callback().Query().Remove("test:cancel")
+		}()
+
+		_, err := svc.GetUserByID(ctx, u.ID)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, IsContextError(err))
+		assert.Equal(t, context.Canceled, err, "context errors are not wrapped as database failures")
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		_, _, err := svc.ListUsers(ctx, UserFilterParams{}, 1, 20)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, IsContextError(err))
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		err := repoError("find user", errors.New("connection refused"))
+		assert.EqualError(t, err, "failed to find user: connection refused")
+		assert.False(t, IsContextError(err))
+	})
+}
+
+func TestService_BulkRoles(t *testing.T) {
+	ctx := context.Background()
+	database := setupTestDB(t)
+	repo := NewRepository(database)
+	svc := NewService(repo)
+
+	var ids []uint
+	for _, email := range []string{"admin@example.com", "a@example.com", "b@example.com"} {
+		u, err := svc.RegisterUser(ctx, RegisterRequest{Name: "User", Email: email, Password: "password123"})
+		require.NoError(t, err)
+		ids = append(ids, u.ID)
+	}
+	require.NoError(t, svc.PromoteToAdmin(ctx, ids[0]))
+
+	adminIDs := func(t *testing.T) []uint {
+		users, _, err := svc.ListUsers(ctx, UserFilterParams{Role: RoleAdmin}, 1, 100)
+		require.NoError(t, err)
+		var found []uint
+		for _, u := range users {
+			found = append(found, u.ID)
+		}
+		return found
+	}
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		results, err := svc.BulkAssignRole(ctx, RoleAdmin, []uint{ids[0], ids[1], 999}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []RoleChangeResult{
+			{UserID: ids[0], Status: RoleChangeUnchanged},
+			{UserID: ids[1], Status: RoleChangeAssigned},
+			{UserID: 999, Status: RoleChangeNotFound},
+		}, results)
+		assert.ElementsMatch(t, []uint{ids[0]}, adminIDs(t))
+	})
+
+	t.Run("assign with unknown and duplicate IDs", func(t *testing.T) {
+		results, err := svc.BulkAssignRole(ctx, RoleAdmin, []uint{ids[1], 999, ids[2], ids[1]}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []RoleChangeResult{
+			{UserID: ids[1], Status: RoleChangeAssigned},
+			{UserID: 999, Status: RoleChangeNotFound},
+			{UserID: ids[2], Status: RoleChangeAssigned},
+		}, results)
+		assert.ElementsMatch(t, ids, adminIDs(t))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		results, err := svc.BulkRemoveRole(ctx, RoleAdmin, []uint{ids[2], 999}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []RoleChangeResult{
+			{UserID: ids[2], Status: RoleChangeRemoved},
+			{UserID: 999, Status: RoleChangeNotFound},
+		}, results)
+		assert.ElementsMatch(t, ids[:2], adminIDs(t))
+
+		u, err := svc.GetUserByID(ctx, ids[2])
+		require.NoError(t, err)
+		assert.Equal(t, []string{RoleUser}, u.GetRoleNames(), "other roles are kept")
+	})
+
+	t.Run("removing every admin is refused", func(t *testing.T) {
+		for _, dryRun := range []bool{true, false} {
+			_, err := svc.BulkRemoveRole(ctx, RoleAdmin, []uint{ids[0], ids[1], ids[2]}, dryRun)
+			assert.ErrorIs(t, err, ErrLastAdmin)
+		}
+		assert.ElementsMatch(t, ids[:2], adminIDs(t), "nothing is removed")
+
+		results, err := svc.BulkRemoveRole(ctx, RoleAdmin, []uint{ids[1]}, false)
+		require.NoError(t, err)
+		assert.Equal(t, RoleChangeRemoved, results[0].Status, "admins can be removed while one remains")
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		_, err := svc.BulkAssignRole(ctx, "superuser", ids, false)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("write failure", func(t *testing.T) {
+		failing := &MockRepository{}
+		failing.On("FindRoleByName", mock.Anything, RoleAdmin).Return(&Role{ID: 2, Name: RoleAdmin}, nil)
+		failing.On("FindExistingUserIDs", mock.Anything, []uint{1}).Return([]uint{1}, nil)
+		failing.On("FindUserIDsWithRole", mock.Anything, uint(2), []uint{1}).Return([]uint{}, nil)
+		failing.On("AssignRoleToUsers", mock.Anything, uint(2), []uint{1}).Return(errors.New("disk full"))
+
+		_, err := NewService(failing).BulkAssignRole(ctx, RoleAdmin, []uint{1}, false)
+		assert.ErrorContains(t, err, "failed to update roles: disk full")
+	})
+}
+
+func TestService_RestoreUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("restores a deleted user with their roles", func(t *testing.T) {
+		svc := NewService(NewRepository(setupTestDB(t)))
+		user, err := svc.RegisterUser(ctx, RegisterRequest{Name: "Jane", Email: "jane@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteUser(ctx, user.ID))
+
+		restored, err := svc.RestoreUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.False(t, restored.DeletedAt.Valid)
+		assert.Equal(t, []string{RoleUser}, restored.GetRoleNames())
+
+		_, err = svc.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err, "the restored user is visible again")
+
+		_, err = svc.RestoreUser(ctx, user.ID)
+		assert.ErrorIs(t, err, ErrUserNotDeleted)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := NewService(NewRepository(setupTestDB(t))).RestoreUser(ctx, 999)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("takes a license seat", func(t *testing.T) {
+		svc := NewService(NewRepository(setupTestDB(t)), WithMaxUsers(1))
+		user, err := svc.RegisterUser(ctx, RegisterRequest{Name: "Jane", Email: "jane@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteUser(ctx, user.ID))
+		_, err = svc.RegisterUser(ctx, RegisterRequest{Name: "John", Email: "john@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		_, err = svc.RestoreUser(ctx, user.ID)
+		assert.ErrorIs(t, err, ErrLicenseLimitReached)
+	})
+}
@@ -0,0 +1,58 @@
+// Package version exposes build information injected at link time:
+//
+//	go build -ldflags "-X github.com/vahiiiid/go-rest-api-boilerplate/internal/version.Version=v1.2.3 \
+//	  -X github.com/vahiiiid/go-rest-api-boilerplate/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/vahiiiid/go-rest-api-boilerplate/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+)
+
+// Build information, overridden with -ldflags "-X" at build time
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info is the build information of the running binary
+type Info struct {
+	Version   string `json:"version" xml:"version"`
+	Commit    string `json:"commit" xml:"commit"`
+	BuildDate string `json:"build_date" xml:"build_date"`
+}
+
+// Get returns the current build information
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, BuildDate: BuildDate}
+}
+
+// String formats the build information for --version output
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", i.Version, i.Commit, i.BuildDate)
+}
+
+// LogAttr returns the attribute added to every log entry
+func LogAttr() slog.Attr {
+	return slog.String("version", Version)
+}
+
+// Handler godoc
+// @Summary Build information
+// @Description Get the version, commit and build date of the running server
+// @Tags Health
+// @Produce json,xml
+// @Success 200 {object} errors.Response{success=bool,data=version.Info} "Build information"
+// @Failure 401 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Unauthorized (when restricted to admins)"
+// @Failure 403 {object} errors.Response{success=bool,error=errors.ErrorInfo} "Admin access required (when restricted to admins)"
+// @Router /version [get]
+func Handler(c *gin.Context) {
+	apiErrors.Respond(c, http.StatusOK, Get())
+}
@@ -0,0 +1,51 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setBuildInfo overrides the ldflags variables for the duration of a test
+func setBuildInfo(t *testing.T, v, commit, date string) {
+	t.Helper()
+	oldVersion, oldCommit, oldDate := Version, Commit, BuildDate
+	Version, Commit, BuildDate = v, commit, date
+	t.Cleanup(func() {
+		Version, Commit, BuildDate = oldVersion, oldCommit, oldDate
+	})
+}
+
+func TestGet(t *testing.T) {
+	setBuildInfo(t, "v1.2.3", "abc1234", "2026-01-02T03:04:05Z")
+
+	info := Get()
+	assert.Equal(t, Info{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2026-01-02T03:04:05Z"}, info)
+	assert.Equal(t, "v1.2.3 (commit abc1234, built 2026-01-02T03:04:05Z)", info.String())
+	assert.Equal(t, "version", LogAttr().Key)
+	assert.Equal(t, "v1.2.3", LogAttr().Value.String())
+}
+
+func TestHandler(t *testing.T) {
+	setBuildInfo(t, "v1.2.3", "abc1234", "2026-01-02T03:04:05Z")
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/version", Handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success bool `json:"success"`
+		Data    Info `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, Info{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2026-01-02T03:04:05Z"}, response.Data)
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	appmetrics "github.com/vahiiiid/go-rest-api-boilerplate/internal/metrics"
+)
+
+const (
+	resultSuccess  = "success"
+	resultPanic    = "panic"
+	resultRejected = "rejected"
+	resultDropped  = "dropped"
+)
+
+type metrics struct {
+	queueDepth *prometheus.GaugeVec
+	jobs       *prometheus.CounterVec
+}
+
+func newMetrics(namespace string, registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "worker_queue_depth",
+			Help:      "Number of jobs waiting for a worker.",
+		}, []string{"pool"}),
+		jobs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "worker_jobs_total",
+			Help:      "Total number of background jobs by result: success, panic, rejected (queue full) or dropped (shutdown deadline).",
+		}, []string{"pool", "result"}),
+	}
+
+	m.queueDepth = appmetrics.Register(registerer, m.queueDepth)
+	m.jobs = appmetrics.Register(registerer, m.jobs)
+
+	return m
+}
@@ -0,0 +1,170 @@
+// Package worker runs jobs in the background on a bounded pool of goroutines,
+// so requests can hand off slow work such as sending email.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Defaults for a zero Config
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+)
+
+var (
+	// ErrQueueFull is returned when every worker is busy and the queue has no room
+	ErrQueueFull = errors.New("worker queue full")
+	// ErrStopped is returned when a job is submitted after Stop
+	ErrStopped = errors.New("worker pool stopped")
+)
+
+// Job is a unit of background work. ctx is cancelled when the pool is stopped
+// and the shutdown deadline passes before the job finishes.
+type Job func(ctx context.Context)
+
+// Config holds pool settings and dependencies; zero values fall back to sensible defaults
+type Config struct {
+	// Name labels the pool's metrics and logs
+	Name      string
+	Workers   int
+	QueueSize int
+	Logger    *slog.Logger
+	// Namespace prefixes the pool's metric names; see metrics.namespace
+	Namespace  string
+	Registerer prometheus.Registerer
+}
+
+// Pool runs submitted jobs on a fixed number of workers with panic recovery
+// and Prometheus instrumentation
+type Pool struct {
+	name    string
+	logger  *slog.Logger
+	metrics *metrics
+
+	mu      sync.RWMutex
+	stopped bool
+	queue   chan Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// New creates a pool and starts its workers
+func New(cfg Config) *Pool {
+	name := cfg.Name
+	if name == "" {
+		name = "default"
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		name:    name,
+		logger:  logger,
+		metrics: newMetrics(cfg.Namespace, registerer),
+		queue:   make(chan Job, queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	for range workers {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues job without waiting for a worker. It fails with ErrQueueFull
+// rather than blocking the caller, and with ErrStopped once Stop was called.
+func (p *Pool) Submit(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		return ErrStopped
+	}
+	select {
+	case p.queue <- job:
+		p.metrics.queueDepth.WithLabelValues(p.name).Inc()
+		return nil
+	default:
+		p.metrics.jobs.WithLabelValues(p.name, resultRejected).Inc()
+		return ErrQueueFull
+	}
+}
+
+// Stop stops accepting jobs and waits until the queued and running ones have
+// finished. If ctx expires first, the context of running jobs is cancelled,
+// jobs still queued are dropped and ctx's error is returned.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		p.logger.Info("Worker pool stopped", "pool", p.name)
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return fmt.Errorf("worker pool stop: %w", ctx.Err())
+	}
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+
+	for job := range p.queue {
+		p.metrics.queueDepth.WithLabelValues(p.name).Dec()
+		// WHY: After the shutdown deadline the remaining jobs are dropped, not started
+		if p.ctx.Err() != nil {
+			p.metrics.jobs.WithLabelValues(p.name, resultDropped).Inc()
+			continue
+		}
+		p.metrics.jobs.WithLabelValues(p.name, p.run(job)).Inc()
+	}
+}
+
+func (p *Pool) run(job Job) (result string) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("Worker job panicked", "pool", p.name, "panic", r)
+			result = resultPanic
+		}
+	}()
+
+	job(p.ctx)
+	return resultSuccess
+}
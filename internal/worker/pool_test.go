@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(workers, queueSize int) *Pool {
+	return New(Config{Name: "test", Workers: workers, QueueSize: queueSize, Registerer: prometheus.NewRegistry()})
+}
+
+func TestPool_Submit(t *testing.T) {
+	p := newTestPool(2, 10)
+
+	var ran atomic.Int32
+	for range 5 {
+		require.NoError(t, p.Submit(func(context.Context) { ran.Add(1) }))
+	}
+	require.NoError(t, p.Stop(context.Background()))
+
+	assert.Equal(t, int32(5), ran.Load())
+	assert.Equal(t, float64(5), testutil.ToFloat64(p.metrics.jobs.WithLabelValues("test", resultSuccess)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(p.metrics.queueDepth.WithLabelValues("test")))
+}
+
+func TestPool_QueueFull(t *testing.T) {
+	p := newTestPool(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	require.NoError(t, p.Submit(func(context.Context) {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.NoError(t, p.Submit(func(context.Context) {}))
+
+	err := p.Submit(func(context.Context) {})
+
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.queueDepth.WithLabelValues("test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.jobs.WithLabelValues("test", resultRejected)))
+
+	close(release)
+	require.NoError(t, p.Stop(context.Background()))
+}
+
+func TestPool_StopDrainsQueue(t *testing.T) {
+	p := newTestPool(1, 10)
+	release := make(chan struct{})
+
+	var ran atomic.Int32
+	require.NoError(t, p.Submit(func(context.Context) {
+		<-release
+		ran.Add(1)
+	}))
+	for range 3 {
+		require.NoError(t, p.Submit(func(context.Context) { ran.Add(1) }))
+	}
+
+	stopped := make(chan error)
+	go func() { stopped <- p.Stop(context.Background()) }()
+
+	// WHY: Stop must wait for the running job instead of returning right away
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the queue was drained")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-stopped)
+	assert.Equal(t, int32(4), ran.Load())
+
+	assert.ErrorIs(t, p.Submit(func(context.Context) {}), ErrStopped)
+}
+
+func TestPool_StopDeadlineCancelsJobs(t *testing.T) {
+	p := newTestPool(1, 10)
+
+	cancelled := make(chan struct{})
+	require.NoError(t, p.Submit(func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	}))
+	var queuedRan atomic.Bool
+	require.NoError(t, p.Submit(func(context.Context) { queuedRan.Store(true) }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.Stop(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the running job's context was not cancelled")
+	}
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(p.metrics.jobs.WithLabelValues("test", resultDropped)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, queuedRan.Load(), "queued jobs are dropped after the deadline")
+}
+
+func TestPool_RecoversPanics(t *testing.T) {
+	p := newTestPool(1, 10)
+
+	var ran atomic.Bool
+	require.NoError(t, p.Submit(func(context.Context) { panic("boom") }))
+	require.NoError(t, p.Submit(func(context.Context) { ran.Store(true) }))
+	require.NoError(t, p.Stop(context.Background()))
+
+	assert.True(t, ran.Load(), "the worker survives a panicking job")
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.jobs.WithLabelValues("test", resultPanic)))
+}
+
+func TestPool_MetricsNamespace(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	p := New(Config{Name: "test", Workers: 1, Namespace: "myapp", Registerer: registry})
+	defer func() { _ = p.Stop(context.Background()) }()
+
+	done := make(chan struct{})
+	require.NoError(t, p.Submit(func(context.Context) { close(done) }))
+	<-done
+
+	assert.Eventually(t, func() bool {
+		count, err := testutil.GatherAndCount(registry, "myapp_worker_jobs_total")
+		return err == nil && count == 1
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,8 @@
+-- Migration: create_audit_logs_table (rollback)
+-- Description: Drops audit_logs table
+
+BEGIN;
+
+DROP TABLE IF EXISTS audit_logs;
+
+COMMIT;
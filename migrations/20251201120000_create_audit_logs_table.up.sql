@@ -0,0 +1,26 @@
+-- Migration: create_audit_logs_table
+-- Description: Creates audit_logs table recording sensitive administrative actions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_id INTEGER,
+    metadata TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_id ON audit_logs(target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+
+COMMENT ON TABLE audit_logs IS 'Audit trail of administrative actions';
+COMMENT ON COLUMN audit_logs.actor_id IS 'ID of the user who performed the action';
+COMMENT ON COLUMN audit_logs.action IS 'Action identifier, e.g. user.promote';
+COMMENT ON COLUMN audit_logs.target_id IS 'ID of the affected user, if any';
+COMMENT ON COLUMN audit_logs.metadata IS 'JSON-encoded action details';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: create_oauth_identities_table (rollback)
+-- Description: Drops oauth_identities table
+
+BEGIN;
+
+DROP TABLE IF EXISTS oauth_identities;
+
+COMMIT;
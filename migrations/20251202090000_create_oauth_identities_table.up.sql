@@ -0,0 +1,24 @@
+-- Migration: create_oauth_identities_table
+-- Description: Creates oauth_identities table linking external provider accounts to users
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS oauth_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    provider_user_id VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_identities_provider_subject ON oauth_identities(provider, provider_user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_identities_user_provider ON oauth_identities(user_id, provider);
+CREATE INDEX IF NOT EXISTS idx_oauth_identities_user_id ON oauth_identities(user_id);
+
+COMMENT ON TABLE oauth_identities IS 'External OAuth/OIDC accounts linked to local users';
+COMMENT ON COLUMN oauth_identities.provider IS 'Provider name, e.g. google';
+COMMENT ON COLUMN oauth_identities.provider_user_id IS 'Stable subject identifier issued by the provider';
+COMMENT ON COLUMN oauth_identities.email IS 'Email reported by the provider when linked';
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Migration: add_pending_email_to_users
+-- Description: Adds columns holding an email change until the new address is verified
+
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_token_hash VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_pending_email ON users(pending_email);
+
+COMMENT ON COLUMN users.pending_email IS 'Requested new email address, applied once verified';
+COMMENT ON COLUMN users.email_change_token_hash IS 'SHA-256 hash of the email change verification token';
+COMMENT ON COLUMN users.email_change_expires_at IS 'Timestamp after which the pending email change can no longer be confirmed';
+
+COMMIT;
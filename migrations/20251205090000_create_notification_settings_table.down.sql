@@ -0,0 +1,8 @@
+-- Migration: create_notification_settings_table (rollback)
+-- Description: Drops notification_settings table
+
+BEGIN;
+
+DROP TABLE IF EXISTS notification_settings;
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- Migration: create_notification_settings_table
+-- Description: Creates notification_settings table holding per-category email opt-outs
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    security BOOLEAN NOT NULL DEFAULT TRUE,
+    product BOOLEAN NOT NULL DEFAULT TRUE,
+    marketing BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE notification_settings IS 'Email notification preferences; users without a row use the defaults';
+COMMENT ON COLUMN notification_settings.security IS 'Stored for completeness; security emails are always sent';
+COMMENT ON COLUMN notification_settings.product IS 'Whether product update emails are sent';
+COMMENT ON COLUMN notification_settings.marketing IS 'Whether marketing emails are sent (opt-in)';
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: add_active_to_users
+-- Description: Adds a flag that blocks sign-in for deactivated accounts without deleting them
+
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMENT ON COLUMN users.active IS 'False when an admin has deactivated the account; deactivated users cannot sign in';
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: add_last_login_at_to_users (rollback)
+-- Description: Drops the last sign-in timestamp from users
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_last_login_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- Migration: add_last_login_at_to_users
+-- Description: Records when each user last signed in so dormant accounts can be found
+
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_last_login_at ON users(last_login_at);
+
+COMMENT ON COLUMN users.last_login_at IS 'Timestamp of the last successful sign-in; NULL if the user never signed in';
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration: add_audit_logs_filter_indexes (rollback)
+-- Description: Restores the single-column audit_logs indexes
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_id ON audit_logs(target_id);
+
+DROP INDEX IF EXISTS idx_audit_logs_action_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_actor_id_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_target_id_created_at;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration: add_audit_logs_filter_indexes
+-- Description: Replaces the single-column audit_logs indexes with composite indexes
+-- matching the action, actor and target filters of the audit log, newest first
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs(action, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id_created_at ON audit_logs(actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_id_created_at ON audit_logs(target_id, created_at);
+
+DROP INDEX IF EXISTS idx_audit_logs_action;
+DROP INDEX IF EXISTS idx_audit_logs_actor_id;
+DROP INDEX IF EXISTS idx_audit_logs_target_id;
+
+COMMIT;
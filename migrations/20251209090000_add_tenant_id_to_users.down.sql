@@ -0,0 +1,10 @@
+-- Migration: add_tenant_id_to_users (rollback)
+-- Description: Drops the tenant of users
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_tenant_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+COMMIT;
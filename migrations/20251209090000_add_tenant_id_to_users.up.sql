@@ -0,0 +1,12 @@
+-- Migration: add_tenant_id_to_users
+-- Description: Adds the tenant a user belongs to; existing users join the default tenant
+
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
+
+COMMENT ON COLUMN users.tenant_id IS 'Tenant the user belongs to; empty for the default tenant';
+
+COMMIT;
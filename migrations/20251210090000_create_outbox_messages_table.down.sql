@@ -0,0 +1,8 @@
+-- Migration: create_outbox_messages_table (rollback)
+-- Description: Drops outbox_messages table
+
+BEGIN;
+
+DROP TABLE IF EXISTS outbox_messages;
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- Migration: create_outbox_messages_table
+-- Description: Creates outbox_messages table holding events written with the change that caused them until they are dispatched
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    user_id INTEGER NOT NULL,
+    payload TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_sent_at_id ON outbox_messages(sent_at, id);
+
+COMMENT ON TABLE outbox_messages IS 'Events waiting to be dispatched by the outbox relay';
+COMMENT ON COLUMN outbox_messages.payload IS 'JSON-encoded event data';
+COMMENT ON COLUMN outbox_messages.attempts IS 'Number of failed dispatch attempts';
+COMMENT ON COLUMN outbox_messages.sent_at IS 'When the event was dispatched; NULL while pending';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: add_lower_email_index_to_users (rollback)
+-- Description: Drops the lower-cased email index of users
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+COMMIT;
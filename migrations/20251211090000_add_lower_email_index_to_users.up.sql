@@ -0,0 +1,8 @@
+-- Migration: add_lower_email_index_to_users
+-- Description: Indexes users by lower-cased email so case-insensitive email lookups stay indexed
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users(LOWER(email));
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: scope_user_email_to_tenant (rollback)
+-- Description: Makes emails unique across every tenant again; fails while two tenants share an email
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_tenant_email;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: scope_user_email_to_tenant
+-- Description: Makes emails unique per tenant instead of across every tenant
+
+BEGIN;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users(tenant_id, email);
+
+COMMIT;
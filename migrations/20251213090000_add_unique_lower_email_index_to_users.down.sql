@@ -0,0 +1,8 @@
+-- Migration: add_unique_lower_email_index_to_users (rollback)
+-- Description: Drops the case-insensitive per-tenant email uniqueness
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_tenant_email_lower;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration: add_unique_lower_email_index_to_users
+-- Description: Makes emails unique per tenant ignoring case, matching how the API compares them.
+-- Fails while two users of a tenant have emails that differ only in case; find them with
+--   SELECT tenant_id, LOWER(email) FROM users GROUP BY 1, 2 HAVING COUNT(*) > 1;
+-- and change or remove all but one before migrating.
+
+BEGIN;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email_lower ON users(tenant_id, LOWER(email));
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: add_tenant_id_to_audit_logs (rollback)
+-- Description: Drops the tenant of audit entries
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_audit_logs_tenant_id;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_id;
+
+COMMIT;
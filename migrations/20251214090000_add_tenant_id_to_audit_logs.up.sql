@@ -0,0 +1,12 @@
+-- Migration: add_tenant_id_to_audit_logs
+-- Description: Adds the tenant an audit entry belongs to; existing entries join the default tenant
+
+BEGIN;
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs(tenant_id);
+
+COMMENT ON COLUMN audit_logs.tenant_id IS 'Tenant the entry belongs to; empty for the default tenant';
+
+COMMIT;
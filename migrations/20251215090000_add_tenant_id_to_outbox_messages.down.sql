@@ -0,0 +1,8 @@
+-- Migration: add_tenant_id_to_outbox_messages (rollback)
+-- Description: Drops the tenant of outbox events
+
+BEGIN;
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS tenant_id;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Migration: add_tenant_id_to_outbox_messages
+-- Description: Adds the tenant an outbox event belongs to; existing events join the default tenant
+
+BEGIN;
+
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN outbox_messages.tenant_id IS 'Tenant the event belongs to; empty for the default tenant';
+
+COMMIT;
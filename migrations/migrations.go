@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migration files so binaries can apply them
+// without the migrations directory being present at runtime.
+package migrations
+
+import "embed"
+
+// FS holds every *.up.sql and *.down.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,33 @@
+package api
+
+// Error code constants for machine-readable API error identification.
+const (
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeNotFound           = "NOT_FOUND"
+	CodeMethodNotAllowed   = "METHOD_NOT_ALLOWED"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeTokenExpired       = "TOKEN_EXPIRED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeAccountDisabled    = "ACCOUNT_DISABLED"
+	CodeLicenseLimit       = "LICENSE_LIMIT_REACHED"
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeConflict           = "CONFLICT"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeNotImplemented     = "NOT_IMPLEMENTED"
+	CodeRequestCanceled    = "REQUEST_CANCELED"
+	CodeRequestTimeout     = "REQUEST_TIMEOUT"
+)
+
+// ErrorCodeInfo describes an error code: the HTTP status it is returned with
+// and what it means
+type ErrorCodeInfo struct {
+	Code        string `json:"code" xml:"code"`
+	Status      int    `json:"status" xml:"status"`
+	Description string `json:"description" xml:"description"`
+}
+
+// ErrorCatalogResponse lists every error code the API returns
+type ErrorCatalogResponse struct {
+	Codes []ErrorCodeInfo `json:"codes" xml:"codes>ErrorCodeInfo"`
+}
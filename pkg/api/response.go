@@ -0,0 +1,6 @@
+package api
+
+// MessageResponse is the body of actions whose only result is a confirmation
+type MessageResponse struct {
+	Message string `json:"message"`
+}
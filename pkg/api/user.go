@@ -0,0 +1,94 @@
+// Package api holds the request and response bodies of the REST API. The
+// server and the Go client in pkg/client share them so the two cannot drift.
+package api
+
+// RegisterRequest represents registration request payload. The password
+// length and strength are checked by the configured password policy.
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required,min=2,max=100"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginRequest represents login request payload
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UpdateUserRequest represents user update request payload
+type UpdateUserRequest struct {
+	Name  string `json:"name" binding:"omitempty,min=2,max=100"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// AdminUpdateUserRequest is the payload of an admin updating any user. Empty
+// fields are left unchanged; roles, when present, replace the user's roles.
+type AdminUpdateUserRequest struct {
+	Name  string   `json:"name" binding:"omitempty,min=2,max=100"`
+	Email string   `json:"email" binding:"omitempty,email"`
+	Roles []string `json:"roles" binding:"omitempty,min=1,dive,required"`
+}
+
+// EmailAvailabilityResponse reports whether an email can be used to register
+type EmailAvailabilityResponse struct {
+	Available bool `json:"available" xml:"available"`
+}
+
+// UserResponse represents user response (without sensitive fields)
+type UserResponse struct {
+	ID    uint   `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Email string `json:"email" xml:"email"`
+	// PendingEmail is the requested new email awaiting verification, if any
+	PendingEmail string   `json:"pending_email,omitempty" xml:"pending_email,omitempty"`
+	Roles        []string `json:"roles" xml:"roles>role"`
+	Active       bool     `json:"active" xml:"active"`
+	CreatedAt    string   `json:"created_at" xml:"created_at"`
+	UpdatedAt    string   `json:"updated_at" xml:"updated_at"`
+}
+
+// AdminUserResponse is the user representation returned by admin endpoints; it
+// adds fields that are not shown to the users themselves
+type AdminUserResponse struct {
+	UserResponse
+	// LastLoginAt is when the user last signed in; null if they never did
+	LastLoginAt *string `json:"last_login_at" xml:"last_login_at,omitempty"`
+	// DeletedAt is when the user was deleted; null for users that were not
+	DeletedAt *string `json:"deleted_at" xml:"deleted_at,omitempty"`
+}
+
+// AuthResponse represents authentication response
+type AuthResponse struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int64        `json:"expires_in"`
+	User         UserResponse `json:"user"`
+}
+
+// UserListResponse represents paginated user list response
+type UserListResponse struct {
+	Users      []AdminUserResponse `json:"users" xml:"users>UserResponse"`
+	Total      int64               `json:"total" xml:"total"`
+	Page       int                 `json:"page" xml:"page"`
+	PerPage    int                 `json:"per_page" xml:"per_page"`
+	TotalPages int                 `json:"total_pages" xml:"total_pages"`
+	// RoleCounts counts every user matching the filters, not just this page
+	RoleCounts RoleCounts `json:"role_counts" xml:"role_counts"`
+}
+
+// UserStatsResponse is the number of users against the license limit
+type UserStatsResponse struct {
+	Users int64 `json:"users" xml:"users"`
+	// MaxUsers is zero when the number of users is unlimited
+	MaxUsers int `json:"max_users" xml:"max_users"`
+	// RemainingSeats is null when the number of users is unlimited
+	RemainingSeats *int64 `json:"remaining_seats" xml:"remaining_seats"`
+}
+
+// RoleCounts is the number of users holding each role
+type RoleCounts struct {
+	User  int64 `json:"user" xml:"user"`
+	Admin int64 `json:"admin" xml:"admin"`
+}
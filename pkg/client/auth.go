@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+// Register creates an account and keeps the returned tokens. When the API does
+// not sign new users in, only User is set and the client stays signed out.
+func (c *Client) Register(ctx context.Context, req api.RegisterRequest) (*api.AuthResponse, error) {
+	var data json.RawMessage
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/register", nil, req, &data, ""); err != nil {
+		return nil, err
+	}
+
+	var resp api.AuthResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("client: decode response data: %w", err)
+	}
+	if resp.AccessToken == "" {
+		resp = api.AuthResponse{}
+		if err := json.Unmarshal(data, &resp.User); err != nil {
+			return nil, fmt.Errorf("client: decode response data: %w", err)
+		}
+		return &resp, nil
+	}
+
+	c.SetTokens(Tokens{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken})
+	return &resp, nil
+}
+
+// Login signs in and keeps the returned tokens
+func (c *Client) Login(ctx context.Context, req api.LoginRequest) (*api.AuthResponse, error) {
+	var resp api.AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", nil, req, &resp, ""); err != nil {
+		return nil, err
+	}
+	c.SetTokens(Tokens{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken})
+	return &resp, nil
+}
+
+// EmailAvailable reports whether email can be used to register. Servers that
+// protect against email enumeration always report true.
+func (c *Client) EmailAvailable(ctx context.Context, email string) (bool, error) {
+	var resp api.EmailAvailabilityResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/auth/email-available", url.Values{"email": {email}}, nil, &resp, ""); err != nil {
+		return false, err
+	}
+	return resp.Available, nil
+}
+
+// Refresh trades the refresh token for a new token pair and keeps it. Authenticated
+// calls do this on their own when the access token is rejected.
+func (c *Client) Refresh(ctx context.Context) (*api.TokenPairResponse, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.refresh(ctx)
+}
+
+// refresh must be called with refreshMu held
+func (c *Client) refresh(ctx context.Context) (*api.TokenPairResponse, error) {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return nil, ErrSessionExpired
+	}
+
+	var resp api.TokenPairResponse
+	err := c.do(ctx, http.MethodPost, "/api/v1/auth/refresh", nil, api.RefreshTokenRequest{RefreshToken: refreshToken}, &resp, "")
+	if isUnauthorized(err) {
+		c.SetTokens(Tokens{})
+		return nil, fmt.Errorf("%w: %w", ErrSessionExpired, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetTokens(Tokens{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken})
+	return &resp, nil
+}
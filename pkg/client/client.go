@@ -0,0 +1,186 @@
+// Package client is a Go client for the REST API. It keeps the token pair
+// returned by Register and Login, sends the access token on authenticated
+// calls and refreshes it once when the API answers 401, returning
+// ErrSessionExpired when that does not help.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each HTTP request unless WithTimeout or WithHTTPClient says otherwise
+const DefaultTimeout = 30 * time.Second
+
+// Tokens is the token pair the client authenticates with
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Client calls the API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens Tokens
+	// refreshMu lets one call refresh the tokens at a time
+	refreshMu sync.Mutex
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of a client with DefaultTimeout
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout bounds each HTTP request; zero leaves requests bounded only by their context
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Timeout = timeout
+		c.httpClient = &hc
+	}
+}
+
+// WithTokens starts the client with a token pair obtained earlier
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. "https://api.example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tokens returns the token pair the client currently holds
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// SetTokens replaces the token pair the client authenticates with
+func (c *Client) SetTokens(tokens Tokens) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = tokens
+}
+
+// doAuthenticated sends the request with the access token. On a 401 it
+// refreshes the tokens and tries once more; a second 401 ends the session.
+func (c *Client) doAuthenticated(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	access := c.Tokens().AccessToken
+	err := c.do(ctx, method, path, query, body, out, access)
+	if !isUnauthorized(err) {
+		return err
+	}
+
+	if err := c.refreshAfter(ctx, access); err != nil {
+		return err
+	}
+
+	err = c.do(ctx, method, path, query, body, out, c.Tokens().AccessToken)
+	if isUnauthorized(err) {
+		c.SetTokens(Tokens{})
+		return fmt.Errorf("%w: %w", ErrSessionExpired, err)
+	}
+	return err
+}
+
+// refreshAfter refreshes the tokens unless another call already replaced the
+// rejected access token. WHY: Refresh tokens rotate, so redeeming the same one
+// twice looks like token theft to the API and revokes the whole session.
+func (c *Client) refreshAfter(ctx context.Context, rejected string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.Tokens().AccessToken != rejected {
+		return nil
+	}
+	_, err := c.refresh(ctx)
+	return err
+}
+
+// do sends one request and decodes the data of a successful response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any, accessToken string) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("client: encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("client: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	// WHY: The server's default response format is configurable; pin the one decoded below
+	req.Header.Set("X-Response-Format", "standard")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("client: %s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("client: decode response: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("client: decode response data: %w", err)
+	}
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+// Errors an *APIError matches with errors.Is, by its code
+var (
+	ErrValidation   = errors.New("client: validation failed")
+	ErrUnauthorized = errors.New("client: unauthorized")
+	ErrForbidden    = errors.New("client: forbidden")
+	ErrNotFound     = errors.New("client: not found")
+	ErrConflict     = errors.New("client: conflict")
+	ErrRateLimited  = errors.New("client: rate limited")
+)
+
+// ErrSessionExpired is returned when the API still rejects the client after a
+// refresh, or there is no refresh token to try; the client forgets its tokens
+// and the user has to log in again
+var ErrSessionExpired = errors.New("client: session expired")
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Details is the raw details of the error, whose shape depends on the code
+	Details json.RawMessage
+	// Fields maps fields to what is wrong with them, for validation errors and
+	// conflicts about a field
+	Fields     map[string]string
+	RequestID  string
+	TraceID    string
+	RetryAfter int
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("client: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("client: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is matches the sentinel error for the code of e
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrValidation:
+		return e.Code == api.CodeValidation
+	case ErrUnauthorized:
+		return e.Code == api.CodeUnauthorized || e.Code == api.CodeTokenExpired
+	case ErrForbidden:
+		return e.Code == api.CodeForbidden || e.Code == api.CodeAccountDisabled || e.Code == api.CodeLicenseLimit
+	case ErrNotFound:
+		return e.Code == api.CodeNotFound
+	case ErrConflict:
+		return e.Code == api.CodeConflict
+	case ErrRateLimited:
+		return e.Code == api.CodeTooManyRequests
+	default:
+		return false
+	}
+}
+
+// decodeError reads the error envelope of resp. A body that is not one, e.g.
+// from a proxy in front of the API, still yields an *APIError with the status.
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	type errorInfo struct {
+		Code       string          `json:"code"`
+		Message    string          `json:"message"`
+		Details    json.RawMessage `json:"details"`
+		RequestID  string          `json:"request_id"`
+		TraceID    string          `json:"trace_id"`
+		RetryAfter int             `json:"retry_after"`
+	}
+	var envelope struct {
+		Error *errorInfo `json:"error"`
+		// WHY: Some middleware writes the error itself rather than the envelope
+		errorInfo
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || json.Unmarshal(body, &envelope) != nil {
+		return apiErr
+	}
+
+	info := envelope.Error
+	if info == nil {
+		if envelope.Code == "" {
+			return apiErr
+		}
+		info = &envelope.errorInfo
+	}
+	apiErr.Code = info.Code
+	apiErr.Message = info.Message
+	apiErr.Details = info.Details
+	apiErr.RequestID = info.RequestID
+	apiErr.TraceID = info.TraceID
+	apiErr.RetryAfter = info.RetryAfter
+
+	if info.Code == api.CodeValidation || info.Code == api.CodeConflict {
+		var fields map[string]string
+		if json.Unmarshal(info.Details, &fields) == nil {
+			apiErr.Fields = fields
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   APIError
+		is     error
+	}{
+		{
+			name:   "envelope",
+			status: http.StatusTooManyRequests,
+			body:   `{"success":false,"error":{"code":"TOO_MANY_REQUESTS","message":"Rate limit exceeded","request_id":"req-1","retry_after":30}}`,
+			want:   APIError{StatusCode: http.StatusTooManyRequests, Code: "TOO_MANY_REQUESTS", Message: "Rate limit exceeded", RequestID: "req-1", RetryAfter: 30},
+			is:     ErrRateLimited,
+		},
+		{
+			name:   "bare error",
+			status: http.StatusForbidden,
+			body:   `{"code":"FORBIDDEN","message":"insufficient permissions"}`,
+			want:   APIError{StatusCode: http.StatusForbidden, Code: "FORBIDDEN", Message: "insufficient permissions"},
+			is:     ErrForbidden,
+		},
+		{
+			name:   "license limit",
+			status: http.StatusForbidden,
+			body:   `{"success":false,"error":{"code":"LICENSE_LIMIT_REACHED","message":"The user limit of the license has been reached"}}`,
+			want:   APIError{StatusCode: http.StatusForbidden, Code: "LICENSE_LIMIT_REACHED", Message: "The user limit of the license has been reached"},
+			is:     ErrForbidden,
+		},
+		{
+			name:   "not json",
+			status: http.StatusBadGateway,
+			body:   "<html>Bad Gateway</html>",
+			want:   APIError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeError(newResponse(tt.status, tt.body))
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			apiErr.Details = nil
+			assert.Equal(t, tt.want, *apiErr)
+			if tt.is != nil {
+				assert.ErrorIs(t, err, tt.is)
+			}
+		})
+	}
+}
+
+func TestDecodeError_ValidationFields(t *testing.T) {
+	err := decodeError(newResponse(http.StatusBadRequest,
+		`{"success":false,"error":{"code":"VALIDATION_ERROR","message":"Validation failed","details":{"Email":"Email must be a valid email address"}}}`))
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, map[string]string{"Email": "Email must be a valid email address"}, apiErr.Fields)
+}
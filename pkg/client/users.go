@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+// ListUsersOptions filters and pages ListUsers; zero values use the API defaults
+type ListUsersOptions struct {
+	Page    int
+	PerPage int
+	// Role is "user" or "admin"
+	Role string
+	// Search matches names and emails
+	Search string
+	// Sort is one of created_at, updated_at, name, email or id
+	Sort string
+	// Order is "asc" or "desc"
+	Order string
+}
+
+func (o ListUsersOptions) query() url.Values {
+	q := url.Values{}
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	for key, value := range map[string]string{"role": o.Role, "search": o.Search, "sort": o.Sort, "order": o.Order} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	return q
+}
+
+// GetUser fetches a user by ID
+func (c *Client) GetUser(ctx context.Context, id uint) (*api.UserResponse, error) {
+	var resp api.UserResponse
+	if err := c.doAuthenticated(ctx, http.MethodGet, userPath(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateUser replaces the name and email of a user; empty fields are left unchanged
+func (c *Client) UpdateUser(ctx context.Context, id uint, req api.UpdateUserRequest) (*api.UserResponse, error) {
+	var resp api.UserResponse
+	if err := c.doAuthenticated(ctx, http.MethodPut, userPath(id), nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AdminUpdateUser updates any user's name, email and roles; it requires the
+// admin role. Empty fields and nil roles are left unchanged.
+func (c *Client) AdminUpdateUser(ctx context.Context, id uint, req api.AdminUpdateUserRequest) (*api.AdminUserResponse, error) {
+	var resp api.AdminUserResponse
+	if err := c.doAuthenticated(ctx, http.MethodPut, "/api/v1/admin/users/"+strconv.FormatUint(uint64(id), 10), nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteUser deletes a user
+func (c *Client) DeleteUser(ctx context.Context, id uint) error {
+	return c.doAuthenticated(ctx, http.MethodDelete, userPath(id), nil, nil, nil)
+}
+
+// ListUsers lists users page by page; it requires the admin role
+func (c *Client) ListUsers(ctx context.Context, opts ListUsersOptions) (*api.UserListResponse, error) {
+	var resp api.UserListResponse
+	if err := c.doAuthenticated(ctx, http.MethodGet, "/api/v1/admin/users", opts.query(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUserStats fetches the user count and the seats left under the license; it
+// requires the admin role
+func (c *Client) GetUserStats(ctx context.Context) (*api.UserStatsResponse, error) {
+	var resp api.UserStatsResponse
+	if err := c.doAuthenticated(ctx, http.MethodGet, "/api/v1/admin/stats", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func userPath(id uint) string {
+	return "/api/v1/users/" + strconv.FormatUint(uint64(id), 10)
+}
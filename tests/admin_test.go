@@ -0,0 +1,574 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/featureflags"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/graphql"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/maintenance"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func setupAdminTestRouter(t *testing.T) (*gin.Engine, user.Service) {
+	gin.SetMode(gin.TestMode)
+
+	testCfg := config.NewTestConfig()
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+
+	createTestSchema(t, database)
+
+	return newAdminTestRouter(database, testCfg)
+}
+
+// newAdminTestRouter builds the full router on top of an already-prepared database
+func newAdminTestRouter(database *gorm.DB, testCfg *config.Config, opts ...server.RouterOption) (*gin.Engine, user.Service) {
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userRepo := user.NewRepository(database)
+	userService := user.NewService(userRepo)
+	userHandler := user.NewHandler(userService, authService,
+		user.WithAuditLogger(audit.NewDBLogger(audit.NewRepository(database))),
+	)
+
+	return server.SetupRouter(userHandler, authService, testCfg, database, opts...), userService
+}
+
+// doJSON performs a JSON request against the router and decodes the response envelope
+func doJSON(t *testing.T, router *gin.Engine, method, path, token string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+// registerUser registers a user and returns the auth response data
+func registerUser(t *testing.T, router *gin.Engine, name, email, password string) map[string]interface{} {
+	t.Helper()
+
+	w, response := doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name":     name,
+		"email":    email,
+		"password": password,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	return response["data"].(map[string]interface{})
+}
+
+// loginUser logs a user in and returns the auth response data
+func loginUser(t *testing.T, router *gin.Engine, email, password string) map[string]interface{} {
+	t.Helper()
+
+	w, response := doJSON(t, router, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    email,
+		"password": password,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	return response["data"].(map[string]interface{})
+}
+
+// createAdmin registers a user, promotes them to admin and returns a fresh access token
+func createAdmin(t *testing.T, router *gin.Engine, userService user.Service) string {
+	t.Helper()
+
+	data := registerUser(t, router, "Admin User", "admin@example.com", "password123")
+	adminID := uint(data["user"].(map[string]interface{})["id"].(float64))
+	require.NoError(t, userService.PromoteToAdmin(context.Background(), adminID))
+
+	return loginUser(t, router, "admin@example.com", "password123")["access_token"].(string)
+}
+
+func TestAdminRevokeUserSessions(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	victim := registerUser(t, router, "Victim User", "victim@example.com", "password123")
+	victimID := uint(victim["user"].(map[string]interface{})["id"].(float64))
+	victimRefresh := victim["refresh_token"].(string)
+	victimAccess := victim["access_token"].(string)
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/revoke-sessions", victimID), victimAccess, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unknown user returns 404", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPost, "/api/v1/admin/users/9999/revoke-sessions", adminToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("admin revokes and victim refresh fails", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/revoke-sessions", victimID), adminToken,
+			map[string]string{"reason": "compromised account"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(victimID), data["user_id"])
+		assert.Equal(t, float64(1), data["revoked_refresh_tokens"])
+
+		w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+			"refresh_token": victimRefresh,
+		})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAdminRevokeUserTokens(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	victim := registerUser(t, router, "Victim User", "victim@example.com", "password123")
+	victimID := uint(victim["user"].(map[string]interface{})["id"].(float64))
+	secondSession := loginUser(t, router, "victim@example.com", "password123")
+	path := fmt.Sprintf("/api/v1/admin/users/%d/revoke-tokens", victimID)
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPost, path, victim["access_token"].(string), nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("admin logs the user out everywhere", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodPost, path, adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(victimID), data["user_id"])
+		assert.Equal(t, float64(2), data["revoked_refresh_tokens"])
+
+		for _, refreshToken := range []string{victim["refresh_token"].(string), secondSession["refresh_token"].(string)} {
+			w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+				"refresh_token": refreshToken,
+			})
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+	})
+}
+
+func TestAdminUserTokens(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	victim := registerUser(t, router, "Victim User", "victim@example.com", "password123")
+	victimID := uint(victim["user"].(map[string]interface{})["id"].(float64))
+	loginUser(t, router, "victim@example.com", "password123")
+	path := fmt.Sprintf("/api/v1/admin/users/%d/tokens", victimID)
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			w, _ := doJSON(t, router, method, path, victim["access_token"].(string), nil)
+			assert.Equal(t, http.StatusForbidden, w.Code, method)
+		}
+	})
+
+	t.Run("admin lists families without hashes", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodGet, path, adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), "hash")
+
+		families := response["data"].(map[string]interface{})["families"].([]interface{})
+		require.Len(t, families, 2)
+		for _, family := range families {
+			assert.Equal(t, true, family.(map[string]interface{})["active"])
+		}
+	})
+
+	t.Run("admin revokes every family", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodDelete, path, adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, float64(2), response["data"].(map[string]interface{})["revoked_refresh_tokens"])
+
+		_, response = doJSON(t, router, http.MethodGet, path, adminToken, nil)
+		for _, family := range response["data"].(map[string]interface{})["families"].([]interface{}) {
+			assert.Equal(t, false, family.(map[string]interface{})["active"])
+		}
+	})
+}
+
+func TestAdminBulkRoles(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	var ids []float64
+	var memberToken string
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		data := registerUser(t, router, "Member", email, "password123")
+		ids = append(ids, data["user"].(map[string]interface{})["id"].(float64))
+		memberToken = data["access_token"].(string)
+	}
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPost, "/api/v1/admin/roles/admin/assign", memberToken,
+			map[string]interface{}{"user_ids": ids})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("dry run then assign", func(t *testing.T) {
+		for _, dryRun := range []bool{true, false} {
+			w, response := doJSON(t, router, http.MethodPost, "/api/v1/admin/roles/admin/assign", adminToken,
+				map[string]interface{}{"user_ids": append(ids, 9999), "dry_run": dryRun})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, dryRun, data["dry_run"])
+			assert.Equal(t, float64(2), data["changed"])
+			results := data["results"].([]interface{})
+			require.Len(t, results, 3)
+			assert.Equal(t, "not_found", results[2].(map[string]interface{})["status"])
+		}
+
+		u, err := userService.GetUserByID(context.Background(), uint(ids[0]))
+		require.NoError(t, err)
+		assert.True(t, u.IsAdmin())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodPost, "/api/v1/admin/roles/admin/remove", adminToken,
+			map[string]interface{}{"user_ids": ids})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, float64(2), response["data"].(map[string]interface{})["changed"])
+	})
+}
+
+func TestAdminPromoteUserIsAudited(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+	adminID := uint(loginUser(t, router, "admin@example.com", "password123")["user"].(map[string]interface{})["id"].(float64))
+
+	target := registerUser(t, router, "Target User", "target@example.com", "password123")
+	targetID := uint(target["user"].(map[string]interface{})["id"].(float64))
+
+	t.Run("non-admin cannot read the audit log", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodGet, "/api/v1/admin/audit", target["access_token"].(string), nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	w, response := doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/promote", targetID), adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, response["data"].(map[string]interface{})["roles"], user.RoleAdmin)
+
+	w, response = doJSON(t, router, http.MethodGet, "/api/v1/admin/audit", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["total"])
+	entry := data["entries"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, audit.ActionUserPromote, entry["action"])
+	assert.Equal(t, float64(adminID), entry["actor_id"])
+	assert.Equal(t, float64(targetID), entry["target_id"])
+}
+
+func TestAdminUpdateUser(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+	adminID := uint(loginUser(t, router, "admin@example.com", "password123")["user"].(map[string]interface{})["id"].(float64))
+
+	member := registerUser(t, router, "Member User", "member@example.com", "password123")
+	memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+	registerUser(t, router, "Other User", "other@example.com", "password123")
+	memberPath := fmt.Sprintf("/api/v1/admin/users/%d", memberID)
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPut, memberPath, member["access_token"].(string), map[string]any{"roles": []string{user.RoleAdmin}})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("admin renames another user and grants admin", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodPut, memberPath, adminToken, map[string]any{
+			"name":  "Renamed Member",
+			"roles": []string{user.RoleUser, user.RoleAdmin},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "Renamed Member", data["name"])
+		assert.ElementsMatch(t, []interface{}{user.RoleUser, user.RoleAdmin}, data["roles"])
+	})
+
+	t.Run("roles replace the existing ones", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodPut, memberPath, adminToken, map[string]any{"roles": []string{user.RoleUser}})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, []interface{}{user.RoleUser}, response["data"].(map[string]interface{})["roles"])
+	})
+
+	t.Run("duplicate email is rejected with 409", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPut, memberPath, adminToken, map[string]any{"email": "other@example.com"})
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	})
+
+	t.Run("unknown role is rejected and nothing changes", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPut, memberPath, adminToken, map[string]any{"name": "Never Applied", "roles": []string{"owner"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		w, response := doJSON(t, router, http.MethodGet, memberPath, adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "Renamed Member", response["data"].(map[string]interface{})["name"])
+	})
+
+	t.Run("the last admin keeps the admin role", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPut, fmt.Sprintf("/api/v1/admin/users/%d", adminID), adminToken, map[string]any{"roles": []string{user.RoleUser}})
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	})
+
+	t.Run("an empty roles list is rejected and the roles are kept", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodPut, memberPath, adminToken, map[string]any{"roles": []string{}})
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		_, err := userService.UpdateUserAsAdmin(context.Background(), memberID, user.AdminUpdateUserRequest{Roles: []string{}})
+		assert.ErrorIs(t, err, user.ErrInvalidRole)
+
+		w, response := doJSON(t, router, http.MethodGet, memberPath, adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, []interface{}{user.RoleUser}, response["data"].(map[string]interface{})["roles"])
+	})
+}
+
+func TestAdminEventStream(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+	member := registerUser(t, router, "Member User", "member@example.com", "password123")
+
+	t.Run("requires authentication", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodGet, "/api/v1/events", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("non-admin caller is forbidden", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodGet, "/api/v1/events", member["access_token"].(string), nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("admin receives a health snapshot", func(t *testing.T) {
+		// WHY: Streaming needs a real connection; the recorder cannot report a closed client
+		srv := httptest.NewServer(router)
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/events", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		var lines []string
+		for range 2 {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			lines = append(lines, line)
+		}
+		assert.Equal(t, []string{"id:1\n", "event:health\n"}, lines)
+	})
+}
+
+func TestRateLimit_ExemptRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCfg := config.NewTestConfig()
+	testCfg.Ratelimit.Enabled = true
+	testCfg.Ratelimit.Requests = 10
+	testCfg.Ratelimit.Window = time.Minute
+	testCfg.Ratelimit.ExemptRoles = []string{"admin"}
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	router, userService := newAdminTestRouter(database, testCfg)
+	adminToken := createAdmin(t, router, userService)
+	member := registerUser(t, router, "Regular User", "regular@example.com", "password123")
+	memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+	memberToken := member["access_token"].(string)
+
+	for i := 0; i < 2*testCfg.Ratelimit.Requests; i++ {
+		w, _ := doJSON(t, router, http.MethodGet, "/api/v1/admin/users", adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, "admin request %d should not be limited", i+1)
+	}
+
+	limited := false
+	for i := 0; i < testCfg.Ratelimit.Requests && !limited; i++ {
+		w, _ := doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", memberID), memberToken, nil)
+		limited = w.Code == http.StatusTooManyRequests
+	}
+	assert.True(t, limited, "a regular user is limited within the normal budget")
+}
+
+func TestAdminFeatureFlags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCfg := config.NewTestConfig()
+	testCfg.GraphQL.Enabled = true
+	testCfg.Features = map[string]bool{"beta_search": true}
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	router, userService := newAdminTestRouter(database, testCfg)
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	flags := featureflags.Load(testCfg)
+	server.RegisterFeatureFlagRoutes(router, authService, featureflags.NewHandler(flags))
+	graphqlHandler, err := graphql.NewHandler(userService)
+	require.NoError(t, err)
+	server.RegisterGraphQLRoutes(router, flags, authService, graphqlHandler)
+
+	adminToken := createAdmin(t, router, userService)
+	memberToken := registerUser(t, router, "Regular User", "regular@example.com", "password123")["access_token"].(string)
+
+	t.Run("lists every flag", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/flags", adminToken, nil)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, map[string]interface{}{
+			"graphql":     true,
+			"oauth":       false,
+			"websocket":   false,
+			"beta_search": true,
+		}, response["data"].(map[string]interface{})["flags"])
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodGet, "/api/v1/admin/flags", memberToken, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("disabling a flag hides its feature", func(t *testing.T) {
+		query := map[string]string{"query": "{ me { id } }"}
+		w, _ := doJSON(t, router, http.MethodPost, "/graphql", adminToken, query)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		flags.Set(featureflags.GraphQL, false)
+
+		w, _ = doJSON(t, router, http.MethodPost, "/graphql", adminToken, query)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		_, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/flags", adminToken, nil)
+		assert.Equal(t, false, response["data"].(map[string]interface{})["flags"].(map[string]interface{})["graphql"])
+	})
+}
+
+func TestAdminGetDeletedUser(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+	member := registerUser(t, router, "Deleted User", "deleted@example.com", "password123")
+	memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+	require.NoError(t, userService.DeleteUser(context.Background(), memberID))
+
+	w, response := doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/admin/users/%d", memberID), adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "deleted@example.com", data["email"])
+	assert.NotNil(t, data["deleted_at"])
+
+	w, _ = doJSON(t, router, http.MethodGet, "/api/v1/admin/users/9999", adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "a user that never existed is still not found")
+
+	w, _ = doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", memberID), adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "the public endpoint hides deleted users")
+}
+
+func TestInactiveUserTokensStopWorking(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	t.Run("deleted user", func(t *testing.T) {
+		member := registerUser(t, router, "Deleted User", "deleted@example.com", "password123")
+		memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+		accessToken := member["access_token"].(string)
+
+		w, _ := doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", memberID), accessToken, nil)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		w, _ = doJSON(t, router, http.MethodGet, "/api/v1/auth/me", accessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "the still-valid access token is rejected")
+
+		w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": member["refresh_token"].(string)})
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "refresh tokens were revoked")
+	})
+
+	t.Run("deactivated and reactivated user", func(t *testing.T) {
+		member := registerUser(t, router, "Paused User", "paused@example.com", "password123")
+		memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+		accessToken := member["access_token"].(string)
+
+		w, _ := doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/deactivate", memberID), adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w, _ = doJSON(t, router, http.MethodGet, "/api/v1/auth/me", accessToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w, _ = doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/reactivate", memberID), adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w, _ = doJSON(t, router, http.MethodGet, "/api/v1/auth/me", accessToken, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestMaintenance_AdminCanSwitchItOff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	mode := maintenance.NewMode(false, 60)
+	router, userService := newAdminTestRouter(database, config.NewTestConfig(), server.WithMaintenanceMode(mode))
+	createAdmin(t, router, userService)
+	mode.SetEnabled(true)
+
+	w, _ := doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name": "Late User", "email": "late@example.com", "password": "password123",
+	})
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, "maintenance is on")
+
+	// WHY: A fresh login stands in for an admin whose token expired during maintenance
+	session := loginUser(t, router, "admin@example.com", "password123")
+	w, response := doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": session["refresh_token"].(string)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	adminToken := response["data"].(map[string]interface{})["access_token"].(string)
+
+	w, _ = doJSON(t, router, http.MethodPut, "/api/v1/admin/maintenance", adminToken, map[string]bool{"enabled": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, mode.Enabled())
+
+	registerUser(t, router, "Late User", "late@example.com", "password123")
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/client"
+)
+
+// setupClientTestServer serves the full router over HTTP for the Go client
+func setupClientTestServer(t *testing.T) (string, user.Service) {
+	t.Helper()
+
+	router, userService := setupAdminTestRouter(t)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return srv.URL, userService
+}
+
+func TestClient_UserLifecycle(t *testing.T) {
+	baseURL, _ := setupClientTestServer(t)
+	ctx := context.Background()
+	c := client.New(baseURL)
+
+	registered, err := c.Register(ctx, api.RegisterRequest{Name: "Client User", Email: "client@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, "client@example.com", registered.User.Email)
+	assert.Equal(t, registered.AccessToken, c.Tokens().AccessToken)
+	assert.Equal(t, registered.RefreshToken, c.Tokens().RefreshToken)
+
+	got, err := c.GetUser(ctx, registered.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Client User", got.Name)
+
+	updated, err := c.UpdateUser(ctx, registered.User.ID, api.UpdateUserRequest{Name: "Renamed User"})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed User", updated.Name)
+
+	require.NoError(t, c.DeleteUser(ctx, registered.User.ID))
+
+	_, err = client.New(baseURL).Login(ctx, api.LoginRequest{Email: "client@example.com", Password: "password123"})
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestClient_RegisterWithoutAutoLogin(t *testing.T) {
+	testCfg := config.NewTestConfig()
+	testCfg.Security.AutoLoginOnRegister = false
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userHandler := user.NewHandler(user.NewService(user.NewRepository(database)), authService,
+		user.WithAutoLoginOnRegister(testCfg.Security.AutoLoginOnRegister),
+	)
+	srv := httptest.NewServer(server.SetupRouter(userHandler, authService, testCfg, database))
+	t.Cleanup(srv.Close)
+
+	c := client.New(srv.URL)
+	registered, err := c.Register(context.Background(), api.RegisterRequest{Name: "New User", Email: "new@example.com", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", registered.User.Email)
+	assert.Empty(t, registered.AccessToken)
+	assert.Equal(t, client.Tokens{}, c.Tokens())
+}
+
+func TestClient_Errors(t *testing.T) {
+	baseURL, userService := setupClientTestServer(t)
+	ctx := context.Background()
+
+	_, err := client.New(baseURL).Register(ctx, api.RegisterRequest{Name: "First", Email: "taken@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := client.New(baseURL).Register(ctx, api.RegisterRequest{Name: "Second", Email: "taken@example.com", Password: "password123"})
+
+		require.ErrorIs(t, err, client.ErrConflict)
+		var apiErr *client.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 409, apiErr.StatusCode)
+		assert.Equal(t, map[string]string{"email": "already in use"}, apiErr.Fields)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := client.New(baseURL).Register(ctx, api.RegisterRequest{Name: "Bad", Email: "not-an-email", Password: "password123"})
+
+		require.ErrorIs(t, err, client.ErrValidation)
+		var apiErr *client.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Contains(t, apiErr.Fields, "email")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		admin := newAdminClient(t, baseURL, userService)
+
+		_, err := admin.GetUser(ctx, 9999)
+
+		require.ErrorIs(t, err, client.ErrNotFound)
+		assert.NotErrorIs(t, err, client.ErrConflict)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		c := client.New(baseURL)
+		_, err := c.Login(ctx, api.LoginRequest{Email: "taken@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		_, err = c.ListUsers(ctx, client.ListUsersOptions{})
+
+		assert.ErrorIs(t, err, client.ErrForbidden)
+	})
+}
+
+func TestClient_EmailAvailable(t *testing.T) {
+	baseURL, _ := setupClientTestServer(t)
+	ctx := context.Background()
+	c := client.New(baseURL)
+
+	_, err := c.Register(ctx, api.RegisterRequest{Name: "Plus User", Email: "plus+tag@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	available, err := c.EmailAvailable(ctx, "plus+tag@example.com")
+	require.NoError(t, err)
+	assert.False(t, available, "the email is escaped in the query")
+
+	available, err = c.EmailAvailable(ctx, "free@example.com")
+	require.NoError(t, err)
+	assert.True(t, available)
+
+	_, err = c.EmailAvailable(ctx, "not-an-email")
+	assert.ErrorIs(t, err, client.ErrValidation)
+}
+
+func TestClient_ListUsers(t *testing.T) {
+	baseURL, userService := setupClientTestServer(t)
+	ctx := context.Background()
+
+	for _, email := range []string{"one@example.com", "two@example.com"} {
+		_, err := client.New(baseURL).Register(ctx, api.RegisterRequest{Name: "Listed User", Email: email, Password: "password123"})
+		require.NoError(t, err)
+	}
+	admin := newAdminClient(t, baseURL, userService)
+
+	list, err := admin.ListUsers(ctx, client.ListUsersOptions{Search: "Listed", PerPage: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), list.Total)
+	assert.Equal(t, 2, list.TotalPages)
+	assert.Len(t, list.Users, 1)
+	assert.Equal(t, "Listed User", list.Users[0].Name)
+}
+
+func TestClient_RefreshesRejectedAccessToken(t *testing.T) {
+	baseURL, _ := setupClientTestServer(t)
+	ctx := context.Background()
+
+	registered, err := client.New(baseURL).Register(ctx, api.RegisterRequest{Name: "Refresh User", Email: "refresh@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	c := client.New(baseURL, client.WithTokens(client.Tokens{AccessToken: "expired", RefreshToken: registered.RefreshToken}))
+
+	got, err := c.GetUser(ctx, registered.User.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, registered.User.ID, got.ID)
+	assert.NotEqual(t, "expired", c.Tokens().AccessToken)
+	assert.NotEqual(t, registered.RefreshToken, c.Tokens().RefreshToken, "the refresh token was rotated")
+}
+
+func TestClient_SessionExpired(t *testing.T) {
+	baseURL, _ := setupClientTestServer(t)
+	ctx := context.Background()
+
+	t.Run("refresh token rejected", func(t *testing.T) {
+		c := client.New(baseURL, client.WithTokens(client.Tokens{AccessToken: "expired", RefreshToken: "revoked"}))
+
+		_, err := c.GetUser(ctx, 1)
+
+		require.ErrorIs(t, err, client.ErrSessionExpired)
+		assert.Equal(t, client.Tokens{}, c.Tokens(), "the client forgets a dead session")
+	})
+
+	t.Run("no refresh token", func(t *testing.T) {
+		c := client.New(baseURL)
+
+		_, err := c.GetUser(ctx, 1)
+
+		assert.ErrorIs(t, err, client.ErrSessionExpired)
+	})
+}
+
+// newAdminClient registers an admin and returns a client logged in as them
+func newAdminClient(t *testing.T, baseURL string, userService user.Service) *client.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	c := client.New(baseURL)
+	registered, err := c.Register(ctx, api.RegisterRequest{Name: "Admin User", Email: "admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, userService.PromoteToAdmin(ctx, registered.User.ID))
+
+	_, err = c.Login(ctx, api.LoginRequest{Email: "admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	return c
+}
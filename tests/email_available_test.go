@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func checkEmail(t *testing.T, router *gin.Engine, email, ip string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/email-available?email="+email, nil)
+	req.RemoteAddr = ip + ":40000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response
+}
+
+func TestEmailAvailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+	testCfg.Ratelimit.Enabled = true
+	testCfg.Ratelimit.Requests = 100
+	testCfg.Ratelimit.Window = time.Minute
+	testCfg.Ratelimit.EmailCheckRequests = 3
+	testCfg.Ratelimit.EmailCheckWindow = time.Minute
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userService := user.NewService(user.NewRepository(database))
+	router := server.SetupRouter(user.NewHandler(userService, authService), authService, testCfg, database)
+
+	registerUser(t, router, "Taken User", "taken@example.com", "password123")
+
+	w, response := checkEmail(t, router, "Taken@Example.com", "203.0.113.1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"available": false}, response["data"], "lookups ignore case")
+
+	w, response = checkEmail(t, router, "free@example.com", "203.0.113.1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"available": true}, response["data"])
+
+	w, _ = checkEmail(t, router, "free@example.com", "203.0.113.1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, response = checkEmail(t, router, "free@example.com", "203.0.113.1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "the tighter per-IP budget is spent well before the general one")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, "TOO_MANY_REQUESTS", response["error"].(map[string]interface{})["code"])
+
+	w, _ = checkEmail(t, router, "free@example.com", "203.0.113.2")
+	assert.Equal(t, http.StatusOK, w.Code, "other IPs keep their own budget")
+}
+
+func TestEmailAvailable_EnumerationProtection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userService := user.NewService(user.NewRepository(database))
+	router := server.SetupRouter(user.NewHandler(userService, authService, user.WithEmailEnumerationProtection(true)), authService, testCfg, database)
+
+	registerUser(t, router, "Taken User", "taken@example.com", "password123")
+
+	w, response := checkEmail(t, router, "taken@example.com", "203.0.113.1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"available": true}, response["data"])
+
+	w, response = doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Second Try",
+		"email":    "TAKEN@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusConflict, w.Code, "registration still rejects the taken email")
+	assert.Equal(t, "CONFLICT", response["error"].(map[string]interface{})["code"])
+}
+
+func TestEmailAvailable_PendingEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userService := user.NewService(user.NewRepository(database))
+	router := server.SetupRouter(user.NewHandler(userService, authService), authService, testCfg, database)
+
+	owner := registerUser(t, router, "Owner", "owner@example.com", "password123")
+	ownerID := uint(owner["user"].(map[string]interface{})["id"].(float64))
+	w, _ := doJSON(t, router, http.MethodPut, fmt.Sprintf("/api/v1/users/%d", ownerID), owner["access_token"].(string),
+		map[string]string{"email": "claimed@example.com"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, response := checkEmail(t, router, "Claimed@Example.com", "203.0.113.1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"available": false}, response["data"], "a pending email change claims the address")
+
+	w, response = doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Squatter",
+		"email":    "CLAIMED@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, "CONFLICT", response["error"].(map[string]interface{})["code"])
+}
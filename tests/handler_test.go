@@ -17,46 +17,17 @@ import (
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/featureflags"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/testutil"
 	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
 )
 
-// createTestSchema creates the SQLite test schema using GORM AutoMigrate for consistency
+// createTestSchema applies the embedded migrations to the SQLite test database
 func createTestSchema(t *testing.T, database *gorm.DB) {
 	t.Helper()
 
-	err := database.AutoMigrate(&user.User{}, &user.Role{}, &auth.RefreshToken{})
-	assert.NoError(t, err)
-
-	// Drop the auto-created user_roles table (created by GORM for many2many)
-	// and recreate it with our custom schema including assigned_at column
-	database.Exec("DROP TABLE IF EXISTS user_roles")
-
-	// Manually create the user_roles junction table with assigned_at column
-	err = database.Exec(`
-		CREATE TABLE user_roles (
-			user_id INTEGER NOT NULL,
-			role_id INTEGER NOT NULL,
-			assigned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			PRIMARY KEY (user_id, role_id),
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-			FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
-		)
-	`).Error
-	assert.NoError(t, err)
-
-	// Seed role data - use FirstOrCreate to avoid duplicate errors
-	roles := []user.Role{
-		{ID: 1, Name: "user", Description: "Standard user with basic permissions"},
-		{ID: 2, Name: "admin", Description: "Administrator with full system access"},
-	}
-	for _, role := range roles {
-		var existingRole user.Role
-		result := database.Where("name = ?", role.Name).FirstOrCreate(&existingRole, &role)
-		if result.Error != nil {
-			t.Fatalf("Failed to create role %s: %v", role.Name, result.Error)
-		}
-	}
+	assert.NoError(t, testutil.MigrateSQLite(database))
 }
 
 func setupTestRouter(t *testing.T) *gin.Engine {
@@ -156,8 +127,12 @@ func TestRegisterHandler(t *testing.T) {
 				if !ok {
 					t.Fatal("Expected error object in response")
 				}
-				if errorMsg, ok := errorInfo["message"].(string); !ok || errorMsg == "" {
-					t.Error("Expected error message in response")
+				if errorMsg, ok := errorInfo["message"].(string); !ok || errorMsg != "Email already in use" {
+					t.Errorf("Expected message 'Email already in use', got '%v'", errorInfo["message"])
+				}
+				details, ok := errorInfo["details"].(map[string]interface{})
+				if !ok || details["email"] != "already in use" {
+					t.Errorf("Expected details to name the email field, got %v", errorInfo["details"])
 				}
 			},
 		},
@@ -378,6 +353,46 @@ func TestHealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestUnmatchedRoutes(t *testing.T) {
+	router := setupTestRouter(t)
+	// WHY: Routes mounted after SetupRouter must be covered by the 405 handler too
+	server.RegisterWebSocketRoutes(router, featureflags.New(nil), nil)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		code    string
+		allowed string
+	}{
+		{name: "wrong method on auth route", method: http.MethodGet, path: "/api/v1/auth/register", status: http.StatusMethodNotAllowed, code: "METHOD_NOT_ALLOWED", allowed: "POST"},
+		{name: "wrong method on route mounted later", method: http.MethodPost, path: "/api/v1/ws", status: http.StatusMethodNotAllowed, code: "METHOD_NOT_ALLOWED", allowed: "GET"},
+		{name: "unknown route", method: http.MethodGet, path: "/api/v2/users", status: http.StatusNotFound, code: "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.allowed, w.Header().Get("Allow"))
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+			assert.NotEmpty(t, w.Header().Get("X-Request-ID"), "fallbacks run through the global middleware")
+
+			var response map[string]interface{}
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response)) {
+				assert.Equal(t, false, response["success"])
+				errorInfo, _ := response["error"].(map[string]interface{})
+				assert.Equal(t, tt.code, errorInfo["code"])
+				assert.Equal(t, tt.path, errorInfo["path"])
+			}
+		})
+	}
+}
+
 func TestRateLimit_BlocksThenAllows(t *testing.T) {
 	r := setupRateLimitTestRouter(t)
 
@@ -436,6 +451,23 @@ func TestRateLimit_BlocksThenAllows(t *testing.T) {
 			if err != nil || retryAfterSec <= 0 {
 				t.Fatalf("Retry-After should be positive integer seconds, got %q (err=%v)", retryAfterStr, err)
 			}
+			assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+			assert.NotEmpty(t, rr.Header().Get("X-RateLimit-Limit"))
+			assert.NotEmpty(t, rr.Header().Get("X-RateLimit-Reset"))
+
+			var errResp map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &errResp); err != nil {
+				t.Fatalf("Failed to unmarshal 429 response: %v", err)
+			}
+			assert.Equal(t, false, errResp["success"])
+			errorObj, ok := errResp["error"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected standard error object in 429 response, got %s", rr.Body.String())
+			}
+			assert.Equal(t, "TOO_MANY_REQUESTS", errorObj["code"])
+			assert.Equal(t, "Rate limit exceeded", errorObj["message"])
+			assert.NotEmpty(t, errorObj["details"])
+			assert.Equal(t, float64(retryAfterSec), errorObj["retry_after"])
 			t.Logf("Rate limit triggered after %d successful requests (including register)", successCount+1)
 			return
 		} else {
@@ -446,3 +478,85 @@ func TestRateLimit_BlocksThenAllows(t *testing.T) {
 	// If we get here, rate limiting didn't work
 	t.Fatalf("expected rate limiting to trigger, but completed %d requests without 429", successCount)
 }
+
+func TestRegister_WithoutRefreshTokenStore(t *testing.T) {
+	tests := []struct {
+		name           string
+		fallback       bool
+		expectedStatus int
+	}{
+		{name: "access-only fallback enabled", fallback: true, expectedStatus: http.StatusOK},
+		{name: "access-only fallback disabled", fallback: false, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			testCfg := config.NewTestConfig()
+			testCfg.JWT.AccessOnlyFallback = tt.fallback
+
+			database, err := db.NewSQLiteDB(":memory:")
+			assert.NoError(t, err)
+			createTestSchema(t, database)
+
+			authService := auth.NewService(&testCfg.JWT)
+			userService := user.NewService(user.NewRepository(database))
+			router := server.SetupRouter(user.NewHandler(userService, authService), authService, testCfg, database)
+
+			body, _ := json.Marshal(map[string]string{
+				"name":     "No Store",
+				"email":    "nostore@example.com",
+				"password": "password123",
+			})
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.fallback {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				data := response["data"].(map[string]interface{})
+				assert.NotEmpty(t, data["access_token"])
+				assert.Empty(t, data["refresh_token"])
+			}
+		})
+	}
+}
+
+func TestRegisterHandler_StrictJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCfg := config.NewTestConfig()
+	testCfg.Server.StrictJSON = true
+
+	database, err := db.NewSQLiteDB(":memory:")
+	assert.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userHandler := user.NewHandler(user.NewService(user.NewRepository(database)), authService)
+	router := server.SetupRouter(userHandler, authService, testCfg, database)
+
+	body, _ := json.Marshal(map[string]string{
+		"name":    "Strict User",
+		"email":   "strict@example.com",
+		"pasword": "password123",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	errorInfo := response["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errorInfo["code"])
+	assert.Equal(t, map[string]interface{}{
+		"pasword":  "pasword is not a known field",
+		"password": "password is required",
+	}, errorInfo["details"])
+}
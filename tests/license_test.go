@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func TestLicenseMaxUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+	testCfg.License.MaxUsers = 2
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userService := user.NewService(user.NewRepository(database), user.WithMaxUsers(testCfg.License.MaxUsers))
+	router := server.SetupRouter(user.NewHandler(userService, authService), authService, testCfg, database)
+
+	adminToken := createAdmin(t, router, userService)
+
+	w, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/stats", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"users": float64(1), "max_users": float64(2), "remaining_seats": float64(1)}, response["data"])
+
+	registerUser(t, router, "Second User", "second@example.com", "password123")
+
+	w, response = doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Third User",
+		"email":    "third@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Equal(t, "LICENSE_LIMIT_REACHED", response["error"].(map[string]interface{})["code"])
+
+	w, response = doJSON(t, router, http.MethodGet, "/api/v1/admin/stats", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(0), response["data"].(map[string]interface{})["remaining_seats"])
+}
+
+func TestLicenseMaxUsers_Unlimited(t *testing.T) {
+	router, userService := setupAdminTestRouter(t)
+	adminToken := createAdmin(t, router, userService)
+
+	w, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/stats", adminToken, nil)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"users": float64(1), "max_users": float64(0), "remaining_seats": nil}, response["data"])
+}
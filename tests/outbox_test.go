@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/events"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/outbox"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/server"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func TestOutbox_UserCreated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	outboxRepo := outbox.NewRepository(database)
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+	userService := user.NewService(user.NewRepository(database), user.WithOutbox(outboxRepo))
+	router := server.SetupRouter(user.NewHandler(userService, authService), authService, testCfg, database)
+
+	registered := registerUser(t, router, "Outbox User", "outbox@example.com", "password123")
+	userID := uint(registered["user"].(map[string]interface{})["id"].(float64))
+
+	w, _ := doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{
+		"name":     "Duplicate User",
+		"email":    "outbox@example.com",
+		"password": "password123",
+	})
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	pending, err := outboxRepo.Pending(context.Background(), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "only the committed registration wrote an event")
+	assert.Equal(t, events.TypeUserCreated, pending[0].Type)
+	assert.Equal(t, userID, pending[0].UserID)
+
+	bus := events.NewBus(0)
+	ch, unsubscribe := bus.Subscribe(userID)
+	defer unsubscribe()
+	relay := outbox.NewRelay(outboxRepo, outbox.PublishTo(bus), outbox.RelayConfig{})
+
+	require.NoError(t, relay.Run(context.Background()))
+	require.NoError(t, relay.Run(context.Background()))
+
+	require.Len(t, ch, 1, "the event is delivered exactly once")
+	event := <-ch
+	assert.Equal(t, events.TypeUserCreated, event.Type)
+	data, err := json.Marshal(event.Data)
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, "outbox@example.com", payload["email"])
+	assert.Equal(t, []interface{}{"user"}, payload["roles"])
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/audit"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/seed"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+)
+
+func TestSeedRun_IsIdempotentAndDeterministic(t *testing.T) {
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	opts := seed.Options{Users: 12, Admins: 2, SessionsPerUser: 2, Seed: 7, Password: "password123"}
+	ctx := context.Background()
+
+	first, err := seed.Run(ctx, database, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 12, first.UsersCreated)
+	assert.Equal(t, 0, first.UsersExisting)
+	assert.Equal(t, 2, first.AdminsAssigned)
+	assert.Equal(t, 24, first.SessionsCreated)
+	assert.Equal(t, 2, first.AuditEntriesCreated)
+
+	var firstUser user.User
+	require.NoError(t, database.Where("email = ?", seed.Email(1)).First(&firstUser).Error)
+
+	second, err := seed.Run(ctx, database, opts)
+	require.NoError(t, err)
+	assert.Equal(t, seed.Summary{UsersExisting: 12}, *second)
+
+	var userCount, tokenCount, auditCount int64
+	database.Model(&user.User{}).Count(&userCount)
+	database.Model(&auth.RefreshToken{}).Count(&tokenCount)
+	database.Model(&audit.Entry{}).Count(&auditCount)
+	assert.Equal(t, int64(12), userCount)
+	assert.Equal(t, int64(24), tokenCount)
+	assert.Equal(t, int64(2), auditCount)
+
+	// Same seed on a fresh database yields the same names
+	other, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, other)
+	_, err = seed.Run(ctx, other, opts)
+	require.NoError(t, err)
+
+	var otherFirstUser user.User
+	require.NoError(t, other.Where("email = ?", seed.Email(1)).First(&otherFirstUser).Error)
+	assert.Equal(t, firstUser.Name, otherFirstUser.Name)
+}
+
+func TestSeedRun_AdminsCanLogInAndPaginate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	_, err = seed.Run(context.Background(), database, seed.Options{Users: 45, Admins: 1, Seed: 1, Password: "password123"})
+	require.NoError(t, err)
+
+	router, _ := newAdminTestRouter(database, config.NewTestConfig())
+	token := loginUser(t, router, seed.Email(1), "password123")["access_token"].(string)
+
+	w, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/users?page=3&per_page=20", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(45), data["total"])
+	assert.Equal(t, float64(3), data["total_pages"])
+	assert.Len(t, data["users"], 5)
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+)
+
+func TestTablePrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	database, err := db.NewSQLiteDB(":memory:", db.WithTablePrefix("tenant_"))
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	assert.True(t, database.Migrator().HasTable("tenant_users"))
+	assert.False(t, database.Migrator().HasTable("users"))
+
+	router, userService := newAdminTestRouter(database, config.NewTestConfig())
+	adminToken := createAdmin(t, router, userService)
+	member := registerUser(t, router, "Member User", "member@example.com", "password123")
+	memberID := uint(member["user"].(map[string]interface{})["id"].(float64))
+
+	t.Run("admin role is read from the prefixed tables", func(t *testing.T) {
+		w, response := doJSON(t, router, http.MethodGet, "/api/v1/admin/users?role=admin", adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["total"])
+		assert.Equal(t, "admin@example.com", data["users"].([]interface{})[0].(map[string]interface{})["email"])
+	})
+
+	t.Run("deleted user token is rejected", func(t *testing.T) {
+		w, _ := doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/admin/users/%d", memberID), adminToken, nil)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		w, _ = doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", memberID), member["access_token"].(string), nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tenant"
+)
+
+func TestTenantScoping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCfg := config.NewTestConfig()
+	testCfg.Tenant.Enabled = true
+	testCfg.Tenant.BaseDomain = "example.com"
+
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+	router, _ := newAdminTestRouter(database, testCfg)
+
+	// doTenantJSON sends a JSON request on behalf of the tenant named by host
+	doTenantJSON := func(host, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		req := httptest.NewRequest(http.MethodPost, path, &buf)
+		req.Host = host
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	alice := map[string]string{"name": "Alice", "email": "alice@example.com", "password": "password123"}
+	w := doTenantJSON("acme.example.com", "/api/v1/auth/register", alice)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	login := map[string]string{"email": "alice@example.com", "password": "password123"}
+	w = doTenantJSON("acme.example.com", "/api/v1/auth/login", login)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doTenantJSON("globex.example.com", "/api/v1/auth/login", login)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "users of one tenant do not exist in another")
+
+	w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/login", "", login)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "nor in the default tenant")
+
+	t.Run("emails are unique per tenant", func(t *testing.T) {
+		w := doTenantJSON("globex.example.com", "/api/v1/auth/register", alice)
+		assert.Equal(t, http.StatusOK, w.Code, "another tenant's account does not block the email: %s", w.Body.String())
+
+		w = doTenantJSON("acme.example.com", "/api/v1/auth/register", alice)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("tokens are bound to their tenant", func(t *testing.T) {
+		w := doTenantJSON("acme.example.com", "/api/v1/auth/login", login)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Data struct {
+				AccessToken string `json:"access_token"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		token := response.Data.AccessToken
+
+		claims, err := auth.NewService(&testCfg.JWT).ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", claims.TenantID)
+
+		me := func(configure func(*http.Request)) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+			req.Host = "acme.example.com"
+			req.Header.Set("Authorization", "Bearer "+token)
+			configure(req)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusOK, me(func(*http.Request) {}))
+		assert.Equal(t, http.StatusUnauthorized, me(func(req *http.Request) { req.Header.Set(tenant.Header, "globex") }), "the header cannot switch tenants")
+		assert.Equal(t, http.StatusUnauthorized, me(func(req *http.Request) { req.Host = "example.com" }), "nor can dropping the subdomain")
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		req.Header.Set(tenant.Header, "not a tenant")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
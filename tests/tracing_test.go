@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/tracing"
+)
+
+func TestTracing_RequestSpanCoversQueriesAndLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousLogger := otel.GetTracerProvider(), slog.Default()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		slog.SetDefault(previousLogger)
+	})
+	tracing.SetGlobal(provider)
+	require.NoError(t, tracing.InstrumentGORM(database, provider))
+	var logs bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	testCfg := config.NewTestConfig()
+	testCfg.Tracing = config.TracingConfig{Enabled: true, SampleRatio: 1, ServiceName: "test-service"}
+	router, _ := newAdminTestRouter(database, testCfg)
+
+	registerUser(t, router, "Traced User", "traced@example.com", "password123")
+	require.NoError(t, provider.ForceFlush(t.Context()))
+
+	var serverSpan tracetest.SpanStub
+	var querySpans []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		switch span.SpanKind {
+		case trace.SpanKindServer:
+			serverSpan = span
+		case trace.SpanKindClient:
+			querySpans = append(querySpans, span)
+		}
+	}
+	require.True(t, serverSpan.SpanContext.IsValid(), "no server span recorded")
+	assert.Equal(t, "/api/v1/auth/register", serverSpan.Name)
+	require.NotEmpty(t, querySpans)
+	for _, span := range querySpans {
+		assert.Equal(t, serverSpan.SpanContext.TraceID(), span.SpanContext.TraceID(), span.Name)
+		assert.Equal(t, serverSpan.SpanContext.SpanID(), span.Parent.SpanID(), span.Name)
+	}
+
+	var requestLog map[string]any
+	scanner := bufio.NewScanner(&logs)
+	for scanner.Scan() {
+		var line map[string]any
+		if json.Unmarshal(scanner.Bytes(), &line) == nil && line["msg"] == "HTTP Request" {
+			requestLog = line
+		}
+	}
+	require.NotNil(t, requestLog, "no request log line written")
+	assert.Equal(t, serverSpan.SpanContext.TraceID().String(), requestLog["trace_id"])
+	assert.Equal(t, float64(http.StatusOK), requestLog["status"])
+}
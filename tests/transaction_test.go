@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/auth"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/config"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/db"
+	apiErrors "github.com/vahiiiid/go-rest-api-boilerplate/internal/errors"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/middleware"
+	"github.com/vahiiiid/go-rest-api-boilerplate/internal/user"
+	"github.com/vahiiiid/go-rest-api-boilerplate/pkg/api"
+)
+
+func TestRequestTransaction_RollsBackFailedRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	testCfg := config.NewTestConfig()
+	userService := user.NewService(user.NewRepository(database))
+	authService := auth.NewServiceWithRepo(&testCfg.JWT, database)
+
+	router := gin.New()
+	router.Use(apiErrors.ErrorHandler())
+	router.POST("/register-then-fail", middleware.Transaction(database), func(c *gin.Context) {
+		created, err := userService.RegisterUser(c.Request.Context(), api.RegisterRequest{Name: "Doomed User", Email: "doomed@example.com", Password: "password123"})
+		require.NoError(t, err)
+		_, err = authService.GenerateTokenPair(c.Request.Context(), created.ID, created.Email, created.Name)
+		require.NoError(t, err)
+
+		_ = c.Error(apiErrors.ServerError(errors.New("forced failure after the writes")))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/register-then-fail", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	for _, table := range []string{"users", "user_roles", "refresh_tokens"} {
+		var count int64
+		require.NoError(t, database.Table(db.Table(database, table)).Count(&count).Error)
+		assert.Zero(t, count, "rows left in %s", table)
+	}
+}
+
+func TestRequestTransaction_NormalFlows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, err := db.NewSQLiteDB(":memory:")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	testCfg := config.NewTestConfig()
+	testCfg.Database.RequestTransactions = true
+	router, userService := newAdminTestRouter(database, testCfg)
+
+	adminToken := createAdmin(t, router, userService)
+	registered := registerUser(t, router, "Regular User", "regular@example.com", "password123")
+	userID := uint(registered["user"].(map[string]interface{})["id"].(float64))
+	claims, err := auth.NewService(&testCfg.JWT).ValidateToken(registered["access_token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, []string{user.RoleUser}, claims.Roles, "the token sees the role assigned in the same transaction")
+
+	w, response := doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": registered["refresh_token"].(string)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	refreshed := response["data"].(map[string]interface{})
+
+	w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/logout", refreshed["access_token"].(string), map[string]string{"refresh_token": refreshed["refresh_token"].(string)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, _ = doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/deactivate", userID), adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/register", "", map[string]string{"name": "Regular User", "email": "regular@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusConflict, w.Code, "a failed write request is rolled back and reported as before")
+
+	var count int64
+	require.NoError(t, database.Table(db.Table(database, "audit_logs")).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRequestTransaction_ReuseRevocationSurvivesRollback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// WHY: The revocation runs on a second connection, which needs a shared database that it can write next to the open transaction
+	database, err := db.NewSQLiteDB("file:" + filepath.Join(t.TempDir(), "reuse.db") + "?_journal_mode=WAL&_busy_timeout=5000")
+	require.NoError(t, err)
+	createTestSchema(t, database)
+
+	testCfg := config.NewTestConfig()
+	testCfg.Database.RequestTransactions = true
+	testCfg.JWT.RefreshReuseGrace = 0
+	router, _ := newAdminTestRouter(database, testCfg)
+
+	registered := registerUser(t, router, "Regular User", "regular@example.com", "password123")
+	stolen := registered["refresh_token"].(string)
+
+	w, response := doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": stolen})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	current := response["data"].(map[string]interface{})["refresh_token"].(string)
+
+	w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": stolen})
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w, _ = doJSON(t, router, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": current})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the family revoked on reuse stays revoked after the 403 rolls back: %s", w.Body.String())
+}